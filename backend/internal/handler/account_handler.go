@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"huaan-medical/internal/middleware"
+	"huaan-medical/internal/service"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/response"
+)
+
+// AccountHandler 账号个人信息处理器（个人数据导出、账号注销）
+type AccountHandler struct {
+	service *service.AccountService
+}
+
+// NewAccountHandler 创建账号个人信息处理器实例
+func NewAccountHandler() *AccountHandler {
+	return &AccountHandler{
+		service: service.NewAccountService(),
+	}
+}
+
+// CreateExport 申请个人数据导出
+// @Summary 申请个人数据导出
+// @Description 异步导出本人账号资料、就诊人、预约及就诊记录（JSON 或 PDF），生成完成后发送站内消息
+// @Tags 账号与隐私
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.CreateDataExportRequest true "导出格式"
+// @Success 200 {object} response.Response{data=model.DataExportVO}
+// @Router /api/user/account/exports [post]
+func (h *AccountHandler) CreateExport(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	var req service.CreateDataExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	export, err := h.service.RequestExport(userID, &req, c.ClientIP())
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, export)
+}
+
+// ListExports 个人数据导出记录
+// @Summary 个人数据导出记录
+// @Description 查询本人最近的个人数据导出记录及生成状态
+// @Tags 账号与隐私
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response{data=[]model.DataExportVO}
+// @Router /api/user/account/exports [get]
+func (h *AccountHandler) ListExports(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	list, err := h.service.ListExports(userID)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// DownloadExport 下载个人数据导出文件
+// @Summary 下载个人数据导出文件
+// @Description 在有效期内下载已生成的导出文件（仅本人）
+// @Tags 账号与隐私
+// @Produce octet-stream
+// @Security Bearer
+// @Param id path int true "导出记录ID"
+// @Success 200 {file} file
+// @Router /api/user/account/exports/{id}/download [get]
+func (h *AccountHandler) DownloadExport(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	path, fileName, err := h.service.GetExportFile(userID, id)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	c.FileAttachment(path, fileName)
+}
+
+// RequestDeletion 申请注销账号
+// @Summary 申请注销账号
+// @Description 提交注销申请后进入冷静期，冷静期内可撤回；到期后个人信息将被匿名化，诊疗及费用记录按规定保留，所有登录凭证失效
+// @Tags 账号与隐私
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.RequestAccountDeletionRequest true "注销信息"
+// @Success 200 {object} response.Response{data=model.AccountDeletionVO}
+// @Router /api/user/account/deletion [post]
+func (h *AccountHandler) RequestDeletion(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	var req service.RequestAccountDeletionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	deletion, err := h.service.RequestDeletion(userID, &req, c.ClientIP())
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, deletion)
+}
+
+// GetDeletion 查询注销申请
+// @Summary 查询注销申请
+// @Description 查询冷静期中的注销申请及计划注销时间
+// @Tags 账号与隐私
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response{data=model.AccountDeletionVO}
+// @Router /api/user/account/deletion [get]
+func (h *AccountHandler) GetDeletion(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	deletion, err := h.service.GetDeletion(userID)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, deletion)
+}
+
+// CancelDeletion 撤回注销申请
+// @Summary 撤回注销申请
+// @Description 冷静期内撤回注销申请，账号恢复正常使用
+// @Tags 账号与隐私
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/user/account/deletion [delete]
+func (h *AccountHandler) CancelDeletion(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	if err := h.service.CancelDeletion(userID); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "已撤回注销申请", nil)
+}
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"huaan-medical/internal/middleware"
+	"huaan-medical/internal/service"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/response"
+)
+
+// AccountLinkHandler 账号登录方式绑定处理器
+type AccountLinkHandler struct {
+	service *service.AccountLinkService
+}
+
+// NewAccountLinkHandler 创建账号登录方式绑定处理器实例
+func NewAccountLinkHandler() *AccountLinkHandler {
+	return &AccountLinkHandler{
+		service: service.NewAccountLinkService(),
+	}
+}
+
+// GetLogins 查询已绑定的登录方式
+// @Summary 查询已绑定的登录方式
+// @Description 查询当前账号的微信、手机号、用户名密码绑定情况
+// @Tags 账号与隐私
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response{data=model.UserLoginsVO}
+// @Router /api/user/account/logins [get]
+func (h *AccountLinkHandler) GetLogins(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	logins, err := h.service.GetLogins(userID)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, logins)
+}
+
+// BindWeChat 绑定微信
+// @Summary 绑定微信
+// @Description 使用微信登录凭证绑定微信；该微信已属于其他账号时返回合并引导信息
+// @Tags 账号与隐私
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.BindWeChatRequest true "微信登录凭证"
+// @Success 200 {object} response.Response{data=service.BindLoginResponse}
+// @Router /api/user/account/logins/wechat [post]
+func (h *AccountLinkHandler) BindWeChat(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	var req service.BindWeChatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	result, err := h.service.BindWeChat(userID, &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// BindPhone 绑定或更换手机号
+// @Summary 绑定或更换手机号
+// @Description 通过短信验证码绑定或更换手机号；该手机号已属于其他账号时返回合并引导信息
+// @Tags 账号与隐私
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.BindPhoneRequest true "手机号及验证码"
+// @Success 200 {object} response.Response{data=service.BindLoginResponse}
+// @Router /api/user/account/logins/phone [post]
+func (h *AccountLinkHandler) BindPhone(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	var req service.BindPhoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	result, err := h.service.BindPhone(userID, &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// BindPassword 设置用户名密码登录
+// @Summary 设置用户名密码登录
+// @Description 为账号设置用户名和密码；用户名已属于其他账号且密码正确时返回合并引导信息
+// @Tags 账号与隐私
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.BindPasswordRequest true "用户名及密码"
+// @Success 200 {object} response.Response{data=service.BindLoginResponse}
+// @Router /api/user/account/logins/password [post]
+func (h *AccountLinkHandler) BindPassword(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	var req service.BindPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	result, err := h.service.BindPassword(userID, &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// Unbind 解绑登录方式
+// @Summary 解绑登录方式
+// @Description 解绑微信、手机号或用户名密码登录，账号至少需保留一种登录方式
+// @Tags 账号与隐私
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param type path string true "登录方式" Enums(wechat, phone, password)
+// @Success 200 {object} response.Response{data=model.UserLoginsVO}
+// @Router /api/user/account/logins/{type} [delete]
+func (h *AccountLinkHandler) Unbind(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	logins, err := h.service.Unbind(userID, c.Param("type"))
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, logins)
+}
+
+// Merge 确认合并账号
+// @Summary 确认合并账号
+// @Description 使用绑定时返回的合并凭证，将对方账号的就诊人、预约、评价等数据并入当前账号，对方账号随后停用
+// @Tags 账号与隐私
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.MergeAccountRequest true "合并凭证"
+// @Success 200 {object} response.Response{data=service.MergeAccountResponse}
+// @Router /api/user/account/merge [post]
+func (h *AccountLinkHandler) Merge(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	var req service.MergeAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	result, err := h.service.Merge(userID, &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "账号合并成功", result)
+}
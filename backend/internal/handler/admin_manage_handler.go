@@ -137,3 +137,36 @@ func (h *AdminManageHandler) ResetAdminPassword(c *gin.Context) {
 
 	response.SuccessWithMessage(c, "密码重置成功", nil)
 }
+
+// UpdateCampusRoles 分配院区角色
+// @Summary 分配院区角色
+// @Description 整体替换管理员的院区角色，院区角色仅在请求指定院区时生效（仅全院管理员可操作）
+// @Tags 管理员管理
+// @Accept json
+// @Produce json
+// @Security BearerAdmin
+// @Param id path int true "管理员ID"
+// @Param request body service.UpdateCampusRolesRequest true "院区角色"
+// @Success 200 {object} response.Response{data=model.AdminVO}
+// @Router /api/admin/admins/{id}/campus-roles [put]
+func (h *AdminManageHandler) UpdateCampusRoles(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.UpdateCampusRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	admin, err := h.service.UpdateCampusRoles(id, &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, admin)
+}
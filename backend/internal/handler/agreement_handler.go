@@ -0,0 +1,390 @@
+package handler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"huaan-medical/internal/middleware"
+	"huaan-medical/internal/model"
+	"huaan-medical/internal/service"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/response"
+)
+
+// AgreementHandler 协议版本与同意记录处理器
+type AgreementHandler struct {
+	service *service.AgreementService
+}
+
+// NewAgreementHandler 创建协议处理器实例
+func NewAgreementHandler() *AgreementHandler {
+	return &AgreementHandler{
+		service: service.NewAgreementService(),
+	}
+}
+
+// GetCurrent 查询现行协议
+// @Summary 查询现行协议
+// @Description 查询指定类型协议的现行版本（登录、注册页展示）
+// @Tags 协议
+// @Accept json
+// @Produce json
+// @Param type path string true "协议类型 privacy/terms/referral"
+// @Success 200 {object} response.Response{data=model.AgreementVO}
+// @Router /api/agreements/{type} [get]
+func (h *AgreementHandler) GetCurrent(c *gin.Context) {
+	agreementType := c.Param("type")
+	switch agreementType {
+	case model.AgreementTypePrivacy, model.AgreementTypeTerms, model.AgreementTypeReferral:
+	default:
+		response.FailWithMessage(c, errorcode.ErrInvalidParams, "协议类型无效")
+		return
+	}
+
+	agreement, err := h.service.GetCurrent(agreementType)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, agreement)
+}
+
+// ListPending 查询待同意的协议
+// @Summary 查询待同意的协议
+// @Description 查询当前用户尚未同意现行版本的隐私政策、用户服务协议；业务接口返回403004时调用
+// @Tags 协议
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response{data=[]model.AgreementVO}
+// @Router /api/user/agreements/pending [get]
+func (h *AgreementHandler) ListPending(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	list, err := h.service.ListPending(userID)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// Consent 同意或撤回协议
+// @Summary 同意或撤回协议
+// @Description 同意协议现行版本（记录时间、IP及客户端）；转诊病历共享授权按就诊人同意，可撤回
+// @Tags 协议
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.AgreementConsentRequest true "协议及动作"
+// @Success 200 {object} response.Response{data=model.AgreementConsentVO}
+// @Router /api/user/agreements/consents [post]
+func (h *AgreementHandler) Consent(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	var req service.AgreementConsentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	consent, err := h.service.Consent(userID, &req, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, consent)
+}
+
+// ListMyConsents 查询本人的协议同意记录
+// @Summary 查询本人的协议同意记录
+// @Description 查询当前用户最近的协议同意/撤回记录
+// @Tags 协议
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response{data=[]model.AgreementConsentVO}
+// @Router /api/user/agreements/consents [get]
+func (h *AgreementHandler) ListMyConsents(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	list, err := h.service.ListMyConsents(userID)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// List 协议版本列表
+// @Summary 协议版本列表
+// @Description 分页查询协议版本（不含正文）
+// @Tags 协议管理（后台）
+// @Accept json
+// @Produce json
+// @Security BearerAdmin
+// @Param page query int true "页码" minimum(1)
+// @Param page_size query int true "每页数量" minimum(1) maximum(100)
+// @Param type query string false "协议类型 privacy/terms/referral"
+// @Param status query string false "状态 draft/published"
+// @Success 200 {object} response.Response{data=response.PageData{list=[]model.AgreementVO}}
+// @Router /api/admin/agreements [get]
+func (h *AgreementHandler) List(c *gin.Context) {
+	var req service.ListAgreementsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	list, total, err := h.service.List(&req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithPage(c, list, total, req.Page, req.PageSize)
+}
+
+// GetByID 协议版本详情
+// @Summary 协议版本详情
+// @Tags 协议管理（后台）
+// @Accept json
+// @Produce json
+// @Security BearerAdmin
+// @Param id path int true "协议版本ID"
+// @Success 200 {object} response.Response{data=model.AgreementVO}
+// @Router /api/admin/agreements/{id} [get]
+func (h *AgreementHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	agreement, err := h.service.GetByID(id)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, agreement)
+}
+
+// Create 创建协议版本
+// @Summary 创建协议版本
+// @Description 创建协议版本草稿，发布前可编辑
+// @Tags 协议管理（后台）
+// @Accept json
+// @Produce json
+// @Security BearerAdmin
+// @Param request body service.SaveAgreementRequest true "协议内容"
+// @Success 200 {object} response.Response{data=model.AgreementVO}
+// @Router /api/admin/agreements [post]
+func (h *AgreementHandler) Create(c *gin.Context) {
+	var req service.SaveAgreementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	agreement, err := h.service.Create(middleware.GetAdminID(c), &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, agreement)
+}
+
+// Update 编辑协议版本
+// @Summary 编辑协议版本
+// @Description 编辑协议版本草稿（已发布的版本不可修改）
+// @Tags 协议管理（后台）
+// @Accept json
+// @Produce json
+// @Security BearerAdmin
+// @Param id path int true "协议版本ID"
+// @Param request body service.SaveAgreementRequest true "协议内容"
+// @Success 200 {object} response.Response{data=model.AgreementVO}
+// @Router /api/admin/agreements/{id} [put]
+func (h *AgreementHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.SaveAgreementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	agreement, err := h.service.Update(id, &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, agreement)
+}
+
+// Delete 删除协议版本
+// @Summary 删除协议版本
+// @Description 删除协议版本草稿（已发布的版本不可删除）
+// @Tags 协议管理（后台）
+// @Accept json
+// @Produce json
+// @Security BearerAdmin
+// @Param id path int true "协议版本ID"
+// @Success 200 {object} response.Response
+// @Router /api/admin/agreements/{id} [delete]
+func (h *AgreementHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "删除成功", gin.H{})
+}
+
+// Publish 发布协议版本
+// @Summary 发布协议版本
+// @Description 发布后成为该类型的现行版本；隐私政策、用户服务协议发布后用户需重新同意
+// @Tags 协议管理（后台）
+// @Accept json
+// @Produce json
+// @Security BearerAdmin
+// @Param id path int true "协议版本ID"
+// @Success 200 {object} response.Response{data=model.AgreementVO}
+// @Router /api/admin/agreements/{id}/publish [post]
+func (h *AgreementHandler) Publish(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	agreement, err := h.service.Publish(middleware.GetAdminID(c), id)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "发布成功", agreement)
+}
+
+// ListConsents 协议同意记录
+// @Summary 协议同意记录
+// @Description 分页查询用户的协议同意/撤回记录（审计）
+// @Tags 协议管理（后台）
+// @Accept json
+// @Produce json
+// @Security BearerAdmin
+// @Param page query int true "页码" minimum(1)
+// @Param page_size query int true "每页数量" minimum(1) maximum(100)
+// @Param type query string false "协议类型"
+// @Param version query string false "版本号"
+// @Param user_id query int false "用户ID"
+// @Param patient_id query int false "就诊人ID"
+// @Param start_date query string false "开始日期 YYYY-MM-DD"
+// @Param end_date query string false "结束日期 YYYY-MM-DD"
+// @Success 200 {object} response.Response{data=response.PageData{list=[]model.AgreementConsentVO}}
+// @Router /api/admin/agreements/consents [get]
+func (h *AgreementHandler) ListConsents(c *gin.Context) {
+	var req service.ListAgreementConsentsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	list, total, err := h.service.ListConsents(&req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithPage(c, list, total, req.Page, req.PageSize)
+}
+
+// ExportConsents 导出协议同意记录
+// @Summary 导出协议同意记录
+// @Description 按筛选条件导出协议同意/撤回记录（CSV，审计用）
+// @Tags 协议管理（后台）
+// @Produce text/csv
+// @Security BearerAdmin
+// @Param type query string false "协议类型"
+// @Param version query string false "版本号"
+// @Param user_id query int false "用户ID"
+// @Param patient_id query int false "就诊人ID"
+// @Param start_date query string false "开始日期 YYYY-MM-DD"
+// @Param end_date query string false "结束日期 YYYY-MM-DD"
+// @Success 200 {file} file
+// @Router /api/admin/agreements/consents/export [get]
+func (h *AgreementHandler) ExportConsents(c *gin.Context) {
+	var req service.ExportAgreementConsentsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	list, err := h.service.ExportConsents(&req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	filename := fmt.Sprintf("agreement_consents_%s.csv", time.Now().Format("20060102150405"))
+	c.Writer.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	c.Writer.Header().Set("Content-Disposition", "attachment; filename="+filename)
+
+	// 写入BOM头（让Excel正确识别UTF-8）
+	c.Writer.Write([]byte{0xEF, 0xBB, 0xBF})
+
+	// UA等字段可能含逗号，使用 csv.Writer 转义
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{"记录ID", "协议类型", "版本号", "动作", "用户ID", "昵称", "手机号", "就诊人ID", "就诊人", "IP地址", "客户端", "操作时间"})
+	for _, item := range list {
+		_ = w.Write([]string{
+			strconv.FormatInt(item.ID, 10),
+			item.TypeName,
+			item.Version,
+			item.ActionName,
+			strconv.FormatInt(item.UserID, 10),
+			item.Nickname,
+			item.Phone,
+			strconv.FormatInt(item.PatientID, 10),
+			item.PatientName,
+			item.IP,
+			item.UserAgent,
+			item.CreatedAt,
+		})
+	}
+	w.Flush()
+}
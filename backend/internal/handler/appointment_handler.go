@@ -14,13 +14,15 @@ import (
 
 // AppointmentHandler 预约处理器
 type AppointmentHandler struct {
-	service *service.AppointmentService
+	service       *service.AppointmentService
+	campusService *service.CampusService
 }
 
 // NewAppointmentHandler 创建预约处理器实例
 func NewAppointmentHandler() *AppointmentHandler {
 	return &AppointmentHandler{
-		service: service.NewAppointmentService(),
+		service:       service.NewAppointmentService(),
+		campusService: service.NewCampusService(),
 	}
 }
 
@@ -139,6 +141,11 @@ func (h *AppointmentHandler) GetByIDAdmin(c *gin.Context) {
 		return
 	}
 
+	if err := h.campusService.AuthorizeAppointment(middleware.GetAdminCampusScope(c), id); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
 	appointment, err := h.service.GetByIDAdmin(id)
 	if err != nil {
 		response.FailWithError(c, err)
@@ -228,6 +235,7 @@ func (h *AppointmentHandler) Checkin(c *gin.Context) {
 // @Security BearerAdmin
 // @Param page query int true "页码" minimum(1)
 // @Param page_size query int true "每页数量" minimum(1) maximum(100)
+// @Param campus_id query int false "院区ID"
 // @Param start_date query string false "开始日期（YYYY-MM-DD）"
 // @Param end_date query string false "结束日期（YYYY-MM-DD）"
 // @Param status query string false "预约状态"
@@ -240,6 +248,9 @@ func (h *AppointmentHandler) ListAdmin(c *gin.Context) {
 		response.Fail(c, errorcode.ErrBindJSON)
 		return
 	}
+	if scope := middleware.GetAdminCampusScope(c); scope > 0 {
+		req.CampusID = &scope
+	}
 
 	appointments, total, err := h.service.List(&req)
 	if err != nil {
@@ -250,6 +261,36 @@ func (h *AppointmentHandler) ListAdmin(c *gin.Context) {
 	response.SuccessWithPage(c, appointments, total, req.Page, req.PageSize)
 }
 
+// CreateByAdmin 渠道挂号（管理后台）
+// @Summary 渠道挂号（管理后台）
+// @Description 现场挂号/院内安排，从对应渠道的预留号源扣减
+// @Tags 预约管理
+// @Accept json
+// @Produce json
+// @Security BearerAdmin
+// @Param request body service.CreateByAdminRequest true "挂号信息"
+// @Success 200 {object} response.Response{data=model.AppointmentVO}
+// @Router /api/admin/appointments [post]
+func (h *AppointmentHandler) CreateByAdmin(c *gin.Context) {
+	var req service.CreateByAdminRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+	if err := h.campusService.AuthorizeSchedule(middleware.GetAdminCampusScope(c), req.ScheduleID); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	appointment, err := h.service.CreateByAdmin(&req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, appointment)
+}
+
 // UpdateStatus 更新预约状态（管理后台）
 // @Summary 更新预约状态（管理后台）
 // @Description 管理员更新预约状态
@@ -274,6 +315,10 @@ func (h *AppointmentHandler) UpdateStatus(c *gin.Context) {
 		response.Fail(c, errorcode.ErrBindJSON)
 		return
 	}
+	if err := h.campusService.AuthorizeAppointment(middleware.GetAdminCampusScope(c), id); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
 
 	if err := h.service.UpdateStatus(id, &req); err != nil {
 		response.FailWithError(c, err)
@@ -290,6 +335,7 @@ func (h *AppointmentHandler) UpdateStatus(c *gin.Context) {
 // @Accept json
 // @Produce text/csv
 // @Security BearerAdmin
+// @Param campus_id query int false "院区ID"
 // @Param start_date query string false "开始日期（YYYY-MM-DD）"
 // @Param end_date query string false "结束日期（YYYY-MM-DD）"
 // @Param status query string false "预约状态"
@@ -308,6 +354,12 @@ func (h *AppointmentHandler) ExportAppointments(c *gin.Context) {
 		EndDate:   endDate,
 		Status:    func() *string { if status != "" { return &status }; return nil }(),
 	}
+	if campusID, err := strconv.ParseInt(c.Query("campus_id"), 10, 64); err == nil && campusID > 0 {
+		req.CampusID = &campusID
+	}
+	if scope := middleware.GetAdminCampusScope(c); scope > 0 {
+		req.CampusID = &scope
+	}
 
 	appointments, _, err := h.service.List(&req)
 	if err != nil {
@@ -323,14 +375,15 @@ func (h *AppointmentHandler) ExportAppointments(c *gin.Context) {
 	c.Writer.Write([]byte{0xEF, 0xBB, 0xBF})
 
 	// 写入CSV标题
-	c.Writer.Write([]byte("预约编号,患者姓名,医生姓名,科室名称,预约日期,时段,号序,状态,创建时间\n"))
+	c.Writer.Write([]byte("预约编号,患者姓名,医生姓名,院区,科室名称,预约日期,时段,号序,状态,创建时间\n"))
 
 	// 写入数据
 	for _, apt := range appointments {
-		line := fmt.Sprintf("%s,%s,%s,%s,%s,%s,%d,%s,%s\n",
+		line := fmt.Sprintf("%s,%s,%s,%s,%s,%s,%s,%d,%s,%s\n",
 			apt.AppointmentNo,
 			apt.PatientName,
 			apt.DoctorName,
+			apt.CampusName,
 			apt.DepartmentName,
 			apt.AppointmentDate,
 			apt.PeriodName,
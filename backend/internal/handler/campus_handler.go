@@ -0,0 +1,373 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"huaan-medical/internal/middleware"
+	"huaan-medical/internal/model"
+	"huaan-medical/internal/service"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/response"
+)
+
+// CampusHandler 院区处理器（含诊室管理）
+type CampusHandler struct {
+	service *service.CampusService
+}
+
+// NewCampusHandler 创建院区处理器实例
+func NewCampusHandler() *CampusHandler {
+	return &CampusHandler{
+		service: service.NewCampusService(),
+	}
+}
+
+// ListPublic 院区列表（公开接口）
+// @Summary 院区列表
+// @Description 获取所有启用的院区，含地址、坐标、电话及开放时间
+// @Tags 院区
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=[]model.CampusVO}
+// @Router /api/campuses [get]
+func (h *CampusHandler) ListPublic(c *gin.Context) {
+	list, err := h.service.ListPublic()
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// List 院区列表（管理后台）
+// @Summary 院区列表
+// @Description 分页查询院区，院区管理员仅返回其所在院区
+// @Tags 院区管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page query int true "页码"
+// @Param page_size query int true "每页数量"
+// @Param keyword query string false "名称/地址"
+// @Param status query int false "状态 0停用 1启用"
+// @Success 200 {object} response.Response{data=response.PageData{list=[]model.CampusVO}}
+// @Router /api/admin/campuses [get]
+func (h *CampusHandler) List(c *gin.Context) {
+	var req service.ListCampusRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, errorcode.ErrInvalidPageParams)
+		return
+	}
+
+	if scope := middleware.GetAdminCampusScope(c); scope > 0 {
+		campus, err := h.service.GetByID(scope)
+		if err != nil {
+			response.FailWithError(c, err)
+			return
+		}
+		response.SuccessWithPage(c, []model.CampusVO{*campus}, 1, req.Page, req.PageSize)
+		return
+	}
+
+	list, total, err := h.service.List(&req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithPage(c, list, total, req.Page, req.PageSize)
+}
+
+// GetByID 院区详情
+// @Summary 院区详情
+// @Description 获取院区详情
+// @Tags 院区管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "院区ID"
+// @Success 200 {object} response.Response{data=model.CampusVO}
+// @Router /api/admin/campuses/{id} [get]
+func (h *CampusHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+	if err := h.service.AuthorizeCampus(middleware.GetAdminCampusScope(c), id); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	campus, err := h.service.GetByID(id)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, campus)
+}
+
+// Create 创建院区
+// @Summary 创建院区
+// @Description 创建院区（仅全院管理员）
+// @Tags 院区管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.CreateCampusRequest true "院区信息"
+// @Success 200 {object} response.Response{data=model.CampusVO}
+// @Router /api/admin/campuses [post]
+func (h *CampusHandler) Create(c *gin.Context) {
+	var req service.CreateCampusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+	if err := h.service.AuthorizeCampus(middleware.GetAdminCampusScope(c), 0); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	campus, err := h.service.Create(&req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, campus)
+}
+
+// Update 更新院区
+// @Summary 更新院区
+// @Description 更新院区信息
+// @Tags 院区管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "院区ID"
+// @Param request body service.UpdateCampusRequest true "院区信息"
+// @Success 200 {object} response.Response{data=model.CampusVO}
+// @Router /api/admin/campuses/{id} [put]
+func (h *CampusHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.UpdateCampusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+	if err := h.service.AuthorizeCampus(middleware.GetAdminCampusScope(c), id); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	campus, err := h.service.Update(id, &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, campus)
+}
+
+// Delete 删除院区
+// @Summary 删除院区
+// @Description 删除院区（院区下仍有科室或诊室时不可删除，仅全院管理员）
+// @Tags 院区管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "院区ID"
+// @Success 200 {object} response.Response
+// @Router /api/admin/campuses/{id} [delete]
+func (h *CampusHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+	if err := h.service.AuthorizeCampus(middleware.GetAdminCampusScope(c), 0); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "删除成功", nil)
+}
+
+// ListRooms 诊室列表
+// @Summary 诊室列表
+// @Description 分页查询诊室，院区管理员仅返回其所在院区的诊室
+// @Tags 院区管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page query int true "页码"
+// @Param page_size query int true "每页数量"
+// @Param campus_id query int false "院区ID"
+// @Param department_id query int false "科室ID"
+// @Param keyword query string false "名称/位置"
+// @Param status query int false "状态 0停用 1启用"
+// @Success 200 {object} response.Response{data=response.PageData{list=[]model.RoomVO}}
+// @Router /api/admin/rooms [get]
+func (h *CampusHandler) ListRooms(c *gin.Context) {
+	var req service.ListRoomRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, errorcode.ErrInvalidPageParams)
+		return
+	}
+	if scope := middleware.GetAdminCampusScope(c); scope > 0 {
+		req.CampusID = &scope
+	}
+
+	list, total, err := h.service.ListRooms(&req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithPage(c, list, total, req.Page, req.PageSize)
+}
+
+// GetRoom 诊室详情
+// @Summary 诊室详情
+// @Description 获取诊室详情
+// @Tags 院区管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "诊室ID"
+// @Success 200 {object} response.Response{data=model.RoomVO}
+// @Router /api/admin/rooms/{id} [get]
+func (h *CampusHandler) GetRoom(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+	if err := h.service.AuthorizeRoom(middleware.GetAdminCampusScope(c), id); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	room, err := h.service.GetRoom(id)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, room)
+}
+
+// CreateRoom 创建诊室
+// @Summary 创建诊室
+// @Description 在院区下创建诊室，可指定所属科室
+// @Tags 院区管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.CreateRoomRequest true "诊室信息"
+// @Success 200 {object} response.Response{data=model.RoomVO}
+// @Router /api/admin/rooms [post]
+func (h *CampusHandler) CreateRoom(c *gin.Context) {
+	var req service.CreateRoomRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+	if err := h.service.AuthorizeCampus(middleware.GetAdminCampusScope(c), req.CampusID); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	room, err := h.service.CreateRoom(&req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, room)
+}
+
+// UpdateRoom 更新诊室
+// @Summary 更新诊室
+// @Description 更新诊室信息（仍有排班的诊室不能变更院区）
+// @Tags 院区管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "诊室ID"
+// @Param request body service.UpdateRoomRequest true "诊室信息"
+// @Success 200 {object} response.Response{data=model.RoomVO}
+// @Router /api/admin/rooms/{id} [put]
+func (h *CampusHandler) UpdateRoom(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.UpdateRoomRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+	scope := middleware.GetAdminCampusScope(c)
+	if err := h.service.AuthorizeRoom(scope, id); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+	if err := h.service.AuthorizeCampus(scope, req.CampusID); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	room, err := h.service.UpdateRoom(id, &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, room)
+}
+
+// DeleteRoom 删除诊室
+// @Summary 删除诊室
+// @Description 删除诊室（仍有排班时不可删除）
+// @Tags 院区管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "诊室ID"
+// @Success 200 {object} response.Response
+// @Router /api/admin/rooms/{id} [delete]
+func (h *CampusHandler) DeleteRoom(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+	if err := h.service.AuthorizeRoom(middleware.GetAdminCampusScope(c), id); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	if err := h.service.DeleteRoom(id); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "删除成功", nil)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"huaan-medical/internal/middleware"
 	"huaan-medical/internal/service"
 	"huaan-medical/pkg/errorcode"
 	"huaan-medical/pkg/response"
@@ -12,13 +13,15 @@ import (
 
 // DepartmentHandler 科室处理器
 type DepartmentHandler struct {
-	service *service.DepartmentService
+	service       *service.DepartmentService
+	campusService *service.CampusService
 }
 
 // NewDepartmentHandler 创建科室处理器实例
 func NewDepartmentHandler() *DepartmentHandler {
 	return &DepartmentHandler{
-		service: service.NewDepartmentService(),
+		service:       service.NewDepartmentService(),
+		campusService: service.NewCampusService(),
 	}
 }
 
@@ -31,6 +34,7 @@ func NewDepartmentHandler() *DepartmentHandler {
 // @Security Bearer
 // @Param page query int true "页码"
 // @Param page_size query int true "每页数量"
+// @Param campus_id query int false "院区ID"
 // @Param status query int false "状态筛选"
 // @Success 200 {object} response.Response{data=response.PageData}
 // @Router /api/admin/departments [get]
@@ -40,6 +44,9 @@ func (h *DepartmentHandler) List(c *gin.Context) {
 		response.Fail(c, errorcode.ErrInvalidPageParams)
 		return
 	}
+	if scope := middleware.GetAdminCampusScope(c); scope > 0 {
+		req.CampusID = &scope
+	}
 
 	list, total, err := h.service.List(&req)
 	if err != nil {
@@ -52,14 +59,22 @@ func (h *DepartmentHandler) List(c *gin.Context) {
 
 // ListAll 科室列表（公开接口）
 // @Summary 获取所有科室
-// @Description 获取所有启用的科室列表（公开接口）
+// @Description 获取所有启用的科室列表（公开接口），可按院区筛选，登录后可指定就诊人仅返回其符合年龄/性别接诊限制的科室
 // @Tags 科室
 // @Accept json
 // @Produce json
+// @Param campus_id query int false "院区ID"
+// @Param patient_id query int false "就诊人ID（需登录）"
 // @Success 200 {object} response.Response{data=[]model.DepartmentVO}
 // @Router /api/departments [get]
 func (h *DepartmentHandler) ListAll(c *gin.Context) {
-	list, err := h.service.ListAll()
+	var req service.ListPublicDepartmentRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, errorcode.ErrInvalidParams)
+		return
+	}
+
+	list, err := h.service.ListAll(middleware.GetUserID(c), &req)
 	if err != nil {
 		response.FailWithError(c, err)
 		return
@@ -68,6 +83,65 @@ func (h *DepartmentHandler) ListAll(c *gin.Context) {
 	response.Success(c, list)
 }
 
+// Tree 科室树（公开接口）
+// @Summary 获取科室树
+// @Description 获取启用科室的树形结构（停用科室及其下级不返回），可按院区筛选，登录后可指定就诊人仅返回其符合接诊限制的科室
+// @Tags 科室
+// @Accept json
+// @Produce json
+// @Param campus_id query int false "院区ID"
+// @Param patient_id query int false "就诊人ID（需登录）"
+// @Success 200 {object} response.Response{data=[]model.DepartmentVO}
+// @Router /api/departments/tree [get]
+func (h *DepartmentHandler) Tree(c *gin.Context) {
+	var req service.ListPublicDepartmentRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, errorcode.ErrInvalidParams)
+		return
+	}
+
+	tree, err := h.service.TreePublic(middleware.GetUserID(c), &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, tree)
+}
+
+// TreeAdmin 科室树（管理后台）
+// @Summary 科室树
+// @Description 获取全部科室（含停用）的树形结构
+// @Tags 科室管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param campus_id query int false "院区ID"
+// @Success 200 {object} response.Response{data=[]model.DepartmentVO}
+// @Router /api/admin/departments/tree [get]
+func (h *DepartmentHandler) TreeAdmin(c *gin.Context) {
+	var campusID *int64
+	if value := c.Query("campus_id"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			response.Fail(c, errorcode.ErrInvalidParams)
+			return
+		}
+		campusID = &id
+	}
+	if scope := middleware.GetAdminCampusScope(c); scope > 0 {
+		campusID = &scope
+	}
+
+	tree, err := h.service.Tree(false, campusID)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, tree)
+}
+
 // GetByID 获取科室详情
 // @Summary 获取科室详情
 // @Description 根据ID获取科室详情
@@ -84,6 +158,10 @@ func (h *DepartmentHandler) GetByID(c *gin.Context) {
 		response.Fail(c, errorcode.ErrInvalidIDFormat)
 		return
 	}
+	if err := h.campusService.AuthorizeDepartment(middleware.GetAdminCampusScope(c), id); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
 
 	dept, err := h.service.GetByID(id)
 	if err != nil {
@@ -105,11 +183,22 @@ func (h *DepartmentHandler) GetByID(c *gin.Context) {
 // @Success 200 {object} response.Response{data=model.DepartmentVO}
 // @Router /api/admin/departments [post]
 func (h *DepartmentHandler) Create(c *gin.Context) {
+	var err error
 	var req service.CreateDepartmentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		response.Fail(c, errorcode.ErrBindJSON)
 		return
 	}
+	scope := middleware.GetAdminCampusScope(c)
+	if req.ParentID > 0 {
+		err = h.campusService.AuthorizeDepartment(scope, req.ParentID)
+	} else {
+		err = h.campusService.AuthorizeCampus(scope, req.CampusID)
+	}
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
 
 	dept, err := h.service.Create(&req)
 	if err != nil {
@@ -143,6 +232,17 @@ func (h *DepartmentHandler) Update(c *gin.Context) {
 		response.Fail(c, errorcode.ErrBindJSON)
 		return
 	}
+	scope := middleware.GetAdminCampusScope(c)
+	if err := h.campusService.AuthorizeDepartment(scope, id); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+	if req.CampusID != nil {
+		if err := h.campusService.AuthorizeCampus(scope, *req.CampusID); err != nil {
+			response.FailWithError(c, err)
+			return
+		}
+	}
 
 	dept, err := h.service.Update(id, &req)
 	if err != nil {
@@ -153,6 +253,43 @@ func (h *DepartmentHandler) Update(c *gin.Context) {
 	response.Success(c, dept)
 }
 
+// Move 移动科室
+// @Summary 移动科室
+// @Description 调整科室的上级科室及同级排序（不能移动到自身或下级科室下）
+// @Tags 科室管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "科室ID"
+// @Param request body service.MoveDepartmentRequest true "目标位置"
+// @Success 200 {object} response.Response{data=model.DepartmentVO}
+// @Router /api/admin/departments/{id}/move [put]
+func (h *DepartmentHandler) Move(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.MoveDepartmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+	if err := h.campusService.AuthorizeDepartment(middleware.GetAdminCampusScope(c), id); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	dept, err := h.service.Move(id, &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, dept)
+}
+
 // Delete 删除科室
 // @Summary 删除科室
 // @Description 删除科室（软删除）
@@ -169,6 +306,10 @@ func (h *DepartmentHandler) Delete(c *gin.Context) {
 		response.Fail(c, errorcode.ErrInvalidIDFormat)
 		return
 	}
+	if err := h.campusService.AuthorizeDepartment(middleware.GetAdminCampusScope(c), id); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
 
 	if err := h.service.Delete(id); err != nil {
 		response.FailWithError(c, err)
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"huaan-medical/internal/service"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/response"
+)
+
+// DoctorAccountHandler 医生账号管理处理器
+type DoctorAccountHandler struct {
+	service *service.DoctorAccountService
+}
+
+// NewDoctorAccountHandler 创建医生账号管理处理器实例
+func NewDoctorAccountHandler() *DoctorAccountHandler {
+	return &DoctorAccountHandler{
+		service: service.NewDoctorAccountService(),
+	}
+}
+
+// List 医生账号列表
+// @Summary 医生账号列表
+// @Description 分页查询医生工作台账号
+// @Tags 医生账号管理
+// @Accept json
+// @Produce json
+// @Security BearerAdmin
+// @Param page query int true "页码"
+// @Param page_size query int true "每页数量"
+// @Param keyword query string false "关键词(用户名/手机号/医生姓名)"
+// @Param status query int false "状态 0禁用 1启用"
+// @Success 200 {object} response.Response{data=response.PageData{list=[]model.DoctorAccountVO}}
+// @Router /api/admin/doctor-accounts [get]
+func (h *DoctorAccountHandler) List(c *gin.Context) {
+	var req service.ListDoctorAccountRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, errorcode.ErrInvalidPageParams)
+		return
+	}
+
+	list, total, err := h.service.List(&req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithPage(c, list, total, req.Page, req.PageSize)
+}
+
+// Create 开通医生账号
+// @Summary 开通医生账号
+// @Description 为医生开通工作台登录账号，一名医生仅能开通一个账号
+// @Tags 医生账号管理
+// @Accept json
+// @Produce json
+// @Security BearerAdmin
+// @Param request body service.CreateDoctorAccountRequest true "账号信息"
+// @Success 200 {object} response.Response{data=model.DoctorAccountVO}
+// @Router /api/admin/doctor-accounts [post]
+func (h *DoctorAccountHandler) Create(c *gin.Context) {
+	var req service.CreateDoctorAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	account, err := h.service.Create(&req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, account)
+}
+
+// Update 更新医生账号
+// @Summary 更新医生账号
+// @Description 更新医生账号手机号或启用状态
+// @Tags 医生账号管理
+// @Accept json
+// @Produce json
+// @Security BearerAdmin
+// @Param id path int true "账号ID"
+// @Param request body service.UpdateDoctorAccountRequest true "账号信息"
+// @Success 200 {object} response.Response{data=model.DoctorAccountVO}
+// @Router /api/admin/doctor-accounts/{id} [put]
+func (h *DoctorAccountHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.UpdateDoctorAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	account, err := h.service.Update(id, &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, account)
+}
+
+// ResetPassword 重置医生账号密码
+// @Summary 重置医生账号密码
+// @Description 重置医生工作台登录密码
+// @Tags 医生账号管理
+// @Accept json
+// @Produce json
+// @Security BearerAdmin
+// @Param id path int true "账号ID"
+// @Param request body service.ResetAdminPasswordRequest true "密码信息"
+// @Success 200 {object} response.Response
+// @Router /api/admin/doctor-accounts/{id}/password [put]
+func (h *DoctorAccountHandler) ResetPassword(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.ResetAdminPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	if err := h.service.ResetPassword(id, &req); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "密码重置成功", nil)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"huaan-medical/internal/middleware"
 	"huaan-medical/internal/service"
 	"huaan-medical/pkg/errorcode"
 	"huaan-medical/pkg/response"
@@ -32,6 +33,7 @@ func NewDoctorHandler() *DoctorHandler {
 // @Param page query int true "页码"
 // @Param page_size query int true "每页数量"
 // @Param department_id query int false "科室ID筛选"
+// @Param tag_id query int false "擅长领域标签ID"
 // @Param status query int false "状态筛选"
 // @Param keyword query string false "关键词搜索"
 // @Success 200 {object} response.Response{data=response.PageData}
@@ -54,12 +56,15 @@ func (h *DoctorHandler) List(c *gin.Context) {
 
 // ListPublic 医生列表（公开接口）
 // @Summary 获取医生列表
-// @Description 获取启用的医生列表（公开接口）
+// @Description 获取启用的医生列表（公开接口），登录后可指定就诊人仅返回其符合科室接诊限制的医生
 // @Tags 医生
 // @Accept json
 // @Produce json
 // @Param department_id query int false "科室ID筛选"
 // @Param keyword query string false "关键词搜索"
+// @Param campus_id query int false "院区ID"
+// @Param tag_id query int false "擅长领域标签ID"
+// @Param patient_id query int false "就诊人ID（需登录）"
 // @Success 200 {object} response.Response{data=[]model.DoctorListVO}
 // @Router /api/doctors [get]
 func (h *DoctorHandler) ListPublic(c *gin.Context) {
@@ -69,7 +74,7 @@ func (h *DoctorHandler) ListPublic(c *gin.Context) {
 		return
 	}
 
-	list, err := h.service.ListPublic(&req)
+	list, err := h.service.ListPublic(middleware.GetUserID(c), &req)
 	if err != nil {
 		response.FailWithError(c, err)
 		return
@@ -106,7 +111,7 @@ func (h *DoctorHandler) GetByID(c *gin.Context) {
 
 // GetByIDPublic 获取医生详情（公开接口）
 // @Summary 获取医生详情
-// @Description 根据ID获取医生详情（公开接口）
+// @Description 根据ID获取医生详情（公开接口，含评分汇总、擅长领域标签及结构化资料）
 // @Tags 医生
 // @Accept json
 // @Produce json
@@ -120,7 +125,7 @@ func (h *DoctorHandler) GetByIDPublic(c *gin.Context) {
 		return
 	}
 
-	doctor, err := h.service.GetByID(id)
+	doctor, err := h.service.GetByIDPublic(id)
 	if err != nil {
 		response.FailWithError(c, err)
 		return
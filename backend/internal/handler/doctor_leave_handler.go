@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"huaan-medical/internal/middleware"
+	"huaan-medical/internal/service"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/response"
+)
+
+// DoctorLeaveHandler 停诊申请管理处理器
+type DoctorLeaveHandler struct {
+	service *service.DoctorLeaveService
+}
+
+// NewDoctorLeaveHandler 创建停诊申请管理处理器实例
+func NewDoctorLeaveHandler() *DoctorLeaveHandler {
+	return &DoctorLeaveHandler{
+		service: service.NewDoctorLeaveService(),
+	}
+}
+
+// List 停诊申请列表
+// @Summary 停诊申请列表
+// @Description 分页查询停诊申请，可按状态/医生/科室筛选
+// @Tags 停诊管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page query int true "页码"
+// @Param page_size query int true "每页数量"
+// @Param status query string false "状态 pending/approved/rejected/cancelled"
+// @Param doctor_id query int false "医生ID"
+// @Param department_id query int false "科室ID"
+// @Success 200 {object} response.Response{data=response.PageData{list=[]model.DoctorLeaveVO}}
+// @Router /api/admin/doctor-leaves [get]
+func (h *DoctorLeaveHandler) List(c *gin.Context) {
+	var req service.ListDoctorLeaveRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, errorcode.ErrInvalidPageParams)
+		return
+	}
+
+	list, total, err := h.service.List(&req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithPage(c, list, total, req.Page, req.PageSize)
+}
+
+// GetByID 停诊申请详情
+// @Summary 停诊申请详情
+// @Description 获取停诊申请详情
+// @Tags 停诊管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "申请ID"
+// @Success 200 {object} response.Response{data=model.DoctorLeaveVO}
+// @Router /api/admin/doctor-leaves/{id} [get]
+func (h *DoctorLeaveHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	leave, err := h.service.GetByID(id)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, leave)
+}
+
+// Review 审批停诊申请
+// @Summary 审批停诊申请
+// @Description 审批通过后停诊期间的排班置为停诊，待就诊预约自动取消并通知患者
+// @Tags 停诊管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "申请ID"
+// @Param request body service.ReviewDoctorLeaveRequest true "审批信息"
+// @Success 200 {object} response.Response
+// @Router /api/admin/doctor-leaves/{id}/review [put]
+func (h *DoctorLeaveHandler) Review(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.ReviewDoctorLeaveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	if err := h.service.Review(id, middleware.GetAdminID(c), &req); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "审批成功", nil)
+}
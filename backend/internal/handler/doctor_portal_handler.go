@@ -0,0 +1,752 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"huaan-medical/internal/middleware"
+	"huaan-medical/internal/service"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/response"
+)
+
+// DoctorPortalHandler 医生工作台处理器
+// 医生ID均取自医生Token，只能查看和操作本人数据
+type DoctorPortalHandler struct {
+	service       *service.DoctorPortalService
+	leaveService  *service.DoctorLeaveService
+	swapService   *service.ScheduleSwapService
+	recordService *service.MedicalRecordService
+	reviewService *service.DoctorReviewService
+}
+
+// NewDoctorPortalHandler 创建医生工作台处理器实例
+func NewDoctorPortalHandler() *DoctorPortalHandler {
+	return &DoctorPortalHandler{
+		service:       service.NewDoctorPortalService(),
+		leaveService:  service.NewDoctorLeaveService(),
+		swapService:   service.NewScheduleSwapService(),
+		recordService: service.NewMedicalRecordService(),
+		reviewService: service.NewDoctorReviewService(),
+	}
+}
+
+// Login 医生登录
+// @Summary 医生登录
+// @Description 医生工作台账号密码登录
+// @Tags 医生工作台
+// @Accept json
+// @Produce json
+// @Param request body service.LoginRequest true "登录信息"
+// @Success 200 {object} response.Response{data=service.DoctorLoginResponse}
+// @Router /api/doctor/login [post]
+func (h *DoctorPortalHandler) Login(c *gin.Context) {
+	var req service.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	result, err := h.service.Login(&req, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// GetInfo 获取当前医生信息
+// @Summary 获取当前医生信息
+// @Description 获取当前登录医生的账号及执业信息
+// @Tags 医生工作台
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response{data=service.DoctorInfoResponse}
+// @Router /api/doctor/info [get]
+func (h *DoctorPortalHandler) GetInfo(c *gin.Context) {
+	info, err := h.service.GetInfo(middleware.GetDoctorAccountID(c))
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, info)
+}
+
+// ChangePassword 修改密码
+// @Summary 修改密码
+// @Description 修改当前医生账号密码
+// @Tags 医生工作台
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.ChangePasswordRequest true "密码信息"
+// @Success 200 {object} response.Response
+// @Router /api/doctor/password [put]
+func (h *DoctorPortalHandler) ChangePassword(c *gin.Context) {
+	var req service.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	if err := h.service.ChangePassword(middleware.GetDoctorAccountID(c), &req); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "密码修改成功", nil)
+}
+
+// ListSchedules 本人排班
+// @Summary 本人排班
+// @Description 查询本人排班（含停诊排班），默认今天起14天
+// @Tags 医生工作台
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param start_date query string false "开始日期 YYYY-MM-DD"
+// @Param end_date query string false "结束日期 YYYY-MM-DD"
+// @Success 200 {object} response.Response{data=[]model.ScheduleVO}
+// @Router /api/doctor/schedules [get]
+func (h *DoctorPortalHandler) ListSchedules(c *gin.Context) {
+	var req service.ListDoctorScheduleRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, errorcode.ErrInvalidParams)
+		return
+	}
+
+	list, err := h.service.ListSchedules(middleware.GetDoctorID(c), &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// ListAppointments 本人预约患者
+// @Summary 本人预约患者
+// @Description 分页查询本人的预约患者及症状描述，默认今天及以后
+// @Tags 医生工作台
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page query int true "页码"
+// @Param page_size query int true "每页数量"
+// @Param start_date query string false "开始日期 YYYY-MM-DD"
+// @Param end_date query string false "结束日期 YYYY-MM-DD"
+// @Param schedule_id query int false "排班ID"
+// @Param status query string false "状态"
+// @Success 200 {object} response.Response{data=response.PageData{list=[]model.DoctorAppointmentVO}}
+// @Router /api/doctor/appointments [get]
+func (h *DoctorPortalHandler) ListAppointments(c *gin.Context) {
+	var req service.ListDoctorAppointmentRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, errorcode.ErrInvalidPageParams)
+		return
+	}
+
+	list, total, err := h.service.ListAppointments(middleware.GetDoctorID(c), &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithPage(c, list, total, req.Page, req.PageSize)
+}
+
+// GetAppointment 预约详情
+// @Summary 预约详情
+// @Description 获取本人的预约详情
+// @Tags 医生工作台
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "预约ID"
+// @Success 200 {object} response.Response{data=model.DoctorAppointmentVO}
+// @Router /api/doctor/appointments/{id} [get]
+func (h *DoctorPortalHandler) GetAppointment(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	appointment, err := h.service.GetAppointment(middleware.GetDoctorID(c), id)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, appointment)
+}
+
+// CheckinAppointment 患者签到
+// @Summary 患者签到
+// @Description 为当天就诊的患者签到
+// @Tags 医生工作台
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "预约ID"
+// @Success 200 {object} response.Response
+// @Router /api/doctor/appointments/{id}/checkin [put]
+func (h *DoctorPortalHandler) CheckinAppointment(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	if err := h.service.Checkin(middleware.GetDoctorID(c), id); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "签到成功", nil)
+}
+
+// CompleteAppointment 完成接诊
+// @Summary 完成接诊
+// @Description 将已签到的预约标记为已完成
+// @Tags 医生工作台
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "预约ID"
+// @Success 200 {object} response.Response
+// @Router /api/doctor/appointments/{id}/complete [put]
+func (h *DoctorPortalHandler) CompleteAppointment(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	if err := h.service.Complete(middleware.GetDoctorID(c), id); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "接诊完成", nil)
+}
+
+// MarkAppointmentMissed 标记爽约
+// @Summary 标记爽约
+// @Description 就诊时间过后将未到诊的预约标记为爽约
+// @Tags 医生工作台
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "预约ID"
+// @Success 200 {object} response.Response
+// @Router /api/doctor/appointments/{id}/missed [put]
+func (h *DoctorPortalHandler) MarkAppointmentMissed(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	if err := h.service.MarkMissed(middleware.GetDoctorID(c), id); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "已标记爽约", nil)
+}
+
+// ListLeaves 本人停诊申请
+// @Summary 本人停诊申请
+// @Description 分页查询本人的停诊申请
+// @Tags 医生工作台
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page query int true "页码"
+// @Param page_size query int true "每页数量"
+// @Param status query string false "状态 pending/approved/rejected/cancelled"
+// @Success 200 {object} response.Response{data=response.PageData{list=[]model.DoctorLeaveVO}}
+// @Router /api/doctor/leaves [get]
+func (h *DoctorPortalHandler) ListLeaves(c *gin.Context) {
+	var req service.ListDoctorLeaveRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, errorcode.ErrInvalidPageParams)
+		return
+	}
+
+	// 限定本人
+	doctorID := middleware.GetDoctorID(c)
+	req.DoctorID = &doctorID
+	req.DepartmentID = nil
+
+	list, total, err := h.leaveService.List(&req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithPage(c, list, total, req.Page, req.PageSize)
+}
+
+// GetLeave 停诊申请详情
+// @Summary 停诊申请详情
+// @Description 获取本人的停诊申请详情
+// @Tags 医生工作台
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "申请ID"
+// @Success 200 {object} response.Response{data=model.DoctorLeaveVO}
+// @Router /api/doctor/leaves/{id} [get]
+func (h *DoctorPortalHandler) GetLeave(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	leave, err := h.leaveService.GetByIDForDoctor(id, middleware.GetDoctorID(c))
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, leave)
+}
+
+// CreateLeave 申请停诊
+// @Summary 申请停诊
+// @Description 提交停诊申请，审批通过后相关排班停诊并通知已预约患者
+// @Tags 医生工作台
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.CreateDoctorLeaveRequest true "停诊信息"
+// @Success 200 {object} response.Response{data=model.DoctorLeaveVO}
+// @Router /api/doctor/leaves [post]
+func (h *DoctorPortalHandler) CreateLeave(c *gin.Context) {
+	var req service.CreateDoctorLeaveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	leave, err := h.leaveService.Create(middleware.GetDoctorID(c), &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, leave)
+}
+
+// CancelLeave 撤销停诊申请
+// @Summary 撤销停诊申请
+// @Description 审批前撤销本人的停诊申请
+// @Tags 医生工作台
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "申请ID"
+// @Success 200 {object} response.Response
+// @Router /api/doctor/leaves/{id}/cancel [put]
+func (h *DoctorPortalHandler) CancelLeave(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	if err := h.leaveService.Cancel(id, middleware.GetDoctorID(c)); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "撤销成功", nil)
+}
+
+// ListSwaps 本人换班申请
+// @Summary 本人换班申请
+// @Description 分页查询本人发起或收到的换班申请
+// @Tags 医生工作台
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page query int true "页码"
+// @Param page_size query int true "每页数量"
+// @Param status query string false "状态 pending/accepted/approved/rejected/declined/cancelled"
+// @Success 200 {object} response.Response{data=response.PageData{list=[]model.ScheduleSwapVO}}
+// @Router /api/doctor/schedule-swaps [get]
+func (h *DoctorPortalHandler) ListSwaps(c *gin.Context) {
+	var req service.ListScheduleSwapRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, errorcode.ErrInvalidPageParams)
+		return
+	}
+
+	// 限定本人
+	doctorID := middleware.GetDoctorID(c)
+	req.DoctorID = &doctorID
+	req.DepartmentID = nil
+
+	list, total, err := h.swapService.List(&req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithPage(c, list, total, req.Page, req.PageSize)
+}
+
+// GetSwap 换班申请详情
+// @Summary 换班申请详情
+// @Description 获取本人发起或收到的换班申请详情
+// @Tags 医生工作台
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "申请ID"
+// @Success 200 {object} response.Response{data=model.ScheduleSwapVO}
+// @Router /api/doctor/schedule-swaps/{id} [get]
+func (h *DoctorPortalHandler) GetSwap(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	swap, err := h.swapService.GetByIDForDoctor(id, middleware.GetDoctorID(c))
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, swap)
+}
+
+// CreateSwap 发起换班申请
+// @Summary 发起换班申请
+// @Description 将本人排班转给其他医生，可同时换入对方的一个排班
+// @Tags 医生工作台
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.CreateScheduleSwapRequest true "换班信息"
+// @Success 200 {object} response.Response{data=model.ScheduleSwapVO}
+// @Router /api/doctor/schedule-swaps [post]
+func (h *DoctorPortalHandler) CreateSwap(c *gin.Context) {
+	var req service.CreateScheduleSwapRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	swap, err := h.swapService.Create(middleware.GetDoctorID(c), &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, swap)
+}
+
+// RespondSwap 答复换班申请
+// @Summary 答复换班申请
+// @Description 同意或拒绝发给本人的换班申请
+// @Tags 医生工作台
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "申请ID"
+// @Param request body service.RespondScheduleSwapRequest true "答复信息"
+// @Success 200 {object} response.Response
+// @Router /api/doctor/schedule-swaps/{id}/respond [put]
+func (h *DoctorPortalHandler) RespondSwap(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.RespondScheduleSwapRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	if err := h.swapService.Respond(id, middleware.GetDoctorID(c), &req); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "操作成功", nil)
+}
+
+// CancelSwap 撤销换班申请
+// @Summary 撤销换班申请
+// @Description 审批完成前撤销本人发起的换班申请
+// @Tags 医生工作台
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "申请ID"
+// @Success 200 {object} response.Response
+// @Router /api/doctor/schedule-swaps/{id}/cancel [put]
+func (h *DoctorPortalHandler) CancelSwap(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	if err := h.swapService.Cancel(id, middleware.GetDoctorID(c)); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "撤销成功", nil)
+}
+
+// ListRecords 本人书写的病历
+// @Summary 本人病历
+// @Description 分页查询本人接诊的病历
+// @Tags 医生工作台
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page query int true "页码"
+// @Param page_size query int true "每页数量"
+// @Param status query string false "状态 draft/signed"
+// @Param start_date query string false "就诊开始日期"
+// @Param end_date query string false "就诊结束日期"
+// @Param keyword query string false "关键词(患者姓名/诊断)"
+// @Success 200 {object} response.Response{data=response.PageData{list=[]model.MedicalRecordVO}}
+// @Router /api/doctor/records [get]
+func (h *DoctorPortalHandler) ListRecords(c *gin.Context) {
+	var req service.ListMedicalRecordRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, errorcode.ErrInvalidPageParams)
+		return
+	}
+
+	list, total, err := h.recordService.List(service.DoctorRecordActor(middleware.GetDoctorID(c)), &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithPage(c, list, total, req.Page, req.PageSize)
+}
+
+// GetRecord 病历详情
+// @Summary 病历详情
+// @Description 获取本人接诊的病历详情（含补充更正记录）
+// @Tags 医生工作台
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "病历ID"
+// @Success 200 {object} response.Response{data=model.MedicalRecordVO}
+// @Router /api/doctor/records/{id} [get]
+func (h *DoctorPortalHandler) GetRecord(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	record, err := h.recordService.GetForActor(service.DoctorRecordActor(middleware.GetDoctorID(c)), id)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, record)
+}
+
+// GetAppointmentRecord 预约的病历
+// @Summary 预约的病历
+// @Description 获取本人预约的病历
+// @Tags 医生工作台
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "预约ID"
+// @Success 200 {object} response.Response{data=model.MedicalRecordVO}
+// @Router /api/doctor/appointments/{id}/record [get]
+func (h *DoctorPortalHandler) GetAppointmentRecord(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	record, err := h.recordService.GetByAppointment(service.DoctorRecordActor(middleware.GetDoctorID(c)), id)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, record)
+}
+
+// SaveAppointmentRecord 书写病历
+// @Summary 书写病历
+// @Description 创建或编辑本人预约的病历草稿，保存后预约置为已完成；已签署的病历需通过补充更正修改
+// @Tags 医生工作台
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "预约ID"
+// @Param request body service.SaveMedicalRecordRequest true "病历内容"
+// @Success 200 {object} response.Response{data=model.MedicalRecordVO}
+// @Router /api/doctor/appointments/{id}/record [put]
+func (h *DoctorPortalHandler) SaveAppointmentRecord(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.SaveMedicalRecordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	record, err := h.recordService.SaveByAppointment(service.DoctorRecordActor(middleware.GetDoctorID(c)), id, &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, record)
+}
+
+// SignRecord 签署病历
+// @Summary 签署病历
+// @Description 签署本人接诊的病历，签署后锁定并对患者可见
+// @Tags 医生工作台
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "病历ID"
+// @Success 200 {object} response.Response{data=model.MedicalRecordVO}
+// @Router /api/doctor/records/{id}/sign [put]
+func (h *DoctorPortalHandler) SignRecord(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	record, err := h.recordService.Sign(service.DoctorRecordActor(middleware.GetDoctorID(c)), id)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, record)
+}
+
+// AmendRecord 补充更正病历
+// @Summary 补充更正病历
+// @Description 修改本人已签署的病历，保留变更前后内容及原因
+// @Tags 医生工作台
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "病历ID"
+// @Param request body service.AmendMedicalRecordRequest true "更正内容"
+// @Success 200 {object} response.Response{data=model.MedicalRecordVO}
+// @Router /api/doctor/records/{id}/amendments [post]
+func (h *DoctorPortalHandler) AmendRecord(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.AmendMedicalRecordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	record, err := h.recordService.Amend(service.DoctorRecordActor(middleware.GetDoctorID(c)), id, &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, record)
+}
+
+// ListReviews 本人收到的评价
+// @Summary 本人收到的评价
+// @Description 分页查询本人收到的患者评价
+// @Tags 医生工作台
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page query int true "页码"
+// @Param page_size query int true "每页数量"
+// @Param status query string false "状态 visible/hidden"
+// @Param min_rating query int false "最低评分"
+// @Param max_rating query int false "最高评分"
+// @Param keyword query string false "评价内容关键词"
+// @Success 200 {object} response.Response{data=response.PageData{list=[]model.DoctorReviewVO}}
+// @Router /api/doctor/reviews [get]
+func (h *DoctorPortalHandler) ListReviews(c *gin.Context) {
+	var req service.ListDoctorReviewRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, errorcode.ErrInvalidPageParams)
+		return
+	}
+
+	list, total, err := h.reviewService.ListForDoctor(middleware.GetDoctorID(c), &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithPage(c, list, total, req.Page, req.PageSize)
+}
+
+// ReplyReview 回复评价
+// @Summary 回复评价
+// @Description 回复本人收到的评价，重复提交将覆盖原回复
+// @Tags 医生工作台
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "评价ID"
+// @Param request body service.ReplyDoctorReviewRequest true "回复内容"
+// @Success 200 {object} response.Response{data=model.DoctorReviewVO}
+// @Router /api/doctor/reviews/{id}/reply [put]
+func (h *DoctorPortalHandler) ReplyReview(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.ReplyDoctorReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	review, err := h.reviewService.Reply(middleware.GetDoctorID(c), id, &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, review)
+}
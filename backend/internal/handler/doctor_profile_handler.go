@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"huaan-medical/internal/service"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/response"
+)
+
+// DoctorProfileHandler 医生结构化资料处理器（资质、相册、擅长领域标签）
+type DoctorProfileHandler struct {
+	service *service.DoctorProfileService
+}
+
+// NewDoctorProfileHandler 创建医生资料处理器实例
+func NewDoctorProfileHandler() *DoctorProfileHandler {
+	return &DoctorProfileHandler{
+		service: service.NewDoctorProfileService(),
+	}
+}
+
+// UpdateProfile 更新医生结构化资料
+// @Summary 更新医生资料
+// @Description 整体替换医生的执业证书、教育经历、资格证书、学术任职、相册及擅长领域标签
+// @Tags 医生管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "医生ID"
+// @Param request body service.UpdateDoctorProfileRequest true "医生资料"
+// @Success 200 {object} response.Response{data=model.DoctorVO}
+// @Router /api/admin/doctors/{id}/profile [put]
+func (h *DoctorProfileHandler) UpdateProfile(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.UpdateDoctorProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	doctor, err := h.service.UpdateProfile(id, &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, doctor)
+}
+
+// ListExpiring 即将到期的医生资质
+// @Summary 即将到期的医生资质
+// @Description 查询未来N天内到期及已过期的医师执业证书和资格证书，默认N取到期提醒配置
+// @Tags 医生管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param days query int false "未来天数（0-365）"
+// @Success 200 {object} response.Response{data=[]model.ExpiringQualificationVO}
+// @Router /api/admin/doctors/expiring-qualifications [get]
+func (h *DoctorProfileHandler) ListExpiring(c *gin.Context) {
+	var req service.ListExpiringQualificationRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, errorcode.ErrInvalidParams)
+		return
+	}
+
+	list, err := h.service.ListExpiring(&req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// ListPublicTags 擅长领域标签（公开接口）
+// @Summary 擅长领域标签
+// @Description 获取启用的擅长领域标签（含出诊医生数），可用于医生列表 tag_id 筛选
+// @Tags 医生
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=[]model.SpecialtyTagVO}
+// @Router /api/specialty-tags [get]
+func (h *DoctorProfileHandler) ListPublicTags(c *gin.Context) {
+	list, err := h.service.ListPublicTags()
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// ListTags 擅长领域标签列表
+// @Summary 擅长领域标签列表
+// @Description 分页查询擅长领域标签
+// @Tags 医生管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page query int true "页码"
+// @Param page_size query int true "每页数量"
+// @Param keyword query string false "标签名称"
+// @Param status query int false "状态 0停用 1启用"
+// @Success 200 {object} response.Response{data=response.PageData{list=[]model.SpecialtyTagVO}}
+// @Router /api/admin/specialty-tags [get]
+func (h *DoctorProfileHandler) ListTags(c *gin.Context) {
+	var req service.ListSpecialtyTagRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, errorcode.ErrInvalidPageParams)
+		return
+	}
+
+	list, total, err := h.service.ListTags(&req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithPage(c, list, total, req.Page, req.PageSize)
+}
+
+// CreateTag 创建擅长领域标签
+// @Summary 创建擅长领域标签
+// @Description 创建擅长领域标签
+// @Tags 医生管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.CreateSpecialtyTagRequest true "标签信息"
+// @Success 200 {object} response.Response{data=model.SpecialtyTagVO}
+// @Router /api/admin/specialty-tags [post]
+func (h *DoctorProfileHandler) CreateTag(c *gin.Context) {
+	var req service.CreateSpecialtyTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	tag, err := h.service.CreateTag(&req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, tag)
+}
+
+// UpdateTag 更新擅长领域标签
+// @Summary 更新擅长领域标签
+// @Description 更新擅长领域标签
+// @Tags 医生管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "标签ID"
+// @Param request body service.UpdateSpecialtyTagRequest true "标签信息"
+// @Success 200 {object} response.Response{data=model.SpecialtyTagVO}
+// @Router /api/admin/specialty-tags/{id} [put]
+func (h *DoctorProfileHandler) UpdateTag(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.UpdateSpecialtyTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	tag, err := h.service.UpdateTag(id, &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, tag)
+}
+
+// DeleteTag 删除擅长领域标签
+// @Summary 删除擅长领域标签
+// @Description 删除擅长领域标签（同时从医生资料中移除）
+// @Tags 医生管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "标签ID"
+// @Success 200 {object} response.Response
+// @Router /api/admin/specialty-tags/{id} [delete]
+func (h *DoctorProfileHandler) DeleteTag(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	if err := h.service.DeleteTag(id); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "删除成功", nil)
+}
@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"huaan-medical/internal/middleware"
+	"huaan-medical/internal/service"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/response"
+)
+
+// DoctorReviewHandler 就诊评价处理器
+type DoctorReviewHandler struct {
+	service *service.DoctorReviewService
+}
+
+// NewDoctorReviewHandler 创建就诊评价处理器实例
+func NewDoctorReviewHandler() *DoctorReviewHandler {
+	return &DoctorReviewHandler{
+		service: service.NewDoctorReviewService(),
+	}
+}
+
+// ListTags 评价标签
+// @Summary 评价标签
+// @Description 获取可选的评价标签（公开接口）
+// @Tags 就诊评价
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=[]model.DoctorReviewTagVO}
+// @Router /api/review-tags [get]
+func (h *DoctorReviewHandler) ListTags(c *gin.Context) {
+	response.Success(c, h.service.ListTags())
+}
+
+// ListByDoctor 医生评价列表（公开接口）
+// @Summary 医生评价列表
+// @Description 分页查询医生的评价（不含已屏蔽评价，患者姓名脱敏）
+// @Tags 就诊评价
+// @Accept json
+// @Produce json
+// @Param id path int true "医生ID"
+// @Param page query int true "页码"
+// @Param page_size query int true "每页数量"
+// @Success 200 {object} response.Response{data=response.PageData{list=[]model.DoctorReviewVO}}
+// @Router /api/doctors/{id}/reviews [get]
+func (h *DoctorReviewHandler) ListByDoctor(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.ListPublicDoctorReviewRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, errorcode.ErrInvalidPageParams)
+		return
+	}
+
+	list, total, err := h.service.ListPublic(id, &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithPage(c, list, total, req.Page, req.PageSize)
+}
+
+// Create 评价就诊
+// @Summary 评价就诊
+// @Description 对已完成的预约进行评价（评分1-5、标签、评价内容），每个预约限评价一次
+// @Tags 就诊评价
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "预约ID"
+// @Param request body service.CreateDoctorReviewRequest true "评价内容"
+// @Success 200 {object} response.Response{data=model.DoctorReviewVO}
+// @Router /api/appointments/{id}/review [post]
+func (h *DoctorReviewHandler) Create(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.CreateDoctorReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	review, err := h.service.Create(middleware.GetUserID(c), id, &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, review)
+}
+
+// GetByAppointment 查看本人评价
+// @Summary 查看本人评价
+// @Description 获取本人对指定预约的评价
+// @Tags 就诊评价
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "预约ID"
+// @Success 200 {object} response.Response{data=model.DoctorReviewVO}
+// @Router /api/appointments/{id}/review [get]
+func (h *DoctorReviewHandler) GetByAppointment(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	review, err := h.service.GetByAppointment(middleware.GetUserID(c), id)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, review)
+}
+
+// List 评价列表（管理后台）
+// @Summary 评价列表
+// @Description 分页查询评价，可按医生/科室/状态/评分/内容筛选
+// @Tags 评价管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page query int true "页码"
+// @Param page_size query int true "每页数量"
+// @Param doctor_id query int false "医生ID"
+// @Param department_id query int false "科室ID"
+// @Param status query string false "状态 visible/hidden"
+// @Param min_rating query int false "最低评分"
+// @Param max_rating query int false "最高评分"
+// @Param keyword query string false "评价内容关键词"
+// @Success 200 {object} response.Response{data=response.PageData{list=[]model.DoctorReviewVO}}
+// @Router /api/admin/reviews [get]
+func (h *DoctorReviewHandler) List(c *gin.Context) {
+	var req service.ListDoctorReviewRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, errorcode.ErrInvalidPageParams)
+		return
+	}
+
+	list, total, err := h.service.List(&req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithPage(c, list, total, req.Page, req.PageSize)
+}
+
+// GetByID 评价详情（管理后台）
+// @Summary 评价详情
+// @Description 获取评价详情
+// @Tags 评价管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "评价ID"
+// @Success 200 {object} response.Response{data=model.DoctorReviewVO}
+// @Router /api/admin/reviews/{id} [get]
+func (h *DoctorReviewHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	review, err := h.service.GetByID(id)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, review)
+}
+
+// Hide 屏蔽评价
+// @Summary 屏蔽评价
+// @Description 屏蔽不当评价，屏蔽后不再公开展示且不计入评分
+// @Tags 评价管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "评价ID"
+// @Param request body service.HideDoctorReviewRequest true "屏蔽原因"
+// @Success 200 {object} response.Response
+// @Router /api/admin/reviews/{id}/hide [put]
+func (h *DoctorReviewHandler) Hide(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.HideDoctorReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	if err := h.service.Hide(id, middleware.GetAdminID(c), &req); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "已屏蔽", nil)
+}
+
+// Show 恢复展示评价
+// @Summary 恢复展示评价
+// @Description 取消屏蔽，恢复公开展示并计入评分
+// @Tags 评价管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "评价ID"
+// @Success 200 {object} response.Response
+// @Router /api/admin/reviews/{id}/show [put]
+func (h *DoctorReviewHandler) Show(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	if err := h.service.Show(id); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "已恢复展示", nil)
+}
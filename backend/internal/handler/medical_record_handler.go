@@ -80,3 +80,180 @@ func (h *MedicalRecordHandler) GetByID(c *gin.Context) {
 
 	response.Success(c, record)
 }
+
+// ListAdmin 病历列表（管理后台）
+// @Summary 病历列表
+// @Description 分页查询病历，可按医生/科室/状态/就诊日期筛选
+// @Tags 病历管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page query int true "页码"
+// @Param page_size query int true "每页数量"
+// @Param doctor_id query int false "医生ID"
+// @Param department_id query int false "科室ID"
+// @Param status query string false "状态 draft/signed"
+// @Param start_date query string false "就诊开始日期"
+// @Param end_date query string false "就诊结束日期"
+// @Param keyword query string false "关键词(患者姓名/诊断)"
+// @Success 200 {object} response.Response{data=response.PageData{list=[]model.MedicalRecordVO}}
+// @Router /api/admin/records [get]
+func (h *MedicalRecordHandler) ListAdmin(c *gin.Context) {
+	var req service.ListMedicalRecordRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, errorcode.ErrInvalidPageParams)
+		return
+	}
+
+	list, total, err := h.service.List(service.AdminRecordActor(middleware.GetAdminID(c)), &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithPage(c, list, total, req.Page, req.PageSize)
+}
+
+// GetByIDAdmin 病历详情（管理后台）
+// @Summary 病历详情
+// @Description 获取病历详情（含补充更正记录）
+// @Tags 病历管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "病历ID"
+// @Success 200 {object} response.Response{data=model.MedicalRecordVO}
+// @Router /api/admin/records/{id} [get]
+func (h *MedicalRecordHandler) GetByIDAdmin(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	record, err := h.service.GetForActor(service.AdminRecordActor(middleware.GetAdminID(c)), id)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, record)
+}
+
+// GetByAppointmentAdmin 预约的病历（管理后台）
+// @Summary 预约的病历
+// @Description 获取指定预约的病历
+// @Tags 病历管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "预约ID"
+// @Success 200 {object} response.Response{data=model.MedicalRecordVO}
+// @Router /api/admin/appointments/{id}/record [get]
+func (h *MedicalRecordHandler) GetByAppointmentAdmin(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	record, err := h.service.GetByAppointment(service.AdminRecordActor(middleware.GetAdminID(c)), id)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, record)
+}
+
+// SaveAdmin 书写病历（管理后台）
+// @Summary 书写病历
+// @Description 创建或编辑预约的病历草稿，保存后预约置为已完成；已签署的病历需通过补充更正修改
+// @Tags 病历管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "预约ID"
+// @Param request body service.SaveMedicalRecordRequest true "病历内容"
+// @Success 200 {object} response.Response{data=model.MedicalRecordVO}
+// @Router /api/admin/appointments/{id}/record [put]
+func (h *MedicalRecordHandler) SaveAdmin(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.SaveMedicalRecordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	record, err := h.service.SaveByAppointment(service.AdminRecordActor(middleware.GetAdminID(c)), id, &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, record)
+}
+
+// SignAdmin 签署病历（管理后台）
+// @Summary 签署病历
+// @Description 签署后病历锁定，患者端可见
+// @Tags 病历管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "病历ID"
+// @Success 200 {object} response.Response{data=model.MedicalRecordVO}
+// @Router /api/admin/records/{id}/sign [put]
+func (h *MedicalRecordHandler) SignAdmin(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	record, err := h.service.Sign(service.AdminRecordActor(middleware.GetAdminID(c)), id)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, record)
+}
+
+// AmendAdmin 补充更正病历（管理后台）
+// @Summary 补充更正病历
+// @Description 修改已签署的病历，保留变更前后内容及原因
+// @Tags 病历管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "病历ID"
+// @Param request body service.AmendMedicalRecordRequest true "更正内容"
+// @Success 200 {object} response.Response{data=model.MedicalRecordVO}
+// @Router /api/admin/records/{id}/amendments [post]
+func (h *MedicalRecordHandler) AmendAdmin(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.AmendMedicalRecordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	record, err := h.service.Amend(service.AdminRecordActor(middleware.GetAdminID(c)), id, &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, record)
+}
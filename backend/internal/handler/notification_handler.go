@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"huaan-medical/internal/middleware"
+	"huaan-medical/internal/service"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/response"
+)
+
+// NotificationHandler 站内消息处理器
+type NotificationHandler struct {
+	service *service.NotificationService
+}
+
+// NewNotificationHandler 创建站内消息处理器实例
+func NewNotificationHandler() *NotificationHandler {
+	return &NotificationHandler{
+		service: service.NewNotificationService(),
+	}
+}
+
+// List 消息列表
+// @Summary 消息列表
+// @Description 分页获取当前用户的站内消息
+// @Tags 站内消息
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page query int true "页码"
+// @Param page_size query int true "每页数量"
+// @Param unread_only query bool false "仅未读"
+// @Success 200 {object} response.Response{data=response.PageData}
+// @Router /api/user/notifications [get]
+func (h *NotificationHandler) List(c *gin.Context) {
+	var req service.ListNotificationRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, errorcode.ErrInvalidPageParams)
+		return
+	}
+
+	list, total, err := h.service.List(middleware.GetUserID(c), &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithPage(c, list, total, req.Page, req.PageSize)
+}
+
+// UnreadCount 未读消息数
+// @Summary 未读消息数
+// @Description 获取当前用户的未读消息数
+// @Tags 站内消息
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/user/notifications/unread-count [get]
+func (h *NotificationHandler) UnreadCount(c *gin.Context) {
+	count, err := h.service.UnreadCount(middleware.GetUserID(c))
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"count": count})
+}
+
+// MarkRead 标记已读
+// @Summary 标记消息已读
+// @Description 将指定消息标记为已读
+// @Tags 站内消息
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "消息ID"
+// @Success 200 {object} response.Response
+// @Router /api/user/notifications/{id}/read [put]
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	if err := h.service.MarkRead(middleware.GetUserID(c), id); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "操作成功", nil)
+}
+
+// MarkAllRead 全部标记已读
+// @Summary 全部标记已读
+// @Description 将当前用户的全部消息标记为已读
+// @Tags 站内消息
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/user/notifications/read-all [put]
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	if err := h.service.MarkAllRead(middleware.GetUserID(c)); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "操作成功", nil)
+}
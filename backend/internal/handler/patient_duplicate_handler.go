@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"huaan-medical/internal/middleware"
+	"huaan-medical/internal/service"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/response"
+)
+
+// PatientDuplicateHandler 重复就诊人审核与合并处理器
+type PatientDuplicateHandler struct {
+	service *service.PatientDuplicateService
+}
+
+// NewPatientDuplicateHandler 创建重复就诊人处理器实例
+func NewPatientDuplicateHandler() *PatientDuplicateHandler {
+	return &PatientDuplicateHandler{
+		service: service.NewPatientDuplicateService(),
+	}
+}
+
+// List 疑似重复就诊人列表
+// @Summary 疑似重复就诊人列表
+// @Description 分页查询疑似重复就诊人审核队列，按相似度排序
+// @Tags 患者管理（后台）
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page query int true "页码"
+// @Param page_size query int true "每页数量"
+// @Param status query string false "状态 pending/merged/ignored"
+// @Param match_type query string false "匹配方式 id_card/name_phone/name_birth"
+// @Success 200 {object} response.Response{data=response.PageData{list=[]model.PatientDuplicateVO}}
+// @Router /api/admin/patients/duplicates [get]
+func (h *PatientDuplicateHandler) List(c *gin.Context) {
+	var req service.ListPatientDuplicatesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, errorcode.ErrInvalidPageParams)
+		return
+	}
+
+	list, total, err := h.service.List(&req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithPage(c, list, total, req.Page, req.PageSize)
+}
+
+// Detect 立即检测重复就诊人
+// @Summary 检测重复就诊人
+// @Description 立即扫描全部就诊人并将新发现的疑似重复写入审核队列（每日定时任务亦会执行）
+// @Tags 患者管理（后台）
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response{data=int}
+// @Router /api/admin/patients/duplicates/detect [post]
+func (h *PatientDuplicateHandler) Detect(c *gin.Context) {
+	count, err := h.service.DetectDuplicates()
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, count)
+}
+
+// Merge 合并疑似重复就诊人
+// @Summary 合并疑似重复就诊人
+// @Description 保留指定一方，将另一方的预约、病历、评价迁移到保留方并删除被合并方，可撤销
+// @Tags 患者管理（后台）
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "疑似重复记录ID"
+// @Param request body service.MergePatientRequest true "合并信息"
+// @Success 200 {object} response.Response{data=model.PatientMergeVO}
+// @Router /api/admin/patients/duplicates/{id}/merge [post]
+func (h *PatientDuplicateHandler) Merge(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.MergePatientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	merge, err := h.service.Merge(middleware.GetAdminID(c), middleware.GetAdminUsername(c), id, &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, merge)
+}
+
+// Ignore 忽略疑似重复
+// @Summary 忽略疑似重复
+// @Description 确认不是同一人，该对就诊人不再进入审核队列
+// @Tags 患者管理（后台）
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "疑似重复记录ID"
+// @Param request body service.ReviewPatientDuplicateRequest true "备注"
+// @Success 200 {object} response.Response
+// @Router /api/admin/patients/duplicates/{id}/ignore [post]
+func (h *PatientDuplicateHandler) Ignore(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.ReviewPatientDuplicateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	if err := h.service.Ignore(middleware.GetAdminID(c), id, &req); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// ListMerges 就诊人合并记录
+// @Summary 就诊人合并记录
+// @Description 分页查询就诊人合并审计记录（含迁移数量、操作人及撤销信息）
+// @Tags 患者管理（后台）
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page query int true "页码"
+// @Param page_size query int true "每页数量"
+// @Param status query string false "状态 merged/undone"
+// @Param patient_id query int false "就诊人ID（保留方或被合并方）"
+// @Success 200 {object} response.Response{data=response.PageData{list=[]model.PatientMergeVO}}
+// @Router /api/admin/patients/merges [get]
+func (h *PatientDuplicateHandler) ListMerges(c *gin.Context) {
+	var req service.ListPatientMergesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, errorcode.ErrInvalidPageParams)
+		return
+	}
+
+	list, total, err := h.service.ListMerges(&req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithPage(c, list, total, req.Page, req.PageSize)
+}
+
+// Undo 撤销就诊人合并
+// @Summary 撤销就诊人合并
+// @Description 恢复被合并的就诊人，并将合并时迁移的预约、病历、评价迁回
+// @Tags 患者管理（后台）
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "合并记录ID"
+// @Param request body service.ReviewPatientDuplicateRequest true "撤销原因"
+// @Success 200 {object} response.Response{data=model.PatientMergeVO}
+// @Router /api/admin/patients/merges/{id}/undo [post]
+func (h *PatientDuplicateHandler) Undo(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.ReviewPatientDuplicateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	merge, err := h.service.Undo(middleware.GetAdminID(c), middleware.GetAdminUsername(c), id, &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, merge)
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"huaan-medical/internal/middleware"
+	"huaan-medical/internal/service"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/response"
+)
+
+// PatientGuardianHandler 就诊人监护处理器（成年家人病历授权、成年子女档案转交）
+type PatientGuardianHandler struct {
+	service *service.PatientGuardianService
+}
+
+// NewPatientGuardianHandler 创建就诊人监护处理器实例
+func NewPatientGuardianHandler() *PatientGuardianHandler {
+	return &PatientGuardianHandler{
+		service: service.NewPatientGuardianService(),
+	}
+}
+
+// SendConsentCode 发送病历查看授权验证码
+// @Summary 发送病历查看授权验证码
+// @Description 就诊人已成年时，向其本人账号绑定的手机号发送验证码，由本人告知验证码完成授权
+// @Tags 就诊人管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "就诊人ID"
+// @Success 200 {object} response.Response{data=service.SendConsentCodeResult}
+// @Router /api/user/patients/{id}/consent/code [post]
+func (h *PatientGuardianHandler) SendConsentCode(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	result, err := h.service.SendConsentCode(userID, id)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "验证码已发送至就诊人本人账号绑定的手机", result)
+}
+
+// GrantConsent 完成病历查看授权
+// @Summary 完成病历查看授权
+// @Description 提交就诊人本人账号手机收到的验证码，授权当前账号查看该成年家人的病历
+// @Tags 就诊人管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "就诊人ID"
+// @Param request body service.GrantPatientConsentRequest true "验证码"
+// @Success 200 {object} response.Response{data=model.PatientConsentVO}
+// @Router /api/user/patients/{id}/consent [post]
+func (h *PatientGuardianHandler) GrantConsent(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.GrantPatientConsentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	consent, err := h.service.GrantConsent(userID, id, &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "授权成功", consent)
+}
+
+// Transfer 转交成年子女档案
+// @Summary 转交成年子女档案
+// @Description 将已成年的子女就诊人转交给其登记手机号对应的账号，转交后由本人管理
+// @Tags 就诊人管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "就诊人ID"
+// @Success 200 {object} response.Response
+// @Router /api/user/patients/{id}/transfer [post]
+func (h *PatientGuardianHandler) Transfer(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	if err := h.service.Transfer(userID, id); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "已转交本人管理", gin.H{})
+}
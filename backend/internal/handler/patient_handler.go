@@ -82,7 +82,7 @@ func (h *PatientHandler) GetByID(c *gin.Context) {
 
 // Create 创建就诊人
 // @Summary 创建就诊人
-// @Description 添加新的就诊人
+// @Description 添加新的就诊人，支持居民身份证、护照、港澳居民来往内地通行证；居民身份证自动解析性别和出生日期，启用实名核验时姓名与证件不一致将拒绝添加
 // @Tags 就诊人管理
 // @Accept json
 // @Produce json
@@ -114,7 +114,7 @@ func (h *PatientHandler) Create(c *gin.Context) {
 
 // Update 更新就诊人
 // @Summary 更新就诊人
-// @Description 更新就诊人信息
+// @Description 更新就诊人信息，姓名或证件变更时重新实名核验
 // @Tags 就诊人管理
 // @Accept json
 // @Produce json
@@ -151,6 +151,38 @@ func (h *PatientHandler) Update(c *gin.Context) {
 	response.Success(c, patient)
 }
 
+// Verify 实名核验就诊人
+// @Summary 实名核验就诊人
+// @Description 重新核验就诊人姓名与证件号码是否一致，并记录核验结果
+// @Tags 就诊人管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "就诊人ID"
+// @Success 200 {object} response.Response{data=model.PatientVO}
+// @Router /api/user/patients/{id}/verify [post]
+func (h *PatientHandler) Verify(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	patient, err := h.service.Verify(userID, id)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, patient)
+}
+
 // Delete 删除就诊人
 // @Summary 删除就诊人
 // @Description 删除就诊人（软删除）
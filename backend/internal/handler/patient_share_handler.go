@@ -0,0 +1,277 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"huaan-medical/internal/middleware"
+	"huaan-medical/internal/service"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/response"
+)
+
+// PatientShareHandler 就诊人共享处理器
+type PatientShareHandler struct {
+	service *service.PatientShareService
+}
+
+// NewPatientShareHandler 创建就诊人共享处理器实例
+func NewPatientShareHandler() *PatientShareHandler {
+	return &PatientShareHandler{
+		service: service.NewPatientShareService(),
+	}
+}
+
+// ListMembers 就诊人共享成员列表
+// @Summary 就诊人共享成员列表
+// @Description 获取就诊人的所有者及共享成员（共享成员均可查看）
+// @Tags 就诊人共享
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "就诊人ID"
+// @Success 200 {object} response.Response{data=[]model.PatientMemberVO}
+// @Router /api/user/patients/{id}/members [get]
+func (h *PatientShareHandler) ListMembers(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	list, err := h.service.ListMembers(userID, id)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// UpdateMember 修改共享成员角色
+// @Summary 修改共享成员角色
+// @Description 所有者修改共享成员的角色（管理者/查看者）
+// @Tags 就诊人共享
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "就诊人ID"
+// @Param user_id path int true "成员用户ID"
+// @Param request body service.UpdatePatientMemberRequest true "角色"
+// @Success 200 {object} response.Response
+// @Router /api/user/patients/{id}/members/{user_id} [put]
+func (h *PatientShareHandler) UpdateMember(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+	memberUserID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.UpdatePatientMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	if err := h.service.UpdateMemberRole(userID, id, memberUserID, &req); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// RemoveMember 移除共享成员
+// @Summary 移除共享成员
+// @Description 所有者移除共享成员；共享成员传入自己的用户ID即退出共享
+// @Tags 就诊人共享
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "就诊人ID"
+// @Param user_id path int true "成员用户ID"
+// @Success 200 {object} response.Response
+// @Router /api/user/patients/{id}/members/{user_id} [delete]
+func (h *PatientShareHandler) RemoveMember(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+	memberUserID, err := strconv.ParseInt(c.Param("user_id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	if err := h.service.RemoveMember(userID, id, memberUserID); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "移除成功", nil)
+}
+
+// CreateInvitation 发起共享邀请
+// @Summary 发起共享邀请
+// @Description 所有者为就诊人生成共享邀请码，可指定被邀请人手机号；对方已注册时发送站内消息
+// @Tags 就诊人共享
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "就诊人ID"
+// @Param request body service.CreatePatientInvitationRequest true "邀请信息"
+// @Success 200 {object} response.Response{data=model.PatientInvitationVO}
+// @Router /api/user/patients/{id}/invitations [post]
+func (h *PatientShareHandler) CreateInvitation(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.CreatePatientInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	invitation, err := h.service.CreateInvitation(userID, id, &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, invitation)
+}
+
+// ListInvitations 共享邀请列表
+// @Summary 共享邀请列表
+// @Description 所有者查看就诊人的共享邀请记录
+// @Tags 就诊人共享
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "就诊人ID"
+// @Success 200 {object} response.Response{data=[]model.PatientInvitationVO}
+// @Router /api/user/patients/{id}/invitations [get]
+func (h *PatientShareHandler) ListInvitations(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	list, err := h.service.ListInvitations(userID, id)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// RevokeInvitation 撤销共享邀请
+// @Summary 撤销共享邀请
+// @Description 所有者撤销尚未被接受的共享邀请
+// @Tags 就诊人共享
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "就诊人ID"
+// @Param invitation_id path int true "邀请ID"
+// @Success 200 {object} response.Response
+// @Router /api/user/patients/{id}/invitations/{invitation_id} [delete]
+func (h *PatientShareHandler) RevokeInvitation(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+	invitationID, err := strconv.ParseInt(c.Param("invitation_id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	if err := h.service.RevokeInvitation(userID, id, invitationID); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "撤销成功", nil)
+}
+
+// AcceptInvitation 接受共享邀请
+// @Summary 接受共享邀请
+// @Description 凭邀请码加入就诊人共享，之后可按角色查看或管理该就诊人的资料、预约及病历
+// @Tags 就诊人共享
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.AcceptPatientInvitationRequest true "邀请码"
+// @Success 200 {object} response.Response{data=model.PatientVO}
+// @Router /api/user/patient-invitations/accept [post]
+func (h *PatientShareHandler) AcceptInvitation(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	var req service.AcceptPatientInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	patient, err := h.service.AcceptInvitation(userID, &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, patient)
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"huaan-medical/internal/service"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/response"
+)
+
+// ReleaseRuleHandler 放号规则处理器
+type ReleaseRuleHandler struct {
+	service *service.ReleaseRuleService
+}
+
+// NewReleaseRuleHandler 创建放号规则处理器实例
+func NewReleaseRuleHandler() *ReleaseRuleHandler {
+	return &ReleaseRuleHandler{
+		service: service.NewReleaseRuleService(),
+	}
+}
+
+// List 放号规则列表
+// @Summary 放号规则列表
+// @Description 分页查询放号规则
+// @Tags 放号规则
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page query int true "页码"
+// @Param page_size query int true "每页数量"
+// @Param scope_type query string false "作用范围 global/department/doctor"
+// @Success 200 {object} response.Response{data=response.PageData}
+// @Router /api/admin/release-rules [get]
+func (h *ReleaseRuleHandler) List(c *gin.Context) {
+	var req service.ListReleaseRuleRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, errorcode.ErrInvalidPageParams)
+		return
+	}
+
+	list, total, err := h.service.List(&req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithPage(c, list, total, req.Page, req.PageSize)
+}
+
+// Create 创建放号规则
+// @Summary 创建放号规则
+// @Description 为全院/科室/医生创建分阶段放号规则，各阶段比例之和须为100
+// @Tags 放号规则
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.SaveReleaseRuleRequest true "规则信息"
+// @Success 200 {object} response.Response{data=model.ReleaseRuleVO}
+// @Router /api/admin/release-rules [post]
+func (h *ReleaseRuleHandler) Create(c *gin.Context) {
+	var req service.SaveReleaseRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	rule, err := h.service.Create(&req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, rule)
+}
+
+// Update 更新放号规则
+// @Summary 更新放号规则
+// @Description 更新放号规则（不回收已放出的号源）
+// @Tags 放号规则
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "规则ID"
+// @Param request body service.SaveReleaseRuleRequest true "规则信息"
+// @Success 200 {object} response.Response{data=model.ReleaseRuleVO}
+// @Router /api/admin/release-rules/{id} [put]
+func (h *ReleaseRuleHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.SaveReleaseRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	rule, err := h.service.Update(id, &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, rule)
+}
+
+// Delete 删除放号规则
+// @Summary 删除放号规则
+// @Description 删除放号规则（软删除）
+// @Tags 放号规则
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "规则ID"
+// @Success 200 {object} response.Response
+// @Router /api/admin/release-rules/{id} [delete]
+func (h *ReleaseRuleHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	if err := h.service.Delete(id); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "删除成功", nil)
+}
@@ -1,10 +1,13 @@
 package handler
 
 import (
+	"io"
+	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 
+	"huaan-medical/internal/middleware"
 	"huaan-medical/internal/service"
 	"huaan-medical/pkg/errorcode"
 	"huaan-medical/pkg/response"
@@ -12,13 +15,15 @@ import (
 
 // ScheduleHandler 排班处理器
 type ScheduleHandler struct {
-	service *service.ScheduleService
+	service       *service.ScheduleService
+	campusService *service.CampusService
 }
 
 // NewScheduleHandler 创建排班处理器实例
 func NewScheduleHandler() *ScheduleHandler {
 	return &ScheduleHandler{
-		service: service.NewScheduleService(),
+		service:       service.NewScheduleService(),
+		campusService: service.NewCampusService(),
 	}
 }
 
@@ -33,6 +38,7 @@ func NewScheduleHandler() *ScheduleHandler {
 // @Param page_size query int true "每页数量"
 // @Param doctor_id query int false "医生ID筛选"
 // @Param department_id query int false "科室ID筛选"
+// @Param campus_id query int false "院区ID筛选"
 // @Param start_date query string false "开始日期 YYYY-MM-DD"
 // @Param end_date query string false "结束日期 YYYY-MM-DD"
 // @Param status query int false "状态筛选"
@@ -44,6 +50,9 @@ func (h *ScheduleHandler) List(c *gin.Context) {
 		response.Fail(c, errorcode.ErrInvalidPageParams)
 		return
 	}
+	if scope := middleware.GetAdminCampusScope(c); scope > 0 {
+		req.CampusID = &scope
+	}
 
 	list, total, err := h.service.List(&req)
 	if err != nil {
@@ -71,6 +80,11 @@ func (h *ScheduleHandler) GetByID(c *gin.Context) {
 		return
 	}
 
+	if err := h.campusService.AuthorizeSchedule(middleware.GetAdminCampusScope(c), id); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
 	schedule, err := h.service.GetByID(id)
 	if err != nil {
 		response.FailWithError(c, err)
@@ -96,6 +110,10 @@ func (h *ScheduleHandler) Create(c *gin.Context) {
 		response.Fail(c, errorcode.ErrBindJSON)
 		return
 	}
+	if err := h.campusService.AuthorizeDoctorDepartment(middleware.GetAdminCampusScope(c), req.DoctorID, req.DepartmentID); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
 
 	schedule, err := h.service.Create(&req)
 	if err != nil {
@@ -122,6 +140,10 @@ func (h *ScheduleHandler) BatchCreate(c *gin.Context) {
 		response.Fail(c, errorcode.ErrBindJSON)
 		return
 	}
+	if err := h.campusService.AuthorizeDoctorDepartment(middleware.GetAdminCampusScope(c), req.DoctorID, req.DepartmentID); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
 
 	count, err := h.service.BatchCreate(&req)
 	if err != nil {
@@ -135,6 +157,114 @@ func (h *ScheduleHandler) BatchCreate(c *gin.Context) {
 	})
 }
 
+// scheduleImportMaxSize 排班导入文件大小上限（5MB）
+const scheduleImportMaxSize = 5 << 20
+
+// Import 导入排班
+// @Summary 导入排班
+// @Description 上传 CSV/XLSX 排班表；dry_run=true（默认）仅校验并返回行级错误，dry_run=false 时全部校验通过后在同一事务内写入
+// @Tags 排班管理
+// @Accept multipart/form-data
+// @Produce json
+// @Security Bearer
+// @Param file formData file true "排班文件（.xlsx/.csv）"
+// @Param dry_run formData bool false "是否仅预检，默认true"
+// @Success 200 {object} response.Response{data=service.ScheduleImportResult}
+// @Router /api/admin/schedules/import [post]
+func (h *ScheduleHandler) Import(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		response.FailWithMessage(c, errorcode.ErrInvalidParams, "请选择要上传的文件")
+		return
+	}
+	if fileHeader.Size > scheduleImportMaxSize {
+		response.FailWithMessage(c, errorcode.ErrInvalidParams, "文件大小不能超过5MB")
+		return
+	}
+
+	dryRun, err := strconv.ParseBool(c.DefaultPostForm("dry_run", "true"))
+	if err != nil {
+		response.FailWithMessage(c, errorcode.ErrInvalidParams, "dry_run参数格式错误")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.Fail(c, errorcode.ErrFileUpload)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		response.Fail(c, errorcode.ErrFileUpload)
+		return
+	}
+
+	result, err := h.service.Import(fileHeader.Filename, data, dryRun)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	if !dryRun && !result.Committed {
+		response.SuccessWithMessage(c, "存在校验失败的行，未导入任何排班", result)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// ImportTemplate 下载排班导入模板
+// @Summary 下载排班导入模板
+// @Description 下载排班导入模板文件
+// @Tags 排班管理
+// @Produce octet-stream
+// @Security Bearer
+// @Param format query string false "模板格式 xlsx/csv，默认xlsx"
+// @Success 200 {file} file
+// @Router /api/admin/schedules/import/template [get]
+func (h *ScheduleHandler) ImportTemplate(c *gin.Context) {
+	data, contentType, fileName, err := h.service.BuildImportTemplate(c.Query("format"))
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename="+fileName)
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// Copy 复制排班
+// @Summary 复制排班
+// @Description 将医生/科室/全院指定日期范围的排班平移复制到目标日期；preview=true 时仅返回预览对比
+// @Tags 排班管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.CopyScheduleRequest true "复制参数"
+// @Success 200 {object} response.Response{data=service.ScheduleCopyResult}
+// @Router /api/admin/schedules/copy [post]
+func (h *ScheduleHandler) Copy(c *gin.Context) {
+	var req service.CopyScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+	result, err := h.service.Copy(&req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	if !req.Preview && !result.Committed {
+		response.SuccessWithMessage(c, "存在冲突的排班，未复制任何排班", result)
+		return
+	}
+
+	response.Success(c, result)
+}
+
 // Update 更新排班
 // @Summary 更新排班
 // @Description 更新排班信息
@@ -158,6 +288,10 @@ func (h *ScheduleHandler) Update(c *gin.Context) {
 		response.Fail(c, errorcode.ErrBindJSON)
 		return
 	}
+	if err := h.campusService.AuthorizeSchedule(middleware.GetAdminCampusScope(c), id); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
 
 	schedule, err := h.service.Update(id, &req)
 	if err != nil {
@@ -185,6 +319,11 @@ func (h *ScheduleHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	if err := h.campusService.AuthorizeSchedule(middleware.GetAdminCampusScope(c), id); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
 	if err := h.service.Delete(id); err != nil {
 		response.FailWithError(c, err)
 		return
@@ -242,6 +381,7 @@ func (h *ScheduleHandler) ListByDoctor(c *gin.Context) {
 // @Produce json
 // @Param doctor_id query int false "医生ID筛选"
 // @Param department_id query int false "科室ID筛选"
+// @Param campus_id query int false "院区ID筛选"
 // @Param start_date query string true "开始日期 YYYY-MM-DD"
 // @Param end_date query string true "结束日期 YYYY-MM-DD"
 // @Success 200 {object} response.Response{data=[]model.ScheduleVO}
@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"huaan-medical/internal/middleware"
+	"huaan-medical/internal/service"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/response"
+)
+
+// ScheduleSwapHandler 换班申请处理器
+type ScheduleSwapHandler struct {
+	service *service.ScheduleSwapService
+}
+
+// NewScheduleSwapHandler 创建换班申请处理器实例
+func NewScheduleSwapHandler() *ScheduleSwapHandler {
+	return &ScheduleSwapHandler{
+		service: service.NewScheduleSwapService(),
+	}
+}
+
+// List 换班申请列表
+// @Summary 换班申请列表
+// @Description 分页查询换班申请，可按状态/医生/科室筛选
+// @Tags 换班管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page query int true "页码"
+// @Param page_size query int true "每页数量"
+// @Param status query string false "状态 pending/accepted/approved/rejected/declined/cancelled"
+// @Param doctor_id query int false "医生ID（发起方或接班方）"
+// @Param department_id query int false "科室ID"
+// @Success 200 {object} response.Response{data=response.PageData}
+// @Router /api/admin/schedule-swaps [get]
+func (h *ScheduleSwapHandler) List(c *gin.Context) {
+	var req service.ListScheduleSwapRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, errorcode.ErrInvalidPageParams)
+		return
+	}
+
+	list, total, err := h.service.List(&req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithPage(c, list, total, req.Page, req.PageSize)
+}
+
+// GetByID 换班申请详情
+// @Summary 换班申请详情
+// @Description 获取换班申请详情
+// @Tags 换班管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "申请ID"
+// @Success 200 {object} response.Response{data=model.ScheduleSwapVO}
+// @Router /api/admin/schedule-swaps/{id} [get]
+func (h *ScheduleSwapHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	swap, err := h.service.GetByID(id)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, swap)
+}
+
+// Create 代医生发起换班申请
+// @Summary 发起换班申请
+// @Description 将排班转给其他医生，可同时换入对方的一个排班
+// @Tags 换班管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.CreateScheduleSwapRequest true "换班信息"
+// @Success 200 {object} response.Response{data=model.ScheduleSwapVO}
+// @Router /api/admin/schedule-swaps [post]
+func (h *ScheduleSwapHandler) Create(c *gin.Context) {
+	var req service.CreateScheduleSwapRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	swap, err := h.service.Create(0, &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, swap)
+}
+
+// Respond 代对方医生答复换班申请
+// @Summary 答复换班申请
+// @Description 接班医生同意或拒绝换班申请
+// @Tags 换班管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "申请ID"
+// @Param request body service.RespondScheduleSwapRequest true "答复信息"
+// @Success 200 {object} response.Response
+// @Router /api/admin/schedule-swaps/{id}/respond [put]
+func (h *ScheduleSwapHandler) Respond(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.RespondScheduleSwapRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	if err := h.service.Respond(id, 0, &req); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "操作成功", nil)
+}
+
+// Review 审批换班申请
+// @Summary 审批换班申请
+// @Description 审批通过后排班及已预约记录转给对方医生，并通知受影响的患者
+// @Tags 换班管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "申请ID"
+// @Param request body service.ReviewScheduleSwapRequest true "审批信息"
+// @Success 200 {object} response.Response
+// @Router /api/admin/schedule-swaps/{id}/review [put]
+func (h *ScheduleSwapHandler) Review(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.ReviewScheduleSwapRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	if err := h.service.Review(id, middleware.GetAdminID(c), &req); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "审批成功", nil)
+}
+
+// Cancel 撤销换班申请
+// @Summary 撤销换班申请
+// @Description 审批完成前撤销换班申请
+// @Tags 换班管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "申请ID"
+// @Success 200 {object} response.Response
+// @Router /api/admin/schedule-swaps/{id}/cancel [put]
+func (h *ScheduleSwapHandler) Cancel(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	if err := h.service.Cancel(id, 0); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "撤销成功", nil)
+}
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"huaan-medical/internal/service"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/response"
+)
+
+// SearchHandler 搜索处理器
+type SearchHandler struct {
+	service *service.SearchService
+}
+
+// NewSearchHandler 创建搜索处理器实例
+func NewSearchHandler() *SearchHandler {
+	return &SearchHandler{
+		service: service.NewSearchService(),
+	}
+}
+
+// Search 搜索医生和科室（公开接口）
+// @Summary 搜索医生和科室
+// @Description 按名称、拼音全拼/首字母、擅长领域及同义词搜索医生和科室，结果按相关度排序并附带可预约提示
+// @Tags 搜索
+// @Accept json
+// @Produce json
+// @Param keyword query string true "搜索关键词"
+// @Param type query string false "搜索类型 all/doctor/department，默认all"
+// @Param department_id query int false "科室ID（仅筛选医生结果）"
+// @Param limit query int false "每类返回数量，默认20，最大50"
+// @Success 200 {object} response.Response{data=service.SearchResponse}
+// @Router /api/search [get]
+func (h *SearchHandler) Search(c *gin.Context) {
+	var req service.SearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, errorcode.ErrInvalidParams)
+		return
+	}
+
+	result, err := h.service.Search(&req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// Rebuild 重建搜索索引
+// @Summary 重建搜索索引
+// @Description 根据医生、科室数据全量重建搜索索引
+// @Tags 搜索管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response{data=map[string]int}
+// @Router /api/admin/search/rebuild [post]
+func (h *SearchHandler) Rebuild(c *gin.Context) {
+	count, err := h.service.Rebuild()
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "索引重建成功", gin.H{"count": count})
+}
+
+// ListSynonyms 同义词组列表
+// @Summary 同义词组列表
+// @Description 分页查询搜索同义词组
+// @Tags 搜索管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page query int true "页码"
+// @Param page_size query int true "每页数量"
+// @Param keyword query string false "关键词"
+// @Param status query int false "状态 0停用 1启用"
+// @Success 200 {object} response.Response{data=response.PageData{list=[]model.SearchSynonymVO}}
+// @Router /api/admin/search/synonyms [get]
+func (h *SearchHandler) ListSynonyms(c *gin.Context) {
+	var req service.ListSearchSynonymRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, errorcode.ErrInvalidPageParams)
+		return
+	}
+
+	list, total, err := h.service.ListSynonyms(&req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithPage(c, list, total, req.Page, req.PageSize)
+}
+
+// CreateSynonym 创建同义词组
+// @Summary 创建同义词组
+// @Description 创建搜索同义词组，组内词语互为同义词
+// @Tags 搜索管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.CreateSearchSynonymRequest true "同义词组"
+// @Success 200 {object} response.Response{data=model.SearchSynonymVO}
+// @Router /api/admin/search/synonyms [post]
+func (h *SearchHandler) CreateSynonym(c *gin.Context) {
+	var req service.CreateSearchSynonymRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	synonym, err := h.service.CreateSynonym(&req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, synonym)
+}
+
+// UpdateSynonym 更新同义词组
+// @Summary 更新同义词组
+// @Description 更新搜索同义词组
+// @Tags 搜索管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "同义词组ID"
+// @Param request body service.UpdateSearchSynonymRequest true "同义词组"
+// @Success 200 {object} response.Response{data=model.SearchSynonymVO}
+// @Router /api/admin/search/synonyms/{id} [put]
+func (h *SearchHandler) UpdateSynonym(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.UpdateSearchSynonymRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	synonym, err := h.service.UpdateSynonym(id, &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, synonym)
+}
+
+// DeleteSynonym 删除同义词组
+// @Summary 删除同义词组
+// @Description 删除搜索同义词组
+// @Tags 搜索管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "同义词组ID"
+// @Success 200 {object} response.Response
+// @Router /api/admin/search/synonyms/{id} [delete]
+func (h *SearchHandler) DeleteSynonym(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	if err := h.service.DeleteSynonym(id); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "删除成功", nil)
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"huaan-medical/internal/middleware"
+	"huaan-medical/internal/service"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/response"
+)
+
+// SessionHandler 用户登录会话处理器（退出登录、登录设备管理）
+type SessionHandler struct {
+	service *service.SessionService
+}
+
+// NewSessionHandler 创建登录会话处理器实例
+func NewSessionHandler() *SessionHandler {
+	return &SessionHandler{
+		service: service.NewSessionService(),
+	}
+}
+
+// Logout 退出登录
+// @Summary 退出登录
+// @Description 吊销当前登录会话，该会话的access_token与refresh_token立即失效
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response
+// @Router /api/user/logout [post]
+func (h *SessionHandler) Logout(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	if err := h.service.Logout(userID, middleware.GetSessionID(c)); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "已退出登录", nil)
+}
+
+// List 登录设备列表
+// @Summary 登录设备列表
+// @Description 查询当前账号所有有效的登录会话，current 标识当前设备
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response{data=[]model.UserSessionVO}
+// @Router /api/user/sessions [get]
+func (h *SessionHandler) List(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	list, err := h.service.List(userID, middleware.GetSessionID(c))
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, list)
+}
+
+// Revoke 下线登录设备
+// @Summary 下线登录设备
+// @Description 吊销指定登录会话，该设备需重新登录
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "会话ID"
+// @Success 200 {object} response.Response
+// @Router /api/user/sessions/{id} [delete]
+func (h *SessionHandler) Revoke(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	if err := h.service.Revoke(userID, id); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "设备已下线", nil)
+}
+
+// RevokeOthers 下线其他设备
+// @Summary 下线其他设备
+// @Description 吊销除当前设备外的所有登录会话
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response{data=map[string]int}
+// @Router /api/user/sessions [delete]
+func (h *SessionHandler) RevokeOthers(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	count, err := h.service.RevokeOthers(userID, middleware.GetSessionID(c))
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"count": count})
+}
@@ -26,10 +26,13 @@ func NewStatisticsHandler() *StatisticsHandler {
 // @Accept json
 // @Produce json
 // @Security BearerAdmin
+// @Param rollup query bool false "科室统计是否汇总到一级科室"
 // @Success 200 {object} response.Response{data=service.DashboardData}
 // @Router /api/admin/dashboard [get]
 func (h *StatisticsHandler) GetDashboard(c *gin.Context) {
-	data, err := h.service.GetDashboard()
+	rollup := c.Query("rollup") == "true"
+
+	data, err := h.service.GetDashboard(rollup)
 	if err != nil {
 		response.FailWithError(c, err)
 		return
@@ -47,13 +50,15 @@ func (h *StatisticsHandler) GetDashboard(c *gin.Context) {
 // @Security BearerAdmin
 // @Param start_date query string false "开始日期（YYYY-MM-DD）"
 // @Param end_date query string false "结束日期（YYYY-MM-DD）"
+// @Param rollup query bool false "科室排行是否汇总到一级科室"
 // @Success 200 {object} response.Response{data=service.StatisticsData}
 // @Router /api/admin/statistics [get]
 func (h *StatisticsHandler) GetStatistics(c *gin.Context) {
 	startDate := c.Query("start_date")
 	endDate := c.Query("end_date")
+	rollup := c.Query("rollup") == "true"
 
-	data, err := h.service.GetStatistics(startDate, endDate)
+	data, err := h.service.GetStatistics(startDate, endDate, rollup)
 	if err != nil {
 		response.FailWithError(c, err)
 		return
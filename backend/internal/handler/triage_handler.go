@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"huaan-medical/internal/middleware"
+	"huaan-medical/internal/service"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/response"
+)
+
+// TriageHandler 智能导诊处理器
+type TriageHandler struct {
+	service *service.TriageService
+}
+
+// NewTriageHandler 创建智能导诊处理器实例
+func NewTriageHandler() *TriageHandler {
+	return &TriageHandler{
+		service: service.NewTriageService(),
+	}
+}
+
+// Recommend 症状导诊（公开接口）
+// @Summary 症状导诊
+// @Description 根据症状描述及患者年龄、性别推荐就诊科室，返回推荐度及推荐理由
+// @Tags 智能导诊
+// @Accept json
+// @Produce json
+// @Param request body service.TriageRequest true "症状信息"
+// @Success 200 {object} response.Response{data=model.TriageResultVO}
+// @Router /api/triage [post]
+func (h *TriageHandler) Recommend(c *gin.Context) {
+	var req service.TriageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	result, err := h.service.Recommend(&req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// Check 预约科室匹配检查
+// @Summary 预约科室匹配检查
+// @Description 预约前根据就诊人及症状检查所选科室是否合适，不匹配时返回提示及推荐科室
+// @Tags 智能导诊
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.TriageCheckRequest true "检查信息"
+// @Success 200 {object} response.Response{data=model.TriageCheckVO}
+// @Router /api/triage/check [post]
+func (h *TriageHandler) Check(c *gin.Context) {
+	var req service.TriageCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	result, err := h.service.Check(middleware.GetUserID(c), &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// ListRules 导诊规则列表
+// @Summary 导诊规则列表
+// @Description 分页查询导诊规则
+// @Tags 导诊规则管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page query int true "页码"
+// @Param page_size query int true "每页数量"
+// @Param keyword query string false "关键词/说明"
+// @Param department_id query int false "科室ID"
+// @Param status query int false "状态 0停用 1启用"
+// @Success 200 {object} response.Response{data=response.PageData{list=[]model.TriageRuleVO}}
+// @Router /api/admin/triage-rules [get]
+func (h *TriageHandler) ListRules(c *gin.Context) {
+	var req service.ListTriageRuleRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, errorcode.ErrInvalidPageParams)
+		return
+	}
+
+	list, total, err := h.service.ListRules(&req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithPage(c, list, total, req.Page, req.PageSize)
+}
+
+// GetRule 导诊规则详情
+// @Summary 导诊规则详情
+// @Description 获取导诊规则详情
+// @Tags 导诊规则管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "规则ID"
+// @Success 200 {object} response.Response{data=model.TriageRuleVO}
+// @Router /api/admin/triage-rules/{id} [get]
+func (h *TriageHandler) GetRule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	rule, err := h.service.GetRule(id)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, rule)
+}
+
+// CreateRule 创建导诊规则
+// @Summary 创建导诊规则
+// @Description 创建症状关键词到科室的导诊规则，可设置权重及适用年龄、性别
+// @Tags 导诊规则管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.CreateTriageRuleRequest true "规则信息"
+// @Success 200 {object} response.Response{data=model.TriageRuleVO}
+// @Router /api/admin/triage-rules [post]
+func (h *TriageHandler) CreateRule(c *gin.Context) {
+	var req service.CreateTriageRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	rule, err := h.service.CreateRule(&req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, rule)
+}
+
+// UpdateRule 更新导诊规则
+// @Summary 更新导诊规则
+// @Description 更新导诊规则
+// @Tags 导诊规则管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "规则ID"
+// @Param request body service.UpdateTriageRuleRequest true "规则信息"
+// @Success 200 {object} response.Response{data=model.TriageRuleVO}
+// @Router /api/admin/triage-rules/{id} [put]
+func (h *TriageHandler) UpdateRule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.UpdateTriageRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	rule, err := h.service.UpdateRule(id, &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, rule)
+}
+
+// DeleteRule 删除导诊规则
+// @Summary 删除导诊规则
+// @Description 删除导诊规则
+// @Tags 导诊规则管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path int true "规则ID"
+// @Success 200 {object} response.Response
+// @Router /api/admin/triage-rules/{id} [delete]
+func (h *TriageHandler) DeleteRule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	if err := h.service.DeleteRule(id); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "删除成功", nil)
+}
+
+// TestRules 导诊规则测试
+// @Summary 导诊规则测试
+// @Description 使用当前启用的规则对症状描述进行试算，便于调整规则
+// @Tags 导诊规则管理
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.TriageRequest true "症状信息"
+// @Success 200 {object} response.Response{data=model.TriageResultVO}
+// @Router /api/admin/triage-rules/test [post]
+func (h *TriageHandler) TestRules(c *gin.Context) {
+	h.Recommend(c)
+}
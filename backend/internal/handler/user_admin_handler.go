@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"huaan-medical/internal/middleware"
+	"huaan-medical/internal/model"
+	"huaan-medical/internal/service"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/response"
+)
+
+// UserAdminHandler 用户账号管理处理器（管理后台）
+type UserAdminHandler struct {
+	service *service.UserAdminService
+}
+
+// NewUserAdminHandler 创建用户账号管理处理器实例
+func NewUserAdminHandler() *UserAdminHandler {
+	return &UserAdminHandler{
+		service: service.NewUserAdminService(),
+	}
+}
+
+// List 查询用户账号列表
+// @Summary 查询用户账号列表
+// @Description 分页查询患者端用户账号，支持按手机号、用户名、昵称搜索及按状态、封禁筛选
+// @Tags 用户管理（后台）
+// @Accept json
+// @Produce json
+// @Security BearerAdmin
+// @Param page query int true "页码" minimum(1)
+// @Param page_size query int true "每页数量" minimum(1) maximum(100)
+// @Param keyword query string false "手机号、用户名或昵称"
+// @Param status query int false "账号状态 0停用 1启用"
+// @Param blocked query bool false "仅显示爽约封禁中的账号"
+// @Success 200 {object} response.Response{data=response.PageData{list=[]model.UserAdminVO}}
+// @Router /api/admin/users [get]
+func (h *UserAdminHandler) List(c *gin.Context) {
+	var req service.ListAdminUsersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	list, total, err := h.service.List(&req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithPage(c, list, total, req.Page, req.PageSize)
+}
+
+// GetDetail 查询用户账号详情
+// @Summary 查询用户账号详情
+// @Description 查询账号信息、名下就诊人、预约统计、最近登录记录及管理操作记录
+// @Tags 用户管理（后台）
+// @Accept json
+// @Produce json
+// @Security BearerAdmin
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response{data=service.UserAdminDetail}
+// @Router /api/admin/users/{id} [get]
+func (h *UserAdminHandler) GetDetail(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	detail, err := h.service.GetDetail(id)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.Success(c, detail)
+}
+
+// Enable 启用用户账号
+// @Summary 启用用户账号
+// @Description 恢复已停用的账号，需填写操作原因
+// @Tags 用户管理（后台）
+// @Accept json
+// @Produce json
+// @Security BearerAdmin
+// @Param id path int true "用户ID"
+// @Param request body service.UserAdminActionRequest true "操作原因"
+// @Success 200 {object} response.Response{data=model.UserAdminVO}
+// @Router /api/admin/users/{id}/enable [put]
+func (h *UserAdminHandler) Enable(c *gin.Context) {
+	h.handleAction(c, h.service.Enable, "账号已启用")
+}
+
+// Disable 停用用户账号
+// @Summary 停用用户账号
+// @Description 停用账号并使其所有已登录设备下线，需填写操作原因
+// @Tags 用户管理（后台）
+// @Accept json
+// @Produce json
+// @Security BearerAdmin
+// @Param id path int true "用户ID"
+// @Param request body service.UserAdminActionRequest true "操作原因"
+// @Success 200 {object} response.Response{data=model.UserAdminVO}
+// @Router /api/admin/users/{id}/disable [put]
+func (h *UserAdminHandler) Disable(c *gin.Context) {
+	h.handleAction(c, h.service.Disable, "账号已停用")
+}
+
+// Unblock 解除爽约封禁
+// @Summary 解除爽约封禁
+// @Description 解除因爽约导致的预约封禁，可选同时清零爽约次数，需填写操作原因
+// @Tags 用户管理（后台）
+// @Accept json
+// @Produce json
+// @Security BearerAdmin
+// @Param id path int true "用户ID"
+// @Param request body service.UserAdminActionRequest true "操作原因"
+// @Success 200 {object} response.Response{data=model.UserAdminVO}
+// @Router /api/admin/users/{id}/unblock [put]
+func (h *UserAdminHandler) Unblock(c *gin.Context) {
+	h.handleAction(c, h.service.Unblock, "已解除封禁")
+}
+
+// handleAction 处理启用/停用/解封请求
+func (h *UserAdminHandler) handleAction(c *gin.Context, action func(int64, string, int64, *service.UserAdminActionRequest) (*model.UserAdminVO, error), message string) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Fail(c, errorcode.ErrInvalidIDFormat)
+		return
+	}
+
+	var req service.UserAdminActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	user, err := action(middleware.GetAdminID(c), middleware.GetAdminUsername(c), id, &req)
+	if err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, message, user)
+}
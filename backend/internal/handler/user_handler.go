@@ -6,7 +6,6 @@ import (
 	"huaan-medical/internal/middleware"
 	"huaan-medical/internal/service"
 	"huaan-medical/pkg/errorcode"
-	"huaan-medical/pkg/jwt"
 	"huaan-medical/pkg/response"
 )
 
@@ -38,7 +37,7 @@ func (h *UserHandler) WeChatLogin(c *gin.Context) {
 		return
 	}
 
-	result, err := h.service.WeChatLogin(&req, c.ClientIP())
+	result, err := h.service.WeChatLogin(&req, c.ClientIP(), c.Request.UserAgent())
 	if err != nil {
 		response.FailWithError(c, err)
 		return
@@ -111,7 +110,7 @@ type RefreshTokenRequest struct {
 
 // RefreshToken 刷新Token
 // @Summary 刷新Token
-// @Description 使用refresh_token刷新access_token
+// @Description 使用refresh_token换取新的Token对；refresh_token一次有效，重复使用将使该登录会话失效
 // @Tags 用户
 // @Accept json
 // @Produce json
@@ -126,9 +125,9 @@ func (h *UserHandler) RefreshToken(c *gin.Context) {
 	}
 
 	// 刷新Token
-	tokenPair, err := jwt.RefreshAccessToken(req.RefreshToken)
+	tokenPair, err := h.service.RefreshToken(req.RefreshToken, c.ClientIP())
 	if err != nil {
-		response.FailWithMessage(c, errorcode.ErrUnauthorized, err.Error())
+		response.FailWithError(c, err)
 		return
 	}
 
@@ -151,7 +150,7 @@ func (h *UserHandler) Register(c *gin.Context) {
 		return
 	}
 
-	result, err := h.service.Register(&req, c.ClientIP())
+	result, err := h.service.Register(&req, c.ClientIP(), c.Request.UserAgent())
 	if err != nil {
 		response.FailWithError(c, err)
 		return
@@ -176,7 +175,7 @@ func (h *UserHandler) PasswordLogin(c *gin.Context) {
 		return
 	}
 
-	result, err := h.service.PasswordLogin(&req, c.ClientIP())
+	result, err := h.service.PasswordLogin(&req, c.ClientIP(), c.Request.UserAgent())
 	if err != nil {
 		response.FailWithError(c, err)
 		return
@@ -201,7 +200,7 @@ func (h *UserHandler) PhoneLogin(c *gin.Context) {
 		return
 	}
 
-	result, err := h.service.PhoneLogin(&req, c.ClientIP())
+	result, err := h.service.PhoneLogin(&req, c.ClientIP(), c.Request.UserAgent())
 	if err != nil {
 		response.FailWithError(c, err)
 		return
@@ -209,3 +208,58 @@ func (h *UserHandler) PhoneLogin(c *gin.Context) {
 
 	response.Success(c, result)
 }
+
+// ChangePassword 修改密码
+// @Summary 修改密码
+// @Description 校验原密码后修改密码，成功后所有已登录设备需重新登录
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body service.ChangeUserPasswordRequest true "密码信息"
+// @Success 200 {object} response.Response
+// @Router /api/user/password [put]
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Fail(c, errorcode.ErrUnauthorized)
+		return
+	}
+
+	var req service.ChangeUserPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	if err := h.service.ChangePassword(userID, &req, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "密码修改成功，请重新登录", nil)
+}
+
+// ResetPassword 忘记密码
+// @Summary 忘记密码
+// @Description 通过账号绑定手机号的短信验证码重置密码，成功后所有已登录设备需重新登录
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Param request body service.ResetUserPasswordRequest true "重置信息"
+// @Success 200 {object} response.Response
+// @Router /api/user/password/reset [post]
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	var req service.ResetUserPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, errorcode.ErrBindJSON)
+		return
+	}
+
+	if err := h.service.ResetPassword(&req, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		response.FailWithError(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "密码已重置，请使用新密码登录", nil)
+}
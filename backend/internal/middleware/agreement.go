@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/internal/repository"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/logger"
+	"huaan-medical/pkg/redis"
+	"huaan-medical/pkg/response"
+)
+
+const (
+	// agreementCurrentCacheTTL 现行协议版本缓存时长（发布新版本时主动清除）
+	agreementCurrentCacheTTL = 10 * time.Minute
+	// agreementAcceptedCacheTTL 用户已同意现行版本的缓存时长
+	agreementAcceptedCacheTTL = 24 * time.Hour
+)
+
+// agreementExemptPrefixes 未同意最新协议时仍可访问的接口：
+// 查看/同意协议、账号信息、退出登录与设备管理、个人数据导出与账号注销
+var agreementExemptPrefixes = []string{
+	"/api/user/agreements",
+	"/api/user/info",
+	"/api/user/logout",
+	"/api/user/sessions",
+	"/api/user/account/",
+}
+
+// AgreementConsent 协议同意检查中间件（需在 JWTAuth 之后使用）
+// 隐私政策、用户服务协议发布新版本后，用户须重新同意才能继续使用业务接口；
+// 未同意时返回 ErrAgreementConsentRequired，客户端据此查询待同意的协议并引导用户同意
+func AgreementConsent() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := GetUserID(c)
+		if userID == 0 || isAgreementExempt(c.FullPath()) {
+			c.Next()
+			return
+		}
+
+		if !hasAcceptedAgreements(userID) {
+			response.Fail(c, errorcode.ErrAgreementConsentRequired)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// isAgreementExempt 接口是否无需同意最新协议即可访问
+func isAgreementExempt(path string) bool {
+	for _, prefix := range agreementExemptPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAcceptedAgreements 用户是否已同意全部必须同意协议的现行版本
+// 优先比对Redis中缓存的已同意版本签名，未命中时查询数据库并回填缓存；查询失败时放行，避免协议检查影响正常就诊
+func hasAcceptedAgreements(userID int64) bool {
+	repo := repository.NewAgreementRepository()
+	current, err := currentRequiredAgreements(repo)
+	if err != nil {
+		logger.Error("查询现行协议版本失败", zap.Error(err))
+		return true
+	}
+	if len(current) == 0 {
+		return true
+	}
+
+	signature := model.AgreementSignature(current)
+	key := fmt.Sprintf(redis.KeyAgreementAccepted, userID)
+	if redis.IsEnabled() {
+		if accepted, err := redis.Get(context.Background(), key); err == nil && accepted == signature {
+			return true
+		}
+	}
+
+	latest, err := repo.LatestConsents(userID, 0, model.RequiredAgreementTypes())
+	if err != nil {
+		logger.Error("查询用户协议同意记录失败", zap.Int64("user_id", userID), zap.Error(err))
+		return true
+	}
+	if len(model.PendingAgreements(current, latest)) > 0 {
+		return false
+	}
+
+	if redis.IsEnabled() {
+		_ = redis.Set(context.Background(), key, signature, agreementAcceptedCacheTTL)
+	}
+	return true
+}
+
+// currentRequiredAgreements 必须同意协议的现行版本（Redis缓存，发布新版本时清除）
+func currentRequiredAgreements(repo *repository.AgreementRepository) ([]model.Agreement, error) {
+	if redis.IsEnabled() {
+		if data, err := redis.Get(context.Background(), redis.KeyAgreementCurrent); err == nil {
+			var cached []model.Agreement
+			if json.Unmarshal([]byte(data), &cached) == nil {
+				return cached, nil
+			}
+		}
+	}
+
+	current, err := repo.ListCurrent(model.RequiredAgreementTypes())
+	if err != nil {
+		return nil, err
+	}
+	if redis.IsEnabled() {
+		if data, err := json.Marshal(current); err == nil {
+			_ = redis.Set(context.Background(), redis.KeyAgreementCurrent, string(data), agreementCurrentCacheTTL)
+		}
+	}
+	return current, nil
+}
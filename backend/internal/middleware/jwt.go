@@ -2,11 +2,16 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 
+	"huaan-medical/internal/repository"
 	"huaan-medical/pkg/errorcode"
 	"huaan-medical/pkg/jwt"
 	"huaan-medical/pkg/redis"
@@ -18,12 +23,18 @@ const (
 	ContextKeyUserID = "user_id"
 	// ContextKeyOpenID OpenID上下文键
 	ContextKeyOpenID = "open_id"
+	// ContextKeySessionID 用户登录会话ID上下文键
+	ContextKeySessionID = "session_id"
 	// ContextKeyAdminID 管理员ID上下文键
 	ContextKeyAdminID = "admin_id"
 	// ContextKeyAdminRole 管理员角色上下文键
 	ContextKeyAdminRole = "admin_role"
 	// ContextKeyAdminUsername 管理员用户名上下文键
 	ContextKeyAdminUsername = "admin_username"
+	// ContextKeyDoctorID 医生ID上下文键
+	ContextKeyDoctorID = "doctor_id"
+	// ContextKeyDoctorAccountID 医生账号ID上下文键
+	ContextKeyDoctorAccountID = "doctor_account_id"
 )
 
 // JWTAuth 用户JWT认证中间件
@@ -66,9 +77,52 @@ func JWTAuth() gin.HandlerFunc {
 			return
 		}
 
+		// 检查用户Token是否已被整体吊销（如账号注销）
+		if isUserTokenRevoked(claims) {
+			response.Fail(c, errorcode.ErrTokenInvalid)
+			c.Abort()
+			return
+		}
+
+		// 检查登录会话是否已失效（退出登录、设备下线等）
+		if isSessionRevoked(claims) {
+			response.Fail(c, errorcode.ErrTokenInvalid)
+			c.Abort()
+			return
+		}
+
 		// 将用户信息存入上下文
 		c.Set(ContextKeyUserID, claims.UserID)
 		c.Set(ContextKeyOpenID, claims.OpenID)
+		c.Set(ContextKeySessionID, claims.SessionID)
+		c.Next()
+	}
+}
+
+// JWTOptionalAuth 用户JWT可选认证中间件（公开接口使用）
+// 携带有效用户Token时将用户信息存入上下文，未携带或Token无效时按未登录继续处理
+func JWTOptionalAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := extractToken(c)
+		if token == "" {
+			c.Next()
+			return
+		}
+
+		if redis.IsEnabled() {
+			blacklistKey := fmt.Sprintf(redis.KeyTokenBlacklist, token)
+			if exists, _ := redis.Exists(context.Background(), blacklistKey); exists {
+				c.Next()
+				return
+			}
+		}
+
+		claims, err := jwt.ParseToken(token)
+		if err == nil && claims.TokenType == jwt.AccessToken && !isUserTokenRevoked(claims) && !isSessionRevoked(claims) {
+			c.Set(ContextKeyUserID, claims.UserID)
+			c.Set(ContextKeyOpenID, claims.OpenID)
+			c.Set(ContextKeySessionID, claims.SessionID)
+		}
 		c.Next()
 	}
 }
@@ -114,6 +168,94 @@ func JWTAdminAuth() gin.HandlerFunc {
 	}
 }
 
+// JWTDoctorAuth 医生JWT认证中间件
+func JWTDoctorAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := extractToken(c)
+		if token == "" {
+			response.Fail(c, errorcode.ErrUnauthorized)
+			c.Abort()
+			return
+		}
+
+		// 检查Token是否在黑名单中（需要Redis）
+		if redis.IsEnabled() {
+			blacklistKey := fmt.Sprintf(redis.KeyTokenBlacklist, token)
+			exists, _ := redis.Exists(context.Background(), blacklistKey)
+			if exists {
+				response.Fail(c, errorcode.ErrTokenInvalid)
+				c.Abort()
+				return
+			}
+		}
+
+		// 解析Token
+		claims, err := jwt.ParseDoctorToken(token)
+		if err != nil {
+			if strings.Contains(err.Error(), "过期") {
+				response.Fail(c, errorcode.ErrTokenExpired)
+			} else {
+				response.Fail(c, errorcode.ErrTokenInvalid)
+			}
+			c.Abort()
+			return
+		}
+
+		// 将医生信息存入上下文
+		c.Set(ContextKeyDoctorID, claims.DoctorID)
+		c.Set(ContextKeyDoctorAccountID, claims.AccountID)
+		c.Next()
+	}
+}
+
+// isUserTokenRevoked 检查用户Token是否签发于整体吊销时间之前（需要Redis）
+// 整体吊销时已同时吊销全部登录会话，携带会话ID的Token由 isSessionRevoked 精确判断；
+// 此处仅用于会话机制上线前签发的Token，避免同一秒内重新登录签发的Token被误判为已吊销
+func isUserTokenRevoked(claims *jwt.Claims) bool {
+	if claims.SessionID != "" || !redis.IsEnabled() || claims.IssuedAt == nil {
+		return false
+	}
+
+	value, err := redis.Get(context.Background(), fmt.Sprintf(redis.KeyUserTokenRevoked, claims.UserID))
+	if err != nil {
+		return false
+	}
+	revokedAt, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return false
+	}
+	return claims.IssuedAt.Unix() <= revokedAt
+}
+
+// isSessionRevoked 检查Token所属登录会话是否已失效
+// 优先查询Redis中的有效会话缓存，未命中或Redis不可用时回退数据库（并回填缓存）
+func isSessionRevoked(claims *jwt.Claims) bool {
+	// 会话机制上线前签发的Token不含会话ID，随Access Token过期自然失效
+	if claims.SessionID == "" {
+		return false
+	}
+
+	key := fmt.Sprintf(redis.KeyRefreshToken, claims.SessionID)
+	if redis.IsEnabled() {
+		if exists, err := redis.Exists(context.Background(), key); err == nil && exists {
+			return false
+		}
+	}
+
+	session, err := repository.NewSessionRepository().GetBySessionID(claims.SessionID)
+	if err != nil {
+		return errors.Is(err, gorm.ErrRecordNotFound)
+	}
+	if session.UserID != claims.UserID || !session.IsActive(time.Now()) {
+		return true
+	}
+
+	if redis.IsEnabled() {
+		_ = redis.Set(context.Background(), key, session.RefreshID, time.Until(session.ExpiresAt))
+	}
+	return false
+}
+
 // extractToken 从请求头中提取Token
 func extractToken(c *gin.Context) string {
 	authHeader := c.GetHeader("Authorization")
@@ -138,6 +280,14 @@ func GetUserID(c *gin.Context) int64 {
 	return 0
 }
 
+// GetSessionID 从上下文获取用户登录会话ID
+func GetSessionID(c *gin.Context) string {
+	if sessionID, exists := c.Get(ContextKeySessionID); exists {
+		return sessionID.(string)
+	}
+	return ""
+}
+
 // GetOpenID 从上下文获取OpenID
 func GetOpenID(c *gin.Context) string {
 	if openID, exists := c.Get(ContextKeyOpenID); exists {
@@ -169,3 +319,19 @@ func GetAdminUsername(c *gin.Context) string {
 	}
 	return ""
 }
+
+// GetDoctorID 从上下文获取医生ID
+func GetDoctorID(c *gin.Context) int64 {
+	if doctorID, exists := c.Get(ContextKeyDoctorID); exists {
+		return doctorID.(int64)
+	}
+	return 0
+}
+
+// GetDoctorAccountID 从上下文获取医生账号ID
+func GetDoctorAccountID(c *gin.Context) int64 {
+	if accountID, exists := c.Get(ContextKeyDoctorAccountID); exists {
+		return accountID.(int64)
+	}
+	return 0
+}
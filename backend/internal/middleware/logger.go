@@ -167,6 +167,15 @@ func inferModuleAction(method, path string) (module string, action string) {
 	if path == "/api/admin/login" {
 		return "admin", "login"
 	}
+	if module == "agreements" && len(parts) == 5 && parts[4] == "publish" {
+		return module, "publish"
+	}
+	if module == "users" && len(parts) == 5 {
+		switch parts[4] {
+		case "enable", "disable", "unblock":
+			return module, parts[4]
+		}
+	}
 
 	switch method {
 	case "GET":
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"errors"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -17,6 +18,11 @@ import (
 const (
 	// ContextKeyAdminPermissions 管理员权限码上下文键
 	ContextKeyAdminPermissions = "admin_permissions"
+	// ContextKeyAdminCampusScope 管理员院区权限范围上下文键（仅凭院区角色获得权限时设置）
+	ContextKeyAdminCampusScope = "admin_campus_scope"
+
+	// HeaderCampusID 院区ID请求头，院区管理员以此声明当前操作的院区
+	HeaderCampusID = "X-Campus-ID"
 )
 
 // AdminRBAC 管理后台 RBAC 鉴权中间件
@@ -86,11 +92,34 @@ func AdminRBAC() gin.HandlerFunc {
 			}
 		}
 
-		allowed := false
-		for _, p := range required {
-			if _, ok := permSet[p]; ok {
-				allowed = true
-				break
+		allowed := hasAnyPermission(permSet, required)
+
+		// 全院角色无权限时，按请求声明的院区检查院区角色，通过后限定数据范围为该院区；
+		// 仅支持院区范围的接口才检查院区角色，其余接口默认拒绝
+		if !allowed && rbac.IsCampusScopedRoute(key) {
+			if campusID := requestCampusID(c); campusID > 0 {
+				campusPerms := map[string]struct{}{}
+				campusSuper := false
+				for _, cr := range admin.CampusRoles {
+					if cr.CampusID != campusID || cr.Role == nil {
+						continue
+					}
+					if cr.Role.Code == rbac.RoleSuperAdmin {
+						campusSuper = true
+					}
+					for _, p := range cr.Role.Permissions {
+						if p.Code != "" {
+							campusPerms[p.Code] = struct{}{}
+						}
+					}
+				}
+				if campusSuper || hasAnyPermission(campusPerms, required) {
+					allowed = true
+					for code := range campusPerms {
+						permSet[code] = struct{}{}
+					}
+					c.Set(ContextKeyAdminCampusScope, campusID)
+				}
 			}
 		}
 		if !allowed {
@@ -109,3 +138,34 @@ func AdminRBAC() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// GetAdminCampusScope 获取当前请求的院区权限范围，0 表示拥有全院权限
+func GetAdminCampusScope(c *gin.Context) int64 {
+	if campusID, exists := c.Get(ContextKeyAdminCampusScope); exists {
+		return campusID.(int64)
+	}
+	return 0
+}
+
+// requestCampusID 解析请求声明的院区ID（请求头优先，其次为 campus_id 查询参数）
+func requestCampusID(c *gin.Context) int64 {
+	value := c.GetHeader(HeaderCampusID)
+	if value == "" {
+		value = c.Query("campus_id")
+	}
+	campusID, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || campusID <= 0 {
+		return 0
+	}
+	return campusID
+}
+
+// hasAnyPermission 判断权限集合是否包含任一所需权限
+func hasAnyPermission(permSet map[string]struct{}, required []string) bool {
+	for _, p := range required {
+		if _, ok := permSet[p]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,225 @@
+package model
+
+import (
+	"time"
+)
+
+// 个人数据导出格式
+const (
+	DataExportFormatJSON = "json" // JSON 归档
+	DataExportFormatPDF  = "pdf"  // PDF 文档
+)
+
+// 个人数据导出状态
+const (
+	DataExportStatusPending    = "pending"    // 排队中
+	DataExportStatusProcessing = "processing" // 生成中
+	DataExportStatusCompleted  = "completed"  // 已完成，可下载
+	DataExportStatusFailed     = "failed"     // 生成失败
+	DataExportStatusExpired    = "expired"    // 已过期（文件已删除）
+)
+
+// 账号注销申请状态
+const (
+	AccountDeletionStatusPending   = "pending"   // 冷静期中
+	AccountDeletionStatusCancelled = "cancelled" // 用户已撤回
+	AccountDeletionStatusCompleted = "completed" // 已注销（个人信息已匿名化）
+)
+
+// 站内消息类型
+const (
+	NotificationTypeDataExport      = "data_export"      // 个人数据导出完成
+	NotificationTypeAccountDeletion = "account_deletion" // 账号注销进度
+)
+
+// 注销后保留记录使用的匿名化占位
+const (
+	AnonymizedUserNickname = "已注销用户"
+	AnonymizedPatientName  = "已注销"
+)
+
+// DataExport 个人数据导出任务（《个人信息保护法》查阅复制权）
+// 文件保存在非公开目录，仅本人可在有效期内下载，过期后由定时任务删除
+type DataExport struct {
+	BaseModel
+	UserID      int64      `gorm:"index;not null;comment:用户ID" json:"user_id"`
+	Format      string     `gorm:"type:varchar(10);not null;comment:导出格式 json/pdf" json:"format"`
+	Status      string     `gorm:"type:varchar(20);index;default:'pending';comment:状态" json:"status"`
+	FilePath    string     `gorm:"type:varchar(256);comment:文件路径" json:"-"`
+	FileSize    int64      `gorm:"default:0;comment:文件大小（字节）" json:"file_size"`
+	ErrorMsg    string     `gorm:"type:varchar(256);comment:失败原因" json:"error_msg"`
+	RequestIP   string     `gorm:"type:varchar(64);comment:申请IP" json:"-"`
+	CompletedAt *time.Time `gorm:"comment:生成完成时间" json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `gorm:"index;comment:下载截止时间" json:"expires_at,omitempty"`
+}
+
+// TableName 表名
+func (DataExport) TableName() string {
+	return "data_exports"
+}
+
+// DataExportVO 个人数据导出视图对象
+type DataExportVO struct {
+	ID           int64  `json:"id"`
+	Format       string `json:"format"`
+	Status       string `json:"status"`
+	StatusName   string `json:"status_name"`
+	FileSize     int64  `json:"file_size"`
+	ErrorMsg     string `json:"error_msg,omitempty"`
+	CreatedAt    string `json:"created_at"`
+	CompletedAt  string `json:"completed_at,omitempty"`
+	ExpiresAt    string `json:"expires_at,omitempty"`
+	Downloadable bool   `json:"downloadable"`
+}
+
+// IsDownloadable 是否可下载
+func (e *DataExport) IsDownloadable() bool {
+	return e.Status == DataExportStatusCompleted && e.ExpiresAt != nil && e.ExpiresAt.After(time.Now())
+}
+
+// ToVO 转换为视图对象
+func (e *DataExport) ToVO() *DataExportVO {
+	vo := &DataExportVO{
+		ID:           e.ID,
+		Format:       e.Format,
+		Status:       e.Status,
+		StatusName:   getDataExportStatusName(e.Status),
+		FileSize:     e.FileSize,
+		ErrorMsg:     e.ErrorMsg,
+		CreatedAt:    e.CreatedAt.Format("2006-01-02 15:04:05"),
+		Downloadable: e.IsDownloadable(),
+	}
+	if e.CompletedAt != nil {
+		vo.CompletedAt = e.CompletedAt.Format("2006-01-02 15:04:05")
+	}
+	if e.ExpiresAt != nil {
+		vo.ExpiresAt = e.ExpiresAt.Format("2006-01-02 15:04:05")
+	}
+	return vo
+}
+
+// AccountDeletion 账号注销申请
+// 冷静期内可撤回；到期后由定时任务匿名化个人信息，病历、预约等诊疗及费用记录保留
+type AccountDeletion struct {
+	BaseModel
+	UserID      int64      `gorm:"index;not null;comment:用户ID" json:"user_id"`
+	Status      string     `gorm:"type:varchar(20);index;default:'pending';comment:状态 pending/cancelled/completed" json:"status"`
+	Reason      string     `gorm:"type:varchar(256);comment:注销原因" json:"reason"`
+	RequestIP   string     `gorm:"type:varchar(64);comment:申请IP" json:"-"`
+	ScheduledAt time.Time  `gorm:"index;not null;comment:冷静期结束（计划执行）时间" json:"scheduled_at"`
+	CancelledAt *time.Time `gorm:"comment:撤回时间" json:"cancelled_at,omitempty"`
+	CompletedAt *time.Time `gorm:"comment:注销完成时间" json:"completed_at,omitempty"`
+	Remark      string     `gorm:"type:varchar(256);comment:处理说明" json:"remark"`
+}
+
+// TableName 表名
+func (AccountDeletion) TableName() string {
+	return "account_deletions"
+}
+
+// AccountDeletionVO 账号注销申请视图对象
+type AccountDeletionVO struct {
+	ID          int64  `json:"id"`
+	Status      string `json:"status"`
+	StatusName  string `json:"status_name"`
+	Reason      string `json:"reason"`
+	ScheduledAt string `json:"scheduled_at"`
+	CancelledAt string `json:"cancelled_at,omitempty"`
+	CompletedAt string `json:"completed_at,omitempty"`
+	Remark      string `json:"remark,omitempty"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// ToVO 转换为视图对象
+func (d *AccountDeletion) ToVO() *AccountDeletionVO {
+	vo := &AccountDeletionVO{
+		ID:          d.ID,
+		Status:      d.Status,
+		StatusName:  getAccountDeletionStatusName(d.Status),
+		Reason:      d.Reason,
+		ScheduledAt: d.ScheduledAt.Format("2006-01-02 15:04:05"),
+		Remark:      d.Remark,
+		CreatedAt:   d.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if d.CancelledAt != nil {
+		vo.CancelledAt = d.CancelledAt.Format("2006-01-02 15:04:05")
+	}
+	if d.CompletedAt != nil {
+		vo.CompletedAt = d.CompletedAt.Format("2006-01-02 15:04:05")
+	}
+	return vo
+}
+
+// getDataExportStatusName 获取导出状态名称
+func getDataExportStatusName(status string) string {
+	switch status {
+	case DataExportStatusPending:
+		return "排队中"
+	case DataExportStatusProcessing:
+		return "生成中"
+	case DataExportStatusCompleted:
+		return "已完成"
+	case DataExportStatusFailed:
+		return "生成失败"
+	case DataExportStatusExpired:
+		return "已过期"
+	default:
+		return "未知"
+	}
+}
+
+// getAccountDeletionStatusName 获取注销申请状态名称
+func getAccountDeletionStatusName(status string) string {
+	switch status {
+	case AccountDeletionStatusPending:
+		return "冷静期中"
+	case AccountDeletionStatusCancelled:
+		return "已撤回"
+	case AccountDeletionStatusCompleted:
+		return "已注销"
+	default:
+		return "未知"
+	}
+}
+
+// 账号合并状态
+const (
+	AccountMergeStatusPending   = "pending"   // 待确认（已验证对方账号的登录方式）
+	AccountMergeStatusCompleted = "completed" // 已合并
+)
+
+// AccountMerge 账号合并记录
+// 绑定的登录方式已属于另一账号时生成合并凭证，用户确认后将对方账号的就诊人、预约等数据
+// 并入当前账号，对方账号停用；凭证一次有效并保留为审计记录
+type AccountMerge struct {
+	BaseModel
+	TargetUserID int64      `gorm:"index;not null;comment:保留的账号（当前登录账号）" json:"target_user_id"`
+	SourceUserID int64      `gorm:"index;not null;comment:被合并的账号" json:"source_user_id"`
+	LoginType    string     `gorm:"type:varchar(20);not null;comment:触发合并的登录方式 wechat/phone/password" json:"login_type"`
+	LoginValue   string     `gorm:"type:varchar(64);comment:待绑定的登录标识（OpenID/手机号/用户名）" json:"-"`
+	UnionID      string     `gorm:"type:varchar(64);comment:待绑定的微信UnionID" json:"-"`
+	Ticket       string     `gorm:"type:varchar(64);uniqueIndex;not null;comment:合并凭证" json:"-"`
+	Status       string     `gorm:"type:varchar(20);index;default:'pending';comment:状态 pending/completed" json:"status"`
+	ExpiresAt    time.Time  `gorm:"not null;comment:凭证过期时间" json:"expires_at"`
+	CompletedAt  *time.Time `gorm:"comment:合并时间" json:"completed_at,omitempty"`
+	Summary      string     `gorm:"type:varchar(512);comment:迁移数据统计(JSON)" json:"summary"`
+}
+
+// TableName 表名
+func (AccountMerge) TableName() string {
+	return "account_merges"
+}
+
+// IsUsable 合并凭证是否仍可使用
+func (m *AccountMerge) IsUsable(now time.Time) bool {
+	return m.Status == AccountMergeStatusPending && now.Before(m.ExpiresAt)
+}
+
+// AccountMergeSummary 账号合并迁移数据统计
+type AccountMergeSummary struct {
+	Patients      int64 `json:"patients"`
+	Appointments  int64 `json:"appointments"`
+	Reviews       int64 `json:"reviews"`
+	Memberships   int64 `json:"memberships"`
+	Notifications int64 `json:"notifications"`
+}
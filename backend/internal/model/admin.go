@@ -18,7 +18,8 @@ type Admin struct {
 	LastLoginIP string     `gorm:"type:varchar(64);comment:最后登录IP" json:"last_login_ip,omitempty"`
 
 	// 关联
-	Roles []Role `gorm:"many2many:admin_roles;" json:"roles,omitempty"`
+	Roles       []Role            `gorm:"many2many:admin_roles;" json:"roles,omitempty"`
+	CampusRoles []AdminCampusRole `gorm:"foreignKey:AdminID" json:"campus_roles,omitempty"` // 院区角色
 }
 
 // TableName 表名
@@ -40,6 +41,8 @@ type AdminVO struct {
 	RoleNames   []string `json:"role_names,omitempty"`
 	LastLoginAt string   `json:"last_login_at,omitempty"`
 	CreatedAt   string   `json:"created_at"`
+
+	CampusRoles []AdminCampusRoleVO `json:"campus_roles,omitempty"` // 院区角色（仅在对应院区内生效）
 }
 
 // ToVO 转换为视图对象
@@ -74,6 +77,12 @@ func (a *Admin) ToVO() *AdminVO {
 			vo.RoleNames[i] = role.Name
 		}
 	}
+	if len(a.CampusRoles) > 0 {
+		vo.CampusRoles = make([]AdminCampusRoleVO, len(a.CampusRoles))
+		for i := range a.CampusRoles {
+			vo.CampusRoles[i] = a.CampusRoles[i].ToVO()
+		}
+	}
 
 	return vo
 }
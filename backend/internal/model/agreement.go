@@ -0,0 +1,219 @@
+package model
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// 协议类型常量
+const (
+	AgreementTypePrivacy  = "privacy"  // 隐私政策
+	AgreementTypeTerms    = "terms"    // 用户服务协议
+	AgreementTypeReferral = "referral" // 转诊病历共享授权（按就诊人单独同意，可撤回）
+)
+
+// 协议版本状态常量
+const (
+	AgreementStatusDraft     = "draft"     // 草稿，可编辑
+	AgreementStatusPublished = "published" // 已发布，内容锁定
+)
+
+// 同意记录动作常量
+const (
+	AgreementConsentAccept   = "accept"   // 同意
+	AgreementConsentWithdraw = "withdraw" // 撤回
+)
+
+// Agreement 协议版本
+// 同一类型可有多个版本，最近发布的版本为现行版本；发布后内容不可修改，如需调整请发布新版本
+type Agreement struct {
+	BaseModel
+	Type        string     `gorm:"type:varchar(20);uniqueIndex:uk_agreement_version;not null;comment:协议类型" json:"type"`
+	Version     string     `gorm:"type:varchar(32);uniqueIndex:uk_agreement_version;not null;comment:版本号" json:"version"`
+	Title       string     `gorm:"type:varchar(128);not null;comment:标题" json:"title"`
+	Content     string     `gorm:"type:longtext;comment:协议正文" json:"content"`
+	ChangeLog   string     `gorm:"type:varchar(512);comment:本版本变更说明" json:"change_log"`
+	Status      string     `gorm:"type:varchar(20);default:'draft';index;comment:状态 draft/published" json:"status"`
+	PublishedAt *time.Time `gorm:"index;comment:发布时间" json:"published_at,omitempty"`
+	PublishedBy int64      `gorm:"default:0;comment:发布管理员ID" json:"published_by"`
+	CreatedBy   int64      `gorm:"default:0;comment:创建管理员ID" json:"created_by"`
+}
+
+// TableName 表名
+func (Agreement) TableName() string {
+	return "agreements"
+}
+
+// AgreementVO 协议版本视图对象
+type AgreementVO struct {
+	ID            int64  `json:"id"`
+	Type          string `json:"type"`
+	TypeName      string `json:"type_name"`
+	Version       string `json:"version"`
+	Title         string `json:"title"`
+	Content       string `json:"content,omitempty"`
+	ChangeLog     string `json:"change_log"`
+	Status        string `json:"status"`
+	Required      bool   `json:"required"`       // 是否为使用服务必须同意的协议
+	PatientScoped bool   `json:"patient_scoped"` // 是否按就诊人单独同意
+	PublishedAt   string `json:"published_at,omitempty"`
+	CreatedAt     string `json:"created_at"`
+}
+
+// ToVO 转换为视图对象（withContent 为 false 时不返回正文，用于列表）
+func (a *Agreement) ToVO(withContent bool) *AgreementVO {
+	vo := &AgreementVO{
+		ID:            a.ID,
+		Type:          a.Type,
+		TypeName:      GetAgreementTypeName(a.Type),
+		Version:       a.Version,
+		Title:         a.Title,
+		ChangeLog:     a.ChangeLog,
+		Status:        a.Status,
+		Required:      IsAgreementRequired(a.Type),
+		PatientScoped: IsAgreementPatientScoped(a.Type),
+		CreatedAt:     a.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if withContent {
+		vo.Content = a.Content
+	}
+	if a.PublishedAt != nil {
+		vo.PublishedAt = a.PublishedAt.Format("2006-01-02 15:04:05")
+	}
+	return vo
+}
+
+// AgreementConsent 协议同意记录（只追加不修改，供审计）
+// 账号级协议 PatientID 为0；按就诊人同意的协议记录就诊人ID。
+// 当前是否同意以同一账号（及就诊人）该类型最近一条记录为准
+type AgreementConsent struct {
+	ID          int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	AgreementID int64     `gorm:"index;not null;comment:协议版本ID" json:"agreement_id"`
+	Type        string    `gorm:"type:varchar(20);index:idx_agreement_consent_user;not null;comment:协议类型" json:"type"`
+	Version     string    `gorm:"type:varchar(32);not null;comment:协议版本号" json:"version"`
+	UserID      int64     `gorm:"index:idx_agreement_consent_user;not null;comment:用户ID" json:"user_id"`
+	PatientID   int64     `gorm:"default:0;index;comment:就诊人ID（账号级协议为0）" json:"patient_id"`
+	Action      string    `gorm:"type:varchar(20);not null;comment:动作 accept/withdraw" json:"action"`
+	IP          string    `gorm:"type:varchar(64);comment:IP地址" json:"ip"`
+	UserAgent   string    `gorm:"type:varchar(256);comment:客户端UA" json:"user_agent"`
+	CreatedAt   time.Time `gorm:"autoCreateTime;index;comment:操作时间" json:"created_at"`
+
+	// 关联
+	User    *User    `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Patient *Patient `gorm:"foreignKey:PatientID" json:"patient,omitempty"`
+}
+
+// TableName 表名
+func (AgreementConsent) TableName() string {
+	return "agreement_consents"
+}
+
+// AgreementConsentVO 协议同意记录视图对象
+type AgreementConsentVO struct {
+	ID          int64  `json:"id"`
+	AgreementID int64  `json:"agreement_id"`
+	Type        string `json:"type"`
+	TypeName    string `json:"type_name"`
+	Version     string `json:"version"`
+	UserID      int64  `json:"user_id"`
+	Nickname    string `json:"nickname,omitempty"`
+	Phone       string `json:"phone,omitempty"` // 脱敏后的账号手机号
+	PatientID   int64  `json:"patient_id"`
+	PatientName string `json:"patient_name,omitempty"` // 脱敏后的就诊人姓名
+	Action      string `json:"action"`
+	ActionName  string `json:"action_name"`
+	IP          string `json:"ip"`
+	UserAgent   string `json:"user_agent"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// ToVO 转换为视图对象
+func (c *AgreementConsent) ToVO() *AgreementConsentVO {
+	vo := &AgreementConsentVO{
+		ID:          c.ID,
+		AgreementID: c.AgreementID,
+		Type:        c.Type,
+		TypeName:    GetAgreementTypeName(c.Type),
+		Version:     c.Version,
+		UserID:      c.UserID,
+		PatientID:   c.PatientID,
+		Action:      c.Action,
+		ActionName:  getAgreementConsentActionName(c.Action),
+		IP:          c.IP,
+		UserAgent:   c.UserAgent,
+		CreatedAt:   c.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if c.User != nil {
+		vo.Nickname = c.User.Nickname
+		vo.Phone = maskPhone(c.User.Phone)
+	}
+	if c.Patient != nil {
+		vo.PatientName = maskName(c.Patient.Name)
+	}
+	return vo
+}
+
+// IsAgreementRequired 是否为使用服务必须同意的协议（新版本发布后需重新同意）
+func IsAgreementRequired(agreementType string) bool {
+	return agreementType == AgreementTypePrivacy || agreementType == AgreementTypeTerms
+}
+
+// IsAgreementPatientScoped 是否按就诊人单独同意
+func IsAgreementPatientScoped(agreementType string) bool {
+	return agreementType == AgreementTypeReferral
+}
+
+// RequiredAgreementTypes 必须同意的协议类型
+func RequiredAgreementTypes() []string {
+	return []string{AgreementTypePrivacy, AgreementTypeTerms}
+}
+
+// PendingAgreements 筛选用户尚未同意现行版本的协议
+// latest 为用户各类型最近一条同意记录
+func PendingAgreements(current []Agreement, latest map[string]*AgreementConsent) []Agreement {
+	pending := make([]Agreement, 0, len(current))
+	for _, a := range current {
+		c := latest[a.Type]
+		if c == nil || c.AgreementID != a.ID || c.Action != AgreementConsentAccept {
+			pending = append(pending, a)
+		}
+	}
+	return pending
+}
+
+// AgreementSignature 现行协议版本签名（类型:版本号，排序后拼接），用于缓存用户是否已同意全部现行版本
+func AgreementSignature(current []Agreement) string {
+	parts := make([]string, 0, len(current))
+	for _, a := range current {
+		parts = append(parts, a.Type+":"+a.Version)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+// GetAgreementTypeName 获取协议类型名称
+func GetAgreementTypeName(agreementType string) string {
+	switch agreementType {
+	case AgreementTypePrivacy:
+		return "隐私政策"
+	case AgreementTypeTerms:
+		return "用户服务协议"
+	case AgreementTypeReferral:
+		return "转诊病历共享授权"
+	default:
+		return agreementType
+	}
+}
+
+// getAgreementConsentActionName 获取同意记录动作名称
+func getAgreementConsentActionName(action string) string {
+	switch action {
+	case AgreementConsentAccept:
+		return "同意"
+	case AgreementConsentWithdraw:
+		return "撤回"
+	default:
+		return action
+	}
+}
@@ -13,12 +13,16 @@ type Appointment struct {
 	DoctorID        int64      `gorm:"index;not null;comment:医生ID" json:"doctor_id"`
 	DepartmentID    int64      `gorm:"index;not null;comment:科室ID" json:"department_id"`
 	ScheduleID      int64      `gorm:"index;not null;comment:排班ID" json:"schedule_id"`
+	CampusID        int64      `gorm:"index;default:0;comment:就诊院区ID" json:"campus_id"`
+	RoomID          int64      `gorm:"default:0;comment:诊室ID" json:"room_id"`
 	AppointmentDate time.Time  `gorm:"type:date;index;not null;comment:预约日期" json:"appointment_date"`
 	Period          string     `gorm:"type:varchar(20);not null;comment:时段" json:"period"`
 	AppointmentTime string     `gorm:"type:varchar(10);not null;comment:预约时间 HH:mm" json:"appointment_time"`
 	SlotNumber      int        `gorm:"type:int;not null;comment:号序" json:"slot_number"`
 	Status          string     `gorm:"type:varchar(20);default:'pending';index;comment:状态" json:"status"`
 	Symptom         string     `gorm:"type:varchar(512);comment:症状描述" json:"symptom"`
+	Channel         string     `gorm:"type:varchar(20);default:'online';index;comment:预约渠道 online/onsite/vip" json:"channel"`
+	SlotChannel     string     `gorm:"type:varchar(20);default:'online';comment:号源来源 online/onsite/vip（预留号源转入公共池后占用公共号源时为online）" json:"slot_channel"`
 	CancelReason    string     `gorm:"type:varchar(256);comment:取消原因" json:"cancel_reason"`
 	CancelledAt     *time.Time `gorm:"comment:取消时间" json:"cancelled_at,omitempty"`
 	CheckedInAt     *time.Time `gorm:"comment:签到时间" json:"checked_in_at,omitempty"`
@@ -30,6 +34,8 @@ type Appointment struct {
 	Doctor     *Doctor     `gorm:"foreignKey:DoctorID" json:"doctor,omitempty"`
 	Department *Department `gorm:"foreignKey:DepartmentID" json:"department,omitempty"`
 	Schedule   *Schedule   `gorm:"foreignKey:ScheduleID" json:"schedule,omitempty"`
+	Campus     *Campus     `gorm:"foreignKey:CampusID" json:"campus,omitempty"`
+	Room       *Room       `gorm:"foreignKey:RoomID" json:"room,omitempty"`
 }
 
 // TableName 表名
@@ -58,13 +64,24 @@ type AppointmentVO struct {
 	Status          string `json:"status"`
 	StatusName      string `json:"status_name"`
 	Symptom         string `json:"symptom,omitempty"`
+	Channel         string `json:"channel"`
+	ChannelName     string `json:"channel_name"`
 	CancelReason    string `json:"cancel_reason,omitempty"`
 	CancelledAt     string `json:"cancelled_at,omitempty"`
 	CheckedInAt     string `json:"checked_in_at,omitempty"`
 	CompletedAt     string `json:"completed_at,omitempty"`
 	CreatedAt       string `json:"created_at"`
-	CanCancel       bool   `json:"can_cancel"`   // 是否可取消
-	CanCheckin      bool   `json:"can_checkin"`  // 是否可签到
+	CanCancel       bool   `json:"can_cancel"`               // 是否可取消
+	CanCheckin      bool   `json:"can_checkin"`              // 是否可签到
+	TriageWarning   string `json:"triage_warning,omitempty"` // 症状与所选科室不太匹配的提示（仅预约时返回）
+
+	CampusID      int64   `json:"campus_id"`
+	CampusName    string  `json:"campus_name,omitempty"`
+	CampusAddress string  `json:"campus_address,omitempty"`
+	CampusPhone   string  `json:"campus_phone,omitempty"`
+	Latitude      float64 `json:"latitude,omitempty"`  // 院区纬度（用于地图导航）
+	Longitude     float64 `json:"longitude,omitempty"` // 院区经度（用于地图导航）
+	RoomName      string  `json:"room_name,omitempty"` // 诊室（含位置）
 }
 
 // ToVO 转换为视图对象
@@ -84,8 +101,12 @@ func (a *Appointment) ToVO() *AppointmentVO {
 		Status:          a.Status,
 		StatusName:      GetAppointmentStatusName(a.Status),
 		Symptom:         a.Symptom,
+		Channel:         a.Channel,
+		ChannelName:     GetChannelName(a.Channel),
 		CancelReason:    a.CancelReason,
 		CreatedAt:       a.CreatedAt.Format("2006-01-02 15:04:05"),
+
+		CampusID: a.CampusID,
 	}
 
 	// 关联信息
@@ -100,6 +121,16 @@ func (a *Appointment) ToVO() *AppointmentVO {
 	if a.Department != nil {
 		vo.DepartmentName = a.Department.Name
 	}
+	if a.Campus != nil {
+		vo.CampusName = a.Campus.Name
+		vo.CampusAddress = a.Campus.Address
+		vo.CampusPhone = a.Campus.Phone
+		vo.Latitude = a.Campus.Latitude
+		vo.Longitude = a.Campus.Longitude
+	}
+	if a.Room != nil {
+		vo.RoomName = a.Room.FullName()
+	}
 
 	// 时间格式化
 	if a.CancelledAt != nil {
@@ -171,6 +202,9 @@ type AppointmentListVO struct {
 	StatusName      string `json:"status_name"`
 	CanCancel       bool   `json:"can_cancel"`
 	CanCheckin      bool   `json:"can_checkin"`
+
+	CampusName    string `json:"campus_name,omitempty"`
+	CampusAddress string `json:"campus_address,omitempty"`
 }
 
 // ToListVO 转换为列表视图对象
@@ -197,6 +231,88 @@ func (a *Appointment) ToListVO() *AppointmentListVO {
 	if a.Department != nil {
 		vo.DepartmentName = a.Department.Name
 	}
+	if a.Campus != nil {
+		vo.CampusName = a.Campus.Name
+		vo.CampusAddress = a.Campus.Address
+	}
+
+	return vo
+}
+
+// LocationText 就诊地点描述（院区名称、地址及诊室），未关联院区时返回空
+func (a *Appointment) LocationText() string {
+	if a.Campus == nil {
+		return ""
+	}
+	text := a.Campus.Name
+	if a.Campus.Address != "" {
+		text += "（" + a.Campus.Address + "）"
+	}
+	if a.Room != nil {
+		text += " " + a.Room.FullName()
+	}
+	return text
+}
+
+// DoctorAppointmentVO 医生工作台预约视图对象（接诊需要，患者姓名不脱敏）
+type DoctorAppointmentVO struct {
+	ID              int64  `json:"id"`
+	AppointmentNo   string `json:"appointment_no"`
+	PatientID       int64  `json:"patient_id"`
+	PatientName     string `json:"patient_name"`
+	PatientGender   string `json:"patient_gender"`
+	PatientAge      int    `json:"patient_age"`
+	DepartmentID    int64  `json:"department_id"`
+	DepartmentName  string `json:"department_name"`
+	ScheduleID      int64  `json:"schedule_id"`
+	AppointmentDate string `json:"appointment_date"`
+	Period          string `json:"period"`
+	PeriodName      string `json:"period_name"`
+	AppointmentTime string `json:"appointment_time"`
+	SlotNumber      int    `json:"slot_number"`
+	Status          string `json:"status"`
+	StatusName      string `json:"status_name"`
+	Symptom         string `json:"symptom"`
+	Channel         string `json:"channel"`
+	ChannelName     string `json:"channel_name"`
+	CheckedInAt     string `json:"checked_in_at,omitempty"`
+	CompletedAt     string `json:"completed_at,omitempty"`
+}
+
+// ToDoctorVO 转换为医生工作台视图对象
+func (a *Appointment) ToDoctorVO() *DoctorAppointmentVO {
+	vo := &DoctorAppointmentVO{
+		ID:              a.ID,
+		AppointmentNo:   a.AppointmentNo,
+		PatientID:       a.PatientID,
+		DepartmentID:    a.DepartmentID,
+		ScheduleID:      a.ScheduleID,
+		AppointmentDate: a.AppointmentDate.Format("2006-01-02"),
+		Period:          a.Period,
+		PeriodName:      GetPeriodName(a.Period),
+		AppointmentTime: a.AppointmentTime,
+		SlotNumber:      a.SlotNumber,
+		Status:          a.Status,
+		StatusName:      GetAppointmentStatusName(a.Status),
+		Symptom:         a.Symptom,
+		Channel:         a.Channel,
+		ChannelName:     GetChannelName(a.Channel),
+	}
+
+	if a.Patient != nil {
+		vo.PatientName = a.Patient.Name
+		vo.PatientGender = getGenderName(a.Patient.Gender)
+		vo.PatientAge = a.Patient.Age()
+	}
+	if a.Department != nil {
+		vo.DepartmentName = a.Department.Name
+	}
+	if a.CheckedInAt != nil {
+		vo.CheckedInAt = a.CheckedInAt.Format("2006-01-02 15:04:05")
+	}
+	if a.CompletedAt != nil {
+		vo.CompletedAt = a.CompletedAt.Format("2006-01-02 15:04:05")
+	}
 
 	return vo
 }
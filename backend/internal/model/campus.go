@@ -0,0 +1,166 @@
+package model
+
+// Campus 院区模型
+type Campus struct {
+	BaseModel
+	Name         string  `gorm:"type:varchar(64);not null;comment:院区名称" json:"name"`
+	Address      string  `gorm:"type:varchar(256);not null;comment:院区地址" json:"address"`
+	Latitude     float64 `gorm:"type:decimal(10,6);default:0;comment:纬度" json:"latitude"`
+	Longitude    float64 `gorm:"type:decimal(10,6);default:0;comment:经度" json:"longitude"`
+	Phone        string  `gorm:"type:varchar(32);comment:联系电话" json:"phone"`
+	OpeningHours string  `gorm:"type:varchar(128);comment:开放时间" json:"opening_hours"`
+	Description  string  `gorm:"type:varchar(512);comment:院区简介" json:"description"`
+	SortOrder    int     `gorm:"type:int;default:0;comment:排序序号" json:"sort_order"`
+	Status       int     `gorm:"type:tinyint;default:1;index;comment:状态 0停用 1启用" json:"status"`
+}
+
+// TableName 表名
+func (Campus) TableName() string {
+	return "campuses"
+}
+
+// CampusVO 院区视图对象
+type CampusVO struct {
+	ID           int64   `json:"id"`
+	Name         string  `json:"name"`
+	Address      string  `json:"address"`
+	Latitude     float64 `json:"latitude"`
+	Longitude    float64 `json:"longitude"`
+	Phone        string  `json:"phone"`
+	OpeningHours string  `json:"opening_hours"`
+	Description  string  `json:"description"`
+	SortOrder    int     `json:"sort_order"`
+	Status       int     `json:"status"`
+	StatusName   string  `json:"status_name"`
+}
+
+// ToVO 转换为视图对象
+func (c *Campus) ToVO() *CampusVO {
+	statusName := "启用"
+	if c.Status == StatusDisabled {
+		statusName = "停用"
+	}
+	return &CampusVO{
+		ID:           c.ID,
+		Name:         c.Name,
+		Address:      c.Address,
+		Latitude:     c.Latitude,
+		Longitude:    c.Longitude,
+		Phone:        c.Phone,
+		OpeningHours: c.OpeningHours,
+		Description:  c.Description,
+		SortOrder:    c.SortOrder,
+		Status:       c.Status,
+		StatusName:   statusName,
+	}
+}
+
+// Room 诊室模型
+// 诊室归属于院区，可指定所属科室（0表示院区公共诊室）
+type Room struct {
+	BaseModel
+	CampusID     int64  `gorm:"index;not null;comment:所属院区ID" json:"campus_id"`
+	DepartmentID int64  `gorm:"index;default:0;comment:所属科室ID 0为公共诊室" json:"department_id"`
+	Name         string `gorm:"type:varchar(64);not null;comment:诊室名称" json:"name"`
+	Location     string `gorm:"type:varchar(128);comment:位置(楼栋/楼层)" json:"location"`
+	SortOrder    int    `gorm:"type:int;default:0;comment:排序序号" json:"sort_order"`
+	Status       int    `gorm:"type:tinyint;default:1;comment:状态 0停用 1启用" json:"status"`
+
+	// 关联
+	Campus     *Campus     `gorm:"foreignKey:CampusID" json:"campus,omitempty"`
+	Department *Department `gorm:"foreignKey:DepartmentID" json:"department,omitempty"`
+}
+
+// TableName 表名
+func (Room) TableName() string {
+	return "rooms"
+}
+
+// RoomVO 诊室视图对象
+type RoomVO struct {
+	ID             int64  `json:"id"`
+	CampusID       int64  `json:"campus_id"`
+	CampusName     string `json:"campus_name,omitempty"`
+	DepartmentID   int64  `json:"department_id"`
+	DepartmentName string `json:"department_name,omitempty"`
+	Name           string `json:"name"`
+	Location       string `json:"location"`
+	SortOrder      int    `json:"sort_order"`
+	Status         int    `json:"status"`
+	StatusName     string `json:"status_name"`
+}
+
+// ToVO 转换为视图对象
+func (r *Room) ToVO() *RoomVO {
+	statusName := "启用"
+	if r.Status == StatusDisabled {
+		statusName = "停用"
+	}
+	vo := &RoomVO{
+		ID:           r.ID,
+		CampusID:     r.CampusID,
+		DepartmentID: r.DepartmentID,
+		Name:         r.Name,
+		Location:     r.Location,
+		SortOrder:    r.SortOrder,
+		Status:       r.Status,
+		StatusName:   statusName,
+	}
+	if r.Campus != nil {
+		vo.CampusName = r.Campus.Name
+	}
+	if r.Department != nil {
+		vo.DepartmentName = r.Department.Name
+	}
+	return vo
+}
+
+// FullName 诊室完整名称（含位置）
+func (r *Room) FullName() string {
+	if r.Location == "" {
+		return r.Name
+	}
+	return r.Location + " " + r.Name
+}
+
+// AdminCampusRole 管理员院区角色关联表
+// 管理员在指定院区内拥有该角色的权限（全院角色见 admin_roles）
+type AdminCampusRole struct {
+	AdminID  int64 `gorm:"primaryKey" json:"admin_id"`
+	CampusID int64 `gorm:"primaryKey" json:"campus_id"`
+	RoleID   int64 `gorm:"primaryKey" json:"role_id"`
+
+	// 关联
+	Campus *Campus `gorm:"foreignKey:CampusID" json:"campus,omitempty"`
+	Role   *Role   `gorm:"foreignKey:RoleID" json:"role,omitempty"`
+}
+
+// TableName 表名
+func (AdminCampusRole) TableName() string {
+	return "admin_campus_roles"
+}
+
+// AdminCampusRoleVO 管理员院区角色视图对象
+type AdminCampusRoleVO struct {
+	CampusID   int64  `json:"campus_id"`
+	CampusName string `json:"campus_name"`
+	RoleID     int64  `json:"role_id"`
+	RoleCode   string `json:"role_code"`
+	RoleName   string `json:"role_name"`
+}
+
+// ToVO 转换为视图对象
+func (r *AdminCampusRole) ToVO() AdminCampusRoleVO {
+	vo := AdminCampusRoleVO{
+		CampusID: r.CampusID,
+		RoleID:   r.RoleID,
+	}
+	if r.Campus != nil {
+		vo.CampusName = r.Campus.Name
+	}
+	if r.Role != nil {
+		vo.RoleCode = r.Role.Code
+		vo.RoleName = r.Role.Name
+	}
+	return vo
+}
@@ -1,15 +1,26 @@
 package model
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Department 科室模型
 type Department struct {
 	BaseModel
+	CampusID    int64  `gorm:"index;default:0;comment:所属院区ID" json:"campus_id"`
+	ParentID    int64  `gorm:"index;default:0;comment:上级科室ID 0为一级科室" json:"parent_id"`
 	Name        string `gorm:"type:varchar(64);not null;comment:科室名称" json:"name"`
 	Description string `gorm:"type:varchar(512);comment:科室描述" json:"description"`
 	Icon        string `gorm:"type:varchar(256);comment:科室图标" json:"icon"`
 	SortOrder   int    `gorm:"type:int;default:0;comment:排序序号" json:"sort_order"`
 	Status      int    `gorm:"type:tinyint;default:1;comment:状态 0停用 1启用" json:"status"`
+	MinAge      int    `gorm:"type:int;default:0;comment:接诊最小年龄（含） 0不限" json:"min_age"`
+	MaxAge      int    `gorm:"type:int;default:0;comment:接诊最大年龄（含） 0不限" json:"max_age"`
+	Gender      int    `gorm:"type:tinyint;default:0;comment:接诊性别 0不限 1男 2女" json:"gender"`
 
 	// 关联
+	Campus  *Campus  `gorm:"foreignKey:CampusID" json:"campus,omitempty"`
 	Doctors []Doctor `gorm:"foreignKey:DepartmentID" json:"doctors,omitempty"`
 }
 
@@ -21,6 +32,9 @@ func (Department) TableName() string {
 // DepartmentVO 科室视图对象
 type DepartmentVO struct {
 	ID          int64  `json:"id"`
+	CampusID    int64  `json:"campus_id"`
+	CampusName  string `json:"campus_name,omitempty"`
+	ParentID    int64  `json:"parent_id"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	Icon        string `json:"icon"`
@@ -28,6 +42,12 @@ type DepartmentVO struct {
 	Status      int    `json:"status"`
 	StatusName  string `json:"status_name"`
 	DoctorCount int    `json:"doctor_count,omitempty"` // 医生数量
+	MinAge      int    `json:"min_age"`
+	MaxAge      int    `json:"max_age"`
+	Gender      int    `json:"gender"`
+	Eligibility string `json:"eligibility,omitempty"` // 接诊限制说明（不含上级科室的限制）
+
+	Children []DepartmentVO `json:"children,omitempty"` // 子科室（树形接口返回）
 }
 
 // ToVO 转换为视图对象
@@ -36,8 +56,10 @@ func (d *Department) ToVO() *DepartmentVO {
 	if d.Status == StatusDisabled {
 		statusName = "停用"
 	}
-	return &DepartmentVO{
+	vo := &DepartmentVO{
 		ID:          d.ID,
+		CampusID:    d.CampusID,
+		ParentID:    d.ParentID,
 		Name:        d.Name,
 		Description: d.Description,
 		Icon:        d.Icon,
@@ -45,5 +67,50 @@ func (d *Department) ToVO() *DepartmentVO {
 		Status:      d.Status,
 		StatusName:  statusName,
 		DoctorCount: len(d.Doctors),
+		MinAge:      d.MinAge,
+		MaxAge:      d.MaxAge,
+		Gender:      d.Gender,
+		Eligibility: describeAgeGender(d.MinAge, d.MaxAge, d.Gender),
+	}
+	if d.Campus != nil {
+		vo.CampusName = d.Campus.Name
+	}
+	return vo
+}
+
+// EligibilityReason 检查就诊人年龄/性别是否符合本科室的接诊限制，不符合时返回原因
+// 年龄、性别未知时不做对应限制
+func (d *Department) EligibilityReason(age *int, gender int) string {
+	if age != nil && ((d.MinAge > 0 && *age < d.MinAge) || (d.MaxAge > 0 && *age > d.MaxAge)) {
+		return fmt.Sprintf("%s仅接诊%s的患者", d.Name, describeAgeRange(d.MinAge, d.MaxAge))
+	}
+	if d.Gender != GenderUnknown && gender != GenderUnknown && d.Gender != gender {
+		return fmt.Sprintf("%s仅接诊%s性患者", d.Name, getGenderName(d.Gender))
+	}
+	return ""
+}
+
+// describeAgeRange 年龄范围说明（0表示不限），不限时返回空字符串
+func describeAgeRange(minAge, maxAge int) string {
+	switch {
+	case minAge > 0 && maxAge > 0:
+		return fmt.Sprintf("%d-%d岁", minAge, maxAge)
+	case minAge > 0:
+		return fmt.Sprintf("%d岁及以上", minAge)
+	case maxAge > 0:
+		return fmt.Sprintf("%d岁及以下", maxAge)
+	}
+	return ""
+}
+
+// describeAgeGender 年龄/性别限制说明，无限制时返回空字符串
+func describeAgeGender(minAge, maxAge, gender int) string {
+	var parts []string
+	if ages := describeAgeRange(minAge, maxAge); ages != "" {
+		parts = append(parts, ages)
+	}
+	if gender != GenderUnknown {
+		parts = append(parts, getGenderName(gender)+"性")
 	}
+	return strings.Join(parts, "、")
 }
@@ -1,9 +1,14 @@
 package model
 
+import (
+	"strings"
+	"time"
+)
+
 // Doctor 医生模型
 type Doctor struct {
 	BaseModel
-	DepartmentID int64  `gorm:"index;not null;comment:所属科室ID" json:"department_id"`
+	DepartmentID int64  `gorm:"index;not null;comment:主科室ID" json:"department_id"`
 	Name         string `gorm:"type:varchar(32);not null;comment:姓名" json:"name"`
 	Avatar       string `gorm:"type:varchar(512);comment:头像URL" json:"avatar"`
 	Title        string `gorm:"type:varchar(32);not null;comment:职称" json:"title"`
@@ -12,8 +17,15 @@ type Doctor struct {
 	SortOrder    int    `gorm:"type:int;default:0;comment:排序序号" json:"sort_order"`
 	Status       int    `gorm:"type:tinyint;default:1;comment:状态 0停诊 1正常" json:"status"`
 
+	// 执业证书（到期提醒见 LicenseAlertedAt）
+	LicenseNo        string     `gorm:"type:varchar(32);comment:医师执业证书编号" json:"license_no"`
+	LicenseExpiresAt *time.Time `gorm:"type:date;index;comment:执业证书到期日期" json:"license_expires_at,omitempty"`
+	LicenseAlertedAt *time.Time `gorm:"comment:执业证书到期提醒时间" json:"license_alerted_at,omitempty"`
+
 	// 关联
-	Department *Department `gorm:"foreignKey:DepartmentID" json:"department,omitempty"`
+	Department   *Department        `gorm:"foreignKey:DepartmentID" json:"department,omitempty"`
+	Affiliations []DoctorDepartment `gorm:"foreignKey:DoctorID" json:"affiliations,omitempty"`
+	Tags         []SpecialtyTag     `gorm:"many2many:doctor_specialty_tags;" json:"tags,omitempty"`
 }
 
 // TableName 表名
@@ -35,6 +47,13 @@ type DoctorVO struct {
 	SortOrder      int    `json:"sort_order"`
 	Status         int    `json:"status"`
 	StatusName     string `json:"status_name"`
+
+	Rating      float64 `json:"rating"`       // 平均评分（未屏蔽评价）
+	ReviewCount int64   `json:"review_count"` // 评价数
+
+	Departments []DoctorDepartmentVO `json:"departments,omitempty"` // 执业科室（含主科室）
+	Tags        []SpecialtyTagVO     `json:"tags,omitempty"`        // 擅长领域标签
+	Profile     *DoctorProfileVO     `json:"profile,omitempty"`     // 结构化资料（教育经历、证书、学术任职、相册）
 }
 
 // ToVO 转换为视图对象
@@ -62,9 +81,89 @@ func (d *Doctor) ToVO() *DoctorVO {
 		vo.DepartmentName = d.Department.Name
 	}
 
+	vo.Departments = d.departmentVOs()
+	vo.Tags = d.tagVOs()
+
 	return vo
 }
 
+// MatchTitleCodes 返回名称包含关键词的职称编码（如 "主任" 匹配主任医师、副主任医师）
+func MatchTitleCodes(keyword string) []string {
+	var codes []string
+	for _, code := range []string{TitleChiefPhysician, TitleAssociateChiefPhysician, TitleAttendingPhysician, TitleResidentPhysician} {
+		if strings.Contains(GetTitleName(code), keyword) {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// HasDepartment 判断医生是否在指定科室执业（需预加载 Affiliations）
+func (d *Doctor) HasDepartment(departmentID int64) bool {
+	if d.DepartmentID == departmentID {
+		return true
+	}
+	for _, a := range d.Affiliations {
+		if a.DepartmentID == departmentID {
+			return true
+		}
+	}
+	return false
+}
+
+// PracticesAtCampus 判断医生是否在指定院区执业（主科室或任一执业科室属于该院区，需预加载科室）
+func (d *Doctor) PracticesAtCampus(campusID int64) bool {
+	if d.Department != nil && d.Department.CampusID == campusID {
+		return true
+	}
+	for _, a := range d.Affiliations {
+		if a.Department != nil && a.Department.CampusID == campusID {
+			return true
+		}
+	}
+	return false
+}
+
+// departmentVOs 执业科室视图列表（主科室在前）
+func (d *Doctor) departmentVOs() []DoctorDepartmentVO {
+	if len(d.Affiliations) == 0 {
+		return nil
+	}
+	list := make([]DoctorDepartmentVO, 0, len(d.Affiliations))
+	for _, a := range d.Affiliations {
+		if a.IsPrimary {
+			list = append([]DoctorDepartmentVO{*a.ToVO()}, list...)
+			continue
+		}
+		list = append(list, *a.ToVO())
+	}
+	return list
+}
+
+// tagVOs 擅长领域标签视图列表（仅启用的标签）
+func (d *Doctor) tagVOs() []SpecialtyTagVO {
+	if len(d.Tags) == 0 {
+		return nil
+	}
+	list := make([]SpecialtyTagVO, 0, len(d.Tags))
+	for i := range d.Tags {
+		if d.Tags[i].Status == StatusEnabled {
+			list = append(list, *d.Tags[i].ToVO())
+		}
+	}
+	return list
+}
+
+// HasTag 判断医生是否带有指定擅长领域标签（需预加载 Tags）
+func (d *Doctor) HasTag(tagID int64) bool {
+	for _, t := range d.Tags {
+		if t.ID == tagID {
+			return true
+		}
+	}
+	return false
+}
+
 // DoctorListVO 医生列表视图对象（简化版）
 type DoctorListVO struct {
 	ID             int64  `json:"id"`
@@ -77,6 +176,12 @@ type DoctorListVO struct {
 	Specialty      string `json:"specialty"`
 	Status         int    `json:"status"`
 	StatusName     string `json:"status_name"`
+
+	Rating      float64 `json:"rating"`       // 平均评分（未屏蔽评价）
+	ReviewCount int64   `json:"review_count"` // 评价数
+
+	Departments []DoctorDepartmentVO `json:"departments,omitempty"` // 执业科室（含主科室）
+	Tags        []SpecialtyTagVO     `json:"tags,omitempty"`        // 擅长领域标签
 }
 
 // ToListVO 转换为列表视图对象
@@ -102,5 +207,8 @@ func (d *Doctor) ToListVO() *DoctorListVO {
 		vo.DepartmentName = d.Department.Name
 	}
 
+	vo.Departments = d.departmentVOs()
+	vo.Tags = d.tagVOs()
+
 	return vo
 }
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"time"
+)
+
+// DoctorAccount 医生账号模型（医生工作台登录，一名医生对应一个账号）
+type DoctorAccount struct {
+	BaseModel
+	DoctorID    int64      `gorm:"uniqueIndex;not null;comment:医生ID" json:"doctor_id"`
+	Username    string     `gorm:"type:varchar(64);uniqueIndex;not null;comment:用户名" json:"username"`
+	Password    string     `gorm:"type:varchar(128);not null;comment:密码(加密)" json:"-"`
+	Phone       string     `gorm:"type:varchar(20);comment:手机号" json:"phone"`
+	Status      int        `gorm:"type:tinyint;default:1;comment:状态 0禁用 1启用" json:"status"`
+	LastLoginAt *time.Time `gorm:"comment:最后登录时间" json:"last_login_at,omitempty"`
+	LastLoginIP string     `gorm:"type:varchar(64);comment:最后登录IP" json:"last_login_ip,omitempty"`
+
+	// 关联
+	Doctor *Doctor `gorm:"foreignKey:DoctorID" json:"doctor,omitempty"`
+}
+
+// TableName 表名
+func (DoctorAccount) TableName() string {
+	return "doctor_accounts"
+}
+
+// DoctorAccountVO 医生账号视图对象
+type DoctorAccountVO struct {
+	ID             int64  `json:"id"`
+	DoctorID       int64  `json:"doctor_id"`
+	DoctorName     string `json:"doctor_name"`
+	DoctorTitle    string `json:"doctor_title"`
+	DepartmentName string `json:"department_name"`
+	Username       string `json:"username"`
+	Phone          string `json:"phone"`
+	Status         int    `json:"status"`
+	StatusName     string `json:"status_name"`
+	LastLoginAt    string `json:"last_login_at,omitempty"`
+	LastLoginIP    string `json:"last_login_ip,omitempty"`
+	CreatedAt      string `json:"created_at"`
+}
+
+// ToVO 转换为视图对象
+func (a *DoctorAccount) ToVO() *DoctorAccountVO {
+	statusName := "启用"
+	if a.Status == StatusDisabled {
+		statusName = "禁用"
+	}
+
+	vo := &DoctorAccountVO{
+		ID:          a.ID,
+		DoctorID:    a.DoctorID,
+		Username:    a.Username,
+		Phone:       a.Phone,
+		Status:      a.Status,
+		StatusName:  statusName,
+		LastLoginIP: a.LastLoginIP,
+		CreatedAt:   a.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if a.Doctor != nil {
+		vo.DoctorName = a.Doctor.Name
+		vo.DoctorTitle = GetTitleName(a.Doctor.Title)
+		if a.Doctor.Department != nil {
+			vo.DepartmentName = a.Doctor.Department.Name
+		}
+	}
+	if a.LastLoginAt != nil {
+		vo.LastLoginAt = a.LastLoginAt.Format("2006-01-02 15:04:05")
+	}
+
+	return vo
+}
@@ -0,0 +1,36 @@
+package model
+
+// DoctorDepartment 医生-科室执业关系（一名医生可在多个科室出诊）
+// 主科室同时冗余在 Doctor.DepartmentID，用于默认展示及兼容旧数据
+type DoctorDepartment struct {
+	DoctorID     int64 `gorm:"primaryKey;comment:医生ID" json:"doctor_id"`
+	DepartmentID int64 `gorm:"primaryKey;index;comment:科室ID" json:"department_id"`
+	IsPrimary    bool  `gorm:"default:false;comment:是否主科室" json:"is_primary"`
+
+	// 关联
+	Department *Department `gorm:"foreignKey:DepartmentID" json:"department,omitempty"`
+}
+
+// TableName 表名
+func (DoctorDepartment) TableName() string {
+	return "doctor_departments"
+}
+
+// DoctorDepartmentVO 医生执业科室视图对象
+type DoctorDepartmentVO struct {
+	DepartmentID   int64  `json:"department_id"`
+	DepartmentName string `json:"department_name,omitempty"`
+	IsPrimary      bool   `json:"is_primary"`
+}
+
+// ToVO 转换为视图对象
+func (d *DoctorDepartment) ToVO() *DoctorDepartmentVO {
+	vo := &DoctorDepartmentVO{
+		DepartmentID: d.DepartmentID,
+		IsPrimary:    d.IsPrimary,
+	}
+	if d.Department != nil {
+		vo.DepartmentName = d.Department.Name
+	}
+	return vo
+}
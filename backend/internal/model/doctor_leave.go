@@ -0,0 +1,103 @@
+package model
+
+import (
+	"time"
+)
+
+// 停诊申请状态常量
+const (
+	DoctorLeaveStatusPending   = "pending"   // 待审批
+	DoctorLeaveStatusApproved  = "approved"  // 已批准（已停诊）
+	DoctorLeaveStatusRejected  = "rejected"  // 已驳回
+	DoctorLeaveStatusCancelled = "cancelled" // 医生撤销
+)
+
+// PeriodAll 全天（停诊申请时段）
+const PeriodAll = "all"
+
+// DoctorLeave 医生停诊申请模型
+// 审批通过后停诊期间内的排班置为停诊，待就诊预约自动取消并通知患者
+type DoctorLeave struct {
+	BaseModel
+	DoctorID     int64      `gorm:"index;not null;comment:医生ID" json:"doctor_id"`
+	StartDate    time.Time  `gorm:"type:date;index;not null;comment:开始日期" json:"start_date"`
+	EndDate      time.Time  `gorm:"type:date;index;not null;comment:结束日期" json:"end_date"`
+	Period       string     `gorm:"type:varchar(20);default:'all';comment:时段 all/morning/afternoon" json:"period"`
+	Reason       string     `gorm:"type:varchar(256);comment:停诊原因" json:"reason"`
+	Status       string     `gorm:"type:varchar(20);default:'pending';index;comment:状态" json:"status"`
+	ReviewerID   *int64     `gorm:"comment:审批管理员ID" json:"reviewer_id,omitempty"`
+	ReviewRemark string     `gorm:"type:varchar(256);comment:审批意见" json:"review_remark"`
+	ReviewedAt   *time.Time `gorm:"comment:审批时间" json:"reviewed_at,omitempty"`
+
+	// 关联
+	Doctor *Doctor `gorm:"foreignKey:DoctorID" json:"doctor,omitempty"`
+}
+
+// TableName 表名
+func (DoctorLeave) TableName() string {
+	return "doctor_leaves"
+}
+
+// DoctorLeaveVO 停诊申请视图对象
+type DoctorLeaveVO struct {
+	ID           int64  `json:"id"`
+	DoctorID     int64  `json:"doctor_id"`
+	DoctorName   string `json:"doctor_name"`
+	StartDate    string `json:"start_date"`
+	EndDate      string `json:"end_date"`
+	Period       string `json:"period"`
+	PeriodName   string `json:"period_name"`
+	Reason       string `json:"reason"`
+	Status       string `json:"status"`
+	StatusName   string `json:"status_name"`
+	ReviewRemark string `json:"review_remark,omitempty"`
+	ReviewedAt   string `json:"reviewed_at,omitempty"`
+	CreatedAt    string `json:"created_at"`
+	CanCancel    bool   `json:"can_cancel"`
+}
+
+// ToVO 转换为视图对象
+func (l *DoctorLeave) ToVO() *DoctorLeaveVO {
+	periodName := "全天"
+	if l.Period != PeriodAll {
+		periodName = GetPeriodName(l.Period)
+	}
+
+	vo := &DoctorLeaveVO{
+		ID:           l.ID,
+		DoctorID:     l.DoctorID,
+		StartDate:    l.StartDate.Format("2006-01-02"),
+		EndDate:      l.EndDate.Format("2006-01-02"),
+		Period:       l.Period,
+		PeriodName:   periodName,
+		Reason:       l.Reason,
+		Status:       l.Status,
+		StatusName:   GetDoctorLeaveStatusName(l.Status),
+		ReviewRemark: l.ReviewRemark,
+		CreatedAt:    l.CreatedAt.Format("2006-01-02 15:04:05"),
+		CanCancel:    l.Status == DoctorLeaveStatusPending,
+	}
+
+	if l.Doctor != nil {
+		vo.DoctorName = l.Doctor.Name
+	}
+	if l.ReviewedAt != nil {
+		vo.ReviewedAt = l.ReviewedAt.Format("2006-01-02 15:04:05")
+	}
+
+	return vo
+}
+
+// GetDoctorLeaveStatusName 获取停诊申请状态名称
+func GetDoctorLeaveStatusName(status string) string {
+	statuses := map[string]string{
+		DoctorLeaveStatusPending:   "待审批",
+		DoctorLeaveStatusApproved:  "已停诊",
+		DoctorLeaveStatusRejected:  "已驳回",
+		DoctorLeaveStatusCancelled: "已撤销",
+	}
+	if name, ok := statuses[status]; ok {
+		return name
+	}
+	return status
+}
@@ -0,0 +1,267 @@
+package model
+
+import (
+	"time"
+)
+
+// 资质到期提醒的对象类型
+const (
+	QualificationTypeLicense       = "license"       // 医师执业证书
+	QualificationTypeCertification = "certification" // 资格/专项证书
+)
+
+// DoctorEducation 医生教育经历
+type DoctorEducation struct {
+	BaseModel
+	DoctorID  int64  `gorm:"index;not null;comment:医生ID" json:"doctor_id"`
+	School    string `gorm:"type:varchar(128);not null;comment:院校" json:"school"`
+	Major     string `gorm:"type:varchar(64);comment:专业" json:"major"`
+	Degree    string `gorm:"type:varchar(32);comment:学历/学位" json:"degree"`
+	StartYear int    `gorm:"type:int;default:0;comment:入学年份" json:"start_year"`
+	EndYear   int    `gorm:"type:int;default:0;comment:毕业年份 0表示至今" json:"end_year"`
+	SortOrder int    `gorm:"type:int;default:0;comment:排序序号" json:"sort_order"`
+}
+
+// TableName 表名
+func (DoctorEducation) TableName() string {
+	return "doctor_educations"
+}
+
+// DoctorEducationVO 教育经历视图对象
+type DoctorEducationVO struct {
+	School    string `json:"school"`
+	Major     string `json:"major"`
+	Degree    string `json:"degree"`
+	StartYear int    `json:"start_year"`
+	EndYear   int    `json:"end_year"`
+}
+
+// ToVO 转换为视图对象
+func (e *DoctorEducation) ToVO() *DoctorEducationVO {
+	return &DoctorEducationVO{
+		School:    e.School,
+		Major:     e.Major,
+		Degree:    e.Degree,
+		StartYear: e.StartYear,
+		EndYear:   e.EndYear,
+	}
+}
+
+// DoctorCertification 医生资格/专项证书
+// 到期日为空表示长期有效；AlertedAt 记录到期提醒时间，同一到期日只提醒一次
+type DoctorCertification struct {
+	BaseModel
+	DoctorID  int64      `gorm:"index;not null;comment:医生ID" json:"doctor_id"`
+	Name      string     `gorm:"type:varchar(128);not null;comment:证书名称" json:"name"`
+	Issuer    string     `gorm:"type:varchar(128);comment:发证机构" json:"issuer"`
+	CertNo    string     `gorm:"type:varchar(64);comment:证书编号" json:"cert_no"`
+	IssuedAt  *time.Time `gorm:"type:date;comment:发证日期" json:"issued_at,omitempty"`
+	ExpiresAt *time.Time `gorm:"type:date;index;comment:到期日期" json:"expires_at,omitempty"`
+	AlertedAt *time.Time `gorm:"comment:到期提醒时间" json:"alerted_at,omitempty"`
+	SortOrder int        `gorm:"type:int;default:0;comment:排序序号" json:"sort_order"`
+}
+
+// TableName 表名
+func (DoctorCertification) TableName() string {
+	return "doctor_certifications"
+}
+
+// IsExpired 证书是否已过期
+func (c *DoctorCertification) IsExpired(today time.Time) bool {
+	return c.ExpiresAt != nil && c.ExpiresAt.Before(today)
+}
+
+// DoctorCertificationVO 证书视图对象
+type DoctorCertificationVO struct {
+	Name      string `json:"name"`
+	Issuer    string `json:"issuer"`
+	CertNo    string `json:"cert_no,omitempty"`
+	IssuedAt  string `json:"issued_at,omitempty"`
+	ExpiresAt string `json:"expires_at,omitempty"` // 为空表示长期有效
+	Expired   bool   `json:"expired"`
+}
+
+// ToVO 转换为视图对象
+func (c *DoctorCertification) ToVO(today time.Time) *DoctorCertificationVO {
+	vo := &DoctorCertificationVO{
+		Name:    c.Name,
+		Issuer:  c.Issuer,
+		CertNo:  c.CertNo,
+		Expired: c.IsExpired(today),
+	}
+	if c.IssuedAt != nil {
+		vo.IssuedAt = c.IssuedAt.Format("2006-01-02")
+	}
+	if c.ExpiresAt != nil {
+		vo.ExpiresAt = c.ExpiresAt.Format("2006-01-02")
+	}
+	return vo
+}
+
+// DoctorMembership 医生学术任职/学会会员
+type DoctorMembership struct {
+	BaseModel
+	DoctorID     int64  `gorm:"index;not null;comment:医生ID" json:"doctor_id"`
+	Organization string `gorm:"type:varchar(128);not null;comment:学会/组织" json:"organization"`
+	Position     string `gorm:"type:varchar(64);comment:职务" json:"position"`
+	StartYear    int    `gorm:"type:int;default:0;comment:起始年份" json:"start_year"`
+	EndYear      int    `gorm:"type:int;default:0;comment:结束年份 0表示至今" json:"end_year"`
+	SortOrder    int    `gorm:"type:int;default:0;comment:排序序号" json:"sort_order"`
+}
+
+// TableName 表名
+func (DoctorMembership) TableName() string {
+	return "doctor_memberships"
+}
+
+// DoctorMembershipVO 学术任职视图对象
+type DoctorMembershipVO struct {
+	Organization string `json:"organization"`
+	Position     string `json:"position"`
+	StartYear    int    `json:"start_year"`
+	EndYear      int    `json:"end_year"`
+}
+
+// ToVO 转换为视图对象
+func (m *DoctorMembership) ToVO() *DoctorMembershipVO {
+	return &DoctorMembershipVO{
+		Organization: m.Organization,
+		Position:     m.Position,
+		StartYear:    m.StartYear,
+		EndYear:      m.EndYear,
+	}
+}
+
+// DoctorPhoto 医生相册照片
+type DoctorPhoto struct {
+	BaseModel
+	DoctorID  int64  `gorm:"index;not null;comment:医生ID" json:"doctor_id"`
+	URL       string `gorm:"type:varchar(512);not null;comment:图片URL" json:"url"`
+	Caption   string `gorm:"type:varchar(128);comment:图片说明" json:"caption"`
+	SortOrder int    `gorm:"type:int;default:0;comment:排序序号" json:"sort_order"`
+}
+
+// TableName 表名
+func (DoctorPhoto) TableName() string {
+	return "doctor_photos"
+}
+
+// DoctorPhotoVO 相册照片视图对象
+type DoctorPhotoVO struct {
+	URL     string `json:"url"`
+	Caption string `json:"caption"`
+}
+
+// ToVO 转换为视图对象
+func (p *DoctorPhoto) ToVO() *DoctorPhotoVO {
+	return &DoctorPhotoVO{URL: p.URL, Caption: p.Caption}
+}
+
+// SpecialtyTag 擅长领域标签（字典，供医生打标签及公开列表筛选）
+type SpecialtyTag struct {
+	BaseModel
+	Name      string `gorm:"type:varchar(32);uniqueIndex;not null;comment:标签名称" json:"name"`
+	SortOrder int    `gorm:"type:int;default:0;comment:排序序号" json:"sort_order"`
+	Status    int    `gorm:"type:tinyint;default:1;comment:状态 0停用 1启用" json:"status"`
+}
+
+// TableName 表名
+func (SpecialtyTag) TableName() string {
+	return "specialty_tags"
+}
+
+// SpecialtyTagVO 擅长领域标签视图对象
+type SpecialtyTagVO struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	SortOrder   int    `json:"sort_order"`
+	Status      int    `json:"status"`
+	DoctorCount int64  `json:"doctor_count,omitempty"`
+}
+
+// ToVO 转换为视图对象
+func (t *SpecialtyTag) ToVO() *SpecialtyTagVO {
+	return &SpecialtyTagVO{
+		ID:        t.ID,
+		Name:      t.Name,
+		SortOrder: t.SortOrder,
+		Status:    t.Status,
+	}
+}
+
+// DoctorSpecialtyTag 医生-擅长领域标签关联表
+type DoctorSpecialtyTag struct {
+	DoctorID       int64 `gorm:"primaryKey" json:"doctor_id"`
+	SpecialtyTagID int64 `gorm:"primaryKey;index" json:"specialty_tag_id"`
+}
+
+// TableName 表名
+func (DoctorSpecialtyTag) TableName() string {
+	return "doctor_specialty_tags"
+}
+
+// DoctorProfileVO 医生结构化资料视图对象
+type DoctorProfileVO struct {
+	LicenseNo        string `json:"license_no,omitempty"`
+	LicenseExpiresAt string `json:"license_expires_at,omitempty"`
+	LicenseExpired   bool   `json:"license_expired"`
+
+	Educations     []DoctorEducationVO     `json:"educations"`
+	Certifications []DoctorCertificationVO `json:"certifications"`
+	Memberships    []DoctorMembershipVO    `json:"memberships"`
+	Gallery        []DoctorPhotoVO         `json:"gallery"`
+}
+
+// DoctorProfile 医生结构化资料（教育经历、证书、学术任职、相册）
+type DoctorProfile struct {
+	Educations     []DoctorEducation
+	Certifications []DoctorCertification
+	Memberships    []DoctorMembership
+	Photos         []DoctorPhoto
+}
+
+// ToVO 转换为视图对象，publicOnly 时隐藏已过期证书
+func (p *DoctorProfile) ToVO(doctor *Doctor, today time.Time, publicOnly bool) *DoctorProfileVO {
+	vo := &DoctorProfileVO{
+		LicenseNo:      doctor.LicenseNo,
+		LicenseExpired: doctor.LicenseExpiresAt != nil && doctor.LicenseExpiresAt.Before(today),
+		Educations:     make([]DoctorEducationVO, 0, len(p.Educations)),
+		Certifications: make([]DoctorCertificationVO, 0, len(p.Certifications)),
+		Memberships:    make([]DoctorMembershipVO, 0, len(p.Memberships)),
+		Gallery:        make([]DoctorPhotoVO, 0, len(p.Photos)),
+	}
+	if doctor.LicenseExpiresAt != nil {
+		vo.LicenseExpiresAt = doctor.LicenseExpiresAt.Format("2006-01-02")
+	}
+	for i := range p.Educations {
+		vo.Educations = append(vo.Educations, *p.Educations[i].ToVO())
+	}
+	for i := range p.Certifications {
+		if publicOnly && p.Certifications[i].IsExpired(today) {
+			continue
+		}
+		vo.Certifications = append(vo.Certifications, *p.Certifications[i].ToVO(today))
+	}
+	for i := range p.Memberships {
+		vo.Memberships = append(vo.Memberships, *p.Memberships[i].ToVO())
+	}
+	for i := range p.Photos {
+		vo.Gallery = append(vo.Gallery, *p.Photos[i].ToVO())
+	}
+	return vo
+}
+
+// ExpiringQualificationVO 即将到期/已过期资质视图对象
+type ExpiringQualificationVO struct {
+	Type           string `json:"type"` // license / certification
+	TypeName       string `json:"type_name"`
+	RefID          int64  `json:"ref_id"` // 执业证书为医生ID，其他证书为证书ID
+	DoctorID       int64  `json:"doctor_id"`
+	DoctorName     string `json:"doctor_name"`
+	DepartmentName string `json:"department_name,omitempty"`
+	Name           string `json:"name"`
+	Number         string `json:"number,omitempty"`
+	ExpiresAt      string `json:"expires_at"`
+	DaysLeft       int    `json:"days_left"` // 负数表示已过期天数
+	Alerted        bool   `json:"alerted"`
+}
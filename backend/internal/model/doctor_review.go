@@ -0,0 +1,160 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// 评价状态常量
+const (
+	DoctorReviewStatusVisible = "visible" // 正常展示
+	DoctorReviewStatusHidden  = "hidden"  // 已屏蔽（管理员审核）
+)
+
+// ReviewTags 评价标签（编码 -> 名称）
+var ReviewTags = map[string]string{
+	"patient":      "耐心细致",
+	"professional": "医术精湛",
+	"clear":        "解释清楚",
+	"kind":         "态度和蔼",
+	"effective":    "疗效显著",
+	"punctual":     "准时接诊",
+	"rushed":       "问诊仓促",
+	"long_wait":    "候诊时间长",
+}
+
+// DoctorReview 就诊评价模型（每个已完成预约限评价一次）
+type DoctorReview struct {
+	BaseModel
+	AppointmentID int64      `gorm:"uniqueIndex;not null;comment:预约ID" json:"appointment_id"`
+	UserID        int64      `gorm:"index;not null;comment:用户ID" json:"user_id"`
+	PatientID     int64      `gorm:"index;not null;comment:就诊人ID" json:"patient_id"`
+	DoctorID      int64      `gorm:"index;not null;comment:医生ID" json:"doctor_id"`
+	DepartmentID  int64      `gorm:"index;not null;comment:科室ID" json:"department_id"`
+	Rating        int        `gorm:"type:tinyint;not null;comment:评分 1-5" json:"rating"`
+	Tags          string     `gorm:"type:varchar(256);comment:评价标签(逗号分隔)" json:"tags"`
+	Content       string     `gorm:"type:varchar(1000);comment:评价内容" json:"content"`
+	Status        string     `gorm:"type:varchar(20);default:'visible';index;comment:状态 visible/hidden" json:"status"`
+	HiddenReason  string     `gorm:"type:varchar(256);comment:屏蔽原因" json:"hidden_reason"`
+	HiddenBy      int64      `gorm:"default:0;comment:屏蔽操作管理员ID" json:"hidden_by"`
+	HiddenAt      *time.Time `gorm:"comment:屏蔽时间" json:"hidden_at,omitempty"`
+	Reply         string     `gorm:"type:varchar(1000);comment:医生回复" json:"reply"`
+	RepliedAt     *time.Time `gorm:"comment:回复时间" json:"replied_at,omitempty"`
+
+	// 关联
+	Appointment *Appointment `gorm:"foreignKey:AppointmentID" json:"appointment,omitempty"`
+	Patient     *Patient     `gorm:"foreignKey:PatientID" json:"patient,omitempty"`
+	Doctor      *Doctor      `gorm:"foreignKey:DoctorID" json:"doctor,omitempty"`
+	Department  *Department  `gorm:"foreignKey:DepartmentID" json:"department,omitempty"`
+}
+
+// TableName 表名
+func (DoctorReview) TableName() string {
+	return "doctor_reviews"
+}
+
+// DoctorReviewTagVO 评价标签视图对象
+type DoctorReviewTagVO struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// DoctorReviewVO 评价视图对象
+type DoctorReviewVO struct {
+	ID              int64               `json:"id"`
+	AppointmentID   int64               `json:"appointment_id"`
+	AppointmentDate string              `json:"appointment_date,omitempty"`
+	PatientName     string              `json:"patient_name"`
+	DoctorID        int64               `json:"doctor_id"`
+	DoctorName      string              `json:"doctor_name,omitempty"`
+	DepartmentID    int64               `json:"department_id"`
+	DepartmentName  string              `json:"department_name,omitempty"`
+	Rating          int                 `json:"rating"`
+	Tags            []DoctorReviewTagVO `json:"tags"`
+	Content         string              `json:"content"`
+	Status          string              `json:"status,omitempty"`
+	StatusName      string              `json:"status_name,omitempty"`
+	HiddenReason    string              `json:"hidden_reason,omitempty"`
+	HiddenAt        string              `json:"hidden_at,omitempty"`
+	Reply           string              `json:"reply,omitempty"`
+	RepliedAt       string              `json:"replied_at,omitempty"`
+	CreatedAt       string              `json:"created_at"`
+}
+
+// TagList 评价标签编码列表
+func (r *DoctorReview) TagList() []string {
+	if r.Tags == "" {
+		return nil
+	}
+	return strings.Split(r.Tags, ",")
+}
+
+// ToVO 转换为视图对象（管理后台/医生/本人，姓名不脱敏）
+func (r *DoctorReview) ToVO() *DoctorReviewVO {
+	vo := r.ToPublicVO()
+	if r.Patient != nil {
+		vo.PatientName = r.Patient.Name
+	}
+	vo.Status = r.Status
+	vo.StatusName = GetDoctorReviewStatusName(r.Status)
+	vo.HiddenReason = r.HiddenReason
+	if r.HiddenAt != nil {
+		vo.HiddenAt = r.HiddenAt.Format("2006-01-02 15:04:05")
+	}
+	return vo
+}
+
+// ToPublicVO 转换为公开视图对象（患者姓名脱敏，不含审核信息）
+func (r *DoctorReview) ToPublicVO() *DoctorReviewVO {
+	vo := &DoctorReviewVO{
+		ID:            r.ID,
+		AppointmentID: r.AppointmentID,
+		DoctorID:      r.DoctorID,
+		DepartmentID:  r.DepartmentID,
+		Rating:        r.Rating,
+		Tags:          []DoctorReviewTagVO{},
+		Content:       r.Content,
+		Reply:         r.Reply,
+		CreatedAt:     r.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	for _, code := range r.TagList() {
+		vo.Tags = append(vo.Tags, DoctorReviewTagVO{Code: code, Name: ReviewTags[code]})
+	}
+	if r.RepliedAt != nil {
+		vo.RepliedAt = r.RepliedAt.Format("2006-01-02 15:04:05")
+	}
+	if r.Appointment != nil {
+		vo.AppointmentDate = r.Appointment.AppointmentDate.Format("2006-01-02")
+	}
+	if r.Patient != nil {
+		vo.PatientName = maskName(r.Patient.Name)
+	}
+	if r.Doctor != nil {
+		vo.DoctorName = r.Doctor.Name
+	}
+	if r.Department != nil {
+		vo.DepartmentName = r.Department.Name
+	}
+
+	return vo
+}
+
+// DoctorRatingSummary 医生评分汇总（仅统计未屏蔽的评价）
+type DoctorRatingSummary struct {
+	DoctorID    int64   `json:"doctor_id"`
+	Rating      float64 `json:"rating"`       // 平均分（保留一位小数）
+	ReviewCount int64   `json:"review_count"` // 评价数
+}
+
+// GetDoctorReviewStatusName 获取评价状态名称
+func GetDoctorReviewStatusName(status string) string {
+	switch status {
+	case DoctorReviewStatusVisible:
+		return "正常"
+	case DoctorReviewStatusHidden:
+		return "已屏蔽"
+	default:
+		return status
+	}
+}
@@ -32,7 +32,7 @@ func (OperationLog) TableName() string {
 // LoginLog 登录日志模型
 type LoginLog struct {
 	ID        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
-	UserType  string    `gorm:"type:varchar(20);index;comment:用户类型 user/admin" json:"user_type"`
+	UserType  string    `gorm:"type:varchar(20);index;comment:用户类型 user/admin/doctor" json:"user_type"`
 	UserID    int64     `gorm:"index;comment:用户ID" json:"user_id"`
 	Username  string    `gorm:"type:varchar(64);comment:用户名" json:"username"`
 	LoginType string    `gorm:"type:varchar(20);comment:登录方式 wechat/password" json:"login_type"`
@@ -53,8 +53,9 @@ func (LoginLog) TableName() string {
 
 // 用户类型常量
 const (
-	UserTypeUser  = "user"  // 普通用户
-	UserTypeAdmin = "admin" // 管理员
+	UserTypeUser   = "user"   // 普通用户
+	UserTypeAdmin  = "admin"  // 管理员
+	UserTypeDoctor = "doctor" // 医生
 )
 
 // 登录类型常量
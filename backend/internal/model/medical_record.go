@@ -4,24 +4,40 @@ import (
 	"time"
 )
 
+// 就诊记录状态常量
+const (
+	MedicalRecordStatusDraft  = "draft"  // 草稿（可编辑）
+	MedicalRecordStatusSigned = "signed" // 已签署（锁定，仅可补充更正）
+)
+
 // MedicalRecord 就诊记录模型
+// 签署后内容锁定，之后的修改只能通过补充更正（MedicalRecordAmendment）进行并留痕
+// 状态列默认 signed：历史数据均为已定稿记录，新建记录由服务层显式写入 draft
 type MedicalRecord struct {
 	BaseModel
-	AppointmentID int64     `gorm:"uniqueIndex;not null;comment:预约ID" json:"appointment_id"`
-	PatientID     int64     `gorm:"index;not null;comment:患者ID" json:"patient_id"`
-	DoctorID      int64     `gorm:"index;not null;comment:医生ID" json:"doctor_id"`
-	DepartmentID  int64     `gorm:"index;not null;comment:科室ID" json:"department_id"`
-	VisitDate     time.Time `gorm:"type:date;index;not null;comment:就诊日期" json:"visit_date"`
-	Diagnosis     string    `gorm:"type:text;comment:诊断结果" json:"diagnosis"`
-	Prescription  string    `gorm:"type:text;comment:处方" json:"prescription"`
-	Advice        string    `gorm:"type:text;comment:医嘱" json:"advice"`
-	Remark        string    `gorm:"type:varchar(512);comment:备注" json:"remark"`
+	AppointmentID int64      `gorm:"uniqueIndex;not null;comment:预约ID" json:"appointment_id"`
+	PatientID     int64      `gorm:"index;not null;comment:患者ID" json:"patient_id"`
+	DoctorID      int64      `gorm:"index;not null;comment:医生ID" json:"doctor_id"`
+	DepartmentID  int64      `gorm:"index;not null;comment:科室ID" json:"department_id"`
+	VisitDate     time.Time  `gorm:"type:date;index;not null;comment:就诊日期" json:"visit_date"`
+	Diagnosis     string     `gorm:"type:text;comment:诊断结果" json:"diagnosis"`
+	Prescription  string     `gorm:"type:text;comment:处方" json:"prescription"`
+	Advice        string     `gorm:"type:text;comment:医嘱" json:"advice"`
+	Remark        string     `gorm:"type:varchar(512);comment:备注" json:"remark"`
+	Status        string     `gorm:"type:varchar(20);default:'signed';index;comment:状态 draft/signed" json:"status"`
+	Version       int        `gorm:"type:int;default:1;comment:版本号（每次补充更正加1）" json:"version"`
+	AuthorType    string     `gorm:"type:varchar(20);comment:书写人类型 doctor/admin" json:"author_type"`
+	AuthorID      int64      `gorm:"default:0;comment:书写人ID" json:"author_id"`
+	SignerType    string     `gorm:"type:varchar(20);comment:签署人类型 doctor/admin" json:"signer_type"`
+	SignerID      int64      `gorm:"default:0;comment:签署人ID" json:"signer_id"`
+	SignedAt      *time.Time `gorm:"comment:签署时间" json:"signed_at,omitempty"`
 
 	// 关联
-	Appointment *Appointment `gorm:"foreignKey:AppointmentID" json:"appointment,omitempty"`
-	Patient     *Patient     `gorm:"foreignKey:PatientID" json:"patient,omitempty"`
-	Doctor      *Doctor      `gorm:"foreignKey:DoctorID" json:"doctor,omitempty"`
-	Department  *Department  `gorm:"foreignKey:DepartmentID" json:"department,omitempty"`
+	Appointment *Appointment             `gorm:"foreignKey:AppointmentID" json:"appointment,omitempty"`
+	Patient     *Patient                 `gorm:"foreignKey:PatientID" json:"patient,omitempty"`
+	Doctor      *Doctor                  `gorm:"foreignKey:DoctorID" json:"doctor,omitempty"`
+	Department  *Department              `gorm:"foreignKey:DepartmentID" json:"department,omitempty"`
+	Amendments  []MedicalRecordAmendment `gorm:"foreignKey:RecordID" json:"amendments,omitempty"`
 }
 
 // TableName 表名
@@ -46,7 +62,19 @@ type MedicalRecordVO struct {
 	Prescription   string `json:"prescription"`
 	Advice         string `json:"advice"`
 	Remark         string `json:"remark,omitempty"`
+	Status         string `json:"status"`
+	StatusName     string `json:"status_name"`
+	Version        int    `json:"version"`
+	SignedAt       string `json:"signed_at,omitempty"`
 	CreatedAt      string `json:"created_at"`
+	UpdatedAt      string `json:"updated_at"`
+
+	Amendments []MedicalRecordAmendmentVO `json:"amendments,omitempty"`
+}
+
+// IsSigned 是否已签署
+func (r *MedicalRecord) IsSigned() bool {
+	return r.Status == MedicalRecordStatusSigned
 }
 
 // ToVO 转换为视图对象
@@ -62,9 +90,22 @@ func (r *MedicalRecord) ToVO() *MedicalRecordVO {
 		Prescription:  r.Prescription,
 		Advice:        r.Advice,
 		Remark:        r.Remark,
+		Status:        r.Status,
+		StatusName:    GetMedicalRecordStatusName(r.Status),
+		Version:       r.Version,
 		CreatedAt:     r.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:     r.UpdatedAt.Format("2006-01-02 15:04:05"),
 	}
 
+	if r.SignedAt != nil {
+		vo.SignedAt = r.SignedAt.Format("2006-01-02 15:04:05")
+	}
+	if len(r.Amendments) > 0 {
+		vo.Amendments = make([]MedicalRecordAmendmentVO, len(r.Amendments))
+		for i := range r.Amendments {
+			vo.Amendments[i] = *r.Amendments[i].ToVO()
+		}
+	}
 	if r.Appointment != nil {
 		vo.AppointmentNo = r.Appointment.AppointmentNo
 	}
@@ -82,6 +123,15 @@ func (r *MedicalRecord) ToVO() *MedicalRecordVO {
 	return vo
 }
 
+// ToFullVO 转换为完整视图对象（患者姓名不脱敏，用于医生/管理后台）
+func (r *MedicalRecord) ToFullVO() *MedicalRecordVO {
+	vo := r.ToVO()
+	if r.Patient != nil {
+		vo.PatientName = r.Patient.Name
+	}
+	return vo
+}
+
 // MedicalRecordListVO 就诊记录列表视图对象
 type MedicalRecordListVO struct {
 	ID             int64  `json:"id"`
@@ -90,14 +140,18 @@ type MedicalRecordListVO struct {
 	DepartmentName string `json:"department_name"`
 	VisitDate      string `json:"visit_date"`
 	Diagnosis      string `json:"diagnosis"`
+	Status         string `json:"status"`
+	StatusName     string `json:"status_name"`
 }
 
 // ToListVO 转换为列表视图对象
 func (r *MedicalRecord) ToListVO() *MedicalRecordListVO {
 	vo := &MedicalRecordListVO{
-		ID:        r.ID,
-		VisitDate: r.VisitDate.Format("2006-01-02"),
-		Diagnosis: r.Diagnosis,
+		ID:         r.ID,
+		VisitDate:  r.VisitDate.Format("2006-01-02"),
+		Diagnosis:  r.Diagnosis,
+		Status:     r.Status,
+		StatusName: GetMedicalRecordStatusName(r.Status),
 	}
 
 	if r.Patient != nil {
@@ -112,3 +166,15 @@ func (r *MedicalRecord) ToListVO() *MedicalRecordListVO {
 
 	return vo
 }
+
+// GetMedicalRecordStatusName 获取就诊记录状态名称
+func GetMedicalRecordStatusName(status string) string {
+	switch status {
+	case MedicalRecordStatusDraft:
+		return "草稿"
+	case MedicalRecordStatusSigned:
+		return "已签署"
+	default:
+		return status
+	}
+}
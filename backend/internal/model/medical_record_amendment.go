@@ -0,0 +1,55 @@
+package model
+
+import (
+	"encoding/json"
+)
+
+// MedicalRecordAmendment 就诊记录补充更正（病历签署后的修改留痕）
+type MedicalRecordAmendment struct {
+	BaseModel
+	RecordID   int64  `gorm:"index;not null;comment:就诊记录ID" json:"record_id"`
+	Version    int    `gorm:"type:int;not null;comment:更正后的版本号" json:"version"`
+	Changes    string `gorm:"type:text;comment:变更内容(JSON)" json:"changes"`
+	Reason     string `gorm:"type:varchar(256);not null;comment:更正原因" json:"reason"`
+	AuthorType string `gorm:"type:varchar(20);comment:更正人类型 doctor/admin" json:"author_type"`
+	AuthorID   int64  `gorm:"default:0;comment:更正人ID" json:"author_id"`
+	AuthorName string `gorm:"type:varchar(64);comment:更正人姓名" json:"author_name"`
+}
+
+// TableName 表名
+func (MedicalRecordAmendment) TableName() string {
+	return "medical_record_amendments"
+}
+
+// MedicalRecordChange 单个字段的变更
+type MedicalRecordChange struct {
+	Field     string `json:"field"`
+	FieldName string `json:"field_name"`
+	Before    string `json:"before"`
+	After     string `json:"after"`
+}
+
+// MedicalRecordAmendmentVO 补充更正视图对象
+type MedicalRecordAmendmentVO struct {
+	ID         int64                 `json:"id"`
+	Version    int                   `json:"version"`
+	Changes    []MedicalRecordChange `json:"changes"`
+	Reason     string                `json:"reason"`
+	AuthorType string                `json:"author_type"`
+	AuthorName string                `json:"author_name"`
+	CreatedAt  string                `json:"created_at"`
+}
+
+// ToVO 转换为视图对象
+func (a *MedicalRecordAmendment) ToVO() *MedicalRecordAmendmentVO {
+	vo := &MedicalRecordAmendmentVO{
+		ID:         a.ID,
+		Version:    a.Version,
+		Reason:     a.Reason,
+		AuthorType: a.AuthorType,
+		AuthorName: a.AuthorName,
+		CreatedAt:  a.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	_ = json.Unmarshal([]byte(a.Changes), &vo.Changes)
+	return vo
+}
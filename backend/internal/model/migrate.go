@@ -2,51 +2,192 @@ package model
 
 import (
 	"gorm.io/gorm"
+
+	"huaan-medical/pkg/identity"
 )
 
 // AutoMigrate 自动迁移数据库表
 func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+	err := db.AutoMigrate(
 		// 用户相关
 		&User{},
+		&UserSession{},
+		&UserAdminAction{},
+		&Agreement{},
+		&AgreementConsent{},
 		&Patient{},
+		&PatientMember{},
+		&PatientInvitation{},
+		&PatientConsent{},
+		&PatientDuplicate{},
+		&PatientMerge{},
+		&DataExport{},
+		&AccountDeletion{},
+		&AccountMerge{},
 
 		// 医院相关
+		&Campus{},
+		&Room{},
 		&Department{},
 		&Doctor{},
+		&DoctorDepartment{},
+		&DoctorEducation{},
+		&DoctorCertification{},
+		&DoctorMembership{},
+		&DoctorPhoto{},
+		&SpecialtyTag{},
+		&DoctorSpecialtyTag{},
 		&Schedule{},
+		&ReleaseRule{},
+		&ReleaseRuleStage{},
+		&ScheduleSwap{},
+		&DoctorAccount{},
+		&DoctorLeave{},
+		&DoctorReview{},
+		&SearchDocument{},
+		&SearchSynonym{},
+		&TriageRule{},
 
 		// 预约相关
 		&Appointment{},
 		&MedicalRecord{},
+		&MedicalRecordAmendment{},
+
+		// 消息相关
+		&Notification{},
 
 		// 管理员相关
 		&Admin{},
 		&Role{},
 		&Permission{},
 		&AdminRole{},
+		&AdminCampusRole{},
 		&RolePermission{},
 
 		// 日志相关
 		&OperationLog{},
 		&LoginLog{},
 	)
+	if err != nil {
+		return err
+	}
+
+	if err := backfillDoctorDepartments(db); err != nil {
+		return err
+	}
+	if err := backfillCampuses(db); err != nil {
+		return err
+	}
+	return backfillPatientIdentity(db)
+}
+
+// backfillDoctorDepartments 补齐多科室执业改造前的历史数据（可重复执行）
+// 1. 以医生主科室生成执业关系
+// 2. 历史排班的出诊科室取医生主科室
+func backfillDoctorDepartments(db *gorm.DB) error {
+	err := db.Exec(`INSERT INTO doctor_departments (doctor_id, department_id, is_primary)
+		SELECT d.id, d.department_id, 1 FROM doctors d
+		WHERE d.deleted_at IS NULL AND d.department_id > 0
+		AND NOT EXISTS (SELECT 1 FROM doctor_departments dd WHERE dd.doctor_id = d.id)`).Error
+	if err != nil {
+		return err
+	}
+
+	return db.Exec(`UPDATE schedules s JOIN doctors d ON d.id = s.doctor_id
+		SET s.department_id = d.department_id
+		WHERE s.department_id = 0`).Error
+}
+
+// backfillCampuses 补齐多院区改造前的历史数据（可重复执行）
+// 1. 已有科室但尚无院区时创建默认院区，未归属院区的科室归入首个院区
+// 2. 历史排班的院区取出诊科室所属院区
+// 3. 历史预约的院区/诊室取对应排班
+func backfillCampuses(db *gorm.DB) error {
+	var deptCount int64
+	if err := db.Model(&Department{}).Where("campus_id = 0").Count(&deptCount).Error; err != nil {
+		return err
+	}
+	if deptCount > 0 {
+		var campus Campus
+		err := db.Order("sort_order ASC, id ASC").First(&campus).Error
+		if err == gorm.ErrRecordNotFound {
+			campus = Campus{Name: "总院", Address: "", Status: StatusEnabled}
+			err = db.Create(&campus).Error
+		}
+		if err != nil {
+			return err
+		}
+		if err := db.Model(&Department{}).Where("campus_id = 0").Update("campus_id", campus.ID).Error; err != nil {
+			return err
+		}
+	}
+
+	err := db.Exec(`UPDATE schedules s JOIN departments d ON d.id = s.department_id
+		SET s.campus_id = d.campus_id
+		WHERE s.campus_id = 0 AND d.campus_id > 0`).Error
+	if err != nil {
+		return err
+	}
+
+	return db.Exec(`UPDATE appointments a JOIN schedules s ON s.id = a.schedule_id
+		SET a.campus_id = s.campus_id, a.room_id = s.room_id
+		WHERE a.campus_id = 0 AND s.campus_id > 0`).Error
+}
+
+// backfillPatientIdentity 补齐证件类型改造前的就诊人数据（可重复执行）
+// 历史就诊人均使用居民身份证
+func backfillPatientIdentity(db *gorm.DB) error {
+	return db.Model(&Patient{}).Where("doc_type = '' OR doc_type IS NULL").
+		Update("doc_type", identity.DocTypeIDCard).Error
 }
 
 // GetAllModels 获取所有模型（用于文档生成等）
 func GetAllModels() []interface{} {
 	return []interface{}{
 		&User{},
+		&UserSession{},
+		&UserAdminAction{},
+		&Agreement{},
+		&AgreementConsent{},
 		&Patient{},
+		&PatientMember{},
+		&PatientInvitation{},
+		&PatientConsent{},
+		&PatientDuplicate{},
+		&PatientMerge{},
+		&DataExport{},
+		&AccountDeletion{},
+		&AccountMerge{},
+		&Campus{},
+		&Room{},
 		&Department{},
 		&Doctor{},
+		&DoctorDepartment{},
+		&DoctorEducation{},
+		&DoctorCertification{},
+		&DoctorMembership{},
+		&DoctorPhoto{},
+		&SpecialtyTag{},
+		&DoctorSpecialtyTag{},
 		&Schedule{},
+		&ReleaseRule{},
+		&ReleaseRuleStage{},
+		&ScheduleSwap{},
+		&DoctorAccount{},
+		&DoctorLeave{},
+		&DoctorReview{},
+		&SearchDocument{},
+		&SearchSynonym{},
+		&TriageRule{},
 		&Appointment{},
 		&MedicalRecord{},
+		&MedicalRecordAmendment{},
+		&Notification{},
 		&Admin{},
 		&Role{},
 		&Permission{},
 		&AdminRole{},
+		&AdminCampusRole{},
 		&RolePermission{},
 		&OperationLog{},
 		&LoginLog{},
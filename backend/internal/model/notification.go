@@ -0,0 +1,57 @@
+package model
+
+import (
+	"time"
+)
+
+// 站内消息类型常量
+const (
+	NotificationTypeDoctorChange = "doctor_change" // 就诊医生变更
+	NotificationTypeDoctorLeave  = "doctor_leave"  // 医生停诊
+)
+
+// Notification 站内消息模型
+type Notification struct {
+	BaseModel
+	UserID  int64      `gorm:"index;not null;comment:用户ID" json:"user_id"`
+	Type    string     `gorm:"type:varchar(32);index;not null;comment:消息类型" json:"type"`
+	Title   string     `gorm:"type:varchar(128);not null;comment:标题" json:"title"`
+	Content string     `gorm:"type:varchar(1024);comment:内容" json:"content"`
+	BizID   int64      `gorm:"default:0;comment:关联业务ID" json:"biz_id"`
+	IsRead  bool       `gorm:"default:false;index;comment:是否已读" json:"is_read"`
+	ReadAt  *time.Time `gorm:"comment:阅读时间" json:"read_at,omitempty"`
+}
+
+// TableName 表名
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// NotificationVO 站内消息视图对象
+type NotificationVO struct {
+	ID        int64  `json:"id"`
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Content   string `json:"content"`
+	BizID     int64  `json:"biz_id"`
+	IsRead    bool   `json:"is_read"`
+	ReadAt    string `json:"read_at,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+// ToVO 转换为视图对象
+func (n *Notification) ToVO() *NotificationVO {
+	vo := &NotificationVO{
+		ID:        n.ID,
+		Type:      n.Type,
+		Title:     n.Title,
+		Content:   n.Content,
+		BizID:     n.BizID,
+		IsRead:    n.IsRead,
+		CreatedAt: n.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if n.ReadAt != nil {
+		vo.ReadAt = n.ReadAt.Format("2006-01-02 15:04:05")
+	}
+	return vo
+}
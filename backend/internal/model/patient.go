@@ -1,16 +1,35 @@
 package model
 
+import (
+	"time"
+
+	"huaan-medical/pkg/identity"
+)
+
+// 实名核验状态常量
+const (
+	PatientVerifyUnverified = 0 // 未核验（未启用核验服务或服务暂不可用）
+	PatientVerifyPassed     = 1 // 核验通过
+	PatientVerifyFailed     = 2 // 核验不一致
+)
+
 // Patient 就诊人模型
+// 居民身份证的性别、出生日期由证件号码解析得出；其他证件由用户填写
 type Patient struct {
 	BaseModel
-	UserID    int64  `gorm:"index;not null;comment:所属用户ID" json:"user_id"`
-	Name      string `gorm:"type:varchar(32);not null;comment:姓名" json:"name"`
-	IDCard    string `gorm:"type:varchar(18);index;not null;comment:身份证号" json:"id_card"`
-	Phone     string `gorm:"type:varchar(20);not null;comment:手机号" json:"phone"`
-	Gender    int    `gorm:"type:tinyint;default:0;comment:性别 0未知 1男 2女" json:"gender"`
-	BirthDate string `gorm:"type:date;comment:出生日期" json:"birth_date"`
-	Relation  string `gorm:"type:varchar(20);default:'self';comment:与用户关系" json:"relation"`
-	IsDefault int    `gorm:"type:tinyint;default:0;comment:是否默认就诊人 0否 1是" json:"is_default"`
+	UserID       int64      `gorm:"index;not null;comment:所属用户ID" json:"user_id"`
+	Name         string     `gorm:"type:varchar(32);not null;comment:姓名" json:"name"`
+	DocType      string     `gorm:"type:varchar(20);default:'id_card';comment:证件类型" json:"doc_type"`
+	IDCard       string     `gorm:"type:varchar(32);index;not null;comment:证件号码" json:"id_card"`
+	Phone        string     `gorm:"type:varchar(20);not null;comment:手机号" json:"phone"`
+	Gender       int        `gorm:"type:tinyint;default:0;comment:性别 0未知 1男 2女" json:"gender"`
+	BirthDate    string     `gorm:"type:date;comment:出生日期" json:"birth_date"`
+	Relation     string     `gorm:"type:varchar(20);default:'self';comment:与用户关系" json:"relation"`
+	IsDefault    int        `gorm:"type:tinyint;default:0;comment:是否默认就诊人 0否 1是" json:"is_default"`
+	VerifyStatus int        `gorm:"type:tinyint;default:0;comment:实名核验状态 0未核验 1通过 2不一致" json:"verify_status"`
+	VerifiedAt   *time.Time `gorm:"comment:实名核验时间" json:"verified_at,omitempty"`
+	VerifyRemark string     `gorm:"type:varchar(128);comment:实名核验说明" json:"verify_remark"`
+	MergedInto   int64      `gorm:"default:0;index;comment:已合并到的就诊人ID" json:"merged_into"`
 
 	// 关联
 	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
@@ -23,52 +42,108 @@ func (Patient) TableName() string {
 
 // PatientVO 就诊人视图对象
 type PatientVO struct {
-	ID           int64  `json:"id"`
-	Name         string `json:"name"`
-	IDCard       string `json:"id_card"` // 脱敏后的身份证号
-	Phone        string `json:"phone"`   // 脱敏后的手机号
-	Gender       int    `json:"gender"`
-	GenderName   string `json:"gender_name"`
-	BirthDate    string `json:"birth_date"`
-	Age          int    `json:"age"`
-	Relation     string `json:"relation"`
-	RelationName string `json:"relation_name"`
-	IsDefault    int    `json:"is_default"`
-	LastLoginIP  string `json:"last_login_ip,omitempty"` // 用户最后登录IP
+	ID               int64  `json:"id"`
+	Name             string `json:"name"`
+	DocType          string `json:"doc_type"`
+	DocTypeName      string `json:"doc_type_name"`
+	IDCard           string `json:"id_card"` // 脱敏后的证件号码
+	Phone            string `json:"phone"`   // 脱敏后的手机号
+	Gender           int    `json:"gender"`
+	GenderName       string `json:"gender_name"`
+	BirthDate        string `json:"birth_date"`
+	Age              int    `json:"age"`
+	Relation         string `json:"relation"`
+	RelationName     string `json:"relation_name"`
+	IsDefault        int    `json:"is_default"`
+	VerifyStatus     int    `json:"verify_status"`
+	VerifyStatusName string `json:"verify_status_name"`
+	VerifiedAt       string `json:"verified_at,omitempty"`
+	LastLoginIP      string `json:"last_login_ip,omitempty"` // 用户最后登录IP
+	Role             string `json:"role,omitempty"`          // 当前用户的共享角色 owner/manager/viewer
+	RoleName         string `json:"role_name,omitempty"`
+	Locked           bool   `json:"locked"`           // 已成年的子女，资料只读
+	ConsentRequired  bool   `json:"consent_required"` // 已成年的家人，查看病历需本人授权（尚未授权）
 }
 
 // ToVO 转换为视图对象
 func (p *Patient) ToVO() *PatientVO {
-	return &PatientVO{
-		ID:           p.ID,
-		Name:         maskName(p.Name),
-		IDCard:       maskIDCard(p.IDCard),
-		Phone:        maskPhone(p.Phone),
-		Gender:       p.Gender,
-		GenderName:   getGenderName(p.Gender),
-		BirthDate:    p.BirthDate,
-		Age:          calculateAge(p.IDCard),
-		Relation:     p.Relation,
-		RelationName: GetRelationName(p.Relation),
-		IsDefault:    p.IsDefault,
-	}
+	vo := p.ToFullVO()
+	vo.Name = maskName(p.Name)
+	vo.IDCard = maskIDCard(p.IDCard)
+	vo.Phone = maskPhone(p.Phone)
+	return vo
 }
 
 // ToFullVO 转换为完整视图对象（不脱敏，用于编辑）
 func (p *Patient) ToFullVO() *PatientVO {
-	return &PatientVO{
-		ID:           p.ID,
-		Name:         p.Name,
-		IDCard:       p.IDCard,
-		Phone:        p.Phone,
-		Gender:       p.Gender,
-		GenderName:   getGenderName(p.Gender),
-		BirthDate:    p.BirthDate,
-		Age:          calculateAge(p.IDCard),
-		Relation:     p.Relation,
-		RelationName: GetRelationName(p.Relation),
-		IsDefault:    p.IsDefault,
+	vo := &PatientVO{
+		ID:               p.ID,
+		Name:             p.Name,
+		DocType:          p.DocTypeOrDefault(),
+		DocTypeName:      identity.DocTypeName(p.DocType),
+		IDCard:           p.IDCard,
+		Phone:            p.Phone,
+		Gender:           p.Gender,
+		GenderName:       getGenderName(p.Gender),
+		BirthDate:        p.BirthDate,
+		Age:              p.Age(),
+		Relation:         p.Relation,
+		RelationName:     GetRelationName(p.Relation),
+		IsDefault:        p.IsDefault,
+		VerifyStatus:     p.VerifyStatus,
+		VerifyStatusName: getVerifyStatusName(p.VerifyStatus),
+		Locked:           p.IsGrownChildAt(time.Now()),
+	}
+	if p.VerifiedAt != nil {
+		vo.VerifiedAt = p.VerifiedAt.Format("2006-01-02 15:04:05")
+	}
+	return vo
+}
+
+// DocTypeOrDefault 证件类型（历史数据为空时视为居民身份证）
+func (p *Patient) DocTypeOrDefault() string {
+	if p.DocType == "" {
+		return identity.DocTypeIDCard
+	}
+	return p.DocType
+}
+
+// Age 就诊人当前周岁年龄（无法确定出生日期时返回0）
+func (p *Patient) Age() int {
+	if age := p.KnownAge(); age != nil {
+		return *age
 	}
+	return 0
+}
+
+// KnownAge 就诊人当前周岁年龄（无法确定出生日期时返回nil）
+func (p *Patient) KnownAge() *int {
+	return p.AgeAt(time.Now())
+}
+
+// AgeAt 就诊人在指定日期的周岁年龄（无法确定出生日期时返回nil）
+func (p *Patient) AgeAt(t time.Time) *int {
+	birth, ok := p.BirthTime()
+	if !ok {
+		return nil
+	}
+	age := identity.AgeAt(birth, t)
+	return &age
+}
+
+// BirthTime 出生日期（优先取出生日期字段，缺失时从居民身份证号解析）
+func (p *Patient) BirthTime() (time.Time, bool) {
+	if len(p.BirthDate) >= 10 {
+		if birth, err := time.ParseInLocation("2006-01-02", p.BirthDate[:10], time.Local); err == nil {
+			return birth, true
+		}
+	}
+	if p.DocTypeOrDefault() == identity.DocTypeIDCard {
+		if info, err := identity.ParseIDCard(p.IDCard); err == nil {
+			return info.BirthDate, true
+		}
+	}
+	return time.Time{}, false
 }
 
 // maskName 姓名脱敏
@@ -88,12 +163,15 @@ func maskName(name string) string {
 	return masked
 }
 
-// maskIDCard 身份证脱敏
+// maskIDCard 证件号码脱敏（身份证显示前6后4，较短的证件显示前2后2）
 func maskIDCard(idCard string) string {
-	if len(idCard) < 10 {
-		return idCard
+	if len(idCard) >= 10 {
+		return idCard[:6] + "********" + idCard[len(idCard)-4:]
+	}
+	if len(idCard) > 4 {
+		return idCard[:2] + "****" + idCard[len(idCard)-2:]
 	}
-	return idCard[:6] + "********" + idCard[len(idCard)-4:]
+	return idCard
 }
 
 // getGenderName 获取性别名称
@@ -108,17 +186,14 @@ func getGenderName(gender int) string {
 	}
 }
 
-// calculateAge 根据身份证计算年龄
-func calculateAge(idCard string) int {
-	if len(idCard) != 18 {
-		return 0
-	}
-	// 简单实现，实际应该用time包计算
-	birthYear := 0
-	for i := 6; i < 10; i++ {
-		birthYear = birthYear*10 + int(idCard[i]-'0')
+// getVerifyStatusName 获取实名核验状态名称
+func getVerifyStatusName(status int) string {
+	switch status {
+	case PatientVerifyPassed:
+		return "已实名"
+	case PatientVerifyFailed:
+		return "核验不一致"
+	default:
+		return "未核验"
 	}
-	// 假设当前年份，实际应该用time.Now().Year()
-	currentYear := 2025
-	return currentYear - birthYear
 }
@@ -0,0 +1,211 @@
+package model
+
+import (
+	"time"
+)
+
+// 疑似重复就诊人匹配方式
+const (
+	DuplicateMatchIDCard    = "id_card"    // 证件号码相同
+	DuplicateMatchNamePhone = "name_phone" // 姓名与手机号相同
+	DuplicateMatchNameBirth = "name_birth" // 姓名、性别与出生日期相同
+)
+
+// 疑似重复记录审核状态
+const (
+	DuplicateStatusPending = "pending" // 待审核
+	DuplicateStatusMerged  = "merged"  // 已合并
+	DuplicateStatusIgnored = "ignored" // 已忽略（非同一人）
+)
+
+// 就诊人合并记录状态
+const (
+	PatientMergeStatusMerged = "merged" // 已合并
+	PatientMergeStatusUndone = "undone" // 已撤销
+)
+
+// NotificationTypePatientMerged 就诊人档案合并站内消息类型
+const NotificationTypePatientMerged = "patient_merged"
+
+// PatientDuplicate 疑似重复就诊人（管理后台审核队列）
+// 同一对就诊人只记录一次（PatientID < DuplicateID），忽略后不再重复入队
+type PatientDuplicate struct {
+	BaseModel
+	PatientID   int64      `gorm:"uniqueIndex:uk_patient_duplicate;not null;comment:就诊人ID（较早创建）" json:"patient_id"`
+	DuplicateID int64      `gorm:"uniqueIndex:uk_patient_duplicate;index;not null;comment:疑似重复的就诊人ID" json:"duplicate_id"`
+	MatchType   string     `gorm:"type:varchar(20);not null;comment:匹配方式" json:"match_type"`
+	Score       int        `gorm:"type:int;default:0;comment:相似度 0-100" json:"score"`
+	Status      string     `gorm:"type:varchar(20);index;default:'pending';comment:状态 pending/merged/ignored" json:"status"`
+	ReviewedBy  int64      `gorm:"default:0;comment:审核管理员ID" json:"reviewed_by"`
+	ReviewedAt  *time.Time `gorm:"comment:审核时间" json:"reviewed_at,omitempty"`
+	Remark      string     `gorm:"type:varchar(255);comment:审核备注" json:"remark"`
+	MergeID     int64      `gorm:"default:0;comment:合并记录ID" json:"merge_id"`
+
+	// 关联（合并后被合并方已删除，需 Unscoped 预加载）
+	Patient   *Patient `gorm:"foreignKey:PatientID" json:"patient,omitempty"`
+	Duplicate *Patient `gorm:"foreignKey:DuplicateID" json:"duplicate,omitempty"`
+}
+
+// TableName 表名
+func (PatientDuplicate) TableName() string {
+	return "patient_duplicates"
+}
+
+// PatientDuplicateVO 疑似重复就诊人视图对象
+type PatientDuplicateVO struct {
+	ID            int64      `json:"id"`
+	MatchType     string     `json:"match_type"`
+	MatchTypeName string     `json:"match_type_name"`
+	Score         int        `json:"score"`
+	Status        string     `json:"status"`
+	StatusName    string     `json:"status_name"`
+	Remark        string     `json:"remark"`
+	MergeID       int64      `json:"merge_id,omitempty"`
+	ReviewedAt    string     `json:"reviewed_at,omitempty"`
+	CreatedAt     string     `json:"created_at"`
+	Patient       *PatientVO `json:"patient,omitempty"`
+	Duplicate     *PatientVO `json:"duplicate,omitempty"`
+}
+
+// ToVO 转换为视图对象
+func (d *PatientDuplicate) ToVO() *PatientDuplicateVO {
+	vo := &PatientDuplicateVO{
+		ID:            d.ID,
+		MatchType:     d.MatchType,
+		MatchTypeName: GetDuplicateMatchName(d.MatchType),
+		Score:         d.Score,
+		Status:        d.Status,
+		StatusName:    getDuplicateStatusName(d.Status),
+		Remark:        d.Remark,
+		MergeID:       d.MergeID,
+		CreatedAt:     d.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if d.ReviewedAt != nil {
+		vo.ReviewedAt = d.ReviewedAt.Format("2006-01-02 15:04:05")
+	}
+	if d.Patient != nil {
+		vo.Patient = d.Patient.ToVO()
+	}
+	if d.Duplicate != nil {
+		vo.Duplicate = d.Duplicate.ToVO()
+	}
+	return vo
+}
+
+// PatientMerge 就诊人合并记录（审计留痕，支持撤销）
+// Snapshot 保存被迁移的业务数据ID及新增的共享成员，撤销时按快照还原
+type PatientMerge struct {
+	BaseModel
+	SurvivorID  int64      `gorm:"index;not null;comment:保留的就诊人ID" json:"survivor_id"`
+	MergedID    int64      `gorm:"index;not null;comment:被合并的就诊人ID" json:"merged_id"`
+	DuplicateID int64      `gorm:"default:0;comment:来源疑似重复记录ID" json:"duplicate_id"`
+	Status      string     `gorm:"type:varchar(20);index;default:'merged';comment:状态 merged/undone" json:"status"`
+	Snapshot    string     `gorm:"type:text;comment:迁移快照(JSON)" json:"-"`
+	AdminID     int64      `gorm:"not null;comment:操作管理员ID" json:"admin_id"`
+	AdminName   string     `gorm:"type:varchar(64);comment:操作管理员" json:"admin_name"`
+	Remark      string     `gorm:"type:varchar(255);comment:合并备注" json:"remark"`
+	UndoneBy    int64      `gorm:"default:0;comment:撤销管理员ID" json:"undone_by"`
+	UndoneName  string     `gorm:"type:varchar(64);comment:撤销管理员" json:"undone_name"`
+	UndoneAt    *time.Time `gorm:"comment:撤销时间" json:"undone_at,omitempty"`
+	UndoRemark  string     `gorm:"type:varchar(255);comment:撤销原因" json:"undo_remark"`
+}
+
+// TableName 表名
+func (PatientMerge) TableName() string {
+	return "patient_merges"
+}
+
+// PatientMergeSnapshot 合并迁移快照
+type PatientMergeSnapshot struct {
+	AppointmentIDs   []int64 `json:"appointment_ids"`
+	MedicalRecordIDs []int64 `json:"medical_record_ids"`
+	ReviewIDs        []int64 `json:"review_ids"`
+	AddedMemberIDs   []int64 `json:"added_member_ids"` // 为保留方新增的共享成员（被合并方的所有者及成员）
+}
+
+// PatientMergeVO 就诊人合并记录视图对象
+type PatientMergeVO struct {
+	ID               int64      `json:"id"`
+	SurvivorID       int64      `json:"survivor_id"`
+	MergedID         int64      `json:"merged_id"`
+	DuplicateID      int64      `json:"duplicate_id,omitempty"`
+	Status           string     `json:"status"`
+	StatusName       string     `json:"status_name"`
+	AppointmentCount int        `json:"appointment_count"`
+	RecordCount      int        `json:"record_count"`
+	ReviewCount      int        `json:"review_count"`
+	AdminName        string     `json:"admin_name"`
+	Remark           string     `json:"remark"`
+	UndoneName       string     `json:"undone_name,omitempty"`
+	UndoneAt         string     `json:"undone_at,omitempty"`
+	UndoRemark       string     `json:"undo_remark,omitempty"`
+	CreatedAt        string     `json:"created_at"`
+	Survivor         *PatientVO `json:"survivor,omitempty"`
+	Merged           *PatientVO `json:"merged,omitempty"`
+}
+
+// ToVO 转换为视图对象
+func (m *PatientMerge) ToVO(snapshot *PatientMergeSnapshot) *PatientMergeVO {
+	vo := &PatientMergeVO{
+		ID:          m.ID,
+		SurvivorID:  m.SurvivorID,
+		MergedID:    m.MergedID,
+		DuplicateID: m.DuplicateID,
+		Status:      m.Status,
+		StatusName:  getPatientMergeStatusName(m.Status),
+		AdminName:   m.AdminName,
+		Remark:      m.Remark,
+		UndoneName:  m.UndoneName,
+		UndoRemark:  m.UndoRemark,
+		CreatedAt:   m.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if snapshot != nil {
+		vo.AppointmentCount = len(snapshot.AppointmentIDs)
+		vo.RecordCount = len(snapshot.MedicalRecordIDs)
+		vo.ReviewCount = len(snapshot.ReviewIDs)
+	}
+	if m.UndoneAt != nil {
+		vo.UndoneAt = m.UndoneAt.Format("2006-01-02 15:04:05")
+	}
+	return vo
+}
+
+// GetDuplicateMatchName 获取匹配方式名称
+func GetDuplicateMatchName(matchType string) string {
+	switch matchType {
+	case DuplicateMatchIDCard:
+		return "证件号码相同"
+	case DuplicateMatchNamePhone:
+		return "姓名与手机号相同"
+	case DuplicateMatchNameBirth:
+		return "姓名、性别与出生日期相同"
+	default:
+		return "未知"
+	}
+}
+
+// getDuplicateStatusName 获取审核状态名称
+func getDuplicateStatusName(status string) string {
+	switch status {
+	case DuplicateStatusPending:
+		return "待审核"
+	case DuplicateStatusMerged:
+		return "已合并"
+	case DuplicateStatusIgnored:
+		return "已忽略"
+	default:
+		return "未知"
+	}
+}
+
+// getPatientMergeStatusName 获取合并记录状态名称
+func getPatientMergeStatusName(status string) string {
+	switch status {
+	case PatientMergeStatusMerged:
+		return "已合并"
+	case PatientMergeStatusUndone:
+		return "已撤销"
+	default:
+		return "未知"
+	}
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"time"
+)
+
+// AdultAge 成年年龄（周岁）
+const AdultAge = 18
+
+// 监护相关站内消息类型
+const (
+	NotificationTypePatientTransfer = "patient_transfer" // 成年就诊人档案转交
+)
+
+// PatientConsent 成年就诊人授权查看病历
+// 就诊人年满18周岁后，除本人账号外查看其病历需经本人授权：
+// 验证码发送到本人账号（已实名登记为本人的账号）绑定的手机号，由本人告知验证码完成授权。
+// 监护人无法修改接收验证码的手机号；成年家人的证件、手机号、出生日期及关系对监护人只读
+type PatientConsent struct {
+	BaseModel
+	PatientID int64      `gorm:"index:idx_patient_consent_user;not null;comment:就诊人ID" json:"patient_id"`
+	UserID    int64      `gorm:"index:idx_patient_consent_user;index;not null;comment:被授权用户ID" json:"user_id"`
+	GrantorID int64      `gorm:"default:0;index;comment:授权人（就诊人本人账号）用户ID" json:"grantor_id"`
+	Phone     string     `gorm:"type:varchar(20);not null;comment:授权时验证的本人账号手机号" json:"-"`
+	GrantedAt time.Time  `gorm:"not null;comment:授权时间" json:"granted_at"`
+	ExpiresAt time.Time  `gorm:"index;not null;comment:授权到期时间" json:"expires_at"`
+	RevokedAt *time.Time `gorm:"comment:失效时间（档案转交等）" json:"revoked_at,omitempty"`
+}
+
+// TableName 表名
+func (PatientConsent) TableName() string {
+	return "patient_consents"
+}
+
+// ValidAt 授权在指定时间是否有效
+// 未记录授权人的授权为旧版按就诊人登记手机号验证所得，监护人可自行修改该手机号，不再认可
+func (c *PatientConsent) ValidAt(now time.Time) bool {
+	return c.RevokedAt == nil && now.Before(c.ExpiresAt) && c.GrantorID > 0
+}
+
+// PatientConsentVO 病历查看授权视图对象
+type PatientConsentVO struct {
+	PatientID int64  `json:"patient_id"`
+	Phone     string `json:"phone"` // 脱敏后的本人账号手机号
+	GrantedAt string `json:"granted_at"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// ToVO 转换为视图对象
+func (c *PatientConsent) ToVO() *PatientConsentVO {
+	return &PatientConsentVO{
+		PatientID: c.PatientID,
+		Phone:     maskPhone(c.Phone),
+		GrantedAt: c.GrantedAt.Format("2006-01-02 15:04:05"),
+		ExpiresAt: c.ExpiresAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// IsMinorAt 就诊人在指定时间是否未成年（无法确定出生日期时视为成年）
+func (p *Patient) IsMinorAt(t time.Time) bool {
+	age := p.AgeAt(t)
+	return age != nil && *age < AdultAge
+}
+
+// IsDependant 是否为代管的家人（非本人）
+func (p *Patient) IsDependant() bool {
+	return p.Relation != RelationSelf
+}
+
+// IsGrownChildAt 子女就诊人在指定时间是否已年满18周岁
+// 已成年的子女资料由监护人只读，可转交给本人账号管理
+func (p *Patient) IsGrownChildAt(t time.Time) bool {
+	if p.Relation != RelationChild {
+		return false
+	}
+	age := p.AgeAt(t)
+	return age != nil && *age >= AdultAge
+}
+
+// NeedsRecordConsentAt 查看病历是否需要本人授权（已成年的家人）
+// 此时证件、手机号、出生日期及关系对监护人只读，避免修改后绕过授权
+func (p *Patient) NeedsRecordConsentAt(t time.Time) bool {
+	return p.IsDependant() && !p.IsMinorAt(t)
+}
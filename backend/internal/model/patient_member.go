@@ -0,0 +1,184 @@
+package model
+
+import (
+	"time"
+)
+
+// 就诊人共享角色常量
+// 所有者即 Patient.UserID（创建人），不单独落表；共享成员记录在 patient_members
+const (
+	PatientRoleOwner   = "owner"   // 所有者：全部权限，可邀请/移除成员、删除就诊人
+	PatientRoleManager = "manager" // 管理者：可编辑资料、预约挂号、取消预约、查看病历
+	PatientRoleViewer  = "viewer"  // 查看者：仅可查看资料、预约及病历
+)
+
+// 就诊人共享邀请状态常量
+const (
+	PatientInvitationPending  = "pending"  // 待接受
+	PatientInvitationAccepted = "accepted" // 已接受
+	PatientInvitationRevoked  = "revoked"  // 已撤销
+)
+
+// 共享相关站内消息类型
+const (
+	NotificationTypePatientInvite = "patient_invite" // 就诊人共享邀请
+	NotificationTypePatientShared = "patient_shared" // 就诊人共享成员变更
+)
+
+// PatientMember 就诊人共享成员
+type PatientMember struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	PatientID int64     `gorm:"uniqueIndex:uk_patient_member;not null;comment:就诊人ID" json:"patient_id"`
+	UserID    int64     `gorm:"uniqueIndex:uk_patient_member;index;not null;comment:成员用户ID" json:"user_id"`
+	Role      string    `gorm:"type:varchar(20);not null;comment:角色 manager/viewer" json:"role"`
+	InvitedBy int64     `gorm:"default:0;comment:邀请人用户ID" json:"invited_by"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// 关联
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// TableName 表名
+func (PatientMember) TableName() string {
+	return "patient_members"
+}
+
+// PatientMemberVO 就诊人共享成员视图对象
+type PatientMemberVO struct {
+	UserID    int64  `json:"user_id"`
+	Nickname  string `json:"nickname"`
+	Phone     string `json:"phone"` // 脱敏后的手机号
+	Role      string `json:"role"`
+	RoleName  string `json:"role_name"`
+	JoinedAt  string `json:"joined_at,omitempty"`
+	IsCurrent bool   `json:"is_current"` // 是否为当前登录用户
+}
+
+// ToVO 转换为视图对象
+func (m *PatientMember) ToVO() *PatientMemberVO {
+	vo := &PatientMemberVO{
+		UserID:   m.UserID,
+		Role:     m.Role,
+		RoleName: GetPatientRoleName(m.Role),
+		JoinedAt: m.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if m.User != nil {
+		vo.Nickname = m.User.Nickname
+		vo.Phone = maskPhone(m.User.Phone)
+	}
+	return vo
+}
+
+// PatientInvitation 就诊人共享邀请
+// 邀请码一次有效；指定手机号时仅该手机号绑定的账号可接受
+type PatientInvitation struct {
+	BaseModel
+	PatientID    int64      `gorm:"index;not null;comment:就诊人ID" json:"patient_id"`
+	InviterID    int64      `gorm:"index;not null;comment:邀请人用户ID" json:"inviter_id"`
+	Code         string     `gorm:"type:varchar(32);uniqueIndex;not null;comment:邀请码" json:"code"`
+	Role         string     `gorm:"type:varchar(20);not null;comment:授予角色 manager/viewer" json:"role"`
+	InviteePhone string     `gorm:"type:varchar(20);comment:被邀请人手机号（为空表示不限）" json:"invitee_phone"`
+	Status       string     `gorm:"type:varchar(20);index;default:'pending';comment:状态 pending/accepted/revoked" json:"status"`
+	ExpiresAt    time.Time  `gorm:"not null;comment:过期时间" json:"expires_at"`
+	AcceptedBy   int64      `gorm:"default:0;comment:接受人用户ID" json:"accepted_by"`
+	AcceptedAt   *time.Time `gorm:"comment:接受时间" json:"accepted_at,omitempty"`
+
+	// 关联
+	Patient *Patient `gorm:"foreignKey:PatientID" json:"patient,omitempty"`
+}
+
+// TableName 表名
+func (PatientInvitation) TableName() string {
+	return "patient_invitations"
+}
+
+// IsUsable 邀请是否仍可接受
+func (i *PatientInvitation) IsUsable(now time.Time) bool {
+	return i.Status == PatientInvitationPending && now.Before(i.ExpiresAt)
+}
+
+// PatientInvitationVO 就诊人共享邀请视图对象
+type PatientInvitationVO struct {
+	ID           int64  `json:"id"`
+	PatientID    int64  `json:"patient_id"`
+	PatientName  string `json:"patient_name,omitempty"` // 脱敏后的就诊人姓名
+	Code         string `json:"code"`
+	Role         string `json:"role"`
+	RoleName     string `json:"role_name"`
+	InviteePhone string `json:"invitee_phone,omitempty"` // 脱敏后的手机号
+	Status       string `json:"status"`
+	StatusName   string `json:"status_name"`
+	ExpiresAt    string `json:"expires_at"`
+	AcceptedAt   string `json:"accepted_at,omitempty"`
+	CreatedAt    string `json:"created_at"`
+}
+
+// ToVO 转换为视图对象（已过期的待接受邀请显示为已过期）
+func (i *PatientInvitation) ToVO(now time.Time) *PatientInvitationVO {
+	vo := &PatientInvitationVO{
+		ID:           i.ID,
+		PatientID:    i.PatientID,
+		Code:         i.Code,
+		Role:         i.Role,
+		RoleName:     GetPatientRoleName(i.Role),
+		InviteePhone: maskPhone(i.InviteePhone),
+		Status:       i.Status,
+		StatusName:   getPatientInvitationStatusName(i.Status),
+		ExpiresAt:    i.ExpiresAt.Format("2006-01-02 15:04:05"),
+		CreatedAt:    i.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if i.Status == PatientInvitationPending && !now.Before(i.ExpiresAt) {
+		vo.Status = "expired"
+		vo.StatusName = "已过期"
+	}
+	if i.AcceptedAt != nil {
+		vo.AcceptedAt = i.AcceptedAt.Format("2006-01-02 15:04:05")
+	}
+	if i.Patient != nil {
+		vo.PatientName = maskName(i.Patient.Name)
+	}
+	return vo
+}
+
+// PatientRoleLevel 角色权限等级（越大权限越高，未知角色为0）
+func PatientRoleLevel(role string) int {
+	switch role {
+	case PatientRoleOwner:
+		return 3
+	case PatientRoleManager:
+		return 2
+	case PatientRoleViewer:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// GetPatientRoleName 获取共享角色名称
+func GetPatientRoleName(role string) string {
+	switch role {
+	case PatientRoleOwner:
+		return "所有者"
+	case PatientRoleManager:
+		return "管理者"
+	case PatientRoleViewer:
+		return "查看者"
+	default:
+		return "未知"
+	}
+}
+
+// getPatientInvitationStatusName 获取邀请状态名称
+func getPatientInvitationStatusName(status string) string {
+	switch status {
+	case PatientInvitationPending:
+		return "待接受"
+	case PatientInvitationAccepted:
+		return "已接受"
+	case PatientInvitationRevoked:
+		return "已撤销"
+	default:
+		return "未知"
+	}
+}
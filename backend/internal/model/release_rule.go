@@ -0,0 +1,92 @@
+package model
+
+// 放号规则作用范围常量
+const (
+	ReleaseScopeGlobal     = "global"     // 全院默认
+	ReleaseScopeDepartment = "department" // 科室
+	ReleaseScopeDoctor     = "doctor"     // 医生
+)
+
+// ReleaseRule 放号规则模型
+// 优先级：医生 > 科室 > 全院默认；未匹配到规则的排班创建后立即全部放号
+type ReleaseRule struct {
+	BaseModel
+	Name      string `gorm:"type:varchar(64);not null;comment:规则名称" json:"name"`
+	ScopeType string `gorm:"type:varchar(20);index:idx_release_scope;not null;comment:作用范围 global/department/doctor" json:"scope_type"`
+	ScopeID   int64  `gorm:"index:idx_release_scope;default:0;comment:作用对象ID（科室ID/医生ID，全院为0）" json:"scope_id"`
+	Status    int    `gorm:"type:tinyint;default:1;comment:状态 0停用 1启用" json:"status"`
+
+	// 关联
+	Stages []ReleaseRuleStage `gorm:"foreignKey:RuleID" json:"stages,omitempty"`
+}
+
+// TableName 表名
+func (ReleaseRule) TableName() string {
+	return "release_rules"
+}
+
+// ReleaseRuleStage 放号阶段
+// 例如 {DaysBefore: 7, ReleaseTime: "08:00", Percent: 50} 表示就诊日前7天08:00放出50%号源
+type ReleaseRuleStage struct {
+	ID          int64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	RuleID      int64  `gorm:"index;not null;comment:规则ID" json:"rule_id"`
+	DaysBefore  int    `gorm:"type:int;not null;comment:提前天数" json:"days_before"`
+	ReleaseTime string `gorm:"type:varchar(10);not null;comment:放号时间 HH:mm" json:"release_time"`
+	Percent     int    `gorm:"type:int;not null;comment:放号比例(%)" json:"percent"`
+}
+
+// TableName 表名
+func (ReleaseRuleStage) TableName() string {
+	return "release_rule_stages"
+}
+
+// ReleaseRuleVO 放号规则视图对象
+type ReleaseRuleVO struct {
+	ID         int64              `json:"id"`
+	Name       string             `json:"name"`
+	ScopeType  string             `json:"scope_type"`
+	ScopeName  string             `json:"scope_name"`
+	ScopeID    int64              `json:"scope_id"`
+	Status     int                `json:"status"`
+	StatusName string             `json:"status_name"`
+	Stages     []ReleaseRuleStage `json:"stages"`
+	CreatedAt  string             `json:"created_at"`
+}
+
+// ToVO 转换为视图对象
+func (r *ReleaseRule) ToVO() *ReleaseRuleVO {
+	statusName := "启用"
+	if r.Status == StatusDisabled {
+		statusName = "停用"
+	}
+
+	stages := r.Stages
+	if stages == nil {
+		stages = []ReleaseRuleStage{}
+	}
+
+	return &ReleaseRuleVO{
+		ID:         r.ID,
+		Name:       r.Name,
+		ScopeType:  r.ScopeType,
+		ScopeName:  GetReleaseScopeName(r.ScopeType),
+		ScopeID:    r.ScopeID,
+		Status:     r.Status,
+		StatusName: statusName,
+		Stages:     stages,
+		CreatedAt:  r.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// GetReleaseScopeName 获取放号规则范围名称
+func GetReleaseScopeName(scope string) string {
+	scopes := map[string]string{
+		ReleaseScopeGlobal:     "全院",
+		ReleaseScopeDepartment: "科室",
+		ReleaseScopeDoctor:     "医生",
+	}
+	if name, ok := scopes[scope]; ok {
+		return name
+	}
+	return scope
+}
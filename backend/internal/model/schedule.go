@@ -4,20 +4,41 @@ import (
 	"time"
 )
 
+// 号源渠道常量
+const (
+	ChannelOnline = "online" // 线上预约（公共号源池）
+	ChannelOnsite = "onsite" // 现场挂号
+	ChannelVIP    = "vip"    // 院内安排（VIP）
+)
+
 // Schedule 排班模型
 type Schedule struct {
 	BaseModel
-	DoctorID       int64     `gorm:"index;not null;comment:医生ID" json:"doctor_id"`
-	ScheduleDate   time.Time `gorm:"type:date;index;not null;comment:排班日期" json:"schedule_date"`
-	Period         string    `gorm:"type:varchar(20);not null;comment:时段 morning/afternoon" json:"period"`
-	StartTime      string    `gorm:"type:varchar(10);not null;comment:开始时间 HH:mm" json:"start_time"`
-	EndTime        string    `gorm:"type:varchar(10);not null;comment:结束时间 HH:mm" json:"end_time"`
-	TotalSlots     int       `gorm:"type:int;not null;comment:总号源数" json:"total_slots"`
-	AvailableSlots int       `gorm:"type:int;not null;comment:剩余号源数" json:"available_slots"`
-	Status         int       `gorm:"type:tinyint;default:1;comment:状态 0停诊 1正常" json:"status"`
+	DoctorID        int64      `gorm:"index;not null;comment:医生ID" json:"doctor_id"`
+	DepartmentID    int64      `gorm:"index;default:0;comment:出诊科室ID" json:"department_id"`
+	CampusID        int64      `gorm:"index;default:0;comment:出诊院区ID（取出诊科室所属院区）" json:"campus_id"`
+	RoomID          int64      `gorm:"index;default:0;comment:诊室ID 0未指定" json:"room_id"`
+	ScheduleDate    time.Time  `gorm:"type:date;index;not null;comment:排班日期" json:"schedule_date"`
+	Period          string     `gorm:"type:varchar(20);not null;comment:时段 morning/afternoon" json:"period"`
+	StartTime       string     `gorm:"type:varchar(10);not null;comment:开始时间 HH:mm" json:"start_time"`
+	EndTime         string     `gorm:"type:varchar(10);not null;comment:结束时间 HH:mm" json:"end_time"`
+	TotalSlots      int        `gorm:"type:int;not null;comment:总号源数" json:"total_slots"`
+	AvailableSlots  int        `gorm:"type:int;not null;comment:剩余号源数（已放出未预约）" json:"available_slots"`
+	UnreleasedSlots int        `gorm:"type:int;default:0;comment:未放出号源数" json:"unreleased_slots"`
+	NextReleaseAt   *time.Time `gorm:"index;comment:下次放号时间" json:"next_release_at,omitempty"`
+	OnsiteSlots     int        `gorm:"type:int;default:0;comment:现场预留号源数" json:"onsite_slots"`
+	OnsiteAvailable int        `gorm:"type:int;default:0;comment:现场剩余号源数" json:"onsite_available"`
+	VipSlots        int        `gorm:"type:int;default:0;comment:VIP预留号源数" json:"vip_slots"`
+	VipAvailable    int        `gorm:"type:int;default:0;comment:VIP剩余号源数" json:"vip_available"`
+	QuotaRolloverAt *time.Time `gorm:"index;comment:预留号源转入公共池时间" json:"quota_rollover_at,omitempty"`
+	QuotaRolledOver bool       `gorm:"default:false;comment:预留号源是否已转入公共池" json:"quota_rolled_over"`
+	Status          int        `gorm:"type:tinyint;default:1;comment:状态 0停诊 1正常" json:"status"`
 
 	// 关联
-	Doctor *Doctor `gorm:"foreignKey:DoctorID" json:"doctor,omitempty"`
+	Doctor     *Doctor     `gorm:"foreignKey:DoctorID" json:"doctor,omitempty"`
+	Department *Department `gorm:"foreignKey:DepartmentID" json:"department,omitempty"`
+	Campus     *Campus     `gorm:"foreignKey:CampusID" json:"campus,omitempty"`
+	Room       *Room       `gorm:"foreignKey:RoomID" json:"room,omitempty"`
 }
 
 // TableName 表名
@@ -25,6 +46,23 @@ func (Schedule) TableName() string {
 	return "schedules"
 }
 
+// PublicSlots 公共号源池总数（总号源扣除现场/VIP预留）
+// 预留号源转入公共池时，预留数同步扣减为已使用数，因此该值随之增加
+func (s *Schedule) PublicSlots() int {
+	return s.TotalSlots - s.OnsiteSlots - s.VipSlots
+}
+
+// SessionDepartmentID 出诊科室ID（历史排班未记录时取医生主科室）
+func (s *Schedule) SessionDepartmentID() int64 {
+	if s.DepartmentID > 0 {
+		return s.DepartmentID
+	}
+	if s.Doctor != nil {
+		return s.Doctor.DepartmentID
+	}
+	return 0
+}
+
 // ScheduleVO 排班视图对象
 type ScheduleVO struct {
 	ID             int64  `json:"id"`
@@ -42,6 +80,29 @@ type ScheduleVO struct {
 	Status         int    `json:"status"`
 	StatusName     string `json:"status_name"`
 	IsAvailable    bool   `json:"is_available"` // 是否可预约
+
+	CampusID      int64  `json:"campus_id,omitempty"`
+	CampusName    string `json:"campus_name,omitempty"`
+	CampusAddress string `json:"campus_address,omitempty"`
+	RoomID        int64  `json:"room_id,omitempty"`
+	RoomName      string `json:"room_name,omitempty"` // 诊室（含位置）
+
+	UnreleasedSlots int    `json:"unreleased_slots"`          // 未放出号源数
+	NextReleaseAt   string `json:"next_release_at,omitempty"` // 下次放号时间
+	ReleaseTip      string `json:"release_tip,omitempty"`     // 放号提示，如"01-08 08:00 开放预约"
+
+	// 以下字段仅管理后台返回
+	ChannelQuotas   []ChannelQuotaVO `json:"channel_quotas,omitempty"`    // 各渠道号源
+	QuotaRolloverAt string           `json:"quota_rollover_at,omitempty"` // 预留号源转入公共池时间
+}
+
+// ChannelQuotaVO 渠道号源视图对象
+type ChannelQuotaVO struct {
+	Channel     string `json:"channel"`
+	ChannelName string `json:"channel_name"`
+	Total       int    `json:"total"`     // 渠道号源数
+	Available   int    `json:"available"` // 渠道剩余号源数
+	Booked      int    `json:"booked"`    // 渠道已预约数
 }
 
 // ToVO 转换为视图对象
@@ -64,12 +125,32 @@ func (s *Schedule) ToVO() *ScheduleVO {
 		Status:         s.Status,
 		StatusName:     statusName,
 		IsAvailable:    s.Status == StatusEnabled && s.AvailableSlots > 0,
+
+		UnreleasedSlots: s.UnreleasedSlots,
+
+		CampusID: s.CampusID,
+		RoomID:   s.RoomID,
+	}
+	if s.Campus != nil {
+		vo.CampusName = s.Campus.Name
+		vo.CampusAddress = s.Campus.Address
+	}
+	if s.Room != nil {
+		vo.RoomName = s.Room.FullName()
+	}
+
+	if s.NextReleaseAt != nil && s.UnreleasedSlots > 0 {
+		vo.NextReleaseAt = s.NextReleaseAt.Format("2006-01-02 15:04:05")
+		vo.ReleaseTip = s.NextReleaseAt.Format("01-02 15:04") + " 开放预约"
 	}
 
+	vo.DepartmentID = s.SessionDepartmentID()
+	if s.Department != nil {
+		vo.DepartmentName = s.Department.Name
+	}
 	if s.Doctor != nil {
 		vo.DoctorName = s.Doctor.Name
-		vo.DepartmentID = s.Doctor.DepartmentID
-		if s.Doctor.Department != nil {
+		if vo.DepartmentName == "" && s.Doctor.Department != nil && s.Doctor.DepartmentID == vo.DepartmentID {
 			vo.DepartmentName = s.Doctor.Department.Name
 		}
 	}
@@ -77,6 +158,55 @@ func (s *Schedule) ToVO() *ScheduleVO {
 	return vo
 }
 
+// ToAdminVO 转换为管理后台视图对象（附带各渠道号源）
+func (s *Schedule) ToAdminVO() *ScheduleVO {
+	vo := s.ToVO()
+
+	publicSlots := s.PublicSlots()
+	vo.ChannelQuotas = []ChannelQuotaVO{
+		{
+			Channel:     ChannelOnline,
+			ChannelName: GetChannelName(ChannelOnline),
+			Total:       publicSlots,
+			Available:   s.AvailableSlots,
+			Booked:      publicSlots - s.AvailableSlots - s.UnreleasedSlots,
+		},
+		{
+			Channel:     ChannelOnsite,
+			ChannelName: GetChannelName(ChannelOnsite),
+			Total:       s.OnsiteSlots,
+			Available:   s.OnsiteAvailable,
+			Booked:      s.OnsiteSlots - s.OnsiteAvailable,
+		},
+		{
+			Channel:     ChannelVIP,
+			ChannelName: GetChannelName(ChannelVIP),
+			Total:       s.VipSlots,
+			Available:   s.VipAvailable,
+			Booked:      s.VipSlots - s.VipAvailable,
+		},
+	}
+
+	if s.QuotaRolloverAt != nil && !s.QuotaRolledOver {
+		vo.QuotaRolloverAt = s.QuotaRolloverAt.Format("2006-01-02 15:04:05")
+	}
+
+	return vo
+}
+
+// GetChannelName 获取号源渠道名称
+func GetChannelName(channel string) string {
+	channels := map[string]string{
+		ChannelOnline: "线上预约",
+		ChannelOnsite: "现场挂号",
+		ChannelVIP:    "院内安排",
+	}
+	if name, ok := channels[channel]; ok {
+		return name
+	}
+	return channel
+}
+
 // TimeSlot 时间段
 type TimeSlot struct {
 	StartTime   string `json:"start_time"`   // 开始时间 HH:mm
@@ -0,0 +1,119 @@
+package model
+
+import (
+	"time"
+)
+
+// 换班申请状态常量
+const (
+	ScheduleSwapStatusPending   = "pending"   // 待对方医生确认
+	ScheduleSwapStatusAccepted  = "accepted"  // 对方已同意，待科室审批
+	ScheduleSwapStatusApproved  = "approved"  // 审批通过（已换班）
+	ScheduleSwapStatusRejected  = "rejected"  // 对方医生拒绝
+	ScheduleSwapStatusDeclined  = "declined"  // 审批驳回
+	ScheduleSwapStatusCancelled = "cancelled" // 申请人撤销
+)
+
+// ScheduleSwap 换班申请模型
+// 医生A将排班X转给医生B，可选同时接手B的排班Y（互换）
+type ScheduleSwap struct {
+	BaseModel
+	RequesterDoctorID int64      `gorm:"index;not null;comment:申请医生ID" json:"requester_doctor_id"`
+	TargetDoctorID    int64      `gorm:"index;not null;comment:对方医生ID" json:"target_doctor_id"`
+	ScheduleID        int64      `gorm:"index;not null;comment:转出排班ID" json:"schedule_id"`
+	TargetScheduleID  *int64     `gorm:"index;comment:换入排班ID（互换时）" json:"target_schedule_id,omitempty"`
+	Reason            string     `gorm:"type:varchar(256);comment:申请原因" json:"reason"`
+	Status            string     `gorm:"type:varchar(20);default:'pending';index;comment:状态" json:"status"`
+	RespondRemark     string     `gorm:"type:varchar(256);comment:对方医生答复" json:"respond_remark"`
+	RespondedAt       *time.Time `gorm:"comment:对方答复时间" json:"responded_at,omitempty"`
+	ReviewerID        *int64     `gorm:"comment:审批管理员ID" json:"reviewer_id,omitempty"`
+	ReviewRemark      string     `gorm:"type:varchar(256);comment:审批意见" json:"review_remark"`
+	ReviewedAt        *time.Time `gorm:"comment:审批时间" json:"reviewed_at,omitempty"`
+
+	// 关联
+	RequesterDoctor *Doctor   `gorm:"foreignKey:RequesterDoctorID" json:"requester_doctor,omitempty"`
+	TargetDoctor    *Doctor   `gorm:"foreignKey:TargetDoctorID" json:"target_doctor,omitempty"`
+	Schedule        *Schedule `gorm:"foreignKey:ScheduleID" json:"schedule,omitempty"`
+	TargetSchedule  *Schedule `gorm:"foreignKey:TargetScheduleID" json:"target_schedule,omitempty"`
+}
+
+// TableName 表名
+func (ScheduleSwap) TableName() string {
+	return "schedule_swaps"
+}
+
+// IsOpen 是否为进行中的申请
+func (s *ScheduleSwap) IsOpen() bool {
+	return s.Status == ScheduleSwapStatusPending || s.Status == ScheduleSwapStatusAccepted
+}
+
+// ScheduleSwapVO 换班申请视图对象
+type ScheduleSwapVO struct {
+	ID                  int64       `json:"id"`
+	RequesterDoctorID   int64       `json:"requester_doctor_id"`
+	RequesterDoctorName string      `json:"requester_doctor_name"`
+	TargetDoctorID      int64       `json:"target_doctor_id"`
+	TargetDoctorName    string      `json:"target_doctor_name"`
+	Schedule            *ScheduleVO `json:"schedule,omitempty"`
+	TargetSchedule      *ScheduleVO `json:"target_schedule,omitempty"`
+	Reason              string      `json:"reason"`
+	Status              string      `json:"status"`
+	StatusName          string      `json:"status_name"`
+	RespondRemark       string      `json:"respond_remark,omitempty"`
+	RespondedAt         string      `json:"responded_at,omitempty"`
+	ReviewRemark        string      `json:"review_remark,omitempty"`
+	ReviewedAt          string      `json:"reviewed_at,omitempty"`
+	CreatedAt           string      `json:"created_at"`
+}
+
+// ToVO 转换为视图对象
+func (s *ScheduleSwap) ToVO() *ScheduleSwapVO {
+	vo := &ScheduleSwapVO{
+		ID:                s.ID,
+		RequesterDoctorID: s.RequesterDoctorID,
+		TargetDoctorID:    s.TargetDoctorID,
+		Reason:            s.Reason,
+		Status:            s.Status,
+		StatusName:        GetScheduleSwapStatusName(s.Status),
+		RespondRemark:     s.RespondRemark,
+		ReviewRemark:      s.ReviewRemark,
+		CreatedAt:         s.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if s.RequesterDoctor != nil {
+		vo.RequesterDoctorName = s.RequesterDoctor.Name
+	}
+	if s.TargetDoctor != nil {
+		vo.TargetDoctorName = s.TargetDoctor.Name
+	}
+	if s.Schedule != nil {
+		vo.Schedule = s.Schedule.ToVO()
+	}
+	if s.TargetSchedule != nil {
+		vo.TargetSchedule = s.TargetSchedule.ToVO()
+	}
+	if s.RespondedAt != nil {
+		vo.RespondedAt = s.RespondedAt.Format("2006-01-02 15:04:05")
+	}
+	if s.ReviewedAt != nil {
+		vo.ReviewedAt = s.ReviewedAt.Format("2006-01-02 15:04:05")
+	}
+
+	return vo
+}
+
+// GetScheduleSwapStatusName 获取换班申请状态名称
+func GetScheduleSwapStatusName(status string) string {
+	statuses := map[string]string{
+		ScheduleSwapStatusPending:   "待对方确认",
+		ScheduleSwapStatusAccepted:  "待审批",
+		ScheduleSwapStatusApproved:  "已换班",
+		ScheduleSwapStatusRejected:  "对方已拒绝",
+		ScheduleSwapStatusDeclined:  "审批驳回",
+		ScheduleSwapStatusCancelled: "已撤销",
+	}
+	if name, ok := statuses[status]; ok {
+		return name
+	}
+	return status
+}
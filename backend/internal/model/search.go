@@ -0,0 +1,123 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// 搜索对象类型常量
+const (
+	SearchEntityDoctor     = "doctor"     // 医生
+	SearchEntityDepartment = "department" // 科室
+)
+
+// SearchDocument 搜索索引文档（医生/科室）
+// 由医生、科室数据派生，变更时增量更新，定时任务全量重建；不使用软删除
+type SearchDocument struct {
+	EntityType string    `gorm:"primaryKey;type:varchar(20);comment:对象类型 doctor/department" json:"entity_type"`
+	EntityID   int64     `gorm:"primaryKey;comment:对象ID" json:"entity_id"`
+	Name       string    `gorm:"type:varchar(64);index;not null;comment:名称" json:"name"`
+	Pinyin     string    `gorm:"type:varchar(512);comment:全拼（多音字多种读音以空格分隔）" json:"pinyin"`
+	Initials   string    `gorm:"type:varchar(256);comment:拼音首字母（多种读音以空格分隔）" json:"initials"`
+	Keywords   string    `gorm:"type:text;comment:关键词（擅长领域、科室名称、职称等）" json:"keywords"`
+	SortOrder  int       `gorm:"type:int;default:0;comment:排序序号" json:"sort_order"`
+	Status     int       `gorm:"type:tinyint;default:1;index;comment:状态 0不可检索 1可检索" json:"status"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName 表名
+func (SearchDocument) TableName() string {
+	return "search_documents"
+}
+
+// SearchSynonym 搜索同义词组（组内词语互为同义词，如 "心脏,心血管,胸闷"）
+type SearchSynonym struct {
+	BaseModel
+	Words  string `gorm:"type:varchar(512);not null;comment:同义词(逗号分隔)" json:"words"`
+	Remark string `gorm:"type:varchar(256);comment:备注" json:"remark"`
+	Status int    `gorm:"type:tinyint;default:1;comment:状态 0停用 1启用" json:"status"`
+}
+
+// TableName 表名
+func (SearchSynonym) TableName() string {
+	return "search_synonyms"
+}
+
+// WordList 同义词列表
+func (s *SearchSynonym) WordList() []string {
+	var list []string
+	for _, w := range strings.Split(s.Words, ",") {
+		if w = strings.TrimSpace(w); w != "" {
+			list = append(list, w)
+		}
+	}
+	return list
+}
+
+// SearchSynonymVO 同义词组视图对象
+type SearchSynonymVO struct {
+	ID         int64    `json:"id"`
+	Words      []string `json:"words"`
+	Remark     string   `json:"remark"`
+	Status     int      `json:"status"`
+	StatusName string   `json:"status_name"`
+	CreatedAt  string   `json:"created_at"`
+	UpdatedAt  string   `json:"updated_at"`
+}
+
+// ToVO 转换为视图对象
+func (s *SearchSynonym) ToVO() *SearchSynonymVO {
+	statusName := "启用"
+	if s.Status == StatusDisabled {
+		statusName = "停用"
+	}
+	return &SearchSynonymVO{
+		ID:         s.ID,
+		Words:      s.WordList(),
+		Remark:     s.Remark,
+		Status:     s.Status,
+		StatusName: statusName,
+		CreatedAt:  s.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:  s.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// SearchAvailability 可预约提示
+type SearchAvailability struct {
+	HasAvailable   bool   `json:"has_available"`              // 近期是否有号
+	NextDate       string `json:"next_date,omitempty"`        // 最近可预约日期
+	NextPeriod     string `json:"next_period,omitempty"`      // 最近可预约时段
+	NextPeriodName string `json:"next_period_name,omitempty"` // 最近可预约时段名称
+	AvailableSlots int    `json:"available_slots"`            // 近期剩余号源合计
+	Upcoming       bool   `json:"upcoming"`                   // 近期有排班但尚未放号
+}
+
+// DoctorSearchVO 医生搜索结果
+type DoctorSearchVO struct {
+	ID             int64              `json:"id"`
+	Name           string             `json:"name"`
+	Avatar         string             `json:"avatar"`
+	Title          string             `json:"title"`
+	TitleName      string             `json:"title_name"`
+	Specialty      string             `json:"specialty"`
+	DepartmentID   int64              `json:"department_id"`
+	DepartmentName string             `json:"department_name,omitempty"`
+	Rating         float64            `json:"rating"`
+	ReviewCount    int64              `json:"review_count"`
+	Score          float64            `json:"score"`        // 相关度得分
+	MatchedBy      []string           `json:"matched_by"`   // 命中方式 name/pinyin/initials/specialty/department/synonym
+	Availability   SearchAvailability `json:"availability"` // 可预约提示
+}
+
+// DepartmentSearchVO 科室搜索结果
+type DepartmentSearchVO struct {
+	ID           int64              `json:"id"`
+	ParentID     int64              `json:"parent_id"`
+	Name         string             `json:"name"`
+	Description  string             `json:"description"`
+	Icon         string             `json:"icon"`
+	DoctorCount  int64              `json:"doctor_count"`
+	Score        float64            `json:"score"`
+	MatchedBy    []string           `json:"matched_by"`
+	Availability SearchAvailability `json:"availability"`
+}
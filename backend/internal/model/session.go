@@ -0,0 +1,98 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// 会话吊销原因
+const (
+	SessionRevokeLogout   = "logout"   // 用户退出登录
+	SessionRevokeUser     = "revoked"  // 用户在设备管理中下线
+	SessionRevokeReuse    = "reuse"    // 检测到刷新Token被重复使用
+	SessionRevokeSecurity = "security" // 改密、注销、合并等整体吊销
+)
+
+// UserSession 用户登录会话（每次登录一个会话，Token 中携带会话ID）
+// 刷新Token每次使用后轮换，会话仅记录当前有效的刷新Token标识；
+// 已轮换的刷新Token再次出现视为泄露，整个会话随之吊销
+type UserSession struct {
+	BaseModel
+	UserID       int64      `gorm:"index;not null;comment:用户ID" json:"user_id"`
+	SessionID    string     `gorm:"type:varchar(64);uniqueIndex;not null;comment:会话ID" json:"-"`
+	RefreshID    string     `gorm:"type:varchar(64);comment:当前刷新Token标识(jti)" json:"-"`
+	LoginType    string     `gorm:"type:varchar(20);comment:登录方式" json:"login_type"`
+	IP           string     `gorm:"type:varchar(64);comment:登录IP" json:"ip"`
+	UserAgent    string     `gorm:"type:varchar(256);comment:登录设备UA" json:"-"`
+	LastActiveAt time.Time  `gorm:"comment:最后刷新时间" json:"last_active_at"`
+	LastIP       string     `gorm:"type:varchar(64);comment:最后刷新IP" json:"last_ip"`
+	ExpiresAt    time.Time  `gorm:"index;comment:会话过期时间（随刷新顺延）" json:"expires_at"`
+	RevokedAt    *time.Time `gorm:"index;comment:吊销时间" json:"revoked_at,omitempty"`
+	RevokeReason string     `gorm:"type:varchar(20);comment:吊销原因" json:"revoke_reason,omitempty"`
+}
+
+// TableName 表名
+func (UserSession) TableName() string {
+	return "user_sessions"
+}
+
+// IsActive 会话是否仍然有效
+func (s *UserSession) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// UserSessionVO 登录设备视图对象
+type UserSessionVO struct {
+	ID           int64  `json:"id"`
+	Device       string `json:"device"`
+	LoginType    string `json:"login_type"`
+	IP           string `json:"ip"`
+	LastIP       string `json:"last_ip"`
+	LoginAt      string `json:"login_at"`
+	LastActiveAt string `json:"last_active_at"`
+	Current      bool   `json:"current"` // 是否为当前设备
+}
+
+// ToVO 转换为视图对象
+func (s *UserSession) ToVO(currentSessionID string) *UserSessionVO {
+	return &UserSessionVO{
+		ID:           s.ID,
+		Device:       describeDevice(s.UserAgent),
+		LoginType:    s.LoginType,
+		IP:           s.IP,
+		LastIP:       s.LastIP,
+		LoginAt:      s.CreatedAt.Format("2006-01-02 15:04:05"),
+		LastActiveAt: s.LastActiveAt.Format("2006-01-02 15:04:05"),
+		Current:      s.SessionID == currentSessionID,
+	}
+}
+
+// describeDevice 根据UA粗略识别设备
+func describeDevice(userAgent string) string {
+	var client, system string
+	switch {
+	case strings.Contains(userAgent, "miniProgram") || strings.Contains(userAgent, "MiniProgram"):
+		client = "微信小程序"
+	case strings.Contains(userAgent, "MicroMessenger"):
+		client = "微信"
+	default:
+		client = "浏览器"
+	}
+	switch {
+	case strings.Contains(userAgent, "iPhone"), strings.Contains(userAgent, "iPad"):
+		system = "iOS"
+	case strings.Contains(userAgent, "Android"):
+		system = "Android"
+	case strings.Contains(userAgent, "Windows"):
+		system = "Windows"
+	case strings.Contains(userAgent, "Mac OS"):
+		system = "macOS"
+	}
+	if userAgent == "" {
+		return "未知设备"
+	}
+	if system == "" {
+		return client
+	}
+	return system + " " + client
+}
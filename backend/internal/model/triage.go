@@ -0,0 +1,136 @@
+package model
+
+import (
+	"strings"
+)
+
+// TriageRule 导诊规则（症状关键词 -> 推荐科室）
+// 症状描述命中任一关键词且满足年龄/性别限制时，按权重为对应科室累计得分
+type TriageRule struct {
+	BaseModel
+	Keywords     string `gorm:"type:varchar(512);not null;comment:症状关键词(逗号分隔，命中任一即可)" json:"keywords"`
+	DepartmentID int64  `gorm:"index;not null;comment:推荐科室ID" json:"department_id"`
+	Weight       int    `gorm:"type:int;default:10;comment:权重 1-100" json:"weight"`
+	MinAge       int    `gorm:"type:int;default:0;comment:最小年龄（含） 0不限" json:"min_age"`
+	MaxAge       int    `gorm:"type:int;default:0;comment:最大年龄（含） 0不限" json:"max_age"`
+	Gender       int    `gorm:"type:tinyint;default:0;comment:适用性别 0不限 1男 2女" json:"gender"`
+	Explanation  string `gorm:"type:varchar(256);comment:推荐说明" json:"explanation"`
+	Status       int    `gorm:"type:tinyint;default:1;index;comment:状态 0停用 1启用" json:"status"`
+
+	// 关联
+	Department *Department `gorm:"foreignKey:DepartmentID" json:"department,omitempty"`
+}
+
+// TableName 表名
+func (TriageRule) TableName() string {
+	return "triage_rules"
+}
+
+// KeywordList 关键词列表
+func (r *TriageRule) KeywordList() []string {
+	var list []string
+	for _, w := range strings.Split(r.Keywords, ",") {
+		if w = strings.TrimSpace(w); w != "" {
+			list = append(list, w)
+		}
+	}
+	return list
+}
+
+// MatchProfile 判断患者年龄/性别是否满足规则限制（年龄、性别未知时不做限制）
+func (r *TriageRule) MatchProfile(age *int, gender int) bool {
+	if age != nil {
+		if r.MinAge > 0 && *age < r.MinAge {
+			return false
+		}
+		if r.MaxAge > 0 && *age > r.MaxAge {
+			return false
+		}
+	}
+	if r.Gender != GenderUnknown && gender != GenderUnknown && r.Gender != gender {
+		return false
+	}
+	return true
+}
+
+// ConstraintText 适用人群说明
+func (r *TriageRule) ConstraintText() string {
+	if text := describeAgeGender(r.MinAge, r.MaxAge, r.Gender); text != "" {
+		return text
+	}
+	return "不限"
+}
+
+// TriageRuleVO 导诊规则视图对象
+type TriageRuleVO struct {
+	ID             int64    `json:"id"`
+	Keywords       []string `json:"keywords"`
+	DepartmentID   int64    `json:"department_id"`
+	DepartmentName string   `json:"department_name"`
+	Weight         int      `json:"weight"`
+	MinAge         int      `json:"min_age"`
+	MaxAge         int      `json:"max_age"`
+	Gender         int      `json:"gender"`
+	Constraint     string   `json:"constraint"` // 适用人群说明
+	Explanation    string   `json:"explanation"`
+	Status         int      `json:"status"`
+	StatusName     string   `json:"status_name"`
+	CreatedAt      string   `json:"created_at"`
+	UpdatedAt      string   `json:"updated_at"`
+}
+
+// ToVO 转换为视图对象
+func (r *TriageRule) ToVO() *TriageRuleVO {
+	statusName := "启用"
+	if r.Status == StatusDisabled {
+		statusName = "停用"
+	}
+	vo := &TriageRuleVO{
+		ID:           r.ID,
+		Keywords:     r.KeywordList(),
+		DepartmentID: r.DepartmentID,
+		Weight:       r.Weight,
+		MinAge:       r.MinAge,
+		MaxAge:       r.MaxAge,
+		Gender:       r.Gender,
+		Constraint:   r.ConstraintText(),
+		Explanation:  r.Explanation,
+		Status:       r.Status,
+		StatusName:   statusName,
+		CreatedAt:    r.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:    r.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if r.Department != nil {
+		vo.DepartmentName = r.Department.Name
+	}
+	return vo
+}
+
+// TriageRecommendationVO 推荐科室
+type TriageRecommendationVO struct {
+	DepartmentID    int64    `json:"department_id"`
+	DepartmentName  string   `json:"department_name"`
+	ParentID        int64    `json:"parent_id"`
+	Score           int      `json:"score"`            // 命中规则权重合计
+	Confidence      int      `json:"confidence"`       // 推荐度（占全部推荐得分的百分比）
+	MatchedKeywords []string `json:"matched_keywords"` // 命中的症状关键词
+	Reasons         []string `json:"reasons"`          // 推荐理由
+}
+
+// TriageResultVO 导诊推荐结果
+type TriageResultVO struct {
+	Symptom         string                   `json:"symptom"`
+	Age             *int                     `json:"age,omitempty"`
+	Gender          int                      `json:"gender"`
+	Recommendations []TriageRecommendationVO `json:"recommendations"`
+	Notice          string                   `json:"notice"` // 提示语
+}
+
+// TriageCheckVO 预约科室与症状匹配检查结果
+type TriageCheckVO struct {
+	DepartmentID    int64                    `json:"department_id"`
+	DepartmentName  string                   `json:"department_name"`
+	Unlikely        bool                     `json:"unlikely"` // 所选科室与症状不太匹配
+	Message         string                   `json:"message,omitempty"`
+	Recommendations []TriageRecommendationVO `json:"recommendations"`
+}
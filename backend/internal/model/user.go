@@ -21,6 +21,8 @@ type User struct {
 	MissedCount  int        `gorm:"type:int;default:0;comment:累计爽约次数" json:"missed_count"`
 	LastLoginAt  *time.Time `gorm:"comment:最后登录时间" json:"last_login_at,omitempty"`
 	LastLoginIP  string     `gorm:"type:varchar(64);comment:最后登录IP" json:"last_login_ip,omitempty"`
+
+	TokensRevokedAt *time.Time `gorm:"comment:Token整体吊销时间（此前签发的Token均失效）" json:"-"`
 }
 
 // TableName 表名
@@ -60,6 +62,156 @@ func (u *User) ToVO() *UserVO {
 	}
 }
 
+// UserLoginsVO 账号已绑定的登录方式
+type UserLoginsVO struct {
+	WeChat   bool   `json:"wechat"`             // 是否绑定微信
+	Phone    string `json:"phone,omitempty"`    // 脱敏后的手机号
+	Username string `json:"username,omitempty"` // 用户名（已设置密码时可用于密码登录）
+	Password bool   `json:"password"`           // 是否可使用密码登录
+	Count    int    `json:"count"`              // 可用登录方式数量
+}
+
+// HasWeChatLogin 是否可使用微信登录
+func (u *User) HasWeChatLogin() bool {
+	return u.OpenID != ""
+}
+
+// HasPhoneLogin 是否可使用手机号验证码登录
+func (u *User) HasPhoneLogin() bool {
+	return u.Phone != ""
+}
+
+// HasPasswordLogin 是否可使用用户名密码登录
+func (u *User) HasPasswordLogin() bool {
+	return u.Username != "" && u.Password != ""
+}
+
+// LoginCount 可用登录方式数量
+func (u *User) LoginCount() int {
+	count := 0
+	for _, ok := range []bool{u.HasWeChatLogin(), u.HasPhoneLogin(), u.HasPasswordLogin()} {
+		if ok {
+			count++
+		}
+	}
+	return count
+}
+
+// ToLoginsVO 转换为登录方式视图对象
+func (u *User) ToLoginsVO() *UserLoginsVO {
+	vo := &UserLoginsVO{
+		WeChat:   u.HasWeChatLogin(),
+		Phone:    maskPhone(u.Phone),
+		Password: u.HasPasswordLogin(),
+		Count:    u.LoginCount(),
+	}
+	if vo.Password {
+		vo.Username = u.Username
+	}
+	return vo
+}
+
+// UserAdminVO 用户账号视图对象（管理后台）
+type UserAdminVO struct {
+	ID           int64         `json:"id"`
+	Nickname     string        `json:"nickname"`
+	Avatar       string        `json:"avatar"`
+	Phone        string        `json:"phone"`
+	Username     string        `json:"username"`
+	Gender       int           `json:"gender"`
+	Logins       *UserLoginsVO `json:"logins"`
+	Status       int           `json:"status"`
+	MissedCount  int           `json:"missed_count"`
+	BlockedUntil string        `json:"blocked_until,omitempty"`
+	IsBlocked    bool          `json:"is_blocked"`
+	LastLoginAt  string        `json:"last_login_at,omitempty"`
+	LastLoginIP  string        `json:"last_login_ip,omitempty"`
+	CreatedAt    string        `json:"created_at"`
+}
+
+// ToAdminVO 转换为管理后台视图对象
+func (u *User) ToAdminVO() *UserAdminVO {
+	vo := &UserAdminVO{
+		ID:          u.ID,
+		Nickname:    u.Nickname,
+		Avatar:      u.Avatar,
+		Phone:       maskPhone(u.Phone),
+		Username:    u.Username,
+		Gender:      u.Gender,
+		Logins:      u.ToLoginsVO(),
+		Status:      u.Status,
+		MissedCount: u.MissedCount,
+		IsBlocked:   u.IsBlocked(),
+		LastLoginIP: u.LastLoginIP,
+		CreatedAt:   u.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if u.BlockedUntil != nil {
+		vo.BlockedUntil = u.BlockedUntil.Format("2006-01-02 15:04:05")
+	}
+	if u.LastLoginAt != nil {
+		vo.LastLoginAt = u.LastLoginAt.Format("2006-01-02 15:04:05")
+	}
+	return vo
+}
+
+// 用户账号管理操作
+const (
+	UserAdminActionEnable  = "enable"  // 启用账号
+	UserAdminActionDisable = "disable" // 停用账号
+	UserAdminActionUnblock = "unblock" // 解除爽约封禁
+)
+
+// UserAdminAction 管理员对用户账号的操作记录（含操作原因）
+type UserAdminAction struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    int64     `gorm:"index;not null;comment:用户ID" json:"user_id"`
+	AdminID   int64     `gorm:"index;comment:操作管理员ID" json:"admin_id"`
+	AdminName string    `gorm:"type:varchar(64);comment:操作管理员用户名" json:"admin_name"`
+	Action    string    `gorm:"type:varchar(20);comment:操作 enable/disable/unblock" json:"action"`
+	Reason    string    `gorm:"type:varchar(256);comment:操作原因" json:"reason"`
+	CreatedAt time.Time `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
+// TableName 表名
+func (UserAdminAction) TableName() string {
+	return "user_admin_actions"
+}
+
+// UserAdminActionVO 用户账号操作记录视图对象
+type UserAdminActionVO struct {
+	ID         int64  `json:"id"`
+	AdminName  string `json:"admin_name"`
+	Action     string `json:"action"`
+	ActionName string `json:"action_name"`
+	Reason     string `json:"reason"`
+	CreatedAt  string `json:"created_at"`
+}
+
+// ToVO 转换为视图对象
+func (a *UserAdminAction) ToVO() *UserAdminActionVO {
+	return &UserAdminActionVO{
+		ID:         a.ID,
+		AdminName:  a.AdminName,
+		Action:     a.Action,
+		ActionName: getUserAdminActionName(a.Action),
+		Reason:     a.Reason,
+		CreatedAt:  a.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func getUserAdminActionName(action string) string {
+	switch action {
+	case UserAdminActionEnable:
+		return "启用账号"
+	case UserAdminActionDisable:
+		return "停用账号"
+	case UserAdminActionUnblock:
+		return "解除封禁"
+	default:
+		return "未知"
+	}
+}
+
 // maskPhone 手机号脱敏
 func maskPhone(phone string) string {
 	if len(phone) != 11 {
@@ -9,6 +9,9 @@ const (
 
 // Permission codes (module:action)
 const (
+	PermCampusView   = "campus:view"
+	PermCampusManage = "campus:manage"
+
 	PermDepartmentView   = "department:view"
 	PermDepartmentCreate = "department:create"
 	PermDepartmentUpdate = "department:update"
@@ -19,17 +22,55 @@ const (
 	PermDoctorUpdate = "doctor:update"
 	PermDoctorDelete = "doctor:delete"
 
+	PermDoctorAccountView   = "doctor_account:view"
+	PermDoctorAccountManage = "doctor_account:manage"
+
+	PermDoctorLeaveView   = "doctor_leave:view"
+	PermDoctorLeaveReview = "doctor_leave:review"
+
+	PermReviewView     = "review:view"
+	PermReviewModerate = "review:moderate"
+
+	PermSearchView   = "search:view"
+	PermSearchManage = "search:manage"
+
+	PermTriageView   = "triage:view"
+	PermTriageManage = "triage:manage"
+
 	PermScheduleView   = "schedule:view"
 	PermScheduleCreate = "schedule:create"
 	PermScheduleUpdate = "schedule:update"
 	PermScheduleDelete = "schedule:delete"
 	PermScheduleBatch  = "schedule:batch"
+	PermScheduleImport = "schedule:import"
+	PermScheduleCopy   = "schedule:copy"
+
+	PermReleaseRuleView   = "release_rule:view"
+	PermReleaseRuleManage = "release_rule:manage"
+
+	PermScheduleSwapView    = "schedule_swap:view"
+	PermScheduleSwapManage  = "schedule_swap:manage"
+	PermScheduleSwapApprove = "schedule_swap:approve"
 
 	PermAppointmentView   = "appointment:view"
+	PermAppointmentCreate = "appointment:create"
 	PermAppointmentUpdate = "appointment:update"
 	PermAppointmentExport = "appointment:export"
 
-	PermPatientView = "patient:view"
+	PermPatientView  = "patient:view"
+	PermPatientMerge = "patient:merge"
+
+	PermUserView   = "user:view"
+	PermUserManage = "user:manage"
+
+	PermAgreementView   = "agreement:view"
+	PermAgreementManage = "agreement:manage"
+	PermAgreementAudit  = "agreement:audit"
+
+	PermRecordView  = "record:view"
+	PermRecordWrite = "record:write"
+	PermRecordSign  = "record:sign"
+	PermRecordAmend = "record:amend"
 
 	PermStatisticsView = "statistics:view"
 
@@ -61,6 +102,10 @@ type PermissionDef struct {
 
 // DefaultPermissions 默认权限清单（用于初始化/对齐权限表）
 var DefaultPermissions = []PermissionDef{
+	// 院区管理
+	{Code: PermCampusView, Name: "查看院区", Module: "campus", Description: "查看院区/诊室列表及详情", SortOrder: 1},
+	{Code: PermCampusManage, Name: "管理院区", Module: "campus", Description: "创建/编辑/删除院区及诊室", SortOrder: 2},
+
 	// 科室管理
 	{Code: PermDepartmentView, Name: "查看科室", Module: "department", Description: "查看科室列表/详情", SortOrder: 1},
 	{Code: PermDepartmentCreate, Name: "创建科室", Module: "department", Description: "创建科室", SortOrder: 2},
@@ -72,6 +117,22 @@ var DefaultPermissions = []PermissionDef{
 	{Code: PermDoctorCreate, Name: "创建医生", Module: "doctor", Description: "创建医生", SortOrder: 2},
 	{Code: PermDoctorUpdate, Name: "编辑医生", Module: "doctor", Description: "更新医生", SortOrder: 3},
 	{Code: PermDoctorDelete, Name: "删除医生", Module: "doctor", Description: "删除医生", SortOrder: 4},
+	{Code: PermDoctorAccountView, Name: "查看医生账号", Module: "doctor", Description: "查看医生工作台账号列表", SortOrder: 5},
+	{Code: PermDoctorAccountManage, Name: "管理医生账号", Module: "doctor", Description: "开通/启停医生账号、重置密码", SortOrder: 6},
+	{Code: PermDoctorLeaveView, Name: "查看停诊申请", Module: "doctor", Description: "查看医生停诊申请列表/详情", SortOrder: 7},
+	{Code: PermDoctorLeaveReview, Name: "审批停诊申请", Module: "doctor", Description: "审批停诊申请并停诊相关排班", SortOrder: 8},
+
+	// 评价管理
+	{Code: PermReviewView, Name: "查看评价", Module: "review", Description: "查看患者评价列表/详情", SortOrder: 1},
+	{Code: PermReviewModerate, Name: "审核评价", Module: "review", Description: "屏蔽/恢复患者评价", SortOrder: 2},
+
+	// 搜索管理
+	{Code: PermSearchView, Name: "查看搜索配置", Module: "search", Description: "查看搜索同义词", SortOrder: 1},
+	{Code: PermSearchManage, Name: "管理搜索配置", Module: "search", Description: "维护搜索同义词、重建搜索索引", SortOrder: 2},
+
+	// 导诊规则
+	{Code: PermTriageView, Name: "查看导诊规则", Module: "triage", Description: "查看导诊规则列表/详情、试算导诊结果", SortOrder: 1},
+	{Code: PermTriageManage, Name: "管理导诊规则", Module: "triage", Description: "创建/编辑/删除导诊规则", SortOrder: 2},
 
 	// 排班管理
 	{Code: PermScheduleView, Name: "查看排班", Module: "schedule", Description: "查看排班列表/详情", SortOrder: 1},
@@ -79,14 +140,38 @@ var DefaultPermissions = []PermissionDef{
 	{Code: PermScheduleUpdate, Name: "编辑排班", Module: "schedule", Description: "更新排班", SortOrder: 3},
 	{Code: PermScheduleDelete, Name: "删除排班", Module: "schedule", Description: "删除排班", SortOrder: 4},
 	{Code: PermScheduleBatch, Name: "批量排班", Module: "schedule", Description: "批量创建排班", SortOrder: 5},
+	{Code: PermScheduleImport, Name: "导入排班", Module: "schedule", Description: "通过CSV/XLSX导入排班", SortOrder: 8},
+	{Code: PermScheduleCopy, Name: "复制排班", Module: "schedule", Description: "按日期范围复制/平移排班", SortOrder: 9},
+	{Code: PermReleaseRuleView, Name: "查看放号规则", Module: "schedule", Description: "查看放号规则列表", SortOrder: 6},
+	{Code: PermReleaseRuleManage, Name: "管理放号规则", Module: "schedule", Description: "创建/更新/删除放号规则", SortOrder: 7},
+	{Code: PermScheduleSwapView, Name: "查看换班申请", Module: "schedule", Description: "查看换班申请列表/详情", SortOrder: 10},
+	{Code: PermScheduleSwapManage, Name: "办理换班申请", Module: "schedule", Description: "代医生发起/答复/撤销换班申请", SortOrder: 11},
+	{Code: PermScheduleSwapApprove, Name: "审批换班申请", Module: "schedule", Description: "审批换班申请并转移排班及预约", SortOrder: 12},
 
 	// 预约管理
 	{Code: PermAppointmentView, Name: "查看预约", Module: "appointment", Description: "查看预约列表/详情", SortOrder: 1},
 	{Code: PermAppointmentUpdate, Name: "处理预约", Module: "appointment", Description: "更新预约状态", SortOrder: 2},
 	{Code: PermAppointmentExport, Name: "导出预约", Module: "appointment", Description: "导出预约数据", SortOrder: 3},
+	{Code: PermAppointmentCreate, Name: "渠道挂号", Module: "appointment", Description: "现场挂号/院内安排", SortOrder: 4},
+
+	// 病历管理
+	{Code: PermRecordView, Name: "查看病历", Module: "record", Description: "查看病历列表/详情", SortOrder: 1},
+	{Code: PermRecordWrite, Name: "书写病历", Module: "record", Description: "创建/编辑病历草稿", SortOrder: 2},
+	{Code: PermRecordSign, Name: "签署病历", Module: "record", Description: "签署并锁定病历", SortOrder: 3},
+	{Code: PermRecordAmend, Name: "补充更正病历", Module: "record", Description: "修改已签署的病历（留痕）", SortOrder: 4},
 
 	// 患者管理
 	{Code: PermPatientView, Name: "查看患者", Module: "patient", Description: "查看患者列表/详情", SortOrder: 1},
+	{Code: PermPatientMerge, Name: "合并患者", Module: "patient", Description: "审核疑似重复患者、合并及撤销合并", SortOrder: 2},
+
+	// 用户账号管理
+	{Code: PermUserView, Name: "查看用户账号", Module: "user", Description: "查看用户账号列表/详情及登录记录", SortOrder: 1},
+	{Code: PermUserManage, Name: "管理用户账号", Module: "user", Description: "启用/停用用户账号、解除爽约封禁", SortOrder: 2},
+
+	// 协议管理
+	{Code: PermAgreementView, Name: "查看协议", Module: "agreement", Description: "查看隐私政策、用户协议等协议版本", SortOrder: 1},
+	{Code: PermAgreementManage, Name: "管理协议", Module: "agreement", Description: "编辑、发布协议版本", SortOrder: 2},
+	{Code: PermAgreementAudit, Name: "协议同意审计", Module: "agreement", Description: "查询及导出用户协议同意记录", SortOrder: 3},
 
 	// 数据统计
 	{Code: PermStatisticsView, Name: "查看统计", Module: "statistics", Description: "查看仪表盘/统计数据", SortOrder: 1},
@@ -122,10 +207,11 @@ var AdminRoutePermissions = map[string][]string{
 	"PUT /api/admin/password": {},
 
 	// 管理员管理
-	"GET /api/admin/admins":              {PermAdminView},
-	"POST /api/admin/admins":             {PermAdminCreate},
-	"PUT /api/admin/admins/:id":          {PermAdminUpdate},
-	"PUT /api/admin/admins/:id/password": {PermAdminPassword},
+	"GET /api/admin/admins":                  {PermAdminView},
+	"POST /api/admin/admins":                 {PermAdminCreate},
+	"PUT /api/admin/admins/:id":              {PermAdminUpdate},
+	"PUT /api/admin/admins/:id/password":     {PermAdminPassword},
+	"PUT /api/admin/admins/:id/campus-roles": {PermAdminUpdate},
 
 	// 角色管理
 	"GET /api/admin/roles":                 {PermRoleView},
@@ -143,41 +229,191 @@ var AdminRoutePermissions = map[string][]string{
 
 	// 预约管理
 	"GET /api/admin/appointments":        {PermAppointmentView},
+	"POST /api/admin/appointments":       {PermAppointmentCreate},
 	"GET /api/admin/appointments/:id":    {PermAppointmentView},
 	"PUT /api/admin/appointments/:id":    {PermAppointmentUpdate},
 	"GET /api/admin/appointments/export": {PermAppointmentExport},
 
+	// 病历管理
+	"GET /api/admin/records":                 {PermRecordView},
+	"GET /api/admin/records/:id":             {PermRecordView},
+	"GET /api/admin/appointments/:id/record": {PermRecordView},
+	"PUT /api/admin/appointments/:id/record": {PermRecordWrite},
+	"PUT /api/admin/records/:id/sign":        {PermRecordSign},
+	"POST /api/admin/records/:id/amendments": {PermRecordAmend},
+
 	// 患者管理
-	"GET /api/admin/patients":     {PermPatientView},
-	"GET /api/admin/patients/:id": {PermPatientView},
+	"GET /api/admin/patients":                        {PermPatientView},
+	"GET /api/admin/patients/:id":                    {PermPatientView},
+	"GET /api/admin/patients/duplicates":             {PermPatientView},
+	"POST /api/admin/patients/duplicates/detect":     {PermPatientMerge},
+	"POST /api/admin/patients/duplicates/:id/merge":  {PermPatientMerge},
+	"POST /api/admin/patients/duplicates/:id/ignore": {PermPatientMerge},
+	"GET /api/admin/patients/merges":                 {PermPatientView},
+	"POST /api/admin/patients/merges/:id/undo":       {PermPatientMerge},
+
+	// 用户账号管理
+	"GET /api/admin/users":             {PermUserView},
+	"GET /api/admin/users/:id":         {PermUserView},
+	"PUT /api/admin/users/:id/enable":  {PermUserManage},
+	"PUT /api/admin/users/:id/disable": {PermUserManage},
+	"PUT /api/admin/users/:id/unblock": {PermUserManage},
+
+	// 协议管理
+	"GET /api/admin/agreements":                 {PermAgreementView},
+	"GET /api/admin/agreements/:id":             {PermAgreementView},
+	"POST /api/admin/agreements":                {PermAgreementManage},
+	"PUT /api/admin/agreements/:id":             {PermAgreementManage},
+	"DELETE /api/admin/agreements/:id":          {PermAgreementManage},
+	"POST /api/admin/agreements/:id/publish":    {PermAgreementManage},
+	"GET /api/admin/agreements/consents":        {PermAgreementAudit},
+	"GET /api/admin/agreements/consents/export": {PermAgreementAudit},
+
+	// 院区/诊室管理
+	"GET /api/admin/campuses":        {PermCampusView},
+	"GET /api/admin/campuses/:id":    {PermCampusView},
+	"POST /api/admin/campuses":       {PermCampusManage},
+	"PUT /api/admin/campuses/:id":    {PermCampusManage},
+	"DELETE /api/admin/campuses/:id": {PermCampusManage},
+	"GET /api/admin/rooms":           {PermCampusView},
+	"GET /api/admin/rooms/:id":       {PermCampusView},
+	"POST /api/admin/rooms":          {PermCampusManage},
+	"PUT /api/admin/rooms/:id":       {PermCampusManage},
+	"DELETE /api/admin/rooms/:id":    {PermCampusManage},
 
 	// 科室管理
-	"GET /api/admin/departments":        {PermDepartmentView},
-	"GET /api/admin/departments/:id":    {PermDepartmentView},
-	"POST /api/admin/departments":       {PermDepartmentCreate},
-	"PUT /api/admin/departments/:id":    {PermDepartmentUpdate},
-	"DELETE /api/admin/departments/:id": {PermDepartmentDelete},
+	"GET /api/admin/departments":          {PermDepartmentView},
+	"GET /api/admin/departments/:id":      {PermDepartmentView},
+	"GET /api/admin/departments/tree":     {PermDepartmentView},
+	"POST /api/admin/departments":         {PermDepartmentCreate},
+	"PUT /api/admin/departments/:id":      {PermDepartmentUpdate},
+	"PUT /api/admin/departments/:id/move": {PermDepartmentUpdate},
+	"DELETE /api/admin/departments/:id":   {PermDepartmentDelete},
 
 	// 医生管理
-	"GET /api/admin/doctors":        {PermDoctorView},
-	"GET /api/admin/doctors/:id":    {PermDoctorView},
-	"POST /api/admin/doctors":       {PermDoctorCreate},
-	"PUT /api/admin/doctors/:id":    {PermDoctorUpdate},
-	"DELETE /api/admin/doctors/:id": {PermDoctorDelete},
+	"GET /api/admin/doctors":                         {PermDoctorView},
+	"GET /api/admin/doctors/:id":                     {PermDoctorView},
+	"POST /api/admin/doctors":                        {PermDoctorCreate},
+	"PUT /api/admin/doctors/:id":                     {PermDoctorUpdate},
+	"DELETE /api/admin/doctors/:id":                  {PermDoctorDelete},
+	"PUT /api/admin/doctors/:id/profile":             {PermDoctorUpdate},
+	"GET /api/admin/doctors/expiring-qualifications": {PermDoctorView},
+
+	// 擅长领域标签
+	"GET /api/admin/specialty-tags":        {PermDoctorView},
+	"POST /api/admin/specialty-tags":       {PermDoctorUpdate},
+	"PUT /api/admin/specialty-tags/:id":    {PermDoctorUpdate},
+	"DELETE /api/admin/specialty-tags/:id": {PermDoctorUpdate},
+
+	// 医生账号管理
+	"GET /api/admin/doctor-accounts":              {PermDoctorAccountView},
+	"POST /api/admin/doctor-accounts":             {PermDoctorAccountManage},
+	"PUT /api/admin/doctor-accounts/:id":          {PermDoctorAccountManage},
+	"PUT /api/admin/doctor-accounts/:id/password": {PermDoctorAccountManage},
+
+	// 停诊管理
+	"GET /api/admin/doctor-leaves":            {PermDoctorLeaveView},
+	"GET /api/admin/doctor-leaves/:id":        {PermDoctorLeaveView},
+	"PUT /api/admin/doctor-leaves/:id/review": {PermDoctorLeaveReview},
+
+	// 评价管理
+	"GET /api/admin/reviews":          {PermReviewView},
+	"GET /api/admin/reviews/:id":      {PermReviewView},
+	"PUT /api/admin/reviews/:id/hide": {PermReviewModerate},
+	"PUT /api/admin/reviews/:id/show": {PermReviewModerate},
+
+	// 搜索管理
+	"POST /api/admin/search/rebuild":        {PermSearchManage},
+	"GET /api/admin/search/synonyms":        {PermSearchView},
+	"POST /api/admin/search/synonyms":       {PermSearchManage},
+	"PUT /api/admin/search/synonyms/:id":    {PermSearchManage},
+	"DELETE /api/admin/search/synonyms/:id": {PermSearchManage},
+
+	// 导诊规则
+	"GET /api/admin/triage-rules":        {PermTriageView},
+	"GET /api/admin/triage-rules/:id":    {PermTriageView},
+	"POST /api/admin/triage-rules":       {PermTriageManage},
+	"PUT /api/admin/triage-rules/:id":    {PermTriageManage},
+	"DELETE /api/admin/triage-rules/:id": {PermTriageManage},
+	"POST /api/admin/triage-rules/test":  {PermTriageView},
 
 	// 文件上传
 	"POST /api/admin/upload/avatar": {PermUploadAvatar},
 	"POST /api/admin/upload/image":  {PermUploadImage},
 
 	// 排班管理
-	"GET /api/admin/schedules":        {PermScheduleView},
-	"GET /api/admin/schedules/:id":    {PermScheduleView},
-	"POST /api/admin/schedules":       {PermScheduleCreate},
-	"POST /api/admin/schedules/batch": {PermScheduleBatch},
-	"PUT /api/admin/schedules/:id":    {PermScheduleUpdate},
-	"DELETE /api/admin/schedules/:id": {PermScheduleDelete},
+	"GET /api/admin/schedules":                 {PermScheduleView},
+	"GET /api/admin/schedules/:id":             {PermScheduleView},
+	"POST /api/admin/schedules":                {PermScheduleCreate},
+	"POST /api/admin/schedules/batch":          {PermScheduleBatch},
+	"POST /api/admin/schedules/import":         {PermScheduleImport},
+	"POST /api/admin/schedules/copy":           {PermScheduleCopy},
+	"GET /api/admin/schedules/import/template": {PermScheduleImport},
+	"PUT /api/admin/schedules/:id":             {PermScheduleUpdate},
+	"DELETE /api/admin/schedules/:id":          {PermScheduleDelete},
+
+	// 放号规则
+	"GET /api/admin/release-rules":        {PermReleaseRuleView},
+	"POST /api/admin/release-rules":       {PermReleaseRuleManage},
+	"PUT /api/admin/release-rules/:id":    {PermReleaseRuleManage},
+	"DELETE /api/admin/release-rules/:id": {PermReleaseRuleManage},
+
+	// 换班管理
+	"GET /api/admin/schedule-swaps":             {PermScheduleSwapView},
+	"GET /api/admin/schedule-swaps/:id":         {PermScheduleSwapView},
+	"POST /api/admin/schedule-swaps":            {PermScheduleSwapManage},
+	"PUT /api/admin/schedule-swaps/:id/respond": {PermScheduleSwapManage},
+	"PUT /api/admin/schedule-swaps/:id/review":  {PermScheduleSwapApprove},
+	"PUT /api/admin/schedule-swaps/:id/cancel":  {PermScheduleSwapManage},
 
 	// 系统日志
 	"GET /api/admin/logs/operation": {PermLogView},
 	"GET /api/admin/logs/login":     {PermLogView},
 }
+
+// CampusScopedRoutes 支持按院区限定数据范围的管理后台接口：key 同 AdminRoutePermissions
+// 仅凭院区角色获得权限的管理员只能访问此处列出的接口，其余接口一律拒绝；
+// 新增接口需在 handler 中按 GetAdminCampusScope 过滤或校验数据归属后再加入此表
+var CampusScopedRoutes = map[string]struct{}{
+	// 院区/诊室管理
+	"GET /api/admin/campuses":        {},
+	"GET /api/admin/campuses/:id":    {},
+	"POST /api/admin/campuses":       {},
+	"PUT /api/admin/campuses/:id":    {},
+	"DELETE /api/admin/campuses/:id": {},
+	"GET /api/admin/rooms":           {},
+	"GET /api/admin/rooms/:id":       {},
+	"POST /api/admin/rooms":          {},
+	"PUT /api/admin/rooms/:id":       {},
+	"DELETE /api/admin/rooms/:id":    {},
+
+	// 科室管理
+	"GET /api/admin/departments":          {},
+	"GET /api/admin/departments/:id":      {},
+	"GET /api/admin/departments/tree":     {},
+	"POST /api/admin/departments":         {},
+	"PUT /api/admin/departments/:id":      {},
+	"PUT /api/admin/departments/:id/move": {},
+	"DELETE /api/admin/departments/:id":   {},
+
+	// 排班管理
+	"GET /api/admin/schedules":        {},
+	"GET /api/admin/schedules/:id":    {},
+	"POST /api/admin/schedules":       {},
+	"POST /api/admin/schedules/batch": {},
+	"PUT /api/admin/schedules/:id":    {},
+	"DELETE /api/admin/schedules/:id": {},
+
+	// 预约管理
+	"GET /api/admin/appointments":        {},
+	"POST /api/admin/appointments":       {},
+	"GET /api/admin/appointments/:id":    {},
+	"PUT /api/admin/appointments/:id":    {},
+	"GET /api/admin/appointments/export": {},
+}
+
+// IsCampusScopedRoute 接口是否支持按院区限定数据范围
+func IsCampusScopedRoute(key string) bool {
+	_, ok := CampusScopedRoutes[key]
+	return ok
+}
@@ -0,0 +1,456 @@
+package repository
+
+import (
+	"encoding/json"
+	"time"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// AccountRepository 个人数据导出与账号注销数据访问层
+type AccountRepository struct {
+	db *gorm.DB
+}
+
+// NewAccountRepository 创建账号仓库实例
+func NewAccountRepository() *AccountRepository {
+	return &AccountRepository{db: database.GetDB()}
+}
+
+// CreateExport 创建数据导出任务
+func (r *AccountRepository) CreateExport(export *model.DataExport) error {
+	return r.db.Create(export).Error
+}
+
+// GetExportByID 根据ID查询数据导出任务
+func (r *AccountRepository) GetExportByID(id int64) (*model.DataExport, error) {
+	var export model.DataExport
+	if err := r.db.First(&export, id).Error; err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// GetExportByUserAndID 查询用户本人的数据导出任务
+func (r *AccountRepository) GetExportByUserAndID(userID, id int64) (*model.DataExport, error) {
+	var export model.DataExport
+	if err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&export).Error; err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// ListExportsByUser 查询用户的数据导出任务（最近的在前）
+func (r *AccountRepository) ListExportsByUser(userID int64, limit int) ([]model.DataExport, error) {
+	var list []model.DataExport
+	err := r.db.Where("user_id = ?", userID).Order("id DESC").Limit(limit).Find(&list).Error
+	return list, err
+}
+
+// HasActiveExport 用户是否有排队中或生成中的导出任务
+func (r *AccountRepository) HasActiveExport(userID int64) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.DataExport{}).
+		Where("user_id = ? AND status IN ?", userID, []string{model.DataExportStatusPending, model.DataExportStatusProcessing}).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// UpdateExport 更新数据导出任务
+func (r *AccountRepository) UpdateExport(id int64, updates map[string]interface{}) error {
+	return r.db.Model(&model.DataExport{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// ListExportFilePaths 查询用户尚未清理的导出文件路径
+func (r *AccountRepository) ListExportFilePaths(userID int64) ([]string, error) {
+	var paths []string
+	err := r.db.Model(&model.DataExport{}).Where("user_id = ? AND file_path <> ''", userID).Pluck("file_path", &paths).Error
+	return paths, err
+}
+
+// ListExpiredExports 查询下载有效期已过的导出任务
+func (r *AccountRepository) ListExpiredExports(now time.Time) ([]model.DataExport, error) {
+	var list []model.DataExport
+	err := r.db.Where("status = ? AND expires_at <= ?", model.DataExportStatusCompleted, now).Find(&list).Error
+	return list, err
+}
+
+// FailStaleExports 将长时间未完成的导出任务标记为失败（服务重启等原因中断），返回处理数量
+func (r *AccountRepository) FailStaleExports(before time.Time) (int64, error) {
+	result := r.db.Model(&model.DataExport{}).
+		Where("status IN ? AND created_at < ?", []string{model.DataExportStatusPending, model.DataExportStatusProcessing}, before).
+		Updates(map[string]interface{}{
+			"status":    model.DataExportStatusFailed,
+			"error_msg": "生成超时，请重新申请",
+		})
+	return result.RowsAffected, result.Error
+}
+
+// ListOwnedPatients 查询用户本人创建的就诊人（不含共享给其的就诊人）
+func (r *AccountRepository) ListOwnedPatients(userID int64) ([]model.Patient, error) {
+	var list []model.Patient
+	err := r.db.Where("user_id = ?", userID).Order("id ASC").Find(&list).Error
+	return list, err
+}
+
+// ListAppointmentsForExport 查询用户预约的或其就诊人的全部预约
+func (r *AccountRepository) ListAppointmentsForExport(userID int64, patientIDs []int64) ([]model.Appointment, error) {
+	var list []model.Appointment
+	query := r.db.Preload("Patient", unscopedPatient).
+		Preload("Doctor").
+		Preload("Department").
+		Preload("Campus")
+	if len(patientIDs) > 0 {
+		query = query.Where("user_id = ? OR patient_id IN ?", userID, patientIDs)
+	} else {
+		query = query.Where("user_id = ?", userID)
+	}
+	err := query.Order("appointment_date DESC, id DESC").Find(&list).Error
+	return list, err
+}
+
+// ListSignedRecordsByPatients 查询就诊人已签署的就诊记录（草稿不属于正式病历，不导出）
+func (r *AccountRepository) ListSignedRecordsByPatients(patientIDs []int64) ([]model.MedicalRecord, error) {
+	var list []model.MedicalRecord
+	if len(patientIDs) == 0 {
+		return list, nil
+	}
+	err := r.db.Preload("Patient", unscopedPatient).
+		Preload("Doctor").
+		Preload("Department").
+		Preload("Amendments", func(db *gorm.DB) *gorm.DB {
+			return db.Order("version ASC")
+		}).
+		Where("patient_id IN ? AND status = ?", patientIDs, model.MedicalRecordStatusSigned).
+		Order("visit_date DESC, id DESC").
+		Find(&list).Error
+	return list, err
+}
+
+// CreateDeletion 创建注销申请
+func (r *AccountRepository) CreateDeletion(deletion *model.AccountDeletion) error {
+	return r.db.Create(deletion).Error
+}
+
+// GetPendingDeletion 查询用户冷静期中的注销申请
+func (r *AccountRepository) GetPendingDeletion(userID int64) (*model.AccountDeletion, error) {
+	var deletion model.AccountDeletion
+	err := r.db.Where("user_id = ? AND status = ?", userID, model.AccountDeletionStatusPending).
+		Order("id DESC").First(&deletion).Error
+	if err != nil {
+		return nil, err
+	}
+	return &deletion, nil
+}
+
+// UpdateDeletion 更新注销申请
+func (r *AccountRepository) UpdateDeletion(id int64, updates map[string]interface{}) error {
+	return r.db.Model(&model.AccountDeletion{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// ListDueDeletions 查询冷静期已结束的注销申请
+func (r *AccountRepository) ListDueDeletions(now time.Time) ([]model.AccountDeletion, error) {
+	var list []model.AccountDeletion
+	err := r.db.Where("status = ? AND scheduled_at <= ?", model.AccountDeletionStatusPending, now).
+		Order("id ASC").Find(&list).Error
+	return list, err
+}
+
+// CountOpenAppointments 统计用户预约的或其就诊人的待就诊/已签到预约
+func (r *AccountRepository) CountOpenAppointments(userID int64) (int64, error) {
+	var count int64
+	owned := r.db.Model(&model.Patient{}).Select("id").Where("user_id = ?", userID)
+	err := r.db.Model(&model.Appointment{}).
+		Where("user_id = ? OR patient_id IN (?)", userID, owned).
+		Where("status IN ?", []string{model.AppointmentStatusPending, model.AppointmentStatusCheckedIn}).
+		Count(&count).Error
+	return count, err
+}
+
+// AnonymizeAccount 在同一事务内注销账号：
+// 匿名化用户及其就诊人（含已删除的）的身份信息并删除，解除就诊人共享关系，
+// 清理站内消息、登录日志中的个人信息及导出记录；预约、病历、评价等诊疗与费用记录保留，
+// 其关联的就诊人与用户已无法识别到具体个人
+func (r *AccountRepository) AnonymizeAccount(deletion *model.AccountDeletion, phone string, now time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		userID := deletion.UserID
+
+		var patientIDs []int64
+		if err := tx.Unscoped().Model(&model.Patient{}).Where("user_id = ?", userID).Pluck("id", &patientIDs).Error; err != nil {
+			return err
+		}
+
+		// 1. 就诊人：清除姓名、证件、手机号、出生日期及实名核验信息后删除
+		if len(patientIDs) > 0 {
+			if err := tx.Unscoped().Model(&model.Patient{}).Where("id IN ?", patientIDs).Updates(map[string]interface{}{
+				"name":          model.AnonymizedPatientName,
+				"id_card":       "",
+				"phone":         "",
+				"birth_date":    nil,
+				"is_default":    0,
+				"verify_status": model.PatientVerifyUnverified,
+				"verified_at":   nil,
+				"verify_remark": "",
+			}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("id IN ?", patientIDs).Delete(&model.Patient{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("patient_id IN ?", patientIDs).Delete(&model.PatientMember{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&model.PatientInvitation{}).
+				Where("patient_id IN ? AND status = ?", patientIDs, model.PatientInvitationPending).
+				Update("status", model.PatientInvitationRevoked).Error; err != nil {
+				return err
+			}
+		}
+
+		// 2. 退出他人共享给本人的就诊人，作废发给本人手机号的邀请
+		if err := tx.Where("user_id = ?", userID).Delete(&model.PatientMember{}).Error; err != nil {
+			return err
+		}
+		if phone != "" {
+			if err := tx.Model(&model.PatientInvitation{}).
+				Where("invitee_phone = ? AND status = ?", phone, model.PatientInvitationPending).
+				Updates(map[string]interface{}{
+					"status":        model.PatientInvitationRevoked,
+					"invitee_phone": "",
+				}).Error; err != nil {
+				return err
+			}
+		}
+
+		// 3. 站内消息、导出记录删除；登录日志保留审计所需的时间与结果
+		if err := tx.Where("user_id = ?", userID).Delete(&model.Notification{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&model.DataExport{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.LoginLog{}).
+			Where("user_type = ? AND user_id = ?", model.UserTypeUser, userID).
+			Updates(map[string]interface{}{
+				"username": "",
+				"ip":       "",
+				"location": "",
+				"device":   "",
+				"os":       "",
+				"browser":  "",
+			}).Error; err != nil {
+			return err
+		}
+
+		// 4. 用户：唯一登录标识置空（释放手机号、微信、用户名供重新注册），禁用并删除
+		if err := tx.Model(&model.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"open_id":           nil,
+			"union_id":          "",
+			"username":          nil,
+			"password":          "",
+			"phone":             nil,
+			"nickname":          model.AnonymizedUserNickname,
+			"avatar":            "",
+			"gender":            model.GenderUnknown,
+			"status":            model.StatusDisabled,
+			"last_login_ip":     "",
+			"tokens_revoked_at": now,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&model.User{}, userID).Error; err != nil {
+			return err
+		}
+
+		// 5. 申请完成
+		return tx.Model(&model.AccountDeletion{}).Where("id = ?", deletion.ID).Updates(map[string]interface{}{
+			"status":       model.AccountDeletionStatusCompleted,
+			"completed_at": now,
+			"request_ip":   "",
+			"remark":       "个人信息已匿名化，诊疗及费用记录按规定保留",
+		}).Error
+	})
+}
+
+// CreateMerge 创建账号合并凭证
+func (r *AccountRepository) CreateMerge(merge *model.AccountMerge) error {
+	return r.db.Create(merge).Error
+}
+
+// GetMergeByTicket 根据凭证查询账号合并记录
+func (r *AccountRepository) GetMergeByTicket(ticket string) (*model.AccountMerge, error) {
+	var merge model.AccountMerge
+	if err := r.db.Where("ticket = ?", ticket).First(&merge).Error; err != nil {
+		return nil, err
+	}
+	return &merge, nil
+}
+
+// CountUserData 统计账号下的就诊人及预约数量（用于合并前提示）
+func (r *AccountRepository) CountUserData(userID int64) (int64, int64, error) {
+	var patients, appointments int64
+	if err := r.db.Model(&model.Patient{}).Where("user_id = ?", userID).Count(&patients).Error; err != nil {
+		return 0, 0, err
+	}
+	if err := r.db.Model(&model.Appointment{}).Where("user_id = ?", userID).Count(&appointments).Error; err != nil {
+		return 0, 0, err
+	}
+	return patients, appointments, nil
+}
+
+// MergeAccounts 在同一事务内将被合并账号的数据并入保留账号：
+// 先释放被合并账号的登录标识并停用删除，再为保留账号写入 targetUpdates（绑定的登录方式等），
+// 随后迁移就诊人、共享关系、预约、评价、站内消息及导出记录，并完成合并记录
+func (r *AccountRepository) MergeAccounts(merge *model.AccountMerge, targetUpdates map[string]interface{}, now time.Time) (*model.AccountMergeSummary, error) {
+	summary := &model.AccountMergeSummary{}
+	targetID, sourceID := merge.TargetUserID, merge.SourceUserID
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// 0. 占用合并凭证，防止同一凭证并发重复合并
+		claimed := tx.Model(&model.AccountMerge{}).
+			Where("id = ? AND status = ?", merge.ID, model.AccountMergeStatusPending).
+			Update("status", model.AccountMergeStatusCompleted)
+		if claimed.Error != nil {
+			return claimed.Error
+		}
+		if claimed.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		// 1. 被合并账号：释放唯一登录标识，停用并删除
+		if err := tx.Model(&model.User{}).Where("id = ?", sourceID).Updates(map[string]interface{}{
+			"open_id":           nil,
+			"union_id":          "",
+			"username":          nil,
+			"password":          "",
+			"phone":             nil,
+			"status":            model.StatusDisabled,
+			"tokens_revoked_at": now,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&model.User{}, sourceID).Error; err != nil {
+			return err
+		}
+
+		// 2. 保留账号：绑定登录方式、继承爽约记录等
+		if len(targetUpdates) > 0 {
+			if err := tx.Model(&model.User{}).Where("id = ?", targetID).Updates(targetUpdates).Error; err != nil {
+				return err
+			}
+		}
+
+		// 3. 就诊人归属；保留账号已有默认就诊人时，迁入的就诊人取消默认
+		var sourcePatientIDs []int64
+		if err := tx.Unscoped().Model(&model.Patient{}).Where("user_id = ?", sourceID).Pluck("id", &sourcePatientIDs).Error; err != nil {
+			return err
+		}
+		if len(sourcePatientIDs) > 0 {
+			var defaults int64
+			if err := tx.Model(&model.Patient{}).Where("user_id = ? AND is_default = 1", targetID).Count(&defaults).Error; err != nil {
+				return err
+			}
+			updates := map[string]interface{}{"user_id": targetID}
+			if defaults > 0 {
+				updates["is_default"] = 0
+			}
+			result := tx.Unscoped().Model(&model.Patient{}).Where("id IN ?", sourcePatientIDs).Updates(updates)
+			if result.Error != nil {
+				return result.Error
+			}
+			summary.Patients = result.RowsAffected
+
+			// 每个账号仅一位本人就诊人：保留账号已有本人时，迁入的本人就诊人改为其他关系
+			var selfCount int64
+			if err := tx.Model(&model.Patient{}).
+				Where("user_id = ? AND relation = ? AND id NOT IN ?", targetID, model.RelationSelf, sourcePatientIDs).
+				Count(&selfCount).Error; err != nil {
+				return err
+			}
+			if selfCount > 0 {
+				if err := tx.Unscoped().Model(&model.Patient{}).
+					Where("id IN ? AND relation = ?", sourcePatientIDs, model.RelationSelf).
+					Update("relation", model.RelationOther).Error; err != nil {
+					return err
+				}
+			}
+
+			// 保留账号原为这些就诊人的共享成员，合并后已是所有者
+			if err := tx.Where("user_id = ? AND patient_id IN ?", targetID, sourcePatientIDs).Delete(&model.PatientMember{}).Error; err != nil {
+				return err
+			}
+		}
+
+		// 4. 共享成员关系：保留账号已能访问的就诊人不重复加入
+		var accessible []int64
+		if err := tx.Model(&model.PatientMember{}).Where("user_id = ?", targetID).Pluck("patient_id", &accessible).Error; err != nil {
+			return err
+		}
+		var owned []int64
+		if err := tx.Unscoped().Model(&model.Patient{}).Where("user_id = ?", targetID).Pluck("id", &owned).Error; err != nil {
+			return err
+		}
+		accessible = append(accessible, owned...)
+		if len(accessible) > 0 {
+			if err := tx.Where("user_id = ? AND patient_id IN ?", sourceID, accessible).Delete(&model.PatientMember{}).Error; err != nil {
+				return err
+			}
+		}
+		result := tx.Model(&model.PatientMember{}).Where("user_id = ?", sourceID).Update("user_id", targetID)
+		if result.Error != nil {
+			return result.Error
+		}
+		summary.Memberships = result.RowsAffected
+		if err := tx.Model(&model.PatientInvitation{}).Where("inviter_id = ?", sourceID).Update("inviter_id", targetID).Error; err != nil {
+			return err
+		}
+
+		// 5. 预约、评价、站内消息、导出记录
+		moves := []struct {
+			model interface{}
+			count *int64
+		}{
+			{&model.Appointment{}, &summary.Appointments},
+			{&model.DoctorReview{}, &summary.Reviews},
+			{&model.Notification{}, &summary.Notifications},
+			{&model.DataExport{}, nil},
+		}
+		for _, m := range moves {
+			result := tx.Model(m.model).Where("user_id = ?", sourceID).Update("user_id", targetID)
+			if result.Error != nil {
+				return result.Error
+			}
+			if m.count != nil {
+				*m.count = result.RowsAffected
+			}
+		}
+
+		// 6. 被合并账号的注销申请随之撤销
+		if err := tx.Model(&model.AccountDeletion{}).
+			Where("user_id = ? AND status = ?", sourceID, model.AccountDeletionStatusPending).
+			Updates(map[string]interface{}{
+				"status":       model.AccountDeletionStatusCancelled,
+				"cancelled_at": now,
+				"remark":       "账号已合并",
+			}).Error; err != nil {
+			return err
+		}
+
+		// 7. 记录合并结果
+		data, err := json.Marshal(summary)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&model.AccountMerge{}).Where("id = ?", merge.ID).Updates(map[string]interface{}{
+			"completed_at": now,
+			"summary":      string(data),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
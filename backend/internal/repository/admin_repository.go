@@ -36,17 +36,17 @@ func (r *AdminRepository) GetByUsername(username string) (*model.Admin, error) {
 // GetByID 根据ID查询管理员
 func (r *AdminRepository) GetByID(id int64) (*model.Admin, error) {
 	var admin model.Admin
-	err := r.db.Preload("Roles").First(&admin, id).Error
+	err := r.db.Preload("Roles").Preload("CampusRoles.Campus").Preload("CampusRoles.Role").First(&admin, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &admin, nil
 }
 
-// GetByIDWithRolesPermissions 根据ID查询管理员（预加载角色与权限）
+// GetByIDWithRolesPermissions 根据ID查询管理员（预加载角色与权限，含院区角色）
 func (r *AdminRepository) GetByIDWithRolesPermissions(id int64) (*model.Admin, error) {
 	var admin model.Admin
-	err := r.db.Preload("Roles.Permissions").First(&admin, id).Error
+	err := r.db.Preload("Roles.Permissions").Preload("CampusRoles.Role.Permissions").First(&admin, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -97,7 +97,7 @@ func (r *AdminRepository) List(page, pageSize int, keyword string, status *int)
 	}
 
 	offset := (page - 1) * pageSize
-	if err := query.Preload("Roles").Order("created_at DESC").
+	if err := query.Preload("Roles").Preload("CampusRoles.Campus").Preload("CampusRoles.Role").Order("created_at DESC").
 		Offset(offset).Limit(pageSize).
 		Find(&list).Error; err != nil {
 		return nil, 0, err
@@ -119,3 +119,17 @@ func (r *AdminRepository) ReplaceRoles(adminID int64, roles []model.Role) error
 	}
 	return r.db.Model(&admin).Association("Roles").Replace(&roles)
 }
+
+// ReplaceCampusRoles 替换管理员院区角色（items 为空则清空）
+func (r *AdminRepository) ReplaceCampusRoles(adminID int64, items []model.AdminCampusRole) error {
+	if err := r.db.Where("admin_id = ?", adminID).Delete(&model.AdminCampusRole{}).Error; err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	for i := range items {
+		items[i].AdminID = adminID
+	}
+	return r.db.Omit("Campus", "Role").Create(&items).Error
+}
@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// AgreementRepository 协议版本及同意记录数据访问层
+type AgreementRepository struct {
+	db *gorm.DB
+}
+
+// NewAgreementRepository 创建协议仓库实例
+func NewAgreementRepository() *AgreementRepository {
+	return &AgreementRepository{db: database.GetDB()}
+}
+
+// Create 创建协议版本
+func (r *AgreementRepository) Create(agreement *model.Agreement) error {
+	return r.db.Create(agreement).Error
+}
+
+// UpdateDraft 更新草稿（已发布的版本不可修改），返回 false 表示版本已发布
+func (r *AgreementRepository) UpdateDraft(id int64, updates map[string]interface{}) (bool, error) {
+	result := r.db.Model(&model.Agreement{}).
+		Where("id = ? AND status = ?", id, model.AgreementStatusDraft).
+		Updates(updates)
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteDraft 删除草稿，返回 false 表示版本已发布
+func (r *AgreementRepository) DeleteDraft(id int64) (bool, error) {
+	result := r.db.Where("id = ? AND status = ?", id, model.AgreementStatusDraft).Delete(&model.Agreement{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Publish 发布草稿，返回 false 表示版本已发布
+func (r *AgreementRepository) Publish(id, adminID int64, now time.Time) (bool, error) {
+	result := r.db.Model(&model.Agreement{}).
+		Where("id = ? AND status = ?", id, model.AgreementStatusDraft).
+		Updates(map[string]interface{}{
+			"status":       model.AgreementStatusPublished,
+			"published_at": now,
+			"published_by": adminID,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetByID 根据ID查询协议版本
+func (r *AgreementRepository) GetByID(id int64) (*model.Agreement, error) {
+	var agreement model.Agreement
+	if err := r.db.First(&agreement, id).Error; err != nil {
+		return nil, err
+	}
+	return &agreement, nil
+}
+
+// ExistsVersion 检查同类型下版本号是否已存在
+func (r *AgreementRepository) ExistsVersion(agreementType, version string, excludeID int64) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Agreement{}).
+		Where("type = ? AND version = ? AND id <> ?", agreementType, version, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// List 分页查询协议版本（不含正文）
+func (r *AgreementRepository) List(page, pageSize int, agreementType, status string) ([]model.Agreement, int64, error) {
+	var list []model.Agreement
+	var total int64
+
+	query := r.db.Model(&model.Agreement{})
+	if agreementType != "" {
+		query = query.Where("type = ?", agreementType)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Omit("content").
+		Order("type ASC, id DESC").
+		Offset(offset).Limit(pageSize).
+		Find(&list).Error
+	return list, total, err
+}
+
+// GetCurrent 查询协议类型的现行版本（最近发布的版本）
+func (r *AgreementRepository) GetCurrent(agreementType string) (*model.Agreement, error) {
+	var agreement model.Agreement
+	err := r.db.Where("type = ? AND status = ?", agreementType, model.AgreementStatusPublished).
+		Order("published_at DESC, id DESC").
+		First(&agreement).Error
+	if err != nil {
+		return nil, err
+	}
+	return &agreement, nil
+}
+
+// ListCurrent 查询多个协议类型的现行版本（不含正文，未发布过的类型不返回）
+func (r *AgreementRepository) ListCurrent(types []string) ([]model.Agreement, error) {
+	list := make([]model.Agreement, 0, len(types))
+	for _, t := range types {
+		var agreement model.Agreement
+		err := r.db.Omit("content").
+			Where("type = ? AND status = ?", t, model.AgreementStatusPublished).
+			Order("published_at DESC, id DESC").
+			First(&agreement).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		list = append(list, agreement)
+	}
+	return list, nil
+}
+
+// CreateConsent 追加同意记录
+func (r *AgreementRepository) CreateConsent(consent *model.AgreementConsent) error {
+	return r.db.Create(consent).Error
+}
+
+// LatestConsents 查询用户（及就诊人）各协议类型最近一条同意记录
+func (r *AgreementRepository) LatestConsents(userID, patientID int64, types []string) (map[string]*model.AgreementConsent, error) {
+	latest := make(map[string]*model.AgreementConsent, len(types))
+	for _, t := range types {
+		var consent model.AgreementConsent
+		err := r.db.Where("user_id = ? AND patient_id = ? AND type = ?", userID, patientID, t).
+			Order("id DESC").
+			First(&consent).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		latest[t] = &consent
+	}
+	return latest, nil
+}
+
+// ListConsentsByUser 查询用户的同意记录（含按就诊人同意的记录）
+func (r *AgreementRepository) ListConsentsByUser(userID int64, limit int) ([]model.AgreementConsent, error) {
+	var list []model.AgreementConsent
+	err := r.db.Preload("Patient", unscopedPatient).
+		Where("user_id = ?", userID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&list).Error
+	return list, err
+}
+
+// ListConsents 分页查询同意记录（管理后台审计）
+func (r *AgreementRepository) ListConsents(page, pageSize int, agreementType, version string, userID, patientID *int64, startDate, endDate *time.Time) ([]model.AgreementConsent, int64, error) {
+	var list []model.AgreementConsent
+	var total int64
+
+	query := r.consentQuery(agreementType, version, userID, patientID, startDate, endDate)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Preload("User", unscopedUser).
+		Preload("Patient", unscopedPatient).
+		Order("id DESC").
+		Offset(offset).Limit(pageSize).
+		Find(&list).Error
+	return list, total, err
+}
+
+// ListConsentsForExport 查询待导出的同意记录（按时间正序，最多 limit 条）
+func (r *AgreementRepository) ListConsentsForExport(agreementType, version string, userID, patientID *int64, startDate, endDate *time.Time, limit int) ([]model.AgreementConsent, error) {
+	var list []model.AgreementConsent
+	err := r.consentQuery(agreementType, version, userID, patientID, startDate, endDate).
+		Preload("User", unscopedUser).
+		Preload("Patient", unscopedPatient).
+		Order("id ASC").
+		Limit(limit).
+		Find(&list).Error
+	return list, err
+}
+
+// consentQuery 同意记录查询条件
+func (r *AgreementRepository) consentQuery(agreementType, version string, userID, patientID *int64, startDate, endDate *time.Time) *gorm.DB {
+	query := r.db.Model(&model.AgreementConsent{})
+	if agreementType != "" {
+		query = query.Where("type = ?", agreementType)
+	}
+	if version != "" {
+		query = query.Where("version = ?", version)
+	}
+	if userID != nil && *userID > 0 {
+		query = query.Where("user_id = ?", *userID)
+	}
+	if patientID != nil && *patientID > 0 {
+		query = query.Where("patient_id = ?", *patientID)
+	}
+	if startDate != nil {
+		query = query.Where("created_at >= ?", *startDate)
+	}
+	if endDate != nil {
+		query = query.Where("created_at <= ?", *endDate)
+	}
+	return query
+}
+
+// unscopedUser 预加载用户时包含已注销（软删除）的账号，审计记录需保留操作人信息
+func unscopedUser(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
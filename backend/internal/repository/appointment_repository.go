@@ -31,6 +31,8 @@ func (r *AppointmentRepository) GetByID(id int64) (*model.Appointment, error) {
 		Preload("Doctor").
 		Preload("Department").
 		Preload("Schedule").
+		Preload("Campus").
+		Preload("Room").
 		First(&appointment, id).Error
 	if err != nil {
 		return nil, err
@@ -38,14 +40,18 @@ func (r *AppointmentRepository) GetByID(id int64) (*model.Appointment, error) {
 	return &appointment, nil
 }
 
-// GetByUserAndID 根据用户ID和预约ID查询（用于权限校验）
+// GetByUserAndID 根据用户ID和预约ID查询（用于权限校验，仅可访问名下或共享给该用户的就诊人的预约）
 func (r *AppointmentRepository) GetByUserAndID(userID, appointmentID int64) (*model.Appointment, error) {
 	var appointment model.Appointment
+	cond, args := patientAccessCondition("patient_id", userID)
 	err := r.db.Preload("Patient").
 		Preload("Doctor").
 		Preload("Department").
 		Preload("Schedule").
-		Where("user_id = ? AND id = ?", userID, appointmentID).
+		Preload("Campus").
+		Preload("Room").
+		Where("id = ?", appointmentID).
+		Where(cond, args...).
 		First(&appointment).Error
 	if err != nil {
 		return nil, err
@@ -53,14 +59,16 @@ func (r *AppointmentRepository) GetByUserAndID(userID, appointmentID int64) (*mo
 	return &appointment, nil
 }
 
-// ListByUser 查询用户的预约列表
+// ListByUser 查询用户当前可访问就诊人的预约列表（含共享就诊人的预约）
 func (r *AppointmentRepository) ListByUser(userID int64, status *string) ([]model.Appointment, error) {
 	var appointments []model.Appointment
 
+	cond, args := patientAccessCondition("patient_id", userID)
 	query := r.db.Preload("Patient").
 		Preload("Doctor").
 		Preload("Department").
-		Where("user_id = ?", userID)
+		Preload("Campus").
+		Where(cond, args...)
 
 	if status != nil && *status != "" {
 		query = query.Where("status = ?", *status)
@@ -71,15 +79,44 @@ func (r *AppointmentRepository) ListByUser(userID int64, status *string) ([]mode
 	return appointments, err
 }
 
+// CountByUserGroupStatus 按状态统计用户本人预约数量
+func (r *AppointmentRepository) CountByUserGroupStatus(userID int64) (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	err := r.db.Model(&model.Appointment{}).
+		Select("status, COUNT(*) AS count").
+		Where("user_id = ?", userID).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		result[row.Status] = row.Count
+	}
+	return result, nil
+}
+
 // List 分页查询预约列表（管理后台）
-func (r *AppointmentRepository) List(page, pageSize int, startDate, endDate *time.Time, status *string, keyword string) ([]model.Appointment, int64, error) {
+func (r *AppointmentRepository) List(page, pageSize int, campusID *int64, startDate, endDate *time.Time, status *string, keyword string) ([]model.Appointment, int64, error) {
 	var appointments []model.Appointment
 	var total int64
 
 	query := r.db.Model(&model.Appointment{}).
 		Preload("Patient").
 		Preload("Doctor").
-		Preload("Department")
+		Preload("Department").
+		Preload("Campus").
+		Preload("Room")
+
+	// 院区筛选
+	if campusID != nil && *campusID > 0 {
+		query = query.Where("campus_id = ?", *campusID)
+	}
 
 	// 日期范围筛选
 	if startDate != nil {
@@ -159,3 +196,64 @@ func (r *AppointmentRepository) GetNextSlotNumber(scheduleID int64) (int, error)
 		Scan(&maxSlotNumber).Error
 	return maxSlotNumber + 1, err
 }
+
+// ListPendingBySchedule 查询排班下待就诊的预约
+func (r *AppointmentRepository) ListPendingBySchedule(scheduleID int64) ([]model.Appointment, error) {
+	var appointments []model.Appointment
+	err := r.db.Preload("Patient").
+		Where("schedule_id = ? AND status = ?", scheduleID, model.AppointmentStatusPending).
+		Find(&appointments).Error
+	return appointments, err
+}
+
+// GetByDoctorAndID 根据医生ID和预约ID查询（医生工作台权限校验）
+func (r *AppointmentRepository) GetByDoctorAndID(doctorID, appointmentID int64) (*model.Appointment, error) {
+	var appointment model.Appointment
+	err := r.db.Preload("Patient").
+		Preload("Department").
+		Preload("Schedule").
+		Preload("Campus").
+		Preload("Room").
+		Where("doctor_id = ? AND id = ?", doctorID, appointmentID).
+		First(&appointment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &appointment, nil
+}
+
+// ListByDoctor 分页查询医生的预约列表（医生工作台）
+func (r *AppointmentRepository) ListByDoctor(doctorID int64, page, pageSize int, startDate, endDate *time.Time, scheduleID *int64, status *string) ([]model.Appointment, int64, error) {
+	var appointments []model.Appointment
+	var total int64
+
+	query := r.db.Model(&model.Appointment{}).Where("doctor_id = ?", doctorID)
+
+	if startDate != nil {
+		query = query.Where("appointment_date >= ?", *startDate)
+	}
+	if endDate != nil {
+		query = query.Where("appointment_date <= ?", *endDate)
+	}
+	if scheduleID != nil && *scheduleID > 0 {
+		query = query.Where("schedule_id = ?", *scheduleID)
+	}
+	if status != nil && *status != "" {
+		query = query.Where("status = ?", *status)
+	}
+
+	// 统计总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 分页查询（按就诊顺序）
+	offset := (page - 1) * pageSize
+	err := query.Preload("Patient").
+		Preload("Department").
+		Order("appointment_date ASC, period ASC, slot_number ASC").
+		Offset(offset).Limit(pageSize).
+		Find(&appointments).Error
+
+	return appointments, total, err
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"huaan-medical/internal/model"
+	"huaan-medical/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// CampusRepository 院区数据访问层
+type CampusRepository struct {
+	db *gorm.DB
+}
+
+// NewCampusRepository 创建院区仓库实例
+func NewCampusRepository() *CampusRepository {
+	return &CampusRepository{db: database.GetDB()}
+}
+
+// NewCampusRepositoryWithDB 使用指定DB（如事务）创建院区仓库实例
+func NewCampusRepositoryWithDB(db *gorm.DB) *CampusRepository {
+	return &CampusRepository{db: db}
+}
+
+// Create 创建院区
+func (r *CampusRepository) Create(campus *model.Campus) error {
+	return r.db.Create(campus).Error
+}
+
+// Update 更新院区
+func (r *CampusRepository) Update(campus *model.Campus) error {
+	return r.db.Save(campus).Error
+}
+
+// Delete 删除院区（软删除）
+func (r *CampusRepository) Delete(id int64) error {
+	return r.db.Delete(&model.Campus{}, id).Error
+}
+
+// GetByID 根据ID查询院区
+func (r *CampusRepository) GetByID(id int64) (*model.Campus, error) {
+	var campus model.Campus
+	if err := r.db.First(&campus, id).Error; err != nil {
+		return nil, err
+	}
+	return &campus, nil
+}
+
+// GetByName 根据名称查询院区
+func (r *CampusRepository) GetByName(name string) (*model.Campus, error) {
+	var campus model.Campus
+	if err := r.db.Where("name = ?", name).First(&campus).Error; err != nil {
+		return nil, err
+	}
+	return &campus, nil
+}
+
+// List 分页查询院区列表
+func (r *CampusRepository) List(page, pageSize int, keyword string, status *int) ([]model.Campus, int64, error) {
+	var list []model.Campus
+	var total int64
+
+	query := r.db.Model(&model.Campus{})
+	if keyword != "" {
+		query = query.Where("name LIKE ? OR address LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+	}
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Order("sort_order ASC, id ASC").
+		Offset(offset).Limit(pageSize).
+		Find(&list).Error
+	return list, total, err
+}
+
+// ListEnabled 查询全部启用的院区（公开接口用）
+func (r *CampusRepository) ListEnabled() ([]model.Campus, error) {
+	var list []model.Campus
+	err := r.db.Where("status = ?", model.StatusEnabled).
+		Order("sort_order ASC, id ASC").
+		Find(&list).Error
+	return list, err
+}
+
+// HasDepartments 检查院区下是否有科室
+func (r *CampusRepository) HasDepartments(id int64) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Department{}).Where("campus_id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
+// HasRooms 检查院区下是否有诊室
+func (r *CampusRepository) HasRooms(id int64) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Room{}).Where("campus_id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
+// CampusIDOf 查询指定表中记录所属的院区ID（用于院区权限校验），记录不存在时返回 0
+func (r *CampusRepository) CampusIDOf(table string, id int64) (int64, error) {
+	var ids []int64
+	err := r.db.Table(table).Where("id = ? AND deleted_at IS NULL", id).Pluck("campus_id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	return ids[0], nil
+}
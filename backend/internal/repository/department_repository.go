@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"huaan-medical/internal/model"
 	"huaan-medical/pkg/database"
 
@@ -24,7 +26,7 @@ func (r *DepartmentRepository) Create(dept *model.Department) error {
 
 // Update 更新科室
 func (r *DepartmentRepository) Update(dept *model.Department) error {
-	return r.db.Save(dept).Error
+	return r.db.Omit("Campus").Save(dept).Error
 }
 
 // Delete 删除科室（软删除）
@@ -35,7 +37,7 @@ func (r *DepartmentRepository) Delete(id int64) error {
 // GetByID 根据ID查询科室
 func (r *DepartmentRepository) GetByID(id int64) (*model.Department, error) {
 	var dept model.Department
-	err := r.db.First(&dept, id).Error
+	err := r.db.Preload("Campus").First(&dept, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -53,12 +55,15 @@ func (r *DepartmentRepository) GetByIDWithDoctors(id int64) (*model.Department,
 }
 
 // List 查询科室列表
-func (r *DepartmentRepository) List(page, pageSize int, status *int) ([]model.Department, int64, error) {
+func (r *DepartmentRepository) List(page, pageSize int, campusID *int64, status *int) ([]model.Department, int64, error) {
 	var departments []model.Department
 	var total int64
 
 	query := r.db.Model(&model.Department{})
 
+	if campusID != nil && *campusID > 0 {
+		query = query.Where("campus_id = ?", *campusID)
+	}
 	if status != nil {
 		query = query.Where("status = ?", *status)
 	}
@@ -70,30 +75,44 @@ func (r *DepartmentRepository) List(page, pageSize int, status *int) ([]model.De
 
 	// 分页查询
 	offset := (page - 1) * pageSize
-	err := query.Order("sort_order ASC, id ASC").
+	err := query.Preload("Campus").Order("sort_order ASC, id ASC").
 		Offset(offset).Limit(pageSize).
 		Find(&departments).Error
 
 	return departments, total, err
 }
 
-// ListAll 查询所有启用的科室（公开接口用）
+// ListAll 查询所有启用的科室（公开接口用），所属院区停用的科室不返回
 func (r *DepartmentRepository) ListAll() ([]model.Department, error) {
 	var departments []model.Department
-	err := r.db.Where("status = ?", model.StatusEnabled).
+	err := r.db.Preload("Campus").Where("status = ?", model.StatusEnabled).
+		Where("campus_id NOT IN (?)", r.db.Model(&model.Campus{}).Select("id").Where("status = ?", model.StatusDisabled)).
 		Order("sort_order ASC, id ASC").
 		Find(&departments).Error
 	return departments, err
 }
 
-// HasDoctors 检查科室下是否有医生
+// HasDoctors 检查科室下是否有医生（含在该科室出诊的非主科室医生）
 func (r *DepartmentRepository) HasDoctors(id int64) (bool, error) {
 	var count int64
-	err := r.db.Model(&model.Doctor{}).Where("department_id = ?", id).Count(&count).Error
+	err := r.db.Model(&model.Doctor{}).
+		Where("department_id = ? OR id IN (?)", id,
+			r.db.Model(&model.DoctorDepartment{}).Select("doctor_id").Where("department_id = ?", id)).
+		Count(&count).Error
 	return count > 0, err
 }
 
-// GetByName 根据名称查询科室
+// GetByCampusAndName 根据院区及名称查询科室（科室名称在院区内唯一）
+func (r *DepartmentRepository) GetByCampusAndName(campusID int64, name string) (*model.Department, error) {
+	var dept model.Department
+	err := r.db.Where("campus_id = ? AND name = ?", campusID, name).First(&dept).Error
+	if err != nil {
+		return nil, err
+	}
+	return &dept, nil
+}
+
+// GetByName 根据名称查询科室（多院区同名时返回排序靠前的一个）
 func (r *DepartmentRepository) GetByName(name string) (*model.Department, error) {
 	var dept model.Department
 	err := r.db.Where("name = ?", name).First(&dept).Error
@@ -102,3 +121,52 @@ func (r *DepartmentRepository) GetByName(name string) (*model.Department, error)
 	}
 	return &dept, nil
 }
+
+// ListAllIncludeDisabled 查询全部科室（含停用，用于构建科室树）
+func (r *DepartmentRepository) ListAllIncludeDisabled() ([]model.Department, error) {
+	var departments []model.Department
+	err := r.db.Preload("Campus").Order("sort_order ASC, id ASC").Find(&departments).Error
+	return departments, err
+}
+
+// ListByIDs 根据ID批量查询科室
+func (r *DepartmentRepository) ListByIDs(ids []int64) ([]model.Department, error) {
+	var departments []model.Department
+	if len(ids) == 0 {
+		return departments, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&departments).Error
+	return departments, err
+}
+
+// HasChildren 检查科室下是否有子科室
+func (r *DepartmentRepository) HasChildren(id int64) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Department{}).Where("parent_id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
+// Move 调整科室的上级科室及排序
+func (r *DepartmentRepository) Move(id, parentID int64, sortOrder int) error {
+	return r.db.Model(&model.Department{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"parent_id":  parentID,
+			"sort_order": sortOrder,
+		}).Error
+}
+
+// CountRooms 统计科室下的诊室数量
+func (r *DepartmentRepository) CountRooms(id int64) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Room{}).Where("department_id = ?", id).Count(&count).Error
+	return count, err
+}
+
+// HasFutureSchedules 检查科室是否有今日及以后的排班
+func (r *DepartmentRepository) HasFutureSchedules(id int64, today time.Time) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Schedule{}).
+		Where("department_id = ? AND schedule_date >= ?", id, today).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"strings"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// DoctorAccountRepository 医生账号数据访问层
+type DoctorAccountRepository struct {
+	db *gorm.DB
+}
+
+// NewDoctorAccountRepository 创建医生账号仓库实例
+func NewDoctorAccountRepository() *DoctorAccountRepository {
+	return &DoctorAccountRepository{db: database.GetDB()}
+}
+
+// Create 创建医生账号
+func (r *DoctorAccountRepository) Create(account *model.DoctorAccount) error {
+	return r.db.Create(account).Error
+}
+
+// GetByID 根据ID查询医生账号
+func (r *DoctorAccountRepository) GetByID(id int64) (*model.DoctorAccount, error) {
+	var account model.DoctorAccount
+	err := r.db.Preload("Doctor.Department").First(&account, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// GetByUsername 根据用户名查询医生账号
+func (r *DoctorAccountRepository) GetByUsername(username string) (*model.DoctorAccount, error) {
+	var account model.DoctorAccount
+	err := r.db.Preload("Doctor.Department").Where("username = ?", username).First(&account).Error
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// GetByDoctorID 根据医生ID查询医生账号
+func (r *DoctorAccountRepository) GetByDoctorID(doctorID int64) (*model.DoctorAccount, error) {
+	var account model.DoctorAccount
+	err := r.db.Preload("Doctor.Department").Where("doctor_id = ?", doctorID).First(&account).Error
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// List 分页查询医生账号列表
+func (r *DoctorAccountRepository) List(page, pageSize int, keyword string, status *int) ([]model.DoctorAccount, int64, error) {
+	var list []model.DoctorAccount
+	var total int64
+
+	query := r.db.Model(&model.DoctorAccount{})
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+	if strings.TrimSpace(keyword) != "" {
+		like := "%" + strings.TrimSpace(keyword) + "%"
+		query = query.Where(
+			r.db.Where("username LIKE ?", like).
+				Or("phone LIKE ?", like).
+				Or("EXISTS (SELECT 1 FROM doctors WHERE doctors.id = doctor_accounts.doctor_id AND doctors.name LIKE ?)", like),
+		)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Preload("Doctor.Department").Order("created_at DESC").
+		Offset(offset).Limit(pageSize).
+		Find(&list).Error
+
+	return list, total, err
+}
+
+// Update 更新医生账号信息
+func (r *DoctorAccountRepository) Update(id int64, updates map[string]interface{}) error {
+	return r.db.Model(&model.DoctorAccount{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// UpdatePassword 更新密码
+func (r *DoctorAccountRepository) UpdatePassword(id int64, password string) error {
+	return r.db.Model(&model.DoctorAccount{}).Where("id = ?", id).
+		Update("password", password).Error
+}
+
+// UpdateLoginInfo 更新登录信息
+func (r *DoctorAccountRepository) UpdateLoginInfo(id int64, ip string) error {
+	return r.db.Model(&model.DoctorAccount{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"last_login_at": gorm.Expr("NOW()"),
+			"last_login_ip": ip,
+		}).Error
+}
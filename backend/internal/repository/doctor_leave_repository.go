@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"time"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// DoctorLeaveRepository 停诊申请数据访问层
+type DoctorLeaveRepository struct {
+	db *gorm.DB
+}
+
+// NewDoctorLeaveRepository 创建停诊申请仓库实例
+func NewDoctorLeaveRepository() *DoctorLeaveRepository {
+	return &DoctorLeaveRepository{db: database.GetDB()}
+}
+
+// Create 创建停诊申请
+func (r *DoctorLeaveRepository) Create(leave *model.DoctorLeave) error {
+	return r.db.Create(leave).Error
+}
+
+// GetByID 根据ID查询停诊申请
+func (r *DoctorLeaveRepository) GetByID(id int64) (*model.DoctorLeave, error) {
+	var leave model.DoctorLeave
+	err := r.db.Preload("Doctor").First(&leave, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &leave, nil
+}
+
+// List 分页查询停诊申请
+func (r *DoctorLeaveRepository) List(page, pageSize int, status string, doctorID, departmentID *int64) ([]model.DoctorLeave, int64, error) {
+	var leaves []model.DoctorLeave
+	var total int64
+
+	query := r.db.Model(&model.DoctorLeave{})
+
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if doctorID != nil && *doctorID > 0 {
+		query = query.Where("doctor_id = ?", *doctorID)
+	}
+	if departmentID != nil && *departmentID > 0 {
+		query = query.Where("doctor_id IN (?)",
+			r.db.Model(&model.DoctorDepartment{}).Select("doctor_id").Where("department_id = ?", *departmentID))
+	}
+
+	// 统计总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 分页查询
+	offset := (page - 1) * pageSize
+	err := query.Preload("Doctor").
+		Order("id DESC").
+		Offset(offset).Limit(pageSize).
+		Find(&leaves).Error
+
+	return leaves, total, err
+}
+
+// ExistsOverlap 检查医生是否存在时间重叠的待审批/已批准申请
+// 时段为全天的申请与任意时段重叠
+func (r *DoctorLeaveRepository) ExistsOverlap(doctorID int64, startDate, endDate time.Time, period string) (bool, error) {
+	query := r.db.Model(&model.DoctorLeave{}).
+		Where("doctor_id = ? AND status IN ?", doctorID,
+			[]string{model.DoctorLeaveStatusPending, model.DoctorLeaveStatusApproved}).
+		Where("start_date <= ? AND end_date >= ?", endDate, startDate)
+
+	if period != model.PeriodAll {
+		query = query.Where("period IN ?", []string{model.PeriodAll, period})
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+// UpdateStatus 更新申请状态（乐观锁：仅当当前状态与预期一致时更新）
+// 返回是否更新成功
+func (r *DoctorLeaveRepository) UpdateStatus(tx *gorm.DB, id int64, fromStatus, toStatus string, extraFields map[string]interface{}) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	updates := map[string]interface{}{
+		"status": toStatus,
+	}
+	for k, v := range extraFields {
+		updates[k] = v
+	}
+
+	result := tx.Model(&model.DoctorLeave{}).
+		Where("id = ? AND status = ?", id, fromStatus).
+		Updates(updates)
+	return result.RowsAffected > 0, result.Error
+}
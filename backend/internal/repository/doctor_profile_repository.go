@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"time"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// DoctorProfileRepository 医生结构化资料数据访问层（教育经历、证书、学术任职、相册、标签）
+type DoctorProfileRepository struct {
+	db *gorm.DB
+}
+
+// NewDoctorProfileRepository 创建医生资料仓库实例
+func NewDoctorProfileRepository() *DoctorProfileRepository {
+	return &DoctorProfileRepository{db: database.GetDB()}
+}
+
+// GetByDoctor 查询医生的结构化资料
+func (r *DoctorProfileRepository) GetByDoctor(doctorID int64) (*model.DoctorProfile, error) {
+	var profile model.DoctorProfile
+	order := "sort_order ASC, id ASC"
+	if err := r.db.Where("doctor_id = ?", doctorID).Order(order).Find(&profile.Educations).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("doctor_id = ?", doctorID).Order(order).Find(&profile.Certifications).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("doctor_id = ?", doctorID).Order(order).Find(&profile.Memberships).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("doctor_id = ?", doctorID).Order(order).Find(&profile.Photos).Error; err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// Replace 整体替换医生的执业证书信息、结构化资料及擅长领域标签
+func (r *DoctorProfileRepository) Replace(doctorID int64, license map[string]interface{}, profile *model.DoctorProfile, tagIDs []int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Doctor{}).Where("id = ?", doctorID).Updates(license).Error; err != nil {
+			return err
+		}
+
+		// 子表数据随资料整体替换，直接物理删除
+		for _, m := range []interface{}{&model.DoctorEducation{}, &model.DoctorCertification{}, &model.DoctorMembership{}, &model.DoctorPhoto{}} {
+			if err := tx.Unscoped().Where("doctor_id = ?", doctorID).Delete(m).Error; err != nil {
+				return err
+			}
+		}
+		if len(profile.Educations) > 0 {
+			if err := tx.Create(&profile.Educations).Error; err != nil {
+				return err
+			}
+		}
+		if len(profile.Certifications) > 0 {
+			if err := tx.Create(&profile.Certifications).Error; err != nil {
+				return err
+			}
+		}
+		if len(profile.Memberships) > 0 {
+			if err := tx.Create(&profile.Memberships).Error; err != nil {
+				return err
+			}
+		}
+		if len(profile.Photos) > 0 {
+			if err := tx.Create(&profile.Photos).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("doctor_id = ?", doctorID).Delete(&model.DoctorSpecialtyTag{}).Error; err != nil {
+			return err
+		}
+		if len(tagIDs) == 0 {
+			return nil
+		}
+		links := make([]model.DoctorSpecialtyTag, len(tagIDs))
+		for i, tagID := range tagIDs {
+			links[i] = model.DoctorSpecialtyTag{DoctorID: doctorID, SpecialtyTagID: tagID}
+		}
+		return tx.Create(&links).Error
+	})
+}
+
+// DeleteByDoctor 删除医生的全部结构化资料及标签（删除医生时调用）
+func (r *DoctorProfileRepository) DeleteByDoctor(doctorID int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, m := range []interface{}{&model.DoctorEducation{}, &model.DoctorCertification{}, &model.DoctorMembership{}, &model.DoctorPhoto{}} {
+			if err := tx.Where("doctor_id = ?", doctorID).Delete(m).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Where("doctor_id = ?", doctorID).Delete(&model.DoctorSpecialtyTag{}).Error
+	})
+}
+
+// ListExpiringLicenses 查询执业证书到期日早于指定日期的医生（含已过期，预加载主科室）
+func (r *DoctorProfileRepository) ListExpiringLicenses(before time.Time) ([]model.Doctor, error) {
+	var doctors []model.Doctor
+	err := r.db.Preload("Department").
+		Where("license_expires_at IS NOT NULL AND license_expires_at < ?", before).
+		Order("license_expires_at ASC, id ASC").
+		Find(&doctors).Error
+	return doctors, err
+}
+
+// ListExpiringCertifications 查询到期日早于指定日期的证书（含已过期）
+func (r *DoctorProfileRepository) ListExpiringCertifications(before time.Time) ([]model.DoctorCertification, error) {
+	var list []model.DoctorCertification
+	err := r.db.Where("expires_at IS NOT NULL AND expires_at < ?", before).
+		Where("doctor_id IN (?)", r.db.Model(&model.Doctor{}).Select("id")).
+		Order("expires_at ASC, id ASC").
+		Find(&list).Error
+	return list, err
+}
+
+// MarkLicensesAlerted 标记医生执业证书已发送到期提醒
+func (r *DoctorProfileRepository) MarkLicensesAlerted(doctorIDs []int64, at time.Time) error {
+	if len(doctorIDs) == 0 {
+		return nil
+	}
+	return r.db.Model(&model.Doctor{}).Where("id IN ?", doctorIDs).Update("license_alerted_at", at).Error
+}
+
+// MarkCertificationsAlerted 标记证书已发送到期提醒
+func (r *DoctorProfileRepository) MarkCertificationsAlerted(ids []int64, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Model(&model.DoctorCertification{}).Where("id IN ?", ids).Update("alerted_at", at).Error
+}
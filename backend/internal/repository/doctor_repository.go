@@ -1,8 +1,12 @@
 package repository
 
 import (
+	"strings"
+	"time"
+
 	"huaan-medical/internal/model"
 	"huaan-medical/pkg/database"
+	"huaan-medical/pkg/utils"
 
 	"gorm.io/gorm"
 )
@@ -35,7 +39,7 @@ func (r *DoctorRepository) Delete(id int64) error {
 // GetByID 根据ID查询医生
 func (r *DoctorRepository) GetByID(id int64) (*model.Doctor, error) {
 	var doctor model.Doctor
-	err := r.db.Preload("Department").First(&doctor, id).Error
+	err := r.db.Preload("Department").Preload("Affiliations.Department").Preload("Tags").First(&doctor, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -53,15 +57,20 @@ func (r *DoctorRepository) GetByIDSimple(id int64) (*model.Doctor, error) {
 }
 
 // List 分页查询医生列表（管理后台）
-func (r *DoctorRepository) List(page, pageSize int, departmentID *int64, status *int, keyword string) ([]model.Doctor, int64, error) {
+func (r *DoctorRepository) List(page, pageSize int, departmentID, tagID *int64, status *int, keyword string) ([]model.Doctor, int64, error) {
 	var doctors []model.Doctor
 	var total int64
 
-	query := r.db.Model(&model.Doctor{}).Preload("Department")
+	query := r.db.Model(&model.Doctor{}).Preload("Department").Preload("Affiliations.Department").Preload("Tags")
 
-	// 科室筛选
+	// 科室筛选（含在该科室出诊的非主科室医生）
 	if departmentID != nil && *departmentID > 0 {
-		query = query.Where("department_id = ?", *departmentID)
+		query = query.Where("id IN (?)", r.doctorIDsByDepartment(*departmentID))
+	}
+
+	// 擅长领域标签筛选
+	if tagID != nil && *tagID > 0 {
+		query = query.Where("id IN (?)", r.db.Model(&model.DoctorSpecialtyTag{}).Select("doctor_id").Where("specialty_tag_id = ?", *tagID))
 	}
 
 	// 状态筛选
@@ -69,10 +78,19 @@ func (r *DoctorRepository) List(page, pageSize int, departmentID *int64, status
 		query = query.Where("status = ?", *status)
 	}
 
-	// 关键词搜索（姓名、职称、擅长领域）
+	// 关键词搜索（姓名、擅长领域、职称名称、姓名拼音/首字母）
 	if keyword != "" {
-		query = query.Where("name LIKE ? OR title LIKE ? OR specialty LIKE ?",
-			"%"+keyword+"%", "%"+keyword+"%", "%"+keyword+"%")
+		like := "%" + keyword + "%"
+		cond := r.db.Where("name LIKE ? OR specialty LIKE ?", like, like)
+		if titles := model.MatchTitleCodes(keyword); len(titles) > 0 {
+			cond = cond.Or("title IN ?", titles)
+		}
+		if utils.IsPinyinQuery(keyword) {
+			pinyinLike := "%" + strings.ToLower(keyword) + "%"
+			cond = cond.Or("id IN (?)", r.db.Model(&model.SearchDocument{}).Select("entity_id").
+				Where("entity_type = ? AND (pinyin LIKE ? OR initials LIKE ?)", model.SearchEntityDoctor, pinyinLike, pinyinLike))
+		}
+		query = query.Where(cond)
 	}
 
 	// 统计总数
@@ -93,12 +111,12 @@ func (r *DoctorRepository) List(page, pageSize int, departmentID *int64, status
 func (r *DoctorRepository) ListPublic(departmentID *int64, keyword string) ([]model.Doctor, error) {
 	var doctors []model.Doctor
 
-	query := r.db.Model(&model.Doctor{}).Preload("Department").
+	query := r.db.Model(&model.Doctor{}).Preload("Department").Preload("Affiliations.Department").Preload("Tags").
 		Where("status = ?", model.StatusEnabled)
 
-	// 科室筛选
+	// 科室筛选（含在该科室出诊的非主科室医生）
 	if departmentID != nil && *departmentID > 0 {
-		query = query.Where("department_id = ?", *departmentID)
+		query = query.Where("id IN (?)", r.doctorIDsByDepartment(*departmentID))
 	}
 
 	// 关键词搜索
@@ -129,19 +147,19 @@ func (r *DoctorRepository) HasSchedules(id int64) (bool, error) {
 	return count > 0, err
 }
 
-// CountByDepartment 统计科室下的医生数量
+// CountByDepartment 统计科室下的医生数量（含非主科室医生）
 func (r *DoctorRepository) CountByDepartment(departmentID int64) (int64, error) {
 	var count int64
 	err := r.db.Model(&model.Doctor{}).
-		Where("department_id = ? AND status = ?", departmentID, model.StatusEnabled).
+		Where("id IN (?) AND status = ?", r.doctorIDsByDepartment(departmentID), model.StatusEnabled).
 		Count(&count).Error
 	return count, err
 }
 
-// GetByDepartment 根据科室ID获取医生列表
+// GetByDepartment 根据科室ID获取医生列表（含非主科室医生）
 func (r *DoctorRepository) GetByDepartment(departmentID int64) ([]model.Doctor, error) {
 	var doctors []model.Doctor
-	err := r.db.Where("department_id = ? AND status = ?", departmentID, model.StatusEnabled).
+	err := r.db.Where("id IN (?) AND status = ?", r.doctorIDsByDepartment(departmentID), model.StatusEnabled).
 		Order("sort_order ASC, id DESC").
 		Find(&doctors).Error
 	return doctors, err
@@ -168,3 +186,128 @@ func (r *DoctorRepository) UpdateStatus(ids []int64, status int) error {
 		Where("id IN ?", ids).
 		Update("status", status).Error
 }
+
+// ListByName 根据姓名查询医生（精确匹配，可能存在同名）
+func (r *DoctorRepository) ListByName(name string) ([]model.Doctor, error) {
+	var doctors []model.Doctor
+	err := r.db.Where("name = ?", name).Find(&doctors).Error
+	return doctors, err
+}
+
+// CreateWithDepartments 创建医生及其执业科室（主科室 + 其他执业科室）
+func (r *DoctorRepository) CreateWithDepartments(doctor *model.Doctor, otherDepartmentIDs []int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(doctor).Error; err != nil {
+			return err
+		}
+		return replaceDoctorDepartments(tx, doctor.ID, doctor.DepartmentID, otherDepartmentIDs)
+	})
+}
+
+// UpdateWithDepartments 更新医生并重建其执业科室
+func (r *DoctorRepository) UpdateWithDepartments(doctor *model.Doctor, otherDepartmentIDs []int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(doctor).Error; err != nil {
+			return err
+		}
+		return replaceDoctorDepartments(tx, doctor.ID, doctor.DepartmentID, otherDepartmentIDs)
+	})
+}
+
+// ListDepartments 查询医生的执业科室
+func (r *DoctorRepository) ListDepartments(doctorID int64) ([]model.DoctorDepartment, error) {
+	var list []model.DoctorDepartment
+	err := r.db.Where("doctor_id = ?", doctorID).Find(&list).Error
+	return list, err
+}
+
+// HasDepartment 检查医生是否在指定科室执业
+func (r *DoctorRepository) HasDepartment(doctorID, departmentID int64) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.DoctorDepartment{}).
+		Where("doctor_id = ? AND department_id = ?", doctorID, departmentID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// HasSchedulesInDepartment 检查医生在指定科室下是否有指定日期（含）之后的排班
+func (r *DoctorRepository) HasSchedulesInDepartment(doctorID, departmentID int64, fromDate time.Time) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Schedule{}).
+		Where("doctor_id = ? AND department_id = ? AND schedule_date >= ?", doctorID, departmentID, fromDate).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ListByIDs 根据ID批量查询医生（预加载科室）
+func (r *DoctorRepository) ListByIDs(ids []int64) ([]model.Doctor, error) {
+	var doctors []model.Doctor
+	if len(ids) == 0 {
+		return doctors, nil
+	}
+	err := r.db.Preload("Department").Preload("Affiliations.Department").Preload("Tags").Where("id IN ?", ids).Find(&doctors).Error
+	return doctors, err
+}
+
+// ListAllWithDepartments 查询全部医生（含停诊，预加载科室，用于重建搜索索引）
+func (r *DoctorRepository) ListAllWithDepartments() ([]model.Doctor, error) {
+	var doctors []model.Doctor
+	err := r.db.Preload("Department").Preload("Affiliations.Department").Preload("Tags").Find(&doctors).Error
+	return doctors, err
+}
+
+// ListIDsByDepartment 查询在指定科室执业的医生ID
+func (r *DoctorRepository) ListIDsByDepartment(departmentID int64) ([]int64, error) {
+	var ids []int64
+	err := r.doctorIDsByDepartment(departmentID).Pluck("doctor_id", &ids).Error
+	return ids, err
+}
+
+// CountEnabledByDepartments 批量统计科室下正常出诊的医生数（含非主科室医生）
+func (r *DoctorRepository) CountEnabledByDepartments(departmentIDs []int64) (map[int64]int64, error) {
+	result := make(map[int64]int64, len(departmentIDs))
+	if len(departmentIDs) == 0 {
+		return result, nil
+	}
+
+	var rows []struct {
+		DepartmentID int64
+		Count        int64
+	}
+	err := r.db.Model(&model.DoctorDepartment{}).
+		Select("doctor_departments.department_id, COUNT(*) as count").
+		Joins("JOIN doctors ON doctors.id = doctor_departments.doctor_id AND doctors.deleted_at IS NULL").
+		Where("doctor_departments.department_id IN ? AND doctors.status = ?", departmentIDs, model.StatusEnabled).
+		Group("doctor_departments.department_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		result[row.DepartmentID] = row.Count
+	}
+	return result, nil
+}
+
+// doctorIDsByDepartment 在指定科室执业的医生ID子查询
+func (r *DoctorRepository) doctorIDsByDepartment(departmentID int64) *gorm.DB {
+	return r.db.Model(&model.DoctorDepartment{}).Select("doctor_id").Where("department_id = ?", departmentID)
+}
+
+// replaceDoctorDepartments 重建医生执业科室（需要在事务中调用）
+func replaceDoctorDepartments(tx *gorm.DB, doctorID, primaryID int64, otherDepartmentIDs []int64) error {
+	if err := tx.Where("doctor_id = ?", doctorID).Delete(&model.DoctorDepartment{}).Error; err != nil {
+		return err
+	}
+
+	list := []model.DoctorDepartment{{DoctorID: doctorID, DepartmentID: primaryID, IsPrimary: true}}
+	seen := map[int64]bool{primaryID: true}
+	for _, id := range otherDepartmentIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		list = append(list, model.DoctorDepartment{DoctorID: doctorID, DepartmentID: id})
+	}
+	return tx.Create(&list).Error
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"math"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// DoctorReviewRepository 就诊评价数据访问层
+type DoctorReviewRepository struct {
+	db *gorm.DB
+}
+
+// NewDoctorReviewRepository 创建就诊评价仓库实例
+func NewDoctorReviewRepository() *DoctorReviewRepository {
+	return &DoctorReviewRepository{db: database.GetDB()}
+}
+
+// Create 创建评价
+func (r *DoctorReviewRepository) Create(review *model.DoctorReview) error {
+	return r.db.Create(review).Error
+}
+
+// GetByID 根据ID查询评价
+func (r *DoctorReviewRepository) GetByID(id int64) (*model.DoctorReview, error) {
+	var review model.DoctorReview
+	err := r.db.Preload("Appointment").
+		Preload("Patient").
+		Preload("Doctor").
+		Preload("Department").
+		First(&review, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// GetByAppointmentID 根据预约ID查询评价
+func (r *DoctorReviewRepository) GetByAppointmentID(appointmentID int64) (*model.DoctorReview, error) {
+	var review model.DoctorReview
+	err := r.db.Preload("Appointment").
+		Preload("Patient").
+		Preload("Doctor").
+		Preload("Department").
+		Where("appointment_id = ?", appointmentID).
+		First(&review).Error
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// ExistsByAppointmentID 检查预约是否已评价
+func (r *DoctorReviewRepository) ExistsByAppointmentID(appointmentID int64) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.DoctorReview{}).Where("appointment_id = ?", appointmentID).Count(&count).Error
+	return count > 0, err
+}
+
+// List 分页查询评价
+// status 为空时不过滤状态；minRating/maxRating 为 0 时不过滤评分
+func (r *DoctorReviewRepository) List(page, pageSize int, doctorID, departmentID, userID *int64, status string, minRating, maxRating int, keyword string) ([]model.DoctorReview, int64, error) {
+	var reviews []model.DoctorReview
+	var total int64
+
+	query := r.db.Model(&model.DoctorReview{})
+
+	if doctorID != nil && *doctorID > 0 {
+		query = query.Where("doctor_id = ?", *doctorID)
+	}
+	if departmentID != nil && *departmentID > 0 {
+		query = query.Where("department_id = ?", *departmentID)
+	}
+	if userID != nil && *userID > 0 {
+		query = query.Where("user_id = ?", *userID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if minRating > 0 {
+		query = query.Where("rating >= ?", minRating)
+	}
+	if maxRating > 0 {
+		query = query.Where("rating <= ?", maxRating)
+	}
+	if keyword != "" {
+		query = query.Where("content LIKE ?", "%"+keyword+"%")
+	}
+
+	// 统计总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 分页查询
+	offset := (page - 1) * pageSize
+	err := query.Preload("Appointment").
+		Preload("Patient").
+		Preload("Doctor").
+		Preload("Department").
+		Order("id DESC").
+		Offset(offset).Limit(pageSize).
+		Find(&reviews).Error
+
+	return reviews, total, err
+}
+
+// UpdateStatus 更新评价状态（乐观锁：仅当前状态为 from 时更新）
+func (r *DoctorReviewRepository) UpdateStatus(id int64, from, to string, extra map[string]interface{}) (bool, error) {
+	updates := map[string]interface{}{"status": to}
+	for k, v := range extra {
+		updates[k] = v
+	}
+	result := r.db.Model(&model.DoctorReview{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(updates)
+	return result.RowsAffected > 0, result.Error
+}
+
+// UpdateReply 更新医生回复
+func (r *DoctorReviewRepository) UpdateReply(id int64, updates map[string]interface{}) error {
+	return r.db.Model(&model.DoctorReview{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// SummaryByDoctors 批量统计医生评分（仅统计未屏蔽的评价）
+func (r *DoctorReviewRepository) SummaryByDoctors(doctorIDs []int64) (map[int64]model.DoctorRatingSummary, error) {
+	result := make(map[int64]model.DoctorRatingSummary, len(doctorIDs))
+	if len(doctorIDs) == 0 {
+		return result, nil
+	}
+
+	var rows []struct {
+		DoctorID    int64
+		AvgRating   float64
+		ReviewCount int64
+	}
+	err := r.db.Model(&model.DoctorReview{}).
+		Select("doctor_id, AVG(rating) as avg_rating, COUNT(*) as review_count").
+		Where("doctor_id IN ? AND status = ?", doctorIDs, model.DoctorReviewStatusVisible).
+		Group("doctor_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result[row.DoctorID] = model.DoctorRatingSummary{
+			DoctorID:    row.DoctorID,
+			Rating:      math.Round(row.AvgRating*10) / 10,
+			ReviewCount: row.ReviewCount,
+		}
+	}
+	return result, nil
+}
@@ -107,3 +107,13 @@ func (r *LogRepository) ListLoginLogs(page, pageSize int, userType, keyword stri
 	return list, total, nil
 }
 
+
+// ListRecentLoginLogs 查询指定账号最近的登录日志
+func (r *LogRepository) ListRecentLoginLogs(userType string, userID int64, limit int) ([]model.LoginLog, error) {
+	var list []model.LoginLog
+	err := r.db.Where("user_type = ? AND user_id = ?", userType, userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&list).Error
+	return list, err
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 
 	"huaan-medical/internal/model"
@@ -27,33 +29,38 @@ func (r *MedicalRecordRepository) GetByID(id int64) (*model.MedicalRecord, error
 		Preload("Patient").
 		Preload("Doctor").
 		Preload("Department").
+		Preload("Amendments", func(db *gorm.DB) *gorm.DB { return db.Order("version ASC") }).
 		First(&record, id).Error
 	return &record, err
 }
 
-// GetByUserAndID 根据用户ID和记录ID获取就诊记录（权限校验）
+// GetByUserAndID 根据用户ID和记录ID获取就诊记录（权限校验，本人及共享就诊人的记录，仅返回已签署的记录）
 func (r *MedicalRecordRepository) GetByUserAndID(userID, recordID int64) (*model.MedicalRecord, error) {
 	var record model.MedicalRecord
+	cond, args := patientAccessCondition("medical_records.patient_id", userID)
 	err := r.db.
 		Preload("Appointment").
 		Preload("Patient").
 		Preload("Doctor").
 		Preload("Department").
-		Joins("JOIN patients ON patients.id = medical_records.patient_id").
-		Where("medical_records.id = ? AND patients.user_id = ?", recordID, userID).
+		Preload("Amendments", func(db *gorm.DB) *gorm.DB { return db.Order("version ASC") }).
+		Where("medical_records.id = ?", recordID).
+		Where(cond, args...).
+		Where("medical_records.status = ?", model.MedicalRecordStatusSigned).
 		First(&record).Error
 	return &record, err
 }
 
-// ListByUser 查询用户的就诊记录列表
+// ListByUser 查询用户的就诊记录列表（含共享就诊人，仅已签署的记录）
 func (r *MedicalRecordRepository) ListByUser(userID int64) ([]*model.MedicalRecord, error) {
 	var records []*model.MedicalRecord
+	cond, args := patientAccessCondition("medical_records.patient_id", userID)
 	err := r.db.
 		Preload("Patient").
 		Preload("Doctor").
 		Preload("Department").
-		Joins("JOIN patients ON patients.id = medical_records.patient_id").
-		Where("patients.user_id = ?", userID).
+		Where(cond, args...).
+		Where("medical_records.status = ?", model.MedicalRecordStatusSigned).
 		Order("medical_records.visit_date DESC, medical_records.created_at DESC").
 		Find(&records).Error
 	return records, err
@@ -67,17 +74,110 @@ func (r *MedicalRecordRepository) GetByAppointmentID(appointmentID int64) (*mode
 		Preload("Patient").
 		Preload("Doctor").
 		Preload("Department").
+		Preload("Amendments", func(db *gorm.DB) *gorm.DB { return db.Order("version ASC") }).
 		Where("appointment_id = ?", appointmentID).
 		First(&record).Error
 	return &record, err
 }
 
-// Create 创建就诊记录
-func (r *MedicalRecordRepository) Create(record *model.MedicalRecord) error {
-	return r.db.Create(record).Error
+// Create 创建就诊记录（需要在事务中调用）
+func (r *MedicalRecordRepository) Create(tx *gorm.DB, record *model.MedicalRecord) error {
+	return tx.Create(record).Error
 }
 
 // Update 更新就诊记录
 func (r *MedicalRecordRepository) Update(record *model.MedicalRecord) error {
 	return r.db.Save(record).Error
 }
+
+// List 分页查询就诊记录（管理后台/医生工作台）
+// doctorID 不为空时仅查询该医生的记录
+func (r *MedicalRecordRepository) List(page, pageSize int, doctorID, departmentID *int64, status string, startDate, endDate *time.Time, keyword string) ([]model.MedicalRecord, int64, error) {
+	var records []model.MedicalRecord
+	var total int64
+
+	query := r.db.Model(&model.MedicalRecord{})
+
+	if doctorID != nil && *doctorID > 0 {
+		query = query.Where("medical_records.doctor_id = ?", *doctorID)
+	}
+	if departmentID != nil && *departmentID > 0 {
+		query = query.Where("medical_records.department_id = ?", *departmentID)
+	}
+	if status != "" {
+		query = query.Where("medical_records.status = ?", status)
+	}
+	if startDate != nil {
+		query = query.Where("medical_records.visit_date >= ?", *startDate)
+	}
+	if endDate != nil {
+		query = query.Where("medical_records.visit_date <= ?", *endDate)
+	}
+
+	// 关键词搜索（患者姓名、诊断）
+	if keyword != "" {
+		query = query.Where(
+			r.db.Where("medical_records.diagnosis LIKE ?", "%"+keyword+"%").
+				Or("EXISTS (SELECT 1 FROM patients WHERE patients.id = medical_records.patient_id AND patients.name LIKE ?)", "%"+keyword+"%"),
+		)
+	}
+
+	// 统计总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 分页查询
+	offset := (page - 1) * pageSize
+	err := query.Preload("Patient").
+		Preload("Doctor").
+		Preload("Department").
+		Order("medical_records.visit_date DESC, medical_records.id DESC").
+		Offset(offset).Limit(pageSize).
+		Find(&records).Error
+
+	return records, total, err
+}
+
+// UpdateDraft 更新草稿内容（需要在事务中调用，乐观锁：仅当仍为草稿时更新）
+// 返回是否更新成功
+func (r *MedicalRecordRepository) UpdateDraft(tx *gorm.DB, id int64, updates map[string]interface{}) (bool, error) {
+	result := tx.Model(&model.MedicalRecord{}).
+		Where("id = ? AND status = ?", id, model.MedicalRecordStatusDraft).
+		Updates(updates)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Sign 签署病历（乐观锁：仅当仍为草稿时更新）
+// 返回是否更新成功
+func (r *MedicalRecordRepository) Sign(id int64, signerType string, signerID int64, signedAt time.Time) (bool, error) {
+	result := r.db.Model(&model.MedicalRecord{}).
+		Where("id = ? AND status = ?", id, model.MedicalRecordStatusDraft).
+		Updates(map[string]interface{}{
+			"status":      model.MedicalRecordStatusSigned,
+			"signer_type": signerType,
+			"signer_id":   signerID,
+			"signed_at":   signedAt,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Amend 补充更正已签署的病历（需要在事务中调用，乐观锁：版本号一致时更新并加1）
+// 返回是否更新成功
+func (r *MedicalRecordRepository) Amend(tx *gorm.DB, id int64, version int, updates map[string]interface{}, amendment *model.MedicalRecordAmendment) (bool, error) {
+	fields := map[string]interface{}{
+		"version": gorm.Expr("version + 1"),
+	}
+	for k, v := range updates {
+		fields[k] = v
+	}
+
+	result := tx.Model(&model.MedicalRecord{}).
+		Where("id = ? AND status = ? AND version = ?", id, model.MedicalRecordStatusSigned, version).
+		Updates(fields)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+
+	return true, tx.Create(amendment).Error
+}
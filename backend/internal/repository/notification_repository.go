@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"time"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// NotificationRepository 站内消息数据访问层
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository 创建站内消息仓库实例
+func NewNotificationRepository() *NotificationRepository {
+	return &NotificationRepository{db: database.GetDB()}
+}
+
+// BatchCreate 批量创建消息
+func (r *NotificationRepository) BatchCreate(notifications []model.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(notifications, 100).Error
+}
+
+// ListByUser 分页查询用户消息
+func (r *NotificationRepository) ListByUser(userID int64, page, pageSize int, unreadOnly bool) ([]model.Notification, int64, error) {
+	var notifications []model.Notification
+	var total int64
+
+	query := r.db.Model(&model.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("is_read = ?", false)
+	}
+
+	// 统计总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 分页查询
+	offset := (page - 1) * pageSize
+	err := query.Order("id DESC").
+		Offset(offset).Limit(pageSize).
+		Find(&notifications).Error
+
+	return notifications, total, err
+}
+
+// CountUnread 统计用户未读消息数
+func (r *NotificationRepository) CountUnread(userID int64) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Notification{}).
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Count(&count).Error
+	return count, err
+}
+
+// MarkRead 标记单条消息已读，返回是否更新成功
+func (r *NotificationRepository) MarkRead(userID, id int64) (bool, error) {
+	result := r.db.Model(&model.Notification{}).
+		Where("id = ? AND user_id = ? AND is_read = ?", id, userID, false).
+		Updates(map[string]interface{}{
+			"is_read": true,
+			"read_at": time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// MarkAllRead 标记用户全部消息已读
+func (r *NotificationRepository) MarkAllRead(userID int64) error {
+	return r.db.Model(&model.Notification{}).
+		Where("user_id = ? AND is_read = ?", userID, false).
+		Updates(map[string]interface{}{
+			"is_read": true,
+			"read_at": time.Now(),
+		}).Error
+}
+
+// Exists 检查消息是否属于该用户
+func (r *NotificationRepository) Exists(userID, id int64) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Notification{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"time"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// PatientConsentRepository 成年就诊人病历查看授权数据访问层
+type PatientConsentRepository struct {
+	db *gorm.DB
+}
+
+// NewPatientConsentRepository 创建病历查看授权仓库实例
+func NewPatientConsentRepository() *PatientConsentRepository {
+	return &PatientConsentRepository{db: database.GetDB()}
+}
+
+// Create 创建授权
+func (r *PatientConsentRepository) Create(consent *model.PatientConsent) error {
+	return r.db.Create(consent).Error
+}
+
+// ListActiveByUser 查询用户获得的未到期、未失效授权
+func (r *PatientConsentRepository) ListActiveByUser(userID int64, now time.Time) ([]model.PatientConsent, error) {
+	var list []model.PatientConsent
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("id DESC").
+		Find(&list).Error
+	return list, err
+}
+
+// ListActiveByPatient 查询用户对指定就诊人的未到期、未失效授权
+func (r *PatientConsentRepository) ListActiveByPatient(patientID, userID int64, now time.Time) ([]model.PatientConsent, error) {
+	var list []model.PatientConsent
+	err := r.db.Where("patient_id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?", patientID, userID, now).
+		Order("id DESC").
+		Find(&list).Error
+	return list, err
+}
+
+// RevokeByPatientAndUser 撤销用户对就诊人的授权
+func (r *PatientConsentRepository) RevokeByPatientAndUser(patientID, userID int64, now time.Time) error {
+	return r.db.Model(&model.PatientConsent{}).
+		Where("patient_id = ? AND user_id = ? AND revoked_at IS NULL", patientID, userID).
+		Update("revoked_at", now).Error
+}
@@ -0,0 +1,327 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/pkg/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PatientDuplicateRepository 疑似重复就诊人及合并记录数据访问层
+type PatientDuplicateRepository struct {
+	db *gorm.DB
+}
+
+// NewPatientDuplicateRepository 创建疑似重复就诊人仓库实例
+func NewPatientDuplicateRepository() *PatientDuplicateRepository {
+	return &PatientDuplicateRepository{db: database.GetDB()}
+}
+
+// errMergeConflict 合并/撤销时记录状态已被并发请求改变，用于回滚事务
+var errMergeConflict = errors.New("patient merge state changed")
+
+// unscopedPatient 预加载就诊人时包含已合并（软删除）的记录
+func unscopedPatient(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+// ListPatientsForScan 查询参与查重的就诊人（仅查重所需字段）
+func (r *PatientDuplicateRepository) ListPatientsForScan() ([]model.Patient, error) {
+	var patients []model.Patient
+	err := r.db.Model(&model.Patient{}).
+		Select("id, user_id, name, doc_type, id_card, phone, gender, birth_date").
+		Order("id ASC").
+		Find(&patients).Error
+	return patients, err
+}
+
+// CreateIfAbsent 写入疑似重复记录，同一对就诊人已存在（含已忽略）时跳过，返回是否新增
+func (r *PatientDuplicateRepository) CreateIfAbsent(dup *model.PatientDuplicate) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(dup)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// List 分页查询疑似重复记录
+// 待审核记录仅返回双方均未被合并/删除的，避免展示已失效的候选
+func (r *PatientDuplicateRepository) List(page, pageSize int, status, matchType string) ([]model.PatientDuplicate, int64, error) {
+	var list []model.PatientDuplicate
+	var total int64
+
+	query := r.db.Model(&model.PatientDuplicate{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if matchType != "" {
+		query = query.Where("match_type = ?", matchType)
+	}
+	if status == model.DuplicateStatusPending {
+		alive := r.db.Model(&model.Patient{}).Select("id")
+		query = query.Where("patient_id IN (?) AND duplicate_id IN (?)", alive, alive)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Preload("Patient", unscopedPatient).
+		Preload("Duplicate", unscopedPatient).
+		Order("score DESC, id DESC").
+		Offset(offset).Limit(pageSize).
+		Find(&list).Error
+	return list, total, err
+}
+
+// GetByID 根据ID查询疑似重复记录
+func (r *PatientDuplicateRepository) GetByID(id int64) (*model.PatientDuplicate, error) {
+	var dup model.PatientDuplicate
+	if err := r.db.First(&dup, id).Error; err != nil {
+		return nil, err
+	}
+	return &dup, nil
+}
+
+// Review 审核疑似重复记录（乐观锁：仅当记录仍为待审核时更新），返回是否更新成功
+func (r *PatientDuplicateRepository) Review(id int64, status string, adminID int64, remark string, now time.Time) (bool, error) {
+	result := r.db.Model(&model.PatientDuplicate{}).
+		Where("id = ? AND status = ?", id, model.DuplicateStatusPending).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"reviewed_by": adminID,
+			"reviewed_at": now,
+			"remark":      remark,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetPatientUnscoped 查询就诊人（含已合并/删除的）
+func (r *PatientDuplicateRepository) GetPatientUnscoped(id int64) (*model.Patient, error) {
+	var patient model.Patient
+	if err := r.db.Unscoped().First(&patient, id).Error; err != nil {
+		return nil, err
+	}
+	return &patient, nil
+}
+
+// ListMerges 分页查询合并记录
+func (r *PatientDuplicateRepository) ListMerges(page, pageSize int, status string, patientID *int64) ([]model.PatientMerge, int64, error) {
+	var list []model.PatientMerge
+	var total int64
+
+	query := r.db.Model(&model.PatientMerge{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if patientID != nil && *patientID > 0 {
+		query = query.Where("survivor_id = ? OR merged_id = ?", *patientID, *patientID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&list).Error
+	return list, total, err
+}
+
+// GetMergeByID 根据ID查询合并记录
+func (r *PatientDuplicateRepository) GetMergeByID(id int64) (*model.PatientMerge, error) {
+	var merge model.PatientMerge
+	if err := r.db.First(&merge, id).Error; err != nil {
+		return nil, err
+	}
+	return &merge, nil
+}
+
+// Merge 在同一事务内将被合并就诊人的预约、病历、评价迁移到保留方，
+// 为保留方补充被合并方的所有者及共享成员，软删除被合并方并写入合并记录。
+// 返回 false 表示审核记录已被处理或被合并方已被删除/合并（并发请求），此时不做任何修改
+func (r *PatientDuplicateRepository) Merge(merge *model.PatientMerge, survivor, merged *model.Patient, dup *model.PatientDuplicate, now time.Time) (bool, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var snapshot model.PatientMergeSnapshot
+
+		// 0. 锁定审核队列记录：仅待审核的记录可合并
+		if dup != nil {
+			result := tx.Model(&model.PatientDuplicate{}).
+				Where("id = ? AND status = ?", dup.ID, model.DuplicateStatusPending).
+				Updates(map[string]interface{}{
+					"status":      model.DuplicateStatusMerged,
+					"reviewed_by": merge.AdminID,
+					"reviewed_at": now,
+					"remark":      merge.Remark,
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return errMergeConflict
+			}
+		}
+
+		// 1. 迁移业务数据（记录ID用于撤销）
+		moves := []struct {
+			model interface{}
+			ids   *[]int64
+		}{
+			{&model.Appointment{}, &snapshot.AppointmentIDs},
+			{&model.MedicalRecord{}, &snapshot.MedicalRecordIDs},
+			{&model.DoctorReview{}, &snapshot.ReviewIDs},
+		}
+		for _, m := range moves {
+			if err := tx.Model(m.model).Where("patient_id = ?", merged.ID).Pluck("id", m.ids).Error; err != nil {
+				return err
+			}
+			if len(*m.ids) == 0 {
+				continue
+			}
+			if err := tx.Model(m.model).Where("id IN ?", *m.ids).Update("patient_id", survivor.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		// 2. 保留被合并方的访问权：其所有者成为保留方的管理者，其共享成员按原角色加入
+		var existing []model.PatientMember
+		if err := tx.Where("patient_id = ?", survivor.ID).Find(&existing).Error; err != nil {
+			return err
+		}
+		hasAccess := map[int64]bool{survivor.UserID: true}
+		for _, m := range existing {
+			hasAccess[m.UserID] = true
+		}
+		var mergedMembers []model.PatientMember
+		if err := tx.Where("patient_id = ?", merged.ID).Order("id ASC").Find(&mergedMembers).Error; err != nil {
+			return err
+		}
+		candidates := append([]model.PatientMember{{UserID: merged.UserID, Role: model.PatientRoleManager}}, mergedMembers...)
+		for _, c := range candidates {
+			if hasAccess[c.UserID] {
+				continue
+			}
+			member := &model.PatientMember{
+				PatientID: survivor.ID,
+				UserID:    c.UserID,
+				Role:      c.Role,
+				InvitedBy: survivor.UserID,
+			}
+			if err := tx.Create(member).Error; err != nil {
+				return err
+			}
+			hasAccess[c.UserID] = true
+			snapshot.AddedMemberIDs = append(snapshot.AddedMemberIDs, member.ID)
+		}
+
+		// 3. 标记并软删除被合并方
+		if err := tx.Model(&model.Patient{}).Where("id = ?", merged.ID).Updates(map[string]interface{}{
+			"merged_into": survivor.ID,
+			"is_default":  0,
+		}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("merged_into = ?", survivor.ID).Delete(&model.Patient{}, merged.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errMergeConflict
+		}
+
+		// 4. 写入合并记录
+		data, err := json.Marshal(&snapshot)
+		if err != nil {
+			return err
+		}
+		merge.Snapshot = string(data)
+		if err := tx.Create(merge).Error; err != nil {
+			return err
+		}
+
+		// 5. 关联审核队列与合并记录
+		if dup == nil {
+			return nil
+		}
+		return tx.Model(&model.PatientDuplicate{}).Where("id = ?", dup.ID).Update("merge_id", merge.ID).Error
+	})
+	if errors.Is(err, errMergeConflict) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Undo 撤销合并：恢复被合并方，按快照将业务数据迁回，移除合并时新增的共享成员。
+// 返回 false 表示合并已被并发请求撤销，此时不做任何修改
+func (r *PatientDuplicateRepository) Undo(merge *model.PatientMerge, snapshot *model.PatientMergeSnapshot) (bool, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// 锁定合并记录：仅已合并状态可撤销
+		result := tx.Model(&model.PatientMerge{}).
+			Where("id = ? AND status = ?", merge.ID, model.PatientMergeStatusMerged).
+			Updates(map[string]interface{}{
+				"status":      model.PatientMergeStatusUndone,
+				"undone_by":   merge.UndoneBy,
+				"undone_name": merge.UndoneName,
+				"undone_at":   merge.UndoneAt,
+				"undo_remark": merge.UndoRemark,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errMergeConflict
+		}
+
+		if err := tx.Unscoped().Model(&model.Patient{}).Where("id = ?", merge.MergedID).Updates(map[string]interface{}{
+			"deleted_at":  nil,
+			"merged_into": 0,
+		}).Error; err != nil {
+			return err
+		}
+
+		moves := []struct {
+			model interface{}
+			ids   []int64
+		}{
+			{&model.Appointment{}, snapshot.AppointmentIDs},
+			{&model.MedicalRecord{}, snapshot.MedicalRecordIDs},
+			{&model.DoctorReview{}, snapshot.ReviewIDs},
+		}
+		for _, m := range moves {
+			if len(m.ids) == 0 {
+				continue
+			}
+			if err := tx.Model(m.model).
+				Where("id IN ? AND patient_id = ?", m.ids, merge.SurvivorID).
+				Update("patient_id", merge.MergedID).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(snapshot.AddedMemberIDs) > 0 {
+			if err := tx.Where("id IN ?", snapshot.AddedMemberIDs).Delete(&model.PatientMember{}).Error; err != nil {
+				return err
+			}
+		}
+
+		// 审核队列恢复为待审核
+		if merge.DuplicateID == 0 {
+			return nil
+		}
+		return tx.Model(&model.PatientDuplicate{}).Where("id = ?", merge.DuplicateID).Updates(map[string]interface{}{
+			"status":      model.DuplicateStatusPending,
+			"merge_id":    0,
+			"reviewed_by": 0,
+			"reviewed_at": nil,
+		}).Error
+	})
+	if errors.Is(err, errMergeConflict) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"time"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// patientAccessCondition 用户可访问就诊人的查询条件（本人创建或被共享）
+// column 为就诊人ID所在列，如 "id"、"patient_id"、"medical_records.patient_id"
+func patientAccessCondition(column string, userID int64) (string, []interface{}) {
+	sql := "(" + column + " IN (SELECT id FROM patients WHERE user_id = ? AND deleted_at IS NULL)" +
+		" OR " + column + " IN (SELECT patient_id FROM patient_members WHERE user_id = ?))"
+	return sql, []interface{}{userID, userID}
+}
+
+// PatientMemberRepository 就诊人共享成员及邀请数据访问层
+type PatientMemberRepository struct {
+	db *gorm.DB
+}
+
+// NewPatientMemberRepository 创建就诊人共享仓库实例
+func NewPatientMemberRepository() *PatientMemberRepository {
+	return &PatientMemberRepository{db: database.GetDB()}
+}
+
+// GetMember 查询用户在就诊人下的共享成员记录
+func (r *PatientMemberRepository) GetMember(patientID, userID int64) (*model.PatientMember, error) {
+	var member model.PatientMember
+	err := r.db.Where("patient_id = ? AND user_id = ?", patientID, userID).First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// ListMembers 查询就诊人的共享成员（预加载用户信息）
+func (r *PatientMemberRepository) ListMembers(patientID int64) ([]model.PatientMember, error) {
+	var members []model.PatientMember
+	err := r.db.Preload("User").
+		Where("patient_id = ?", patientID).
+		Order("id ASC").
+		Find(&members).Error
+	return members, err
+}
+
+// ListByUser 查询共享给用户的成员记录
+func (r *PatientMemberRepository) ListByUser(userID int64) ([]model.PatientMember, error) {
+	var members []model.PatientMember
+	err := r.db.Where("user_id = ?", userID).Order("id ASC").Find(&members).Error
+	return members, err
+}
+
+// CountMembers 统计就诊人的共享成员数
+func (r *PatientMemberRepository) CountMembers(patientID int64) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.PatientMember{}).Where("patient_id = ?", patientID).Count(&count).Error
+	return count, err
+}
+
+// UpdateRole 更新共享成员角色
+func (r *PatientMemberRepository) UpdateRole(patientID, userID int64, role string) error {
+	return r.db.Model(&model.PatientMember{}).
+		Where("patient_id = ? AND user_id = ?", patientID, userID).
+		Update("role", role).Error
+}
+
+// DeleteMember 移除共享成员
+func (r *PatientMemberRepository) DeleteMember(patientID, userID int64) error {
+	return r.db.Where("patient_id = ? AND user_id = ?", patientID, userID).Delete(&model.PatientMember{}).Error
+}
+
+// CreateInvitation 创建共享邀请
+func (r *PatientMemberRepository) CreateInvitation(invitation *model.PatientInvitation) error {
+	return r.db.Create(invitation).Error
+}
+
+// GetInvitationByID 根据ID查询共享邀请
+func (r *PatientMemberRepository) GetInvitationByID(id int64) (*model.PatientInvitation, error) {
+	var invitation model.PatientInvitation
+	if err := r.db.First(&invitation, id).Error; err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// GetInvitationByCode 根据邀请码查询共享邀请（预加载就诊人）
+func (r *PatientMemberRepository) GetInvitationByCode(code string) (*model.PatientInvitation, error) {
+	var invitation model.PatientInvitation
+	err := r.db.Preload("Patient").Where("code = ?", code).First(&invitation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// ListInvitations 查询就诊人的共享邀请（最新在前）
+func (r *PatientMemberRepository) ListInvitations(patientID int64) ([]model.PatientInvitation, error) {
+	var list []model.PatientInvitation
+	err := r.db.Where("patient_id = ?", patientID).Order("id DESC").Find(&list).Error
+	return list, err
+}
+
+// RevokeInvitation 撤销待接受的共享邀请
+func (r *PatientMemberRepository) RevokeInvitation(id int64) error {
+	return r.db.Model(&model.PatientInvitation{}).
+		Where("id = ? AND status = ?", id, model.PatientInvitationPending).
+		Update("status", model.PatientInvitationRevoked).Error
+}
+
+// AcceptInvitation 接受共享邀请：邀请置为已接受并添加共享成员（邀请已被使用时返回 gorm.ErrRecordNotFound）
+func (r *PatientMemberRepository) AcceptInvitation(invitation *model.PatientInvitation, member *model.PatientMember, now time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.PatientInvitation{}).
+			Where("id = ? AND status = ?", invitation.ID, model.PatientInvitationPending).
+			Updates(map[string]interface{}{
+				"status":      model.PatientInvitationAccepted,
+				"accepted_by": member.UserID,
+				"accepted_at": now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Create(member).Error
+	})
+}
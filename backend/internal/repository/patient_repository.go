@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"huaan-medical/internal/model"
 	"huaan-medical/pkg/database"
 
@@ -27,9 +29,19 @@ func (r *PatientRepository) Update(patient *model.Patient) error {
 	return r.db.Save(patient).Error
 }
 
-// Delete 删除就诊人（软删除）
+// Delete 删除就诊人（软删除），同时移除共享成员并撤销待接受的邀请
 func (r *PatientRepository) Delete(id int64) error {
-	return r.db.Delete(&model.Patient{}, id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("patient_id = ?", id).Delete(&model.PatientMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.PatientInvitation{}).
+			Where("patient_id = ? AND status = ?", id, model.PatientInvitationPending).
+			Update("status", model.PatientInvitationRevoked).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Patient{}, id).Error
+	})
 }
 
 // GetByID 根据ID查询就诊人
@@ -42,16 +54,45 @@ func (r *PatientRepository) GetByID(id int64) (*model.Patient, error) {
 	return &patient, nil
 }
 
-// GetByUserAndID 根据用户ID和就诊人ID查询（用于权限校验）
+// GetByUserAndID 根据用户ID和就诊人ID查询（用于权限校验，本人创建或被共享的就诊人均可访问）
 func (r *PatientRepository) GetByUserAndID(userID, patientID int64) (*model.Patient, error) {
 	var patient model.Patient
-	err := r.db.Where("user_id = ? AND id = ?", userID, patientID).First(&patient).Error
+	cond, args := patientAccessCondition("id", userID)
+	err := r.db.Where("id = ?", patientID).Where(cond, args...).First(&patient).Error
 	if err != nil {
 		return nil, err
 	}
 	return &patient, nil
 }
 
+// GetWithRole 查询用户可访问的就诊人及用户的共享角色（无权访问时返回 gorm.ErrRecordNotFound）
+func (r *PatientRepository) GetWithRole(userID, patientID int64) (*model.Patient, string, error) {
+	patient, err := r.GetByID(patientID)
+	if err != nil {
+		return nil, "", err
+	}
+	if patient.UserID == userID {
+		return patient, model.PatientRoleOwner, nil
+	}
+
+	var member model.PatientMember
+	err = r.db.Where("patient_id = ? AND user_id = ?", patientID, userID).First(&member).Error
+	if err != nil {
+		return nil, "", err
+	}
+	return patient, member.Role, nil
+}
+
+// ListByIDs 根据ID批量查询就诊人
+func (r *PatientRepository) ListByIDs(ids []int64) ([]model.Patient, error) {
+	var patients []model.Patient
+	if len(ids) == 0 {
+		return patients, nil
+	}
+	err := r.db.Where("id IN ?", ids).Order("id DESC").Find(&patients).Error
+	return patients, err
+}
+
 // ListByUser 根据用户ID查询就诊人列表
 func (r *PatientRepository) ListByUser(userID int64) ([]model.Patient, error) {
 	var patients []model.Patient
@@ -86,6 +127,88 @@ func (r *PatientRepository) ExistsByIDCard(userID int64, idCard string, excludeI
 	return count > 0, err
 }
 
+// ExistsSharedByIDCard 检查共享给用户的就诊人中是否已有该证件号码
+func (r *PatientRepository) ExistsSharedByIDCard(userID int64, idCard string) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Patient{}).
+		Where("id_card = ?", idCard).
+		Where("id IN (SELECT patient_id FROM patient_members WHERE user_id = ?)", userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetSelfByUser 查询用户的本人就诊人
+func (r *PatientRepository) GetSelfByUser(userID int64) (*model.Patient, error) {
+	var patient model.Patient
+	err := r.db.Where("user_id = ? AND relation = ?", userID, model.RelationSelf).First(&patient).Error
+	if err != nil {
+		return nil, err
+	}
+	return &patient, nil
+}
+
+// GetVerifiedSelfByIDCard 查询证件号码对应的已实名本人就诊人（即证件持有人本人的账号）
+func (r *PatientRepository) GetVerifiedSelfByIDCard(idCard string) (*model.Patient, error) {
+	var patient model.Patient
+	err := r.db.Where("id_card = ? AND relation = ? AND verify_status = ?",
+		idCard, model.RelationSelf, model.PatientVerifyPassed).
+		Order("id ASC").
+		First(&patient).Error
+	if err != nil {
+		return nil, err
+	}
+	return &patient, nil
+}
+
+// ExistsSelfByIDCard 检查证件号码是否已被其他账号实名登记为本人
+// 未通过实名核验的本人登记不占用证件，避免先注册者冒用他人证件
+func (r *PatientRepository) ExistsSelfByIDCard(idCard string, excludeUserID int64) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Patient{}).
+		Where("id_card = ? AND relation = ? AND verify_status = ? AND user_id <> ?",
+			idCard, model.RelationSelf, model.PatientVerifyPassed, excludeUserID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Transfer 将就诊人转交给新的所有者并登记为本人（记录转交前的三要素核验结果）
+// 原有共享成员、待接受的邀请及病历查看授权随之失效，由新所有者重新决定共享
+func (r *PatientRepository) Transfer(verified *model.Patient, toUserID int64, isDefault int, now time.Time) error {
+	patientID := verified.ID
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("patient_id = ?", patientID).Delete(&model.PatientMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.PatientInvitation{}).
+			Where("patient_id = ? AND status = ?", patientID, model.PatientInvitationPending).
+			Update("status", model.PatientInvitationRevoked).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.PatientConsent{}).
+			Where("patient_id = ? AND revoked_at IS NULL", patientID).
+			Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		if isDefault == 1 {
+			if err := tx.Model(&model.Patient{}).
+				Where("user_id = ? AND is_default = 1", toUserID).
+				Update("is_default", 0).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&model.Patient{}).
+			Where("id = ?", patientID).
+			Updates(map[string]interface{}{
+				"user_id":       toUserID,
+				"relation":      model.RelationSelf,
+				"is_default":    isDefault,
+				"verify_status": verified.VerifyStatus,
+				"verified_at":   verified.VerifiedAt,
+				"verify_remark": verified.VerifyRemark,
+			}).Error
+	})
+}
+
 // CountByUser 统计用户的就诊人数量
 func (r *PatientRepository) CountByUser(userID int64) (int64, error) {
 	var count int64
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"errors"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// ReleaseRuleRepository 放号规则数据访问层
+type ReleaseRuleRepository struct {
+	db *gorm.DB
+}
+
+// NewReleaseRuleRepository 创建放号规则仓库实例
+func NewReleaseRuleRepository() *ReleaseRuleRepository {
+	return &ReleaseRuleRepository{db: database.GetDB()}
+}
+
+// Create 创建规则（连同放号阶段，使用事务）
+func (r *ReleaseRuleRepository) Create(rule *model.ReleaseRule) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return tx.Create(rule).Error
+	})
+}
+
+// Update 更新规则（整体替换放号阶段，使用事务）
+func (r *ReleaseRuleRepository) Update(rule *model.ReleaseRule) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("rule_id = ?", rule.ID).Delete(&model.ReleaseRuleStage{}).Error; err != nil {
+			return err
+		}
+		for i := range rule.Stages {
+			rule.Stages[i].ID = 0
+			rule.Stages[i].RuleID = rule.ID
+		}
+		if len(rule.Stages) > 0 {
+			if err := tx.Create(&rule.Stages).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Omit("Stages").Save(rule).Error
+	})
+}
+
+// Delete 删除规则（软删除）
+func (r *ReleaseRuleRepository) Delete(id int64) error {
+	return r.db.Delete(&model.ReleaseRule{}, id).Error
+}
+
+// GetByID 根据ID查询规则
+func (r *ReleaseRuleRepository) GetByID(id int64) (*model.ReleaseRule, error) {
+	var rule model.ReleaseRule
+	err := r.db.Preload("Stages").First(&rule, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// List 分页查询规则列表
+func (r *ReleaseRuleRepository) List(page, pageSize int, scopeType string) ([]model.ReleaseRule, int64, error) {
+	var rules []model.ReleaseRule
+	var total int64
+
+	query := r.db.Model(&model.ReleaseRule{})
+
+	if scopeType != "" {
+		query = query.Where("scope_type = ?", scopeType)
+	}
+
+	// 统计总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 分页查询
+	offset := (page - 1) * pageSize
+	err := query.Preload("Stages").
+		Order("id DESC").
+		Offset(offset).Limit(pageSize).
+		Find(&rules).Error
+
+	return rules, total, err
+}
+
+// ExistsByScope 检查同一范围是否已存在规则
+func (r *ReleaseRuleRepository) ExistsByScope(scopeType string, scopeID int64, excludeID ...int64) (bool, error) {
+	query := r.db.Model(&model.ReleaseRule{}).
+		Where("scope_type = ? AND scope_id = ?", scopeType, scopeID)
+
+	// 排除指定ID（用于更新时的检查）
+	if len(excludeID) > 0 && excludeID[0] > 0 {
+		query = query.Where("id != ?", excludeID[0])
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+// GetEffective 获取生效的规则（医生 > 科室 > 全院）
+// 未找到时返回 gorm.ErrRecordNotFound
+func (r *ReleaseRuleRepository) GetEffective(doctorID, departmentID int64) (*model.ReleaseRule, error) {
+	scopes := []struct {
+		scopeType string
+		scopeID   int64
+	}{
+		{model.ReleaseScopeDoctor, doctorID},
+		{model.ReleaseScopeDepartment, departmentID},
+		{model.ReleaseScopeGlobal, 0},
+	}
+
+	for _, scope := range scopes {
+		var rule model.ReleaseRule
+		err := r.db.Preload("Stages").
+			Where("scope_type = ? AND scope_id = ? AND status = ?", scope.scopeType, scope.scopeID, model.StatusEnabled).
+			First(&rule).Error
+		if err == nil {
+			return &rule, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"time"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// RoomRepository 诊室数据访问层
+type RoomRepository struct {
+	db *gorm.DB
+}
+
+// NewRoomRepository 创建诊室仓库实例
+func NewRoomRepository() *RoomRepository {
+	return &RoomRepository{db: database.GetDB()}
+}
+
+// Create 创建诊室
+func (r *RoomRepository) Create(room *model.Room) error {
+	return r.db.Create(room).Error
+}
+
+// Update 更新诊室
+func (r *RoomRepository) Update(room *model.Room) error {
+	return r.db.Omit("Campus", "Department").Save(room).Error
+}
+
+// Delete 删除诊室（软删除）
+func (r *RoomRepository) Delete(id int64) error {
+	return r.db.Delete(&model.Room{}, id).Error
+}
+
+// GetByID 根据ID查询诊室
+func (r *RoomRepository) GetByID(id int64) (*model.Room, error) {
+	var room model.Room
+	if err := r.db.Preload("Campus").Preload("Department").First(&room, id).Error; err != nil {
+		return nil, err
+	}
+	return &room, nil
+}
+
+// ExistsName 检查院区内诊室名称是否重复
+func (r *RoomRepository) ExistsName(campusID int64, name string, excludeID int64) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Room{}).
+		Where("campus_id = ? AND name = ? AND id != ?", campusID, name, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// List 分页查询诊室列表
+func (r *RoomRepository) List(page, pageSize int, campusID, departmentID *int64, keyword string, status *int) ([]model.Room, int64, error) {
+	var list []model.Room
+	var total int64
+
+	query := r.db.Model(&model.Room{})
+	if campusID != nil && *campusID > 0 {
+		query = query.Where("campus_id = ?", *campusID)
+	}
+	if departmentID != nil && *departmentID > 0 {
+		query = query.Where("department_id = ?", *departmentID)
+	}
+	if keyword != "" {
+		query = query.Where("name LIKE ? OR location LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+	}
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Preload("Campus").Preload("Department").
+		Order("campus_id ASC, sort_order ASC, id ASC").
+		Offset(offset).Limit(pageSize).
+		Find(&list).Error
+	return list, total, err
+}
+
+// HasSchedules 检查诊室是否有今日及以后的排班
+func (r *RoomRepository) HasSchedules(id int64, today time.Time) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Schedule{}).
+		Where("room_id = ? AND schedule_date >= ?", id, today).
+		Count(&count).Error
+	return count > 0, err
+}
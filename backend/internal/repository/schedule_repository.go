@@ -47,7 +47,7 @@ func (r *ScheduleRepository) Delete(id int64) error {
 // GetByID 根据ID查询排班
 func (r *ScheduleRepository) GetByID(id int64) (*model.Schedule, error) {
 	var schedule model.Schedule
-	err := r.db.Preload("Doctor.Department").First(&schedule, id).Error
+	err := r.db.Preload("Doctor.Department").Preload("Department").Preload("Campus").Preload("Room").First(&schedule, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -81,21 +81,25 @@ func (r *ScheduleRepository) Exists(doctorID int64, scheduleDate time.Time, peri
 }
 
 // List 分页查询排班列表（管理后台）
-func (r *ScheduleRepository) List(page, pageSize int, doctorID *int64, departmentID *int64, startDate, endDate *time.Time, status *int) ([]model.Schedule, int64, error) {
+func (r *ScheduleRepository) List(page, pageSize int, doctorID *int64, departmentID *int64, campusID *int64, startDate, endDate *time.Time, status *int) ([]model.Schedule, int64, error) {
 	var schedules []model.Schedule
 	var total int64
 
-	query := r.db.Model(&model.Schedule{}).Preload("Doctor.Department")
+	query := r.db.Model(&model.Schedule{}).Preload("Doctor.Department").Preload("Department").Preload("Campus").Preload("Room")
 
 	// 医生筛选
 	if doctorID != nil && *doctorID > 0 {
 		query = query.Where("doctor_id = ?", *doctorID)
 	}
 
-	// 科室筛选（通过关联查询）
+	// 科室筛选（按出诊科室）
 	if departmentID != nil && *departmentID > 0 {
-		query = query.Joins("JOIN doctors ON doctors.id = schedules.doctor_id").
-			Where("doctors.department_id = ?", *departmentID)
+		query = query.Where("schedules.department_id = ?", *departmentID)
+	}
+
+	// 院区筛选
+	if campusID != nil && *campusID > 0 {
+		query = query.Where("schedules.campus_id = ?", *campusID)
 	}
 
 	// 日期范围筛选
@@ -128,7 +132,7 @@ func (r *ScheduleRepository) List(page, pageSize int, doctorID *int64, departmen
 // ListByDoctor 查询医生的排班列表（公开接口）
 func (r *ScheduleRepository) ListByDoctor(doctorID int64, startDate, endDate time.Time) ([]model.Schedule, error) {
 	var schedules []model.Schedule
-	err := r.db.Preload("Doctor.Department").
+	err := r.db.Preload("Doctor.Department").Preload("Department").Preload("Campus").Preload("Room").
 		Where("doctor_id = ? AND schedule_date >= ? AND schedule_date <= ? AND status = ?",
 			doctorID, startDate, endDate, model.StatusEnabled).
 		Order("schedule_date ASC, period ASC").
@@ -137,11 +141,12 @@ func (r *ScheduleRepository) ListByDoctor(doctorID int64, startDate, endDate tim
 }
 
 // ListAvailable 查询可预约的排班列表（公开接口）
-func (r *ScheduleRepository) ListAvailable(doctorID *int64, departmentID *int64, startDate, endDate time.Time) ([]model.Schedule, error) {
+// 包含尚未放号的排班，由调用方展示"开放预约"时间
+func (r *ScheduleRepository) ListAvailable(doctorID *int64, departmentID *int64, campusID *int64, startDate, endDate time.Time) ([]model.Schedule, error) {
 	var schedules []model.Schedule
 
-	query := r.db.Preload("Doctor.Department").
-		Where("schedule_date >= ? AND schedule_date <= ? AND status = ? AND available_slots > 0",
+	query := r.db.Preload("Doctor.Department").Preload("Department").Preload("Campus").Preload("Room").
+		Where("schedule_date >= ? AND schedule_date <= ? AND schedules.status = ? AND (available_slots > 0 OR unreleased_slots > 0)",
 			startDate, endDate, model.StatusEnabled)
 
 	// 医生筛选
@@ -149,16 +154,41 @@ func (r *ScheduleRepository) ListAvailable(doctorID *int64, departmentID *int64,
 		query = query.Where("doctor_id = ?", *doctorID)
 	}
 
-	// 科室筛选
+	// 科室筛选（按出诊科室）
 	if departmentID != nil && *departmentID > 0 {
-		query = query.Joins("JOIN doctors ON doctors.id = schedules.doctor_id").
-			Where("doctors.department_id = ?", *departmentID)
+		query = query.Where("schedules.department_id = ?", *departmentID)
+	}
+
+	// 院区筛选
+	if campusID != nil && *campusID > 0 {
+		query = query.Where("schedules.campus_id = ?", *campusID)
 	}
 
 	err := query.Order("schedule_date ASC, period ASC").Find(&schedules).Error
 	return schedules, err
 }
 
+// ListUpcoming 批量查询医生/科室在日期范围内仍有号源（含未放号）的正常排班（用于搜索结果可预约提示）
+func (r *ScheduleRepository) ListUpcoming(doctorIDs, departmentIDs []int64, startDate, endDate time.Time) ([]model.Schedule, error) {
+	var schedules []model.Schedule
+	if len(doctorIDs) == 0 && len(departmentIDs) == 0 {
+		return schedules, nil
+	}
+
+	query := r.db.Model(&model.Schedule{}).
+		Where("schedule_date >= ? AND schedule_date <= ? AND status = ? AND (available_slots > 0 OR unreleased_slots > 0)",
+			startDate, endDate, model.StatusEnabled)
+	if len(doctorIDs) > 0 {
+		query = query.Where("doctor_id IN ?", doctorIDs)
+	}
+	if len(departmentIDs) > 0 {
+		query = query.Where("department_id IN ?", departmentIDs)
+	}
+
+	err := query.Order("schedule_date ASC, start_time ASC").Find(&schedules).Error
+	return schedules, err
+}
+
 // GetByDoctorAndDate 根据医生ID和日期查询排班
 func (r *ScheduleRepository) GetByDoctorAndDate(doctorID int64, scheduleDate time.Time, period string) (*model.Schedule, error) {
 	var schedule model.Schedule
@@ -214,3 +244,143 @@ func (r *ScheduleRepository) UpdateStatusByDoctor(doctorID int64, status int) er
 		Where("doctor_id = ? AND schedule_date >= ?", doctorID, time.Now()).
 		Update("status", status).Error
 }
+
+// ListDueRelease 查询已到放号时间的排班
+func (r *ScheduleRepository) ListDueRelease(now time.Time, limit int) ([]model.Schedule, error) {
+	var schedules []model.Schedule
+	err := r.db.Preload("Doctor").
+		Where("next_release_at IS NOT NULL AND next_release_at <= ? AND unreleased_slots > 0 AND status = ? AND schedule_date >= ?",
+			now, model.StatusEnabled, time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())).
+		Order("next_release_at ASC").
+		Limit(limit).
+		Find(&schedules).Error
+	return schedules, err
+}
+
+// ReleaseSlots 放出号源（乐观锁：仅当未放出号源数与预期一致时更新）
+// 返回是否更新成功
+func (r *ScheduleRepository) ReleaseSlots(id int64, expectedUnreleased, delta int, nextReleaseAt *time.Time) (bool, error) {
+	result := r.db.Model(&model.Schedule{}).
+		Where("id = ? AND unreleased_slots = ?", id, expectedUnreleased).
+		Updates(map[string]interface{}{
+			"available_slots":  gorm.Expr("available_slots + ?", delta),
+			"unreleased_slots": gorm.Expr("unreleased_slots - ?", delta),
+			"next_release_at":  nextReleaseAt,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// channelAvailableColumns 渠道剩余号源字段
+var channelAvailableColumns = map[string]string{
+	model.ChannelOnsite: "onsite_available",
+	model.ChannelVIP:    "vip_available",
+}
+
+// channelSlotsColumns 渠道预留号源字段
+var channelSlotsColumns = map[string]string{
+	model.ChannelOnsite: "onsite_slots",
+	model.ChannelVIP:    "vip_slots",
+}
+
+// DecrChannelSlot 扣减渠道号源（在事务中调用，原子操作）
+// 线上渠道扣减公共号源池；现场/VIP渠道在预留号源未转入公共池前扣减本渠道号源，
+// 转入后扣减公共号源池。返回实际扣减的号源来源，号源不足时返回空字符串
+func (r *ScheduleRepository) DecrChannelSlot(tx *gorm.DB, id int64, channel string) (string, error) {
+	if column, ok := channelAvailableColumns[channel]; ok {
+		result := tx.Model(&model.Schedule{}).
+			Where("id = ? AND "+column+" > 0 AND quota_rolled_over = ?", id, false).
+			Update(column, gorm.Expr(column+" - 1"))
+		if result.Error != nil {
+			return "", result.Error
+		}
+		if result.RowsAffected > 0 {
+			return channel, nil
+		}
+
+		// 预留号源已转入公共池时，从公共号源池扣减
+		result = tx.Model(&model.Schedule{}).
+			Where("id = ? AND available_slots > 0 AND quota_rolled_over = ?", id, true).
+			Update("available_slots", gorm.Expr("available_slots - 1"))
+		if result.Error != nil {
+			return "", result.Error
+		}
+		if result.RowsAffected > 0 {
+			return model.ChannelOnline, nil
+		}
+		return "", nil
+	}
+
+	result := tx.Model(&model.Schedule{}).
+		Where("id = ? AND available_slots > 0", id).
+		Update("available_slots", gorm.Expr("available_slots - 1"))
+	if result.Error != nil {
+		return "", result.Error
+	}
+	if result.RowsAffected > 0 {
+		return model.ChannelOnline, nil
+	}
+	return "", nil
+}
+
+// ReturnChannelSlot 返还渠道号源（取消预约时使用）
+// 预留号源已转入公共池时，现场/VIP号源返还到公共号源池，并同步扣减该渠道预留数
+func (r *ScheduleRepository) ReturnChannelSlot(tx *gorm.DB, id int64, slotChannel string) error {
+	availableColumn, ok := channelAvailableColumns[slotChannel]
+	if !ok {
+		return tx.Model(&model.Schedule{}).
+			Where("id = ?", id).
+			Update("available_slots", gorm.Expr("available_slots + 1")).Error
+	}
+	slotsColumn := channelSlotsColumns[slotChannel]
+
+	// MySQL 按书写顺序计算 SET 子句，各字段只依赖 quota_rolled_over，顺序无影响
+	return tx.Exec("UPDATE schedules SET "+
+		availableColumn+" = IF(quota_rolled_over, "+availableColumn+", "+availableColumn+" + 1), "+
+		slotsColumn+" = IF(quota_rolled_over, "+slotsColumn+" - 1, "+slotsColumn+"), "+
+		"available_slots = IF(quota_rolled_over, available_slots + 1, available_slots) "+
+		"WHERE id = ?", id).Error
+}
+
+// ListDueRollover 查询已到预留号源转入时间的排班
+func (r *ScheduleRepository) ListDueRollover(now time.Time, limit int) ([]model.Schedule, error) {
+	var schedules []model.Schedule
+	err := r.db.Where("quota_rollover_at IS NOT NULL AND quota_rollover_at <= ? AND quota_rolled_over = ?", now, false).
+		Order("quota_rollover_at ASC").
+		Limit(limit).
+		Find(&schedules).Error
+	return schedules, err
+}
+
+// RolloverQuota 将未用完的现场/VIP预留号源转入公共号源池
+// 预留数扣减为已使用数，剩余数清零；返回是否更新成功
+func (r *ScheduleRepository) RolloverQuota(id int64) (bool, error) {
+	// MySQL 按书写顺序计算 SET 子句，剩余数必须最后清零
+	result := r.db.Exec("UPDATE schedules SET "+
+		"available_slots = available_slots + onsite_available + vip_available, "+
+		"onsite_slots = onsite_slots - onsite_available, "+
+		"vip_slots = vip_slots - vip_available, "+
+		"onsite_available = 0, "+
+		"vip_available = 0, "+
+		"quota_rolled_over = ? "+
+		"WHERE id = ? AND quota_rolled_over = ? AND deleted_at IS NULL", true, id, false)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListByRange 查询日期范围内的排班（不分页，用于复制排班）
+// doctorID、departmentID 为空时查询全院
+func (r *ScheduleRepository) ListByRange(doctorID, departmentID *int64, startDate, endDate time.Time) ([]model.Schedule, error) {
+	var schedules []model.Schedule
+
+	query := r.db.Model(&model.Schedule{}).Preload("Doctor.Department").Preload("Department").Preload("Campus").Preload("Room").
+		Where("schedule_date >= ? AND schedule_date <= ?", startDate, endDate)
+
+	if doctorID != nil && *doctorID > 0 {
+		query = query.Where("doctor_id = ?", *doctorID)
+	}
+	if departmentID != nil && *departmentID > 0 {
+		query = query.Where("schedules.department_id = ?", *departmentID)
+	}
+
+	err := query.Order("schedule_date ASC, doctor_id ASC, period ASC").Find(&schedules).Error
+	return schedules, err
+}
@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+
+	"huaan-medical/internal/model"
+)
+
+// scheduleRow 排班号源字段快照，键为列名，quota_rolled_over 以 0/1 表示
+type scheduleRow map[string]int
+
+// newDryRunScheduleRepository 创建不连接数据库的排班仓库，执行的 UPDATE 语句按 MySQL 规则应用到 row
+func newDryRunScheduleRepository(t *testing.T, row scheduleRow) *ScheduleRepository {
+	t.Helper()
+	db, err := gorm.Open(mysql.New(mysql.Config{SkipInitializeWithVersion: true}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatalf("open dry-run db: %v", err)
+	}
+	apply := func(db *gorm.DB) {
+		applyScheduleUpdate(t, db.Statement.SQL.String(), db.Statement.Vars, row)
+	}
+	if err := db.Callback().Raw().After("gorm:raw").Register("test:apply_raw", apply); err != nil {
+		t.Fatalf("register raw callback: %v", err)
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("test:apply_update", apply); err != nil {
+		t.Fatalf("register update callback: %v", err)
+	}
+	return &ScheduleRepository{db: db}
+}
+
+// applyScheduleUpdate 按 MySQL 单表 UPDATE 的语义（SET 子句从左到右依次计算，后者可见前者结果）
+// 将语句应用到 row；仅支持仓库中使用的列、整数加减、? 占位符及 IF(quota_rolled_over, a, b)
+func applyScheduleUpdate(t *testing.T, sql string, vars []interface{}, row scheduleRow) {
+	t.Helper()
+	sql = strings.ReplaceAll(sql, "`", "")
+	sql = strings.ReplaceAll(sql, "schedules.", "")
+	setStart := strings.Index(sql, " SET ")
+	setEnd := strings.Index(sql, " WHERE ")
+	if setStart < 0 || setEnd < 0 {
+		t.Fatalf("unexpected sql: %s", sql)
+	}
+
+	argIndex := 0
+	nextArg := func() interface{} {
+		if argIndex >= len(vars) {
+			t.Fatalf("not enough vars for sql: %s", sql)
+		}
+		v := vars[argIndex]
+		argIndex++
+		return v
+	}
+
+	var eval func(expr string) int
+	eval = func(expr string) int {
+		expr = strings.TrimSpace(expr)
+		if strings.HasPrefix(expr, "IF(") && strings.HasSuffix(expr, ")") {
+			parts := splitTopLevel(expr[3:len(expr)-1], ',')
+			if len(parts) != 3 || strings.TrimSpace(parts[0]) != "quota_rolled_over" {
+				t.Fatalf("unsupported IF: %s", expr)
+			}
+			if row["quota_rolled_over"] != 0 {
+				return eval(parts[1])
+			}
+			return eval(parts[2])
+		}
+		tokens := strings.Fields(expr)
+		total := evalOperand(t, tokens[0], row, nextArg)
+		for i := 1; i+1 < len(tokens); i += 2 {
+			operand := evalOperand(t, tokens[i+1], row, nextArg)
+			switch tokens[i] {
+			case "+":
+				total += operand
+			case "-":
+				total -= operand
+			default:
+				t.Fatalf("unsupported operator %q in %s", tokens[i], expr)
+			}
+		}
+		return total
+	}
+
+	for _, assignment := range splitTopLevel(sql[setStart+len(" SET "):setEnd], ',') {
+		column, expr, ok := strings.Cut(assignment, "=")
+		if !ok {
+			t.Fatalf("unexpected assignment: %s", assignment)
+		}
+		column = strings.TrimSpace(column)
+		if column == "updated_at" {
+			nextArg()
+			continue
+		}
+		row[column] = eval(expr)
+	}
+}
+
+// evalOperand 计算单个操作数：整数、列名或占位符
+func evalOperand(t *testing.T, token string, row scheduleRow, nextArg func() interface{}) int {
+	t.Helper()
+	if token == "?" {
+		switch v := nextArg().(type) {
+		case bool:
+			if v {
+				return 1
+			}
+			return 0
+		case int:
+			return v
+		default:
+			t.Fatalf("unsupported var %T", v)
+		}
+	}
+	if n, err := strconv.Atoi(token); err == nil {
+		return n
+	}
+	value, ok := row[token]
+	if !ok {
+		t.Fatalf("unknown column %q", token)
+	}
+	return value
+}
+
+// splitTopLevel 按括号外的分隔符切分
+func splitTopLevel(s string, sep rune) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case sep:
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func TestScheduleRepositoryReturnChannelSlot(t *testing.T) {
+	base := scheduleRow{
+		"available_slots":  5,
+		"onsite_slots":     4,
+		"onsite_available": 1,
+		"vip_slots":        2,
+		"vip_available":    0,
+	}
+
+	tests := []struct {
+		name       string
+		channel    string
+		rolledOver bool
+		want       scheduleRow
+	}{
+		{
+			"线上号源返还公共池", model.ChannelOnline, false,
+			scheduleRow{"available_slots": 6, "onsite_slots": 4, "onsite_available": 1, "vip_slots": 2, "vip_available": 0},
+		},
+		{
+			"现场号源返还现场剩余", model.ChannelOnsite, false,
+			scheduleRow{"available_slots": 5, "onsite_slots": 4, "onsite_available": 2, "vip_slots": 2, "vip_available": 0},
+		},
+		{
+			"VIP号源返还VIP剩余", model.ChannelVIP, false,
+			scheduleRow{"available_slots": 5, "onsite_slots": 4, "onsite_available": 1, "vip_slots": 2, "vip_available": 1},
+		},
+		{
+			"转入后现场号源返还公共池并扣减预留", model.ChannelOnsite, true,
+			scheduleRow{"available_slots": 6, "onsite_slots": 3, "onsite_available": 1, "vip_slots": 2, "vip_available": 0},
+		},
+		{
+			"转入后VIP号源返还公共池并扣减预留", model.ChannelVIP, true,
+			scheduleRow{"available_slots": 6, "onsite_slots": 4, "onsite_available": 1, "vip_slots": 1, "vip_available": 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := scheduleRow{}
+			for k, v := range base {
+				row[k] = v
+			}
+			if tt.rolledOver {
+				row["quota_rolled_over"] = 1
+			}
+			repo := newDryRunScheduleRepository(t, row)
+			if err := repo.ReturnChannelSlot(repo.db, 1, tt.channel); err != nil {
+				t.Fatalf("ReturnChannelSlot: %v", err)
+			}
+			for column, want := range tt.want {
+				if row[column] != want {
+					t.Errorf("%s = %d, want %d", column, row[column], want)
+				}
+			}
+		})
+	}
+}
+
+func TestScheduleRepositoryRolloverQuota(t *testing.T) {
+	tests := []struct {
+		name string
+		row  scheduleRow
+		want scheduleRow
+	}{
+		{
+			"剩余预留号源转入公共池",
+			scheduleRow{"available_slots": 3, "onsite_slots": 5, "onsite_available": 2, "vip_slots": 4, "vip_available": 1},
+			scheduleRow{"available_slots": 6, "onsite_slots": 3, "onsite_available": 0, "vip_slots": 3, "vip_available": 0, "quota_rolled_over": 1},
+		},
+		{
+			"预留号源已用完",
+			scheduleRow{"available_slots": 0, "onsite_slots": 2, "onsite_available": 0, "vip_slots": 1, "vip_available": 0},
+			scheduleRow{"available_slots": 0, "onsite_slots": 2, "onsite_available": 0, "vip_slots": 1, "vip_available": 0, "quota_rolled_over": 1},
+		},
+		{
+			"未使用的预留号源全部转入",
+			scheduleRow{"available_slots": 10, "onsite_slots": 4, "onsite_available": 4, "vip_slots": 0, "vip_available": 0},
+			scheduleRow{"available_slots": 14, "onsite_slots": 0, "onsite_available": 0, "vip_slots": 0, "vip_available": 0, "quota_rolled_over": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := tt.row
+			row["quota_rolled_over"] = 0
+			repo := newDryRunScheduleRepository(t, row)
+			if _, err := repo.RolloverQuota(1); err != nil {
+				t.Fatalf("RolloverQuota: %v", err)
+			}
+			for column, want := range tt.want {
+				if row[column] != want {
+					t.Errorf("%s = %d, want %d", column, row[column], want)
+				}
+			}
+		})
+	}
+}
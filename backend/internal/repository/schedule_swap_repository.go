@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"huaan-medical/internal/model"
+	"huaan-medical/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// ScheduleSwapRepository 换班申请数据访问层
+type ScheduleSwapRepository struct {
+	db *gorm.DB
+}
+
+// NewScheduleSwapRepository 创建换班申请仓库实例
+func NewScheduleSwapRepository() *ScheduleSwapRepository {
+	return &ScheduleSwapRepository{db: database.GetDB()}
+}
+
+// Create 创建换班申请
+func (r *ScheduleSwapRepository) Create(swap *model.ScheduleSwap) error {
+	return r.db.Create(swap).Error
+}
+
+// GetByID 根据ID查询换班申请
+func (r *ScheduleSwapRepository) GetByID(id int64) (*model.ScheduleSwap, error) {
+	var swap model.ScheduleSwap
+	err := r.db.Preload("RequesterDoctor").
+		Preload("TargetDoctor").
+		Preload("Schedule").
+		Preload("TargetSchedule").
+		First(&swap, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &swap, nil
+}
+
+// List 分页查询换班申请
+// doctorID 不为空时查询该医生发起或收到的申请
+func (r *ScheduleSwapRepository) List(page, pageSize int, status string, doctorID, departmentID *int64) ([]model.ScheduleSwap, int64, error) {
+	var swaps []model.ScheduleSwap
+	var total int64
+
+	query := r.db.Model(&model.ScheduleSwap{})
+
+	if status != "" {
+		query = query.Where("schedule_swaps.status = ?", status)
+	}
+	if doctorID != nil && *doctorID > 0 {
+		query = query.Where("(requester_doctor_id = ? OR target_doctor_id = ?)", *doctorID, *doctorID)
+	}
+	if departmentID != nil && *departmentID > 0 {
+		query = query.Joins("JOIN schedules ON schedules.id = schedule_swaps.schedule_id").
+			Where("schedules.department_id = ?", *departmentID)
+	}
+
+	// 统计总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 分页查询
+	offset := (page - 1) * pageSize
+	err := query.Preload("RequesterDoctor").
+		Preload("TargetDoctor").
+		Preload("Schedule").
+		Preload("TargetSchedule").
+		Order("schedule_swaps.id DESC").
+		Offset(offset).Limit(pageSize).
+		Find(&swaps).Error
+
+	return swaps, total, err
+}
+
+// ExistsOpenBySchedule 检查排班是否存在进行中的换班申请
+func (r *ScheduleSwapRepository) ExistsOpenBySchedule(scheduleIDs []int64, excludeID ...int64) (bool, error) {
+	query := r.db.Model(&model.ScheduleSwap{}).
+		Where("status IN ?", []string{model.ScheduleSwapStatusPending, model.ScheduleSwapStatusAccepted}).
+		Where("(schedule_id IN ? OR target_schedule_id IN ?)", scheduleIDs, scheduleIDs)
+
+	// 排除指定ID
+	if len(excludeID) > 0 && excludeID[0] > 0 {
+		query = query.Where("id != ?", excludeID[0])
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+// UpdateStatus 更新申请状态（乐观锁：仅当当前状态与预期一致时更新）
+// 返回是否更新成功
+func (r *ScheduleSwapRepository) UpdateStatus(tx *gorm.DB, id int64, fromStatus, toStatus string, extraFields map[string]interface{}) (bool, error) {
+	if tx == nil {
+		tx = r.db
+	}
+
+	updates := map[string]interface{}{
+		"status": toStatus,
+	}
+	for k, v := range extraFields {
+		updates[k] = v
+	}
+
+	result := tx.Model(&model.ScheduleSwap{}).
+		Where("id = ? AND status = ?", id, fromStatus).
+		Updates(updates)
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"huaan-medical/internal/model"
+	"huaan-medical/pkg/database"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SearchRepository 搜索索引数据访问层
+type SearchRepository struct {
+	db *gorm.DB
+}
+
+// NewSearchRepository 创建搜索索引仓库实例
+func NewSearchRepository() *SearchRepository {
+	return &SearchRepository{db: database.GetDB()}
+}
+
+// Upsert 写入或更新索引文档
+func (r *SearchRepository) Upsert(doc *model.SearchDocument) error {
+	return r.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(doc).Error
+}
+
+// Delete 删除索引文档
+func (r *SearchRepository) Delete(entityType string, entityID int64) error {
+	return r.db.Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Delete(&model.SearchDocument{}).Error
+}
+
+// ReplaceAll 全量替换索引文档（事务内先清空再写入）
+func (r *SearchRepository) ReplaceAll(docs []model.SearchDocument) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&model.SearchDocument{}).Error; err != nil {
+			return err
+		}
+		if len(docs) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(docs, 200).Error
+	})
+}
+
+// Search 按检索词查询可检索的索引文档（任一检索词命中名称/全拼/首字母/关键词即返回）
+func (r *SearchRepository) Search(entityType string, terms []string, limit int) ([]model.SearchDocument, error) {
+	var docs []model.SearchDocument
+	if len(terms) == 0 {
+		return docs, nil
+	}
+
+	cond := r.db.Where("1 = 0")
+	for _, term := range terms {
+		like := "%" + term + "%"
+		cond = cond.Or("name LIKE ? OR pinyin LIKE ? OR initials LIKE ? OR keywords LIKE ?", like, like, like, like)
+	}
+
+	query := r.db.Model(&model.SearchDocument{}).Where("status = ?", model.StatusEnabled).Where(cond)
+	if entityType != "" {
+		query = query.Where("entity_type = ?", entityType)
+	}
+
+	err := query.Order("sort_order ASC, entity_id ASC").Limit(limit).Find(&docs).Error
+	return docs, err
+}
+
+// CreateSynonym 创建同义词组
+func (r *SearchRepository) CreateSynonym(synonym *model.SearchSynonym) error {
+	return r.db.Create(synonym).Error
+}
+
+// UpdateSynonym 更新同义词组
+func (r *SearchRepository) UpdateSynonym(synonym *model.SearchSynonym) error {
+	return r.db.Save(synonym).Error
+}
+
+// DeleteSynonym 删除同义词组
+func (r *SearchRepository) DeleteSynonym(id int64) error {
+	return r.db.Delete(&model.SearchSynonym{}, id).Error
+}
+
+// GetSynonym 根据ID查询同义词组
+func (r *SearchRepository) GetSynonym(id int64) (*model.SearchSynonym, error) {
+	var synonym model.SearchSynonym
+	if err := r.db.First(&synonym, id).Error; err != nil {
+		return nil, err
+	}
+	return &synonym, nil
+}
+
+// ListSynonyms 分页查询同义词组
+func (r *SearchRepository) ListSynonyms(page, pageSize int, keyword string, status *int) ([]model.SearchSynonym, int64, error) {
+	var list []model.SearchSynonym
+	var total int64
+
+	query := r.db.Model(&model.SearchSynonym{})
+	if keyword != "" {
+		query = query.Where("words LIKE ?", "%"+keyword+"%")
+	}
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&list).Error
+	return list, total, err
+}
+
+// ListEnabledSynonyms 查询全部启用的同义词组
+func (r *SearchRepository) ListEnabledSynonyms() ([]model.SearchSynonym, error) {
+	var list []model.SearchSynonym
+	err := r.db.Where("status = ?", model.StatusEnabled).Find(&list).Error
+	return list, err
+}
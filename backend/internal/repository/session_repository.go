@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"time"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// SessionRepository 用户登录会话数据访问层
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+// NewSessionRepository 创建会话仓库实例
+func NewSessionRepository() *SessionRepository {
+	return &SessionRepository{
+		db: database.GetDB(),
+	}
+}
+
+// Create 创建会话
+func (r *SessionRepository) Create(session *model.UserSession) error {
+	return r.db.Create(session).Error
+}
+
+// GetBySessionID 根据会话ID查询
+func (r *SessionRepository) GetBySessionID(sessionID string) (*model.UserSession, error) {
+	var session model.UserSession
+	if err := r.db.Where("session_id = ?", sessionID).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetByUserAndID 查询用户本人的会话
+func (r *SessionRepository) GetByUserAndID(userID, id int64) (*model.UserSession, error) {
+	var session model.UserSession
+	if err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Rotate 轮换刷新Token：仅当会话未吊销且当前刷新Token标识仍为 oldRefreshID 时更新，
+// 返回 false 表示该刷新Token已被使用过（或会话已吊销）
+func (r *SessionRepository) Rotate(id int64, oldRefreshID, newRefreshID, ip string, expiresAt, now time.Time) (bool, error) {
+	result := r.db.Model(&model.UserSession{}).
+		Where("id = ? AND refresh_id = ? AND revoked_at IS NULL", id, oldRefreshID).
+		Updates(map[string]interface{}{
+			"refresh_id":     newRefreshID,
+			"last_active_at": now,
+			"last_ip":        ip,
+			"expires_at":     expiresAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Revoke 吊销会话
+func (r *SessionRepository) Revoke(id int64, reason string, now time.Time) error {
+	return r.db.Model(&model.UserSession{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"revoked_at":    now,
+			"revoke_reason": reason,
+		}).Error
+}
+
+// ListActiveByUser 查询用户有效会话（since 非空时仅返回其后创建的会话）
+func (r *SessionRepository) ListActiveByUser(userID int64, since *time.Time, now time.Time) ([]model.UserSession, error) {
+	query := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now)
+	if since != nil {
+		query = query.Where("created_at > ?", *since)
+	}
+
+	var list []model.UserSession
+	err := query.Order("last_active_at DESC").Find(&list).Error
+	return list, err
+}
+
+// RevokeByUser 吊销用户全部有效会话（exceptSessionID 非空时保留该会话），返回被吊销的会话ID
+func (r *SessionRepository) RevokeByUser(userID int64, exceptSessionID, reason string, now time.Time) ([]string, error) {
+	var sessionIDs []string
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&model.UserSession{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+		if exceptSessionID != "" {
+			query = query.Where("session_id <> ?", exceptSessionID)
+		}
+		if err := query.Pluck("session_id", &sessionIDs).Error; err != nil {
+			return err
+		}
+		if len(sessionIDs) == 0 {
+			return nil
+		}
+		return tx.Model(&model.UserSession{}).
+			Where("session_id IN ? AND revoked_at IS NULL", sessionIDs).
+			Updates(map[string]interface{}{
+				"revoked_at":    now,
+				"revoke_reason": reason,
+			}).Error
+	})
+	return sessionIDs, err
+}
+
+// DeleteStale 物理删除过期或吊销早于 before 的会话
+func (r *SessionRepository) DeleteStale(before time.Time) (int64, error) {
+	result := r.db.Unscoped().
+		Where("expires_at < ? OR revoked_at < ?", before, before).
+		Delete(&model.UserSession{})
+	return result.RowsAffected, result.Error
+}
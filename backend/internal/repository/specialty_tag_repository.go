@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"huaan-medical/internal/model"
+	"huaan-medical/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// SpecialtyTagRepository 擅长领域标签数据访问层
+type SpecialtyTagRepository struct {
+	db *gorm.DB
+}
+
+// NewSpecialtyTagRepository 创建擅长领域标签仓库实例
+func NewSpecialtyTagRepository() *SpecialtyTagRepository {
+	return &SpecialtyTagRepository{db: database.GetDB()}
+}
+
+// Create 创建标签
+func (r *SpecialtyTagRepository) Create(tag *model.SpecialtyTag) error {
+	return r.db.Create(tag).Error
+}
+
+// Update 更新标签
+func (r *SpecialtyTagRepository) Update(tag *model.SpecialtyTag) error {
+	return r.db.Save(tag).Error
+}
+
+// Delete 删除标签及医生关联（物理删除，释放唯一名称）
+func (r *SpecialtyTagRepository) Delete(id int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("specialty_tag_id = ?", id).Delete(&model.DoctorSpecialtyTag{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&model.SpecialtyTag{}, id).Error
+	})
+}
+
+// GetByID 根据ID查询标签
+func (r *SpecialtyTagRepository) GetByID(id int64) (*model.SpecialtyTag, error) {
+	var tag model.SpecialtyTag
+	if err := r.db.First(&tag, id).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// GetByName 根据名称查询标签
+func (r *SpecialtyTagRepository) GetByName(name string) (*model.SpecialtyTag, error) {
+	var tag model.SpecialtyTag
+	if err := r.db.Where("name = ?", name).First(&tag).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// List 分页查询标签
+func (r *SpecialtyTagRepository) List(page, pageSize int, keyword string, status *int) ([]model.SpecialtyTag, int64, error) {
+	var list []model.SpecialtyTag
+	var total int64
+
+	query := r.db.Model(&model.SpecialtyTag{})
+	if keyword != "" {
+		query = query.Where("name LIKE ?", "%"+keyword+"%")
+	}
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Order("sort_order ASC, id ASC").
+		Offset(offset).Limit(pageSize).
+		Find(&list).Error
+	return list, total, err
+}
+
+// ListEnabled 查询全部启用的标签
+func (r *SpecialtyTagRepository) ListEnabled() ([]model.SpecialtyTag, error) {
+	var list []model.SpecialtyTag
+	err := r.db.Where("status = ?", model.StatusEnabled).
+		Order("sort_order ASC, id ASC").
+		Find(&list).Error
+	return list, err
+}
+
+// FindByIDs 根据ID批量查询标签
+func (r *SpecialtyTagRepository) FindByIDs(ids []int64) ([]model.SpecialtyTag, error) {
+	var list []model.SpecialtyTag
+	if len(ids) == 0 {
+		return list, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&list).Error
+	return list, err
+}
+
+// ListDoctorIDs 查询带有指定标签的医生ID
+func (r *SpecialtyTagRepository) ListDoctorIDs(tagID int64) ([]int64, error) {
+	var ids []int64
+	err := r.db.Model(&model.DoctorSpecialtyTag{}).Where("specialty_tag_id = ?", tagID).Pluck("doctor_id", &ids).Error
+	return ids, err
+}
+
+// CountDoctors 批量统计标签下正常出诊的医生数
+func (r *SpecialtyTagRepository) CountDoctors(tagIDs []int64) (map[int64]int64, error) {
+	result := make(map[int64]int64, len(tagIDs))
+	if len(tagIDs) == 0 {
+		return result, nil
+	}
+
+	var rows []struct {
+		SpecialtyTagID int64
+		Count          int64
+	}
+	err := r.db.Model(&model.DoctorSpecialtyTag{}).
+		Select("doctor_specialty_tags.specialty_tag_id, COUNT(*) as count").
+		Joins("JOIN doctors ON doctors.id = doctor_specialty_tags.doctor_id AND doctors.deleted_at IS NULL").
+		Where("doctor_specialty_tags.specialty_tag_id IN ? AND doctors.status = ?", tagIDs, model.StatusEnabled).
+		Group("doctor_specialty_tags.specialty_tag_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		result[row.SpecialtyTagID] = row.Count
+	}
+	return result, nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"huaan-medical/internal/model"
+	"huaan-medical/pkg/database"
+
+	"gorm.io/gorm"
+)
+
+// TriageRepository 导诊规则数据访问层
+type TriageRepository struct {
+	db *gorm.DB
+}
+
+// NewTriageRepository 创建导诊规则仓库实例
+func NewTriageRepository() *TriageRepository {
+	return &TriageRepository{db: database.GetDB()}
+}
+
+// Create 创建导诊规则
+func (r *TriageRepository) Create(rule *model.TriageRule) error {
+	return r.db.Create(rule).Error
+}
+
+// Update 更新导诊规则
+func (r *TriageRepository) Update(rule *model.TriageRule) error {
+	return r.db.Omit("Department").Save(rule).Error
+}
+
+// Delete 删除导诊规则
+func (r *TriageRepository) Delete(id int64) error {
+	return r.db.Delete(&model.TriageRule{}, id).Error
+}
+
+// GetByID 根据ID查询导诊规则
+func (r *TriageRepository) GetByID(id int64) (*model.TriageRule, error) {
+	var rule model.TriageRule
+	if err := r.db.Preload("Department").First(&rule, id).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// List 分页查询导诊规则
+func (r *TriageRepository) List(page, pageSize int, keyword string, departmentID *int64, status *int) ([]model.TriageRule, int64, error) {
+	var list []model.TriageRule
+	var total int64
+
+	query := r.db.Model(&model.TriageRule{})
+	if keyword != "" {
+		query = query.Where("keywords LIKE ? OR explanation LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
+	}
+	if departmentID != nil && *departmentID > 0 {
+		query = query.Where("department_id = ?", *departmentID)
+	}
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Preload("Department").
+		Order("department_id ASC, weight DESC, id ASC").
+		Offset(offset).Limit(pageSize).
+		Find(&list).Error
+	return list, total, err
+}
+
+// ListEnabled 查询全部启用的导诊规则（含科室信息）
+func (r *TriageRepository) ListEnabled() ([]model.TriageRule, error) {
+	var list []model.TriageRule
+	err := r.db.Preload("Department").
+		Where("status = ?", model.StatusEnabled).
+		Order("weight DESC, id ASC").
+		Find(&list).Error
+	return list, err
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"huaan-medical/internal/model"
 	"huaan-medical/pkg/database"
 
@@ -107,3 +109,67 @@ func (r *UserRepository) UnblockUser(id int64) error {
 	return r.db.Model(&model.User{}).Where("id = ?", id).
 		Update("blocked_until", nil).Error
 }
+
+// UpdateFields 更新用户指定字段（值为 nil 时写入 NULL）
+func (r *UserRepository) UpdateFields(id int64, updates map[string]interface{}) error {
+	return r.db.Model(&model.User{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// UpdatePassword 更新用户密码，并吊销此前签发的所有Token
+func (r *UserRepository) UpdatePassword(id int64, hashedPassword string, revokedAt time.Time) error {
+	return r.db.Model(&model.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"password":          hashedPassword,
+		"tokens_revoked_at": revokedAt,
+	}).Error
+}
+
+// ListAdmin 分页查询用户账号（管理后台）
+// keyword 匹配手机号、用户名、昵称；blocked 为 true 时仅返回封禁中的账号
+func (r *UserRepository) ListAdmin(page, pageSize int, keyword string, status *int, blocked bool, now time.Time) ([]model.User, int64, error) {
+	var users []model.User
+	var total int64
+
+	query := r.db.Model(&model.User{})
+	if keyword != "" {
+		like := "%" + keyword + "%"
+		query = query.Where("phone LIKE ? OR username LIKE ? OR nickname LIKE ?", like, like, like)
+	}
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+	if blocked {
+		query = query.Where("blocked_until > ?", now)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Order("id DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&users).Error
+
+	return users, total, err
+}
+
+// UpdateWithAdminAction 更新用户字段并记录管理员操作（同一事务）
+func (r *UserRepository) UpdateWithAdminAction(id int64, updates map[string]interface{}, action *model.UserAdminAction) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.User{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return err
+		}
+		return tx.Create(action).Error
+	})
+}
+
+// ListAdminActions 查询用户账号最近的管理员操作记录
+func (r *UserRepository) ListAdminActions(userID int64, limit int) ([]model.UserAdminAction, error) {
+	var list []model.UserAdminAction
+	err := r.db.Where("user_id = ?", userID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&list).Error
+	return list, err
+}
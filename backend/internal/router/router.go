@@ -43,7 +43,15 @@ func Setup(mode string) *gin.Engine {
 	scheduleHandler := handler.NewScheduleHandler()
 	uploadHandler := handler.NewUploadHandler()
 	userHandler := handler.NewUserHandler()
+	accountHandler := handler.NewAccountHandler()
+	accountLinkHandler := handler.NewAccountLinkHandler()
+	sessionHandler := handler.NewSessionHandler()
 	patientHandler := handler.NewPatientHandler()
+	patientShareHandler := handler.NewPatientShareHandler()
+	patientGuardianHandler := handler.NewPatientGuardianHandler()
+	patientDuplicateHandler := handler.NewPatientDuplicateHandler()
+	userAdminHandler := handler.NewUserAdminHandler()
+	agreementHandler := handler.NewAgreementHandler()
 	tokenHandler := handler.NewTokenHandler()
 	appointmentHandler := handler.NewAppointmentHandler()
 	medicalRecordHandler := handler.NewMedicalRecordHandler()
@@ -53,25 +61,39 @@ func Setup(mode string) *gin.Engine {
 	adminManageHandler := handler.NewAdminManageHandler()
 	roleHandler := handler.NewRoleHandler()
 	permissionHandler := handler.NewPermissionHandler()
+	releaseRuleHandler := handler.NewReleaseRuleHandler()
+	scheduleSwapHandler := handler.NewScheduleSwapHandler()
+	notificationHandler := handler.NewNotificationHandler()
+	doctorPortalHandler := handler.NewDoctorPortalHandler()
+	doctorAccountHandler := handler.NewDoctorAccountHandler()
+	doctorLeaveHandler := handler.NewDoctorLeaveHandler()
+	doctorReviewHandler := handler.NewDoctorReviewHandler()
+	searchHandler := handler.NewSearchHandler()
+	triageHandler := handler.NewTriageHandler()
+	campusHandler := handler.NewCampusHandler()
+	doctorProfileHandler := handler.NewDoctorProfileHandler()
 
 	// API路由组
 	api := r.Group("/api")
 	{
 		// 公开接口（无需认证）
-		setupPublicRoutes(api, deptHandler, doctorHandler, scheduleHandler, userHandler, smsHandler)
+		setupPublicRoutes(api, deptHandler, doctorHandler, scheduleHandler, userHandler, smsHandler, doctorReviewHandler, searchHandler, triageHandler, campusHandler, doctorProfileHandler, agreementHandler)
 
 		// 用户接口（需要用户认证）
-		setupUserRoutes(api, userHandler, patientHandler, tokenHandler, appointmentHandler, medicalRecordHandler)
+		setupUserRoutes(api, userHandler, sessionHandler, accountHandler, accountLinkHandler, patientHandler, patientShareHandler, patientGuardianHandler, tokenHandler, appointmentHandler, medicalRecordHandler, notificationHandler, doctorReviewHandler, triageHandler, agreementHandler)
+
+		// 医生工作台接口（需要医生认证）
+		setupDoctorRoutes(api, doctorPortalHandler)
 
 		// 管理后台接口（需要管理员认证）
-		setupAdminRoutes(api, adminHandler, deptHandler, doctorHandler, scheduleHandler, uploadHandler, appointmentHandler, patientHandler, statisticsHandler, logHandler, adminManageHandler, roleHandler, permissionHandler)
+		setupAdminRoutes(api, adminHandler, deptHandler, doctorHandler, scheduleHandler, uploadHandler, appointmentHandler, medicalRecordHandler, patientHandler, statisticsHandler, logHandler, adminManageHandler, roleHandler, permissionHandler, releaseRuleHandler, scheduleSwapHandler, doctorAccountHandler, doctorLeaveHandler, doctorReviewHandler, searchHandler, triageHandler, campusHandler, doctorProfileHandler, patientDuplicateHandler, userAdminHandler, agreementHandler)
 	}
 
 	return r
 }
 
 // setupPublicRoutes 设置公开路由（无需认证）
-func setupPublicRoutes(rg *gin.RouterGroup, deptHandler *handler.DepartmentHandler, doctorHandler *handler.DoctorHandler, scheduleHandler *handler.ScheduleHandler, userHandler *handler.UserHandler, smsHandler *handler.SMSHandler) {
+func setupPublicRoutes(rg *gin.RouterGroup, deptHandler *handler.DepartmentHandler, doctorHandler *handler.DoctorHandler, scheduleHandler *handler.ScheduleHandler, userHandler *handler.UserHandler, smsHandler *handler.SMSHandler, doctorReviewHandler *handler.DoctorReviewHandler, searchHandler *handler.SearchHandler, triageHandler *handler.TriageHandler, campusHandler *handler.CampusHandler, doctorProfileHandler *handler.DoctorProfileHandler, agreementHandler *handler.AgreementHandler) {
 	// 用户注册
 	rg.POST("/user/register", userHandler.Register)
 
@@ -80,18 +102,39 @@ func setupPublicRoutes(rg *gin.RouterGroup, deptHandler *handler.DepartmentHandl
 	rg.POST("/user/login/password", userHandler.PasswordLogin) // 密码登录
 	rg.POST("/user/login/phone", userHandler.PhoneLogin)       // 手机号登录
 
+	// 忘记密码（短信验证码重置）
+	rg.POST("/user/password/reset", userHandler.ResetPassword)
+
 	// 短信验证码
 	rg.POST("/sms/send", smsHandler.SendCode)
 
 	// Token刷新
 	rg.POST("/auth/refresh", userHandler.RefreshToken)
 
+	// 现行协议（公开）
+	rg.GET("/agreements/:type", agreementHandler.GetCurrent)
+
+	// 院区列表（公开）
+	rg.GET("/campuses", campusHandler.ListPublic)
+
 	// 科室列表（公开）
-	rg.GET("/departments", deptHandler.ListAll)
+	rg.GET("/departments", middleware.JWTOptionalAuth(), deptHandler.ListAll)
+	rg.GET("/departments/tree", middleware.JWTOptionalAuth(), deptHandler.Tree)
 
 	// 医生列表（公开）
-	rg.GET("/doctors", doctorHandler.ListPublic)
+	rg.GET("/doctors", middleware.JWTOptionalAuth(), doctorHandler.ListPublic)
 	rg.GET("/doctors/:id", doctorHandler.GetByIDPublic)
+	rg.GET("/specialty-tags", doctorProfileHandler.ListPublicTags)
+
+	// 医生评价（公开）
+	rg.GET("/doctors/:id/reviews", doctorReviewHandler.ListByDoctor)
+	rg.GET("/review-tags", doctorReviewHandler.ListTags)
+
+	// 搜索（公开）
+	rg.GET("/search", searchHandler.Search)
+
+	// 智能导诊（公开）
+	rg.POST("/triage", triageHandler.Recommend)
 
 	// 排班查询（公开）
 	rg.GET("/schedule", scheduleHandler.ListByDoctor)
@@ -99,13 +142,41 @@ func setupPublicRoutes(rg *gin.RouterGroup, deptHandler *handler.DepartmentHandl
 }
 
 // setupUserRoutes 设置用户路由（需要用户认证）
-func setupUserRoutes(rg *gin.RouterGroup, userHandler *handler.UserHandler, patientHandler *handler.PatientHandler, tokenHandler *handler.TokenHandler, appointmentHandler *handler.AppointmentHandler, medicalRecordHandler *handler.MedicalRecordHandler) {
+func setupUserRoutes(rg *gin.RouterGroup, userHandler *handler.UserHandler, sessionHandler *handler.SessionHandler, accountHandler *handler.AccountHandler, accountLinkHandler *handler.AccountLinkHandler, patientHandler *handler.PatientHandler, patientShareHandler *handler.PatientShareHandler, patientGuardianHandler *handler.PatientGuardianHandler, tokenHandler *handler.TokenHandler, appointmentHandler *handler.AppointmentHandler, medicalRecordHandler *handler.MedicalRecordHandler, notificationHandler *handler.NotificationHandler, doctorReviewHandler *handler.DoctorReviewHandler, triageHandler *handler.TriageHandler, agreementHandler *handler.AgreementHandler) {
 	user := rg.Group("")
-	user.Use(middleware.JWTAuth())
+	user.Use(middleware.JWTAuth(), middleware.AgreementConsent())
 	{
 		// 用户信息
 		user.GET("/user/info", userHandler.GetInfo)
 		user.PUT("/user/info", userHandler.UpdateInfo)
+		user.PUT("/user/password", userHandler.ChangePassword)
+
+		// 退出登录与登录设备管理
+		user.POST("/user/logout", sessionHandler.Logout)
+		user.GET("/user/sessions", sessionHandler.List)
+		user.DELETE("/user/sessions", sessionHandler.RevokeOthers)
+		user.DELETE("/user/sessions/:id", sessionHandler.Revoke)
+
+		// 个人数据导出与账号注销
+		user.POST("/user/account/exports", accountHandler.CreateExport)
+		user.GET("/user/account/exports", accountHandler.ListExports)
+		user.GET("/user/account/exports/:id/download", accountHandler.DownloadExport)
+		user.POST("/user/account/deletion", accountHandler.RequestDeletion)
+		user.GET("/user/account/deletion", accountHandler.GetDeletion)
+		user.DELETE("/user/account/deletion", accountHandler.CancelDeletion)
+
+		// 登录方式绑定与账号合并
+		user.GET("/user/account/logins", accountLinkHandler.GetLogins)
+		user.POST("/user/account/logins/wechat", accountLinkHandler.BindWeChat)
+		user.POST("/user/account/logins/phone", accountLinkHandler.BindPhone)
+		user.POST("/user/account/logins/password", accountLinkHandler.BindPassword)
+		user.DELETE("/user/account/logins/:type", accountLinkHandler.Unbind)
+		user.POST("/user/account/merge", accountLinkHandler.Merge)
+
+		// 协议同意
+		user.GET("/user/agreements/pending", agreementHandler.ListPending)
+		user.GET("/user/agreements/consents", agreementHandler.ListMyConsents)
+		user.POST("/user/agreements/consents", agreementHandler.Consent)
 
 		// 就诊人管理
 		user.GET("/user/patients", patientHandler.List)
@@ -113,6 +184,19 @@ func setupUserRoutes(rg *gin.RouterGroup, userHandler *handler.UserHandler, pati
 		user.POST("/user/patients", patientHandler.Create)
 		user.PUT("/user/patients/:id", patientHandler.Update)
 		user.DELETE("/user/patients/:id", patientHandler.Delete)
+		user.POST("/user/patients/:id/verify", patientHandler.Verify)
+		user.POST("/user/patients/:id/consent/code", patientGuardianHandler.SendConsentCode)
+		user.POST("/user/patients/:id/consent", patientGuardianHandler.GrantConsent)
+		user.POST("/user/patients/:id/transfer", patientGuardianHandler.Transfer)
+
+		// 就诊人共享
+		user.GET("/user/patients/:id/members", patientShareHandler.ListMembers)
+		user.PUT("/user/patients/:id/members/:user_id", patientShareHandler.UpdateMember)
+		user.DELETE("/user/patients/:id/members/:user_id", patientShareHandler.RemoveMember)
+		user.GET("/user/patients/:id/invitations", patientShareHandler.ListInvitations)
+		user.POST("/user/patients/:id/invitations", patientShareHandler.CreateInvitation)
+		user.DELETE("/user/patients/:id/invitations/:invitation_id", patientShareHandler.RevokeInvitation)
+		user.POST("/user/patient-invitations/accept", patientShareHandler.AcceptInvitation)
 
 		// 幂等Token
 		user.GET("/token/idempotent", tokenHandler.GetIdempotentToken)
@@ -124,14 +208,76 @@ func setupUserRoutes(rg *gin.RouterGroup, userHandler *handler.UserHandler, pati
 		user.PUT("/appointments/:id/cancel", appointmentHandler.Cancel)
 		user.POST("/appointments/:id/checkin", appointmentHandler.Checkin)
 
+		// 预约科室匹配检查
+		user.POST("/triage/check", triageHandler.Check)
+
+		// 就诊评价
+		user.POST("/appointments/:id/review", doctorReviewHandler.Create)
+		user.GET("/appointments/:id/review", doctorReviewHandler.GetByAppointment)
+
 		// 就诊记录
 		user.GET("/records", medicalRecordHandler.List)
 		user.GET("/records/:id", medicalRecordHandler.GetByID)
+
+		// 站内消息
+		user.GET("/notifications", notificationHandler.List)
+		user.GET("/notifications/unread-count", notificationHandler.UnreadCount)
+		user.PUT("/notifications/read-all", notificationHandler.MarkAllRead)
+		user.PUT("/notifications/:id/read", notificationHandler.MarkRead)
+	}
+}
+
+// setupDoctorRoutes 设置医生工作台路由（需要医生认证，只能访问本人数据）
+func setupDoctorRoutes(rg *gin.RouterGroup, doctorPortalHandler *handler.DoctorPortalHandler) {
+	// 医生登录（公开）
+	rg.POST("/doctor/login", doctorPortalHandler.Login)
+
+	doctor := rg.Group("/doctor")
+	doctor.Use(middleware.JWTDoctorAuth())
+	{
+		// 医生信息
+		doctor.GET("/info", doctorPortalHandler.GetInfo)
+		doctor.PUT("/password", doctorPortalHandler.ChangePassword)
+
+		// 本人排班
+		doctor.GET("/schedules", doctorPortalHandler.ListSchedules)
+
+		// 预约患者
+		doctor.GET("/appointments", doctorPortalHandler.ListAppointments)
+		doctor.GET("/appointments/:id", doctorPortalHandler.GetAppointment)
+		doctor.PUT("/appointments/:id/checkin", doctorPortalHandler.CheckinAppointment)
+		doctor.PUT("/appointments/:id/complete", doctorPortalHandler.CompleteAppointment)
+		doctor.PUT("/appointments/:id/missed", doctorPortalHandler.MarkAppointmentMissed)
+
+		// 病历书写
+		doctor.GET("/records", doctorPortalHandler.ListRecords)
+		doctor.GET("/records/:id", doctorPortalHandler.GetRecord)
+		doctor.GET("/appointments/:id/record", doctorPortalHandler.GetAppointmentRecord)
+		doctor.PUT("/appointments/:id/record", doctorPortalHandler.SaveAppointmentRecord)
+		doctor.PUT("/records/:id/sign", doctorPortalHandler.SignRecord)
+		doctor.POST("/records/:id/amendments", doctorPortalHandler.AmendRecord)
+
+		// 停诊申请
+		doctor.GET("/leaves", doctorPortalHandler.ListLeaves)
+		doctor.GET("/leaves/:id", doctorPortalHandler.GetLeave)
+		doctor.POST("/leaves", doctorPortalHandler.CreateLeave)
+		doctor.PUT("/leaves/:id/cancel", doctorPortalHandler.CancelLeave)
+
+		// 换班申请
+		doctor.GET("/schedule-swaps", doctorPortalHandler.ListSwaps)
+		doctor.GET("/schedule-swaps/:id", doctorPortalHandler.GetSwap)
+		doctor.POST("/schedule-swaps", doctorPortalHandler.CreateSwap)
+		doctor.PUT("/schedule-swaps/:id/respond", doctorPortalHandler.RespondSwap)
+		doctor.PUT("/schedule-swaps/:id/cancel", doctorPortalHandler.CancelSwap)
+
+		// 患者评价
+		doctor.GET("/reviews", doctorPortalHandler.ListReviews)
+		doctor.PUT("/reviews/:id/reply", doctorPortalHandler.ReplyReview)
 	}
 }
 
 // setupAdminRoutes 设置管理后台路由（需要管理员认证）
-func setupAdminRoutes(rg *gin.RouterGroup, adminHandler *handler.AdminHandler, deptHandler *handler.DepartmentHandler, doctorHandler *handler.DoctorHandler, scheduleHandler *handler.ScheduleHandler, uploadHandler *handler.UploadHandler, appointmentHandler *handler.AppointmentHandler, patientHandler *handler.PatientHandler, statisticsHandler *handler.StatisticsHandler, logHandler *handler.LogHandler, adminManageHandler *handler.AdminManageHandler, roleHandler *handler.RoleHandler, permissionHandler *handler.PermissionHandler) {
+func setupAdminRoutes(rg *gin.RouterGroup, adminHandler *handler.AdminHandler, deptHandler *handler.DepartmentHandler, doctorHandler *handler.DoctorHandler, scheduleHandler *handler.ScheduleHandler, uploadHandler *handler.UploadHandler, appointmentHandler *handler.AppointmentHandler, medicalRecordHandler *handler.MedicalRecordHandler, patientHandler *handler.PatientHandler, statisticsHandler *handler.StatisticsHandler, logHandler *handler.LogHandler, adminManageHandler *handler.AdminManageHandler, roleHandler *handler.RoleHandler, permissionHandler *handler.PermissionHandler, releaseRuleHandler *handler.ReleaseRuleHandler, scheduleSwapHandler *handler.ScheduleSwapHandler, doctorAccountHandler *handler.DoctorAccountHandler, doctorLeaveHandler *handler.DoctorLeaveHandler, doctorReviewHandler *handler.DoctorReviewHandler, searchHandler *handler.SearchHandler, triageHandler *handler.TriageHandler, campusHandler *handler.CampusHandler, doctorProfileHandler *handler.DoctorProfileHandler, patientDuplicateHandler *handler.PatientDuplicateHandler, userAdminHandler *handler.UserAdminHandler, agreementHandler *handler.AgreementHandler) {
 	// 管理员登录（公开）
 	rg.POST("/admin/login", adminHandler.Login)
 
@@ -147,6 +293,7 @@ func setupAdminRoutes(rg *gin.RouterGroup, adminHandler *handler.AdminHandler, d
 		admin.POST("/admins", adminManageHandler.CreateAdmin)
 		admin.PUT("/admins/:id", adminManageHandler.UpdateAdmin)
 		admin.PUT("/admins/:id/password", adminManageHandler.ResetAdminPassword)
+		admin.PUT("/admins/:id/campus-roles", adminManageHandler.UpdateCampusRoles)
 
 		// 角色管理
 		admin.GET("/roles", roleHandler.ListRoles)
@@ -163,19 +310,67 @@ func setupAdminRoutes(rg *gin.RouterGroup, adminHandler *handler.AdminHandler, d
 
 		// 预约管理
 		admin.GET("/appointments", appointmentHandler.ListAdmin)
+		admin.POST("/appointments", appointmentHandler.CreateByAdmin)
 		admin.GET("/appointments/:id", appointmentHandler.GetByIDAdmin)
 		admin.PUT("/appointments/:id", appointmentHandler.UpdateStatus)
 		admin.GET("/appointments/export", appointmentHandler.ExportAppointments)
 
+		// 病历管理
+		admin.GET("/records", medicalRecordHandler.ListAdmin)
+		admin.GET("/records/:id", medicalRecordHandler.GetByIDAdmin)
+		admin.GET("/appointments/:id/record", medicalRecordHandler.GetByAppointmentAdmin)
+		admin.PUT("/appointments/:id/record", medicalRecordHandler.SaveAdmin)
+		admin.PUT("/records/:id/sign", medicalRecordHandler.SignAdmin)
+		admin.POST("/records/:id/amendments", medicalRecordHandler.AmendAdmin)
+
 		// 患者管理
 		admin.GET("/patients", patientHandler.ListAdmin)
+		admin.GET("/patients/duplicates", patientDuplicateHandler.List)
+		admin.POST("/patients/duplicates/detect", patientDuplicateHandler.Detect)
+		admin.POST("/patients/duplicates/:id/merge", patientDuplicateHandler.Merge)
+		admin.POST("/patients/duplicates/:id/ignore", patientDuplicateHandler.Ignore)
+		admin.GET("/patients/merges", patientDuplicateHandler.ListMerges)
+		admin.POST("/patients/merges/:id/undo", patientDuplicateHandler.Undo)
 		admin.GET("/patients/:id", patientHandler.GetByIDAdmin)
 
+		// 用户账号管理
+		admin.GET("/users", userAdminHandler.List)
+		admin.GET("/users/:id", userAdminHandler.GetDetail)
+		admin.PUT("/users/:id/enable", userAdminHandler.Enable)
+		admin.PUT("/users/:id/disable", userAdminHandler.Disable)
+		admin.PUT("/users/:id/unblock", userAdminHandler.Unblock)
+
+		// 协议版本与同意记录
+		admin.GET("/agreements", agreementHandler.List)
+		admin.GET("/agreements/consents", agreementHandler.ListConsents)
+		admin.GET("/agreements/consents/export", agreementHandler.ExportConsents)
+		admin.GET("/agreements/:id", agreementHandler.GetByID)
+		admin.POST("/agreements", agreementHandler.Create)
+		admin.PUT("/agreements/:id", agreementHandler.Update)
+		admin.DELETE("/agreements/:id", agreementHandler.Delete)
+		admin.POST("/agreements/:id/publish", agreementHandler.Publish)
+
+		// 院区管理
+		admin.GET("/campuses", campusHandler.List)
+		admin.GET("/campuses/:id", campusHandler.GetByID)
+		admin.POST("/campuses", campusHandler.Create)
+		admin.PUT("/campuses/:id", campusHandler.Update)
+		admin.DELETE("/campuses/:id", campusHandler.Delete)
+
+		// 诊室管理
+		admin.GET("/rooms", campusHandler.ListRooms)
+		admin.GET("/rooms/:id", campusHandler.GetRoom)
+		admin.POST("/rooms", campusHandler.CreateRoom)
+		admin.PUT("/rooms/:id", campusHandler.UpdateRoom)
+		admin.DELETE("/rooms/:id", campusHandler.DeleteRoom)
+
 		// 科室管理
 		admin.GET("/departments", deptHandler.List)
+		admin.GET("/departments/tree", deptHandler.TreeAdmin)
 		admin.GET("/departments/:id", deptHandler.GetByID)
 		admin.POST("/departments", deptHandler.Create)
 		admin.PUT("/departments/:id", deptHandler.Update)
+		admin.PUT("/departments/:id/move", deptHandler.Move)
 		admin.DELETE("/departments/:id", deptHandler.Delete)
 
 		// 医生管理
@@ -184,6 +379,20 @@ func setupAdminRoutes(rg *gin.RouterGroup, adminHandler *handler.AdminHandler, d
 		admin.POST("/doctors", doctorHandler.Create)
 		admin.PUT("/doctors/:id", doctorHandler.Update)
 		admin.DELETE("/doctors/:id", doctorHandler.Delete)
+		admin.PUT("/doctors/:id/profile", doctorProfileHandler.UpdateProfile)
+		admin.GET("/doctors/expiring-qualifications", doctorProfileHandler.ListExpiring)
+
+		// 擅长领域标签
+		admin.GET("/specialty-tags", doctorProfileHandler.ListTags)
+		admin.POST("/specialty-tags", doctorProfileHandler.CreateTag)
+		admin.PUT("/specialty-tags/:id", doctorProfileHandler.UpdateTag)
+		admin.DELETE("/specialty-tags/:id", doctorProfileHandler.DeleteTag)
+
+		// 医生账号管理
+		admin.GET("/doctor-accounts", doctorAccountHandler.List)
+		admin.POST("/doctor-accounts", doctorAccountHandler.Create)
+		admin.PUT("/doctor-accounts/:id", doctorAccountHandler.Update)
+		admin.PUT("/doctor-accounts/:id/password", doctorAccountHandler.ResetPassword)
 
 		// 文件上传
 		admin.POST("/upload/avatar", uploadHandler.UploadAvatar)
@@ -194,9 +403,52 @@ func setupAdminRoutes(rg *gin.RouterGroup, adminHandler *handler.AdminHandler, d
 		admin.GET("/schedules/:id", scheduleHandler.GetByID)
 		admin.POST("/schedules", scheduleHandler.Create)
 		admin.POST("/schedules/batch", scheduleHandler.BatchCreate)
+		admin.POST("/schedules/import", scheduleHandler.Import)
+		admin.POST("/schedules/copy", scheduleHandler.Copy)
+		admin.GET("/schedules/import/template", scheduleHandler.ImportTemplate)
 		admin.PUT("/schedules/:id", scheduleHandler.Update)
 		admin.DELETE("/schedules/:id", scheduleHandler.Delete)
 
+		// 放号规则
+		admin.GET("/release-rules", releaseRuleHandler.List)
+		admin.POST("/release-rules", releaseRuleHandler.Create)
+		admin.PUT("/release-rules/:id", releaseRuleHandler.Update)
+		admin.DELETE("/release-rules/:id", releaseRuleHandler.Delete)
+
+		// 换班管理
+		admin.GET("/schedule-swaps", scheduleSwapHandler.List)
+		admin.GET("/schedule-swaps/:id", scheduleSwapHandler.GetByID)
+		admin.POST("/schedule-swaps", scheduleSwapHandler.Create)
+		admin.PUT("/schedule-swaps/:id/respond", scheduleSwapHandler.Respond)
+		admin.PUT("/schedule-swaps/:id/review", scheduleSwapHandler.Review)
+		admin.PUT("/schedule-swaps/:id/cancel", scheduleSwapHandler.Cancel)
+
+		// 停诊管理
+		admin.GET("/doctor-leaves", doctorLeaveHandler.List)
+		admin.GET("/doctor-leaves/:id", doctorLeaveHandler.GetByID)
+		admin.PUT("/doctor-leaves/:id/review", doctorLeaveHandler.Review)
+
+		// 评价管理
+		admin.GET("/reviews", doctorReviewHandler.List)
+		admin.GET("/reviews/:id", doctorReviewHandler.GetByID)
+		admin.PUT("/reviews/:id/hide", doctorReviewHandler.Hide)
+		admin.PUT("/reviews/:id/show", doctorReviewHandler.Show)
+
+		// 搜索管理
+		admin.POST("/search/rebuild", searchHandler.Rebuild)
+		admin.GET("/search/synonyms", searchHandler.ListSynonyms)
+		admin.POST("/search/synonyms", searchHandler.CreateSynonym)
+		admin.PUT("/search/synonyms/:id", searchHandler.UpdateSynonym)
+		admin.DELETE("/search/synonyms/:id", searchHandler.DeleteSynonym)
+
+		// 导诊规则管理
+		admin.GET("/triage-rules", triageHandler.ListRules)
+		admin.GET("/triage-rules/:id", triageHandler.GetRule)
+		admin.POST("/triage-rules", triageHandler.CreateRule)
+		admin.PUT("/triage-rules/:id", triageHandler.UpdateRule)
+		admin.DELETE("/triage-rules/:id", triageHandler.DeleteRule)
+		admin.POST("/triage-rules/test", triageHandler.TestRules)
+
 		// 数据统计
 		admin.GET("/statistics", statisticsHandler.GetStatistics)
 
@@ -9,6 +9,7 @@ import (
 	"go.uber.org/zap"
 
 	"huaan-medical/internal/model"
+	"huaan-medical/internal/service"
 	"huaan-medical/pkg/config"
 	"huaan-medical/pkg/database"
 	"huaan-medical/pkg/logger"
@@ -31,6 +32,31 @@ func Init() {
 	// 每小时清理过期Token（预留功能）
 	cronJob.AddFunc("0 0 * * * *", cleanExpiredTokens)
 
+	// 每分钟按放号规则放出到期号源
+	cronJob.AddFunc("0 * * * * *", releaseScheduleSlots)
+
+	// 每分钟将到期未用完的现场/VIP预留号源转入公共池
+	cronJob.AddFunc("30 * * * * *", rolloverScheduleQuotas)
+
+	// 每天09:00提醒即将到期的医生执业证书/资格证书
+	cronJob.AddFunc("0 0 9 * * *", alertExpiringQualifications)
+
+	// 每天02:30检测疑似重复就诊人，写入后台审核队列
+	cronJob.AddFunc("0 30 2 * * *", detectDuplicatePatients)
+
+	// 每天04:00执行冷静期已结束的账号注销（匿名化个人信息）
+	cronJob.AddFunc("0 0 4 * * *", processAccountDeletions)
+
+	// 每小时清理过期的个人数据导出文件
+	cronJob.AddFunc("0 15 * * * *", cleanExpiredDataExports)
+
+	// 每天04:30清理过期或已吊销超过保留期的登录会话
+	cronJob.AddFunc("0 30 4 * * *", cleanStaleSessions)
+
+	// 每天03:30全量重建搜索索引（兜底增量更新遗漏），启动时先构建一次
+	cronJob.AddFunc("0 30 3 * * *", rebuildSearchIndex)
+	go rebuildSearchIndex()
+
 	cronJob.Start()
 	logger.Info("定时任务已启动")
 }
@@ -51,6 +77,12 @@ func handleMissedAppointments() {
 	db := database.GetDB()
 	today := time.Now().Format("2006-01-02")
 
+	// 记录受影响的医生，更新后清除其排班缓存
+	var doctorIDs []int64
+	db.Model(&model.Appointment{}).
+		Where("DATE(appointment_date) = ? AND status = ?", today, model.AppointmentStatusPending).
+		Distinct("doctor_id").Pluck("doctor_id", &doctorIDs)
+
 	// 更新今天所有待就诊的预约为爽约状态
 	result := db.Model(&model.Appointment{}).
 		Where("DATE(appointment_date) = ? AND status = ?", today, model.AppointmentStatusPending).
@@ -63,6 +95,11 @@ func handleMissedAppointments() {
 		return
 	}
 
+	scheduleService := service.NewScheduleService()
+	for _, doctorID := range doctorIDs {
+		scheduleService.InvalidateDoctorScheduleCache(doctorID)
+	}
+
 	logger.Info("处理爽约预约完成", zap.Int64("count", result.RowsAffected))
 }
 
@@ -93,6 +130,8 @@ func sendAppointmentReminders() {
 		Preload("Patient").
 		Preload("Doctor").
 		Preload("Department").
+		Preload("Campus").
+		Preload("Room").
 		Where("DATE(appointment_date) = ? AND status = ?", tomorrow, model.AppointmentStatusPending).
 		Find(&appointments).Error
 
@@ -123,6 +162,10 @@ func sendAppointmentReminders() {
 			"time2":  map[string]string{"value": a.AppointmentDate.Format("2006-01-02") + " " + model.GetPeriodName(a.Period)}, // 时间
 			"thing3": map[string]string{"value": a.Department.Name + " " + a.Doctor.Name},                                      // 科室/医生
 		}
+		if location := a.LocationText(); location != "" {
+			// thing 类字段最多20个字符
+			data["thing4"] = map[string]string{"value": truncateRunes(location, 20)} // 就诊地点（院区/地址/诊室）
+		}
 
 		req := &wechat.SubscribeMessageRequest{
 			ToUser:     openID,
@@ -142,6 +185,15 @@ func sendAppointmentReminders() {
 	logger.Info("发送就诊提醒完成", zap.Int("total", len(appointments)), zap.Int("ok", okCount), zap.Int("fail", failCount))
 }
 
+// truncateRunes 按字符数截断字符串
+func truncateRunes(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max])
+}
+
 // cleanExpiredTokens 清理过期Token
 // 每小时执行一次（预留功能）
 func cleanExpiredTokens() {
@@ -193,3 +245,113 @@ func cleanExpiredTokens() {
 
 	logger.Info("清理过期Token完成", zap.Int("scanned", scanned), zap.Int("deleted", deleted), zap.Int("no_expire", noExpire))
 }
+
+// releaseScheduleSlots 定时放号
+// 每分钟执行，将已到放号时间的未放出号源转为可预约，并预热相关医生的排班缓存
+func releaseScheduleSlots() {
+	count, err := service.NewScheduleService().ReleaseDueSlots(time.Now())
+	if err != nil {
+		logger.Error("定时放号失败", zap.Error(err))
+		return
+	}
+
+	if count > 0 {
+		logger.Info("定时放号完成", zap.Int("count", count))
+	}
+}
+
+// rolloverScheduleQuotas 预留号源转入公共池
+// 每分钟执行，就诊开始前指定时间将未用完的现场/VIP预留号源转为线上可预约
+func rolloverScheduleQuotas() {
+	count, err := service.NewScheduleService().RolloverDueQuotas(time.Now())
+	if err != nil {
+		logger.Error("预留号源转入公共池失败", zap.Error(err))
+		return
+	}
+
+	if count > 0 {
+		logger.Info("预留号源转入公共池完成", zap.Int("count", count))
+	}
+}
+
+// processAccountDeletions 执行到期的账号注销
+// 每天04:00执行，匿名化冷静期已结束用户的个人信息并吊销其全部Token
+func processAccountDeletions() {
+	count, err := service.NewAccountService().ProcessDueDeletions(time.Now())
+	if err != nil {
+		logger.Error("执行账号注销失败", zap.Error(err))
+		return
+	}
+
+	if count > 0 {
+		logger.Info("执行账号注销完成", zap.Int("count", count))
+	}
+}
+
+// cleanExpiredDataExports 清理过期的个人数据导出文件
+// 每小时执行，删除超过下载有效期的导出文件，并将中断的导出任务标记为失败
+func cleanExpiredDataExports() {
+	count, err := service.NewAccountService().CleanExpiredExports(time.Now())
+	if err != nil {
+		logger.Error("清理过期数据导出失败", zap.Error(err))
+		return
+	}
+
+	if count > 0 {
+		logger.Info("清理过期数据导出完成", zap.Int("count", count))
+	}
+}
+
+// cleanStaleSessions 清理登录会话
+// 每天04:30执行，删除过期或吊销超过保留期的会话记录
+func cleanStaleSessions() {
+	count, err := service.NewSessionService().CleanStale(time.Now())
+	if err != nil {
+		logger.Error("清理登录会话失败", zap.Error(err))
+		return
+	}
+
+	if count > 0 {
+		logger.Info("清理登录会话完成", zap.Int64("count", count))
+	}
+}
+
+// rebuildSearchIndex 重建搜索索引
+// 每天03:30执行，根据医生、科室数据全量重建搜索索引
+func rebuildSearchIndex() {
+	count, err := service.NewSearchService().Rebuild()
+	if err != nil {
+		logger.Error("重建搜索索引失败", zap.Error(err))
+		return
+	}
+
+	logger.Info("重建搜索索引完成", zap.Int("count", count))
+}
+
+// alertExpiringQualifications 医生资质到期提醒
+// 每天09:00执行，到期前N天（含已过期）尚未提醒的执业证书/资格证书记录告警并标记已提醒
+func alertExpiringQualifications() {
+	count, err := service.NewDoctorProfileService().AlertExpiring(time.Now())
+	if err != nil {
+		logger.Error("医生资质到期提醒失败", zap.Error(err))
+		return
+	}
+
+	if count > 0 {
+		logger.Info("医生资质到期提醒完成", zap.Int("count", count))
+	}
+}
+
+// detectDuplicatePatients 检测疑似重复就诊人
+// 每天02:30执行，按证件号码、姓名+手机号、姓名+性别+出生日期查重，新发现的写入审核队列
+func detectDuplicatePatients() {
+	count, err := service.NewPatientDuplicateService().DetectDuplicates()
+	if err != nil {
+		logger.Error("检测重复就诊人失败", zap.Error(err))
+		return
+	}
+
+	if count > 0 {
+		logger.Info("检测重复就诊人完成", zap.Int("count", count))
+	}
+}
@@ -0,0 +1,409 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/internal/repository"
+	"huaan-medical/pkg/config"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/logger"
+	"huaan-medical/pkg/sms"
+	"huaan-medical/pkg/utils"
+	"huaan-medical/pkg/wechat"
+
+	"go.uber.org/zap"
+)
+
+// accountMergeTicketTTL 账号合并凭证有效期
+const accountMergeTicketTTL = 30 * time.Minute
+
+// AccountLinkService 账号登录方式绑定与合并服务
+// 同一用户通过微信、手机号、用户名密码分别登录会产生多个账号，
+// 绑定时若该登录方式已属于其他账号，则在验证后引导用户将其合并到当前账号
+type AccountLinkService struct {
+	userRepo    *repository.UserRepository
+	accountRepo *repository.AccountRepository
+}
+
+// NewAccountLinkService 创建账号绑定服务实例
+func NewAccountLinkService() *AccountLinkService {
+	return &AccountLinkService{
+		userRepo:    repository.NewUserRepository(),
+		accountRepo: repository.NewAccountRepository(),
+	}
+}
+
+// BindWeChatRequest 绑定微信请求
+type BindWeChatRequest struct {
+	Code string `json:"code" binding:"required"` // 微信登录凭证
+}
+
+// BindPhoneRequest 绑定手机号请求（已绑定时为更换手机号）
+type BindPhoneRequest struct {
+	Phone string `json:"phone" binding:"required,len=11"`
+	Code  string `json:"code" binding:"required,len=6"`
+}
+
+// BindPasswordRequest 设置用户名密码登录请求
+// 用户名已属于其他账号时，需填写该账号的密码以验证归属并引导合并
+type BindPasswordRequest struct {
+	Username string `json:"username" binding:"required,min=4,max=20"`
+	Password string `json:"password" binding:"required,max=20"`
+}
+
+// MergeAccountRequest 确认合并账号请求
+type MergeAccountRequest struct {
+	Ticket string `json:"ticket" binding:"required"`
+}
+
+// BindLoginResponse 绑定登录方式响应
+// 登录方式已属于其他账号时不直接绑定，返回合并引导信息
+type BindLoginResponse struct {
+	Bound  bool                 `json:"bound"`
+	Logins *model.UserLoginsVO  `json:"logins"`
+	Merge  *AccountMergePreview `json:"merge,omitempty"`
+}
+
+// AccountMergePreview 账号合并引导信息
+type AccountMergePreview struct {
+	Ticket           string              `json:"ticket"`     // 合并凭证，确认合并时提交
+	ExpiresAt        string              `json:"expires_at"` // 凭证过期时间
+	Nickname         string              `json:"nickname"`   // 对方账号昵称
+	Logins           *model.UserLoginsVO `json:"logins"`     // 对方账号的登录方式
+	PatientCount     int64               `json:"patient_count"`
+	AppointmentCount int64               `json:"appointment_count"`
+}
+
+// MergeAccountResponse 合并账号响应
+type MergeAccountResponse struct {
+	Logins  *model.UserLoginsVO        `json:"logins"`
+	Summary *model.AccountMergeSummary `json:"summary"`
+}
+
+// GetLogins 查询当前账号已绑定的登录方式
+func (s *AccountLinkService) GetLogins(userID int64) (*model.UserLoginsVO, error) {
+	user, err := s.loadActiveUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return user.ToLoginsVO(), nil
+}
+
+// BindWeChat 绑定微信
+func (s *AccountLinkService) BindWeChat(userID int64, req *BindWeChatRequest) (*BindLoginResponse, error) {
+	user, err := s.loadActiveUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.HasWeChatLogin() {
+		return nil, errorcode.New(errorcode.ErrLoginAlreadyBound)
+	}
+
+	session, err := wechatSession(req.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	other, err := s.userRepo.GetByOpenID(session.OpenID)
+	if err == nil {
+		return s.offerMerge(user, other, model.LoginTypeWeChat, session.OpenID, session.UnionID)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	return s.bind(user, map[string]interface{}{
+		"open_id":  session.OpenID,
+		"union_id": session.UnionID,
+	})
+}
+
+// BindPhone 绑定或更换手机号（短信验证码验证）
+func (s *AccountLinkService) BindPhone(userID int64, req *BindPhoneRequest) (*BindLoginResponse, error) {
+	if !utils.ValidatePhone(req.Phone) {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "手机号格式错误")
+	}
+
+	user, err := s.loadActiveUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Phone == req.Phone {
+		return nil, errorcode.New(errorcode.ErrLoginAlreadyBound)
+	}
+
+	if err := sms.GetService().VerifyCode(req.Phone, req.Code); err != nil {
+		return nil, errorcode.NewWithMessage(errorcode.ErrSMSCodeInvalid, err.Error())
+	}
+
+	other, err := s.userRepo.GetByPhone(req.Phone)
+	if err == nil {
+		return s.offerMerge(user, other, model.LoginTypePhone, req.Phone, "")
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	return s.bind(user, map[string]interface{}{"phone": req.Phone})
+}
+
+// BindPassword 设置用户名密码登录
+func (s *AccountLinkService) BindPassword(userID int64, req *BindPasswordRequest) (*BindLoginResponse, error) {
+	if !utils.ValidateUsername(req.Username) {
+		return nil, errorcode.New(errorcode.ErrUsernameInvalid)
+	}
+
+	user, err := s.loadActiveUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.HasPasswordLogin() {
+		return nil, errorcode.New(errorcode.ErrLoginAlreadyBound)
+	}
+	if user.Username != "" && user.Username != req.Username {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "账号已有用户名，请使用该用户名设置密码")
+	}
+
+	other, err := s.userRepo.GetByUsername(req.Username)
+	if err == nil && other.ID != user.ID {
+		// 用户名属于其他账号：密码正确视为本人的另一个账号，引导合并
+		if other.Password == "" || !utils.CheckPassword(req.Password, other.Password) {
+			return nil, errorcode.New(errorcode.ErrUsernameExists)
+		}
+		return s.offerMerge(user, other, model.LoginTypePassword, req.Username, "")
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	if !utils.ValidatePasswordStrength(req.Password) {
+		return nil, errorcode.New(errorcode.ErrPasswordWeak)
+	}
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrInternalServer)
+	}
+	return s.bind(user, map[string]interface{}{
+		"username": req.Username,
+		"password": hashedPassword,
+	})
+}
+
+// Unbind 解绑登录方式，账号至少保留一种可用的登录方式
+func (s *AccountLinkService) Unbind(userID int64, loginType string) (*model.UserLoginsVO, error) {
+	user, err := s.loadActiveUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var bound bool
+	var updates map[string]interface{}
+	switch loginType {
+	case model.LoginTypeWeChat:
+		bound = user.HasWeChatLogin()
+		updates = map[string]interface{}{"open_id": nil, "union_id": ""}
+	case model.LoginTypePhone:
+		bound = user.HasPhoneLogin()
+		updates = map[string]interface{}{"phone": nil}
+	case model.LoginTypePassword:
+		bound = user.HasPasswordLogin()
+		updates = map[string]interface{}{"username": nil, "password": ""}
+	default:
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "不支持的登录方式")
+	}
+	if !bound {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该登录方式未绑定")
+	}
+	if user.LoginCount() <= 1 {
+		return nil, errorcode.New(errorcode.ErrLastLoginMethod)
+	}
+
+	if err := s.userRepo.UpdateFields(user.ID, updates); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return s.GetLogins(user.ID)
+}
+
+// Merge 确认合并账号：将凭证对应账号的就诊人、预约、评价等数据并入当前账号，
+// 当前账号绑定触发合并的登录方式及其缺少的其他登录方式，对方账号停用且其Token全部失效
+func (s *AccountLinkService) Merge(userID int64, req *MergeAccountRequest) (*MergeAccountResponse, error) {
+	merge, err := s.accountRepo.GetMergeByTicket(req.Ticket)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrAccountMergeInvalid)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	now := time.Now()
+	if merge.TargetUserID != userID || !merge.IsUsable(now) {
+		return nil, errorcode.New(errorcode.ErrAccountMergeInvalid)
+	}
+
+	target, err := s.loadActiveUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	source, err := s.userRepo.GetByID(merge.SourceUserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrAccountMergeInvalid)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	if source.Status == model.StatusDisabled {
+		return nil, errorcode.NewWithMessage(errorcode.ErrAccountDisabled, "对方账号已被禁用，无法合并")
+	}
+
+	// 凭证生成后登录方式可能已变更
+	var stillOwned bool
+	switch merge.LoginType {
+	case model.LoginTypeWeChat:
+		stillOwned = source.OpenID == merge.LoginValue
+	case model.LoginTypePhone:
+		stillOwned = source.Phone == merge.LoginValue
+	case model.LoginTypePassword:
+		stillOwned = source.Username == merge.LoginValue && source.HasPasswordLogin()
+	}
+	if !stillOwned {
+		return nil, errorcode.New(errorcode.ErrAccountMergeInvalid)
+	}
+
+	summary, err := s.accountRepo.MergeAccounts(merge, mergedLoginUpdates(target, source, merge.LoginType), now)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrAccountMergeInvalid)
+		}
+		logger.Error("合并账号失败", zap.Int64("target_user_id", target.ID), zap.Int64("source_user_id", source.ID), zap.Error(err))
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	markUserTokensRevoked(source.ID, now)
+
+	logins, err := s.GetLogins(target.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &MergeAccountResponse{Logins: logins, Summary: summary}, nil
+}
+
+// bind 为当前账号写入登录方式
+func (s *AccountLinkService) bind(user *model.User, updates map[string]interface{}) (*BindLoginResponse, error) {
+	if err := s.userRepo.UpdateFields(user.ID, updates); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	logins, err := s.GetLogins(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &BindLoginResponse{Bound: true, Logins: logins}, nil
+}
+
+// offerMerge 登录方式已属于其他账号（已验证归属）时生成合并凭证
+func (s *AccountLinkService) offerMerge(user, other *model.User, loginType, loginValue, unionID string) (*BindLoginResponse, error) {
+	if other.ID == user.ID {
+		return nil, errorcode.New(errorcode.ErrLoginAlreadyBound)
+	}
+	if other.Status == model.StatusDisabled {
+		return nil, errorcode.NewWithMessage(errorcode.ErrAccountDisabled, "该登录方式所属账号已被禁用，无法绑定")
+	}
+
+	patients, appointments, err := s.accountRepo.CountUserData(other.ID)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	merge := &model.AccountMerge{
+		TargetUserID: user.ID,
+		SourceUserID: other.ID,
+		LoginType:    loginType,
+		LoginValue:   loginValue,
+		UnionID:      unionID,
+		Ticket:       utils.GenerateShortUUID(),
+		Status:       model.AccountMergeStatusPending,
+		ExpiresAt:    time.Now().Add(accountMergeTicketTTL),
+	}
+	if err := s.accountRepo.CreateMerge(merge); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	return &BindLoginResponse{
+		Bound:  false,
+		Logins: user.ToLoginsVO(),
+		Merge: &AccountMergePreview{
+			Ticket:           merge.Ticket,
+			ExpiresAt:        merge.ExpiresAt.Format("2006-01-02 15:04:05"),
+			Nickname:         other.Nickname,
+			Logins:           other.ToLoginsVO(),
+			PatientCount:     patients,
+			AppointmentCount: appointments,
+		},
+	}, nil
+}
+
+// loadActiveUser 查询当前用户并检查账号状态
+func (s *AccountLinkService) loadActiveUser(userID int64) (*model.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrUserNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	if user.Status == model.StatusDisabled {
+		return nil, errorcode.New(errorcode.ErrAccountDisabled)
+	}
+	return user, nil
+}
+
+// mergedLoginUpdates 合并后保留账号需要写入的字段：
+// 触发合并的登录方式覆盖写入，其余登录方式仅在保留账号缺少时继承；
+// 爽约次数累加、封禁取较晚者，避免通过合并规避爽约惩罚
+func mergedLoginUpdates(target, source *model.User, loginType string) map[string]interface{} {
+	updates := map[string]interface{}{}
+
+	if loginType == model.LoginTypeWeChat || (!target.HasWeChatLogin() && source.HasWeChatLogin()) {
+		updates["open_id"] = source.OpenID
+		updates["union_id"] = source.UnionID
+	}
+	if loginType == model.LoginTypePhone || (!target.HasPhoneLogin() && source.HasPhoneLogin()) {
+		updates["phone"] = source.Phone
+	}
+	if loginType == model.LoginTypePassword || (target.Username == "" && source.HasPasswordLogin()) {
+		updates["username"] = source.Username
+		updates["password"] = source.Password
+	}
+
+	if target.Nickname == "" {
+		updates["nickname"] = source.Nickname
+	}
+	if target.Avatar == "" {
+		updates["avatar"] = source.Avatar
+	}
+	if source.MissedCount > 0 {
+		updates["missed_count"] = target.MissedCount + source.MissedCount
+	}
+	if source.BlockedUntil != nil && (target.BlockedUntil == nil || source.BlockedUntil.After(*target.BlockedUntil)) {
+		updates["blocked_until"] = *source.BlockedUntil
+	}
+	return updates
+}
+
+// wechatSession 使用微信登录凭证换取 OpenID
+func wechatSession(code string) (*wechat.SessionResponse, error) {
+	cfg := config.Get()
+	if cfg == nil || cfg.WeChat.AppID == "" || cfg.WeChat.AppSecret == "" {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInternalServer, "微信登录功能未配置，请检查config.yaml中的wechat配置")
+	}
+
+	session, err := wechat.NewClient(cfg.WeChat.AppID, cfg.WeChat.AppSecret).Code2Session(code)
+	if err != nil {
+		return nil, errorcode.NewWithMessage(errorcode.ErrWeChatLoginFailed, "微信授权失败: "+err.Error())
+	}
+	if session.OpenID == "" {
+		return nil, errorcode.NewWithMessage(errorcode.ErrWeChatLoginFailed, "获取微信OpenID失败")
+	}
+	return session, nil
+}
@@ -0,0 +1,688 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/internal/repository"
+	"huaan-medical/pkg/config"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/logger"
+	"huaan-medical/pkg/pdf"
+	"huaan-medical/pkg/sms"
+	"huaan-medical/pkg/utils"
+)
+
+// 账号个人信息默认规则
+const (
+	defaultDeletionCoolingDays = 15
+	defaultExportExpireHours   = 72
+	defaultExportDir           = "exports"
+	dataExportListLimit        = 20
+	dataExportStaleAfter       = time.Hour // 超过该时长仍未完成的导出视为中断
+)
+
+// AccountService 账号个人信息服务（个人数据导出、账号注销）
+type AccountService struct {
+	accountRepo  *repository.AccountRepository
+	userRepo     *repository.UserRepository
+	consentRepo  *repository.PatientConsentRepository
+	notification *NotificationService
+}
+
+// NewAccountService 创建账号个人信息服务实例
+func NewAccountService() *AccountService {
+	return &AccountService{
+		accountRepo:  repository.NewAccountRepository(),
+		userRepo:     repository.NewUserRepository(),
+		consentRepo:  repository.NewPatientConsentRepository(),
+		notification: NewNotificationService(),
+	}
+}
+
+// CreateDataExportRequest 申请个人数据导出请求
+type CreateDataExportRequest struct {
+	Format string `json:"format" binding:"required,oneof=json pdf"` // 导出格式 json/pdf
+}
+
+// RequestAccountDeletionRequest 申请注销账号请求
+// 已绑定手机号的账号需短信验证码；未绑定手机号但设置了密码的账号需登录密码
+type RequestAccountDeletionRequest struct {
+	Reason   string `json:"reason" binding:"max=256"`
+	Code     string `json:"code" binding:"omitempty,len=6"`
+	Password string `json:"password" binding:"max=20"`
+}
+
+// DataExportArchive 个人数据导出归档内容
+type DataExportArchive struct {
+	ExportedAt     string                    `json:"exported_at"`
+	User           *DataExportUser           `json:"user"`
+	Patients       []*model.PatientVO        `json:"patients"`
+	Appointments   []DataExportAppointment   `json:"appointments"`
+	MedicalRecords []DataExportMedicalRecord `json:"medical_records"`
+}
+
+// DataExportUser 导出的账号资料
+type DataExportUser struct {
+	ID           int64  `json:"id"`
+	Username     string `json:"username,omitempty"`
+	Nickname     string `json:"nickname"`
+	Phone        string `json:"phone,omitempty"`
+	Gender       string `json:"gender"`
+	LoginType    string `json:"login_type"`
+	WeChatBound  bool   `json:"wechat_bound"`
+	RegisteredAt string `json:"registered_at"`
+	LastLoginAt  string `json:"last_login_at,omitempty"`
+	LastLoginIP  string `json:"last_login_ip,omitempty"`
+}
+
+// DataExportAppointment 导出的预约记录
+type DataExportAppointment struct {
+	AppointmentNo   string `json:"appointment_no"`
+	PatientName     string `json:"patient_name"`
+	CampusName      string `json:"campus_name,omitempty"`
+	DepartmentName  string `json:"department_name"`
+	DoctorName      string `json:"doctor_name"`
+	AppointmentDate string `json:"appointment_date"`
+	Period          string `json:"period"`
+	AppointmentTime string `json:"appointment_time"`
+	Status          string `json:"status"`
+	Channel         string `json:"channel"`
+	Symptom         string `json:"symptom,omitempty"`
+	CancelReason    string `json:"cancel_reason,omitempty"`
+	CreatedAt       string `json:"created_at"`
+	CancelledAt     string `json:"cancelled_at,omitempty"`
+	CheckedInAt     string `json:"checked_in_at,omitempty"`
+	CompletedAt     string `json:"completed_at,omitempty"`
+}
+
+// DataExportMedicalRecord 导出的就诊记录
+type DataExportMedicalRecord struct {
+	PatientName    string                      `json:"patient_name"`
+	VisitDate      string                      `json:"visit_date"`
+	DepartmentName string                      `json:"department_name"`
+	DoctorName     string                      `json:"doctor_name"`
+	Diagnosis      string                      `json:"diagnosis"`
+	Prescription   string                      `json:"prescription"`
+	Advice         string                      `json:"advice"`
+	Remark         string                      `json:"remark,omitempty"`
+	Version        int                         `json:"version"`
+	SignedAt       string                      `json:"signed_at,omitempty"`
+	Amendments     []DataExportRecordAmendment `json:"amendments,omitempty"`
+}
+
+// DataExportRecordAmendment 导出的病历补充更正记录
+type DataExportRecordAmendment struct {
+	Version    int    `json:"version"`
+	Reason     string `json:"reason"`
+	AuthorName string `json:"author_name"`
+	CreatedAt  string `json:"created_at"`
+}
+
+// RequestExport 申请个人数据导出，导出文件在后台异步生成，完成后发送站内消息
+func (s *AccountService) RequestExport(userID int64, req *CreateDataExportRequest, clientIP string) (*model.DataExportVO, error) {
+	active, err := s.accountRepo.HasActiveExport(userID)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	if active {
+		return nil, errorcode.New(errorcode.ErrDataExportInProgress)
+	}
+
+	export := &model.DataExport{
+		UserID:    userID,
+		Format:    req.Format,
+		Status:    model.DataExportStatusPending,
+		RequestIP: clientIP,
+	}
+	if err := s.accountRepo.CreateExport(export); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	go s.generateExport(export.ID)
+
+	return export.ToVO(), nil
+}
+
+// ListExports 查询本人最近的数据导出记录
+func (s *AccountService) ListExports(userID int64) ([]*model.DataExportVO, error) {
+	list, err := s.accountRepo.ListExportsByUser(userID, dataExportListLimit)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	result := make([]*model.DataExportVO, 0, len(list))
+	for i := range list {
+		result = append(result, list[i].ToVO())
+	}
+	return result, nil
+}
+
+// GetExportFile 获取可下载的导出文件路径及下载文件名
+func (s *AccountService) GetExportFile(userID, id int64) (string, string, error) {
+	export, err := s.accountRepo.GetExportByUserAndID(userID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", "", errorcode.New(errorcode.ErrDataExportNotFound)
+		}
+		return "", "", errorcode.New(errorcode.ErrDatabase)
+	}
+	if !export.IsDownloadable() || export.FilePath == "" {
+		return "", "", errorcode.New(errorcode.ErrDataExportUnavailable)
+	}
+	if _, err := os.Stat(export.FilePath); err != nil {
+		return "", "", errorcode.New(errorcode.ErrDataExportUnavailable)
+	}
+
+	fileName := fmt.Sprintf("个人数据_%s.%s", export.CreatedAt.Format("20060102"), export.Format)
+	return export.FilePath, fileName, nil
+}
+
+// CleanExpiredExports 删除超过下载有效期的导出文件，并将中断的导出任务标记为失败
+func (s *AccountService) CleanExpiredExports(now time.Time) (int, error) {
+	if _, err := s.accountRepo.FailStaleExports(now.Add(-dataExportStaleAfter)); err != nil {
+		return 0, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	list, err := s.accountRepo.ListExpiredExports(now)
+	if err != nil {
+		return 0, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	count := 0
+	for _, export := range list {
+		removeExportFile(export.FilePath)
+		if err := s.accountRepo.UpdateExport(export.ID, map[string]interface{}{
+			"status":    model.DataExportStatusExpired,
+			"file_path": "",
+		}); err != nil {
+			logger.Error("更新过期导出记录失败", zap.Int64("export_id", export.ID), zap.Error(err))
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+// generateExport 生成导出文件（后台执行）
+func (s *AccountService) generateExport(id int64) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("生成个人数据导出异常", zap.Int64("export_id", id), zap.Any("panic", r))
+			s.failExport(id, "生成失败，请重新申请")
+		}
+	}()
+
+	export, err := s.accountRepo.GetExportByID(id)
+	if err != nil {
+		logger.Error("查询个人数据导出任务失败", zap.Int64("export_id", id), zap.Error(err))
+		return
+	}
+	if err := s.accountRepo.UpdateExport(id, map[string]interface{}{"status": model.DataExportStatusProcessing}); err != nil {
+		logger.Error("更新个人数据导出状态失败", zap.Int64("export_id", id), zap.Error(err))
+		return
+	}
+
+	archive, err := s.buildArchive(export.UserID)
+	if err != nil {
+		logger.Error("汇总个人数据失败", zap.Int64("export_id", id), zap.Error(err))
+		s.failExport(id, "数据汇总失败，请重新申请")
+		return
+	}
+
+	var data []byte
+	if export.Format == model.DataExportFormatPDF {
+		data = renderArchivePDF(archive)
+	} else if data, err = json.MarshalIndent(archive, "", "  "); err != nil {
+		logger.Error("序列化个人数据失败", zap.Int64("export_id", id), zap.Error(err))
+		s.failExport(id, "生成失败，请重新申请")
+		return
+	}
+
+	dir := exportDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		logger.Error("创建导出目录失败", zap.String("dir", dir), zap.Error(err))
+		s.failExport(id, "文件保存失败，请重新申请")
+		return
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%d_%s.%s", export.UserID, utils.GenerateShortUUID(), export.Format))
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		logger.Error("写入导出文件失败", zap.String("path", path), zap.Error(err))
+		s.failExport(id, "文件保存失败，请重新申请")
+		return
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(time.Duration(exportExpireHours()) * time.Hour)
+	if err := s.accountRepo.UpdateExport(id, map[string]interface{}{
+		"status":       model.DataExportStatusCompleted,
+		"file_path":    path,
+		"file_size":    int64(len(data)),
+		"completed_at": now,
+		"expires_at":   expiresAt,
+	}); err != nil {
+		logger.Error("更新个人数据导出状态失败", zap.Int64("export_id", id), zap.Error(err))
+		removeExportFile(path)
+		return
+	}
+
+	s.notification.Send([]model.Notification{{
+		UserID:  export.UserID,
+		Type:    model.NotificationTypeDataExport,
+		Title:   "个人数据导出已完成",
+		Content: fmt.Sprintf("您申请的个人数据导出已生成，请在%s前下载。", expiresAt.Format("2006-01-02 15:04")),
+		BizID:   id,
+	}})
+}
+
+// failExport 将导出任务标记为失败
+func (s *AccountService) failExport(id int64, reason string) {
+	if err := s.accountRepo.UpdateExport(id, map[string]interface{}{
+		"status":    model.DataExportStatusFailed,
+		"error_msg": reason,
+	}); err != nil {
+		logger.Error("更新个人数据导出状态失败", zap.Int64("export_id", id), zap.Error(err))
+	}
+}
+
+// buildArchive 汇总用户的账号资料、本人创建的就诊人及其预约和已签署病历
+// 他人共享给本人的就诊人属于他人个人信息，不在导出范围内；成年家人的病历需本人授权后才导出
+func (s *AccountService) buildArchive(userID int64) (*DataExportArchive, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	patients, err := s.accountRepo.ListOwnedPatients(userID)
+	if err != nil {
+		return nil, err
+	}
+	patientIDs := make([]int64, 0, len(patients))
+	for _, p := range patients {
+		patientIDs = append(patientIDs, p.ID)
+	}
+	appointments, err := s.accountRepo.ListAppointmentsForExport(userID, patientIDs)
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.accountRepo.ListSignedRecordsByPatients(patientIDs)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	consents, err := loadRecordConsents(s.consentRepo, userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := &DataExportArchive{
+		ExportedAt: time.Now().Format("2006-01-02 15:04:05"),
+		User: &DataExportUser{
+			ID:           user.ID,
+			Username:     user.Username,
+			Nickname:     user.Nickname,
+			Phone:        user.Phone,
+			Gender:       exportGenderName(user.Gender),
+			LoginType:    user.LoginType,
+			WeChatBound:  user.OpenID != "",
+			RegisteredAt: user.CreatedAt.Format("2006-01-02 15:04:05"),
+			LastLoginAt:  formatExportTime(user.LastLoginAt),
+			LastLoginIP:  user.LastLoginIP,
+		},
+		Patients:       make([]*model.PatientVO, 0, len(patients)),
+		Appointments:   make([]DataExportAppointment, 0, len(appointments)),
+		MedicalRecords: make([]DataExportMedicalRecord, 0, len(records)),
+	}
+
+	for i := range patients {
+		archive.Patients = append(archive.Patients, patients[i].ToFullVO())
+	}
+
+	for _, a := range appointments {
+		item := DataExportAppointment{
+			AppointmentNo:   a.AppointmentNo,
+			AppointmentDate: utils.FormatDate(a.AppointmentDate),
+			Period:          model.GetPeriodName(a.Period),
+			AppointmentTime: a.AppointmentTime,
+			Status:          model.GetAppointmentStatusName(a.Status),
+			Channel:         model.GetChannelName(a.Channel),
+			Symptom:         a.Symptom,
+			CancelReason:    a.CancelReason,
+			CreatedAt:       a.CreatedAt.Format("2006-01-02 15:04:05"),
+			CancelledAt:     formatExportTime(a.CancelledAt),
+			CheckedInAt:     formatExportTime(a.CheckedInAt),
+			CompletedAt:     formatExportTime(a.CompletedAt),
+		}
+		if a.Patient != nil {
+			item.PatientName = a.Patient.Name
+		}
+		if a.Campus != nil {
+			item.CampusName = a.Campus.Name
+		}
+		if a.Department != nil {
+			item.DepartmentName = a.Department.Name
+		}
+		if a.Doctor != nil {
+			item.DoctorName = a.Doctor.Name
+		}
+		archive.Appointments = append(archive.Appointments, item)
+	}
+
+	for _, r := range records {
+		// 成年家人的病历未经本人授权不导出
+		if r.Patient != nil && !consents.allows(r.Patient, now) {
+			continue
+		}
+		item := DataExportMedicalRecord{
+			VisitDate:    utils.FormatDate(r.VisitDate),
+			Diagnosis:    r.Diagnosis,
+			Prescription: r.Prescription,
+			Advice:       r.Advice,
+			Remark:       r.Remark,
+			Version:      r.Version,
+			SignedAt:     formatExportTime(r.SignedAt),
+		}
+		if r.Patient != nil {
+			item.PatientName = r.Patient.Name
+		}
+		if r.Department != nil {
+			item.DepartmentName = r.Department.Name
+		}
+		if r.Doctor != nil {
+			item.DoctorName = r.Doctor.Name
+		}
+		for _, am := range r.Amendments {
+			item.Amendments = append(item.Amendments, DataExportRecordAmendment{
+				Version:    am.Version,
+				Reason:     am.Reason,
+				AuthorName: am.AuthorName,
+				CreatedAt:  am.CreatedAt.Format("2006-01-02 15:04:05"),
+			})
+		}
+		archive.MedicalRecords = append(archive.MedicalRecords, item)
+	}
+
+	return archive, nil
+}
+
+// renderArchivePDF 将导出内容排版为 PDF 文档
+func renderArchivePDF(archive *DataExportArchive) []byte {
+	doc := pdf.New()
+	doc.Title("个人数据导出")
+	doc.Field("导出时间", archive.ExportedAt)
+
+	u := archive.User
+	doc.Heading("一、账号资料")
+	doc.Field("用户ID", fmt.Sprintf("%d", u.ID))
+	if u.Username != "" {
+		doc.Field("用户名", u.Username)
+	}
+	doc.Field("昵称", u.Nickname)
+	if u.Phone != "" {
+		doc.Field("手机号", u.Phone)
+	}
+	doc.Field("性别", u.Gender)
+	doc.Field("绑定微信", map[bool]string{true: "是", false: "否"}[u.WeChatBound])
+	doc.Field("注册时间", u.RegisteredAt)
+	if u.LastLoginAt != "" {
+		doc.Field("最近登录", fmt.Sprintf("%s（%s）", u.LastLoginAt, u.LastLoginIP))
+	}
+
+	doc.Heading(fmt.Sprintf("二、就诊人（%d）", len(archive.Patients)))
+	for i, p := range archive.Patients {
+		doc.Space(pdf.TextSize / 2)
+		doc.Text(fmt.Sprintf("%d. %s（%s）", i+1, p.Name, p.RelationName))
+		doc.Field("证件", fmt.Sprintf("%s %s", p.DocTypeName, p.IDCard))
+		doc.Field("手机号", p.Phone)
+		doc.Field("性别/出生日期", fmt.Sprintf("%s / %s", p.GenderName, p.BirthDate))
+		doc.Field("实名核验", p.VerifyStatusName)
+	}
+
+	doc.Heading(fmt.Sprintf("三、预约记录（%d）", len(archive.Appointments)))
+	for i, a := range archive.Appointments {
+		doc.Space(pdf.TextSize / 2)
+		doc.Text(fmt.Sprintf("%d. %s %s %s  %s", i+1, a.AppointmentDate, a.Period, a.AppointmentTime, a.Status))
+		doc.Field("预约编号", a.AppointmentNo)
+		doc.Field("就诊人", a.PatientName)
+		doc.Field("科室/医生", strings.TrimPrefix(fmt.Sprintf("%s %s / %s", a.CampusName, a.DepartmentName, a.DoctorName), " "))
+		if a.Symptom != "" {
+			doc.Field("症状描述", a.Symptom)
+		}
+		if a.CancelReason != "" {
+			doc.Field("取消原因", a.CancelReason)
+		}
+	}
+
+	doc.Heading(fmt.Sprintf("四、就诊记录（%d）", len(archive.MedicalRecords)))
+	for i, r := range archive.MedicalRecords {
+		doc.Space(pdf.TextSize / 2)
+		doc.Text(fmt.Sprintf("%d. %s %s %s（%s）", i+1, r.VisitDate, r.DepartmentName, r.DoctorName, r.PatientName))
+		doc.Field("诊断", r.Diagnosis)
+		doc.Field("处方", r.Prescription)
+		doc.Field("医嘱", r.Advice)
+		if r.Remark != "" {
+			doc.Field("备注", r.Remark)
+		}
+		for _, am := range r.Amendments {
+			doc.Field(fmt.Sprintf("补充更正v%d", am.Version), fmt.Sprintf("%s（%s，%s）", am.Reason, am.AuthorName, am.CreatedAt))
+		}
+	}
+
+	return doc.Bytes()
+}
+
+// RequestDeletion 申请注销账号，进入冷静期
+// 冷静期内可撤回；存在待就诊预约时不可申请
+func (s *AccountService) RequestDeletion(userID int64, req *RequestAccountDeletionRequest, clientIP string) (*model.AccountDeletionVO, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrUserNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	// 身份确认
+	switch {
+	case user.Phone != "":
+		if req.Code == "" {
+			return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "请输入短信验证码")
+		}
+		if err := sms.GetService().VerifyCode(user.Phone, req.Code); err != nil {
+			return nil, errorcode.NewWithMessage(errorcode.ErrSMSCodeInvalid, err.Error())
+		}
+	case user.Password != "":
+		if !utils.CheckPassword(req.Password, user.Password) {
+			return nil, errorcode.New(errorcode.ErrPasswordWrong)
+		}
+	}
+
+	if _, err := s.accountRepo.GetPendingDeletion(userID); err == nil {
+		return nil, errorcode.New(errorcode.ErrAccountDeletionPending)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	openCount, err := s.accountRepo.CountOpenAppointments(userID)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	if openCount > 0 {
+		return nil, errorcode.New(errorcode.ErrAccountHasPendingAppt)
+	}
+
+	deletion := &model.AccountDeletion{
+		UserID:      userID,
+		Status:      model.AccountDeletionStatusPending,
+		Reason:      req.Reason,
+		RequestIP:   clientIP,
+		ScheduledAt: time.Now().AddDate(0, 0, deletionCoolingDays()),
+	}
+	if err := s.accountRepo.CreateDeletion(deletion); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	s.notification.Send([]model.Notification{{
+		UserID: userID,
+		Type:   model.NotificationTypeAccountDeletion,
+		Title:  "账号注销申请已提交",
+		Content: fmt.Sprintf("您的账号将于%s注销，届时个人信息将被匿名化且无法恢复。冷静期内可随时撤回申请。",
+			deletion.ScheduledAt.Format("2006-01-02 15:04")),
+		BizID: deletion.ID,
+	}})
+
+	return deletion.ToVO(), nil
+}
+
+// GetDeletion 查询冷静期中的注销申请
+func (s *AccountService) GetDeletion(userID int64) (*model.AccountDeletionVO, error) {
+	deletion, err := s.accountRepo.GetPendingDeletion(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrAccountDeletionNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return deletion.ToVO(), nil
+}
+
+// CancelDeletion 冷静期内撤回注销申请
+func (s *AccountService) CancelDeletion(userID int64) error {
+	deletion, err := s.accountRepo.GetPendingDeletion(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errorcode.New(errorcode.ErrAccountDeletionNotFound)
+		}
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+
+	if err := s.accountRepo.UpdateDeletion(deletion.ID, map[string]interface{}{
+		"status":       model.AccountDeletionStatusCancelled,
+		"cancelled_at": time.Now(),
+	}); err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+
+	s.notification.Send([]model.Notification{{
+		UserID:  userID,
+		Type:    model.NotificationTypeAccountDeletion,
+		Title:   "账号注销申请已撤回",
+		Content: "您已撤回账号注销申请，账号可继续正常使用。",
+		BizID:   deletion.ID,
+	}})
+	return nil
+}
+
+// ProcessDueDeletions 执行冷静期已结束的注销申请，返回完成数量
+// 冷静期内新产生待就诊预约的，顺延到就诊结束后再执行
+func (s *AccountService) ProcessDueDeletions(now time.Time) (int, error) {
+	list, err := s.accountRepo.ListDueDeletions(now)
+	if err != nil {
+		return 0, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	count := 0
+	for i := range list {
+		deletion := &list[i]
+
+		openCount, err := s.accountRepo.CountOpenAppointments(deletion.UserID)
+		if err != nil {
+			logger.Error("查询待注销用户预约失败", zap.Int64("user_id", deletion.UserID), zap.Error(err))
+			continue
+		}
+		if openCount > 0 {
+			_ = s.accountRepo.UpdateDeletion(deletion.ID, map[string]interface{}{
+				"remark": "存在待就诊预约，将在就诊结束后执行注销",
+			})
+			continue
+		}
+
+		var phone string
+		if user, err := s.userRepo.GetByID(deletion.UserID); err == nil {
+			phone = user.Phone
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logger.Error("查询待注销用户失败", zap.Int64("user_id", deletion.UserID), zap.Error(err))
+			continue
+		}
+
+		exportFiles, err := s.accountRepo.ListExportFilePaths(deletion.UserID)
+		if err != nil {
+			logger.Error("查询待注销用户导出记录失败", zap.Int64("user_id", deletion.UserID), zap.Error(err))
+			continue
+		}
+
+		if err := s.accountRepo.AnonymizeAccount(deletion, phone, now); err != nil {
+			logger.Error("注销账号失败", zap.Int64("user_id", deletion.UserID), zap.Error(err))
+			continue
+		}
+
+		markUserTokensRevoked(deletion.UserID, now)
+		for _, path := range exportFiles {
+			removeExportFile(path)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// removeExportFile 删除导出文件（文件不存在时忽略）
+func removeExportFile(path string) {
+	if path == "" {
+		return
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		logger.Warn("删除导出文件失败", zap.String("path", path), zap.Error(err))
+	}
+}
+
+// formatExportTime 格式化可空时间
+func formatExportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// exportGenderName 性别名称
+func exportGenderName(gender int) string {
+	switch gender {
+	case model.GenderMale:
+		return "男"
+	case model.GenderFemale:
+		return "女"
+	default:
+		return "未知"
+	}
+}
+
+// deletionCoolingDays 注销冷静期（天）
+func deletionCoolingDays() int {
+	if cfg := config.Get(); cfg != nil && cfg.Business.Account.DeletionCoolingDays > 0 {
+		return cfg.Business.Account.DeletionCoolingDays
+	}
+	return defaultDeletionCoolingDays
+}
+
+// exportExpireHours 导出文件下载有效期（小时）
+func exportExpireHours() int {
+	if cfg := config.Get(); cfg != nil && cfg.Business.Account.ExportExpireHours > 0 {
+		return cfg.Business.Account.ExportExpireHours
+	}
+	return defaultExportExpireHours
+}
+
+// exportDir 导出文件存放目录
+func exportDir() string {
+	if cfg := config.Get(); cfg != nil && cfg.Business.Account.ExportDir != "" {
+		return cfg.Business.Account.ExportDir
+	}
+	return defaultExportDir
+}
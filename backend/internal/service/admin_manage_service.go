@@ -232,3 +232,70 @@ func (s *AdminManageService) ResetPassword(adminID int64, req *ResetAdminPasswor
 	}
 	return nil
 }
+
+// AdminCampusRoleItem 院区角色分配项
+type AdminCampusRoleItem struct {
+	CampusID int64 `json:"campus_id" binding:"required,min=1"`
+	RoleID   int64 `json:"role_id" binding:"required,min=1"`
+}
+
+// UpdateCampusRolesRequest 分配院区角色请求（整体替换，空数组表示清空）
+type UpdateCampusRolesRequest struct {
+	CampusRoles []AdminCampusRoleItem `json:"campus_roles" binding:"dive"`
+}
+
+// UpdateCampusRoles 分配管理员的院区角色
+// 院区角色仅在请求携带对应院区（X-Campus-ID 或 campus_id）时生效，全院角色仍通过 role_ids 分配
+func (s *AdminManageService) UpdateCampusRoles(adminID int64, req *UpdateCampusRolesRequest) (*model.AdminVO, error) {
+	if _, err := s.repo.GetByID(adminID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrAdminNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	items := make([]model.AdminCampusRole, 0, len(req.CampusRoles))
+	seen := make(map[[2]int64]bool, len(req.CampusRoles))
+	for _, item := range req.CampusRoles {
+		key := [2]int64{item.CampusID, item.RoleID}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		items = append(items, model.AdminCampusRole{CampusID: item.CampusID, RoleID: item.RoleID})
+	}
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		campusRepo := repository.NewCampusRepositoryWithDB(tx)
+		roleRepo := repository.NewRoleRepositoryWithDB(tx)
+
+		for _, item := range items {
+			if _, err := campusRepo.GetByID(item.CampusID); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return errorcode.New(errorcode.ErrCampusNotFound)
+				}
+				return err
+			}
+			if _, err := roleRepo.GetByID(item.RoleID); err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return errorcode.New(errorcode.ErrInvalidParams)
+				}
+				return err
+			}
+		}
+
+		return repository.NewAdminRepositoryWithDB(tx).ReplaceCampusRoles(adminID, items)
+	})
+	if err != nil {
+		if appErr, ok := err.(*errorcode.AppError); ok {
+			return nil, appErr
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	admin, err := s.repo.GetByID(adminID)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return admin.ToVO(), nil
+}
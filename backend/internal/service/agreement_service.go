@@ -0,0 +1,399 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/internal/repository"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/redis"
+	"huaan-medical/pkg/utils"
+)
+
+const (
+	// agreementConsentListLimit 用户查看本人同意记录的最大条数
+	agreementConsentListLimit = 100
+	// agreementConsentExportLimit 单次审计导出的最大记录数
+	agreementConsentExportLimit = 50000
+)
+
+// AgreementService 协议版本与同意记录服务
+// 管理员维护协议版本（草稿 -> 发布），用户同意现行版本时记录时间、IP及客户端；
+// 隐私政策、用户服务协议发布新版本后由 middleware.AgreementConsent 要求用户重新同意
+type AgreementService struct {
+	repo        *repository.AgreementRepository
+	patientRepo *repository.PatientRepository
+}
+
+// NewAgreementService 创建协议服务实例
+func NewAgreementService() *AgreementService {
+	return &AgreementService{
+		repo:        repository.NewAgreementRepository(),
+		patientRepo: repository.NewPatientRepository(),
+	}
+}
+
+// ListAgreementsRequest 协议版本列表查询请求
+type ListAgreementsRequest struct {
+	Page     int    `form:"page" binding:"required,min=1"`
+	PageSize int    `form:"page_size" binding:"required,min=1,max=100"`
+	Type     string `form:"type" binding:"omitempty,oneof=privacy terms referral"`
+	Status   string `form:"status" binding:"omitempty,oneof=draft published"`
+}
+
+// SaveAgreementRequest 创建/编辑协议版本请求（仅草稿可编辑）
+type SaveAgreementRequest struct {
+	Type      string `json:"type" binding:"required,oneof=privacy terms referral"`
+	Version   string `json:"version" binding:"required,max=32"`
+	Title     string `json:"title" binding:"required,max=128"`
+	Content   string `json:"content" binding:"required"`
+	ChangeLog string `json:"change_log" binding:"max=512"` // 本版本变更说明，重新同意时展示给用户
+}
+
+// AgreementConsentRequest 用户同意/撤回协议请求
+type AgreementConsentRequest struct {
+	AgreementID int64  `json:"agreement_id" binding:"required"`
+	PatientID   int64  `json:"patient_id"`                                       // 按就诊人同意的协议必填
+	Action      string `json:"action" binding:"omitempty,oneof=accept withdraw"` // 默认同意
+}
+
+// ListAgreementConsentsRequest 同意记录审计查询请求
+type ListAgreementConsentsRequest struct {
+	Page      int    `form:"page" binding:"required,min=1"`
+	PageSize  int    `form:"page_size" binding:"required,min=1,max=100"`
+	Type      string `form:"type" binding:"omitempty,oneof=privacy terms referral"`
+	Version   string `form:"version"`
+	UserID    int64  `form:"user_id"`
+	PatientID int64  `form:"patient_id"`
+	StartDate string `form:"start_date"` // YYYY-MM-DD
+	EndDate   string `form:"end_date"`   // YYYY-MM-DD
+}
+
+// ExportAgreementConsentsRequest 同意记录审计导出请求
+type ExportAgreementConsentsRequest struct {
+	Type      string `form:"type" binding:"omitempty,oneof=privacy terms referral"`
+	Version   string `form:"version"`
+	UserID    int64  `form:"user_id"`
+	PatientID int64  `form:"patient_id"`
+	StartDate string `form:"start_date"` // YYYY-MM-DD
+	EndDate   string `form:"end_date"`   // YYYY-MM-DD
+}
+
+// List 分页查询协议版本
+func (s *AgreementService) List(req *ListAgreementsRequest) ([]*model.AgreementVO, int64, error) {
+	list, total, err := s.repo.List(req.Page, req.PageSize, req.Type, req.Status)
+	if err != nil {
+		return nil, 0, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	voList := make([]*model.AgreementVO, 0, len(list))
+	for i := range list {
+		voList = append(voList, list[i].ToVO(false))
+	}
+	return voList, total, nil
+}
+
+// GetByID 查询协议版本详情
+func (s *AgreementService) GetByID(id int64) (*model.AgreementVO, error) {
+	agreement, err := s.getAgreement(id)
+	if err != nil {
+		return nil, err
+	}
+	return agreement.ToVO(true), nil
+}
+
+// Create 创建协议版本草稿
+func (s *AgreementService) Create(adminID int64, req *SaveAgreementRequest) (*model.AgreementVO, error) {
+	version := strings.TrimSpace(req.Version)
+	if err := s.checkVersion(req.Type, version, 0); err != nil {
+		return nil, err
+	}
+
+	agreement := &model.Agreement{
+		Type:      req.Type,
+		Version:   version,
+		Title:     strings.TrimSpace(req.Title),
+		Content:   req.Content,
+		ChangeLog: req.ChangeLog,
+		Status:    model.AgreementStatusDraft,
+		CreatedBy: adminID,
+	}
+	if err := s.repo.Create(agreement); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return agreement.ToVO(true), nil
+}
+
+// Update 编辑协议版本草稿
+func (s *AgreementService) Update(id int64, req *SaveAgreementRequest) (*model.AgreementVO, error) {
+	agreement, err := s.getAgreement(id)
+	if err != nil {
+		return nil, err
+	}
+	if agreement.Status != model.AgreementStatusDraft {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "已发布的协议不可修改，请创建新版本")
+	}
+	if req.Type != agreement.Type {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "协议类型不可修改")
+	}
+	version := strings.TrimSpace(req.Version)
+	if err := s.checkVersion(agreement.Type, version, id); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repo.UpdateDraft(id, map[string]interface{}{
+		"version":    version,
+		"title":      strings.TrimSpace(req.Title),
+		"content":    req.Content,
+		"change_log": req.ChangeLog,
+	})
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	if !updated {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "已发布的协议不可修改，请创建新版本")
+	}
+	return s.GetByID(id)
+}
+
+// Delete 删除协议版本草稿
+func (s *AgreementService) Delete(id int64) error {
+	if _, err := s.getAgreement(id); err != nil {
+		return err
+	}
+	deleted, err := s.repo.DeleteDraft(id)
+	if err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if !deleted {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "已发布的协议不可删除")
+	}
+	return nil
+}
+
+// Publish 发布协议版本，成为该类型的现行版本
+// 隐私政策、用户服务协议发布后，用户需重新同意方可继续使用业务功能
+func (s *AgreementService) Publish(adminID, id int64) (*model.AgreementVO, error) {
+	if _, err := s.getAgreement(id); err != nil {
+		return nil, err
+	}
+	published, err := s.repo.Publish(id, adminID, time.Now())
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	if !published {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该版本已发布")
+	}
+
+	// 清除现行版本缓存，用户已同意签名随之失配，下次访问时重新校验
+	if redis.IsEnabled() {
+		_ = redis.Del(context.Background(), redis.KeyAgreementCurrent)
+	}
+	return s.GetByID(id)
+}
+
+// GetCurrent 查询协议类型的现行版本（公开）
+func (s *AgreementService) GetCurrent(agreementType string) (*model.AgreementVO, error) {
+	agreement, err := s.repo.GetCurrent(agreementType)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrAgreementNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return agreement.ToVO(true), nil
+}
+
+// ListPending 查询用户尚未同意现行版本的必须同意协议（含正文及变更说明）
+func (s *AgreementService) ListPending(userID int64) ([]*model.AgreementVO, error) {
+	current, err := s.repo.ListCurrent(model.RequiredAgreementTypes())
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	latest, err := s.repo.LatestConsents(userID, 0, model.RequiredAgreementTypes())
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	pending := model.PendingAgreements(current, latest)
+	list := make([]*model.AgreementVO, 0, len(pending))
+	for _, a := range pending {
+		vo, err := s.GetByID(a.ID)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, vo)
+	}
+	return list, nil
+}
+
+// Consent 用户同意或撤回协议，记录操作时间、IP及客户端
+// 只能同意现行版本；必须同意的协议不可撤回（如不再同意请注销账号）
+func (s *AgreementService) Consent(userID int64, req *AgreementConsentRequest, clientIP, userAgent string) (*model.AgreementConsentVO, error) {
+	agreement, err := s.getAgreement(req.AgreementID)
+	if err != nil {
+		return nil, err
+	}
+	if agreement.Status != model.AgreementStatusPublished {
+		return nil, errorcode.New(errorcode.ErrAgreementNotFound)
+	}
+	current, err := s.repo.GetCurrent(agreement.Type)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	if current.ID != agreement.ID {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "协议已更新，请阅读并同意最新版本")
+	}
+
+	action := req.Action
+	if action == "" {
+		action = model.AgreementConsentAccept
+	}
+	if action == model.AgreementConsentWithdraw && model.IsAgreementRequired(agreement.Type) {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该协议为使用服务必须同意的协议，不可撤回；如不再同意请申请注销账号")
+	}
+
+	patientID := int64(0)
+	if model.IsAgreementPatientScoped(agreement.Type) {
+		if req.PatientID <= 0 {
+			return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "请选择就诊人")
+		}
+		if _, _, err := loadPatientForRole(s.patientRepo, userID, req.PatientID, model.PatientRoleManager); err != nil {
+			return nil, err
+		}
+		patientID = req.PatientID
+	}
+
+	if action == model.AgreementConsentWithdraw {
+		latest, err := s.repo.LatestConsents(userID, patientID, []string{agreement.Type})
+		if err != nil {
+			return nil, errorcode.New(errorcode.ErrDatabase)
+		}
+		if c := latest[agreement.Type]; c == nil || c.Action != model.AgreementConsentAccept {
+			return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "尚未同意该协议，无需撤回")
+		}
+	}
+
+	if len(userAgent) > 256 {
+		userAgent = userAgent[:256]
+	}
+	consent := &model.AgreementConsent{
+		AgreementID: agreement.ID,
+		Type:        agreement.Type,
+		Version:     agreement.Version,
+		UserID:      userID,
+		PatientID:   patientID,
+		Action:      action,
+		IP:          clientIP,
+		UserAgent:   userAgent,
+	}
+	if err := s.repo.CreateConsent(consent); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return consent.ToVO(), nil
+}
+
+// ListMyConsents 查询用户本人的同意记录
+func (s *AgreementService) ListMyConsents(userID int64) ([]*model.AgreementConsentVO, error) {
+	list, err := s.repo.ListConsentsByUser(userID, agreementConsentListLimit)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	voList := make([]*model.AgreementConsentVO, 0, len(list))
+	for i := range list {
+		voList = append(voList, list[i].ToVO())
+	}
+	return voList, nil
+}
+
+// ListConsents 分页查询同意记录（管理后台审计）
+func (s *AgreementService) ListConsents(req *ListAgreementConsentsRequest) ([]*model.AgreementConsentVO, int64, error) {
+	startDate, endDate, err := parseConsentDateRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	list, total, err := s.repo.ListConsents(req.Page, req.PageSize, req.Type, req.Version, &req.UserID, &req.PatientID, startDate, endDate)
+	if err != nil {
+		return nil, 0, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	voList := make([]*model.AgreementConsentVO, 0, len(list))
+	for i := range list {
+		voList = append(voList, list[i].ToVO())
+	}
+	return voList, total, nil
+}
+
+// ExportConsents 导出同意记录（管理后台审计，按时间正序）
+func (s *AgreementService) ExportConsents(req *ExportAgreementConsentsRequest) ([]*model.AgreementConsentVO, error) {
+	startDate, endDate, err := parseConsentDateRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.repo.ListConsentsForExport(req.Type, req.Version, &req.UserID, &req.PatientID, startDate, endDate, agreementConsentExportLimit)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	voList := make([]*model.AgreementConsentVO, 0, len(list))
+	for i := range list {
+		voList = append(voList, list[i].ToVO())
+	}
+	return voList, nil
+}
+
+// getAgreement 查询协议版本
+func (s *AgreementService) getAgreement(id int64) (*model.Agreement, error) {
+	agreement, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrAgreementNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return agreement, nil
+}
+
+// checkVersion 校验同类型下版本号不重复
+func (s *AgreementService) checkVersion(agreementType, version string, excludeID int64) error {
+	if version == "" {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "请填写版本号")
+	}
+	exists, err := s.repo.ExistsVersion(agreementType, version, excludeID)
+	if err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if exists {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该版本号已存在")
+	}
+	return nil
+}
+
+// parseConsentDateRange 解析审计查询的日期范围（结束日期包含当天）
+func parseConsentDateRange(start, end string) (*time.Time, *time.Time, error) {
+	var startDate, endDate *time.Time
+	if start != "" {
+		sd, err := utils.ParseDate(start)
+		if err != nil {
+			return nil, nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "开始日期格式错误")
+		}
+		startDate = &sd
+	}
+	if end != "" {
+		ed, err := utils.ParseDate(end)
+		if err != nil {
+			return nil, nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "结束日期格式错误")
+		}
+		tmp := ed.Add(24*time.Hour - time.Nanosecond)
+		endDate = &tmp
+	}
+	return startDate, endDate, nil
+}
@@ -19,8 +19,11 @@ type AppointmentService struct {
 	scheduleRepo *repository.ScheduleRepository
 	patientRepo  *repository.PatientRepository
 	doctorRepo   *repository.DoctorRepository
+	deptRepo     *repository.DepartmentRepository
 	userRepo     *repository.UserRepository
 	tokenService *TokenService
+
+	triageService *TriageService
 }
 
 // NewAppointmentService 创建预约服务实例
@@ -30,8 +33,11 @@ func NewAppointmentService() *AppointmentService {
 		scheduleRepo: repository.NewScheduleRepository(),
 		patientRepo:  repository.NewPatientRepository(),
 		doctorRepo:   repository.NewDoctorRepository(),
+		deptRepo:     repository.NewDepartmentRepository(),
 		userRepo:     repository.NewUserRepository(),
 		tokenService: NewTokenService(),
+
+		triageService: NewTriageService(),
 	}
 }
 
@@ -52,6 +58,7 @@ type ListAppointmentRequest struct {
 type ListAdminAppointmentRequest struct {
 	Page      int     `form:"page" binding:"required,min=1"`
 	PageSize  int     `form:"page_size" binding:"required,min=1,max=100"`
+	CampusID  *int64  `form:"campus_id"`
 	StartDate string  `form:"start_date"`
 	EndDate   string  `form:"end_date"`
 	Status    *string `form:"status"`
@@ -100,13 +107,23 @@ func (s *AppointmentService) Create(userID int64, req *CreateAppointmentRequest)
 		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该排班已过期")
 	}
 
-	// 4. 查询就诊人信息（验证就诊人是否存在且属于该用户）
-	_, err = s.patientRepo.GetByUserAndID(userID, req.PatientID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errorcode.New(errorcode.ErrPatientNotFound)
+	// 检查是否已放号（剩余号源为0但仍有未放出号源）
+	if schedule.AvailableSlots == 0 && schedule.UnreleasedSlots > 0 {
+		if tip := schedule.ToVO().ReleaseTip; tip != "" {
+			return nil, errorcode.NewWithMessage(errorcode.ErrScheduleNotReleased, "该排班尚未放号，"+tip)
 		}
-		return nil, errorcode.New(errorcode.ErrDatabase)
+		return nil, errorcode.New(errorcode.ErrScheduleNotReleased)
+	}
+
+	// 4. 查询就诊人信息（验证就诊人存在且用户为所有者或管理者，查看者不可预约）
+	patient, _, err := loadPatientForRole(s.patientRepo, userID, req.PatientID, model.PatientRoleManager)
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查就诊人是否符合科室接诊限制（年龄/性别）
+	if err := s.checkEligibility(schedule, patient); err != nil {
+		return nil, err
 	}
 
 	// 5. 检查是否已有同一医生同一时段的预约
@@ -118,46 +135,140 @@ func (s *AppointmentService) Create(userID int64, req *CreateAppointmentRequest)
 		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "您已预约该医生的该时段")
 	}
 
-	// 6. 使用事务创建预约并扣减号源
-	var appointment *model.Appointment
-	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
-		// 6.1 扣减号源（使用原子操作）
-		result := tx.Model(&model.Schedule{}).
-			Where("id = ? AND available_slots > 0", req.ScheduleID).
-			Update("available_slots", gorm.Expr("available_slots - 1"))
+	// 6. 创建预约并扣减线上号源
+	vo, err := s.book(schedule, userID, req.PatientID, req.Symptom, model.ChannelOnline)
+	if err != nil {
+		return nil, err
+	}
+
+	// 7. 症状与所选科室不太匹配时返回提示（不影响预约结果）
+	vo.TriageWarning = s.triageService.BookingWarning(patient, vo.DepartmentID, req.Symptom)
+	return vo, nil
+}
+
+// CreateByAdminRequest 管理后台渠道挂号请求（现场/VIP）
+type CreateByAdminRequest struct {
+	ScheduleID int64  `json:"schedule_id" binding:"required,min=1"`
+	PatientID  int64  `json:"patient_id" binding:"required,min=1"`
+	Channel    string `json:"channel" binding:"required,oneof=onsite vip"`
+	Symptom    string `json:"symptom" binding:"max=512"`
 
-		if result.Error != nil {
-			return result.Error
+	// 忽略科室接诊限制（年龄/性别），由工作人员确认特殊情况后使用，请求记录于操作日志
+	OverrideEligibility bool `json:"override_eligibility"`
+}
+
+// CreateByAdmin 管理后台渠道挂号（现场挂号/院内安排）
+// 从对应渠道的预留号源扣减，预留号源转入公共池后从公共号源池扣减
+func (s *AppointmentService) CreateByAdmin(req *CreateByAdminRequest) (*model.AppointmentVO, error) {
+	// 1. 查询排班信息
+	schedule, err := s.scheduleRepo.GetByID(req.ScheduleID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrScheduleNotFound)
 		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
 
-		// 如果影响行数为0，说明号源不足
-		if result.RowsAffected == 0 {
-			return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "号源已满")
+	// 检查排班状态
+	if schedule.Status == model.StatusDisabled {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该排班已停诊")
+	}
+
+	// 检查排班日期是否已过期
+	if schedule.ScheduleDate.Before(utils.GetTodayStart()) {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该排班已过期")
+	}
+
+	// 2. 查询就诊人信息
+	patient, err := s.patientRepo.GetByID(req.PatientID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrPatientNotFound)
 		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	// 检查就诊人是否符合科室接诊限制（工作人员可确认后忽略）
+	if !req.OverrideEligibility {
+		if err := s.checkEligibility(schedule, patient); err != nil {
+			return nil, err
+		}
+	}
+
+	// 3. 检查是否已有同一医生同一时段的预约
+	hasAppointment, err := s.repo.CheckUserPendingAppointment(patient.UserID, schedule.DoctorID, schedule.ScheduleDate, schedule.Period)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	if hasAppointment {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该用户已预约该医生的该时段")
+	}
 
-		// 6.2 获取号序
-		slotNumber, err := s.repo.GetNextSlotNumber(req.ScheduleID)
+	// 4. 创建预约并扣减渠道号源
+	vo, err := s.book(schedule, patient.UserID, patient.ID, req.Symptom, req.Channel)
+	if err != nil {
+		return nil, err
+	}
+
+	// 5. 症状与所选科室不太匹配时返回提示（不影响挂号结果）
+	vo.TriageWarning = s.triageService.BookingWarning(patient, vo.DepartmentID, req.Symptom)
+	return vo, nil
+}
+
+// checkEligibility 检查就诊人在就诊日的年龄及性别是否符合出诊科室（含上级科室）的接诊限制
+func (s *AppointmentService) checkEligibility(schedule *model.Schedule, patient *model.Patient) error {
+	eligibility, err := newDepartmentEligibility(s.deptRepo)
+	if err != nil {
+		return err
+	}
+	if reason := eligibility.reason(schedule.SessionDepartmentID(), patient.AgeAt(schedule.ScheduleDate), patient.Gender); reason != "" {
+		return errorcode.NewWithMessage(errorcode.ErrDepartmentIneligible, reason)
+	}
+	return nil
+}
+
+// book 使用事务扣减号源并创建预约
+func (s *AppointmentService) book(schedule *model.Schedule, userID, patientID int64, symptom, channel string) (*model.AppointmentVO, error) {
+	var appointment *model.Appointment
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		// 扣减号源（使用原子操作）
+		slotChannel, err := s.scheduleRepo.DecrChannelSlot(tx, schedule.ID, channel)
 		if err != nil {
 			return err
 		}
 
-		// 6.3 生成预约编号
-		appointmentNo := utils.GenerateAppointmentNo()
+		// 未扣减成功，说明号源不足
+		if slotChannel == "" {
+			if channel == model.ChannelOnline {
+				return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "号源已满")
+			}
+			return errorcode.NewWithMessage(errorcode.ErrInvalidParams, model.GetChannelName(channel)+"号源已满")
+		}
+
+		// 获取号序
+		slotNumber, err := s.repo.GetNextSlotNumber(schedule.ID)
+		if err != nil {
+			return err
+		}
 
-		// 6.4 创建预约
+		// 创建预约
 		appointment = &model.Appointment{
-			AppointmentNo:   appointmentNo,
+			AppointmentNo:   utils.GenerateAppointmentNo(),
 			UserID:          userID,
-			PatientID:       req.PatientID,
+			PatientID:       patientID,
 			DoctorID:        schedule.DoctorID,
-			DepartmentID:    schedule.Doctor.DepartmentID,
-			ScheduleID:      req.ScheduleID,
+			DepartmentID:    schedule.SessionDepartmentID(),
+			ScheduleID:      schedule.ID,
+			CampusID:        schedule.CampusID,
+			RoomID:          schedule.RoomID,
 			AppointmentDate: schedule.ScheduleDate,
 			Period:          schedule.Period,
 			AppointmentTime: schedule.StartTime,
 			SlotNumber:      slotNumber,
 			Status:          model.AppointmentStatusPending,
-			Symptom:         req.Symptom,
+			Symptom:         symptom,
+			Channel:         channel,
+			SlotChannel:     slotChannel,
 		}
 
 		return s.repo.Create(tx, appointment)
@@ -166,8 +277,9 @@ func (s *AppointmentService) Create(userID int64, req *CreateAppointmentRequest)
 	if err != nil {
 		return nil, err
 	}
+	invalidateDoctorScheduleCache(schedule.DoctorID)
 
-	// 7. 重新查询以获取完整数据
+	// 重新查询以获取完整数据
 	appointment, err = s.repo.GetByID(appointment.ID)
 	if err != nil {
 		return nil, errorcode.New(errorcode.ErrDatabase)
@@ -187,6 +299,11 @@ func (s *AppointmentService) Cancel(userID, appointmentID int64, req *CancelAppo
 		return errorcode.New(errorcode.ErrDatabase)
 	}
 
+	// 共享就诊人的预约需为所有者或管理者才可操作
+	if err := s.checkAppointmentOperator(userID, appointment); err != nil {
+		return err
+	}
+
 	// 2. 检查预约状态
 	if appointment.Status != model.AppointmentStatusPending {
 		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "只能取消待就诊的预约")
@@ -209,11 +326,15 @@ func (s *AppointmentService) Cancel(userID, appointmentID int64, req *CancelAppo
 			return err
 		}
 
-		// 4.2 返还号源
-		return s.scheduleRepo.UpdateAvailableSlots(appointment.ScheduleID, 1)
+		// 4.2 返还号源（返还到号源来源渠道）
+		return s.scheduleRepo.ReturnChannelSlot(tx, appointment.ScheduleID, appointment.SlotChannel)
 	})
+	if err != nil {
+		return err
+	}
+	invalidateDoctorScheduleCache(appointment.DoctorID)
 
-	return err
+	return nil
 }
 
 // Checkin 预约签到
@@ -227,6 +348,11 @@ func (s *AppointmentService) Checkin(userID, appointmentID int64) error {
 		return errorcode.New(errorcode.ErrDatabase)
 	}
 
+	// 共享就诊人的预约需为所有者或管理者才可操作
+	if err := s.checkAppointmentOperator(userID, appointment); err != nil {
+		return err
+	}
+
 	// 2. 检查预约状态
 	if appointment.Status != model.AppointmentStatusPending {
 		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "只能签到待就诊的预约")
@@ -242,7 +368,20 @@ func (s *AppointmentService) Checkin(userID, appointmentID int64) error {
 	updates := map[string]interface{}{
 		"checked_in_at": now,
 	}
-	return s.repo.UpdateStatus(appointmentID, model.AppointmentStatusCheckedIn, updates)
+	if err := s.repo.UpdateStatus(appointmentID, model.AppointmentStatusCheckedIn, updates); err != nil {
+		return err
+	}
+	invalidateDoctorScheduleCache(appointment.DoctorID)
+	return nil
+}
+
+// checkAppointmentOperator 校验用户可操作预约：预约人本人，或就诊人的所有者/管理者
+func (s *AppointmentService) checkAppointmentOperator(userID int64, appointment *model.Appointment) error {
+	if appointment.UserID == userID {
+		return nil
+	}
+	_, _, err := loadPatientForRole(s.patientRepo, userID, appointment.PatientID, model.PatientRoleManager)
+	return err
 }
 
 // GetByID 获取预约详情
@@ -305,7 +444,7 @@ func (s *AppointmentService) List(req *ListAdminAppointmentRequest) ([]model.App
 		endDate = &ed
 	}
 
-	appointments, total, err := s.repo.List(req.Page, req.PageSize, startDate, endDate, req.Status, req.Keyword)
+	appointments, total, err := s.repo.List(req.Page, req.PageSize, req.CampusID, startDate, endDate, req.Status, req.Keyword)
 	if err != nil {
 		return nil, 0, errorcode.New(errorcode.ErrDatabase)
 	}
@@ -358,7 +497,11 @@ func (s *AppointmentService) UpdateStatus(appointmentID int64, req *UpdateAppoin
 		// 爽约状态无需设置特定时间
 	}
 
-	return s.repo.UpdateStatus(appointmentID, req.Status, updates)
+	if err := s.repo.UpdateStatus(appointmentID, req.Status, updates); err != nil {
+		return err
+	}
+	invalidateDoctorScheduleCache(appointment.DoctorID)
+	return nil
 }
 
 // isValidStatusTransition 检查状态转换是否合法
@@ -0,0 +1,401 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/internal/repository"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/utils"
+)
+
+// CampusService 院区服务（含诊室管理）
+type CampusService struct {
+	repo       *repository.CampusRepository
+	roomRepo   *repository.RoomRepository
+	deptRepo   *repository.DepartmentRepository
+	doctorRepo *repository.DoctorRepository
+}
+
+// NewCampusService 创建院区服务实例
+func NewCampusService() *CampusService {
+	return &CampusService{
+		repo:       repository.NewCampusRepository(),
+		roomRepo:   repository.NewRoomRepository(),
+		deptRepo:   repository.NewDepartmentRepository(),
+		doctorRepo: repository.NewDoctorRepository(),
+	}
+}
+
+// CreateCampusRequest 创建院区请求
+type CreateCampusRequest struct {
+	Name         string  `json:"name" binding:"required,min=2,max=64"`
+	Address      string  `json:"address" binding:"required,max=256"`
+	Latitude     float64 `json:"latitude" binding:"min=-90,max=90"`
+	Longitude    float64 `json:"longitude" binding:"min=-180,max=180"`
+	Phone        string  `json:"phone" binding:"max=32"`
+	OpeningHours string  `json:"opening_hours" binding:"max=128"` // 如"周一至周日 07:30-17:30"
+	Description  string  `json:"description" binding:"max=512"`
+	SortOrder    int     `json:"sort_order"`
+	Status       int     `json:"status" binding:"oneof=0 1"`
+}
+
+// UpdateCampusRequest 更新院区请求
+type UpdateCampusRequest = CreateCampusRequest
+
+// ListCampusRequest 院区列表请求
+type ListCampusRequest struct {
+	Page     int    `form:"page" binding:"required,min=1"`
+	PageSize int    `form:"page_size" binding:"required,min=1,max=100"`
+	Keyword  string `form:"keyword"`
+	Status   *int   `form:"status"`
+}
+
+// CreateRoomRequest 创建诊室请求
+type CreateRoomRequest struct {
+	CampusID     int64  `json:"campus_id" binding:"required,min=1"`
+	DepartmentID int64  `json:"department_id" binding:"min=0"` // 所属科室，0为院区公共诊室
+	Name         string `json:"name" binding:"required,max=64"`
+	Location     string `json:"location" binding:"max=128"` // 楼栋/楼层，如"门诊楼3楼"
+	SortOrder    int    `json:"sort_order"`
+	Status       int    `json:"status" binding:"oneof=0 1"`
+}
+
+// UpdateRoomRequest 更新诊室请求
+type UpdateRoomRequest = CreateRoomRequest
+
+// ListRoomRequest 诊室列表请求
+type ListRoomRequest struct {
+	Page         int    `form:"page" binding:"required,min=1"`
+	PageSize     int    `form:"page_size" binding:"required,min=1,max=100"`
+	CampusID     *int64 `form:"campus_id"`
+	DepartmentID *int64 `form:"department_id"`
+	Keyword      string `form:"keyword"`
+	Status       *int   `form:"status"`
+}
+
+// ListPublic 启用的院区列表（公开接口）
+func (s *CampusService) ListPublic() ([]model.CampusVO, error) {
+	campuses, err := s.repo.ListEnabled()
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	list := make([]model.CampusVO, len(campuses))
+	for i := range campuses {
+		list[i] = *campuses[i].ToVO()
+	}
+	return list, nil
+}
+
+// List 分页查询院区（管理后台）
+func (s *CampusService) List(req *ListCampusRequest) ([]model.CampusVO, int64, error) {
+	campuses, total, err := s.repo.List(req.Page, req.PageSize, strings.TrimSpace(req.Keyword), req.Status)
+	if err != nil {
+		return nil, 0, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	list := make([]model.CampusVO, len(campuses))
+	for i := range campuses {
+		list[i] = *campuses[i].ToVO()
+	}
+	return list, total, nil
+}
+
+// GetByID 获取院区详情
+func (s *CampusService) GetByID(id int64) (*model.CampusVO, error) {
+	campus, err := s.getCampus(id)
+	if err != nil {
+		return nil, err
+	}
+	return campus.ToVO(), nil
+}
+
+// Create 创建院区
+func (s *CampusService) Create(req *CreateCampusRequest) (*model.CampusVO, error) {
+	name := strings.TrimSpace(req.Name)
+	if existing, _ := s.repo.GetByName(name); existing != nil {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "院区名称已存在")
+	}
+
+	campus := &model.Campus{}
+	applyCampusRequest(campus, req)
+	if err := s.repo.Create(campus); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return campus.ToVO(), nil
+}
+
+// Update 更新院区
+func (s *CampusService) Update(id int64, req *UpdateCampusRequest) (*model.CampusVO, error) {
+	campus, err := s.getCampus(id)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if existing, _ := s.repo.GetByName(name); existing != nil && existing.ID != id {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "院区名称已存在")
+	}
+
+	applyCampusRequest(campus, req)
+	if err := s.repo.Update(campus); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return campus.ToVO(), nil
+}
+
+// Delete 删除院区（院区下仍有科室或诊室时不可删除）
+func (s *CampusService) Delete(id int64) error {
+	if _, err := s.getCampus(id); err != nil {
+		return err
+	}
+
+	hasDepartments, err := s.repo.HasDepartments(id)
+	if err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	hasRooms, err := s.repo.HasRooms(id)
+	if err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if hasDepartments || hasRooms {
+		return errorcode.New(errorcode.ErrCampusInUse)
+	}
+
+	if err := s.repo.Delete(id); err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	return nil
+}
+
+// ListRooms 分页查询诊室
+func (s *CampusService) ListRooms(req *ListRoomRequest) ([]model.RoomVO, int64, error) {
+	rooms, total, err := s.roomRepo.List(req.Page, req.PageSize, req.CampusID, req.DepartmentID, strings.TrimSpace(req.Keyword), req.Status)
+	if err != nil {
+		return nil, 0, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	list := make([]model.RoomVO, len(rooms))
+	for i := range rooms {
+		list[i] = *rooms[i].ToVO()
+	}
+	return list, total, nil
+}
+
+// GetRoom 获取诊室详情
+func (s *CampusService) GetRoom(id int64) (*model.RoomVO, error) {
+	room, err := s.getRoom(id)
+	if err != nil {
+		return nil, err
+	}
+	return room.ToVO(), nil
+}
+
+// CreateRoom 创建诊室
+func (s *CampusService) CreateRoom(req *CreateRoomRequest) (*model.RoomVO, error) {
+	if err := s.validateRoom(0, req); err != nil {
+		return nil, err
+	}
+
+	room := &model.Room{}
+	applyRoomRequest(room, req)
+	if err := s.roomRepo.Create(room); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return s.GetRoom(room.ID)
+}
+
+// UpdateRoom 更新诊室
+func (s *CampusService) UpdateRoom(id int64, req *UpdateRoomRequest) (*model.RoomVO, error) {
+	room, err := s.getRoom(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// 仍有未来排班的诊室不能迁移到其他院区
+	if req.CampusID != room.CampusID {
+		hasSchedules, err := s.roomRepo.HasSchedules(id, utils.GetTodayStart())
+		if err != nil {
+			return nil, errorcode.New(errorcode.ErrDatabase)
+		}
+		if hasSchedules {
+			return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该诊室仍有排班，无法变更院区")
+		}
+	}
+	if err := s.validateRoom(id, req); err != nil {
+		return nil, err
+	}
+
+	applyRoomRequest(room, req)
+	if err := s.roomRepo.Update(room); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return s.GetRoom(id)
+}
+
+// DeleteRoom 删除诊室（仍有未来排班时不可删除）
+func (s *CampusService) DeleteRoom(id int64) error {
+	if _, err := s.getRoom(id); err != nil {
+		return err
+	}
+
+	hasSchedules, err := s.roomRepo.HasSchedules(id, utils.GetTodayStart())
+	if err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if hasSchedules {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该诊室仍有排班，无法删除")
+	}
+
+	if err := s.roomRepo.Delete(id); err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	return nil
+}
+
+// AuthorizeCampus 校验院区管理员是否可操作指定院区
+// scope 为当前请求的院区权限范围，0 表示拥有全院权限
+func (s *CampusService) AuthorizeCampus(scope, campusID int64) error {
+	if scope == 0 || campusID == scope {
+		return nil
+	}
+	return errorcode.NewWithMessage(errorcode.ErrPermissionDenied, "无权操作其他院区的数据")
+}
+
+// AuthorizeDepartment 校验院区管理员是否可操作指定科室
+func (s *CampusService) AuthorizeDepartment(scope, departmentID int64) error {
+	return s.authorizeRecord(scope, model.Department{}.TableName(), departmentID)
+}
+
+// AuthorizeDoctorDepartment 校验院区管理员是否可为医生在指定出诊科室排班（departmentID 为 0 时取医生主科室）
+func (s *CampusService) AuthorizeDoctorDepartment(scope, doctorID, departmentID int64) error {
+	if scope == 0 {
+		return nil
+	}
+	if departmentID == 0 {
+		doctor, err := s.doctorRepo.GetByIDSimple(doctorID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return errorcode.New(errorcode.ErrDatabase)
+		}
+		departmentID = doctor.DepartmentID
+	}
+	return s.AuthorizeDepartment(scope, departmentID)
+}
+
+// AuthorizeSchedule 校验院区管理员是否可操作指定排班
+func (s *CampusService) AuthorizeSchedule(scope, scheduleID int64) error {
+	return s.authorizeRecord(scope, model.Schedule{}.TableName(), scheduleID)
+}
+
+// AuthorizeAppointment 校验院区管理员是否可操作指定预约
+func (s *CampusService) AuthorizeAppointment(scope, appointmentID int64) error {
+	return s.authorizeRecord(scope, model.Appointment{}.TableName(), appointmentID)
+}
+
+// AuthorizeRoom 校验院区管理员是否可操作指定诊室
+func (s *CampusService) AuthorizeRoom(scope, roomID int64) error {
+	return s.authorizeRecord(scope, model.Room{}.TableName(), roomID)
+}
+
+// authorizeRecord 按记录所属院区校验权限，记录不存在时放行，由后续业务返回不存在错误
+func (s *CampusService) authorizeRecord(scope int64, table string, id int64) error {
+	if scope == 0 || id == 0 {
+		return nil
+	}
+
+	campusID, err := s.repo.CampusIDOf(table, id)
+	if err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if campusID == 0 {
+		return nil
+	}
+	return s.AuthorizeCampus(scope, campusID)
+}
+
+// validateRoom 校验诊室所属院区、科室及名称
+func (s *CampusService) validateRoom(id int64, req *CreateRoomRequest) error {
+	campus, err := s.getCampus(req.CampusID)
+	if err != nil {
+		return err
+	}
+	if id == 0 && campus.Status == model.StatusDisabled {
+		return errorcode.New(errorcode.ErrCampusDisabled)
+	}
+
+	if req.DepartmentID > 0 {
+		dept, err := s.deptRepo.GetByID(req.DepartmentID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errorcode.New(errorcode.ErrDepartmentNotFound)
+			}
+			return errorcode.New(errorcode.ErrDatabase)
+		}
+		if dept.CampusID != req.CampusID {
+			return errorcode.NewWithMessage(errorcode.ErrRoomCampusMismatch, "所选科室不属于该院区")
+		}
+	}
+
+	exists, err := s.roomRepo.ExistsName(req.CampusID, strings.TrimSpace(req.Name), id)
+	if err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if exists {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该院区下诊室名称已存在")
+	}
+	return nil
+}
+
+// getCampus 查询院区
+func (s *CampusService) getCampus(id int64) (*model.Campus, error) {
+	campus, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrCampusNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return campus, nil
+}
+
+// getRoom 查询诊室
+func (s *CampusService) getRoom(id int64) (*model.Room, error) {
+	room, err := s.roomRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrRoomNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return room, nil
+}
+
+// applyCampusRequest 将请求字段写入院区
+func applyCampusRequest(campus *model.Campus, req *CreateCampusRequest) {
+	campus.Name = strings.TrimSpace(req.Name)
+	campus.Address = strings.TrimSpace(req.Address)
+	campus.Latitude = req.Latitude
+	campus.Longitude = req.Longitude
+	campus.Phone = strings.TrimSpace(req.Phone)
+	campus.OpeningHours = strings.TrimSpace(req.OpeningHours)
+	campus.Description = req.Description
+	campus.SortOrder = req.SortOrder
+	campus.Status = req.Status
+}
+
+// applyRoomRequest 将请求字段写入诊室
+func applyRoomRequest(room *model.Room, req *CreateRoomRequest) {
+	room.CampusID = req.CampusID
+	room.DepartmentID = req.DepartmentID
+	room.Name = strings.TrimSpace(req.Name)
+	room.Location = strings.TrimSpace(req.Location)
+	room.SortOrder = req.SortOrder
+	room.Status = req.Status
+}
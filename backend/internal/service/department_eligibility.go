@@ -0,0 +1,80 @@
+package service
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/internal/repository"
+	"huaan-medical/pkg/errorcode"
+)
+
+// departmentEligibility 科室接诊限制检查（年龄/性别），下级科室同时受上级科室的限制约束
+type departmentEligibility struct {
+	departments map[int64]*model.Department
+}
+
+// newDepartmentEligibility 加载全部科室的接诊限制
+func newDepartmentEligibility(repo *repository.DepartmentRepository) (*departmentEligibility, error) {
+	list, err := repo.ListAllIncludeDisabled()
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	departments := make(map[int64]*model.Department, len(list))
+	for i := range list {
+		departments[list[i].ID] = &list[i]
+	}
+	return &departmentEligibility{departments: departments}, nil
+}
+
+// reason 返回就诊人不符合科室（含上级科室）接诊限制的原因，符合时返回空字符串
+func (e *departmentEligibility) reason(departmentID int64, age *int, gender int) string {
+	id := departmentID
+	// 限制层级深度，防止脏数据形成循环
+	for depth := 0; depth < 16 && id > 0; depth++ {
+		dept, ok := e.departments[id]
+		if !ok {
+			return ""
+		}
+		if reason := dept.EligibilityReason(age, gender); reason != "" {
+			return reason
+		}
+		id = dept.ParentID
+	}
+	return ""
+}
+
+// eligible 判断就诊人是否符合科室（含上级科室）接诊限制
+func (e *departmentEligibility) eligible(departmentID int64, age *int, gender int) bool {
+	return e.reason(departmentID, age, gender) == ""
+}
+
+// eligibleForDoctor 判断就诊人是否可在医生的任一执业科室就诊（需预加载 Affiliations）
+func (e *departmentEligibility) eligibleForDoctor(doctor *model.Doctor, age *int, gender int) bool {
+	if e.eligible(doctor.DepartmentID, age, gender) {
+		return true
+	}
+	for _, a := range doctor.Affiliations {
+		if e.eligible(a.DepartmentID, age, gender) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadEligibilityPatient 查询用于科室/医生列表筛选的就诊人（需登录且为本人添加的就诊人）
+func loadEligibilityPatient(patientRepo *repository.PatientRepository, userID, patientID int64) (*model.Patient, error) {
+	if userID == 0 {
+		return nil, errorcode.NewWithMessage(errorcode.ErrUnauthorized, "请先登录后再按就诊人筛选")
+	}
+	patient, err := patientRepo.GetByUserAndID(userID, patientID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrPatientNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return patient, nil
+}
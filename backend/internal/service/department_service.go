@@ -8,66 +8,116 @@ import (
 	"huaan-medical/internal/model"
 	"huaan-medical/internal/repository"
 	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/utils"
 )
 
 // DepartmentService 科室服务
 type DepartmentService struct {
-	repo *repository.DepartmentRepository
+	repo        *repository.DepartmentRepository
+	patientRepo *repository.PatientRepository
+	campusRepo  *repository.CampusRepository
+
+	searchService *SearchService
 }
 
 // NewDepartmentService 创建科室服务实例
 func NewDepartmentService() *DepartmentService {
 	return &DepartmentService{
-		repo: repository.NewDepartmentRepository(),
+		repo:        repository.NewDepartmentRepository(),
+		patientRepo: repository.NewPatientRepository(),
+		campusRepo:  repository.NewCampusRepository(),
+
+		searchService: NewSearchService(),
 	}
 }
 
 // CreateRequest 创建科室请求
 type CreateDepartmentRequest struct {
+	CampusID    int64  `json:"campus_id" binding:"min=0"` // 所属院区ID，一级科室必填，下级科室默认与上级科室相同
+	ParentID    int64  `json:"parent_id" binding:"min=0"` // 上级科室ID，0为一级科室
 	Name        string `json:"name" binding:"required,min=2,max=64"`
 	Description string `json:"description" binding:"max=512"`
 	Icon        string `json:"icon" binding:"max=256"`
 	SortOrder   int    `json:"sort_order"`
 	Status      int    `json:"status"`
+	MinAge      int    `json:"min_age" binding:"min=0,max=150"` // 接诊最小年龄（含），0不限
+	MaxAge      int    `json:"max_age" binding:"min=0,max=150"` // 接诊最大年龄（含），0不限
+	Gender      int    `json:"gender" binding:"oneof=0 1 2"`    // 接诊性别，0不限
 }
 
 // UpdateRequest 更新科室请求
 type UpdateDepartmentRequest struct {
+	CampusID    *int64 `json:"campus_id" binding:"omitempty,min=1"` // 所属院区ID，不传则不调整
+	ParentID    *int64 `json:"parent_id" binding:"omitempty,min=0"` // 上级科室ID，不传则不调整
 	Name        string `json:"name" binding:"required,min=2,max=64"`
 	Description string `json:"description" binding:"max=512"`
 	Icon        string `json:"icon" binding:"max=256"`
 	SortOrder   int    `json:"sort_order"`
 	Status      int    `json:"status"`
+	MinAge      int    `json:"min_age" binding:"min=0,max=150"` // 接诊最小年龄（含），0不限
+	MaxAge      int    `json:"max_age" binding:"min=0,max=150"` // 接诊最大年龄（含），0不限
+	Gender      int    `json:"gender" binding:"oneof=0 1 2"`    // 接诊性别，0不限
+}
+
+// MoveDepartmentRequest 移动/排序科室请求
+type MoveDepartmentRequest struct {
+	ParentID  int64 `json:"parent_id" binding:"min=0"` // 目标上级科室ID，0为一级科室
+	SortOrder int   `json:"sort_order"`                // 在同级中的排序序号
 }
 
 // ListRequest 列表查询请求
 type ListDepartmentRequest struct {
-	Page     int  `form:"page" binding:"required,min=1"`
-	PageSize int  `form:"page_size" binding:"required,min=1,max=100"`
-	Status   *int `form:"status"`
+	Page     int    `form:"page" binding:"required,min=1"`
+	PageSize int    `form:"page_size" binding:"required,min=1,max=100"`
+	CampusID *int64 `form:"campus_id"`
+	Status   *int   `form:"status"`
+}
+
+// ListPublicDepartmentRequest 公开科室列表请求
+type ListPublicDepartmentRequest struct {
+	CampusID  *int64 `form:"campus_id"`  // 仅返回该院区的科室
+	PatientID *int64 `form:"patient_id"` // 仅返回该就诊人可就诊的科室（需登录）
 }
 
 // Create 创建科室
 func (s *DepartmentService) Create(req *CreateDepartmentRequest) (*model.DepartmentVO, error) {
-	// 检查名称是否重复
-	existing, _ := s.repo.GetByName(req.Name)
+	if err := s.validateParent(0, req.ParentID); err != nil {
+		return nil, err
+	}
+
+	campusID, err := s.resolveCampus(req.CampusID, req.ParentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查名称是否重复（院区内唯一）
+	existing, _ := s.repo.GetByCampusAndName(campusID, req.Name)
 	if existing != nil {
 		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "科室名称已存在")
 	}
+	if err := validateDepartmentEligibility(req.MinAge, req.MaxAge); err != nil {
+		return nil, err
+	}
 
 	dept := &model.Department{
+		CampusID:    campusID,
+		ParentID:    req.ParentID,
 		Name:        req.Name,
 		Description: req.Description,
 		Icon:        req.Icon,
 		SortOrder:   req.SortOrder,
 		Status:      req.Status,
+		MinAge:      req.MinAge,
+		MaxAge:      req.MaxAge,
+		Gender:      req.Gender,
 	}
 
 	if err := s.repo.Create(dept); err != nil {
 		return nil, errorcode.New(errorcode.ErrDatabase)
 	}
+	s.searchService.IndexDepartment(dept.ID)
 
-	return dept.ToVO(), nil
+	return s.GetByID(dept.ID)
 }
 
 // Update 更新科室
@@ -80,8 +130,29 @@ func (s *DepartmentService) Update(id int64, req *UpdateDepartmentRequest) (*mod
 		return nil, errorcode.New(errorcode.ErrDatabase)
 	}
 
-	// 检查名称是否重复（排除自己）
-	existing, _ := s.repo.GetByName(req.Name)
+	if err := validateDepartmentEligibility(req.MinAge, req.MaxAge); err != nil {
+		return nil, err
+	}
+
+	if req.CampusID != nil && *req.CampusID != dept.CampusID {
+		if err := s.validateCampusChange(dept, *req.CampusID, req.ParentID); err != nil {
+			return nil, err
+		}
+		dept.CampusID = *req.CampusID
+	}
+
+	if req.ParentID != nil && *req.ParentID != dept.ParentID {
+		if err := s.validateParent(id, *req.ParentID); err != nil {
+			return nil, err
+		}
+		if err := s.validateSameCampus(dept.CampusID, *req.ParentID); err != nil {
+			return nil, err
+		}
+		dept.ParentID = *req.ParentID
+	}
+
+	// 检查名称是否重复（院区内唯一，排除自己）
+	existing, _ := s.repo.GetByCampusAndName(dept.CampusID, req.Name)
 	if existing != nil && existing.ID != id {
 		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "科室名称已存在")
 	}
@@ -91,12 +162,16 @@ func (s *DepartmentService) Update(id int64, req *UpdateDepartmentRequest) (*mod
 	dept.Icon = req.Icon
 	dept.SortOrder = req.SortOrder
 	dept.Status = req.Status
+	dept.MinAge = req.MinAge
+	dept.MaxAge = req.MaxAge
+	dept.Gender = req.Gender
 
 	if err := s.repo.Update(dept); err != nil {
 		return nil, errorcode.New(errorcode.ErrDatabase)
 	}
+	s.searchService.IndexDepartment(id)
 
-	return dept.ToVO(), nil
+	return s.GetByID(id)
 }
 
 // Delete 删除科室
@@ -119,7 +194,20 @@ func (s *DepartmentService) Delete(id int64) error {
 		return errorcode.New(errorcode.ErrDepartmentHasDoctor)
 	}
 
-	return s.repo.Delete(id)
+	// 检查是否有子科室
+	hasChildren, err := s.repo.HasChildren(id)
+	if err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if hasChildren {
+		return errorcode.New(errorcode.ErrDepartmentHasChild)
+	}
+
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	s.searchService.RemoveDepartment(id)
+	return nil
 }
 
 // GetByID 获取科室详情
@@ -136,7 +224,7 @@ func (s *DepartmentService) GetByID(id int64) (*model.DepartmentVO, error) {
 
 // List 分页查询科室列表（管理后台）
 func (s *DepartmentService) List(req *ListDepartmentRequest) ([]model.DepartmentVO, int64, error) {
-	departments, total, err := s.repo.List(req.Page, req.PageSize, req.Status)
+	departments, total, err := s.repo.List(req.Page, req.PageSize, req.CampusID, req.Status)
 	if err != nil {
 		return nil, 0, errorcode.New(errorcode.ErrDatabase)
 	}
@@ -149,13 +237,19 @@ func (s *DepartmentService) List(req *ListDepartmentRequest) ([]model.Department
 	return voList, total, nil
 }
 
-// ListAll 查询所有启用的科室（公开接口）
-func (s *DepartmentService) ListAll() ([]model.DepartmentVO, error) {
+// ListAll 获取所有启用的科室（公开接口）
+// 指定就诊人时仅返回该就诊人符合接诊限制的科室
+func (s *DepartmentService) ListAll(userID int64, req *ListPublicDepartmentRequest) ([]model.DepartmentVO, error) {
 	departments, err := s.repo.ListAll()
 	if err != nil {
 		return nil, errorcode.New(errorcode.ErrDatabase)
 	}
 
+	departments = filterDepartmentsByCampus(departments, req.CampusID)
+	if departments, err = s.filterEligible(departments, userID, req.PatientID); err != nil {
+		return nil, err
+	}
+
 	voList := make([]model.DepartmentVO, len(departments))
 	for i, dept := range departments {
 		voList[i] = *dept.ToVO()
@@ -163,3 +257,293 @@ func (s *DepartmentService) ListAll() ([]model.DepartmentVO, error) {
 
 	return voList, nil
 }
+
+// Move 移动科室到新的上级科室并调整排序
+func (s *DepartmentService) Move(id int64, req *MoveDepartmentRequest) (*model.DepartmentVO, error) {
+	dept, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrDepartmentNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	if req.ParentID != dept.ParentID {
+		if err := s.validateParent(id, req.ParentID); err != nil {
+			return nil, err
+		}
+		if err := s.validateSameCampus(dept.CampusID, req.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.Move(id, req.ParentID, req.SortOrder); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	s.searchService.IndexDepartment(id)
+
+	dept.ParentID = req.ParentID
+	dept.SortOrder = req.SortOrder
+	return dept.ToVO(), nil
+}
+
+// Tree 查询科室树
+// enabledOnly 为 true 时仅返回启用的科室，停用科室的下级科室一并隐藏（公开接口）
+// campusID 不为空时仅返回该院区的科室
+func (s *DepartmentService) Tree(enabledOnly bool, campusID *int64) ([]model.DepartmentVO, error) {
+	var departments []model.Department
+	var err error
+	if enabledOnly {
+		departments, err = s.repo.ListAll()
+	} else {
+		departments, err = s.repo.ListAllIncludeDisabled()
+	}
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	departments = filterDepartmentsByCampus(departments, campusID)
+	return buildDepartmentTree(departments, !enabledOnly), nil
+}
+
+// TreePublic 启用科室树（公开接口），指定就诊人时仅返回该就诊人符合接诊限制的科室
+func (s *DepartmentService) TreePublic(userID int64, req *ListPublicDepartmentRequest) ([]model.DepartmentVO, error) {
+	departments, err := s.repo.ListAll()
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	departments = filterDepartmentsByCampus(departments, req.CampusID)
+	if departments, err = s.filterEligible(departments, userID, req.PatientID); err != nil {
+		return nil, err
+	}
+
+	return buildDepartmentTree(departments, false), nil
+}
+
+// filterEligible 按就诊人年龄/性别过滤科室（未指定就诊人时原样返回）
+func (s *DepartmentService) filterEligible(departments []model.Department, userID int64, patientID *int64) ([]model.Department, error) {
+	if patientID == nil || *patientID == 0 {
+		return departments, nil
+	}
+
+	patient, err := loadEligibilityPatient(s.patientRepo, userID, *patientID)
+	if err != nil {
+		return nil, err
+	}
+	eligibility, err := newDepartmentEligibility(s.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	age := patient.KnownAge()
+	result := make([]model.Department, 0, len(departments))
+	for _, dept := range departments {
+		if eligibility.eligible(dept.ID, age, patient.Gender) {
+			result = append(result, dept)
+		}
+	}
+	return result, nil
+}
+
+// resolveCampus 确定新建科室的所属院区：下级科室与上级科室相同，一级科室需指定启用的院区
+func (s *DepartmentService) resolveCampus(campusID, parentID int64) (int64, error) {
+	if parentID > 0 {
+		parent, err := s.repo.GetByID(parentID)
+		if err != nil {
+			return 0, errorcode.New(errorcode.ErrDepartmentParent)
+		}
+		if campusID > 0 && campusID != parent.CampusID {
+			return 0, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "下级科室须与上级科室属于同一院区")
+		}
+		return parent.CampusID, nil
+	}
+
+	if campusID == 0 {
+		return 0, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "请选择所属院区")
+	}
+	campus, err := s.campusRepo.GetByID(campusID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, errorcode.New(errorcode.ErrCampusNotFound)
+		}
+		return 0, errorcode.New(errorcode.ErrDatabase)
+	}
+	if campus.Status == model.StatusDisabled {
+		return 0, errorcode.New(errorcode.ErrCampusDisabled)
+	}
+	return campusID, nil
+}
+
+// validateCampusChange 校验科室变更院区
+// 仅允许（调整后的）一级科室变更，且科室下不能有子科室、诊室或今日及以后的排班
+func (s *DepartmentService) validateCampusChange(dept *model.Department, campusID int64, parentID *int64) error {
+	newParentID := dept.ParentID
+	if parentID != nil {
+		newParentID = *parentID
+	}
+	if newParentID != 0 {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "下级科室须与上级科室属于同一院区")
+	}
+
+	if _, err := s.resolveCampus(campusID, 0); err != nil {
+		return err
+	}
+
+	hasChildren, err := s.repo.HasChildren(dept.ID)
+	if err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	rooms, err := s.repo.CountRooms(dept.ID)
+	if err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	hasSchedules, err := s.repo.HasFutureSchedules(dept.ID, utils.GetTodayStart())
+	if err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if hasChildren || rooms > 0 || hasSchedules {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "科室下有子科室、诊室或排班，无法变更院区")
+	}
+	return nil
+}
+
+// validateSameCampus 校验上级科室与科室属于同一院区
+func (s *DepartmentService) validateSameCampus(campusID, parentID int64) error {
+	if parentID == 0 {
+		return nil
+	}
+	parent, err := s.repo.GetByID(parentID)
+	if err != nil {
+		return errorcode.New(errorcode.ErrDepartmentParent)
+	}
+	if parent.CampusID != campusID {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "下级科室须与上级科室属于同一院区")
+	}
+	return nil
+}
+
+// validateParent 校验上级科室：必须存在，且不能是自身或自身的下级科室
+// id 为 0 表示新建科室
+func (s *DepartmentService) validateParent(id, parentID int64) error {
+	if parentID == 0 {
+		return nil
+	}
+	if parentID == id {
+		return errorcode.New(errorcode.ErrDepartmentParent)
+	}
+
+	departments, err := s.repo.ListAllIncludeDisabled()
+	if err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+
+	parents := make(map[int64]int64, len(departments))
+	for _, d := range departments {
+		parents[d.ID] = d.ParentID
+	}
+	if _, ok := parents[parentID]; !ok {
+		return errorcode.New(errorcode.ErrDepartmentParent)
+	}
+
+	// 沿上级链向上查找，遇到自身即形成循环
+	if id > 0 {
+		current := parentID
+		for steps := 0; current != 0 && steps <= len(parents); steps++ {
+			if current == id {
+				return errorcode.New(errorcode.ErrDepartmentParent)
+			}
+			current = parents[current]
+		}
+	}
+
+	return nil
+}
+
+// filterDepartmentsByCampus 按院区过滤科室（未指定院区时原样返回）
+func filterDepartmentsByCampus(departments []model.Department, campusID *int64) []model.Department {
+	if campusID == nil || *campusID == 0 {
+		return departments
+	}
+
+	result := make([]model.Department, 0, len(departments))
+	for _, dept := range departments {
+		if dept.CampusID == *campusID {
+			result = append(result, dept)
+		}
+	}
+	return result
+}
+
+// buildDepartmentTree 将科室列表（已按排序规则排好序）组装为树
+// keepOrphans 为 true 时上级科室不在列表中的科室作为一级科室返回，否则连同其下级一并丢弃
+func buildDepartmentTree(departments []model.Department, keepOrphans bool) []model.DepartmentVO {
+	exists := make(map[int64]bool, len(departments))
+	for _, d := range departments {
+		exists[d.ID] = true
+	}
+
+	var roots []*model.Department
+	children := make(map[int64][]*model.Department)
+	for i := range departments {
+		d := &departments[i]
+		if d.ParentID == 0 || (keepOrphans && !exists[d.ParentID]) {
+			roots = append(roots, d)
+			continue
+		}
+		children[d.ParentID] = append(children[d.ParentID], d)
+	}
+
+	visited := make(map[int64]bool, len(departments))
+	var build func(d *model.Department) model.DepartmentVO
+	build = func(d *model.Department) model.DepartmentVO {
+		visited[d.ID] = true
+		vo := *d.ToVO()
+		for _, child := range children[d.ID] {
+			if visited[child.ID] {
+				continue
+			}
+			vo.Children = append(vo.Children, build(child))
+		}
+		return vo
+	}
+
+	tree := make([]model.DepartmentVO, 0, len(roots))
+	for _, root := range roots {
+		tree = append(tree, build(root))
+	}
+	return tree
+}
+
+// departmentRootIDs 计算每个科室所属的一级科室ID（用于统计向上汇总）
+func departmentRootIDs(departments []model.Department) map[int64]int64 {
+	parents := make(map[int64]int64, len(departments))
+	for _, d := range departments {
+		parents[d.ID] = d.ParentID
+	}
+
+	roots := make(map[int64]int64, len(departments))
+	for _, d := range departments {
+		current := d.ID
+		for steps := 0; steps <= len(parents); steps++ {
+			parentID, ok := parents[current]
+			if !ok || parentID == 0 {
+				break
+			}
+			if _, ok := parents[parentID]; !ok {
+				break
+			}
+			current = parentID
+		}
+		roots[d.ID] = current
+	}
+	return roots
+}
+
+// validateDepartmentEligibility 校验科室接诊年龄范围
+func validateDepartmentEligibility(minAge, maxAge int) error {
+	if maxAge > 0 && minAge > maxAge {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "接诊最小年龄不能大于最大年龄")
+	}
+	return nil
+}
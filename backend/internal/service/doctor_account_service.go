@@ -0,0 +1,179 @@
+package service
+
+import (
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/internal/repository"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/utils"
+)
+
+// DoctorAccountService 医生账号管理服务（管理后台）
+type DoctorAccountService struct {
+	repo       *repository.DoctorAccountRepository
+	doctorRepo *repository.DoctorRepository
+}
+
+// NewDoctorAccountService 创建医生账号管理服务实例
+func NewDoctorAccountService() *DoctorAccountService {
+	return &DoctorAccountService{
+		repo:       repository.NewDoctorAccountRepository(),
+		doctorRepo: repository.NewDoctorRepository(),
+	}
+}
+
+// ListDoctorAccountRequest 医生账号列表请求
+type ListDoctorAccountRequest struct {
+	Page     int    `form:"page" binding:"required,min=1"`
+	PageSize int    `form:"page_size" binding:"required,min=1,max=100"`
+	Keyword  string `form:"keyword"`
+	Status   *int   `form:"status"`
+}
+
+// CreateDoctorAccountRequest 开通医生账号请求
+type CreateDoctorAccountRequest struct {
+	DoctorID int64  `json:"doctor_id" binding:"required,min=1"`
+	Username string `json:"username" binding:"required,min=4,max=20"`
+	Password string `json:"password" binding:"required,min=6,max=32"`
+	Phone    string `json:"phone" binding:"max=20"`
+	Status   *int   `json:"status"`
+}
+
+// UpdateDoctorAccountRequest 更新医生账号请求
+type UpdateDoctorAccountRequest struct {
+	Phone  *string `json:"phone" binding:"omitempty,max=20"`
+	Status *int    `json:"status"`
+}
+
+// List 分页查询医生账号
+func (s *DoctorAccountService) List(req *ListDoctorAccountRequest) ([]model.DoctorAccountVO, int64, error) {
+	accounts, total, err := s.repo.List(req.Page, req.PageSize, req.Keyword, req.Status)
+	if err != nil {
+		return nil, 0, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	list := make([]model.DoctorAccountVO, len(accounts))
+	for i := range accounts {
+		list[i] = *accounts[i].ToVO()
+	}
+	return list, total, nil
+}
+
+// Create 为医生开通账号
+func (s *DoctorAccountService) Create(req *CreateDoctorAccountRequest) (*model.DoctorAccountVO, error) {
+	username := strings.TrimSpace(req.Username)
+	if !utils.ValidateUsername(username) {
+		return nil, errorcode.New(errorcode.ErrUsernameInvalid)
+	}
+
+	// 检查医生是否存在
+	if _, err := s.doctorRepo.GetByIDSimple(req.DoctorID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrDoctorNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	// 一名医生只能开通一个账号
+	if _, err := s.repo.GetByDoctorID(req.DoctorID); err == nil {
+		return nil, errorcode.New(errorcode.ErrDoctorAccountExists)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	// 检查用户名是否存在
+	if _, err := s.repo.GetByUsername(username); err == nil {
+		return nil, errorcode.New(errorcode.ErrUsernameExists)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	status := model.StatusEnabled
+	if req.Status != nil {
+		if *req.Status != model.StatusEnabled && *req.Status != model.StatusDisabled {
+			return nil, errorcode.New(errorcode.ErrInvalidParams)
+		}
+		status = *req.Status
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrInternalServer)
+	}
+
+	account := &model.DoctorAccount{
+		DoctorID: req.DoctorID,
+		Username: username,
+		Password: hashedPassword,
+		Phone:    strings.TrimSpace(req.Phone),
+		Status:   status,
+	}
+	if err := s.repo.Create(account); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	return s.GetByID(account.ID)
+}
+
+// GetByID 获取医生账号详情
+func (s *DoctorAccountService) GetByID(id int64) (*model.DoctorAccountVO, error) {
+	account, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrDoctorAccountNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return account.ToVO(), nil
+}
+
+// Update 更新医生账号（手机号、启用状态）
+func (s *DoctorAccountService) Update(id int64, req *UpdateDoctorAccountRequest) (*model.DoctorAccountVO, error) {
+	if _, err := s.GetByID(id); err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if req.Phone != nil {
+		updates["phone"] = strings.TrimSpace(*req.Phone)
+	}
+	if req.Status != nil {
+		if *req.Status != model.StatusEnabled && *req.Status != model.StatusDisabled {
+			return nil, errorcode.New(errorcode.ErrInvalidParams)
+		}
+		updates["status"] = *req.Status
+	}
+
+	if len(updates) > 0 {
+		if err := s.repo.Update(id, updates); err != nil {
+			return nil, errorcode.New(errorcode.ErrDatabase)
+		}
+	}
+
+	return s.GetByID(id)
+}
+
+// ResetPassword 重置医生账号密码
+func (s *DoctorAccountService) ResetPassword(id int64, req *ResetAdminPasswordRequest) error {
+	if _, err := s.GetByID(id); err != nil {
+		return err
+	}
+
+	if len(strings.TrimSpace(req.Password)) < 6 {
+		return errorcode.New(errorcode.ErrPasswordInvalid)
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return errorcode.New(errorcode.ErrInternalServer)
+	}
+
+	if err := s.repo.UpdatePassword(id, hashedPassword); err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	return nil
+}
@@ -0,0 +1,328 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/internal/repository"
+	"huaan-medical/pkg/database"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/utils"
+)
+
+// DoctorLeaveService 医生停诊申请服务
+// 流程：医生发起 -> 管理员审批 -> 停诊期间排班置为停诊、待就诊预约取消并通知患者
+type DoctorLeaveService struct {
+	repo                *repository.DoctorLeaveRepository
+	scheduleRepo        *repository.ScheduleRepository
+	appointmentRepo     *repository.AppointmentRepository
+	notificationService *NotificationService
+}
+
+// NewDoctorLeaveService 创建停诊申请服务实例
+func NewDoctorLeaveService() *DoctorLeaveService {
+	return &DoctorLeaveService{
+		repo:                repository.NewDoctorLeaveRepository(),
+		scheduleRepo:        repository.NewScheduleRepository(),
+		appointmentRepo:     repository.NewAppointmentRepository(),
+		notificationService: NewNotificationService(),
+	}
+}
+
+// doctorLeaveMaxDays 单次停诊申请最长天数
+const doctorLeaveMaxDays = 90
+
+// doctorLeaveCancelReason 停诊取消预约原因
+const doctorLeaveCancelReason = "医生停诊"
+
+// CreateDoctorLeaveRequest 发起停诊申请请求
+type CreateDoctorLeaveRequest struct {
+	StartDate string `json:"start_date" binding:"required"`
+	EndDate   string `json:"end_date" binding:"required"`
+	Period    string `json:"period" binding:"required,oneof=all morning afternoon"`
+	Reason    string `json:"reason" binding:"required,max=256"`
+}
+
+// ReviewDoctorLeaveRequest 审批停诊申请请求
+type ReviewDoctorLeaveRequest struct {
+	Approved *bool  `json:"approved" binding:"required"`
+	Remark   string `json:"remark" binding:"max=256"`
+}
+
+// ListDoctorLeaveRequest 停诊申请列表请求
+type ListDoctorLeaveRequest struct {
+	Page         int    `form:"page" binding:"required,min=1"`
+	PageSize     int    `form:"page_size" binding:"required,min=1,max=100"`
+	Status       string `form:"status"`
+	DoctorID     *int64 `form:"doctor_id"`
+	DepartmentID *int64 `form:"department_id"`
+}
+
+// Create 医生发起停诊申请
+func (s *DoctorLeaveService) Create(doctorID int64, req *CreateDoctorLeaveRequest) (*model.DoctorLeaveVO, error) {
+	startDate, err := utils.ParseDate(req.StartDate)
+	if err != nil {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "开始日期格式错误")
+	}
+	endDate, err := utils.ParseDate(req.EndDate)
+	if err != nil {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "结束日期格式错误")
+	}
+	if startDate.Before(utils.GetTodayStart()) {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "开始日期不能早于今天")
+	}
+	if endDate.Before(startDate) {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "结束日期不能早于开始日期")
+	}
+	if endDate.Sub(startDate) >= doctorLeaveMaxDays*24*time.Hour {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, fmt.Sprintf("单次停诊不能超过%d天", doctorLeaveMaxDays))
+	}
+
+	exists, err := s.repo.ExistsOverlap(doctorID, startDate, endDate, req.Period)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	if exists {
+		return nil, errorcode.New(errorcode.ErrDoctorLeaveConflict)
+	}
+
+	leave := &model.DoctorLeave{
+		DoctorID:  doctorID,
+		StartDate: startDate,
+		EndDate:   endDate,
+		Period:    req.Period,
+		Reason:    req.Reason,
+		Status:    model.DoctorLeaveStatusPending,
+	}
+	if err := s.repo.Create(leave); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	return s.GetByID(leave.ID)
+}
+
+// Cancel 医生撤销停诊申请（审批前可撤销）
+func (s *DoctorLeaveService) Cancel(id, doctorID int64) error {
+	leave, err := s.getLeave(id)
+	if err != nil {
+		return err
+	}
+	if leave.DoctorID != doctorID {
+		return errorcode.New(errorcode.ErrDoctorLeaveNotFound)
+	}
+	if leave.Status != model.DoctorLeaveStatusPending {
+		return errorcode.New(errorcode.ErrDoctorLeaveStatus)
+	}
+
+	ok, err := s.repo.UpdateStatus(nil, id, model.DoctorLeaveStatusPending, model.DoctorLeaveStatusCancelled, nil)
+	if err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if !ok {
+		return errorcode.New(errorcode.ErrDoctorLeaveStatus)
+	}
+	return nil
+}
+
+// Review 管理员审批停诊申请
+// 审批通过时在同一事务内停诊相关排班、取消待就诊预约并返还号源，事务后通知患者
+func (s *DoctorLeaveService) Review(id, adminID int64, req *ReviewDoctorLeaveRequest) error {
+	leave, err := s.getLeave(id)
+	if err != nil {
+		return err
+	}
+	if leave.Status != model.DoctorLeaveStatusPending {
+		return errorcode.New(errorcode.ErrDoctorLeaveStatus)
+	}
+
+	now := time.Now()
+	extra := map[string]interface{}{
+		"reviewer_id":   adminID,
+		"review_remark": req.Remark,
+		"reviewed_at":   now,
+	}
+
+	// 驳回
+	if !*req.Approved {
+		ok, err := s.repo.UpdateStatus(nil, id, model.DoctorLeaveStatusPending, model.DoctorLeaveStatusRejected, extra)
+		if err != nil {
+			return errorcode.New(errorcode.ErrDatabase)
+		}
+		if !ok {
+			return errorcode.New(errorcode.ErrDoctorLeaveStatus)
+		}
+		return nil
+	}
+
+	// 停诊期间内的正常排班
+	schedules, err := s.scheduleRepo.ListByRange(&leave.DoctorID, nil, leave.StartDate, leave.EndDate)
+	if err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	var affected []model.Schedule
+	for _, schedule := range schedules {
+		if schedule.Status != model.StatusEnabled {
+			continue
+		}
+		if leave.Period != model.PeriodAll && schedule.Period != leave.Period {
+			continue
+		}
+		affected = append(affected, schedule)
+	}
+
+	// 事务前查询待就诊预约，用于取消与通知
+	var appointments []model.Appointment
+	deptNames := make(map[int64]string, len(affected))
+	for _, schedule := range affected {
+		list, err := s.appointmentRepo.ListPendingBySchedule(schedule.ID)
+		if err != nil {
+			return errorcode.New(errorcode.ErrDatabase)
+		}
+		appointments = append(appointments, list...)
+		if schedule.Department != nil {
+			deptNames[schedule.ID] = schedule.Department.Name
+		}
+	}
+
+	var cancelled []model.Appointment
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		cancelled = cancelled[:0]
+		ok, err := s.repo.UpdateStatus(tx, id, model.DoctorLeaveStatusPending, model.DoctorLeaveStatusApproved, extra)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errorcode.New(errorcode.ErrDoctorLeaveStatus)
+		}
+
+		if len(affected) > 0 {
+			ids := make([]int64, len(affected))
+			for i, schedule := range affected {
+				ids[i] = schedule.ID
+			}
+			if err := tx.Model(&model.Schedule{}).Where("id IN ?", ids).
+				Update("status", model.StatusDisabled).Error; err != nil {
+				return err
+			}
+		}
+
+		// 取消待就诊预约并返还号源（乐观锁：仅取消仍为待就诊的预约）
+		for _, a := range appointments {
+			result := tx.Model(&model.Appointment{}).
+				Where("id = ? AND status = ?", a.ID, model.AppointmentStatusPending).
+				Updates(map[string]interface{}{
+					"status":        model.AppointmentStatusCancelled,
+					"cancel_reason": doctorLeaveCancelReason,
+					"cancelled_at":  now,
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				continue
+			}
+			if err := s.scheduleRepo.ReturnChannelSlot(tx, a.ScheduleID, a.SlotChannel); err != nil {
+				return err
+			}
+			cancelled = append(cancelled, a)
+		}
+		return nil
+	})
+	if err != nil {
+		var appErr *errorcode.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+
+	invalidateDoctorScheduleCache(leave.DoctorID)
+
+	// 通知受影响的患者
+	s.notificationService.Send(buildDoctorLeaveNotifications(cancelled, leave.Doctor, deptNames))
+
+	return nil
+}
+
+// GetByID 获取停诊申请详情
+func (s *DoctorLeaveService) GetByID(id int64) (*model.DoctorLeaveVO, error) {
+	leave, err := s.getLeave(id)
+	if err != nil {
+		return nil, err
+	}
+	return leave.ToVO(), nil
+}
+
+// GetByIDForDoctor 获取本人的停诊申请详情
+func (s *DoctorLeaveService) GetByIDForDoctor(id, doctorID int64) (*model.DoctorLeaveVO, error) {
+	leave, err := s.getLeave(id)
+	if err != nil {
+		return nil, err
+	}
+	if leave.DoctorID != doctorID {
+		return nil, errorcode.New(errorcode.ErrDoctorLeaveNotFound)
+	}
+	return leave.ToVO(), nil
+}
+
+// List 分页查询停诊申请
+func (s *DoctorLeaveService) List(req *ListDoctorLeaveRequest) ([]model.DoctorLeaveVO, int64, error) {
+	leaves, total, err := s.repo.List(req.Page, req.PageSize, req.Status, req.DoctorID, req.DepartmentID)
+	if err != nil {
+		return nil, 0, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	voList := make([]model.DoctorLeaveVO, len(leaves))
+	for i, leave := range leaves {
+		voList[i] = *leave.ToVO()
+	}
+
+	return voList, total, nil
+}
+
+// getLeave 查询停诊申请
+func (s *DoctorLeaveService) getLeave(id int64) (*model.DoctorLeave, error) {
+	leave, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrDoctorLeaveNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return leave, nil
+}
+
+// buildDoctorLeaveNotifications 构建医生停诊通知
+func buildDoctorLeaveNotifications(appointments []model.Appointment, doctor *model.Doctor, deptNames map[int64]string) []model.Notification {
+	doctorName := ""
+	if doctor != nil {
+		doctorName = doctor.Name
+	}
+
+	notifications := make([]model.Notification, 0, len(appointments))
+	for _, a := range appointments {
+		patientName := ""
+		if a.Patient != nil {
+			patientName = a.Patient.Name
+		}
+		notifications = append(notifications, model.Notification{
+			UserID: a.UserID,
+			Type:   model.NotificationTypeDoctorLeave,
+			Title:  "医生停诊通知",
+			Content: fmt.Sprintf("%s您好，您预约的%s %s%s%s医生门诊（预约号%s）因医生停诊已自动取消，请重新预约其他医生或时段。",
+				patientName,
+				a.AppointmentDate.Format("2006-01-02"),
+				model.GetPeriodName(a.Period),
+				deptNames[a.ScheduleID],
+				doctorName,
+				a.AppointmentNo,
+			),
+			BizID: a.ID,
+		})
+	}
+	return notifications
+}
@@ -0,0 +1,352 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/internal/repository"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/jwt"
+	"huaan-medical/pkg/utils"
+)
+
+// DoctorPortalService 医生工作台服务
+// 所有查询与操作均限定为 Token 中的医生本人数据
+type DoctorPortalService struct {
+	accountRepo     *repository.DoctorAccountRepository
+	doctorRepo      *repository.DoctorRepository
+	scheduleRepo    *repository.ScheduleRepository
+	appointmentRepo *repository.AppointmentRepository
+	userRepo        *repository.UserRepository
+	logRepo         *repository.LogRepository
+}
+
+// NewDoctorPortalService 创建医生工作台服务实例
+func NewDoctorPortalService() *DoctorPortalService {
+	return &DoctorPortalService{
+		accountRepo:     repository.NewDoctorAccountRepository(),
+		doctorRepo:      repository.NewDoctorRepository(),
+		scheduleRepo:    repository.NewScheduleRepository(),
+		appointmentRepo: repository.NewAppointmentRepository(),
+		userRepo:        repository.NewUserRepository(),
+		logRepo:         repository.NewLogRepository(),
+	}
+}
+
+// DoctorLoginResponse 医生登录响应
+type DoctorLoginResponse struct {
+	Token     string                 `json:"token"`
+	ExpiresIn int64                  `json:"expires_in"`
+	Account   *model.DoctorAccountVO `json:"account"`
+	Doctor    *model.DoctorVO        `json:"doctor"`
+}
+
+// DoctorInfoResponse 医生信息响应
+type DoctorInfoResponse struct {
+	Account *model.DoctorAccountVO `json:"account"`
+	Doctor  *model.DoctorVO        `json:"doctor"`
+}
+
+// ListDoctorScheduleRequest 医生排班查询请求
+type ListDoctorScheduleRequest struct {
+	StartDate string `form:"start_date"` // 默认今天
+	EndDate   string `form:"end_date"`   // 默认14天后
+}
+
+// ListDoctorAppointmentRequest 医生预约查询请求
+type ListDoctorAppointmentRequest struct {
+	Page       int     `form:"page" binding:"required,min=1"`
+	PageSize   int     `form:"page_size" binding:"required,min=1,max=100"`
+	StartDate  string  `form:"start_date"` // 默认今天
+	EndDate    string  `form:"end_date"`
+	ScheduleID *int64  `form:"schedule_id"`
+	Status     *string `form:"status"`
+}
+
+// doctorScheduleDefaultDays 医生排班默认查询天数
+const doctorScheduleDefaultDays = 14
+
+// Login 医生登录
+func (s *DoctorPortalService) Login(req *LoginRequest, clientIP string, userAgent string) (*DoctorLoginResponse, error) {
+	// 查询账号
+	account, err := s.accountRepo.GetByUsername(req.Username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.writeLoginLog(req.Username, 0, clientIP, userAgent, model.LoginStatusFailed, "账号不存在")
+			return nil, errorcode.New(errorcode.ErrDoctorAccountNotFound)
+		}
+		s.writeLoginLog(req.Username, 0, clientIP, userAgent, model.LoginStatusFailed, "数据库错误")
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	// 检查账号及医生状态
+	if account.Status == model.StatusDisabled {
+		s.writeLoginLog(account.Username, account.ID, clientIP, userAgent, model.LoginStatusFailed, "账号已禁用")
+		return nil, errorcode.New(errorcode.ErrAccountDisabled)
+	}
+	if account.Doctor == nil || account.Doctor.Status == model.StatusDisabled {
+		s.writeLoginLog(account.Username, account.ID, clientIP, userAgent, model.LoginStatusFailed, "医生已停用")
+		return nil, errorcode.New(errorcode.ErrAccountDisabled)
+	}
+
+	// 验证密码
+	if !utils.CheckPassword(req.Password, account.Password) {
+		s.writeLoginLog(account.Username, account.ID, clientIP, userAgent, model.LoginStatusFailed, "密码错误")
+		return nil, errorcode.New(errorcode.ErrPasswordWrong)
+	}
+
+	// 生成Token
+	token, err := jwt.GenerateDoctorToken(account.ID, account.DoctorID, account.Username)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrInternalServer)
+	}
+
+	// 更新登录信息
+	_ = s.accountRepo.UpdateLoginInfo(account.ID, clientIP)
+	s.writeLoginLog(account.Username, account.ID, clientIP, userAgent, model.LoginStatusSuccess, "登录成功")
+
+	doctor, err := s.doctorRepo.GetByID(account.DoctorID)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	return &DoctorLoginResponse{
+		Token:     token,
+		ExpiresIn: 7200, // 2小时
+		Account:   account.ToVO(),
+		Doctor:    doctor.ToVO(),
+	}, nil
+}
+
+func (s *DoctorPortalService) writeLoginLog(username string, accountID int64, ip string, userAgent string, status int, msg string) {
+	device := userAgent
+	if len(device) > 256 {
+		device = device[:256]
+	}
+
+	_ = s.logRepo.CreateLoginLog(&model.LoginLog{
+		UserType:  model.UserTypeDoctor,
+		UserID:    accountID,
+		Username:  username,
+		LoginType: model.LoginTypePassword,
+		IP:        ip,
+		Device:    device,
+		Status:    status,
+		Message:   msg,
+	})
+}
+
+// GetInfo 获取当前医生信息
+func (s *DoctorPortalService) GetInfo(accountID int64) (*DoctorInfoResponse, error) {
+	account, err := s.getAccount(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	doctor, err := s.doctorRepo.GetByID(account.DoctorID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrDoctorNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	return &DoctorInfoResponse{
+		Account: account.ToVO(),
+		Doctor:  doctor.ToVO(),
+	}, nil
+}
+
+// ChangePassword 修改密码
+func (s *DoctorPortalService) ChangePassword(accountID int64, req *ChangePasswordRequest) error {
+	account, err := s.getAccount(accountID)
+	if err != nil {
+		return err
+	}
+
+	// 验证旧密码
+	if !utils.CheckPassword(req.OldPassword, account.Password) {
+		return errorcode.New(errorcode.ErrPasswordWrong)
+	}
+
+	// 加密新密码
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return errorcode.New(errorcode.ErrInternalServer)
+	}
+
+	if err := s.accountRepo.UpdatePassword(accountID, hashedPassword); err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	return nil
+}
+
+// ListSchedules 查询本人排班（含停诊排班，默认今天起14天）
+func (s *DoctorPortalService) ListSchedules(doctorID int64, req *ListDoctorScheduleRequest) ([]model.ScheduleVO, error) {
+	sd := utils.GetTodayStart()
+	if req.StartDate != "" {
+		d, err := utils.ParseDate(req.StartDate)
+		if err != nil {
+			return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "开始日期格式错误")
+		}
+		sd = d
+	}
+
+	ed := sd.AddDate(0, 0, doctorScheduleDefaultDays)
+	if req.EndDate != "" {
+		d, err := utils.ParseDate(req.EndDate)
+		if err != nil {
+			return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "结束日期格式错误")
+		}
+		ed = d
+	}
+	if ed.Before(sd) {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "结束日期不能早于开始日期")
+	}
+
+	schedules, err := s.scheduleRepo.ListByRange(&doctorID, nil, sd, ed)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	voList := make([]model.ScheduleVO, len(schedules))
+	for i, schedule := range schedules {
+		voList[i] = *schedule.ToAdminVO()
+	}
+
+	return voList, nil
+}
+
+// ListAppointments 查询本人的预约患者（默认今天及以后）
+func (s *DoctorPortalService) ListAppointments(doctorID int64, req *ListDoctorAppointmentRequest) ([]model.DoctorAppointmentVO, int64, error) {
+	startDate := utils.GetTodayStart()
+	if req.StartDate != "" {
+		sd, err := utils.ParseDate(req.StartDate)
+		if err != nil {
+			return nil, 0, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "开始日期格式错误")
+		}
+		startDate = sd
+	}
+
+	var endDate *time.Time
+	if req.EndDate != "" {
+		ed, err := utils.ParseDate(req.EndDate)
+		if err != nil {
+			return nil, 0, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "结束日期格式错误")
+		}
+		endDate = &ed
+	}
+
+	appointments, total, err := s.appointmentRepo.ListByDoctor(doctorID, req.Page, req.PageSize, &startDate, endDate, req.ScheduleID, req.Status)
+	if err != nil {
+		return nil, 0, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	voList := make([]model.DoctorAppointmentVO, len(appointments))
+	for i, appointment := range appointments {
+		voList[i] = *appointment.ToDoctorVO()
+	}
+
+	return voList, total, nil
+}
+
+// GetAppointment 获取本人的预约详情
+func (s *DoctorPortalService) GetAppointment(doctorID, appointmentID int64) (*model.DoctorAppointmentVO, error) {
+	appointment, err := s.getAppointment(doctorID, appointmentID)
+	if err != nil {
+		return nil, err
+	}
+	return appointment.ToDoctorVO(), nil
+}
+
+// Checkin 为患者签到（仅限就诊当天）
+func (s *DoctorPortalService) Checkin(doctorID, appointmentID int64) error {
+	appointment, err := s.getAppointment(doctorID, appointmentID)
+	if err != nil {
+		return err
+	}
+	if !isValidStatusTransition(appointment.Status, model.AppointmentStatusCheckedIn) {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "只能签到待就诊的预约")
+	}
+	if utils.FormatDate(appointment.AppointmentDate) != utils.FormatDate(time.Now()) {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "只能为当天就诊的患者签到")
+	}
+
+	return s.updateAppointmentStatus(appointment, model.AppointmentStatusCheckedIn, map[string]interface{}{
+		"checked_in_at": time.Now(),
+	})
+}
+
+// Complete 完成接诊
+func (s *DoctorPortalService) Complete(doctorID, appointmentID int64) error {
+	appointment, err := s.getAppointment(doctorID, appointmentID)
+	if err != nil {
+		return err
+	}
+	if !isValidStatusTransition(appointment.Status, model.AppointmentStatusCompleted) {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "只能完成已签到的预约")
+	}
+
+	return s.updateAppointmentStatus(appointment, model.AppointmentStatusCompleted, map[string]interface{}{
+		"completed_at": time.Now(),
+	})
+}
+
+// MarkMissed 标记患者爽约（就诊时间过后）
+func (s *DoctorPortalService) MarkMissed(doctorID, appointmentID int64) error {
+	appointment, err := s.getAppointment(doctorID, appointmentID)
+	if err != nil {
+		return err
+	}
+	if !isValidStatusTransition(appointment.Status, model.AppointmentStatusMissed) {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "只能将待就诊的预约标记为爽约")
+	}
+
+	appointmentAt, err := time.ParseInLocation("2006-01-02 15:04",
+		appointment.AppointmentDate.Format("2006-01-02")+" "+appointment.AppointmentTime, time.Local)
+	if err != nil || time.Now().Before(appointmentAt) {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "未到就诊时间，不能标记爽约")
+	}
+
+	if err := s.updateAppointmentStatus(appointment, model.AppointmentStatusMissed, nil); err != nil {
+		return err
+	}
+	_ = s.userRepo.IncrementMissedCount(appointment.UserID)
+	return nil
+}
+
+// updateAppointmentStatus 更新预约状态并清除医生排班缓存
+func (s *DoctorPortalService) updateAppointmentStatus(appointment *model.Appointment, status string, extra map[string]interface{}) error {
+	if err := s.appointmentRepo.UpdateStatus(appointment.ID, status, extra); err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	invalidateDoctorScheduleCache(appointment.DoctorID)
+	return nil
+}
+
+// getAccount 查询医生账号
+func (s *DoctorPortalService) getAccount(accountID int64) (*model.DoctorAccount, error) {
+	account, err := s.accountRepo.GetByID(accountID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrDoctorAccountNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return account, nil
+}
+
+// getAppointment 查询本人的预约（非本人预约视为不存在）
+func (s *DoctorPortalService) getAppointment(doctorID, appointmentID int64) (*model.Appointment, error) {
+	appointment, err := s.appointmentRepo.GetByDoctorAndID(doctorID, appointmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrAppointmentNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return appointment, nil
+}
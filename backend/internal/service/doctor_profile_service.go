@@ -0,0 +1,550 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/internal/repository"
+	"huaan-medical/pkg/config"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/logger"
+	"huaan-medical/pkg/utils"
+)
+
+// defaultExpiryAlertDays 默认资质到期提前提醒天数
+const defaultExpiryAlertDays = 30
+
+// DoctorProfileService 医生结构化资料服务（执业证书、教育经历、证书、学术任职、相册、擅长领域标签）
+type DoctorProfileService struct {
+	repo       *repository.DoctorProfileRepository
+	tagRepo    *repository.SpecialtyTagRepository
+	doctorRepo *repository.DoctorRepository
+
+	doctorService *DoctorService
+	searchService *SearchService
+}
+
+// NewDoctorProfileService 创建医生资料服务实例
+func NewDoctorProfileService() *DoctorProfileService {
+	return &DoctorProfileService{
+		repo:       repository.NewDoctorProfileRepository(),
+		tagRepo:    repository.NewSpecialtyTagRepository(),
+		doctorRepo: repository.NewDoctorRepository(),
+
+		doctorService: NewDoctorService(),
+		searchService: NewSearchService(),
+	}
+}
+
+// DoctorEducationItem 教育经历
+type DoctorEducationItem struct {
+	School    string `json:"school" binding:"required,max=128"`
+	Major     string `json:"major" binding:"max=64"`
+	Degree    string `json:"degree" binding:"max=32"` // 学历/学位，如 本科、硕士、博士
+	StartYear int    `json:"start_year" binding:"omitempty,min=1900,max=2100"`
+	EndYear   int    `json:"end_year" binding:"omitempty,min=1900,max=2100"` // 0表示至今
+}
+
+// DoctorCertificationItem 资格/专项证书
+type DoctorCertificationItem struct {
+	Name      string `json:"name" binding:"required,max=128"`
+	Issuer    string `json:"issuer" binding:"max=128"`
+	CertNo    string `json:"cert_no" binding:"max=64"`
+	IssuedAt  string `json:"issued_at"`  // YYYY-MM-DD
+	ExpiresAt string `json:"expires_at"` // YYYY-MM-DD，为空表示长期有效
+}
+
+// DoctorMembershipItem 学术任职
+type DoctorMembershipItem struct {
+	Organization string `json:"organization" binding:"required,max=128"`
+	Position     string `json:"position" binding:"max=64"`
+	StartYear    int    `json:"start_year" binding:"omitempty,min=1900,max=2100"`
+	EndYear      int    `json:"end_year" binding:"omitempty,min=1900,max=2100"` // 0表示至今
+}
+
+// DoctorPhotoItem 相册照片
+type DoctorPhotoItem struct {
+	URL     string `json:"url" binding:"required,max=512"`
+	Caption string `json:"caption" binding:"max=128"`
+}
+
+// UpdateDoctorProfileRequest 更新医生结构化资料请求（整体替换，列表按传入顺序排序）
+type UpdateDoctorProfileRequest struct {
+	LicenseNo        string                    `json:"license_no" binding:"max=32"`
+	LicenseExpiresAt string                    `json:"license_expires_at"` // YYYY-MM-DD，为空表示未登记到期日
+	Educations       []DoctorEducationItem     `json:"educations" binding:"omitempty,max=20,dive"`
+	Certifications   []DoctorCertificationItem `json:"certifications" binding:"omitempty,max=50,dive"`
+	Memberships      []DoctorMembershipItem    `json:"memberships" binding:"omitempty,max=50,dive"`
+	Gallery          []DoctorPhotoItem         `json:"gallery" binding:"omitempty,max=30,dive"`
+	TagIDs           []int64                   `json:"tag_ids" binding:"omitempty,max=20,dive,min=1"`
+}
+
+// ListExpiringQualificationRequest 即将到期资质查询请求
+type ListExpiringQualificationRequest struct {
+	Days *int `form:"days" binding:"omitempty,min=0,max=365"` // 查询未来N天内到期（含已过期），默认取提醒配置
+}
+
+// CreateSpecialtyTagRequest 创建擅长领域标签请求
+type CreateSpecialtyTagRequest struct {
+	Name      string `json:"name" binding:"required,max=32"`
+	SortOrder int    `json:"sort_order"`
+	Status    *int   `json:"status" binding:"omitempty,oneof=0 1"`
+}
+
+// UpdateSpecialtyTagRequest 更新擅长领域标签请求
+type UpdateSpecialtyTagRequest = CreateSpecialtyTagRequest
+
+// ListSpecialtyTagRequest 擅长领域标签列表请求
+type ListSpecialtyTagRequest struct {
+	Page     int    `form:"page" binding:"required,min=1"`
+	PageSize int    `form:"page_size" binding:"required,min=1,max=100"`
+	Keyword  string `form:"keyword"`
+	Status   *int   `form:"status"`
+}
+
+// UpdateProfile 整体更新医生结构化资料
+// 证书到期日未变化时保留已发送的到期提醒记录，避免重复提醒
+func (s *DoctorProfileService) UpdateProfile(doctorID int64, req *UpdateDoctorProfileRequest) (*model.DoctorVO, error) {
+	doctor, err := s.doctorRepo.GetByIDSimple(doctorID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrDoctorNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	current, err := s.repo.GetByDoctor(doctorID)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	licenseExpiresAt, err := parseOptionalDate(req.LicenseExpiresAt, "执业证书到期日期")
+	if err != nil {
+		return nil, err
+	}
+	license := map[string]interface{}{
+		"license_no":         strings.TrimSpace(req.LicenseNo),
+		"license_expires_at": licenseExpiresAt,
+	}
+	if !sameDate(doctor.LicenseExpiresAt, licenseExpiresAt) {
+		license["license_alerted_at"] = nil
+	}
+
+	profile, err := buildDoctorProfile(doctorID, req, current)
+	if err != nil {
+		return nil, err
+	}
+
+	tagIDs := uniqueInt64s(req.TagIDs)
+	if len(tagIDs) > 0 {
+		tags, err := s.tagRepo.FindByIDs(tagIDs)
+		if err != nil {
+			return nil, errorcode.New(errorcode.ErrDatabase)
+		}
+		if len(tags) != len(tagIDs) {
+			return nil, errorcode.New(errorcode.ErrSpecialtyTagNotFound)
+		}
+	}
+
+	if err := s.repo.Replace(doctorID, license, profile, tagIDs); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	s.searchService.IndexDoctor(doctorID)
+
+	return s.doctorService.GetByID(doctorID)
+}
+
+// ListPublicTags 查询启用的擅长领域标签（公开接口，含出诊医生数）
+func (s *DoctorProfileService) ListPublicTags() ([]model.SpecialtyTagVO, error) {
+	tags, err := s.tagRepo.ListEnabled()
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return s.tagVOs(tags)
+}
+
+// ListTags 分页查询擅长领域标签（管理后台）
+func (s *DoctorProfileService) ListTags(req *ListSpecialtyTagRequest) ([]model.SpecialtyTagVO, int64, error) {
+	tags, total, err := s.tagRepo.List(req.Page, req.PageSize, strings.TrimSpace(req.Keyword), req.Status)
+	if err != nil {
+		return nil, 0, errorcode.New(errorcode.ErrDatabase)
+	}
+	list, err := s.tagVOs(tags)
+	if err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+// CreateTag 创建擅长领域标签
+func (s *DoctorProfileService) CreateTag(req *CreateSpecialtyTagRequest) (*model.SpecialtyTagVO, error) {
+	name := strings.TrimSpace(req.Name)
+	if err := s.checkTagName(name, 0); err != nil {
+		return nil, err
+	}
+
+	tag := &model.SpecialtyTag{Name: name, SortOrder: req.SortOrder, Status: model.StatusEnabled}
+	if req.Status != nil {
+		tag.Status = *req.Status
+	}
+	if err := s.tagRepo.Create(tag); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return tag.ToVO(), nil
+}
+
+// UpdateTag 更新擅长领域标签，并同步更新相关医生的搜索索引
+func (s *DoctorProfileService) UpdateTag(id int64, req *UpdateSpecialtyTagRequest) (*model.SpecialtyTagVO, error) {
+	tag, err := s.getTag(id)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(req.Name)
+	if err := s.checkTagName(name, id); err != nil {
+		return nil, err
+	}
+
+	tag.Name = name
+	tag.SortOrder = req.SortOrder
+	if req.Status != nil {
+		tag.Status = *req.Status
+	}
+	if err := s.tagRepo.Update(tag); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	s.reindexTagDoctors(id)
+	return tag.ToVO(), nil
+}
+
+// DeleteTag 删除擅长领域标签（同时移除医生上的该标签）
+func (s *DoctorProfileService) DeleteTag(id int64) error {
+	if _, err := s.getTag(id); err != nil {
+		return err
+	}
+	doctorIDs, err := s.tagRepo.ListDoctorIDs(id)
+	if err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if err := s.tagRepo.Delete(id); err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	for _, doctorID := range doctorIDs {
+		s.searchService.IndexDoctor(doctorID)
+	}
+	return nil
+}
+
+// ListExpiring 查询未来N天内到期及已过期的执业证书/资格证书（按到期日升序）
+func (s *DoctorProfileService) ListExpiring(req *ListExpiringQualificationRequest) ([]model.ExpiringQualificationVO, error) {
+	days := expiryAlertDays()
+	if req.Days != nil {
+		days = *req.Days
+	}
+	list, err := s.collectExpiring(utils.GetTodayStart(), days)
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// AlertExpiring 资质到期提醒：到期前N天（含已过期）仍未提醒的证书记录告警日志并标记已提醒
+// 返回本次提醒的条数
+func (s *DoctorProfileService) AlertExpiring(now time.Time) (int, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	list, err := s.collectExpiring(today, expiryAlertDays())
+	if err != nil {
+		return 0, err
+	}
+
+	var doctorIDs, certIDs []int64
+	for _, item := range list {
+		if item.Alerted {
+			continue
+		}
+		logger.Warn("医生资质即将到期",
+			zap.String("type", item.Type),
+			zap.Int64("doctor_id", item.DoctorID),
+			zap.String("doctor_name", item.DoctorName),
+			zap.String("name", item.Name),
+			zap.String("expires_at", item.ExpiresAt),
+			zap.Int("days_left", item.DaysLeft))
+		if item.Type == model.QualificationTypeLicense {
+			doctorIDs = append(doctorIDs, item.RefID)
+		} else {
+			certIDs = append(certIDs, item.RefID)
+		}
+	}
+
+	if err := s.repo.MarkLicensesAlerted(doctorIDs, now); err != nil {
+		return 0, errorcode.New(errorcode.ErrDatabase)
+	}
+	if err := s.repo.MarkCertificationsAlerted(certIDs, now); err != nil {
+		return 0, errorcode.New(errorcode.ErrDatabase)
+	}
+	return len(doctorIDs) + len(certIDs), nil
+}
+
+// collectExpiring 汇总到期日早于 today+days 的执业证书与资格证书
+func (s *DoctorProfileService) collectExpiring(today time.Time, days int) ([]model.ExpiringQualificationVO, error) {
+	before := today.AddDate(0, 0, days+1)
+
+	doctors, err := s.repo.ListExpiringLicenses(before)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	certs, err := s.repo.ListExpiringCertifications(before)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	list := make([]model.ExpiringQualificationVO, 0, len(doctors)+len(certs))
+	for i := range doctors {
+		d := &doctors[i]
+		item := model.ExpiringQualificationVO{
+			Type:       model.QualificationTypeLicense,
+			TypeName:   "医师执业证书",
+			RefID:      d.ID,
+			DoctorID:   d.ID,
+			DoctorName: d.Name,
+			Name:       "医师执业证书",
+			Number:     d.LicenseNo,
+			ExpiresAt:  d.LicenseExpiresAt.Format("2006-01-02"),
+			DaysLeft:   daysBetween(today, *d.LicenseExpiresAt),
+			Alerted:    d.LicenseAlertedAt != nil,
+		}
+		if d.Department != nil {
+			item.DepartmentName = d.Department.Name
+		}
+		list = append(list, item)
+	}
+
+	if len(certs) > 0 {
+		ids := make([]int64, 0, len(certs))
+		for _, c := range certs {
+			ids = append(ids, c.DoctorID)
+		}
+		owners, err := s.doctorRepo.ListByIDs(uniqueInt64s(ids))
+		if err != nil {
+			return nil, errorcode.New(errorcode.ErrDatabase)
+		}
+		ownerMap := make(map[int64]*model.Doctor, len(owners))
+		for i := range owners {
+			ownerMap[owners[i].ID] = &owners[i]
+		}
+		for _, c := range certs {
+			item := model.ExpiringQualificationVO{
+				Type:      model.QualificationTypeCertification,
+				TypeName:  "资格/专项证书",
+				RefID:     c.ID,
+				DoctorID:  c.DoctorID,
+				Name:      c.Name,
+				Number:    c.CertNo,
+				ExpiresAt: c.ExpiresAt.Format("2006-01-02"),
+				DaysLeft:  daysBetween(today, *c.ExpiresAt),
+				Alerted:   c.AlertedAt != nil,
+			}
+			if d, ok := ownerMap[c.DoctorID]; ok {
+				item.DoctorName = d.Name
+				if d.Department != nil {
+					item.DepartmentName = d.Department.Name
+				}
+			}
+			list = append(list, item)
+		}
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].DaysLeft < list[j].DaysLeft
+	})
+	return list, nil
+}
+
+// tagVOs 转换标签视图列表并填充出诊医生数
+func (s *DoctorProfileService) tagVOs(tags []model.SpecialtyTag) ([]model.SpecialtyTagVO, error) {
+	ids := make([]int64, len(tags))
+	for i := range tags {
+		ids[i] = tags[i].ID
+	}
+	counts, err := s.tagRepo.CountDoctors(ids)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	list := make([]model.SpecialtyTagVO, len(tags))
+	for i := range tags {
+		list[i] = *tags[i].ToVO()
+		list[i].DoctorCount = counts[tags[i].ID]
+	}
+	return list, nil
+}
+
+// getTag 查询标签
+func (s *DoctorProfileService) getTag(id int64) (*model.SpecialtyTag, error) {
+	tag, err := s.tagRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrSpecialtyTagNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return tag, nil
+}
+
+// checkTagName 校验标签名称非空且不重复
+func (s *DoctorProfileService) checkTagName(name string, excludeID int64) error {
+	if name == "" {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "标签名称不能为空")
+	}
+	existing, err := s.tagRepo.GetByName(name)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if err == nil && existing.ID != excludeID {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "标签名称已存在")
+	}
+	return nil
+}
+
+// reindexTagDoctors 更新带有指定标签的医生搜索索引
+func (s *DoctorProfileService) reindexTagDoctors(tagID int64) {
+	doctorIDs, err := s.tagRepo.ListDoctorIDs(tagID)
+	if err != nil {
+		logger.Warn("查询标签关联医生失败", zap.Error(err), zap.Int64("tag_id", tagID))
+		return
+	}
+	for _, doctorID := range doctorIDs {
+		s.searchService.IndexDoctor(doctorID)
+	}
+}
+
+// buildDoctorProfile 根据请求构建结构化资料（按传入顺序设置排序序号）
+func buildDoctorProfile(doctorID int64, req *UpdateDoctorProfileRequest, current *model.DoctorProfile) (*model.DoctorProfile, error) {
+	profile := &model.DoctorProfile{}
+
+	for i, item := range req.Educations {
+		if item.StartYear > 0 && item.EndYear > 0 && item.EndYear < item.StartYear {
+			return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, fmt.Sprintf("教育经历「%s」的毕业年份早于入学年份", item.School))
+		}
+		profile.Educations = append(profile.Educations, model.DoctorEducation{
+			DoctorID:  doctorID,
+			School:    strings.TrimSpace(item.School),
+			Major:     strings.TrimSpace(item.Major),
+			Degree:    strings.TrimSpace(item.Degree),
+			StartYear: item.StartYear,
+			EndYear:   item.EndYear,
+			SortOrder: i,
+		})
+	}
+
+	for i, item := range req.Certifications {
+		issuedAt, err := parseOptionalDate(item.IssuedAt, "发证日期")
+		if err != nil {
+			return nil, err
+		}
+		expiresAt, err := parseOptionalDate(item.ExpiresAt, "到期日期")
+		if err != nil {
+			return nil, err
+		}
+		if issuedAt != nil && expiresAt != nil && expiresAt.Before(*issuedAt) {
+			return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, fmt.Sprintf("证书「%s」的到期日期早于发证日期", item.Name))
+		}
+		cert := model.DoctorCertification{
+			DoctorID:  doctorID,
+			Name:      strings.TrimSpace(item.Name),
+			Issuer:    strings.TrimSpace(item.Issuer),
+			CertNo:    strings.TrimSpace(item.CertNo),
+			IssuedAt:  issuedAt,
+			ExpiresAt: expiresAt,
+			SortOrder: i,
+		}
+		// 同一证书到期日未变化时沿用提醒记录
+		for _, old := range current.Certifications {
+			if old.Name == cert.Name && old.CertNo == cert.CertNo && sameDate(old.ExpiresAt, cert.ExpiresAt) {
+				cert.AlertedAt = old.AlertedAt
+				break
+			}
+		}
+		profile.Certifications = append(profile.Certifications, cert)
+	}
+
+	for i, item := range req.Memberships {
+		if item.StartYear > 0 && item.EndYear > 0 && item.EndYear < item.StartYear {
+			return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, fmt.Sprintf("学术任职「%s」的结束年份早于起始年份", item.Organization))
+		}
+		profile.Memberships = append(profile.Memberships, model.DoctorMembership{
+			DoctorID:     doctorID,
+			Organization: strings.TrimSpace(item.Organization),
+			Position:     strings.TrimSpace(item.Position),
+			StartYear:    item.StartYear,
+			EndYear:      item.EndYear,
+			SortOrder:    i,
+		})
+	}
+
+	for i, item := range req.Gallery {
+		profile.Photos = append(profile.Photos, model.DoctorPhoto{
+			DoctorID:  doctorID,
+			URL:       strings.TrimSpace(item.URL),
+			Caption:   strings.TrimSpace(item.Caption),
+			SortOrder: i,
+		})
+	}
+
+	return profile, nil
+}
+
+// parseOptionalDate 解析可选日期（YYYY-MM-DD），为空返回 nil
+func parseOptionalDate(value, field string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := utils.ParseDate(value)
+	if err != nil {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, field+"格式错误，应为YYYY-MM-DD")
+	}
+	return &t, nil
+}
+
+// sameDate 比较两个可选日期是否为同一天
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
+}
+
+// daysBetween 计算 from 到 to 的自然日天数（to 早于 from 时为负数）
+func daysBetween(from, to time.Time) int {
+	a := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	b := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
+
+// uniqueInt64s 去重并保持顺序
+func uniqueInt64s(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	result := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		result = append(result, id)
+	}
+	return result
+}
+
+// expiryAlertDays 资质到期提前提醒天数
+func expiryAlertDays() int {
+	if cfg := config.Get(); cfg != nil && cfg.Business.Doctor.ExpiryAlertDays > 0 {
+		return cfg.Business.Doctor.ExpiryAlertDays
+	}
+	return defaultExpiryAlertDays
+}
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/internal/repository"
+	"huaan-medical/pkg/config"
+	"huaan-medical/pkg/errorcode"
+)
+
+// DoctorReviewService 就诊评价服务
+// 患者在预约完成后的评价期限内可对本次就诊评价一次；管理员可屏蔽不当评价，医生可回复
+type DoctorReviewService struct {
+	repo            *repository.DoctorReviewRepository
+	appointmentRepo *repository.AppointmentRepository
+	doctorRepo      *repository.DoctorRepository
+}
+
+// NewDoctorReviewService 创建就诊评价服务实例
+func NewDoctorReviewService() *DoctorReviewService {
+	return &DoctorReviewService{
+		repo:            repository.NewDoctorReviewRepository(),
+		appointmentRepo: repository.NewAppointmentRepository(),
+		doctorRepo:      repository.NewDoctorRepository(),
+	}
+}
+
+// defaultReviewWindowDays 默认评价期限（天）
+const defaultReviewWindowDays = 30
+
+// CreateDoctorReviewRequest 提交评价请求
+type CreateDoctorReviewRequest struct {
+	Rating  int      `json:"rating" binding:"required,min=1,max=5"`
+	Tags    []string `json:"tags" binding:"omitempty,max=5,dive,max=32"`
+	Content string   `json:"content" binding:"max=1000"`
+}
+
+// ReplyDoctorReviewRequest 医生回复评价请求
+type ReplyDoctorReviewRequest struct {
+	Reply string `json:"reply" binding:"required,max=1000"`
+}
+
+// HideDoctorReviewRequest 屏蔽评价请求
+type HideDoctorReviewRequest struct {
+	Reason string `json:"reason" binding:"required,max=256"`
+}
+
+// ListPublicDoctorReviewRequest 医生公开评价列表请求
+type ListPublicDoctorReviewRequest struct {
+	Page     int `form:"page" binding:"required,min=1"`
+	PageSize int `form:"page_size" binding:"required,min=1,max=50"`
+}
+
+// ListDoctorReviewRequest 评价列表请求（管理后台/医生工作台）
+type ListDoctorReviewRequest struct {
+	Page         int    `form:"page" binding:"required,min=1"`
+	PageSize     int    `form:"page_size" binding:"required,min=1,max=100"`
+	DoctorID     *int64 `form:"doctor_id"`
+	DepartmentID *int64 `form:"department_id"`
+	Status       string `form:"status"`
+	MinRating    int    `form:"min_rating" binding:"omitempty,min=1,max=5"`
+	MaxRating    int    `form:"max_rating" binding:"omitempty,min=1,max=5"`
+	Keyword      string `form:"keyword"`
+}
+
+// ListTags 评价标签列表
+func (s *DoctorReviewService) ListTags() []model.DoctorReviewTagVO {
+	list := make([]model.DoctorReviewTagVO, 0, len(model.ReviewTags))
+	for code, name := range model.ReviewTags {
+		list = append(list, model.DoctorReviewTagVO{Code: code, Name: name})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list
+}
+
+// Create 患者评价已完成的预约
+func (s *DoctorReviewService) Create(userID, appointmentID int64, req *CreateDoctorReviewRequest) (*model.DoctorReviewVO, error) {
+	appointment, err := s.appointmentRepo.GetByUserAndID(userID, appointmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrAppointmentNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	// 共享就诊人的预约仅预约人本人可评价
+	if appointment.UserID != userID {
+		return nil, errorcode.NewWithMessage(errorcode.ErrReviewNotAllowed, "仅预约人本人可评价")
+	}
+	if appointment.Status != model.AppointmentStatusCompleted {
+		return nil, errorcode.New(errorcode.ErrReviewNotAllowed)
+	}
+
+	// 评价期限从完成时间起算，历史数据无完成时间时按就诊日期计算
+	completedAt := appointment.AppointmentDate
+	if appointment.CompletedAt != nil {
+		completedAt = *appointment.CompletedAt
+	}
+	windowDays := reviewWindowDays()
+	if time.Now().After(completedAt.AddDate(0, 0, windowDays)) {
+		return nil, errorcode.NewWithMessage(errorcode.ErrReviewExpired, fmt.Sprintf("就诊完成%d天内可评价，已超过评价期限", windowDays))
+	}
+
+	exists, err := s.repo.ExistsByAppointmentID(appointmentID)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	if exists {
+		return nil, errorcode.New(errorcode.ErrReviewExists)
+	}
+
+	tags, err := normalizeReviewTags(req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	review := &model.DoctorReview{
+		AppointmentID: appointment.ID,
+		UserID:        userID,
+		PatientID:     appointment.PatientID,
+		DoctorID:      appointment.DoctorID,
+		DepartmentID:  appointment.DepartmentID,
+		Rating:        req.Rating,
+		Tags:          strings.Join(tags, ","),
+		Content:       strings.TrimSpace(req.Content),
+		Status:        model.DoctorReviewStatusVisible,
+	}
+	if err := s.repo.Create(review); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	return s.GetByID(review.ID)
+}
+
+// GetByAppointment 患者查看本人预约的评价
+func (s *DoctorReviewService) GetByAppointment(userID, appointmentID int64) (*model.DoctorReviewVO, error) {
+	review, err := s.repo.GetByAppointmentID(appointmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrReviewNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	if review.UserID != userID {
+		return nil, errorcode.New(errorcode.ErrReviewNotFound)
+	}
+	return review.ToVO(), nil
+}
+
+// ListPublic 医生公开评价列表（仅未屏蔽的评价，患者姓名脱敏）
+func (s *DoctorReviewService) ListPublic(doctorID int64, req *ListPublicDoctorReviewRequest) ([]model.DoctorReviewVO, int64, error) {
+	if _, err := s.doctorRepo.GetByIDSimple(doctorID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, 0, errorcode.New(errorcode.ErrDoctorNotFound)
+		}
+		return nil, 0, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	reviews, total, err := s.repo.List(req.Page, req.PageSize, &doctorID, nil, nil, model.DoctorReviewStatusVisible, 0, 0, "")
+	if err != nil {
+		return nil, 0, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	list := make([]model.DoctorReviewVO, len(reviews))
+	for i := range reviews {
+		list[i] = *reviews[i].ToPublicVO()
+	}
+	return list, total, nil
+}
+
+// List 分页查询评价（管理后台）
+func (s *DoctorReviewService) List(req *ListDoctorReviewRequest) ([]model.DoctorReviewVO, int64, error) {
+	reviews, total, err := s.repo.List(req.Page, req.PageSize, req.DoctorID, req.DepartmentID, nil,
+		req.Status, req.MinRating, req.MaxRating, strings.TrimSpace(req.Keyword))
+	if err != nil {
+		return nil, 0, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	list := make([]model.DoctorReviewVO, len(reviews))
+	for i := range reviews {
+		list[i] = *reviews[i].ToVO()
+	}
+	return list, total, nil
+}
+
+// ListForDoctor 医生查看本人收到的评价
+func (s *DoctorReviewService) ListForDoctor(doctorID int64, req *ListDoctorReviewRequest) ([]model.DoctorReviewVO, int64, error) {
+	req.DoctorID = &doctorID
+	req.DepartmentID = nil
+	return s.List(req)
+}
+
+// GetByID 获取评价详情
+func (s *DoctorReviewService) GetByID(id int64) (*model.DoctorReviewVO, error) {
+	review, err := s.getReview(id)
+	if err != nil {
+		return nil, err
+	}
+	return review.ToVO(), nil
+}
+
+// Reply 医生回复本人收到的评价（可修改回复）
+func (s *DoctorReviewService) Reply(doctorID, id int64, req *ReplyDoctorReviewRequest) (*model.DoctorReviewVO, error) {
+	review, err := s.getReview(id)
+	if err != nil {
+		return nil, err
+	}
+	if review.DoctorID != doctorID {
+		return nil, errorcode.New(errorcode.ErrReviewNotFound)
+	}
+	if review.Status == model.DoctorReviewStatusHidden {
+		return nil, errorcode.New(errorcode.ErrReviewHidden)
+	}
+
+	reply := strings.TrimSpace(req.Reply)
+	if reply == "" {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "回复内容不能为空")
+	}
+	if err := s.repo.UpdateReply(id, map[string]interface{}{
+		"reply":      reply,
+		"replied_at": time.Now(),
+	}); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	return s.GetByID(id)
+}
+
+// Hide 管理员屏蔽评价（不计入评分）
+func (s *DoctorReviewService) Hide(id, adminID int64, req *HideDoctorReviewRequest) error {
+	if _, err := s.getReview(id); err != nil {
+		return err
+	}
+
+	ok, err := s.repo.UpdateStatus(id, model.DoctorReviewStatusVisible, model.DoctorReviewStatusHidden, map[string]interface{}{
+		"hidden_reason": strings.TrimSpace(req.Reason),
+		"hidden_by":     adminID,
+		"hidden_at":     time.Now(),
+	})
+	if err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if !ok {
+		return errorcode.New(errorcode.ErrReviewHidden)
+	}
+	return nil
+}
+
+// Show 管理员恢复展示已屏蔽的评价
+func (s *DoctorReviewService) Show(id int64) error {
+	if _, err := s.getReview(id); err != nil {
+		return err
+	}
+
+	ok, err := s.repo.UpdateStatus(id, model.DoctorReviewStatusHidden, model.DoctorReviewStatusVisible, map[string]interface{}{
+		"hidden_reason": "",
+		"hidden_by":     0,
+		"hidden_at":     nil,
+	})
+	if err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if !ok {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该评价未被屏蔽")
+	}
+	return nil
+}
+
+// getReview 查询评价
+func (s *DoctorReviewService) getReview(id int64) (*model.DoctorReview, error) {
+	review, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrReviewNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return review, nil
+}
+
+// normalizeReviewTags 校验并去重评价标签
+func normalizeReviewTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if _, ok := model.ReviewTags[tag]; !ok {
+			return nil, errorcode.NewWithMessage(errorcode.ErrReviewTagInvalid, fmt.Sprintf("评价标签「%s」无效", tag))
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	return result, nil
+}
+
+// reviewWindowDays 评价期限（天）
+func reviewWindowDays() int {
+	if cfg := config.Get(); cfg != nil && cfg.Business.Review.WindowDays > 0 {
+		return cfg.Business.Review.WindowDays
+	}
+	return defaultReviewWindowDays
+}
@@ -2,50 +2,65 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
 	"gorm.io/gorm"
 
 	"huaan-medical/internal/model"
 	"huaan-medical/internal/repository"
 	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/utils"
 )
 
 // DoctorService 医生服务
 type DoctorService struct {
-	repo     *repository.DoctorRepository
-	deptRepo *repository.DepartmentRepository
+	repo        *repository.DoctorRepository
+	deptRepo    *repository.DepartmentRepository
+	reviewRepo  *repository.DoctorReviewRepository
+	patientRepo *repository.PatientRepository
+	profileRepo *repository.DoctorProfileRepository
+
+	searchService *SearchService
 }
 
 // NewDoctorService 创建医生服务实例
 func NewDoctorService() *DoctorService {
 	return &DoctorService{
-		repo:     repository.NewDoctorRepository(),
-		deptRepo: repository.NewDepartmentRepository(),
+		repo:        repository.NewDoctorRepository(),
+		deptRepo:    repository.NewDepartmentRepository(),
+		reviewRepo:  repository.NewDoctorReviewRepository(),
+		patientRepo: repository.NewPatientRepository(),
+		profileRepo: repository.NewDoctorProfileRepository(),
+
+		searchService: NewSearchService(),
 	}
 }
 
 // CreateDoctorRequest 创建医生请求
 type CreateDoctorRequest struct {
-	DepartmentID int64  `json:"department_id" binding:"required,min=1"`
-	Name         string `json:"name" binding:"required,min=2,max=32"`
-	Avatar       string `json:"avatar" binding:"max=512"`
-	Title        string `json:"title" binding:"required,oneof=chief_physician associate_chief_physician attending_physician resident_physician"`
-	Specialty    string `json:"specialty" binding:"max=256"`
-	Introduction string `json:"introduction" binding:"max=2000"`
-	SortOrder    int    `json:"sort_order"`
-	Status       int    `json:"status" binding:"oneof=0 1"`
+	DepartmentID int64   `json:"department_id" binding:"required,min=1"`              // 主科室
+	OtherDeptIDs []int64 `json:"other_department_ids" binding:"omitempty,dive,min=1"` // 其他执业科室
+	Name         string  `json:"name" binding:"required,min=2,max=32"`
+	Avatar       string  `json:"avatar" binding:"max=512"`
+	Title        string  `json:"title" binding:"required,oneof=chief_physician associate_chief_physician attending_physician resident_physician"`
+	Specialty    string  `json:"specialty" binding:"max=256"`
+	Introduction string  `json:"introduction" binding:"max=2000"`
+	SortOrder    int     `json:"sort_order"`
+	Status       int     `json:"status" binding:"oneof=0 1"`
 }
 
 // UpdateDoctorRequest 更新医生请求
 type UpdateDoctorRequest struct {
-	DepartmentID int64  `json:"department_id" binding:"required,min=1"`
-	Name         string `json:"name" binding:"required,min=2,max=32"`
-	Avatar       string `json:"avatar" binding:"max=512"`
-	Title        string `json:"title" binding:"required,oneof=chief_physician associate_chief_physician attending_physician resident_physician"`
-	Specialty    string `json:"specialty" binding:"max=256"`
-	Introduction string `json:"introduction" binding:"max=2000"`
-	SortOrder    int    `json:"sort_order"`
-	Status       int    `json:"status" binding:"oneof=0 1"`
+	DepartmentID int64   `json:"department_id" binding:"required,min=1"`              // 主科室
+	OtherDeptIDs []int64 `json:"other_department_ids" binding:"omitempty,dive,min=1"` // 其他执业科室，不传则保持不变，传空数组则清空
+	Name         string  `json:"name" binding:"required,min=2,max=32"`
+	Avatar       string  `json:"avatar" binding:"max=512"`
+	Title        string  `json:"title" binding:"required,oneof=chief_physician associate_chief_physician attending_physician resident_physician"`
+	Specialty    string  `json:"specialty" binding:"max=256"`
+	Introduction string  `json:"introduction" binding:"max=2000"`
+	SortOrder    int     `json:"sort_order"`
+	Status       int     `json:"status" binding:"oneof=0 1"`
 }
 
 // ListDoctorRequest 列表查询请求
@@ -53,14 +68,18 @@ type ListDoctorRequest struct {
 	Page         int    `form:"page" binding:"required,min=1"`
 	PageSize     int    `form:"page_size" binding:"required,min=1,max=100"`
 	DepartmentID *int64 `form:"department_id"`
+	TagID        *int64 `form:"tag_id"` // 擅长领域标签
 	Status       *int   `form:"status"`
 	Keyword      string `form:"keyword"`
 }
 
 // ListPublicDoctorRequest 公开接口列表查询请求
 type ListPublicDoctorRequest struct {
+	CampusID     *int64 `form:"campus_id"` // 仅返回在该院区执业的医生
 	DepartmentID *int64 `form:"department_id"`
+	TagID        *int64 `form:"tag_id"` // 擅长领域标签
 	Keyword      string `form:"keyword"`
+	PatientID    *int64 `form:"patient_id"` // 仅返回该就诊人符合科室接诊限制的医生（需登录）
 }
 
 // Create 创建医生
@@ -88,6 +107,11 @@ func (s *DoctorService) Create(req *CreateDoctorRequest) (*model.DoctorVO, error
 		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该科室下已存在同名医生")
 	}
 
+	// 检查其他执业科室
+	if err := s.validateOtherDepartments(req.OtherDeptIDs, nil); err != nil {
+		return nil, err
+	}
+
 	// 创建医生
 	doctor := &model.Doctor{
 		DepartmentID: req.DepartmentID,
@@ -100,9 +124,10 @@ func (s *DoctorService) Create(req *CreateDoctorRequest) (*model.DoctorVO, error
 		Status:       req.Status,
 	}
 
-	if err := s.repo.Create(doctor); err != nil {
+	if err := s.repo.CreateWithDepartments(doctor, req.OtherDeptIDs); err != nil {
 		return nil, errorcode.New(errorcode.ErrDatabase)
 	}
+	s.searchService.IndexDoctor(doctor.ID)
 
 	// 重新查询以获取关联数据
 	doctor, err = s.repo.GetByID(doctor.ID)
@@ -147,6 +172,32 @@ func (s *DoctorService) Update(id int64, req *UpdateDoctorRequest) (*model.Docto
 		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该科室下已存在同名医生")
 	}
 
+	// 执业科室调整
+	current, err := s.repo.ListDepartments(id)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	existing := make(map[int64]bool, len(current))
+	for _, a := range current {
+		existing[a.DepartmentID] = true
+	}
+	otherDeptIDs := req.OtherDeptIDs
+	if otherDeptIDs == nil {
+		// 未传时保留原有的非主科室执业关系
+		otherDeptIDs = []int64{}
+		for _, a := range current {
+			if !a.IsPrimary && a.DepartmentID != req.DepartmentID {
+				otherDeptIDs = append(otherDeptIDs, a.DepartmentID)
+			}
+		}
+	}
+	if err := s.validateOtherDepartments(otherDeptIDs, existing); err != nil {
+		return nil, err
+	}
+	if err := s.checkRemovedDepartments(id, current, req.DepartmentID, otherDeptIDs); err != nil {
+		return nil, err
+	}
+
 	// 更新医生信息
 	doctor.DepartmentID = req.DepartmentID
 	doctor.Name = req.Name
@@ -157,9 +208,10 @@ func (s *DoctorService) Update(id int64, req *UpdateDoctorRequest) (*model.Docto
 	doctor.SortOrder = req.SortOrder
 	doctor.Status = req.Status
 
-	if err := s.repo.Update(doctor); err != nil {
+	if err := s.repo.UpdateWithDepartments(doctor, otherDeptIDs); err != nil {
 		return nil, errorcode.New(errorcode.ErrDatabase)
 	}
+	s.searchService.IndexDoctor(id)
 
 	// 重新查询以获取关联数据
 	doctor, err = s.repo.GetByID(id)
@@ -199,11 +251,40 @@ func (s *DoctorService) Delete(id int64) error {
 		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该医生存在排班记录，请先删除排班")
 	}
 
-	return s.repo.Delete(id)
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	if err := s.profileRepo.DeleteByDoctor(id); err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	s.searchService.RemoveDoctor(id)
+	return nil
 }
 
-// GetByID 获取医生详情
+// GetByID 获取医生详情（含完整结构化资料）
 func (s *DoctorService) GetByID(id int64) (*model.DoctorVO, error) {
+	return s.getWithProfile(id, false)
+}
+
+// GetByIDPublic 获取医生详情（公开接口，含评分汇总，不返回已过期证书）
+func (s *DoctorService) GetByIDPublic(id int64) (*model.DoctorVO, error) {
+	vo, err := s.getWithProfile(id, true)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries, err := s.reviewRepo.SummaryByDoctors([]int64{id})
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	vo.Rating = summaries[id].Rating
+	vo.ReviewCount = summaries[id].ReviewCount
+
+	return vo, nil
+}
+
+// getWithProfile 查询医生详情并附带结构化资料
+func (s *DoctorService) getWithProfile(id int64, publicOnly bool) (*model.DoctorVO, error) {
 	doctor, err := s.repo.GetByID(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -211,12 +292,19 @@ func (s *DoctorService) GetByID(id int64) (*model.DoctorVO, error) {
 		}
 		return nil, errorcode.New(errorcode.ErrDatabase)
 	}
-	return doctor.ToVO(), nil
+	profile, err := s.profileRepo.GetByDoctor(id)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	vo := doctor.ToVO()
+	vo.Profile = profile.ToVO(doctor, utils.GetTodayStart(), publicOnly)
+	return vo, nil
 }
 
 // List 分页查询医生列表（管理后台）
 func (s *DoctorService) List(req *ListDoctorRequest) ([]model.DoctorListVO, int64, error) {
-	doctors, total, err := s.repo.List(req.Page, req.PageSize, req.DepartmentID, req.Status, req.Keyword)
+	doctors, total, err := s.repo.List(req.Page, req.PageSize, req.DepartmentID, req.TagID, req.Status, req.Keyword)
 	if err != nil {
 		return nil, 0, errorcode.New(errorcode.ErrDatabase)
 	}
@@ -230,8 +318,38 @@ func (s *DoctorService) List(req *ListDoctorRequest) ([]model.DoctorListVO, int6
 }
 
 // ListPublic 查询医生列表（公开接口）
-func (s *DoctorService) ListPublic(req *ListPublicDoctorRequest) ([]model.DoctorListVO, error) {
-	doctors, err := s.repo.ListPublic(req.DepartmentID, req.Keyword)
+// 带关键词时通过搜索索引匹配（支持拼音、首字母、擅长领域及同义词），结果按相关度排序；
+// 指定就诊人时仅返回该就诊人符合科室接诊限制的医生
+func (s *DoctorService) ListPublic(userID int64, req *ListPublicDoctorRequest) ([]model.DoctorListVO, error) {
+	var doctors []model.Doctor
+	var err error
+	if keyword := strings.TrimSpace(req.Keyword); keyword != "" {
+		doctors, err = s.searchPublic(keyword, req.DepartmentID)
+	} else {
+		if doctors, err = s.repo.ListPublic(req.DepartmentID, ""); err != nil {
+			err = errorcode.New(errorcode.ErrDatabase)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if req.CampusID != nil && *req.CampusID > 0 {
+		doctors = filterDoctorsByCampus(doctors, *req.CampusID)
+	}
+	if req.TagID != nil && *req.TagID > 0 {
+		doctors = filterDoctorsByTag(doctors, *req.TagID)
+	}
+	if req.PatientID != nil && *req.PatientID > 0 {
+		if doctors, err = s.filterEligible(doctors, userID, *req.PatientID, req.DepartmentID); err != nil {
+			return nil, err
+		}
+	}
+
+	ids := make([]int64, len(doctors))
+	for i, doctor := range doctors {
+		ids[i] = doctor.ID
+	}
+	summaries, err := s.reviewRepo.SummaryByDoctors(ids)
 	if err != nil {
 		return nil, errorcode.New(errorcode.ErrDatabase)
 	}
@@ -239,11 +357,93 @@ func (s *DoctorService) ListPublic(req *ListPublicDoctorRequest) ([]model.Doctor
 	voList := make([]model.DoctorListVO, len(doctors))
 	for i, doctor := range doctors {
 		voList[i] = *doctor.ToListVO()
+		voList[i].Rating = summaries[doctor.ID].Rating
+		voList[i].ReviewCount = summaries[doctor.ID].ReviewCount
 	}
 
 	return voList, nil
 }
 
+// filterDoctorsByCampus 按执业院区过滤医生
+func filterDoctorsByCampus(doctors []model.Doctor, campusID int64) []model.Doctor {
+	result := make([]model.Doctor, 0, len(doctors))
+	for i := range doctors {
+		if doctors[i].PracticesAtCampus(campusID) {
+			result = append(result, doctors[i])
+		}
+	}
+	return result
+}
+
+// filterDoctorsByTag 按擅长领域标签过滤医生
+func filterDoctorsByTag(doctors []model.Doctor, tagID int64) []model.Doctor {
+	result := make([]model.Doctor, 0, len(doctors))
+	for i := range doctors {
+		if doctors[i].HasTag(tagID) {
+			result = append(result, doctors[i])
+		}
+	}
+	return result
+}
+
+// filterEligible 按就诊人年龄/性别过滤医生
+// 指定科室时检查该科室的接诊限制，否则医生任一执业科室符合即可
+func (s *DoctorService) filterEligible(doctors []model.Doctor, userID, patientID int64, departmentID *int64) ([]model.Doctor, error) {
+	patient, err := loadEligibilityPatient(s.patientRepo, userID, patientID)
+	if err != nil {
+		return nil, err
+	}
+	eligibility, err := newDepartmentEligibility(s.deptRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	age := patient.KnownAge()
+	if departmentID != nil && *departmentID > 0 {
+		if !eligibility.eligible(*departmentID, age, patient.Gender) {
+			return []model.Doctor{}, nil
+		}
+		return doctors, nil
+	}
+
+	result := make([]model.Doctor, 0, len(doctors))
+	for i := range doctors {
+		if eligibility.eligibleForDoctor(&doctors[i], age, patient.Gender) {
+			result = append(result, doctors[i])
+		}
+	}
+	return result, nil
+}
+
+// searchPublic 按关键词检索正常出诊的医生（按相关度排序）
+func (s *DoctorService) searchPublic(keyword string, departmentID *int64) ([]model.Doctor, error) {
+	ids, err := s.searchService.MatchDoctorIDs(keyword)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.repo.ListByIDs(ids)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	doctorMap := make(map[int64]model.Doctor, len(list))
+	for _, doctor := range list {
+		doctorMap[doctor.ID] = doctor
+	}
+	doctors := make([]model.Doctor, 0, len(ids))
+	for _, id := range ids {
+		doctor, ok := doctorMap[id]
+		if !ok || doctor.Status != model.StatusEnabled {
+			continue
+		}
+		if departmentID != nil && *departmentID > 0 && !doctor.HasDepartment(*departmentID) {
+			continue
+		}
+		doctors = append(doctors, doctor)
+	}
+	return doctors, nil
+}
+
 // UpdateStatus 批量更新医生状态
 func (s *DoctorService) UpdateStatus(ids []int64, status int) error {
 	if len(ids) == 0 {
@@ -254,5 +454,54 @@ func (s *DoctorService) UpdateStatus(ids []int64, status int) error {
 		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "状态值无效")
 	}
 
-	return s.repo.UpdateStatus(ids, status)
+	if err := s.repo.UpdateStatus(ids, status); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		s.searchService.IndexDoctor(id)
+	}
+	return nil
+}
+
+// validateOtherDepartments 校验其他执业科室存在且启用（已有的执业科室允许保留停用科室）
+func (s *DoctorService) validateOtherDepartments(deptIDs []int64, existing map[int64]bool) error {
+	for _, deptID := range deptIDs {
+		if existing[deptID] {
+			continue
+		}
+		dept, err := s.deptRepo.GetByID(deptID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errorcode.New(errorcode.ErrDepartmentNotFound)
+			}
+			return errorcode.New(errorcode.ErrDatabase)
+		}
+		if dept.Status == model.StatusDisabled {
+			return errorcode.NewWithMessage(errorcode.ErrInvalidParams, fmt.Sprintf("执业科室「%s」已停用", dept.Name))
+		}
+	}
+	return nil
+}
+
+// checkRemovedDepartments 移除执业科室前检查该科室下是否仍有未来排班
+func (s *DoctorService) checkRemovedDepartments(doctorID int64, current []model.DoctorDepartment, primaryID int64, otherDeptIDs []int64) error {
+	keep := map[int64]bool{primaryID: true}
+	for _, deptID := range otherDeptIDs {
+		keep[deptID] = true
+	}
+
+	today := utils.GetTodayStart()
+	for _, a := range current {
+		if keep[a.DepartmentID] {
+			continue
+		}
+		has, err := s.repo.HasSchedulesInDepartment(doctorID, a.DepartmentID, today)
+		if err != nil {
+			return errorcode.New(errorcode.ErrDatabase)
+		}
+		if has {
+			return errorcode.NewWithMessage(errorcode.ErrDoctorHasSchedule, "该医生在移除的执业科室下仍有未来排班，请先调整排班")
+		}
+	}
+	return nil
 }
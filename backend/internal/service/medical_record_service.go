@@ -1,28 +1,43 @@
 package service
 
 import (
+	"encoding/json"
 	"errors"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 
 	"huaan-medical/internal/model"
 	"huaan-medical/internal/repository"
+	"huaan-medical/pkg/database"
 	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/utils"
 )
 
 // MedicalRecordService 就诊记录服务
+// 病历流程：医生/管理员书写草稿（保存即完成接诊）-> 签署锁定 -> 如需修改提交补充更正
+// 患者端仅可查看已签署的病历
 type MedicalRecordService struct {
-	repo *repository.MedicalRecordRepository
+	repo            *repository.MedicalRecordRepository
+	appointmentRepo *repository.AppointmentRepository
+	doctorRepo      *repository.DoctorRepository
+	adminRepo       *repository.AdminRepository
+	consentRepo     *repository.PatientConsentRepository
 }
 
 // NewMedicalRecordService 创建就诊记录服务实例
 func NewMedicalRecordService() *MedicalRecordService {
 	return &MedicalRecordService{
-		repo: repository.NewMedicalRecordRepository(),
+		repo:            repository.NewMedicalRecordRepository(),
+		appointmentRepo: repository.NewAppointmentRepository(),
+		doctorRepo:      repository.NewDoctorRepository(),
+		adminRepo:       repository.NewAdminRepository(),
+		consentRepo:     repository.NewPatientConsentRepository(),
 	}
 }
 
-// GetByID 获取就诊记录详情（需要权限校验）
+// GetByID 获取就诊记录详情（需要权限校验，成年家人的病历需本人授权）
 func (s *MedicalRecordService) GetByID(userID, recordID int64) (*model.MedicalRecordVO, error) {
 	record, err := s.repo.GetByUserAndID(userID, recordID)
 	if err != nil {
@@ -31,20 +46,382 @@ func (s *MedicalRecordService) GetByID(userID, recordID int64) (*model.MedicalRe
 		}
 		return nil, errorcode.New(errorcode.ErrDatabase)
 	}
+
+	now := time.Now()
+	consents, err := loadRecordConsents(s.consentRepo, userID, now)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	if record.Patient != nil && !consents.allows(record.Patient, now) {
+		return nil, errorcode.New(errorcode.ErrRecordConsentRequired)
+	}
 	return record.ToVO(), nil
 }
 
-// ListByUser 查询用户的就诊记录列表
+// ListByUser 查询用户的就诊记录列表（未获授权的成年家人病历不返回）
 func (s *MedicalRecordService) ListByUser(userID int64) ([]model.MedicalRecordListVO, error) {
 	records, err := s.repo.ListByUser(userID)
 	if err != nil {
 		return nil, errorcode.New(errorcode.ErrDatabase)
 	}
 
-	voList := make([]model.MedicalRecordListVO, len(records))
-	for i, record := range records {
-		voList[i] = *record.ToListVO()
+	now := time.Now()
+	consents, err := loadRecordConsents(s.consentRepo, userID, now)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	voList := make([]model.MedicalRecordListVO, 0, len(records))
+	for _, record := range records {
+		if record.Patient != nil && !consents.allows(record.Patient, now) {
+			continue
+		}
+		voList = append(voList, *record.ToListVO())
 	}
 
 	return voList, nil
 }
+
+// RecordActor 病历操作人
+// Type 为 doctor 时 ID 为医生ID，只能操作本人接诊的病历；为 admin 时 ID 为管理员ID
+type RecordActor struct {
+	Type string
+	ID   int64
+}
+
+// DoctorRecordActor 医生操作人
+func DoctorRecordActor(doctorID int64) RecordActor {
+	return RecordActor{Type: model.UserTypeDoctor, ID: doctorID}
+}
+
+// AdminRecordActor 管理员操作人
+func AdminRecordActor(adminID int64) RecordActor {
+	return RecordActor{Type: model.UserTypeAdmin, ID: adminID}
+}
+
+// isDoctor 是否为医生本人操作
+func (a RecordActor) isDoctor() bool {
+	return a.Type == model.UserTypeDoctor
+}
+
+// SaveMedicalRecordRequest 书写病历请求
+type SaveMedicalRecordRequest struct {
+	Diagnosis    string `json:"diagnosis" binding:"required,max=4000"`
+	Prescription string `json:"prescription" binding:"max=4000"`
+	Advice       string `json:"advice" binding:"max=4000"`
+	Remark       string `json:"remark" binding:"max=512"`
+}
+
+// AmendMedicalRecordRequest 补充更正请求（仅提交需要修改的字段）
+type AmendMedicalRecordRequest struct {
+	Diagnosis    *string `json:"diagnosis" binding:"omitempty,min=1,max=4000"`
+	Prescription *string `json:"prescription" binding:"omitempty,max=4000"`
+	Advice       *string `json:"advice" binding:"omitempty,max=4000"`
+	Remark       *string `json:"remark" binding:"omitempty,max=512"`
+	Reason       string  `json:"reason" binding:"required,max=256"`
+}
+
+// ListMedicalRecordRequest 病历列表请求
+type ListMedicalRecordRequest struct {
+	Page         int    `form:"page" binding:"required,min=1"`
+	PageSize     int    `form:"page_size" binding:"required,min=1,max=100"`
+	DoctorID     *int64 `form:"doctor_id"`
+	DepartmentID *int64 `form:"department_id"`
+	Status       string `form:"status"`
+	StartDate    string `form:"start_date"`
+	EndDate      string `form:"end_date"`
+	Keyword      string `form:"keyword"`
+}
+
+// SaveByAppointment 书写/编辑预约的病历（草稿）
+// 首次保存时若预约为已签到状态，则同一事务内将预约置为已完成
+func (s *MedicalRecordService) SaveByAppointment(actor RecordActor, appointmentID int64, req *SaveMedicalRecordRequest) (*model.MedicalRecordVO, error) {
+	appointment, err := s.getAppointment(actor, appointmentID)
+	if err != nil {
+		return nil, err
+	}
+	if appointment.Status != model.AppointmentStatusCheckedIn && appointment.Status != model.AppointmentStatusCompleted {
+		return nil, errorcode.New(errorcode.ErrRecordAppointmentStatus)
+	}
+
+	record, err := s.repo.GetByAppointmentID(appointmentID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	exists := err == nil
+	if exists && record.IsSigned() {
+		return nil, errorcode.New(errorcode.ErrRecordSigned)
+	}
+
+	now := time.Now()
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if exists {
+			ok, err := s.repo.UpdateDraft(tx, record.ID, map[string]interface{}{
+				"diagnosis":    req.Diagnosis,
+				"prescription": req.Prescription,
+				"advice":       req.Advice,
+				"remark":       req.Remark,
+			})
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return errorcode.New(errorcode.ErrRecordSigned)
+			}
+		} else {
+			record = &model.MedicalRecord{
+				AppointmentID: appointment.ID,
+				PatientID:     appointment.PatientID,
+				DoctorID:      appointment.DoctorID,
+				DepartmentID:  appointment.DepartmentID,
+				VisitDate:     appointment.AppointmentDate,
+				Diagnosis:     req.Diagnosis,
+				Prescription:  req.Prescription,
+				Advice:        req.Advice,
+				Remark:        req.Remark,
+				Status:        model.MedicalRecordStatusDraft,
+				Version:       1,
+				AuthorType:    actor.Type,
+				AuthorID:      actor.ID,
+			}
+			if err := s.repo.Create(tx, record); err != nil {
+				return err
+			}
+		}
+
+		// 保存病历即完成接诊
+		if appointment.Status == model.AppointmentStatusCheckedIn {
+			return tx.Model(&model.Appointment{}).
+				Where("id = ? AND status = ?", appointment.ID, model.AppointmentStatusCheckedIn).
+				Updates(map[string]interface{}{
+					"status":       model.AppointmentStatusCompleted,
+					"completed_at": now,
+				}).Error
+		}
+		return nil
+	})
+	if err != nil {
+		var appErr *errorcode.AppError
+		if errors.As(err, &appErr) {
+			return nil, appErr
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	if appointment.Status == model.AppointmentStatusCheckedIn {
+		invalidateDoctorScheduleCache(appointment.DoctorID)
+	}
+
+	return s.getRecordVO(record.ID)
+}
+
+// GetByAppointment 获取预约的病历
+func (s *MedicalRecordService) GetByAppointment(actor RecordActor, appointmentID int64) (*model.MedicalRecordVO, error) {
+	if _, err := s.getAppointment(actor, appointmentID); err != nil {
+		return nil, err
+	}
+
+	record, err := s.repo.GetByAppointmentID(appointmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrRecordNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return record.ToFullVO(), nil
+}
+
+// GetForActor 获取病历详情（医生只能查看本人接诊的病历）
+func (s *MedicalRecordService) GetForActor(actor RecordActor, recordID int64) (*model.MedicalRecordVO, error) {
+	record, err := s.getRecord(actor, recordID)
+	if err != nil {
+		return nil, err
+	}
+	return record.ToFullVO(), nil
+}
+
+// List 分页查询病历（医生只能查询本人接诊的病历）
+func (s *MedicalRecordService) List(actor RecordActor, req *ListMedicalRecordRequest) ([]model.MedicalRecordVO, int64, error) {
+	var startDate, endDate *time.Time
+	if req.StartDate != "" {
+		sd, err := utils.ParseDate(req.StartDate)
+		if err != nil {
+			return nil, 0, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "开始日期格式错误")
+		}
+		startDate = &sd
+	}
+	if req.EndDate != "" {
+		ed, err := utils.ParseDate(req.EndDate)
+		if err != nil {
+			return nil, 0, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "结束日期格式错误")
+		}
+		endDate = &ed
+	}
+
+	doctorID := req.DoctorID
+	if actor.isDoctor() {
+		doctorID = &actor.ID
+	}
+
+	records, total, err := s.repo.List(req.Page, req.PageSize, doctorID, req.DepartmentID, req.Status, startDate, endDate, req.Keyword)
+	if err != nil {
+		return nil, 0, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	voList := make([]model.MedicalRecordVO, len(records))
+	for i := range records {
+		voList[i] = *records[i].ToFullVO()
+	}
+
+	return voList, total, nil
+}
+
+// Sign 签署病历，签署后内容锁定
+func (s *MedicalRecordService) Sign(actor RecordActor, recordID int64) (*model.MedicalRecordVO, error) {
+	record, err := s.getRecord(actor, recordID)
+	if err != nil {
+		return nil, err
+	}
+	if record.IsSigned() {
+		return nil, errorcode.New(errorcode.ErrRecordSigned)
+	}
+	if strings.TrimSpace(record.Diagnosis) == "" {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "诊断结果不能为空")
+	}
+
+	ok, err := s.repo.Sign(recordID, actor.Type, actor.ID, time.Now())
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	if !ok {
+		return nil, errorcode.New(errorcode.ErrRecordSigned)
+	}
+
+	return s.getRecordVO(recordID)
+}
+
+// Amend 补充更正已签署的病历，记录变更前后内容、原因及更正人
+func (s *MedicalRecordService) Amend(actor RecordActor, recordID int64, req *AmendMedicalRecordRequest) (*model.MedicalRecordVO, error) {
+	record, err := s.getRecord(actor, recordID)
+	if err != nil {
+		return nil, err
+	}
+	if !record.IsSigned() {
+		return nil, errorcode.New(errorcode.ErrRecordNotSigned)
+	}
+
+	updates := map[string]interface{}{}
+	var changes []model.MedicalRecordChange
+	addChange := func(field, fieldName, before string, after *string) {
+		if after == nil || *after == before {
+			return
+		}
+		updates[field] = *after
+		changes = append(changes, model.MedicalRecordChange{
+			Field:     field,
+			FieldName: fieldName,
+			Before:    before,
+			After:     *after,
+		})
+	}
+	addChange("diagnosis", "诊断结果", record.Diagnosis, req.Diagnosis)
+	addChange("prescription", "处方", record.Prescription, req.Prescription)
+	addChange("advice", "医嘱", record.Advice, req.Advice)
+	addChange("remark", "备注", record.Remark, req.Remark)
+	if len(changes) == 0 {
+		return nil, errorcode.New(errorcode.ErrRecordNoChange)
+	}
+
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrInternalServer)
+	}
+
+	amendment := &model.MedicalRecordAmendment{
+		RecordID:   record.ID,
+		Version:    record.Version + 1,
+		Changes:    string(changesJSON),
+		Reason:     req.Reason,
+		AuthorType: actor.Type,
+		AuthorID:   actor.ID,
+		AuthorName: s.actorName(actor),
+	}
+
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		ok, err := s.repo.Amend(tx, record.ID, record.Version, updates, amendment)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "病历已被他人更正，请刷新后重试")
+		}
+		return nil
+	})
+	if err != nil {
+		var appErr *errorcode.AppError
+		if errors.As(err, &appErr) {
+			return nil, appErr
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	return s.getRecordVO(recordID)
+}
+
+// getAppointment 查询操作人可书写病历的预约
+func (s *MedicalRecordService) getAppointment(actor RecordActor, appointmentID int64) (*model.Appointment, error) {
+	var appointment *model.Appointment
+	var err error
+	if actor.isDoctor() {
+		appointment, err = s.appointmentRepo.GetByDoctorAndID(actor.ID, appointmentID)
+	} else {
+		appointment, err = s.appointmentRepo.GetByID(appointmentID)
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrAppointmentNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return appointment, nil
+}
+
+// getRecord 查询操作人可访问的病历（非本人接诊的病历视为不存在）
+func (s *MedicalRecordService) getRecord(actor RecordActor, recordID int64) (*model.MedicalRecord, error) {
+	record, err := s.repo.GetByID(recordID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrRecordNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	if actor.isDoctor() && record.DoctorID != actor.ID {
+		return nil, errorcode.New(errorcode.ErrRecordNotFound)
+	}
+	return record, nil
+}
+
+// getRecordVO 查询病历并转换为完整视图对象
+func (s *MedicalRecordService) getRecordVO(recordID int64) (*model.MedicalRecordVO, error) {
+	record, err := s.repo.GetByID(recordID)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return record.ToFullVO(), nil
+}
+
+// actorName 操作人姓名（用于更正留痕）
+func (s *MedicalRecordService) actorName(actor RecordActor) string {
+	if actor.isDoctor() {
+		if doctor, err := s.doctorRepo.GetByIDSimple(actor.ID); err == nil {
+			return doctor.Name
+		}
+		return ""
+	}
+	if admin, err := s.adminRepo.GetByID(actor.ID); err == nil {
+		if admin.Nickname != "" {
+			return admin.Nickname
+		}
+		return admin.Username
+	}
+	return ""
+}
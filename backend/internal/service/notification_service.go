@@ -0,0 +1,87 @@
+package service
+
+import (
+	"go.uber.org/zap"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/internal/repository"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/logger"
+)
+
+// NotificationService 站内消息服务
+type NotificationService struct {
+	repo *repository.NotificationRepository
+}
+
+// NewNotificationService 创建站内消息服务实例
+func NewNotificationService() *NotificationService {
+	return &NotificationService{
+		repo: repository.NewNotificationRepository(),
+	}
+}
+
+// ListNotificationRequest 消息列表请求
+type ListNotificationRequest struct {
+	Page       int  `form:"page" binding:"required,min=1"`
+	PageSize   int  `form:"page_size" binding:"required,min=1,max=100"`
+	UnreadOnly bool `form:"unread_only"`
+}
+
+// Send 发送站内消息（失败仅记录日志，不影响主流程）
+func (s *NotificationService) Send(notifications []model.Notification) {
+	if len(notifications) == 0 {
+		return
+	}
+	if err := s.repo.BatchCreate(notifications); err != nil {
+		logger.Error("发送站内消息失败", zap.Error(err), zap.Int("count", len(notifications)))
+	}
+}
+
+// List 分页查询用户消息
+func (s *NotificationService) List(userID int64, req *ListNotificationRequest) ([]model.NotificationVO, int64, error) {
+	notifications, total, err := s.repo.ListByUser(userID, req.Page, req.PageSize, req.UnreadOnly)
+	if err != nil {
+		return nil, 0, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	voList := make([]model.NotificationVO, len(notifications))
+	for i, n := range notifications {
+		voList[i] = *n.ToVO()
+	}
+
+	return voList, total, nil
+}
+
+// UnreadCount 获取未读消息数
+func (s *NotificationService) UnreadCount(userID int64) (int64, error) {
+	count, err := s.repo.CountUnread(userID)
+	if err != nil {
+		return 0, errorcode.New(errorcode.ErrDatabase)
+	}
+	return count, nil
+}
+
+// MarkRead 标记消息已读
+func (s *NotificationService) MarkRead(userID, id int64) error {
+	exists, err := s.repo.Exists(userID, id)
+	if err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if !exists {
+		return errorcode.NewWithMessage(errorcode.ErrNotFound, "消息不存在")
+	}
+
+	if _, err := s.repo.MarkRead(userID, id); err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	return nil
+}
+
+// MarkAllRead 标记全部消息已读
+func (s *NotificationService) MarkAllRead(userID int64) error {
+	if err := s.repo.MarkAllRead(userID); err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	return nil
+}
@@ -0,0 +1,369 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/internal/repository"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/logger"
+	"huaan-medical/pkg/utils"
+)
+
+// 疑似重复匹配方式对应的相似度
+var duplicateMatchScores = map[string]int{
+	model.DuplicateMatchIDCard:    100,
+	model.DuplicateMatchNamePhone: 80,
+	model.DuplicateMatchNameBirth: 60,
+}
+
+// PatientDuplicateService 重复就诊人检测与合并服务
+type PatientDuplicateService struct {
+	repo         *repository.PatientDuplicateRepository
+	notification *NotificationService
+}
+
+// NewPatientDuplicateService 创建重复就诊人服务实例
+func NewPatientDuplicateService() *PatientDuplicateService {
+	return &PatientDuplicateService{
+		repo:         repository.NewPatientDuplicateRepository(),
+		notification: NewNotificationService(),
+	}
+}
+
+// ListPatientDuplicatesRequest 疑似重复列表请求
+type ListPatientDuplicatesRequest struct {
+	Page      int    `form:"page" binding:"required,min=1"`
+	PageSize  int    `form:"page_size" binding:"required,min=1,max=100"`
+	Status    string `form:"status" binding:"omitempty,oneof=pending merged ignored"`
+	MatchType string `form:"match_type" binding:"omitempty,oneof=id_card name_phone name_birth"`
+}
+
+// MergePatientRequest 合并疑似重复就诊人请求
+type MergePatientRequest struct {
+	SurvivorID int64  `json:"survivor_id" binding:"required"` // 保留的就诊人ID，须为该记录中的一方
+	Remark     string `json:"remark" binding:"max=255"`
+}
+
+// ReviewPatientDuplicateRequest 忽略/撤销合并请求
+type ReviewPatientDuplicateRequest struct {
+	Remark string `json:"remark" binding:"max=255"`
+}
+
+// ListPatientMergesRequest 合并记录列表请求
+type ListPatientMergesRequest struct {
+	Page      int    `form:"page" binding:"required,min=1"`
+	PageSize  int    `form:"page_size" binding:"required,min=1,max=100"`
+	Status    string `form:"status" binding:"omitempty,oneof=merged undone"`
+	PatientID *int64 `form:"patient_id"`
+}
+
+// DetectDuplicates 扫描全部就诊人，按证件号码、姓名+手机号、姓名+性别+出生日期分组，
+// 将疑似重复的就诊人对写入审核队列（已存在的就诊人对不重复写入），返回新增数量
+func (s *PatientDuplicateService) DetectDuplicates() (int, error) {
+	patients, err := s.repo.ListPatientsForScan()
+	if err != nil {
+		return 0, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	groups := map[string]map[string][]int64{
+		model.DuplicateMatchIDCard:    {},
+		model.DuplicateMatchNamePhone: {},
+		model.DuplicateMatchNameBirth: {},
+	}
+	for _, p := range patients {
+		name := strings.Join(strings.Fields(p.Name), "")
+		if p.IDCard != "" {
+			key := p.DocTypeOrDefault() + "|" + p.IDCard
+			groups[model.DuplicateMatchIDCard][key] = append(groups[model.DuplicateMatchIDCard][key], p.ID)
+		}
+		if name != "" && p.Phone != "" {
+			key := name + "|" + p.Phone
+			groups[model.DuplicateMatchNamePhone][key] = append(groups[model.DuplicateMatchNamePhone][key], p.ID)
+		}
+		if name != "" && p.BirthDate != "" && p.Gender != model.GenderUnknown {
+			key := fmt.Sprintf("%s|%d|%s", name, p.Gender, normalizeBirthDate(p.BirthDate))
+			groups[model.DuplicateMatchNameBirth][key] = append(groups[model.DuplicateMatchNameBirth][key], p.ID)
+		}
+	}
+
+	// 同一对就诊人只保留相似度最高的匹配方式
+	pairs := make(map[[2]int64]string)
+	for _, matchType := range []string{model.DuplicateMatchIDCard, model.DuplicateMatchNamePhone, model.DuplicateMatchNameBirth} {
+		for _, ids := range groups[matchType] {
+			for i := 0; i < len(ids); i++ {
+				for j := i + 1; j < len(ids); j++ {
+					key := [2]int64{ids[i], ids[j]}
+					if ids[j] < ids[i] {
+						key = [2]int64{ids[j], ids[i]}
+					}
+					if _, ok := pairs[key]; !ok {
+						pairs[key] = matchType
+					}
+				}
+			}
+		}
+	}
+
+	keys := make([][2]int64, 0, len(pairs))
+	for key := range pairs {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+
+	created := 0
+	for _, key := range keys {
+		matchType := pairs[key]
+		ok, err := s.repo.CreateIfAbsent(&model.PatientDuplicate{
+			PatientID:   key[0],
+			DuplicateID: key[1],
+			MatchType:   matchType,
+			Score:       duplicateMatchScores[matchType],
+			Status:      model.DuplicateStatusPending,
+		})
+		if err != nil {
+			return created, errorcode.New(errorcode.ErrDatabase)
+		}
+		if ok {
+			created++
+		}
+	}
+	return created, nil
+}
+
+// List 分页查询疑似重复就诊人
+func (s *PatientDuplicateService) List(req *ListPatientDuplicatesRequest) ([]model.PatientDuplicateVO, int64, error) {
+	list, total, err := s.repo.List(req.Page, req.PageSize, req.Status, req.MatchType)
+	if err != nil {
+		return nil, 0, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	voList := make([]model.PatientDuplicateVO, len(list))
+	for i := range list {
+		voList[i] = *list[i].ToVO()
+	}
+	return voList, total, nil
+}
+
+// Ignore 标记为非同一人，后续检测不再入队
+func (s *PatientDuplicateService) Ignore(adminID, id int64, req *ReviewPatientDuplicateRequest) error {
+	dup, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errorcode.New(errorcode.ErrPatientDuplicateNotFound)
+		}
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if dup.Status != model.DuplicateStatusPending {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该记录已处理")
+	}
+
+	ok, err := s.repo.Review(id, model.DuplicateStatusIgnored, adminID, req.Remark, time.Now())
+	if err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if !ok {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该记录已处理")
+	}
+	return nil
+}
+
+// Merge 合并疑似重复就诊人：预约、病历、评价迁移到保留方，被合并方软删除，
+// 被合并方的所有者及共享成员自动加入保留方的共享成员，全部在同一事务内完成
+func (s *PatientDuplicateService) Merge(adminID int64, adminName string, id int64, req *MergePatientRequest) (*model.PatientMergeVO, error) {
+	dup, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrPatientDuplicateNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	if dup.Status != model.DuplicateStatusPending {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该记录已处理")
+	}
+
+	var mergedID int64
+	switch req.SurvivorID {
+	case dup.PatientID:
+		mergedID = dup.DuplicateID
+	case dup.DuplicateID:
+		mergedID = dup.PatientID
+	default:
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "保留的就诊人必须是该记录中的一方")
+	}
+
+	survivor, err := s.getAlivePatient(req.SurvivorID)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := s.getAlivePatient(mergedID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	merge := &model.PatientMerge{
+		SurvivorID:  survivor.ID,
+		MergedID:    merged.ID,
+		DuplicateID: dup.ID,
+		Status:      model.PatientMergeStatusMerged,
+		AdminID:     adminID,
+		AdminName:   adminName,
+		Remark:      req.Remark,
+	}
+	ok, err := s.repo.Merge(merge, survivor, merged, dup, now)
+	if err != nil {
+		logger.Error("合并就诊人失败", zap.Int64("survivor_id", survivor.ID), zap.Int64("merged_id", merged.ID), zap.Error(err))
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	if !ok {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该记录已处理或就诊人已被合并，请刷新后重试")
+	}
+	logger.Info("合并就诊人", zap.Int64("merge_id", merge.ID), zap.Int64("survivor_id", survivor.ID),
+		zap.Int64("merged_id", merged.ID), zap.Int64("admin_id", adminID))
+
+	// 被合并方属于其他账号时告知其所有者
+	if merged.UserID != survivor.UserID {
+		s.notification.Send([]model.Notification{{
+			UserID:  merged.UserID,
+			Type:    model.NotificationTypePatientMerged,
+			Title:   "就诊人档案已合并",
+			Content: fmt.Sprintf("经医院核实，您添加的就诊人%s与家人名下的同一就诊人档案已合并，预约及病历可在共享的就诊人下查看", utils.MaskName(merged.Name)),
+			BizID:   survivor.ID,
+		}})
+	}
+
+	return s.buildMergeVO(merge)
+}
+
+// ListMerges 分页查询合并记录（审计）
+func (s *PatientDuplicateService) ListMerges(req *ListPatientMergesRequest) ([]model.PatientMergeVO, int64, error) {
+	list, total, err := s.repo.ListMerges(req.Page, req.PageSize, req.Status, req.PatientID)
+	if err != nil {
+		return nil, 0, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	voList := make([]model.PatientMergeVO, 0, len(list))
+	for i := range list {
+		vo, err := s.buildMergeVO(&list[i])
+		if err != nil {
+			return nil, 0, err
+		}
+		voList = append(voList, *vo)
+	}
+	return voList, total, nil
+}
+
+// Undo 撤销合并：恢复被合并的就诊人并将迁移的数据迁回
+// 保留方已被删除或再次合并时需先处理后续操作
+func (s *PatientDuplicateService) Undo(adminID int64, adminName string, mergeID int64, req *ReviewPatientDuplicateRequest) (*model.PatientMergeVO, error) {
+	merge, err := s.repo.GetMergeByID(mergeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrPatientMergeNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	if merge.Status != model.PatientMergeStatusMerged {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该合并已撤销")
+	}
+
+	if _, err := s.getAlivePatient(merge.SurvivorID); err != nil {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "保留的就诊人已被删除或再次合并，请先撤销后续合并")
+	}
+	merged, err := s.repo.GetPatientUnscoped(merge.MergedID)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	if merged.MergedInto != merge.SurvivorID {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "被合并的就诊人状态已变化，无法撤销")
+	}
+
+	snapshot, err := decodeMergeSnapshot(merge)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	merge.UndoneBy = adminID
+	merge.UndoneName = adminName
+	merge.UndoneAt = &now
+	merge.UndoRemark = req.Remark
+	ok, err := s.repo.Undo(merge, snapshot)
+	if err != nil {
+		logger.Error("撤销就诊人合并失败", zap.Int64("merge_id", merge.ID), zap.Error(err))
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	if !ok {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该合并已撤销")
+	}
+	merge.Status = model.PatientMergeStatusUndone
+	logger.Info("撤销就诊人合并", zap.Int64("merge_id", merge.ID), zap.Int64("admin_id", adminID))
+
+	return s.buildMergeVO(merge)
+}
+
+// getAlivePatient 查询未被删除/合并的就诊人
+func (s *PatientDuplicateService) getAlivePatient(id int64) (*model.Patient, error) {
+	patient, err := s.repo.GetPatientUnscoped(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrPatientNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	if patient.DeletedAt.Valid {
+		return nil, errorcode.NewWithMessage(errorcode.ErrPatientNotFound, fmt.Sprintf("就诊人%d已被删除或合并", id))
+	}
+	return patient, nil
+}
+
+// buildMergeVO 组装合并记录视图（含双方就诊人）
+func (s *PatientDuplicateService) buildMergeVO(merge *model.PatientMerge) (*model.PatientMergeVO, error) {
+	snapshot, err := decodeMergeSnapshot(merge)
+	if err != nil {
+		return nil, err
+	}
+	vo := merge.ToVO(snapshot)
+	if survivor, err := s.repo.GetPatientUnscoped(merge.SurvivorID); err == nil {
+		vo.Survivor = survivor.ToVO()
+	}
+	if merged, err := s.repo.GetPatientUnscoped(merge.MergedID); err == nil {
+		vo.Merged = merged.ToVO()
+	}
+	return vo, nil
+}
+
+// decodeMergeSnapshot 解析合并快照
+func decodeMergeSnapshot(merge *model.PatientMerge) (*model.PatientMergeSnapshot, error) {
+	var snapshot model.PatientMergeSnapshot
+	if merge.Snapshot == "" {
+		return &snapshot, nil
+	}
+	if err := json.Unmarshal([]byte(merge.Snapshot), &snapshot); err != nil {
+		logger.Error("解析就诊人合并快照失败", zap.Int64("merge_id", merge.ID), zap.Error(err))
+		return nil, errorcode.New(errorcode.ErrInternalServer)
+	}
+	return &snapshot, nil
+}
+
+// normalizeBirthDate 出生日期统一为 YYYY-MM-DD（数据库 date 字段可能带时间部分）
+func normalizeBirthDate(birthDate string) string {
+	if len(birthDate) >= 10 {
+		return birthDate[:10]
+	}
+	return birthDate
+}
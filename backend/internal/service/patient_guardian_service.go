@@ -0,0 +1,270 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/internal/repository"
+	"huaan-medical/pkg/config"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/sms"
+	"huaan-medical/pkg/utils"
+)
+
+// defaultConsentValidDays 病历查看授权默认有效期（天）
+const defaultConsentValidDays = 365
+
+// PatientGuardianService 就诊人监护规则服务
+// 未成年就诊人由监护人（所有者及共享成员）代管；年满18周岁后：
+//   - 子女就诊人资料对监护人只读，可转交给本人账号管理
+//   - 家人（非本人）就诊人的病历需经本人账号短信授权后方可查看
+type PatientGuardianService struct {
+	patientRepo  *repository.PatientRepository
+	consentRepo  *repository.PatientConsentRepository
+	userRepo     *repository.UserRepository
+	notification *NotificationService
+}
+
+// NewPatientGuardianService 创建就诊人监护规则服务实例
+func NewPatientGuardianService() *PatientGuardianService {
+	return &PatientGuardianService{
+		patientRepo:  repository.NewPatientRepository(),
+		consentRepo:  repository.NewPatientConsentRepository(),
+		userRepo:     repository.NewUserRepository(),
+		notification: NewNotificationService(),
+	}
+}
+
+// GrantPatientConsentRequest 病历查看授权请求
+type GrantPatientConsentRequest struct {
+	Code string `json:"code" binding:"required,len=6"` // 发送到就诊人本人账号手机号的验证码
+}
+
+// SendConsentCodeResult 授权验证码发送结果
+type SendConsentCodeResult struct {
+	Phone string `json:"phone"` // 脱敏后的接收手机号
+}
+
+// SendConsentCode 向成年家人本人账号绑定的手机号发送授权验证码
+func (s *PatientGuardianService) SendConsentCode(userID, patientID int64) (*SendConsentCodeResult, error) {
+	patient, err := s.loadConsentPatient(userID, patientID)
+	if err != nil {
+		return nil, err
+	}
+	grantor, err := s.loadConsentGrantor(userID, patient)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := config.Get()
+	if cfg == nil || !cfg.SMS.Enabled {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "短信服务未启用")
+	}
+	if err := sms.GetService().SendCode(grantor.Phone); err != nil {
+		return nil, errorcode.NewWithMessage(errorcode.ErrSMSCodeSendTooFrequent, err.Error())
+	}
+
+	return &SendConsentCodeResult{Phone: utils.MaskPhone(grantor.Phone)}, nil
+}
+
+// GrantConsent 凭本人账号手机号收到的验证码完成病历查看授权
+func (s *PatientGuardianService) GrantConsent(userID, patientID int64, req *GrantPatientConsentRequest) (*model.PatientConsentVO, error) {
+	patient, err := s.loadConsentPatient(userID, patientID)
+	if err != nil {
+		return nil, err
+	}
+	grantor, err := s.loadConsentGrantor(userID, patient)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sms.GetService().VerifyCode(grantor.Phone, req.Code); err != nil {
+		return nil, errorcode.NewWithMessage(errorcode.ErrSMSCodeInvalid, err.Error())
+	}
+
+	now := time.Now()
+	// 重新授权时作废旧授权，保证同一用户只有一条有效授权
+	if err := s.consentRepo.RevokeByPatientAndUser(patient.ID, userID, now); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	consent := &model.PatientConsent{
+		PatientID: patient.ID,
+		UserID:    userID,
+		GrantorID: grantor.ID,
+		Phone:     grantor.Phone,
+		GrantedAt: now,
+		ExpiresAt: now.AddDate(0, 0, consentValidDays()),
+	}
+	if err := s.consentRepo.Create(consent); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return consent.ToVO(), nil
+}
+
+// Transfer 将已成年的子女就诊人转交给本人账号
+// 接收账号须已绑定就诊人登记的手机号并通过三要素实名核验，且该账号尚未登记其他人为本人；
+// 转交后就诊人成为接收账号的本人就诊人，原所有者、共享成员及授权均不再保留
+func (s *PatientGuardianService) Transfer(userID, patientID int64) error {
+	patient, _, err := loadPatientForRole(s.patientRepo, userID, patientID, model.PatientRoleOwner)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if !patient.IsGrownChildAt(now) {
+		return errorcode.NewWithMessage(errorcode.ErrPatientTransferInvalid, "仅已成年的子女就诊人可转交本人管理")
+	}
+
+	target, err := s.userRepo.GetByPhone(patient.Phone)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errorcode.NewWithMessage(errorcode.ErrPatientTransferInvalid, "就诊人登记的手机号尚未注册账号，请本人先使用该手机号登录")
+		}
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if target.ID == userID {
+		return errorcode.NewWithMessage(errorcode.ErrPatientTransferInvalid, "就诊人登记的手机号为您本人账号，请修改为子女本人的手机号")
+	}
+	if target.Status == model.StatusDisabled {
+		return errorcode.NewWithMessage(errorcode.ErrPatientTransferInvalid, "接收账号已停用")
+	}
+
+	exists, err := s.patientRepo.ExistsByIDCard(target.ID, patient.IDCard)
+	if err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if exists {
+		return errorcode.NewWithMessage(errorcode.ErrPatientTransferInvalid, "对方账号已添加该就诊人，如需合并档案请联系客服")
+	}
+	if _, err := s.patientRepo.GetSelfByUser(target.ID); err == nil {
+		return errorcode.NewWithMessage(errorcode.ErrPatientTransferInvalid, "对方账号已登记其他本人信息，与该就诊人不一致")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if taken, err := s.patientRepo.ExistsSelfByIDCard(patient.IDCard, target.ID); err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	} else if taken {
+		return errorcode.NewWithMessage(errorcode.ErrPatientTransferInvalid, "该证件已被其他账号登记为本人，如有疑问请联系客服")
+	}
+
+	// 转交后成为接收账号的本人就诊人，需核验姓名、证件号码与接收账号手机号一致
+	verified := *patient
+	verified.Relation = model.RelationSelf
+	verified.Phone = target.Phone
+	applyIdentityVerification(&verified)
+	if verified.VerifyStatus == model.PatientVerifyFailed || requireSelfVerified(&verified) != nil {
+		return errorcode.NewWithMessage(errorcode.ErrPatientTransferInvalid, "就诊人身份与接收账号的手机号核验不一致或核验服务暂不可用")
+	}
+
+	count, err := s.patientRepo.CountByUser(target.ID)
+	if err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if count >= 10 {
+		return errorcode.NewWithMessage(errorcode.ErrPatientTransferInvalid, "对方账号的就诊人已达上限")
+	}
+	isDefault := 0
+	if count == 0 {
+		isDefault = 1
+	}
+
+	if err := s.patientRepo.Transfer(&verified, target.ID, isDefault, now); err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+
+	// 原所有者的默认就诊人被转走时，自动设置第一个为默认
+	if patient.IsDefault == 1 {
+		patients, err := s.patientRepo.ListByUser(userID)
+		if err == nil && len(patients) > 0 {
+			_ = s.patientRepo.SetDefault(userID, patients[0].ID)
+		}
+	}
+
+	s.notification.Send([]model.Notification{{
+		UserID:  target.ID,
+		Type:    model.NotificationTypePatientTransfer,
+		Title:   "就诊档案已转交给您",
+		Content: fmt.Sprintf("家人已将就诊人%s的档案转交给您本人管理，可在就诊人管理中查看", utils.MaskName(patient.Name)),
+		BizID:   patient.ID,
+	}})
+	return nil
+}
+
+// loadConsentPatient 查询需要授权才能查看病历的就诊人
+func (s *PatientGuardianService) loadConsentPatient(userID, patientID int64) (*model.Patient, error) {
+	patient, _, err := loadPatientForRole(s.patientRepo, userID, patientID, model.PatientRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	if !patient.NeedsRecordConsentAt(time.Now()) {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该就诊人的病历无需授权即可查看")
+	}
+	return patient, nil
+}
+
+// loadConsentGrantor 查询成年家人本人的账号（已实名登记为本人、证件号码一致）
+// 验证码只发送到该账号绑定的手机号，监护人无法修改
+func (s *PatientGuardianService) loadConsentGrantor(userID int64, patient *model.Patient) (*model.User, error) {
+	self, err := s.patientRepo.GetVerifiedSelfByIDCard(patient.IDCard)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该就诊人尚未使用本人账号实名登记，请本人登录后添加本人就诊人再授权")
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	if self.UserID == userID {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该就诊人已登记为您本人")
+	}
+
+	grantor, err := s.userRepo.GetByID(self.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "就诊人本人账号不存在")
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	if grantor.Status == model.StatusDisabled || !utils.ValidatePhone(grantor.Phone) {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "就诊人本人账号暂无法接收验证码，请联系客服")
+	}
+	return grantor, nil
+}
+
+// recordConsents 用户获得的病历查看授权（按就诊人分组）
+type recordConsents map[int64][]model.PatientConsent
+
+// loadRecordConsents 查询用户获得的有效病历查看授权
+func loadRecordConsents(repo *repository.PatientConsentRepository, userID int64, now time.Time) (recordConsents, error) {
+	list, err := repo.ListActiveByUser(userID, now)
+	if err != nil {
+		return nil, err
+	}
+	consents := make(recordConsents, len(list))
+	for _, c := range list {
+		consents[c.PatientID] = append(consents[c.PatientID], c)
+	}
+	return consents, nil
+}
+
+// allows 是否可查看就诊人的病历：未成年或本人无需授权，成年家人需有效授权
+func (c recordConsents) allows(patient *model.Patient, now time.Time) bool {
+	if !patient.NeedsRecordConsentAt(now) {
+		return true
+	}
+	for i := range c[patient.ID] {
+		if c[patient.ID][i].ValidAt(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// consentValidDays 病历查看授权有效期（天）
+func consentValidDays() int {
+	if cfg := config.Get(); cfg != nil && cfg.Business.Patient.ConsentValidDays > 0 {
+		return cfg.Business.Patient.ConsentValidDays
+	}
+	return defaultConsentValidDays
+}
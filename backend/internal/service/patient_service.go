@@ -2,31 +2,46 @@ package service
 
 import (
 	"errors"
+	"strings"
+	"time"
 
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 
 	"huaan-medical/internal/model"
 	"huaan-medical/internal/repository"
 	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/identity"
+	"huaan-medical/pkg/logger"
 	"huaan-medical/pkg/utils"
 )
 
 // PatientService 就诊人服务
 type PatientService struct {
-	repo *repository.PatientRepository
+	repo        *repository.PatientRepository
+	memberRepo  *repository.PatientMemberRepository
+	consentRepo *repository.PatientConsentRepository
+	userRepo    *repository.UserRepository
 }
 
 // NewPatientService 创建就诊人服务实例
 func NewPatientService() *PatientService {
 	return &PatientService{
-		repo: repository.NewPatientRepository(),
+		repo:        repository.NewPatientRepository(),
+		memberRepo:  repository.NewPatientMemberRepository(),
+		consentRepo: repository.NewPatientConsentRepository(),
+		userRepo:    repository.NewUserRepository(),
 	}
 }
 
 // CreatePatientRequest 创建就诊人请求
+// 居民身份证的性别、出生日期由号码解析，无需填写；护照、港澳通行证需填写性别及出生日期
 type CreatePatientRequest struct {
 	Name      string `json:"name" binding:"required,min=2,max=32"`
-	IDCard    string `json:"id_card" binding:"required,len=18"`
+	DocType   string `json:"doc_type" binding:"omitempty,oneof=id_card passport hk_macau_permit"` // 证件类型，默认居民身份证
+	IDCard    string `json:"id_card" binding:"required,max=32"`                                   // 证件号码
+	Gender    int    `json:"gender" binding:"omitempty,oneof=1 2"`
+	BirthDate string `json:"birth_date"` // YYYY-MM-DD
 	Phone     string `json:"phone" binding:"required,len=11"`
 	Relation  string `json:"relation" binding:"required,oneof=self parent child spouse other"`
 	IsDefault int    `json:"is_default" binding:"oneof=0 1"`
@@ -35,17 +50,29 @@ type CreatePatientRequest struct {
 // UpdatePatientRequest 更新就诊人请求
 type UpdatePatientRequest struct {
 	Name      string `json:"name" binding:"required,min=2,max=32"`
-	IDCard    string `json:"id_card" binding:"required,len=18"`
+	DocType   string `json:"doc_type" binding:"omitempty,oneof=id_card passport hk_macau_permit"` // 证件类型，默认居民身份证
+	IDCard    string `json:"id_card" binding:"required,max=32"`                                   // 证件号码
+	Gender    int    `json:"gender" binding:"omitempty,oneof=1 2"`
+	BirthDate string `json:"birth_date"` // YYYY-MM-DD
 	Phone     string `json:"phone" binding:"required,len=11"`
 	Relation  string `json:"relation" binding:"required,oneof=self parent child spouse other"`
 	IsDefault int    `json:"is_default" binding:"oneof=0 1"`
 }
 
+// patientIdentity 就诊人证件信息（校验并解析后）
+type patientIdentity struct {
+	DocType   string
+	Number    string
+	Gender    int
+	BirthDate string
+}
+
 // Create 创建就诊人
 func (s *PatientService) Create(userID int64, req *CreatePatientRequest) (*model.PatientVO, error) {
-	// 验证身份证号格式
-	if !utils.ValidateIDCard(req.IDCard) {
-		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "身份证号格式错误")
+	// 校验证件并解析性别、出生日期
+	ident, err := resolvePatientIdentity(req.DocType, req.IDCard, req.Gender, req.BirthDate)
+	if err != nil {
+		return nil, err
 	}
 
 	// 验证手机号格式
@@ -53,6 +80,13 @@ func (s *PatientService) Create(userID int64, req *CreatePatientRequest) (*model
 		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "手机号格式错误")
 	}
 
+	// 本人就诊人须与账号身份一致
+	if req.Relation == model.RelationSelf {
+		if err := s.checkSelfPatient(userID, 0, ident.Number, req.Phone); err != nil {
+			return nil, err
+		}
+	}
+
 	// 检查就诊人数量限制（每个用户最多10个就诊人）
 	count, err := s.repo.CountByUser(userID)
 	if err != nil {
@@ -62,18 +96,42 @@ func (s *PatientService) Create(userID int64, req *CreatePatientRequest) (*model
 		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "最多只能添加10个就诊人")
 	}
 
-	// 检查身份证号是否重复（同一用户下）
-	exists, err := s.repo.ExistsByIDCard(userID, req.IDCard)
+	// 检查证件号码是否重复（同一用户下）
+	exists, err := s.repo.ExistsByIDCard(userID, ident.Number)
 	if err != nil {
 		return nil, errorcode.New(errorcode.ErrDatabase)
 	}
 	if exists {
-		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该身份证号已添加")
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该证件号码已添加")
 	}
 
-	// 从身份证号提取信息
-	gender := utils.GetGenderFromIDCard(req.IDCard)
-	birthDate := utils.GetBirthDateFromIDCard(req.IDCard)
+	// 已通过共享获得该就诊人时无需重复添加，避免预约和病历分散在两份档案
+	exists, err = s.repo.ExistsSharedByIDCard(userID, ident.Number)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	if exists {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该就诊人已由家人共享给您，无需重复添加")
+	}
+
+	// 实名核验（不一致时拒绝添加）
+	patient := &model.Patient{
+		UserID:    userID,
+		Name:      strings.TrimSpace(req.Name),
+		DocType:   ident.DocType,
+		IDCard:    ident.Number,
+		Phone:     req.Phone,
+		Gender:    ident.Gender,
+		BirthDate: ident.BirthDate,
+		Relation:  req.Relation,
+	}
+	applyIdentityVerification(patient)
+	if patient.VerifyStatus == model.PatientVerifyFailed {
+		return nil, errorcode.NewWithMessage(errorcode.ErrIdentityMismatch, patient.VerifyRemark)
+	}
+	if err := requireSelfVerified(patient); err != nil {
+		return nil, err
+	}
 
 	// 如果是第一个就诊人，自动设为默认
 	if count == 0 {
@@ -88,17 +146,7 @@ func (s *PatientService) Create(userID int64, req *CreatePatientRequest) (*model
 	}
 
 	// 创建就诊人
-	patient := &model.Patient{
-		UserID:    userID,
-		Name:      req.Name,
-		IDCard:    req.IDCard,
-		Phone:     req.Phone,
-		Gender:    gender,
-		BirthDate: birthDate,
-		Relation:  req.Relation,
-		IsDefault: req.IsDefault,
-	}
-
+	patient.IsDefault = req.IsDefault
 	if err := s.repo.Create(patient); err != nil {
 		return nil, errorcode.New(errorcode.ErrDatabase)
 	}
@@ -109,14 +157,15 @@ func (s *PatientService) Create(userID int64, req *CreatePatientRequest) (*model
 		return nil, errorcode.New(errorcode.ErrDatabase)
 	}
 
-	return patient.ToFullVO(), nil
+	return patientVOForRole(patient, model.PatientRoleOwner), nil
 }
 
 // Update 更新就诊人
 func (s *PatientService) Update(userID, patientID int64, req *UpdatePatientRequest) (*model.PatientVO, error) {
-	// 验证身份证号格式
-	if !utils.ValidateIDCard(req.IDCard) {
-		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "身份证号格式错误")
+	// 校验证件并解析性别、出生日期
+	ident, err := resolvePatientIdentity(req.DocType, req.IDCard, req.Gender, req.BirthDate)
+	if err != nil {
+		return nil, err
 	}
 
 	// 验证手机号格式
@@ -124,27 +173,60 @@ func (s *PatientService) Update(userID, patientID int64, req *UpdatePatientReque
 		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "手机号格式错误")
 	}
 
-	// 检查就诊人是否存在且属于该用户
-	patient, err := s.repo.GetByUserAndID(userID, patientID)
+	// 检查就诊人是否存在且当前用户可编辑（所有者或管理者）
+	patient, role, err := loadPatientForRole(s.repo, userID, patientID, model.PatientRoleManager)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errorcode.New(errorcode.ErrPatientNotFound)
-		}
-		return nil, errorcode.New(errorcode.ErrDatabase)
+		return nil, err
+	}
+
+	// 已成年的子女资料只读，需转交本人管理后由本人修改
+	now := time.Now()
+	if patient.IsGrownChildAt(now) {
+		return nil, errorcode.New(errorcode.ErrPatientLocked)
 	}
 
-	// 检查身份证号是否重复（同一用户下，排除自己）
-	exists, err := s.repo.ExistsByIDCard(userID, req.IDCard, patientID)
+	// 默认就诊人及与用户关系均相对所有者，共享成员不可修改
+	if role != model.PatientRoleOwner {
+		req.IsDefault = patient.IsDefault
+		req.Relation = patient.Relation
+	}
+
+	// 检查证件号码是否重复（所有者名下，排除自己）
+	exists, err := s.repo.ExistsByIDCard(patient.UserID, ident.Number, patientID)
 	if err != nil {
 		return nil, errorcode.New(errorcode.ErrDatabase)
 	}
 	if exists {
-		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该身份证号已添加")
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该证件号码已添加")
+	}
+
+	name := strings.TrimSpace(req.Name)
+
+	// 成年家人的病历需本人授权，其身份、手机号、出生日期及关系对监护人只读，避免修改后绕过授权
+	if patient.NeedsRecordConsentAt(now) {
+		if name != patient.Name || ident.DocType != patient.DocTypeOrDefault() || ident.Number != patient.IDCard ||
+			req.Phone != patient.Phone || ident.BirthDate != patientBirthDate(patient) || req.Relation != patient.Relation {
+			return nil, errorcode.NewWithMessage(errorcode.ErrPatientLocked, "该就诊人已成年，姓名、证件、手机号、出生日期及关系不可修改")
+		}
 	}
 
-	// 从身份证号提取信息
-	gender := utils.GetGenderFromIDCard(req.IDCard)
-	birthDate := utils.GetBirthDateFromIDCard(req.IDCard)
+	// 姓名或证件变更后需重新实名核验；本人就诊人还需核验账号手机号，登记为本人或更换手机号时同样重新核验
+	// 身份信息未变更的历史本人记录可直接修改其他信息，保持原核验状态
+	identityChanged := name != patient.Name || ident.DocType != patient.DocTypeOrDefault() || ident.Number != patient.IDCard
+	reverify := identityChanged || req.Relation == model.RelationSelf &&
+		(patient.Relation != model.RelationSelf || req.Phone != patient.Phone)
+
+	// 已实名的本人就诊人即账号身份，不可更换为他人
+	if patient.Relation == model.RelationSelf && patient.VerifyStatus == model.PatientVerifyPassed {
+		if identityChanged || req.Relation != model.RelationSelf {
+			return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "本人就诊人已实名核验，姓名、证件及关系不可修改")
+		}
+	}
+	if reverify && req.Relation == model.RelationSelf {
+		if err := s.checkSelfPatient(patient.UserID, patient.ID, ident.Number, req.Phone); err != nil {
+			return nil, err
+		}
+	}
 
 	// 如果要设置为默认，先清除其他默认标记
 	if req.IsDefault == 1 && patient.IsDefault == 0 {
@@ -154,13 +236,23 @@ func (s *PatientService) Update(userID, patientID int64, req *UpdatePatientReque
 	}
 
 	// 更新就诊人信息
-	patient.Name = req.Name
-	patient.IDCard = req.IDCard
+	patient.Name = name
+	patient.DocType = ident.DocType
+	patient.IDCard = ident.Number
 	patient.Phone = req.Phone
-	patient.Gender = gender
-	patient.BirthDate = birthDate
+	patient.Gender = ident.Gender
+	patient.BirthDate = ident.BirthDate
 	patient.Relation = req.Relation
 	patient.IsDefault = req.IsDefault
+	if reverify {
+		applyIdentityVerification(patient)
+		if patient.VerifyStatus == model.PatientVerifyFailed {
+			return nil, errorcode.NewWithMessage(errorcode.ErrIdentityMismatch, patient.VerifyRemark)
+		}
+		if err := requireSelfVerified(patient); err != nil {
+			return nil, err
+		}
+	}
 
 	if err := s.repo.Update(patient); err != nil {
 		return nil, errorcode.New(errorcode.ErrDatabase)
@@ -172,18 +264,15 @@ func (s *PatientService) Update(userID, patientID int64, req *UpdatePatientReque
 		return nil, errorcode.New(errorcode.ErrDatabase)
 	}
 
-	return patient.ToFullVO(), nil
+	return patientVOForRole(patient, role), nil
 }
 
-// Delete 删除就诊人
+// Delete 删除就诊人（同时解除全部共享）
 func (s *PatientService) Delete(userID, patientID int64) error {
-	// 检查就诊人是否存在且属于该用户
-	patient, err := s.repo.GetByUserAndID(userID, patientID)
+	// 仅所有者可删除；共享成员请使用退出共享
+	patient, _, err := loadPatientForRole(s.repo, userID, patientID, model.PatientRoleOwner)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errorcode.New(errorcode.ErrPatientNotFound)
-		}
-		return errorcode.New(errorcode.ErrDatabase)
+		return err
 	}
 
 	// 检查是否有预约记录
@@ -211,43 +300,75 @@ func (s *PatientService) Delete(userID, patientID int64) error {
 	return nil
 }
 
-// GetByID 获取就诊人详情
+// GetByID 获取就诊人详情（查看者仅返回脱敏信息）
 func (s *PatientService) GetByID(userID, patientID int64) (*model.PatientVO, error) {
-	// 检查就诊人是否存在且属于该用户
-	patient, err := s.repo.GetByUserAndID(userID, patientID)
+	patient, role, err := loadPatientForRole(s.repo, userID, patientID, model.PatientRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	consents, err := loadRecordConsents(s.consentRepo, userID, now)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errorcode.New(errorcode.ErrPatientNotFound)
-		}
 		return nil, errorcode.New(errorcode.ErrDatabase)
 	}
-	return patient.ToFullVO(), nil
+	vo := patientVOForRole(patient, role)
+	vo.ConsentRequired = !consents.allows(patient, now)
+	return vo, nil
 }
 
-// List 查询用户的就诊人列表
+// List 查询用户的就诊人列表（本人创建的在前，其后为共享给用户的就诊人）
 func (s *PatientService) List(userID int64) ([]model.PatientVO, error) {
 	patients, err := s.repo.ListByUser(userID)
 	if err != nil {
 		return nil, errorcode.New(errorcode.ErrDatabase)
 	}
 
-	voList := make([]model.PatientVO, len(patients))
-	for i, patient := range patients {
-		voList[i] = *patient.ToVO()
+	members, err := s.memberRepo.ListByUser(userID)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	roles := make(map[int64]string, len(members))
+	sharedIDs := make([]int64, 0, len(members))
+	for _, m := range members {
+		roles[m.PatientID] = m.Role
+		sharedIDs = append(sharedIDs, m.PatientID)
+	}
+	shared, err := s.repo.ListByIDs(sharedIDs)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	now := time.Now()
+	consents, err := loadRecordConsents(s.consentRepo, userID, now)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	voList := make([]model.PatientVO, 0, len(patients)+len(shared))
+	for _, patient := range patients {
+		vo := patient.ToVO()
+		vo.Role = model.PatientRoleOwner
+		vo.RoleName = model.GetPatientRoleName(vo.Role)
+		vo.ConsentRequired = !consents.allows(&patient, now)
+		voList = append(voList, *vo)
+	}
+	for _, patient := range shared {
+		vo := patient.ToVO()
+		vo.IsDefault = 0
+		vo.Role = roles[patient.ID]
+		vo.RoleName = model.GetPatientRoleName(vo.Role)
+		vo.ConsentRequired = !consents.allows(&patient, now)
+		voList = append(voList, *vo)
 	}
 
 	return voList, nil
 }
 
-// SetDefault 设置默认就诊人
+// SetDefault 设置默认就诊人（仅限本人创建的就诊人）
 func (s *PatientService) SetDefault(userID, patientID int64) error {
-	// 检查就诊人是否存在且属于该用户
-	_, err := s.repo.GetByUserAndID(userID, patientID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errorcode.New(errorcode.ErrPatientNotFound)
-		}
-		return errorcode.New(errorcode.ErrDatabase)
+	if _, _, err := loadPatientForRole(s.repo, userID, patientID, model.PatientRoleOwner); err != nil {
+		return err
 	}
 
 	return s.repo.SetDefault(userID, patientID)
@@ -291,3 +412,155 @@ func (s *PatientService) GetByIDAdmin(patientID int64) (*model.PatientVO, error)
 	}
 	return patient.ToVO(), nil
 }
+
+// Verify 重新实名核验就诊人（用于历史数据补核验或核验服务恢复后重试）
+// 核验结果（含不一致）会记录到就诊人上
+func (s *PatientService) Verify(userID, patientID int64) (*model.PatientVO, error) {
+	patient, role, err := loadPatientForRole(s.repo, userID, patientID, model.PatientRoleManager)
+	if err != nil {
+		return nil, err
+	}
+
+	applyIdentityVerification(patient)
+	if err := s.repo.Update(patient); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return patientVOForRole(patient, role), nil
+}
+
+// checkSelfPatient 校验本人就诊人与账号身份一致：
+// 每个账号仅一位本人就诊人，已绑定手机号的账号须使用该手机号，且证件未被其他账号实名登记为本人。
+// 姓名、证件号码与账号手机号的三要素核验由 requireSelfVerified 在实名核验后检查
+func (s *PatientService) checkSelfPatient(userID, patientID int64, idCard, phone string) error {
+	self, err := s.repo.GetSelfByUser(userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if err == nil && self.ID != patientID {
+		return errorcode.New(errorcode.ErrPatientSelfExists)
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errorcode.New(errorcode.ErrUserNotFound)
+		}
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if user.Phone != "" && phone != user.Phone {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "本人就诊人的手机号需与账号绑定的手机号一致")
+	}
+
+	taken, err := s.repo.ExistsSelfByIDCard(idCard, userID)
+	if err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if taken {
+		return errorcode.NewWithMessage(errorcode.ErrIDCardExists, "该证件已被其他账号登记为本人，如有疑问请联系客服")
+	}
+	return nil
+}
+
+// patientBirthDate 就诊人当前的出生日期（YYYY-MM-DD，无法确定时为空）
+func patientBirthDate(patient *model.Patient) string {
+	if birth, ok := patient.BirthTime(); ok {
+		return utils.FormatDate(birth)
+	}
+	return ""
+}
+
+// requireSelfVerified 已启用实名核验服务时，本人就诊人须通过三要素核验（姓名、证件号码与手机号一致），
+// 核验服务暂不可用时不能登记本人，避免冒用他人证件；未启用核验服务时不做要求
+func requireSelfVerified(patient *model.Patient) error {
+	if !identity.Enabled() {
+		return nil
+	}
+	if patient.Relation == model.RelationSelf && patient.VerifyStatus != model.PatientVerifyPassed {
+		return errorcode.New(errorcode.ErrPatientSelfUnverified)
+	}
+	return nil
+}
+
+// resolvePatientIdentity 校验证件号码并确定性别、出生日期
+// 居民身份证以号码解析结果为准；其他证件使用请求中填写的性别和出生日期
+func resolvePatientIdentity(docType, number string, gender int, birthDate string) (*patientIdentity, error) {
+	if docType == "" {
+		docType = identity.DocTypeIDCard
+	}
+
+	number, err := identity.ValidateDocument(docType, number)
+	if err != nil {
+		return nil, errorcode.NewWithMessage(errorcode.ErrIDDocumentInvalid, err.Error())
+	}
+
+	if docType == identity.DocTypeIDCard {
+		info, err := identity.ParseIDCard(number)
+		if err != nil {
+			return nil, errorcode.NewWithMessage(errorcode.ErrIDDocumentInvalid, err.Error())
+		}
+		return &patientIdentity{
+			DocType:   docType,
+			Number:    number,
+			Gender:    info.Gender,
+			BirthDate: info.BirthDateString(),
+		}, nil
+	}
+
+	if gender != model.GenderMale && gender != model.GenderFemale {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "请选择性别")
+	}
+	if birthDate == "" {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "请填写出生日期")
+	}
+	birth, err := utils.ParseDate(birthDate)
+	if err != nil {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "出生日期格式错误")
+	}
+	if birth.After(time.Now()) || birth.Year() < 1900 {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "出生日期无效")
+	}
+	return &patientIdentity{
+		DocType:   docType,
+		Number:    number,
+		Gender:    gender,
+		BirthDate: utils.FormatDate(birth),
+	}, nil
+}
+
+// applyIdentityVerification 调用实名核验服务并更新就诊人核验状态
+// 未启用核验服务或服务异常时标记为未核验，是否阻断由调用方决定（本人就诊人见 requireSelfVerified）
+func applyIdentityVerification(patient *model.Patient) {
+	req := &identity.VerifyRequest{
+		Name:    patient.Name,
+		DocType: patient.DocTypeOrDefault(),
+		Number:  patient.IDCard,
+	}
+	// 本人就诊人同时核验手机号（已校验为账号绑定的手机号），将账号与证件持有人绑定
+	if patient.Relation == model.RelationSelf {
+		req.Phone = patient.Phone
+	}
+	result, err := identity.Verify(req)
+	if err != nil {
+		patient.VerifyStatus = model.PatientVerifyUnverified
+		patient.VerifiedAt = nil
+		patient.VerifyRemark = ""
+		if !errors.Is(err, identity.ErrProviderDisabled) {
+			logger.Warn("实名核验服务调用失败", zap.Int64("patient_id", patient.ID), zap.Error(err))
+			patient.VerifyRemark = "核验服务暂不可用"
+		}
+		return
+	}
+
+	now := time.Now()
+	patient.VerifiedAt = &now
+	if result.Matched {
+		patient.VerifyStatus = model.PatientVerifyPassed
+		patient.VerifyRemark = ""
+		return
+	}
+	patient.VerifyStatus = model.PatientVerifyFailed
+	patient.VerifyRemark = result.Reason
+	if patient.VerifyRemark == "" {
+		patient.VerifyRemark = errorcode.GetMessage(errorcode.ErrIdentityMismatch)
+	}
+}
@@ -0,0 +1,372 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/internal/repository"
+	"huaan-medical/pkg/config"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/utils"
+)
+
+// 就诊人共享默认规则
+const (
+	defaultInvitationExpireHours = 72
+	defaultPatientMaxMembers     = 5
+	patientInvitationCodeLength  = 16
+)
+
+// PatientShareService 就诊人共享服务（邀请、接受、成员管理）
+type PatientShareService struct {
+	patientRepo  *repository.PatientRepository
+	memberRepo   *repository.PatientMemberRepository
+	userRepo     *repository.UserRepository
+	notification *NotificationService
+}
+
+// NewPatientShareService 创建就诊人共享服务实例
+func NewPatientShareService() *PatientShareService {
+	return &PatientShareService{
+		patientRepo:  repository.NewPatientRepository(),
+		memberRepo:   repository.NewPatientMemberRepository(),
+		userRepo:     repository.NewUserRepository(),
+		notification: NewNotificationService(),
+	}
+}
+
+// CreatePatientInvitationRequest 创建共享邀请请求
+type CreatePatientInvitationRequest struct {
+	Role  string `json:"role" binding:"required,oneof=manager viewer"`
+	Phone string `json:"phone" binding:"omitempty,len=11"` // 被邀请人手机号，为空表示任何持有邀请码的用户均可接受
+}
+
+// AcceptPatientInvitationRequest 接受共享邀请请求
+type AcceptPatientInvitationRequest struct {
+	Code string `json:"code" binding:"required,max=32"`
+}
+
+// UpdatePatientMemberRequest 修改共享成员角色请求
+type UpdatePatientMemberRequest struct {
+	Role string `json:"role" binding:"required,oneof=manager viewer"`
+}
+
+// ListMembers 查询就诊人的所有者及共享成员
+func (s *PatientShareService) ListMembers(userID, patientID int64) ([]model.PatientMemberVO, error) {
+	patient, _, err := loadPatientForRole(s.patientRepo, userID, patientID, model.PatientRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.memberRepo.ListMembers(patientID)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	list := make([]model.PatientMemberVO, 0, len(members)+1)
+	owner := model.PatientMemberVO{
+		UserID:    patient.UserID,
+		Role:      model.PatientRoleOwner,
+		RoleName:  model.GetPatientRoleName(model.PatientRoleOwner),
+		IsCurrent: patient.UserID == userID,
+	}
+	if user, err := s.userRepo.GetByID(patient.UserID); err == nil {
+		vo := user.ToVO()
+		owner.Nickname = vo.Nickname
+		owner.Phone = vo.Phone
+	}
+	list = append(list, owner)
+	for i := range members {
+		vo := members[i].ToVO()
+		vo.IsCurrent = members[i].UserID == userID
+		list = append(list, *vo)
+	}
+	return list, nil
+}
+
+// CreateInvitation 所有者发起共享邀请
+func (s *PatientShareService) CreateInvitation(userID, patientID int64, req *CreatePatientInvitationRequest) (*model.PatientInvitationVO, error) {
+	patient, _, err := loadPatientForRole(s.patientRepo, userID, patientID, model.PatientRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.memberRepo.CountMembers(patientID)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	if limit := patientMaxMembers(); count >= int64(limit) {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, fmt.Sprintf("每个就诊人最多共享给%d位家人", limit))
+	}
+
+	// 指定手机号时校验不是自己、也不是已有成员
+	var invitee *model.User
+	if req.Phone != "" {
+		if !utils.ValidatePhone(req.Phone) {
+			return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "手机号格式错误")
+		}
+		user, err := s.userRepo.GetByPhone(req.Phone)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrDatabase)
+		}
+		if user != nil && err == nil {
+			if user.ID == userID {
+				return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "不能邀请自己")
+			}
+			if _, err := s.memberRepo.GetMember(patientID, user.ID); err == nil {
+				return nil, errorcode.NewWithMessage(errorcode.ErrPatientMemberExists, "对方已是该就诊人的共享成员")
+			}
+			invitee = user
+		}
+	}
+
+	invitation := &model.PatientInvitation{
+		PatientID:    patientID,
+		InviterID:    userID,
+		Code:         utils.GenerateRandomString(patientInvitationCodeLength),
+		Role:         req.Role,
+		InviteePhone: req.Phone,
+		Status:       model.PatientInvitationPending,
+		ExpiresAt:    time.Now().Add(time.Duration(invitationExpireHours()) * time.Hour),
+	}
+	if err := s.memberRepo.CreateInvitation(invitation); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	// 被邀请人已注册时发送站内消息
+	if invitee != nil {
+		s.notification.Send([]model.Notification{{
+			UserID:  invitee.ID,
+			Type:    model.NotificationTypePatientInvite,
+			Title:   "就诊人共享邀请",
+			Content: fmt.Sprintf("家人邀请您共同管理就诊人%s（%s），邀请码：%s，有效期至%s", utils.MaskName(patient.Name), model.GetPatientRoleName(req.Role), invitation.Code, invitation.ExpiresAt.Format("2006-01-02 15:04")),
+			BizID:   invitation.ID,
+		}})
+	}
+
+	return invitation.ToVO(time.Now()), nil
+}
+
+// ListInvitations 所有者查询就诊人的共享邀请
+func (s *PatientShareService) ListInvitations(userID, patientID int64) ([]model.PatientInvitationVO, error) {
+	if _, _, err := loadPatientForRole(s.patientRepo, userID, patientID, model.PatientRoleOwner); err != nil {
+		return nil, err
+	}
+
+	list, err := s.memberRepo.ListInvitations(patientID)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	now := time.Now()
+	voList := make([]model.PatientInvitationVO, len(list))
+	for i := range list {
+		voList[i] = *list[i].ToVO(now)
+	}
+	return voList, nil
+}
+
+// RevokeInvitation 所有者撤销待接受的共享邀请
+func (s *PatientShareService) RevokeInvitation(userID, patientID, invitationID int64) error {
+	if _, _, err := loadPatientForRole(s.patientRepo, userID, patientID, model.PatientRoleOwner); err != nil {
+		return err
+	}
+
+	invitation, err := s.memberRepo.GetInvitationByID(invitationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errorcode.New(errorcode.ErrPatientInvitationNotFound)
+		}
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if invitation.PatientID != patientID {
+		return errorcode.New(errorcode.ErrPatientInvitationNotFound)
+	}
+	if invitation.Status != model.PatientInvitationPending {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "只能撤销待接受的邀请")
+	}
+
+	if err := s.memberRepo.RevokeInvitation(invitationID); err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	return nil
+}
+
+// AcceptInvitation 被邀请人凭邀请码加入就诊人共享
+func (s *PatientShareService) AcceptInvitation(userID int64, req *AcceptPatientInvitationRequest) (*model.PatientVO, error) {
+	invitation, err := s.memberRepo.GetInvitationByCode(strings.TrimSpace(req.Code))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrPatientInvitationInvalid)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	now := time.Now()
+	if !invitation.IsUsable(now) || invitation.Patient == nil {
+		return nil, errorcode.New(errorcode.ErrPatientInvitationInvalid)
+	}
+	patient := invitation.Patient
+
+	if patient.UserID == userID {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "不能接受自己发出的邀请")
+	}
+	if _, err := s.memberRepo.GetMember(patient.ID, userID); err == nil {
+		return nil, errorcode.New(errorcode.ErrPatientMemberExists)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	// 指定了手机号的邀请仅限该手机号绑定的账号接受
+	if invitation.InviteePhone != "" {
+		user, err := s.userRepo.GetByID(userID)
+		if err != nil {
+			return nil, errorcode.New(errorcode.ErrDatabase)
+		}
+		if user.Phone != invitation.InviteePhone {
+			return nil, errorcode.NewWithMessage(errorcode.ErrPatientInvitationInvalid, "该邀请仅限指定手机号的账号接受")
+		}
+	}
+
+	count, err := s.memberRepo.CountMembers(patient.ID)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	if limit := patientMaxMembers(); count >= int64(limit) {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, fmt.Sprintf("该就诊人共享成员已达上限（%d位）", limit))
+	}
+
+	member := &model.PatientMember{
+		PatientID: patient.ID,
+		UserID:    userID,
+		Role:      invitation.Role,
+		InvitedBy: invitation.InviterID,
+	}
+	if err := s.memberRepo.AcceptInvitation(invitation, member, now); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrPatientInvitationInvalid)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	s.notification.Send([]model.Notification{{
+		UserID:  patient.UserID,
+		Type:    model.NotificationTypePatientShared,
+		Title:   "就诊人共享成功",
+		Content: fmt.Sprintf("您共享的就诊人%s已有家人接受邀请，角色：%s", utils.MaskName(patient.Name), model.GetPatientRoleName(member.Role)),
+		BizID:   patient.ID,
+	}})
+
+	return patientVOForRole(patient, member.Role), nil
+}
+
+// UpdateMemberRole 所有者修改共享成员角色
+func (s *PatientShareService) UpdateMemberRole(userID, patientID, memberUserID int64, req *UpdatePatientMemberRequest) error {
+	if _, _, err := loadPatientForRole(s.patientRepo, userID, patientID, model.PatientRoleOwner); err != nil {
+		return err
+	}
+	if _, err := s.memberRepo.GetMember(patientID, memberUserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errorcode.NewWithMessage(errorcode.ErrNotFound, "共享成员不存在")
+		}
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+
+	if err := s.memberRepo.UpdateRole(patientID, memberUserID, req.Role); err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	return nil
+}
+
+// RemoveMember 移除共享成员：所有者可移除任意成员，成员可退出共享
+func (s *PatientShareService) RemoveMember(userID, patientID, memberUserID int64) error {
+	minRole := model.PatientRoleOwner
+	if memberUserID == userID {
+		minRole = model.PatientRoleViewer
+	}
+	patient, role, err := loadPatientForRole(s.patientRepo, userID, patientID, minRole)
+	if err != nil {
+		return err
+	}
+	if memberUserID == patient.UserID {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "所有者不能退出共享，如不再需要请删除就诊人")
+	}
+	if _, err := s.memberRepo.GetMember(patientID, memberUserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errorcode.NewWithMessage(errorcode.ErrNotFound, "共享成员不存在")
+		}
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+
+	if err := s.memberRepo.DeleteMember(patientID, memberUserID); err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+
+	// 通知另一方
+	notice := model.Notification{
+		Type:  model.NotificationTypePatientShared,
+		BizID: patientID,
+	}
+	if role == model.PatientRoleOwner {
+		notice.UserID = memberUserID
+		notice.Title = "就诊人共享已解除"
+		notice.Content = fmt.Sprintf("就诊人%s的所有者已取消与您的共享", utils.MaskName(patient.Name))
+	} else {
+		notice.UserID = patient.UserID
+		notice.Title = "家人已退出就诊人共享"
+		notice.Content = fmt.Sprintf("一位家人已退出就诊人%s的共享", utils.MaskName(patient.Name))
+	}
+	s.notification.Send([]model.Notification{notice})
+	return nil
+}
+
+// loadPatientForRole 查询用户可访问的就诊人，并校验用户角色不低于 minRole
+func loadPatientForRole(repo *repository.PatientRepository, userID, patientID int64, minRole string) (*model.Patient, string, error) {
+	patient, role, err := repo.GetWithRole(userID, patientID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", errorcode.New(errorcode.ErrPatientNotFound)
+		}
+		return nil, "", errorcode.New(errorcode.ErrDatabase)
+	}
+	if model.PatientRoleLevel(role) < model.PatientRoleLevel(minRole) {
+		return nil, "", errorcode.NewWithMessage(errorcode.ErrPermissionDenied,
+			fmt.Sprintf("您是该就诊人的%s，无权执行此操作", model.GetPatientRoleName(role)))
+	}
+	return patient, role, nil
+}
+
+// patientVOForRole 按共享角色生成就诊人视图：所有者/管理者返回完整信息，查看者返回脱敏信息
+func patientVOForRole(patient *model.Patient, role string) *model.PatientVO {
+	var vo *model.PatientVO
+	if model.PatientRoleLevel(role) >= model.PatientRoleLevel(model.PatientRoleManager) {
+		vo = patient.ToFullVO()
+	} else {
+		vo = patient.ToVO()
+	}
+	if role != model.PatientRoleOwner {
+		vo.IsDefault = 0
+	}
+	vo.Role = role
+	vo.RoleName = model.GetPatientRoleName(role)
+	return vo
+}
+
+// invitationExpireHours 共享邀请有效期（小时）
+func invitationExpireHours() int {
+	if cfg := config.Get(); cfg != nil && cfg.Business.Patient.InvitationExpireHours > 0 {
+		return cfg.Business.Patient.InvitationExpireHours
+	}
+	return defaultInvitationExpireHours
+}
+
+// patientMaxMembers 每个就诊人最多共享成员数
+func patientMaxMembers() int {
+	if cfg := config.Get(); cfg != nil && cfg.Business.Patient.MaxMembers > 0 {
+		return cfg.Business.Patient.MaxMembers
+	}
+	return defaultPatientMaxMembers
+}
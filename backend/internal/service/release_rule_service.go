@@ -0,0 +1,286 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/internal/repository"
+	"huaan-medical/pkg/errorcode"
+)
+
+// ReleaseRuleService 放号规则服务
+type ReleaseRuleService struct {
+	repo       *repository.ReleaseRuleRepository
+	deptRepo   *repository.DepartmentRepository
+	doctorRepo *repository.DoctorRepository
+}
+
+// NewReleaseRuleService 创建放号规则服务实例
+func NewReleaseRuleService() *ReleaseRuleService {
+	return &ReleaseRuleService{
+		repo:       repository.NewReleaseRuleRepository(),
+		deptRepo:   repository.NewDepartmentRepository(),
+		doctorRepo: repository.NewDoctorRepository(),
+	}
+}
+
+// ReleaseStageRequest 放号阶段
+type ReleaseStageRequest struct {
+	DaysBefore  int    `json:"days_before" binding:"min=0,max=90"`       // 就诊日前N天
+	ReleaseTime string `json:"release_time" binding:"required"`          // HH:mm
+	Percent     int    `json:"percent" binding:"required,min=1,max=100"` // 本阶段放号比例
+}
+
+// SaveReleaseRuleRequest 创建/更新放号规则请求
+type SaveReleaseRuleRequest struct {
+	Name      string                `json:"name" binding:"required,min=2,max=64"`
+	ScopeType string                `json:"scope_type" binding:"required,oneof=global department doctor"`
+	ScopeID   int64                 `json:"scope_id"`
+	Status    *int                  `json:"status" binding:"required,oneof=0 1"`
+	Stages    []ReleaseStageRequest `json:"stages" binding:"required,min=1,max=10,dive"`
+}
+
+// ListReleaseRuleRequest 列表查询请求
+type ListReleaseRuleRequest struct {
+	Page      int    `form:"page" binding:"required,min=1"`
+	PageSize  int    `form:"page_size" binding:"required,min=1,max=100"`
+	ScopeType string `form:"scope_type"`
+}
+
+// Create 创建放号规则
+func (s *ReleaseRuleService) Create(req *SaveReleaseRuleRequest) (*model.ReleaseRuleVO, error) {
+	if err := s.validate(req, 0); err != nil {
+		return nil, err
+	}
+
+	rule := &model.ReleaseRule{
+		Name:      req.Name,
+		ScopeType: req.ScopeType,
+		ScopeID:   req.ScopeID,
+		Status:    *req.Status,
+		Stages:    buildReleaseStages(req.Stages),
+	}
+	if rule.ScopeType == model.ReleaseScopeGlobal {
+		rule.ScopeID = 0
+	}
+
+	if err := s.repo.Create(rule); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	return rule.ToVO(), nil
+}
+
+// Update 更新放号规则
+// 仅影响之后创建或之后到达放号时间的排班，已放出的号源不会回收
+func (s *ReleaseRuleService) Update(id int64, req *SaveReleaseRuleRequest) (*model.ReleaseRuleVO, error) {
+	rule, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.NewWithMessage(errorcode.ErrNotFound, "放号规则不存在")
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	if err := s.validate(req, id); err != nil {
+		return nil, err
+	}
+
+	rule.Name = req.Name
+	rule.ScopeType = req.ScopeType
+	rule.ScopeID = req.ScopeID
+	if rule.ScopeType == model.ReleaseScopeGlobal {
+		rule.ScopeID = 0
+	}
+	rule.Status = *req.Status
+	rule.Stages = buildReleaseStages(req.Stages)
+
+	if err := s.repo.Update(rule); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	rule, err = s.repo.GetByID(id)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	return rule.ToVO(), nil
+}
+
+// Delete 删除放号规则
+func (s *ReleaseRuleService) Delete(id int64) error {
+	if _, err := s.repo.GetByID(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errorcode.NewWithMessage(errorcode.ErrNotFound, "放号规则不存在")
+		}
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+
+	if err := s.repo.Delete(id); err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	return nil
+}
+
+// List 分页查询放号规则
+func (s *ReleaseRuleService) List(req *ListReleaseRuleRequest) ([]model.ReleaseRuleVO, int64, error) {
+	rules, total, err := s.repo.List(req.Page, req.PageSize, req.ScopeType)
+	if err != nil {
+		return nil, 0, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	voList := make([]model.ReleaseRuleVO, len(rules))
+	for i, rule := range rules {
+		voList[i] = *rule.ToVO()
+	}
+
+	return voList, total, nil
+}
+
+// validate 校验规则参数
+func (s *ReleaseRuleService) validate(req *SaveReleaseRuleRequest, excludeID int64) error {
+	// 校验作用对象
+	switch req.ScopeType {
+	case model.ReleaseScopeDepartment:
+		if _, err := s.deptRepo.GetByID(req.ScopeID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errorcode.New(errorcode.ErrDepartmentNotFound)
+			}
+			return errorcode.New(errorcode.ErrDatabase)
+		}
+	case model.ReleaseScopeDoctor:
+		if _, err := s.doctorRepo.GetByIDSimple(req.ScopeID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errorcode.New(errorcode.ErrDoctorNotFound)
+			}
+			return errorcode.New(errorcode.ErrDatabase)
+		}
+	default:
+		req.ScopeID = 0
+	}
+
+	// 同一范围只能有一条规则
+	exists, err := s.repo.ExistsByScope(req.ScopeType, req.ScopeID, excludeID)
+	if err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if exists {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该范围已存在放号规则")
+	}
+
+	// 校验阶段
+	totalPercent := 0
+	seen := make(map[int]bool)
+	for _, stage := range req.Stages {
+		if _, err := time.Parse("15:04", stage.ReleaseTime); err != nil {
+			return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "放号时间格式错误，应为HH:mm")
+		}
+		offset := stageOffsetMinutes(stage.DaysBefore, stage.ReleaseTime)
+		if seen[offset] {
+			return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "放号阶段时间重复")
+		}
+		seen[offset] = true
+		totalPercent += stage.Percent
+	}
+	if totalPercent != 100 {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "各阶段放号比例之和必须为100%")
+	}
+
+	return nil
+}
+
+// buildReleaseStages 构建放号阶段模型
+func buildReleaseStages(reqs []ReleaseStageRequest) []model.ReleaseRuleStage {
+	stages := make([]model.ReleaseRuleStage, len(reqs))
+	for i, st := range reqs {
+		stages[i] = model.ReleaseRuleStage{
+			DaysBefore:  st.DaysBefore,
+			ReleaseTime: st.ReleaseTime,
+			Percent:     st.Percent,
+		}
+	}
+	return stages
+}
+
+// stageOffsetMinutes 阶段相对就诊日零点的偏移（分钟，越大越早），用于去重
+func stageOffsetMinutes(daysBefore int, releaseTime string) int {
+	t, _ := time.Parse("15:04", releaseTime)
+	return daysBefore*24*60 - (t.Hour()*60 + t.Minute())
+}
+
+// releasePlan 某个排班在指定时刻的放号进度
+type releasePlan struct {
+	Released      int        // 截至当前应放出的号源总数（含已预约）
+	NextReleaseAt *time.Time // 下一次放号时间，nil 表示已全部放出
+}
+
+// calcReleasePlan 根据规则计算排班在 now 时刻的放号进度
+// rule 为 nil 时表示不限制，全部放出
+func calcReleasePlan(rule *model.ReleaseRule, scheduleDate time.Time, totalSlots int, now time.Time) releasePlan {
+	if rule == nil || len(rule.Stages) == 0 {
+		return releasePlan{Released: totalSlots}
+	}
+
+	type point struct {
+		at      time.Time
+		percent int
+	}
+	points := make([]point, 0, len(rule.Stages))
+	for _, st := range rule.Stages {
+		t, err := time.Parse("15:04", st.ReleaseTime)
+		if err != nil {
+			continue
+		}
+		day := scheduleDate.AddDate(0, 0, -st.DaysBefore)
+		at := time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, scheduleDate.Location())
+		points = append(points, point{at: at, percent: st.Percent})
+	}
+	if len(points) == 0 {
+		return releasePlan{Released: totalSlots}
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].at.Before(points[j].at) })
+
+	plan := releasePlan{}
+	allocated := 0
+	for i, p := range points {
+		slots := totalSlots * p.percent / 100
+		// 最后一个阶段放出剩余全部号源，避免取整误差
+		if i == len(points)-1 {
+			slots = totalSlots - allocated
+		}
+		allocated += slots
+
+		if p.at.After(now) {
+			at := p.at
+			plan.NextReleaseAt = &at
+			break
+		}
+		plan.Released += slots
+	}
+
+	return plan
+}
+
+// applyReleasePlan 按放号进度重新分配排班公共号源池的剩余号源与未放出号源
+// bookedSlots 为公共号源池已预约数，已预约的号源一定是已放出的
+func applyReleasePlan(schedule *model.Schedule, plan releasePlan, bookedSlots int) {
+	publicSlots := schedule.PublicSlots()
+	released := plan.Released
+	if released < bookedSlots {
+		released = bookedSlots
+	}
+	if released > publicSlots {
+		released = publicSlots
+	}
+
+	schedule.UnreleasedSlots = publicSlots - released
+	schedule.AvailableSlots = released - bookedSlots
+	schedule.NextReleaseAt = plan.NextReleaseAt
+	if schedule.UnreleasedSlots == 0 {
+		schedule.NextReleaseAt = nil
+	}
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"huaan-medical/internal/model"
+)
+
+func TestCalcReleasePlan(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	scheduleDate := time.Date(2026, 3, 10, 0, 0, 0, 0, loc)
+	firstAt := time.Date(2026, 3, 3, 8, 0, 0, 0, loc)
+	secondAt := time.Date(2026, 3, 9, 8, 0, 0, 0, loc)
+
+	twoStages := &model.ReleaseRule{Stages: []model.ReleaseRuleStage{
+		// 故意倒序，验证按放号时间排序
+		{DaysBefore: 1, ReleaseTime: "08:00", Percent: 50},
+		{DaysBefore: 7, ReleaseTime: "08:00", Percent: 50},
+	}}
+
+	tests := []struct {
+		name         string
+		rule         *model.ReleaseRule
+		totalSlots   int
+		now          time.Time
+		wantReleased int
+		wantNext     *time.Time
+	}{
+		{"无规则全部放出", nil, 20, firstAt, 20, nil},
+		{"规则无阶段全部放出", &model.ReleaseRule{}, 20, firstAt, 20, nil},
+		{"首阶段之前", twoStages, 20, firstAt.Add(-time.Minute), 0, &firstAt},
+		{"首阶段放号时刻", twoStages, 20, firstAt, 10, &secondAt},
+		{"两阶段之间", twoStages, 20, secondAt.Add(-time.Minute), 10, &secondAt},
+		{"全部阶段之后", twoStages, 20, secondAt, 20, nil},
+		{"取整余数归入最后阶段", twoStages, 7, firstAt, 3, &secondAt},
+		{"取整余数全部放出", twoStages, 7, secondAt, 7, nil},
+		{
+			"无效时间的阶段被忽略",
+			&model.ReleaseRule{Stages: []model.ReleaseRuleStage{
+				{DaysBefore: 7, ReleaseTime: "25:00", Percent: 50},
+				{DaysBefore: 1, ReleaseTime: "08:00", Percent: 50},
+			}},
+			20, secondAt.Add(-time.Minute), 0, &secondAt,
+		},
+		{
+			"阶段时间均无效时全部放出",
+			&model.ReleaseRule{Stages: []model.ReleaseRuleStage{{DaysBefore: 1, ReleaseTime: "bad", Percent: 50}}},
+			20, firstAt, 20, nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := calcReleasePlan(tt.rule, scheduleDate, tt.totalSlots, tt.now)
+			if plan.Released != tt.wantReleased {
+				t.Errorf("Released = %d, want %d", plan.Released, tt.wantReleased)
+			}
+			switch {
+			case tt.wantNext == nil && plan.NextReleaseAt != nil:
+				t.Errorf("NextReleaseAt = %v, want nil", *plan.NextReleaseAt)
+			case tt.wantNext != nil && (plan.NextReleaseAt == nil || !plan.NextReleaseAt.Equal(*tt.wantNext)):
+				t.Errorf("NextReleaseAt = %v, want %v", plan.NextReleaseAt, *tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestApplyReleasePlan(t *testing.T) {
+	next := time.Date(2026, 3, 9, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		plan            releasePlan
+		bookedSlots     int
+		wantAvailable   int
+		wantUnreleased  int
+		wantNextCleared bool
+	}{
+		{"部分放出", releasePlan{Released: 6, NextReleaseAt: &next}, 2, 4, 9, false},
+		{"未放号", releasePlan{Released: 0, NextReleaseAt: &next}, 0, 0, 15, false},
+		{"已预约数超过应放数按已预约计", releasePlan{Released: 3, NextReleaseAt: &next}, 5, 0, 10, false},
+		// 放号进度按总号源计算，需限制在公共号源池内
+		{"应放数超过公共号源池时截断", releasePlan{Released: 20}, 4, 11, 0, true},
+		{"全部放出后清除下次放号时间", releasePlan{Released: 15, NextReleaseAt: &next}, 0, 15, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 公共号源池 = 20 - 3 - 2 = 15
+			schedule := &model.Schedule{TotalSlots: 20, OnsiteSlots: 3, VipSlots: 2}
+			applyReleasePlan(schedule, tt.plan, tt.bookedSlots)
+			if schedule.AvailableSlots != tt.wantAvailable {
+				t.Errorf("AvailableSlots = %d, want %d", schedule.AvailableSlots, tt.wantAvailable)
+			}
+			if schedule.UnreleasedSlots != tt.wantUnreleased {
+				t.Errorf("UnreleasedSlots = %d, want %d", schedule.UnreleasedSlots, tt.wantUnreleased)
+			}
+			if tt.wantNextCleared != (schedule.NextReleaseAt == nil) {
+				t.Errorf("NextReleaseAt = %v, want cleared %v", schedule.NextReleaseAt, tt.wantNextCleared)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"huaan-medical/internal/model"
+	"huaan-medical/pkg/config"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/utils"
+)
+
+// 复制排班的处理结果
+const (
+	ScheduleCopyActionCreate   = "create"   // 将创建
+	ScheduleCopyActionSkip     = "skip"     // 跳过
+	ScheduleCopyActionConflict = "conflict" // 冲突
+)
+
+// CopyScheduleRequest 复制排班请求
+type CopyScheduleRequest struct {
+	ScopeType       string `json:"scope_type" binding:"required,oneof=global department doctor"` // 复制范围
+	ScopeID         int64  `json:"scope_id"`                                                     // 科室ID/医生ID，全院为0
+	SourceStartDate string `json:"source_start_date" binding:"required"`                         // 源开始日期 YYYY-MM-DD
+	SourceEndDate   string `json:"source_end_date" binding:"required"`                           // 源结束日期 YYYY-MM-DD
+	TargetStartDate string `json:"target_start_date" binding:"required"`                         // 目标开始日期 YYYY-MM-DD，按相同天数平移
+	SkipExisting    bool   `json:"skip_existing"`                                                // 目标已存在时跳过；为 false 时视为冲突并不提交
+	SkipClosures    bool   `json:"skip_closures"`                                                // 跳过停诊的源排班；为 false 时按停诊状态复制
+	KeepCapacity    bool   `json:"keep_capacity"`                                                // 保留号源数与渠道预留；为 false 时按系统默认号源数重置
+	Preview         bool   `json:"preview"`                                                      // 仅预览，不提交
+}
+
+// ScheduleCopyItem 复制排班明细
+type ScheduleCopyItem struct {
+	SourceID     int64  `json:"source_id"`
+	DoctorID     int64  `json:"doctor_id"`
+	DoctorName   string `json:"doctor_name"`
+	SourceDate   string `json:"source_date"`
+	TargetDate   string `json:"target_date"`
+	Period       string `json:"period"`
+	PeriodName   string `json:"period_name"`
+	StartTime    string `json:"start_time"`
+	EndTime      string `json:"end_time"`
+	TotalSlots   int    `json:"total_slots"` // 目标号源数
+	Status       int    `json:"status"`      // 目标状态
+	Action       string `json:"action"`      // create/skip/conflict
+	Reason       string `json:"reason,omitempty"`
+	TargetExists bool   `json:"target_exists"` // 目标时段是否已有排班
+}
+
+// ScheduleCopyResult 复制排班结果
+type ScheduleCopyResult struct {
+	Preview       bool               `json:"preview"`
+	Committed     bool               `json:"committed"`
+	CreatedCount  int                `json:"created_count"`
+	SkippedCount  int                `json:"skipped_count"`
+	ConflictCount int                `json:"conflict_count"`
+	Items         []ScheduleCopyItem `json:"items"`
+}
+
+// Copy 将指定范围内的排班复制到目标日期（按相同天数平移）
+// 预览模式返回逐条对比结果；提交模式在存在冲突时不写入任何数据，否则在同一事务内写入
+func (s *ScheduleService) Copy(req *CopyScheduleRequest) (*ScheduleCopyResult, error) {
+	sourceStart, err := utils.ParseDate(req.SourceStartDate)
+	if err != nil {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "源开始日期格式错误")
+	}
+	sourceEnd, err := utils.ParseDate(req.SourceEndDate)
+	if err != nil {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "源结束日期格式错误")
+	}
+	targetStart, err := utils.ParseDate(req.TargetStartDate)
+	if err != nil {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "目标开始日期格式错误")
+	}
+
+	if sourceEnd.Before(sourceStart) {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "源结束日期不能早于源开始日期")
+	}
+	if sourceEnd.Sub(sourceStart).Hours() > 31*24 {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "源日期跨度不能超过31天")
+	}
+	if targetStart.Before(utils.GetTodayStart()) {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "目标开始日期不能早于今天")
+	}
+
+	// 目标与源日期范围不能重叠，避免复制结果再次成为源
+	offsetDays := int(targetStart.Sub(sourceStart).Hours() / 24)
+	targetEnd := sourceEnd.AddDate(0, 0, offsetDays)
+	if !targetStart.After(sourceEnd) && !targetEnd.Before(sourceStart) {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "目标日期范围不能与源日期范围重叠")
+	}
+
+	// 解析复制范围
+	var doctorID, departmentID *int64
+	switch req.ScopeType {
+	case "doctor":
+		doctorID = &req.ScopeID
+	case "department":
+		departmentID = &req.ScopeID
+	}
+	if req.ScopeType != "global" && req.ScopeID <= 0 {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "请选择要复制的科室或医生")
+	}
+
+	sources, err := s.repo.ListByRange(doctorID, departmentID, sourceStart, sourceEnd)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	if len(sources) == 0 {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "源日期范围内没有排班")
+	}
+
+	result := &ScheduleCopyResult{
+		Preview: req.Preview,
+		Items:   make([]ScheduleCopyItem, 0, len(sources)),
+	}
+
+	var schedules []model.Schedule
+	doctorIDs := make(map[int64]struct{})
+	for _, src := range sources {
+		targetDate := src.ScheduleDate.AddDate(0, 0, offsetDays)
+		item := ScheduleCopyItem{
+			SourceID:   src.ID,
+			DoctorID:   src.DoctorID,
+			SourceDate: utils.FormatDate(src.ScheduleDate),
+			TargetDate: utils.FormatDate(targetDate),
+			Period:     src.Period,
+			PeriodName: model.GetPeriodName(src.Period),
+			StartTime:  src.StartTime,
+			EndTime:    src.EndTime,
+			Status:     src.Status,
+		}
+		if src.Doctor != nil {
+			item.DoctorName = src.Doctor.Name
+		}
+
+		createReq := &CreateScheduleRequest{
+			DoctorID:     src.DoctorID,
+			DepartmentID: src.SessionDepartmentID(),
+			RoomID:       src.RoomID,
+			ScheduleDate: item.TargetDate,
+			Period:       src.Period,
+			StartTime:    src.StartTime,
+			EndTime:      src.EndTime,
+			Status:       src.Status,
+		}
+		if req.KeepCapacity {
+			createReq.TotalSlots = src.TotalSlots
+			createReq.OnsiteSlots = src.OnsiteSlots
+			createReq.VipSlots = src.VipSlots
+			// 预留号源已转入公共池的源排班，预留数已扣减为已使用数，无法还原原始预留，目标排班不设预留
+			if src.QuotaRolledOver {
+				createReq.OnsiteSlots, createReq.VipSlots = 0, 0
+			}
+		} else {
+			createReq.TotalSlots = defaultPeriodSlots(src.Period, src.TotalSlots)
+		}
+		item.TotalSlots = createReq.TotalSlots
+
+		s.classifyCopyItem(&item, src, createReq, req, &schedules)
+		switch item.Action {
+		case ScheduleCopyActionCreate:
+			result.CreatedCount++
+			doctorIDs[src.DoctorID] = struct{}{}
+		case ScheduleCopyActionSkip:
+			result.SkippedCount++
+		case ScheduleCopyActionConflict:
+			result.ConflictCount++
+		}
+		result.Items = append(result.Items, item)
+	}
+
+	if req.Preview || result.ConflictCount > 0 || len(schedules) == 0 {
+		return result, nil
+	}
+
+	// 同一事务内写入全部排班
+	if err := s.repo.BatchCreate(schedules); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	for id := range doctorIDs {
+		s.InvalidateDoctorScheduleCache(id)
+	}
+
+	result.Committed = true
+	return result, nil
+}
+
+// classifyCopyItem 判断单条排班的复制结果，可创建时追加到 schedules
+func (s *ScheduleService) classifyCopyItem(item *ScheduleCopyItem, src model.Schedule, createReq *CreateScheduleRequest, req *CopyScheduleRequest, schedules *[]model.Schedule) {
+	// 停诊排班
+	if src.Status == model.StatusDisabled && req.SkipClosures {
+		item.Action = ScheduleCopyActionSkip
+		item.Reason = "源排班为停诊"
+		return
+	}
+
+	// 目标已存在
+	targetDate, _ := utils.ParseDate(item.TargetDate)
+	exists, err := s.repo.Exists(src.DoctorID, targetDate, src.Period)
+	if err != nil {
+		item.Action = ScheduleCopyActionConflict
+		item.Reason = errorcode.GetMessage(errorcode.ErrDatabase)
+		return
+	}
+	if exists {
+		item.TargetExists = true
+		if req.SkipExisting {
+			item.Action = ScheduleCopyActionSkip
+			item.Reason = "目标时段已有排班"
+		} else {
+			item.Action = ScheduleCopyActionConflict
+			item.Reason = "目标时段已有排班"
+		}
+		return
+	}
+
+	// 按创建排班的规则校验（医生停诊、号源数等）
+	schedule, err := s.buildSchedule(createReq)
+	if err != nil {
+		item.Action = ScheduleCopyActionConflict
+		item.Reason = importErrorMessage(err)
+		return
+	}
+
+	item.Action = ScheduleCopyActionCreate
+	*schedules = append(*schedules, *schedule)
+}
+
+// defaultPeriodSlots 获取时段默认号源数，未配置时沿用原号源数
+func defaultPeriodSlots(period string, fallback int) int {
+	cfg := config.Get()
+	if cfg == nil {
+		return fallback
+	}
+
+	slots := cfg.Business.Schedule.MorningSlots
+	if period == model.PeriodAfternoon {
+		slots = cfg.Business.Schedule.AfternoonSlots
+	}
+	if slots <= 0 {
+		return fallback
+	}
+	return slots
+}
@@ -0,0 +1,386 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/xlsx"
+)
+
+// scheduleImportMaxRows 单次导入最大行数
+const scheduleImportMaxRows = 1000
+
+// scheduleImportHeader 导入模板表头（列顺序固定）
+var scheduleImportHeader = []string{"医生ID", "医生姓名", "排班日期", "时段", "开始时间", "结束时间", "总号源数", "现场预留", "VIP预留", "状态", "出诊科室"}
+
+// ScheduleImportRowError 导入行错误
+type ScheduleImportRowError struct {
+	Row     int    `json:"row"` // Excel 行号（含表头，从1开始）
+	Message string `json:"message"`
+}
+
+// ScheduleImportResult 排班导入结果
+type ScheduleImportResult struct {
+	DryRun       bool                     `json:"dry_run"`       // 是否为预检
+	Committed    bool                     `json:"committed"`     // 是否已写入
+	TotalRows    int                      `json:"total_rows"`    // 数据行数（不含表头与空行）
+	ValidRows    int                      `json:"valid_rows"`    // 校验通过行数
+	InvalidRows  int                      `json:"invalid_rows"`  // 校验失败行数
+	CreatedCount int                      `json:"created_count"` // 创建的排班数量
+	Errors       []ScheduleImportRowError `json:"errors"`
+}
+
+// Import 导入排班
+// dryRun 为 true 时仅校验并返回行级错误报告；为 false 时在全部行校验通过后于同一事务内写入，
+// 任意一行校验失败则不写入任何数据
+func (s *ScheduleService) Import(fileName string, data []byte, dryRun bool) (*ScheduleImportResult, error) {
+	rows, err := parseScheduleImportFile(fileName, data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ScheduleImportResult{
+		DryRun: dryRun,
+		Errors: []ScheduleImportRowError{},
+	}
+
+	var schedules []model.Schedule
+	seen := make(map[string]int) // 文件内重复检查：医生+日期+时段 -> 行号
+	doctorIDs := make(map[int64]struct{})
+	doctorCache := make(map[string]int64) // 医生姓名 -> 医生ID
+	deptCache := make(map[string]int64)   // 科室名称 -> 科室ID
+
+	// 第一行为表头
+	for i := 1; i < len(rows); i++ {
+		rowNum := i + 1
+		row := rows[i]
+		if isBlankRow(row) {
+			continue
+		}
+
+		result.TotalRows++
+		if result.TotalRows > scheduleImportMaxRows {
+			return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams,
+				fmt.Sprintf("单次最多导入%d行", scheduleImportMaxRows))
+		}
+
+		req, err := s.parseImportRow(row, doctorCache, deptCache)
+		if err == nil {
+			key := fmt.Sprintf("%d|%s|%s", req.DoctorID, req.ScheduleDate, req.Period)
+			if prev, ok := seen[key]; ok {
+				err = errorcode.NewWithMessage(errorcode.ErrInvalidParams, fmt.Sprintf("与第%d行排班重复", prev))
+			} else {
+				seen[key] = rowNum
+			}
+		}
+
+		var schedule *model.Schedule
+		if err == nil {
+			schedule, err = s.buildSchedule(req)
+		}
+
+		if err != nil {
+			result.InvalidRows++
+			result.Errors = append(result.Errors, ScheduleImportRowError{Row: rowNum, Message: importErrorMessage(err)})
+			continue
+		}
+
+		result.ValidRows++
+		schedules = append(schedules, *schedule)
+		doctorIDs[schedule.DoctorID] = struct{}{}
+	}
+
+	if result.TotalRows == 0 {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "文件中没有排班数据")
+	}
+
+	if dryRun || result.InvalidRows > 0 {
+		return result, nil
+	}
+
+	// 同一事务内写入全部排班
+	if err := s.repo.BatchCreate(schedules); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	for doctorID := range doctorIDs {
+		s.InvalidateDoctorScheduleCache(doctorID)
+	}
+
+	result.Committed = true
+	result.CreatedCount = len(schedules)
+	return result, nil
+}
+
+// BuildImportTemplate 生成排班导入模板
+// format 为 xlsx 或 csv，返回文件内容、Content-Type 与文件名
+func (s *ScheduleService) BuildImportTemplate(format string) ([]byte, string, string, error) {
+	example := time.Now().AddDate(0, 0, 7).Format("2006-01-02")
+	rows := [][]string{
+		scheduleImportHeader,
+		{"1", "张三", example, "上午", "08:00", "12:00", "30", "6", "2", "正常", ""},
+		{"", "李四", example, "下午", "14:00", "17:30", "20", "0", "0", "正常", "心血管内科"},
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case "", "xlsx":
+		if err := xlsx.WriteRows(&buf, "排班导入", rows); err != nil {
+			return nil, "", "", errorcode.New(errorcode.ErrInternalServer)
+		}
+		return buf.Bytes(), "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "schedule_import_template.xlsx", nil
+	case "csv":
+		// 写入BOM头（让Excel正确识别UTF-8）
+		buf.Write([]byte{0xEF, 0xBB, 0xBF})
+		w := csv.NewWriter(&buf)
+		if err := w.WriteAll(rows); err != nil {
+			return nil, "", "", errorcode.New(errorcode.ErrInternalServer)
+		}
+		return buf.Bytes(), "text/csv; charset=utf-8", "schedule_import_template.csv", nil
+	default:
+		return nil, "", "", errorcode.NewWithMessage(errorcode.ErrInvalidParams, "模板格式仅支持 xlsx 或 csv")
+	}
+}
+
+// parseImportRow 将一行数据解析为创建排班请求（对应接口参数绑定校验）
+func (s *ScheduleService) parseImportRow(row []string, doctorCache, deptCache map[string]int64) (*CreateScheduleRequest, error) {
+	col := func(i int) string {
+		if i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	req := &CreateScheduleRequest{
+		ScheduleDate: normalizeImportDate(col(2)),
+		StartTime:    normalizeImportTime(col(4)),
+		EndTime:      normalizeImportTime(col(5)),
+		Status:       model.StatusEnabled,
+	}
+
+	// 医生：优先使用ID，其次按姓名匹配
+	doctorID, err := s.resolveImportDoctor(col(0), col(1), doctorCache)
+	if err != nil {
+		return nil, err
+	}
+	req.DoctorID = doctorID
+
+	if req.ScheduleDate == "" {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "排班日期不能为空")
+	}
+
+	// 时段
+	switch strings.ToLower(col(3)) {
+	case model.PeriodMorning, "上午":
+		req.Period = model.PeriodMorning
+	case model.PeriodAfternoon, "下午":
+		req.Period = model.PeriodAfternoon
+	default:
+		return nil, errorcode.New(errorcode.ErrInvalidSchedulePeriod)
+	}
+
+	// 时间
+	for _, t := range []string{req.StartTime, req.EndTime} {
+		if _, err := time.Parse("15:04", t); err != nil {
+			return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "开始/结束时间格式错误，应为HH:mm")
+		}
+	}
+
+	// 号源数
+	if req.TotalSlots, err = parseImportInt(col(6), "总号源数", 1, 999, false); err != nil {
+		return nil, err
+	}
+	if req.OnsiteSlots, err = parseImportInt(col(7), "现场预留", 0, 999, true); err != nil {
+		return nil, err
+	}
+	if req.VipSlots, err = parseImportInt(col(8), "VIP预留", 0, 999, true); err != nil {
+		return nil, err
+	}
+
+	// 状态
+	switch col(9) {
+	case "", "1", "正常":
+		req.Status = model.StatusEnabled
+	case "0", "停诊":
+		req.Status = model.StatusDisabled
+	default:
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "状态只能为正常或停诊")
+	}
+
+	// 出诊科室（可选，为空时取医生主科室）
+	if req.DepartmentID, err = s.resolveImportDepartment(col(10), deptCache); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// resolveImportDepartment 解析导入行中的出诊科室（科室ID或名称）
+func (s *ScheduleService) resolveImportDepartment(value string, deptCache map[string]int64) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	if id, err := strconv.ParseInt(value, 10, 64); err == nil && id > 0 {
+		return id, nil
+	}
+	if id, ok := deptCache[value]; ok {
+		return id, nil
+	}
+
+	dept, err := s.deptRepo.GetByName(value)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, errorcode.NewWithMessage(errorcode.ErrDepartmentNotFound, "科室不存在："+value)
+		}
+		return 0, errorcode.New(errorcode.ErrDatabase)
+	}
+	deptCache[value] = dept.ID
+	return dept.ID, nil
+}
+
+// resolveImportDoctor 解析导入行中的医生
+func (s *ScheduleService) resolveImportDoctor(idStr, name string, doctorCache map[string]int64) (int64, error) {
+	if idStr != "" {
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil || id <= 0 {
+			return 0, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "医生ID格式错误")
+		}
+		return id, nil
+	}
+
+	if name == "" {
+		return 0, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "医生ID和医生姓名不能同时为空")
+	}
+	if id, ok := doctorCache[name]; ok {
+		return id, nil
+	}
+
+	doctors, err := s.doctorRepo.ListByName(name)
+	if err != nil {
+		return 0, errorcode.New(errorcode.ErrDatabase)
+	}
+	switch len(doctors) {
+	case 0:
+		return 0, errorcode.NewWithMessage(errorcode.ErrDoctorNotFound, "医生不存在："+name)
+	case 1:
+		doctorCache[name] = doctors[0].ID
+		return doctors[0].ID, nil
+	default:
+		return 0, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "存在多名医生同名，请填写医生ID："+name)
+	}
+}
+
+// parseScheduleImportFile 按扩展名解析导入文件为文本行
+func parseScheduleImportFile(fileName string, data []byte) ([][]string, error) {
+	var rows [][]string
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".xlsx":
+		var err error
+		rows, err = xlsx.ReadRows(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "Excel文件解析失败，请使用导入模板")
+		}
+	case ".csv":
+		data = bytes.TrimPrefix(data, []byte{0xEF, 0xBB, 0xBF})
+		r := csv.NewReader(bytes.NewReader(data))
+		r.FieldsPerRecord = -1
+		r.TrimLeadingSpace = true
+		var err error
+		rows, err = r.ReadAll()
+		if err != nil {
+			return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "CSV文件解析失败，请使用导入模板")
+		}
+	default:
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "仅支持 .xlsx 或 .csv 文件")
+	}
+
+	if len(rows) == 0 {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "文件内容为空")
+	}
+	return rows, nil
+}
+
+// parseImportInt 解析导入的整数字段
+func parseImportInt(value, field string, min, max int, allowEmpty bool) (int, error) {
+	if value == "" {
+		if allowEmpty {
+			return 0, nil
+		}
+		return 0, errorcode.NewWithMessage(errorcode.ErrInvalidParams, field+"不能为空")
+	}
+
+	// Excel 数字单元格可能带有小数部分，如 "30.0" 或 "30.00"，按数值解析后要求为整数
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || f != math.Trunc(f) || f < float64(min) || f > float64(max) {
+		return 0, errorcode.NewWithMessage(errorcode.ErrInvalidParams,
+			fmt.Sprintf("%s应为%d-%d之间的整数", field, min, max))
+	}
+	return int(f), nil
+}
+
+// excelEpoch Excel 日期序列号的起点
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.Local)
+
+// normalizeImportDate 规范化日期：兼容 2025/01/08、20250108 写法与 Excel 日期序列号
+func normalizeImportDate(value string) string {
+	// 8位纯数字优先按 YYYYMMDD 解析，避免被当作日期序列号
+	if len(value) == 8 {
+		if t, err := time.Parse("20060102", value); err == nil {
+			return t.Format("2006-01-02")
+		}
+	}
+	if serial, err := strconv.ParseFloat(value, 64); err == nil && serial > 0 {
+		return excelEpoch.AddDate(0, 0, int(serial)).Format("2006-01-02")
+	}
+	if t, err := time.Parse("2006/1/2", value); err == nil {
+		return t.Format("2006-01-02")
+	}
+	if t, err := time.Parse("2006-1-2", value); err == nil {
+		return t.Format("2006-01-02")
+	}
+	return value
+}
+
+// normalizeImportTime 规范化时间：兼容 8:00 写法与 Excel 时间小数（如 0.3333 表示 08:00）
+func normalizeImportTime(value string) string {
+	if fraction, err := strconv.ParseFloat(value, 64); err == nil && fraction >= 0 && fraction < 1 {
+		minutes := int(fraction*24*60 + 0.5)
+		return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+	}
+	if t, err := time.Parse("15:04", value); err == nil {
+		return t.Format("15:04")
+	}
+	if t, err := time.Parse("15:04:05", value); err == nil {
+		return t.Format("15:04")
+	}
+	return value
+}
+
+// isBlankRow 判断是否为空行
+func isBlankRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// importErrorMessage 提取导入错误信息
+func importErrorMessage(err error) string {
+	var appErr *errorcode.AppError
+	if errors.As(err, &appErr) {
+		return appErr.Message
+	}
+	return err.Error()
+}
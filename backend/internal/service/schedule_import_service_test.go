@@ -0,0 +1,87 @@
+package service
+
+import "testing"
+
+func TestNormalizeImportDate(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"标准格式", "2025-01-08", "2025-01-08"},
+		{"短横线不补零", "2025-1-8", "2025-01-08"},
+		{"斜杠格式", "2025/01/08", "2025-01-08"},
+		{"斜杠不补零", "2025/1/8", "2025-01-08"},
+		{"YYYYMMDD", "20250108", "2025-01-08"},
+		{"YYYYMMDD闰日", "20240229", "2024-02-29"},
+		{"Excel日期序列号", "45000", "2023-03-15"},
+		{"Excel日期序列号带小数", "45000.75", "2023-03-15"},
+		{"无法识别时原样返回", "下周一", "下周一"},
+		{"零不按序列号解析", "0", "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeImportDate(tt.value); got != tt.want {
+				t.Errorf("normalizeImportDate(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseImportInt(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      string
+		allowEmpty bool
+		want       int
+		wantErr    bool
+	}{
+		{"整数", "30", false, 30, false},
+		{"Excel数字单元格带.0", "30.0", false, 30, false},
+		{"Excel数字单元格带.00", "30.00", false, 30, false},
+		{"下限", "0", false, 0, false},
+		{"上限", "100", false, 100, false},
+		{"非整数拒绝", "30.5", false, 0, true},
+		{"超出上限", "101", false, 0, true},
+		{"低于下限", "-1", false, 0, true},
+		{"非数字", "三十", false, 0, true},
+		{"为空且必填", "", false, 0, true},
+		{"为空且可选", "", true, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseImportInt(tt.value, "号源数", 0, 100, tt.allowEmpty)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseImportInt(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseImportInt(%q) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeImportTime(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"08:00", "08:00"},
+		{"8:00", "08:00"},
+		{"08:00:00", "08:00"},
+		{"0.3333333333", "08:00"},
+		{"0.5", "12:00"},
+		{"0", "00:00"},
+		{"上午", "上午"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := normalizeImportTime(tt.value); got != tt.want {
+				t.Errorf("normalizeImportTime(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
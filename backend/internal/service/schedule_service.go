@@ -1,14 +1,21 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 
 	"huaan-medical/internal/model"
 	"huaan-medical/internal/repository"
+	"huaan-medical/pkg/config"
 	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/logger"
+	"huaan-medical/pkg/redis"
 	"huaan-medical/pkg/utils"
 )
 
@@ -16,6 +23,9 @@ import (
 type ScheduleService struct {
 	repo       *repository.ScheduleRepository
 	doctorRepo *repository.DoctorRepository
+	deptRepo   *repository.DepartmentRepository
+	ruleRepo   *repository.ReleaseRuleRepository
+	roomRepo   *repository.RoomRepository
 }
 
 // NewScheduleService 创建排班服务实例
@@ -23,38 +33,55 @@ func NewScheduleService() *ScheduleService {
 	return &ScheduleService{
 		repo:       repository.NewScheduleRepository(),
 		doctorRepo: repository.NewDoctorRepository(),
+		deptRepo:   repository.NewDepartmentRepository(),
+		ruleRepo:   repository.NewReleaseRuleRepository(),
+		roomRepo:   repository.NewRoomRepository(),
 	}
 }
 
+// doctorScheduleCacheTTL 医生排班缓存有效期
+const doctorScheduleCacheTTL = 5 * time.Minute
+
 // CreateScheduleRequest 创建排班请求
 type CreateScheduleRequest struct {
 	DoctorID     int64  `json:"doctor_id" binding:"required,min=1"`
-	ScheduleDate string `json:"schedule_date" binding:"required"` // YYYY-MM-DD
+	DepartmentID int64  `json:"department_id" binding:"omitempty,min=1"` // 出诊科室，不传则为医生主科室
+	RoomID       int64  `json:"room_id" binding:"omitempty,min=1"`       // 诊室，须与出诊科室属于同一院区
+	ScheduleDate string `json:"schedule_date" binding:"required"`        // YYYY-MM-DD
 	Period       string `json:"period" binding:"required,oneof=morning afternoon"`
 	StartTime    string `json:"start_time" binding:"required"` // HH:mm
 	EndTime      string `json:"end_time" binding:"required"`   // HH:mm
 	TotalSlots   int    `json:"total_slots" binding:"required,min=1,max=999"`
+	OnsiteSlots  int    `json:"onsite_slots" binding:"min=0,max=999"` // 现场预留号源数
+	VipSlots     int    `json:"vip_slots" binding:"min=0,max=999"`    // VIP预留号源数
 	Status       int    `json:"status" binding:"oneof=0 1"`
 }
 
 // UpdateScheduleRequest 更新排班请求
 type UpdateScheduleRequest struct {
-	StartTime  string `json:"start_time" binding:"required"` // HH:mm
-	EndTime    string `json:"end_time" binding:"required"`   // HH:mm
-	TotalSlots int    `json:"total_slots" binding:"required,min=1,max=999"`
-	Status     int    `json:"status" binding:"oneof=0 1"`
+	StartTime   string `json:"start_time" binding:"required"` // HH:mm
+	EndTime     string `json:"end_time" binding:"required"`   // HH:mm
+	TotalSlots  int    `json:"total_slots" binding:"required,min=1,max=999"`
+	OnsiteSlots int    `json:"onsite_slots" binding:"min=0,max=999"` // 现场预留号源数（预留号源转入公共池后忽略）
+	VipSlots    int    `json:"vip_slots" binding:"min=0,max=999"`    // VIP预留号源数（预留号源转入公共池后忽略）
+	Status      int    `json:"status" binding:"oneof=0 1"`
+	RoomID      *int64 `json:"room_id" binding:"omitempty,min=0"` // 诊室，不传则不调整，0为清除
 }
 
 // BatchCreateScheduleRequest 批量创建排班请求
 type BatchCreateScheduleRequest struct {
-	DoctorID   int64    `json:"doctor_id" binding:"required,min=1"`
-	StartDate  string   `json:"start_date" binding:"required"`  // YYYY-MM-DD
-	EndDate    string   `json:"end_date" binding:"required"`    // YYYY-MM-DD
-	Periods    []string `json:"periods" binding:"required,min=1,dive,oneof=morning afternoon"`
-	WeekDays   []int    `json:"week_days" binding:"required,min=1,dive,min=0,max=6"` // 0=周日, 1=周一...6=周六
-	StartTimes []string `json:"start_times" binding:"required,len=2"`                // [上午开始时间, 下午开始时间]
-	EndTimes   []string `json:"end_times" binding:"required,len=2"`                  // [上午结束时间, 下午结束时间]
-	TotalSlots int      `json:"total_slots" binding:"required,min=1,max=999"`
+	DoctorID     int64    `json:"doctor_id" binding:"required,min=1"`
+	DepartmentID int64    `json:"department_id" binding:"omitempty,min=1"` // 出诊科室，不传则为医生主科室
+	RoomID       int64    `json:"room_id" binding:"omitempty,min=1"`       // 诊室，须与出诊科室属于同一院区
+	StartDate    string   `json:"start_date" binding:"required"`           // YYYY-MM-DD
+	EndDate      string   `json:"end_date" binding:"required"`             // YYYY-MM-DD
+	Periods      []string `json:"periods" binding:"required,min=1,dive,oneof=morning afternoon"`
+	WeekDays     []int    `json:"week_days" binding:"required,min=1,dive,min=0,max=6"` // 0=周日, 1=周一...6=周六
+	StartTimes   []string `json:"start_times" binding:"required,len=2"`                // [上午开始时间, 下午开始时间]
+	EndTimes     []string `json:"end_times" binding:"required,len=2"`                  // [上午结束时间, 下午结束时间]
+	TotalSlots   int      `json:"total_slots" binding:"required,min=1,max=999"`
+	OnsiteSlots  int      `json:"onsite_slots" binding:"min=0,max=999"` // 现场预留号源数
+	VipSlots     int      `json:"vip_slots" binding:"min=0,max=999"`    // VIP预留号源数
 }
 
 // ListScheduleRequest 列表查询请求
@@ -63,6 +90,7 @@ type ListScheduleRequest struct {
 	PageSize     int    `form:"page_size" binding:"required,min=1,max=100"`
 	DoctorID     *int64 `form:"doctor_id"`
 	DepartmentID *int64 `form:"department_id"`
+	CampusID     *int64 `form:"campus_id"`
 	StartDate    string `form:"start_date"` // YYYY-MM-DD
 	EndDate      string `form:"end_date"`   // YYYY-MM-DD
 	Status       *int   `form:"status"`
@@ -72,12 +100,34 @@ type ListScheduleRequest struct {
 type ListAvailableScheduleRequest struct {
 	DoctorID     *int64 `form:"doctor_id"`
 	DepartmentID *int64 `form:"department_id"`
+	CampusID     *int64 `form:"campus_id"`
 	StartDate    string `form:"start_date" binding:"required"` // YYYY-MM-DD
 	EndDate      string `form:"end_date" binding:"required"`   // YYYY-MM-DD
 }
 
 // Create 创建排班
 func (s *ScheduleService) Create(req *CreateScheduleRequest) (*model.ScheduleVO, error) {
+	schedule, err := s.buildSchedule(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(schedule); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	s.InvalidateDoctorScheduleCache(req.DoctorID)
+
+	// 重新查询以获取关联数据
+	schedule, err = s.repo.GetByID(schedule.ID)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	return schedule.ToAdminVO(), nil
+}
+
+// buildSchedule 校验创建请求并构建排班（不入库），供单个创建与导入共用
+func (s *ScheduleService) buildSchedule(req *CreateScheduleRequest) (*model.Schedule, error) {
 	// 解析日期
 	scheduleDate, err := utils.ParseDate(req.ScheduleDate)
 	if err != nil {
@@ -89,6 +139,11 @@ func (s *ScheduleService) Create(req *CreateScheduleRequest) (*model.ScheduleVO,
 		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "排班日期不能早于今天")
 	}
 
+	// 校验渠道预留号源
+	if err := validateChannelQuota(req.TotalSlots, req.OnsiteSlots, req.VipSlots); err != nil {
+		return nil, err
+	}
+
 	// 检查医生是否存在
 	doctor, err := s.doctorRepo.GetByIDSimple(req.DoctorID)
 	if err != nil {
@@ -103,6 +158,18 @@ func (s *ScheduleService) Create(req *CreateScheduleRequest) (*model.ScheduleVO,
 		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该医生已停诊")
 	}
 
+	// 出诊科室
+	departmentID, err := s.resolveScheduleDepartment(doctor, req.DepartmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 出诊院区及诊室
+	campusID, err := s.resolveScheduleLocation(departmentID, req.RoomID)
+	if err != nil {
+		return nil, err
+	}
+
 	// 检查排班是否已存在
 	exists, err := s.repo.Exists(req.DoctorID, scheduleDate, req.Period)
 	if err != nil {
@@ -112,29 +179,31 @@ func (s *ScheduleService) Create(req *CreateScheduleRequest) (*model.ScheduleVO,
 		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该时段排班已存在")
 	}
 
-	// 创建排班
-	schedule := &model.Schedule{
-		DoctorID:       req.DoctorID,
-		ScheduleDate:   scheduleDate,
-		Period:         req.Period,
-		StartTime:      req.StartTime,
-		EndTime:        req.EndTime,
-		TotalSlots:     req.TotalSlots,
-		AvailableSlots: req.TotalSlots, // 初始剩余号源等于总号源
-		Status:         req.Status,
-	}
-
-	if err := s.repo.Create(schedule); err != nil {
-		return nil, errorcode.New(errorcode.ErrDatabase)
-	}
-
-	// 重新查询以获取关联数据
-	schedule, err = s.repo.GetByID(schedule.ID)
+	// 查询放号规则
+	rule, err := s.getReleaseRule(doctor.ID, departmentID)
 	if err != nil {
 		return nil, errorcode.New(errorcode.ErrDatabase)
 	}
 
-	return schedule.ToVO(), nil
+	// 创建排班
+	schedule := &model.Schedule{
+		DoctorID:     req.DoctorID,
+		DepartmentID: departmentID,
+		CampusID:     campusID,
+		RoomID:       req.RoomID,
+		ScheduleDate: scheduleDate,
+		Period:       req.Period,
+		StartTime:    req.StartTime,
+		EndTime:      req.EndTime,
+		TotalSlots:   req.TotalSlots,
+		Status:       req.Status,
+	}
+
+	// 设置渠道预留号源，公共号源池按放号规则计算初始剩余号源（未到放号时间的部分暂不开放）
+	applyChannelQuota(schedule, req.OnsiteSlots, req.VipSlots, 0, 0)
+	applyReleasePlan(schedule, calcReleasePlan(rule, scheduleDate, schedule.PublicSlots(), time.Now()), 0)
+
+	return schedule, nil
 }
 
 // BatchCreate 批量创建排班
@@ -164,6 +233,11 @@ func (s *ScheduleService) BatchCreate(req *BatchCreateScheduleRequest) (int, err
 		return 0, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "日期跨度不能超过90天")
 	}
 
+	// 校验渠道预留号源
+	if err := validateChannelQuota(req.TotalSlots, req.OnsiteSlots, req.VipSlots); err != nil {
+		return 0, err
+	}
+
 	// 检查医生是否存在
 	doctor, err := s.doctorRepo.GetByIDSimple(req.DoctorID)
 	if err != nil {
@@ -178,6 +252,25 @@ func (s *ScheduleService) BatchCreate(req *BatchCreateScheduleRequest) (int, err
 		return 0, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该医生已停诊")
 	}
 
+	// 出诊科室
+	departmentID, err := s.resolveScheduleDepartment(doctor, req.DepartmentID)
+	if err != nil {
+		return 0, err
+	}
+
+	// 出诊院区及诊室
+	campusID, err := s.resolveScheduleLocation(departmentID, req.RoomID)
+	if err != nil {
+		return 0, err
+	}
+
+	// 查询放号规则
+	rule, err := s.getReleaseRule(doctor.ID, departmentID)
+	if err != nil {
+		return 0, errorcode.New(errorcode.ErrDatabase)
+	}
+	now := time.Now()
+
 	// 构建星期几的映射
 	weekDayMap := make(map[int]bool)
 	for _, wd := range req.WeekDays {
@@ -220,16 +313,21 @@ func (s *ScheduleService) BatchCreate(req *BatchCreateScheduleRequest) (int, err
 			}
 
 			timeInfo := timeMap[period]
-			schedules = append(schedules, model.Schedule{
-				DoctorID:       req.DoctorID,
-				ScheduleDate:   currentDate,
-				Period:         period,
-				StartTime:      timeInfo.StartTime,
-				EndTime:        timeInfo.EndTime,
-				TotalSlots:     req.TotalSlots,
-				AvailableSlots: req.TotalSlots,
-				Status:         model.StatusEnabled,
-			})
+			schedule := model.Schedule{
+				DoctorID:     req.DoctorID,
+				DepartmentID: departmentID,
+				CampusID:     campusID,
+				RoomID:       req.RoomID,
+				ScheduleDate: currentDate,
+				Period:       period,
+				StartTime:    timeInfo.StartTime,
+				EndTime:      timeInfo.EndTime,
+				TotalSlots:   req.TotalSlots,
+				Status:       model.StatusEnabled,
+			}
+			applyChannelQuota(&schedule, req.OnsiteSlots, req.VipSlots, 0, 0)
+			applyReleasePlan(&schedule, calcReleasePlan(rule, currentDate, schedule.PublicSlots(), now), 0)
+			schedules = append(schedules, schedule)
 		}
 
 		currentDate = currentDate.AddDate(0, 0, 1)
@@ -243,6 +341,7 @@ func (s *ScheduleService) BatchCreate(req *BatchCreateScheduleRequest) (int, err
 	if err := s.repo.BatchCreate(schedules); err != nil {
 		return 0, errorcode.New(errorcode.ErrDatabase)
 	}
+	s.InvalidateDoctorScheduleCache(req.DoctorID)
 
 	return len(schedules), nil
 }
@@ -258,22 +357,58 @@ func (s *ScheduleService) Update(id int64, req *UpdateScheduleRequest) (*model.S
 		return nil, errorcode.New(errorcode.ErrDatabase)
 	}
 
+	// 各渠道已预约数
+	onsiteBooked := schedule.OnsiteSlots - schedule.OnsiteAvailable
+	vipBooked := schedule.VipSlots - schedule.VipAvailable
+	bookedSlots := schedule.PublicSlots() - schedule.AvailableSlots - schedule.UnreleasedSlots
+
+	// 预留号源已转入公共池后不再调整渠道配额
+	onsiteSlots, vipSlots := req.OnsiteSlots, req.VipSlots
+	if schedule.QuotaRolledOver {
+		onsiteSlots, vipSlots = schedule.OnsiteSlots, schedule.VipSlots
+	}
+	if onsiteSlots < onsiteBooked || vipSlots < vipBooked {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "渠道预留号源数不能少于该渠道已预约数")
+	}
+	if err := validateChannelQuota(req.TotalSlots, onsiteSlots, vipSlots); err != nil {
+		return nil, err
+	}
+
 	// 如果减少总号源数，需要检查是否小于已预约数
-	bookedSlots := schedule.TotalSlots - schedule.AvailableSlots
-	if req.TotalSlots < bookedSlots {
+	if req.TotalSlots-onsiteSlots-vipSlots < bookedSlots {
 		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "总号源数不能少于已预约数")
 	}
 
+	// 查询放号规则
+	rule, err := s.getReleaseRule(schedule.DoctorID, schedule.SessionDepartmentID())
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	// 调整诊室
+	if req.RoomID != nil && *req.RoomID != schedule.RoomID {
+		campusID, err := s.resolveScheduleLocation(schedule.SessionDepartmentID(), *req.RoomID)
+		if err != nil {
+			return nil, err
+		}
+		schedule.CampusID = campusID
+		schedule.RoomID = *req.RoomID
+	}
+
 	// 更新排班信息
 	schedule.StartTime = req.StartTime
 	schedule.EndTime = req.EndTime
 	schedule.TotalSlots = req.TotalSlots
-	schedule.AvailableSlots = req.TotalSlots - bookedSlots // 重新计算剩余号源
 	schedule.Status = req.Status
 
+	// 重新分配渠道号源，并按放号规则重新计算公共号源池的剩余号源与未放出号源
+	applyChannelQuota(schedule, onsiteSlots, vipSlots, onsiteBooked, vipBooked)
+	applyReleasePlan(schedule, calcReleasePlan(rule, schedule.ScheduleDate, schedule.PublicSlots(), time.Now()), bookedSlots)
+
 	if err := s.repo.Update(schedule); err != nil {
 		return nil, errorcode.New(errorcode.ErrDatabase)
 	}
+	s.InvalidateDoctorScheduleCache(schedule.DoctorID)
 
 	// 重新查询以获取关联数据
 	schedule, err = s.repo.GetByID(id)
@@ -281,13 +416,13 @@ func (s *ScheduleService) Update(id int64, req *UpdateScheduleRequest) (*model.S
 		return nil, errorcode.New(errorcode.ErrDatabase)
 	}
 
-	return schedule.ToVO(), nil
+	return schedule.ToAdminVO(), nil
 }
 
 // Delete 删除排班
 func (s *ScheduleService) Delete(id int64) error {
 	// 检查排班是否存在
-	_, err := s.repo.GetByIDSimple(id)
+	schedule, err := s.repo.GetByIDSimple(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return errorcode.New(errorcode.ErrScheduleNotFound)
@@ -304,7 +439,11 @@ func (s *ScheduleService) Delete(id int64) error {
 		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该排班存在预约记录，无法删除")
 	}
 
-	return s.repo.Delete(id)
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	s.InvalidateDoctorScheduleCache(schedule.DoctorID)
+	return nil
 }
 
 // GetByID 获取排班详情
@@ -316,7 +455,7 @@ func (s *ScheduleService) GetByID(id int64) (*model.ScheduleVO, error) {
 		}
 		return nil, errorcode.New(errorcode.ErrDatabase)
 	}
-	return schedule.ToVO(), nil
+	return schedule.ToAdminVO(), nil
 }
 
 // List 分页查询排班列表（管理后台）
@@ -340,14 +479,14 @@ func (s *ScheduleService) List(req *ListScheduleRequest) ([]model.ScheduleVO, in
 		endDate = &ed
 	}
 
-	schedules, total, err := s.repo.List(req.Page, req.PageSize, req.DoctorID, req.DepartmentID, startDate, endDate, req.Status)
+	schedules, total, err := s.repo.List(req.Page, req.PageSize, req.DoctorID, req.DepartmentID, req.CampusID, startDate, endDate, req.Status)
 	if err != nil {
 		return nil, 0, errorcode.New(errorcode.ErrDatabase)
 	}
 
 	voList := make([]model.ScheduleVO, len(schedules))
 	for i, schedule := range schedules {
-		voList[i] = *schedule.ToVO()
+		voList[i] = *schedule.ToAdminVO()
 	}
 
 	return voList, total, nil
@@ -375,6 +514,17 @@ func (s *ScheduleService) ListByDoctor(doctorID int64, startDate, endDate string
 		return nil, errorcode.New(errorcode.ErrDatabase)
 	}
 
+	// 优先读取缓存
+	cacheKey := fmt.Sprintf(redis.KeyDoctorSchedule, doctorID, utils.FormatDate(sd), utils.FormatDate(ed))
+	if voList, ok := s.getCachedSchedules(cacheKey); ok {
+		return voList, nil
+	}
+
+	return s.loadDoctorSchedules(doctorID, sd, ed, cacheKey)
+}
+
+// loadDoctorSchedules 查询医生排班并写入缓存
+func (s *ScheduleService) loadDoctorSchedules(doctorID int64, sd, ed time.Time, cacheKey string) ([]model.ScheduleVO, error) {
 	schedules, err := s.repo.ListByDoctor(doctorID, sd, ed)
 	if err != nil {
 		return nil, errorcode.New(errorcode.ErrDatabase)
@@ -385,6 +535,8 @@ func (s *ScheduleService) ListByDoctor(doctorID int64, startDate, endDate string
 		voList[i] = *schedule.ToVO()
 	}
 
+	s.setCachedSchedules(cacheKey, voList)
+
 	return voList, nil
 }
 
@@ -401,7 +553,7 @@ func (s *ScheduleService) ListAvailable(req *ListAvailableScheduleRequest) ([]mo
 		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "结束日期格式错误")
 	}
 
-	schedules, err := s.repo.ListAvailable(req.DoctorID, req.DepartmentID, startDate, endDate)
+	schedules, err := s.repo.ListAvailable(req.DoctorID, req.DepartmentID, req.CampusID, startDate, endDate)
 	if err != nil {
 		return nil, errorcode.New(errorcode.ErrDatabase)
 	}
@@ -413,3 +565,273 @@ func (s *ScheduleService) ListAvailable(req *ListAvailableScheduleRequest) ([]mo
 
 	return voList, nil
 }
+
+// ReleaseDueSlots 放出已到放号时间的号源（定时任务调用）
+// 返回本次放号的排班数量
+func (s *ScheduleService) ReleaseDueSlots(now time.Time) (int, error) {
+	schedules, err := s.repo.ListDueRelease(now, 500)
+	if err != nil {
+		return 0, err
+	}
+
+	released := 0
+	doctorIDs := make(map[int64]struct{})
+	for i := range schedules {
+		schedule := &schedules[i]
+		if schedule.Doctor == nil {
+			continue
+		}
+
+		rule, err := s.getReleaseRule(schedule.DoctorID, schedule.SessionDepartmentID())
+		if err != nil {
+			logger.Warn("查询放号规则失败", zap.Error(err), zap.Int64("schedule_id", schedule.ID))
+			continue
+		}
+
+		plan := calcReleasePlan(rule, schedule.ScheduleDate, schedule.PublicSlots(), now)
+		bookedSlots := schedule.PublicSlots() - schedule.AvailableSlots - schedule.UnreleasedSlots
+		target := *schedule
+		applyReleasePlan(&target, plan, bookedSlots)
+
+		delta := schedule.UnreleasedSlots - target.UnreleasedSlots
+		if delta < 0 {
+			// 规则变更导致应放数量减少时不回收已放出号源，仅更新下次放号时间
+			delta = 0
+		}
+
+		ok, err := s.repo.ReleaseSlots(schedule.ID, schedule.UnreleasedSlots, delta, target.NextReleaseAt)
+		if err != nil {
+			logger.Warn("放号失败", zap.Error(err), zap.Int64("schedule_id", schedule.ID))
+			continue
+		}
+		if !ok {
+			// 并发修改（如管理员同时编辑），下次任务再处理
+			continue
+		}
+
+		if delta > 0 {
+			released++
+		}
+		doctorIDs[schedule.DoctorID] = struct{}{}
+	}
+
+	// 预热放号医生的排班缓存
+	for doctorID := range doctorIDs {
+		s.WarmDoctorScheduleCache(doctorID)
+	}
+
+	return released, nil
+}
+
+// RolloverDueQuotas 将已到转入时间的现场/VIP预留号源转入公共号源池（定时任务调用）
+// 返回本次转入的排班数量
+func (s *ScheduleService) RolloverDueQuotas(now time.Time) (int, error) {
+	schedules, err := s.repo.ListDueRollover(now, 500)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	doctorIDs := make(map[int64]struct{})
+	for _, schedule := range schedules {
+		ok, err := s.repo.RolloverQuota(schedule.ID)
+		if err != nil {
+			logger.Warn("预留号源转入公共池失败", zap.Error(err), zap.Int64("schedule_id", schedule.ID))
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		count++
+		doctorIDs[schedule.DoctorID] = struct{}{}
+	}
+
+	for doctorID := range doctorIDs {
+		s.WarmDoctorScheduleCache(doctorID)
+	}
+
+	return count, nil
+}
+
+// WarmDoctorScheduleCache 预热医生排班缓存（可预约日期范围）
+func (s *ScheduleService) WarmDoctorScheduleCache(doctorID int64) {
+	if !redis.IsEnabled() {
+		return
+	}
+
+	s.InvalidateDoctorScheduleCache(doctorID)
+
+	advanceDays := 7
+	if cfg := config.Get(); cfg != nil && cfg.Business.Appointment.AdvanceDays > 0 {
+		advanceDays = cfg.Business.Appointment.AdvanceDays
+	}
+	sd := utils.GetTodayStart()
+	ed := sd.AddDate(0, 0, advanceDays)
+	cacheKey := fmt.Sprintf(redis.KeyDoctorSchedule, doctorID, utils.FormatDate(sd), utils.FormatDate(ed))
+
+	_, _ = s.loadDoctorSchedules(doctorID, sd, ed, cacheKey)
+}
+
+// InvalidateDoctorScheduleCache 清除医生排班缓存（排班或号源变化时调用）
+func (s *ScheduleService) InvalidateDoctorScheduleCache(doctorID int64) {
+	invalidateDoctorScheduleCache(doctorID)
+}
+
+// invalidateDoctorScheduleCache 清除医生排班缓存
+func invalidateDoctorScheduleCache(doctorID int64) {
+	if !redis.IsEnabled() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	client := redis.GetClient()
+	pattern := fmt.Sprintf(redis.KeyDoctorSchedule, doctorID, "*", "*")
+	var cursor uint64
+	for {
+		keys, nextCursor, err := client.Scan(ctx, cursor, pattern, 100).Result()
+		if err != nil {
+			return
+		}
+		if len(keys) > 0 {
+			_ = redis.Del(ctx, keys...)
+		}
+		cursor = nextCursor
+		if cursor == 0 {
+			return
+		}
+	}
+}
+
+// getCachedSchedules 读取排班缓存
+func (s *ScheduleService) getCachedSchedules(key string) ([]model.ScheduleVO, bool) {
+	if !redis.IsEnabled() {
+		return nil, false
+	}
+
+	val, err := redis.Get(context.Background(), key)
+	if err != nil {
+		return nil, false
+	}
+
+	var voList []model.ScheduleVO
+	if err := json.Unmarshal([]byte(val), &voList); err != nil {
+		return nil, false
+	}
+	return voList, true
+}
+
+// setCachedSchedules 写入排班缓存
+func (s *ScheduleService) setCachedSchedules(key string, voList []model.ScheduleVO) {
+	if !redis.IsEnabled() {
+		return
+	}
+
+	data, err := json.Marshal(voList)
+	if err != nil {
+		return
+	}
+	_ = redis.Set(context.Background(), key, data, doctorScheduleCacheTTL)
+}
+
+// resolveScheduleDepartment 确定排班的出诊科室：未指定时取医生主科室，指定时须为医生的执业科室
+func (s *ScheduleService) resolveScheduleDepartment(doctor *model.Doctor, departmentID int64) (int64, error) {
+	if departmentID == 0 || departmentID == doctor.DepartmentID {
+		return doctor.DepartmentID, nil
+	}
+
+	ok, err := s.doctorRepo.HasDepartment(doctor.ID, departmentID)
+	if err != nil {
+		return 0, errorcode.New(errorcode.ErrDatabase)
+	}
+	if !ok {
+		return 0, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该医生未在所选科室执业")
+	}
+	return departmentID, nil
+}
+
+// resolveScheduleLocation 确定排班的出诊院区（取出诊科室所属院区），并校验诊室
+// 诊室须为启用状态、与出诊科室属于同一院区，且为公共诊室或出诊科室的诊室
+func (s *ScheduleService) resolveScheduleLocation(departmentID, roomID int64) (int64, error) {
+	dept, err := s.deptRepo.GetByID(departmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, errorcode.New(errorcode.ErrDepartmentNotFound)
+		}
+		return 0, errorcode.New(errorcode.ErrDatabase)
+	}
+	if roomID == 0 {
+		return dept.CampusID, nil
+	}
+
+	room, err := s.roomRepo.GetByID(roomID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, errorcode.New(errorcode.ErrRoomNotFound)
+		}
+		return 0, errorcode.New(errorcode.ErrDatabase)
+	}
+	if room.Status == model.StatusDisabled {
+		return 0, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该诊室已停用")
+	}
+	if room.CampusID != dept.CampusID {
+		return 0, errorcode.New(errorcode.ErrRoomCampusMismatch)
+	}
+	if room.DepartmentID > 0 && room.DepartmentID != departmentID {
+		return 0, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该诊室不属于出诊科室")
+	}
+	return dept.CampusID, nil
+}
+
+// getReleaseRule 获取排班生效的放号规则（按医生、出诊科室匹配），无规则时返回 nil
+func (s *ScheduleService) getReleaseRule(doctorID, departmentID int64) (*model.ReleaseRule, error) {
+	rule, err := s.ruleRepo.GetEffective(doctorID, departmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return rule, nil
+}
+
+// validateChannelQuota 校验渠道预留号源
+func validateChannelQuota(totalSlots, onsiteSlots, vipSlots int) error {
+	if onsiteSlots+vipSlots > totalSlots {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "现场与VIP预留号源之和不能超过总号源数")
+	}
+	return nil
+}
+
+// applyChannelQuota 设置排班的现场/VIP预留号源，并计算预留号源转入公共池的时间
+// onsiteBooked、vipBooked 为对应渠道已预约数
+func applyChannelQuota(schedule *model.Schedule, onsiteSlots, vipSlots, onsiteBooked, vipBooked int) {
+	schedule.OnsiteSlots = onsiteSlots
+	schedule.OnsiteAvailable = onsiteSlots - onsiteBooked
+	schedule.VipSlots = vipSlots
+	schedule.VipAvailable = vipSlots - vipBooked
+
+	if schedule.QuotaRolledOver {
+		return
+	}
+
+	schedule.QuotaRolloverAt = nil
+	if onsiteSlots+vipSlots == 0 {
+		return
+	}
+
+	start, err := time.ParseInLocation("2006-01-02 15:04",
+		schedule.ScheduleDate.Format("2006-01-02")+" "+schedule.StartTime, time.Local)
+	if err != nil {
+		return
+	}
+
+	minutes := 0
+	if cfg := config.Get(); cfg != nil {
+		minutes = cfg.Business.Schedule.QuotaRolloverMinutes
+	}
+	rolloverAt := start.Add(-time.Duration(minutes) * time.Minute)
+	schedule.QuotaRolloverAt = &rolloverAt
+}
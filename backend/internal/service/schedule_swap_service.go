@@ -0,0 +1,484 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/internal/repository"
+	"huaan-medical/pkg/database"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/utils"
+)
+
+// ScheduleSwapService 换班申请服务
+// 流程：医生A发起 -> 医生B同意 -> 科室管理员审批 -> 排班及预约转给对方并通知患者
+type ScheduleSwapService struct {
+	repo                *repository.ScheduleSwapRepository
+	scheduleRepo        *repository.ScheduleRepository
+	doctorRepo          *repository.DoctorRepository
+	appointmentRepo     *repository.AppointmentRepository
+	notificationService *NotificationService
+}
+
+// NewScheduleSwapService 创建换班申请服务实例
+func NewScheduleSwapService() *ScheduleSwapService {
+	return &ScheduleSwapService{
+		repo:                repository.NewScheduleSwapRepository(),
+		scheduleRepo:        repository.NewScheduleRepository(),
+		doctorRepo:          repository.NewDoctorRepository(),
+		appointmentRepo:     repository.NewAppointmentRepository(),
+		notificationService: NewNotificationService(),
+	}
+}
+
+// CreateScheduleSwapRequest 发起换班请求
+type CreateScheduleSwapRequest struct {
+	ScheduleID       int64  `json:"schedule_id" binding:"required,min=1"`         // 转出的排班
+	TargetDoctorID   int64  `json:"target_doctor_id" binding:"required,min=1"`    // 接班医生
+	TargetScheduleID *int64 `json:"target_schedule_id" binding:"omitempty,min=1"` // 换入的排班（互换时填写）
+	Reason           string `json:"reason" binding:"max=256"`
+}
+
+// RespondScheduleSwapRequest 对方医生答复请求
+type RespondScheduleSwapRequest struct {
+	Accept *bool  `json:"accept" binding:"required"`
+	Remark string `json:"remark" binding:"max=256"`
+}
+
+// ReviewScheduleSwapRequest 审批请求
+type ReviewScheduleSwapRequest struct {
+	Approved *bool  `json:"approved" binding:"required"`
+	Remark   string `json:"remark" binding:"max=256"`
+}
+
+// ListScheduleSwapRequest 列表查询请求
+type ListScheduleSwapRequest struct {
+	Page         int    `form:"page" binding:"required,min=1"`
+	PageSize     int    `form:"page_size" binding:"required,min=1,max=100"`
+	Status       string `form:"status"`
+	DoctorID     *int64 `form:"doctor_id"`
+	DepartmentID *int64 `form:"department_id"`
+}
+
+// Create 发起换班申请
+// requesterDoctorID 为发起医生ID，为 0 时表示管理员代医生发起（以排班所属医生为申请人）
+func (s *ScheduleSwapService) Create(requesterDoctorID int64, req *CreateScheduleSwapRequest) (*model.ScheduleSwapVO, error) {
+	schedule, err := s.getSchedule(req.ScheduleID)
+	if err != nil {
+		return nil, err
+	}
+	if requesterDoctorID > 0 && schedule.DoctorID != requesterDoctorID {
+		return nil, errorcode.NewWithMessage(errorcode.ErrForbidden, "只能转出本人的排班")
+	}
+
+	var targetSchedule *model.Schedule
+	if req.TargetScheduleID != nil {
+		targetSchedule, err = s.getSchedule(*req.TargetScheduleID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.validate(schedule, req.TargetDoctorID, targetSchedule, 0); err != nil {
+		return nil, err
+	}
+
+	swap := &model.ScheduleSwap{
+		RequesterDoctorID: schedule.DoctorID,
+		TargetDoctorID:    req.TargetDoctorID,
+		ScheduleID:        schedule.ID,
+		TargetScheduleID:  req.TargetScheduleID,
+		Reason:            req.Reason,
+		Status:            model.ScheduleSwapStatusPending,
+	}
+	if err := s.repo.Create(swap); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	return s.GetByID(swap.ID)
+}
+
+// Respond 对方医生答复（同意/拒绝）
+// doctorID 为答复医生ID，为 0 时表示管理员代对方医生答复
+func (s *ScheduleSwapService) Respond(id, doctorID int64, req *RespondScheduleSwapRequest) error {
+	swap, err := s.getSwap(id)
+	if err != nil {
+		return err
+	}
+	if doctorID > 0 && swap.TargetDoctorID != doctorID {
+		return errorcode.NewWithMessage(errorcode.ErrForbidden, "只能答复发给本人的换班申请")
+	}
+	if swap.Status != model.ScheduleSwapStatusPending {
+		return errorcode.New(errorcode.ErrScheduleSwapStatus)
+	}
+
+	toStatus := model.ScheduleSwapStatusRejected
+	if *req.Accept {
+		toStatus = model.ScheduleSwapStatusAccepted
+	}
+
+	ok, err := s.repo.UpdateStatus(nil, id, model.ScheduleSwapStatusPending, toStatus, map[string]interface{}{
+		"respond_remark": req.Remark,
+		"responded_at":   time.Now(),
+	})
+	if err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if !ok {
+		return errorcode.New(errorcode.ErrScheduleSwapStatus)
+	}
+	return nil
+}
+
+// Cancel 撤销换班申请（审批前可撤销）
+// doctorID 为申请医生ID，为 0 时表示管理员代为撤销
+func (s *ScheduleSwapService) Cancel(id, doctorID int64) error {
+	swap, err := s.getSwap(id)
+	if err != nil {
+		return err
+	}
+	if doctorID > 0 && swap.RequesterDoctorID != doctorID {
+		return errorcode.NewWithMessage(errorcode.ErrForbidden, "只能撤销本人发起的换班申请")
+	}
+	if !swap.IsOpen() {
+		return errorcode.New(errorcode.ErrScheduleSwapStatus)
+	}
+
+	ok, err := s.repo.UpdateStatus(nil, id, swap.Status, model.ScheduleSwapStatusCancelled, nil)
+	if err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if !ok {
+		return errorcode.New(errorcode.ErrScheduleSwapStatus)
+	}
+	return nil
+}
+
+// Review 科室管理员审批
+// 审批通过时在同一事务内将排班及其预约转给对方医生，并通知受影响的患者
+func (s *ScheduleSwapService) Review(id, adminID int64, req *ReviewScheduleSwapRequest) error {
+	swap, err := s.getSwap(id)
+	if err != nil {
+		return err
+	}
+	if swap.Status != model.ScheduleSwapStatusAccepted {
+		return errorcode.New(errorcode.ErrScheduleSwapStatus)
+	}
+
+	now := time.Now()
+	extra := map[string]interface{}{
+		"reviewer_id":   adminID,
+		"review_remark": req.Remark,
+		"reviewed_at":   now,
+	}
+
+	// 驳回
+	if !*req.Approved {
+		ok, err := s.repo.UpdateStatus(nil, id, model.ScheduleSwapStatusAccepted, model.ScheduleSwapStatusDeclined, extra)
+		if err != nil {
+			return errorcode.New(errorcode.ErrDatabase)
+		}
+		if !ok {
+			return errorcode.New(errorcode.ErrScheduleSwapStatus)
+		}
+		return nil
+	}
+
+	// 审批通过前重新校验（排班可能在申请后被修改）
+	schedule, err := s.getSchedule(swap.ScheduleID)
+	if err != nil {
+		return err
+	}
+	var targetSchedule *model.Schedule
+	if swap.TargetScheduleID != nil {
+		targetSchedule, err = s.getSchedule(*swap.TargetScheduleID)
+		if err != nil {
+			return err
+		}
+	}
+	if schedule.DoctorID != swap.RequesterDoctorID ||
+		(targetSchedule != nil && targetSchedule.DoctorID != swap.TargetDoctorID) {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "排班已变更，请重新发起换班申请")
+	}
+	if err := s.validate(schedule, swap.TargetDoctorID, targetSchedule, swap.ID); err != nil {
+		return err
+	}
+
+	requester, err := s.doctorRepo.GetByID(swap.RequesterDoctorID)
+	if err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	target, err := s.doctorRepo.GetByID(swap.TargetDoctorID)
+	if err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+
+	// 排班转给对方后的出诊科室：对方在原科室执业则保持不变，否则改为对方主科室
+	deptID, deptName := handoverDepartment(schedule, target)
+	var targetDeptID int64
+	var targetDeptName string
+	if targetSchedule != nil {
+		targetDeptID, targetDeptName = handoverDepartment(targetSchedule, requester)
+	}
+
+	// 事务前查询待就诊预约，用于通知
+	appointments, err := s.appointmentRepo.ListPendingBySchedule(schedule.ID)
+	if err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	var targetAppointments []model.Appointment
+	if targetSchedule != nil {
+		targetAppointments, err = s.appointmentRepo.ListPendingBySchedule(targetSchedule.ID)
+		if err != nil {
+			return errorcode.New(errorcode.ErrDatabase)
+		}
+	}
+
+	err = database.GetDB().Transaction(func(tx *gorm.DB) error {
+		ok, err := s.repo.UpdateStatus(tx, id, model.ScheduleSwapStatusAccepted, model.ScheduleSwapStatusApproved, extra)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errorcode.New(errorcode.ErrScheduleSwapStatus)
+		}
+
+		if err := reassignSchedule(tx, schedule.ID, requester.ID, target.ID, deptID); err != nil {
+			return err
+		}
+		if targetSchedule != nil {
+			if err := reassignSchedule(tx, targetSchedule.ID, target.ID, requester.ID, targetDeptID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		var appErr *errorcode.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+
+	invalidateDoctorScheduleCache(requester.ID)
+	invalidateDoctorScheduleCache(target.ID)
+
+	// 通知受影响的患者
+	notifications := buildDoctorChangeNotifications(appointments, requester, target, deptName)
+	notifications = append(notifications, buildDoctorChangeNotifications(targetAppointments, target, requester, targetDeptName)...)
+	s.notificationService.Send(notifications)
+
+	return nil
+}
+
+// GetByID 获取换班申请详情
+func (s *ScheduleSwapService) GetByID(id int64) (*model.ScheduleSwapVO, error) {
+	swap, err := s.getSwap(id)
+	if err != nil {
+		return nil, err
+	}
+	return swap.ToVO(), nil
+}
+
+// GetByIDForDoctor 获取本人发起或收到的换班申请详情
+func (s *ScheduleSwapService) GetByIDForDoctor(id, doctorID int64) (*model.ScheduleSwapVO, error) {
+	swap, err := s.getSwap(id)
+	if err != nil {
+		return nil, err
+	}
+	if swap.RequesterDoctorID != doctorID && swap.TargetDoctorID != doctorID {
+		return nil, errorcode.New(errorcode.ErrScheduleSwapNotFound)
+	}
+	return swap.ToVO(), nil
+}
+
+// List 分页查询换班申请
+func (s *ScheduleSwapService) List(req *ListScheduleSwapRequest) ([]model.ScheduleSwapVO, int64, error) {
+	swaps, total, err := s.repo.List(req.Page, req.PageSize, req.Status, req.DoctorID, req.DepartmentID)
+	if err != nil {
+		return nil, 0, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	voList := make([]model.ScheduleSwapVO, len(swaps))
+	for i, swap := range swaps {
+		voList[i] = *swap.ToVO()
+	}
+
+	return voList, total, nil
+}
+
+// validate 校验换班是否可行
+func (s *ScheduleSwapService) validate(schedule *model.Schedule, targetDoctorID int64, targetSchedule *model.Schedule, excludeSwapID int64) error {
+	if schedule.DoctorID == targetDoctorID {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "不能与本人换班")
+	}
+
+	// 转出排班必须未过期
+	today := utils.GetTodayStart()
+	if schedule.ScheduleDate.Before(today) {
+		return errorcode.New(errorcode.ErrScheduleDatePassed)
+	}
+
+	// 对方医生必须存在且出诊
+	targetDoctor, err := s.doctorRepo.GetByIDSimple(targetDoctorID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errorcode.New(errorcode.ErrDoctorNotFound)
+		}
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if targetDoctor.Status == model.StatusDisabled {
+		return errorcode.New(errorcode.ErrDoctorDisabled)
+	}
+
+	scheduleIDs := []int64{schedule.ID}
+	var excludeForTarget int64
+	if targetSchedule != nil {
+		if targetSchedule.DoctorID != targetDoctorID {
+			return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "换入的排班不属于对方医生")
+		}
+		if targetSchedule.ScheduleDate.Before(today) {
+			return errorcode.New(errorcode.ErrScheduleDatePassed)
+		}
+		scheduleIDs = append(scheduleIDs, targetSchedule.ID)
+		excludeForTarget = targetSchedule.ID
+
+		// 申请医生在换入时段不能已有其他排班
+		exists, err := s.scheduleRepo.Exists(schedule.DoctorID, targetSchedule.ScheduleDate, targetSchedule.Period, schedule.ID)
+		if err != nil {
+			return errorcode.New(errorcode.ErrDatabase)
+		}
+		if exists {
+			return errorcode.NewWithMessage(errorcode.ErrScheduleConflict, "申请医生在换入时段已有排班")
+		}
+	}
+
+	// 对方医生在转出时段不能已有其他排班
+	exists, err := s.scheduleRepo.Exists(targetDoctorID, schedule.ScheduleDate, schedule.Period, excludeForTarget)
+	if err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if exists {
+		return errorcode.NewWithMessage(errorcode.ErrScheduleConflict, "对方医生在该时段已有排班")
+	}
+
+	// 同一排班只能有一个进行中的换班申请
+	open, err := s.repo.ExistsOpenBySchedule(scheduleIDs, excludeSwapID)
+	if err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if open {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该排班已有进行中的换班申请")
+	}
+
+	return nil
+}
+
+// getSwap 查询换班申请
+func (s *ScheduleSwapService) getSwap(id int64) (*model.ScheduleSwap, error) {
+	swap, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrScheduleSwapNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return swap, nil
+}
+
+// getSchedule 查询排班
+func (s *ScheduleSwapService) getSchedule(id int64) (*model.Schedule, error) {
+	schedule, err := s.scheduleRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrScheduleNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return schedule, nil
+}
+
+// handoverDepartment 计算排班转给 to 医生后的出诊科室ID及名称（to 需预加载执业科室）
+func handoverDepartment(schedule *model.Schedule, to *model.Doctor) (int64, string) {
+	deptID := schedule.SessionDepartmentID()
+	if !to.HasDepartment(deptID) {
+		deptID = to.DepartmentID
+	}
+
+	if to.Department != nil && to.DepartmentID == deptID {
+		return deptID, to.Department.Name
+	}
+	for _, a := range to.Affiliations {
+		if a.DepartmentID == deptID && a.Department != nil {
+			return deptID, a.Department.Name
+		}
+	}
+	return deptID, ""
+}
+
+// reassignSchedule 将排班及其预约从 fromDoctorID 转给 toDoctorID，并设置出诊科室（需要在事务中调用）
+// 出诊院区随出诊科室调整，原诊室不属于新出诊科室（或其院区公共诊室）时清除
+func reassignSchedule(tx *gorm.DB, scheduleID, fromDoctorID, toDoctorID, departmentID int64) error {
+	result := tx.Model(&model.Schedule{}).
+		Where("id = ? AND doctor_id = ?", scheduleID, fromDoctorID).
+		Updates(map[string]interface{}{
+			"doctor_id":     toDoctorID,
+			"department_id": departmentID,
+			"campus_id":     gorm.Expr("(SELECT campus_id FROM departments WHERE id = ?)", departmentID),
+			"room_id": gorm.Expr("IF(room_id IN (SELECT id FROM rooms WHERE campus_id = (SELECT campus_id FROM departments WHERE id = ?) AND department_id IN (0, ?)), room_id, 0)",
+				departmentID, departmentID),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "排班已变更，请重新发起换班申请")
+	}
+
+	var schedule model.Schedule
+	if err := tx.Select("campus_id", "room_id").First(&schedule, scheduleID).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&model.Appointment{}).
+		Where("schedule_id = ?", scheduleID).
+		Updates(map[string]interface{}{
+			"doctor_id":     toDoctorID,
+			"department_id": departmentID,
+			"campus_id":     schedule.CampusID,
+			"room_id":       schedule.RoomID,
+		}).Error
+}
+
+// buildDoctorChangeNotifications 构建就诊医生变更通知
+func buildDoctorChangeNotifications(appointments []model.Appointment, from, to *model.Doctor, departmentName string) []model.Notification {
+	notifications := make([]model.Notification, 0, len(appointments))
+	for _, a := range appointments {
+		patientName := ""
+		if a.Patient != nil {
+			patientName = a.Patient.Name
+		}
+		notifications = append(notifications, model.Notification{
+			UserID: a.UserID,
+			Type:   model.NotificationTypeDoctorChange,
+			Title:  "就诊医生变更通知",
+			Content: fmt.Sprintf("%s您好，您预约的%s %s%s门诊（预约号%s）因医生排班调整，就诊医生由%s变更为%s%s，就诊时间不变。如需调整请取消后重新预约。",
+				patientName,
+				a.AppointmentDate.Format("2006-01-02"),
+				model.GetPeriodName(a.Period),
+				departmentName,
+				a.AppointmentNo,
+				from.Name,
+				to.Name,
+				model.GetTitleName(to.Title),
+			),
+			BizID: a.ID,
+		})
+	}
+	return notifications
+}
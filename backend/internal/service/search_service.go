@@ -0,0 +1,768 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/internal/repository"
+	"huaan-medical/pkg/config"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/logger"
+	"huaan-medical/pkg/utils"
+)
+
+// SearchService 医生/科室搜索服务
+// 索引文档由医生、科室数据派生（名称全拼/首字母、擅长领域、科室名称等），
+// 检索时按同义词组扩展检索词，在候选集上计算相关度并叠加评分、可预约等因素排序
+type SearchService struct {
+	repo         *repository.SearchRepository
+	doctorRepo   *repository.DoctorRepository
+	deptRepo     *repository.DepartmentRepository
+	scheduleRepo *repository.ScheduleRepository
+	reviewRepo   *repository.DoctorReviewRepository
+}
+
+// NewSearchService 创建搜索服务实例
+func NewSearchService() *SearchService {
+	return &SearchService{
+		repo:         repository.NewSearchRepository(),
+		doctorRepo:   repository.NewDoctorRepository(),
+		deptRepo:     repository.NewDepartmentRepository(),
+		scheduleRepo: repository.NewScheduleRepository(),
+		reviewRepo:   repository.NewDoctorReviewRepository(),
+	}
+}
+
+// 搜索参数
+const (
+	searchDefaultLimit   = 20  // 默认返回条数
+	searchCandidateLimit = 200 // 单类候选集上限
+	searchSynonymWeight  = 0.6 // 同义词扩展词权重
+)
+
+// 命中方式
+const (
+	searchMatchName     = "name"
+	searchMatchPinyin   = "pinyin"
+	searchMatchInitials = "initials"
+	searchMatchKeyword  = "keyword"
+	searchMatchSynonym  = "synonym"
+)
+
+// SearchRequest 搜索请求
+type SearchRequest struct {
+	Keyword      string `form:"keyword" binding:"required,max=64"`
+	Type         string `form:"type" binding:"omitempty,oneof=all doctor department"`
+	DepartmentID *int64 `form:"department_id"` // 仅对医生结果生效
+	Limit        int    `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+// SearchResponse 搜索结果
+type SearchResponse struct {
+	Keyword     string                     `json:"keyword"`
+	Terms       []string                   `json:"terms"` // 实际检索词（含同义词扩展）
+	Doctors     []model.DoctorSearchVO     `json:"doctors"`
+	Departments []model.DepartmentSearchVO `json:"departments"`
+}
+
+// CreateSearchSynonymRequest 创建同义词组请求
+type CreateSearchSynonymRequest struct {
+	Words  []string `json:"words" binding:"required,min=2,max=20,dive,required,max=32"`
+	Remark string   `json:"remark" binding:"max=256"`
+	Status *int     `json:"status"`
+}
+
+// UpdateSearchSynonymRequest 更新同义词组请求
+type UpdateSearchSynonymRequest struct {
+	Words  []string `json:"words" binding:"required,min=2,max=20,dive,required,max=32"`
+	Remark string   `json:"remark" binding:"max=256"`
+	Status *int     `json:"status"`
+}
+
+// ListSearchSynonymRequest 同义词组列表请求
+type ListSearchSynonymRequest struct {
+	Page     int    `form:"page" binding:"required,min=1"`
+	PageSize int    `form:"page_size" binding:"required,min=1,max=100"`
+	Keyword  string `form:"keyword"`
+	Status   *int   `form:"status"`
+}
+
+// searchTerm 检索词
+type searchTerm struct {
+	Text    string
+	Weight  float64
+	Synonym bool
+}
+
+// searchHit 候选文档得分
+type searchHit struct {
+	Doc       model.SearchDocument
+	Score     float64
+	MatchedBy []string
+}
+
+// Search 搜索医生和科室
+func (s *SearchService) Search(req *SearchRequest) (*SearchResponse, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = searchDefaultLimit
+	}
+
+	terms, err := s.buildTerms(req.Keyword)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &SearchResponse{
+		Keyword:     req.Keyword,
+		Terms:       make([]string, 0, len(terms)),
+		Doctors:     []model.DoctorSearchVO{},
+		Departments: []model.DepartmentSearchVO{},
+	}
+	for _, t := range terms {
+		resp.Terms = append(resp.Terms, t.Text)
+	}
+	if len(terms) == 0 {
+		return resp, nil
+	}
+
+	if req.Type != model.SearchEntityDepartment {
+		hits, err := s.searchHits(model.SearchEntityDoctor, terms)
+		if err != nil {
+			return nil, err
+		}
+		resp.Doctors, err = s.buildDoctorResults(hits, req.DepartmentID, limit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Type != model.SearchEntityDoctor {
+		hits, err := s.searchHits(model.SearchEntityDepartment, terms)
+		if err != nil {
+			return nil, err
+		}
+		resp.Departments, err = s.buildDepartmentResults(hits, limit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
+}
+
+// MatchDoctorIDs 按关键词匹配可检索的医生，返回按相关度排序的医生ID（用于医生列表关键词筛选）
+func (s *SearchService) MatchDoctorIDs(keyword string) ([]int64, error) {
+	terms, err := s.buildTerms(keyword)
+	if err != nil {
+		return nil, err
+	}
+	hits, err := s.searchHits(model.SearchEntityDoctor, terms)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.Doc.EntityID
+	}
+	return ids, nil
+}
+
+// IndexDoctor 更新医生索引（医生不存在时删除索引）
+// 索引更新失败不影响业务操作，仅记录日志，由定时全量重建兜底
+func (s *SearchService) IndexDoctor(doctorID int64) {
+	doctor, err := s.doctorRepo.GetByID(doctorID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.RemoveDoctor(doctorID)
+			return
+		}
+		logger.Warn("更新医生搜索索引失败", zap.Error(err), zap.Int64("doctor_id", doctorID))
+		return
+	}
+
+	if err := s.repo.Upsert(buildDoctorSearchDocument(doctor)); err != nil {
+		logger.Warn("更新医生搜索索引失败", zap.Error(err), zap.Int64("doctor_id", doctorID))
+	}
+}
+
+// RemoveDoctor 删除医生索引
+func (s *SearchService) RemoveDoctor(doctorID int64) {
+	if err := s.repo.Delete(model.SearchEntityDoctor, doctorID); err != nil {
+		logger.Warn("删除医生搜索索引失败", zap.Error(err), zap.Int64("doctor_id", doctorID))
+	}
+}
+
+// IndexDepartment 更新科室索引，并同步更新在该科室执业的医生索引（医生关键词包含科室名称）
+func (s *SearchService) IndexDepartment(departmentID int64) {
+	dept, err := s.deptRepo.GetByID(departmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.RemoveDepartment(departmentID)
+			return
+		}
+		logger.Warn("更新科室搜索索引失败", zap.Error(err), zap.Int64("department_id", departmentID))
+		return
+	}
+
+	if err := s.repo.Upsert(buildDepartmentSearchDocument(dept)); err != nil {
+		logger.Warn("更新科室搜索索引失败", zap.Error(err), zap.Int64("department_id", departmentID))
+		return
+	}
+
+	doctorIDs, err := s.doctorRepo.ListIDsByDepartment(departmentID)
+	if err != nil {
+		logger.Warn("查询科室医生失败", zap.Error(err), zap.Int64("department_id", departmentID))
+		return
+	}
+	for _, doctorID := range doctorIDs {
+		s.IndexDoctor(doctorID)
+	}
+}
+
+// RemoveDepartment 删除科室索引
+func (s *SearchService) RemoveDepartment(departmentID int64) {
+	if err := s.repo.Delete(model.SearchEntityDepartment, departmentID); err != nil {
+		logger.Warn("删除科室搜索索引失败", zap.Error(err), zap.Int64("department_id", departmentID))
+	}
+}
+
+// Rebuild 全量重建搜索索引，返回索引文档数
+func (s *SearchService) Rebuild() (int, error) {
+	departments, err := s.deptRepo.ListAllIncludeDisabled()
+	if err != nil {
+		return 0, errorcode.New(errorcode.ErrDatabase)
+	}
+	doctors, err := s.doctorRepo.ListAllWithDepartments()
+	if err != nil {
+		return 0, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	docs := make([]model.SearchDocument, 0, len(departments)+len(doctors))
+	for i := range departments {
+		docs = append(docs, *buildDepartmentSearchDocument(&departments[i]))
+	}
+	for i := range doctors {
+		docs = append(docs, *buildDoctorSearchDocument(&doctors[i]))
+	}
+
+	if err := s.repo.ReplaceAll(docs); err != nil {
+		return 0, errorcode.New(errorcode.ErrDatabase)
+	}
+	return len(docs), nil
+}
+
+// ListSynonyms 分页查询同义词组
+func (s *SearchService) ListSynonyms(req *ListSearchSynonymRequest) ([]model.SearchSynonymVO, int64, error) {
+	list, total, err := s.repo.ListSynonyms(req.Page, req.PageSize, strings.TrimSpace(req.Keyword), req.Status)
+	if err != nil {
+		return nil, 0, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	voList := make([]model.SearchSynonymVO, len(list))
+	for i := range list {
+		voList[i] = *list[i].ToVO()
+	}
+	return voList, total, nil
+}
+
+// CreateSynonym 创建同义词组
+func (s *SearchService) CreateSynonym(req *CreateSearchSynonymRequest) (*model.SearchSynonymVO, error) {
+	words, err := normalizeSynonymWords(req.Words)
+	if err != nil {
+		return nil, err
+	}
+	status, err := parseSynonymStatus(req.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	synonym := &model.SearchSynonym{
+		Words:  strings.Join(words, ","),
+		Remark: strings.TrimSpace(req.Remark),
+		Status: status,
+	}
+	if err := s.repo.CreateSynonym(synonym); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return synonym.ToVO(), nil
+}
+
+// UpdateSynonym 更新同义词组
+func (s *SearchService) UpdateSynonym(id int64, req *UpdateSearchSynonymRequest) (*model.SearchSynonymVO, error) {
+	synonym, err := s.getSynonym(id)
+	if err != nil {
+		return nil, err
+	}
+
+	words, err := normalizeSynonymWords(req.Words)
+	if err != nil {
+		return nil, err
+	}
+	synonym.Words = strings.Join(words, ",")
+	synonym.Remark = strings.TrimSpace(req.Remark)
+	if req.Status != nil {
+		if synonym.Status, err = parseSynonymStatus(req.Status); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.UpdateSynonym(synonym); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return synonym.ToVO(), nil
+}
+
+// DeleteSynonym 删除同义词组
+func (s *SearchService) DeleteSynonym(id int64) error {
+	if _, err := s.getSynonym(id); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteSynonym(id); err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	return nil
+}
+
+// getSynonym 查询同义词组
+func (s *SearchService) getSynonym(id int64) (*model.SearchSynonym, error) {
+	synonym, err := s.repo.GetSynonym(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.NewWithMessage(errorcode.ErrNotFound, "同义词组不存在")
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return synonym, nil
+}
+
+// buildTerms 解析关键词并按同义词组扩展
+// 关键词按空白拆分；检索词包含同义词组中的任一词语时，组内其它词语以较低权重加入检索
+func (s *SearchService) buildTerms(keyword string) ([]searchTerm, error) {
+	var terms []searchTerm
+	seen := map[string]bool{}
+	for _, field := range strings.Fields(strings.ToLower(keyword)) {
+		if !seen[field] {
+			seen[field] = true
+			terms = append(terms, searchTerm{Text: field, Weight: 1})
+		}
+	}
+	if len(terms) == 0 {
+		return terms, nil
+	}
+
+	synonyms, err := s.repo.ListEnabledSynonyms()
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	primary := len(terms)
+	for _, synonym := range synonyms {
+		words := synonym.WordList()
+		matched := false
+		for _, word := range words {
+			for _, t := range terms[:primary] {
+				if strings.Contains(t.Text, strings.ToLower(word)) {
+					matched = true
+					break
+				}
+			}
+			if matched {
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		for _, word := range words {
+			word = strings.ToLower(word)
+			if !seen[word] {
+				seen[word] = true
+				terms = append(terms, searchTerm{Text: word, Weight: searchSynonymWeight, Synonym: true})
+			}
+		}
+	}
+
+	return terms, nil
+}
+
+// searchHits 查询候选文档并计算相关度（按得分降序）
+func (s *SearchService) searchHits(entityType string, terms []searchTerm) ([]searchHit, error) {
+	texts := make([]string, len(terms))
+	for i, t := range terms {
+		texts[i] = t.Text
+	}
+
+	docs, err := s.repo.Search(entityType, texts, searchCandidateLimit)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	hits := make([]searchHit, 0, len(docs))
+	for _, doc := range docs {
+		hit := scoreSearchDocument(doc, terms)
+		if hit.Score > 0 {
+			hits = append(hits, hit)
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Score > hits[j].Score
+	})
+	return hits, nil
+}
+
+// buildDoctorResults 构建医生搜索结果（叠加评分、职称、可预约等因素后重新排序）
+func (s *SearchService) buildDoctorResults(hits []searchHit, departmentID *int64, limit int) ([]model.DoctorSearchVO, error) {
+	if len(hits) == 0 {
+		return []model.DoctorSearchVO{}, nil
+	}
+
+	ids := make([]int64, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.Doc.EntityID
+	}
+
+	doctors, err := s.doctorRepo.ListByIDs(ids)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	doctorMap := make(map[int64]*model.Doctor, len(doctors))
+	for i := range doctors {
+		doctorMap[doctors[i].ID] = &doctors[i]
+	}
+
+	summaries, err := s.reviewRepo.SummaryByDoctors(ids)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	startDate, endDate := searchAvailabilityRange()
+	schedules, err := s.scheduleRepo.ListUpcoming(ids, nil, startDate, endDate)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	byDoctor := make(map[int64][]model.Schedule)
+	for _, schedule := range schedules {
+		byDoctor[schedule.DoctorID] = append(byDoctor[schedule.DoctorID], schedule)
+	}
+
+	list := make([]model.DoctorSearchVO, 0, len(hits))
+	for _, hit := range hits {
+		doctor, ok := doctorMap[hit.Doc.EntityID]
+		if !ok || doctor.Status != model.StatusEnabled {
+			continue
+		}
+		if departmentID != nil && *departmentID > 0 && !doctor.HasDepartment(*departmentID) {
+			continue
+		}
+
+		summary := summaries[doctor.ID]
+		availability := buildSearchAvailability(byDoctor[doctor.ID])
+
+		// 排序加权：评分（0-10）、职称（0-3）、近期有号（+8）
+		score := hit.Score + summary.Rating*2 + searchTitleBoost(doctor.Title)
+		if availability.HasAvailable {
+			score += 8
+		}
+
+		vo := model.DoctorSearchVO{
+			ID:           doctor.ID,
+			Name:         doctor.Name,
+			Avatar:       doctor.Avatar,
+			Title:        doctor.Title,
+			TitleName:    model.GetTitleName(doctor.Title),
+			Specialty:    doctor.Specialty,
+			DepartmentID: doctor.DepartmentID,
+			Rating:       summary.Rating,
+			ReviewCount:  summary.ReviewCount,
+			Score:        score,
+			MatchedBy:    hit.MatchedBy,
+			Availability: availability,
+		}
+		if doctor.Department != nil {
+			vo.DepartmentName = doctor.Department.Name
+		}
+		list = append(list, vo)
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Score > list[j].Score
+	})
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+// buildDepartmentResults 构建科室搜索结果
+func (s *SearchService) buildDepartmentResults(hits []searchHit, limit int) ([]model.DepartmentSearchVO, error) {
+	if len(hits) == 0 {
+		return []model.DepartmentSearchVO{}, nil
+	}
+
+	ids := make([]int64, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.Doc.EntityID
+	}
+
+	departments, err := s.deptRepo.ListByIDs(ids)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	deptMap := make(map[int64]*model.Department, len(departments))
+	for i := range departments {
+		deptMap[departments[i].ID] = &departments[i]
+	}
+
+	counts, err := s.doctorRepo.CountEnabledByDepartments(ids)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	startDate, endDate := searchAvailabilityRange()
+	schedules, err := s.scheduleRepo.ListUpcoming(nil, ids, startDate, endDate)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	byDept := make(map[int64][]model.Schedule)
+	for _, schedule := range schedules {
+		byDept[schedule.DepartmentID] = append(byDept[schedule.DepartmentID], schedule)
+	}
+
+	list := make([]model.DepartmentSearchVO, 0, len(hits))
+	for _, hit := range hits {
+		dept, ok := deptMap[hit.Doc.EntityID]
+		if !ok || dept.Status != model.StatusEnabled {
+			continue
+		}
+
+		availability := buildSearchAvailability(byDept[dept.ID])
+		score := hit.Score
+		if availability.HasAvailable {
+			score += 5
+		}
+
+		list = append(list, model.DepartmentSearchVO{
+			ID:           dept.ID,
+			ParentID:     dept.ParentID,
+			Name:         dept.Name,
+			Description:  dept.Description,
+			Icon:         dept.Icon,
+			DoctorCount:  counts[dept.ID],
+			Score:        score,
+			MatchedBy:    hit.MatchedBy,
+			Availability: availability,
+		})
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Score > list[j].Score
+	})
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+// buildDoctorSearchDocument 构建医生索引文档
+// 关键词包含擅长领域、擅长领域标签、职称及全部执业科室名称；医生停诊或主科室停用时不可检索
+func buildDoctorSearchDocument(doctor *model.Doctor) *model.SearchDocument {
+	keywords := []string{doctor.Specialty, model.GetTitleName(doctor.Title)}
+	if doctor.Department != nil {
+		keywords = append(keywords, doctor.Department.Name)
+	}
+	for _, a := range doctor.Affiliations {
+		if a.Department != nil && a.DepartmentID != doctor.DepartmentID {
+			keywords = append(keywords, a.Department.Name)
+		}
+	}
+	for _, t := range doctor.Tags {
+		if t.Status == model.StatusEnabled {
+			keywords = append(keywords, t.Name)
+		}
+	}
+
+	status := doctor.Status
+	if doctor.Department != nil && doctor.Department.Status != model.StatusEnabled {
+		status = model.StatusDisabled
+	}
+
+	full, initials := utils.ToPinyin(doctor.Name)
+	return &model.SearchDocument{
+		EntityType: model.SearchEntityDoctor,
+		EntityID:   doctor.ID,
+		Name:       doctor.Name,
+		Pinyin:     strings.Join(full, " "),
+		Initials:   strings.Join(initials, " "),
+		Keywords:   strings.ToLower(strings.Join(keywords, " ")),
+		SortOrder:  doctor.SortOrder,
+		Status:     status,
+	}
+}
+
+// buildDepartmentSearchDocument 构建科室索引文档
+func buildDepartmentSearchDocument(dept *model.Department) *model.SearchDocument {
+	full, initials := utils.ToPinyin(dept.Name)
+	return &model.SearchDocument{
+		EntityType: model.SearchEntityDepartment,
+		EntityID:   dept.ID,
+		Name:       dept.Name,
+		Pinyin:     strings.Join(full, " "),
+		Initials:   strings.Join(initials, " "),
+		Keywords:   strings.ToLower(dept.Description),
+		SortOrder:  dept.SortOrder,
+		Status:     dept.Status,
+	}
+}
+
+// scoreSearchDocument 计算文档相关度
+// 每个检索词取各字段的最高分（名称 > 拼音/首字母 > 关键词），同义词扩展词按权重折算，各检索词得分累加
+func scoreSearchDocument(doc model.SearchDocument, terms []searchTerm) searchHit {
+	hit := searchHit{Doc: doc, MatchedBy: []string{}}
+	matched := map[string]bool{}
+
+	name := strings.ToLower(doc.Name)
+	pinyins := strings.Fields(doc.Pinyin)
+	initials := strings.Fields(doc.Initials)
+
+	for _, term := range terms {
+		best, by := 0.0, ""
+		try := func(score float64, field string) {
+			if score > best {
+				best, by = score, field
+			}
+		}
+
+		switch {
+		case name == term.Text:
+			try(100, searchMatchName)
+		case strings.HasPrefix(name, term.Text):
+			try(80, searchMatchName)
+		case strings.Contains(name, term.Text):
+			try(60, searchMatchName)
+		}
+
+		if utils.IsPinyinQuery(term.Text) {
+			for _, p := range pinyins {
+				switch {
+				case p == term.Text:
+					try(70, searchMatchPinyin)
+				case strings.HasPrefix(p, term.Text):
+					try(55, searchMatchPinyin)
+				case strings.Contains(p, term.Text):
+					try(35, searchMatchPinyin)
+				}
+			}
+			for _, p := range initials {
+				switch {
+				case p == term.Text:
+					try(65, searchMatchInitials)
+				case strings.HasPrefix(p, term.Text):
+					try(50, searchMatchInitials)
+				}
+			}
+		}
+
+		if strings.Contains(doc.Keywords, term.Text) {
+			try(30, searchMatchKeyword)
+		}
+
+		if best == 0 {
+			continue
+		}
+		hit.Score += best * term.Weight
+		if term.Synonym {
+			by = searchMatchSynonym
+		}
+		if !matched[by] {
+			matched[by] = true
+			hit.MatchedBy = append(hit.MatchedBy, by)
+		}
+	}
+
+	return hit
+}
+
+// buildSearchAvailability 根据近期排班生成可预约提示（排班需按日期、开始时间升序）
+func buildSearchAvailability(schedules []model.Schedule) model.SearchAvailability {
+	var availability model.SearchAvailability
+	for _, schedule := range schedules {
+		if schedule.AvailableSlots > 0 {
+			if !availability.HasAvailable {
+				availability.HasAvailable = true
+				availability.NextDate = utils.FormatDate(schedule.ScheduleDate)
+				availability.NextPeriod = schedule.Period
+				availability.NextPeriodName = model.GetPeriodName(schedule.Period)
+			}
+			availability.AvailableSlots += schedule.AvailableSlots
+		}
+		if schedule.UnreleasedSlots > 0 {
+			availability.Upcoming = true
+		}
+	}
+	return availability
+}
+
+// searchAvailabilityRange 可预约提示的日期范围（今天起的可预约天数）
+func searchAvailabilityRange() (startDate, endDate time.Time) {
+	advanceDays := 7
+	if cfg := config.Get(); cfg != nil && cfg.Business.Appointment.AdvanceDays > 0 {
+		advanceDays = cfg.Business.Appointment.AdvanceDays
+	}
+	startDate = utils.GetTodayStart()
+	return startDate, startDate.AddDate(0, 0, advanceDays)
+}
+
+// searchTitleBoost 职称排序加权
+func searchTitleBoost(title string) float64 {
+	switch title {
+	case model.TitleChiefPhysician:
+		return 3
+	case model.TitleAssociateChiefPhysician:
+		return 2
+	case model.TitleAttendingPhysician:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// normalizeSynonymWords 同义词去空白、去重，至少保留两个词
+func normalizeSynonymWords(words []string) ([]string, error) {
+	seen := make(map[string]bool, len(words))
+	result := make([]string, 0, len(words))
+	for _, word := range words {
+		word = strings.TrimSpace(word)
+		if word == "" || seen[word] {
+			continue
+		}
+		if strings.Contains(word, ",") {
+			return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "同义词不能包含逗号")
+		}
+		seen[word] = true
+		result = append(result, word)
+	}
+	if len(result) < 2 {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "同义词组至少包含两个不同的词")
+	}
+	return result, nil
+}
+
+// parseSynonymStatus 解析同义词组状态（默认启用）
+func parseSynonymStatus(status *int) (int, error) {
+	if status == nil {
+		return model.StatusEnabled, nil
+	}
+	if *status != model.StatusEnabled && *status != model.StatusDisabled {
+		return 0, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "状态值无效")
+	}
+	return *status, nil
+}
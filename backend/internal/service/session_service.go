@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/internal/repository"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/jwt"
+	"huaan-medical/pkg/logger"
+	"huaan-medical/pkg/redis"
+	"huaan-medical/pkg/utils"
+)
+
+// sessionRetention 过期或吊销的会话保留时长（供设备记录追溯），超过后清理
+const sessionRetention = 30 * 24 * time.Hour
+
+// SessionService 用户登录会话服务
+// 会话以数据库为准，Redis 仅缓存有效会话的当前刷新Token标识供鉴权快速校验，Redis 不可用时回退数据库
+type SessionService struct {
+	sessionRepo *repository.SessionRepository
+	userRepo    *repository.UserRepository
+	logRepo     *repository.LogRepository
+}
+
+// NewSessionService 创建会话服务实例
+func NewSessionService() *SessionService {
+	return &SessionService{
+		sessionRepo: repository.NewSessionRepository(),
+		userRepo:    repository.NewUserRepository(),
+		logRepo:     repository.NewLogRepository(),
+	}
+}
+
+// Create 登录成功后创建会话并签发Token，同时记录登录日志
+func (s *SessionService) Create(user *model.User, loginType, clientIP, userAgent string) (*jwt.TokenPair, error) {
+	sessionID := utils.GenerateShortUUID()
+	tokenPair, err := jwt.GenerateTokenPair(user.ID, user.OpenID, sessionID)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrInternalServer)
+	}
+
+	if len(userAgent) > 256 {
+		userAgent = userAgent[:256]
+	}
+	now := time.Now()
+	session := &model.UserSession{
+		UserID:       user.ID,
+		SessionID:    sessionID,
+		RefreshID:    tokenPair.RefreshID,
+		LoginType:    loginType,
+		IP:           clientIP,
+		UserAgent:    userAgent,
+		LastActiveAt: now,
+		LastIP:       clientIP,
+		ExpiresAt:    tokenPair.RefreshExpiresAt,
+	}
+	if err := s.sessionRepo.Create(session); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	cacheSession(session)
+
+	_ = s.logRepo.CreateLoginLog(&model.LoginLog{
+		UserType:  model.UserTypeUser,
+		UserID:    user.ID,
+		Username:  user.Username,
+		LoginType: loginType,
+		IP:        clientIP,
+		Device:    userAgent,
+		Status:    model.LoginStatusSuccess,
+		Message:   "登录成功",
+	})
+
+	return tokenPair, nil
+}
+
+// Refresh 使用刷新Token换取新的Token对（刷新Token一次有效，每次轮换）
+// 已轮换的刷新Token再次使用说明可能已泄露，吊销整个会话，所有设备上的该会话Token均失效
+func (s *SessionService) Refresh(refreshToken, clientIP string) (*jwt.TokenPair, error) {
+	claims, err := jwt.ParseToken(refreshToken)
+	if err != nil {
+		return nil, errorcode.NewWithMessage(errorcode.ErrUnauthorized, err.Error())
+	}
+	if claims.TokenType != jwt.RefreshToken {
+		return nil, errorcode.NewWithMessage(errorcode.ErrUnauthorized, "无效的refresh token")
+	}
+	// 未携带会话信息的旧版Token无法检测重放，需重新登录
+	if claims.SessionID == "" || claims.ID == "" {
+		return nil, errorcode.NewWithMessage(errorcode.ErrTokenInvalid, "登录已过期，请重新登录")
+	}
+
+	session, err := s.sessionRepo.GetBySessionID(claims.SessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrTokenInvalid)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	now := time.Now()
+	if session.UserID != claims.UserID || !session.IsActive(now) {
+		return nil, errorcode.New(errorcode.ErrTokenInvalid)
+	}
+	if session.RefreshID != claims.ID {
+		s.revokeReused(session, now)
+		return nil, errorcode.NewWithMessage(errorcode.ErrTokenInvalid, "登录凭证已失效，请重新登录")
+	}
+
+	user, err := s.userRepo.GetByID(session.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrTokenInvalid)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	if user.Status == model.StatusDisabled {
+		return nil, errorcode.New(errorcode.ErrAccountDisabled)
+	}
+	if user.TokensRevokedAt != nil && !session.CreatedAt.After(*user.TokensRevokedAt) {
+		s.revoke(session, model.SessionRevokeSecurity, now)
+		return nil, errorcode.New(errorcode.ErrTokenInvalid)
+	}
+
+	tokenPair, err := jwt.GenerateTokenPair(user.ID, user.OpenID, session.SessionID)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrInternalServer)
+	}
+	rotated, err := s.sessionRepo.Rotate(session.ID, claims.ID, tokenPair.RefreshID, clientIP, tokenPair.RefreshExpiresAt, now)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	if !rotated {
+		// 同一刷新Token被并发使用
+		s.revokeReused(session, now)
+		return nil, errorcode.NewWithMessage(errorcode.ErrTokenInvalid, "登录凭证已失效，请重新登录")
+	}
+
+	session.RefreshID = tokenPair.RefreshID
+	session.ExpiresAt = tokenPair.RefreshExpiresAt
+	cacheSession(session)
+
+	return tokenPair, nil
+}
+
+// Logout 退出登录，吊销当前会话
+func (s *SessionService) Logout(userID int64, sessionID string) error {
+	if sessionID == "" {
+		return nil
+	}
+
+	session, err := s.sessionRepo.GetBySessionID(sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if session.UserID != userID {
+		return nil
+	}
+	return s.revoke(session, model.SessionRevokeLogout, time.Now())
+}
+
+// List 查询当前用户已登录的设备
+func (s *SessionService) List(userID int64, currentSessionID string) ([]*model.UserSessionVO, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrUserNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	list, err := s.sessionRepo.ListActiveByUser(userID, user.TokensRevokedAt, time.Now())
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	result := make([]*model.UserSessionVO, 0, len(list))
+	for i := range list {
+		result = append(result, list[i].ToVO(currentSessionID))
+	}
+	return result, nil
+}
+
+// Revoke 下线指定设备
+func (s *SessionService) Revoke(userID, id int64) error {
+	session, err := s.sessionRepo.GetByUserAndID(userID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errorcode.New(errorcode.ErrSessionNotFound)
+		}
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if session.RevokedAt != nil {
+		return nil
+	}
+	return s.revoke(session, model.SessionRevokeUser, time.Now())
+}
+
+// RevokeOthers 下线除当前设备外的所有设备，返回下线数量
+func (s *SessionService) RevokeOthers(userID int64, currentSessionID string) (int, error) {
+	sessionIDs, err := s.sessionRepo.RevokeByUser(userID, currentSessionID, model.SessionRevokeUser, time.Now())
+	if err != nil {
+		return 0, errorcode.New(errorcode.ErrDatabase)
+	}
+	uncacheSessions(sessionIDs...)
+	return len(sessionIDs), nil
+}
+
+// CleanStale 清理过期或吊销超过保留期的会话
+func (s *SessionService) CleanStale(now time.Time) (int64, error) {
+	return s.sessionRepo.DeleteStale(now.Add(-sessionRetention))
+}
+
+// revoke 吊销会话并清除缓存
+func (s *SessionService) revoke(session *model.UserSession, reason string, now time.Time) error {
+	if err := s.sessionRepo.Revoke(session.ID, reason, now); err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	uncacheSessions(session.SessionID)
+	return nil
+}
+
+// revokeReused 刷新Token重放：吊销整个会话
+func (s *SessionService) revokeReused(session *model.UserSession, now time.Time) {
+	logger.Warn("检测到刷新Token重复使用，吊销会话",
+		zap.Int64("user_id", session.UserID), zap.Int64("session_id", session.ID))
+	_ = s.revoke(session, model.SessionRevokeReuse, now)
+}
+
+// revokeUserSessions 吊销用户全部会话（改密、注销、合并等场景）
+func revokeUserSessions(userID int64, now time.Time) {
+	sessionIDs, err := repository.NewSessionRepository().RevokeByUser(userID, "", model.SessionRevokeSecurity, now)
+	if err != nil {
+		logger.Error("吊销用户会话失败", zap.Int64("user_id", userID), zap.Error(err))
+		return
+	}
+	uncacheSessions(sessionIDs...)
+}
+
+// cacheSession 缓存有效会话的当前刷新Token标识，过期时间与会话一致
+func cacheSession(session *model.UserSession) {
+	if !redis.IsEnabled() {
+		return
+	}
+	ttl := time.Until(session.ExpiresAt)
+	if ttl <= 0 {
+		return
+	}
+	_ = redis.Set(context.Background(), fmt.Sprintf(redis.KeyRefreshToken, session.SessionID), session.RefreshID, ttl)
+}
+
+// uncacheSessions 清除会话缓存，鉴权时将回退数据库确认会话已吊销
+func uncacheSessions(sessionIDs ...string) {
+	if !redis.IsEnabled() || len(sessionIDs) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(sessionIDs))
+	for _, id := range sessionIDs {
+		keys = append(keys, fmt.Sprintf(redis.KeyRefreshToken, id))
+	}
+	_ = redis.Del(context.Background(), keys...)
+}
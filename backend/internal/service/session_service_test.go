@@ -0,0 +1,323 @@
+package service
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/pkg/config"
+	"huaan-medical/pkg/database"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/jwt"
+	"huaan-medical/pkg/logger"
+)
+
+// fakeSessionStore 内存中的会话与用户数据，通过 DryRun 模式的 gorm 回调提供给仓库层
+type fakeSessionStore struct {
+	sessions map[int64]*model.UserSession
+	users    map[int64]*model.User
+	// beforeRotate 在轮换写入前执行，用于模拟并发请求抢先轮换
+	beforeRotate func()
+}
+
+// newFakeSessionService 创建使用内存数据的会话服务
+func newFakeSessionService(t *testing.T, store *fakeSessionStore) *SessionService {
+	t.Helper()
+	db, err := gorm.Open(mysql.New(mysql.Config{SkipInitializeWithVersion: true}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatalf("open dry-run db: %v", err)
+	}
+	if err := db.Callback().Query().Replace("gorm:query", store.query); err != nil {
+		t.Fatalf("replace query callback: %v", err)
+	}
+	if err := db.Callback().Update().Replace("gorm:update", store.update); err != nil {
+		t.Fatalf("replace update callback: %v", err)
+	}
+
+	previous := database.GetDB()
+	database.SetDB(db)
+	t.Cleanup(func() { database.SetDB(previous) })
+	return NewSessionService()
+}
+
+// whereVars 取第一个查询条件中的参数
+func whereVars(db *gorm.DB) []interface{} {
+	where, ok := db.Statement.Clauses["WHERE"].Expression.(clause.Where)
+	if !ok || len(where.Exprs) == 0 {
+		return nil
+	}
+	switch expr := where.Exprs[0].(type) {
+	case clause.Expr:
+		return expr.Vars
+	case clause.IN:
+		return expr.Values
+	case clause.Eq:
+		return []interface{}{expr.Value}
+	}
+	return nil
+}
+
+func (s *fakeSessionStore) query(db *gorm.DB) {
+	vars := whereVars(db)
+	if len(vars) == 0 {
+		db.AddError(errors.New("unsupported query"))
+		return
+	}
+	var found interface{}
+	switch db.Statement.Table {
+	case "user_sessions":
+		for _, session := range s.sessions {
+			if session.SessionID == vars[0] {
+				copied := *session
+				found = &copied
+			}
+		}
+	case "users":
+		if user, ok := s.users[reflect.ValueOf(vars[0]).Int()]; ok {
+			copied := *user
+			found = &copied
+		}
+	}
+	if found == nil {
+		db.AddError(gorm.ErrRecordNotFound)
+		return
+	}
+	reflect.ValueOf(db.Statement.Dest).Elem().Set(reflect.ValueOf(found).Elem())
+	db.RowsAffected = 1
+}
+
+func (s *fakeSessionStore) update(db *gorm.DB) {
+	values, ok := db.Statement.Dest.(map[string]interface{})
+	vars := whereVars(db)
+	if db.Statement.Table != "user_sessions" || !ok || len(vars) == 0 {
+		db.AddError(errors.New("unsupported update"))
+		return
+	}
+	session, exists := s.sessions[vars[0].(int64)]
+	if !exists || session.RevokedAt != nil {
+		return
+	}
+	if newRefreshID, rotating := values["refresh_id"]; rotating {
+		if s.beforeRotate != nil {
+			s.beforeRotate()
+		}
+		if session.RefreshID != vars[1] {
+			return
+		}
+		session.RefreshID = newRefreshID.(string)
+		session.ExpiresAt = values["expires_at"].(time.Time)
+		session.LastIP = values["last_ip"].(string)
+	}
+	if revokedAt, revoking := values["revoked_at"]; revoking {
+		at := revokedAt.(time.Time)
+		session.RevokedAt = &at
+		session.RevokeReason = values["revoke_reason"].(string)
+	}
+	db.RowsAffected = 1
+}
+
+// initSessionTestEnv 初始化签发Token和记录日志所需的配置
+func initSessionTestEnv(t *testing.T) {
+	t.Helper()
+	jwt.Init(&config.JWTConfig{
+		Secret:             "session-service-test-secret",
+		AccessTokenExpire:  time.Hour,
+		RefreshTokenExpire: 24 * time.Hour,
+	})
+	if err := logger.Init(&config.LogConfig{
+		Level:    "error",
+		Filename: filepath.Join(t.TempDir(), "test.log"),
+	}); err != nil {
+		t.Fatalf("init logger: %v", err)
+	}
+}
+
+// newSessionFixture 创建一个有效会话及其刷新Token
+func newSessionFixture(t *testing.T) (*fakeSessionStore, string) {
+	t.Helper()
+	pair, err := jwt.GenerateTokenPair(1, "openid", "sess-1")
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	now := time.Now()
+	session := &model.UserSession{
+		UserID:    1,
+		SessionID: "sess-1",
+		RefreshID: pair.RefreshID,
+		ExpiresAt: pair.RefreshExpiresAt,
+	}
+	session.ID = 10
+	session.CreatedAt = now.Add(-time.Minute)
+	user := &model.User{OpenID: "openid", Status: model.StatusEnabled}
+	user.ID = 1
+	return &fakeSessionStore{
+		sessions: map[int64]*model.UserSession{session.ID: session},
+		users:    map[int64]*model.User{user.ID: user},
+	}, pair.RefreshToken
+}
+
+// assertErrorCode 校验返回的业务错误码
+func assertErrorCode(t *testing.T, err error, code int) {
+	t.Helper()
+	var appErr *errorcode.AppError
+	if !errors.As(err, &appErr) || appErr.Code != code {
+		t.Fatalf("error = %v, want code %d", err, code)
+	}
+}
+
+func TestSessionServiceRefreshRotation(t *testing.T) {
+	initSessionTestEnv(t)
+	store, refreshToken := newSessionFixture(t)
+	svc := newFakeSessionService(t, store)
+	session := store.sessions[10]
+
+	// 首次刷新：轮换刷新Token标识
+	pair, err := svc.Refresh(refreshToken, "10.0.0.1")
+	if err != nil {
+		t.Fatalf("first refresh: %v", err)
+	}
+	if session.RefreshID != pair.RefreshID {
+		t.Fatalf("RefreshID = %s, want rotated %s", session.RefreshID, pair.RefreshID)
+	}
+	if session.LastIP != "10.0.0.1" {
+		t.Errorf("LastIP = %s, want 10.0.0.1", session.LastIP)
+	}
+
+	// 新的刷新Token可继续轮换
+	next, err := svc.Refresh(pair.RefreshToken, "10.0.0.1")
+	if err != nil {
+		t.Fatalf("refresh with rotated token: %v", err)
+	}
+
+	// 重放已轮换的刷新Token：吊销整个会话
+	_, err = svc.Refresh(refreshToken, "10.0.0.2")
+	assertErrorCode(t, err, errorcode.ErrTokenInvalid)
+	if session.RevokedAt == nil || session.RevokeReason != model.SessionRevokeReuse {
+		t.Fatalf("session revoked = %v reason = %q, want revoked for reuse", session.RevokedAt, session.RevokeReason)
+	}
+
+	// 会话吊销后，最新的刷新Token同样失效
+	_, err = svc.Refresh(next.RefreshToken, "10.0.0.1")
+	assertErrorCode(t, err, errorcode.ErrTokenInvalid)
+}
+
+func TestSessionServiceRefreshRejects(t *testing.T) {
+	initSessionTestEnv(t)
+
+	tests := []struct {
+		name       string
+		setup      func(store *fakeSessionStore, refreshToken string) string
+		wantCode   int
+		wantReason string
+	}{
+		{
+			name: "Access Token不能用于刷新",
+			setup: func(_ *fakeSessionStore, _ string) string {
+				pair, _ := jwt.GenerateTokenPair(1, "openid", "sess-1")
+				return pair.AccessToken
+			},
+			wantCode: errorcode.ErrUnauthorized,
+		},
+		{
+			name: "未携带会话ID的旧版Token",
+			setup: func(_ *fakeSessionStore, _ string) string {
+				pair, _ := jwt.GenerateTokenPair(1, "openid", "")
+				return pair.RefreshToken
+			},
+			wantCode: errorcode.ErrTokenInvalid,
+		},
+		{
+			name:     "签名无效",
+			setup:    func(_ *fakeSessionStore, token string) string { return token + "x" },
+			wantCode: errorcode.ErrUnauthorized,
+		},
+		{
+			name: "会话不存在",
+			setup: func(store *fakeSessionStore, token string) string {
+				store.sessions[10].SessionID = "other"
+				return token
+			},
+			wantCode: errorcode.ErrTokenInvalid,
+		},
+		{
+			name: "会话属于其他用户",
+			setup: func(store *fakeSessionStore, token string) string {
+				store.sessions[10].UserID = 2
+				return token
+			},
+			wantCode: errorcode.ErrTokenInvalid,
+		},
+		{
+			name: "会话已吊销",
+			setup: func(store *fakeSessionStore, token string) string {
+				revokedAt := time.Now().Add(-time.Second)
+				store.sessions[10].RevokedAt = &revokedAt
+				store.sessions[10].RevokeReason = model.SessionRevokeLogout
+				return token
+			},
+			wantCode:   errorcode.ErrTokenInvalid,
+			wantReason: model.SessionRevokeLogout,
+		},
+		{
+			name: "会话已过期",
+			setup: func(store *fakeSessionStore, token string) string {
+				store.sessions[10].ExpiresAt = time.Now().Add(-time.Second)
+				return token
+			},
+			wantCode: errorcode.ErrTokenInvalid,
+		},
+		{
+			name: "账号已停用",
+			setup: func(store *fakeSessionStore, token string) string {
+				store.users[1].Status = model.StatusDisabled
+				return token
+			},
+			wantCode: errorcode.ErrAccountDisabled,
+		},
+		{
+			name: "会话创建于账号整体吊销之前",
+			setup: func(store *fakeSessionStore, token string) string {
+				revokedAt := time.Now()
+				store.users[1].TokensRevokedAt = &revokedAt
+				return token
+			},
+			wantCode:   errorcode.ErrTokenInvalid,
+			wantReason: model.SessionRevokeSecurity,
+		},
+		{
+			name: "并发请求已抢先轮换",
+			setup: func(store *fakeSessionStore, token string) string {
+				store.beforeRotate = func() { store.sessions[10].RefreshID = "rotated-by-other-request" }
+				return token
+			},
+			wantCode:   errorcode.ErrTokenInvalid,
+			wantReason: model.SessionRevokeReuse,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, refreshToken := newSessionFixture(t)
+			token := tt.setup(store, refreshToken)
+			session := store.sessions[10]
+			svc := newFakeSessionService(t, store)
+
+			_, err := svc.Refresh(token, "10.0.0.1")
+			assertErrorCode(t, err, tt.wantCode)
+			if session.RevokeReason != tt.wantReason {
+				t.Errorf("RevokeReason = %q, want %q", session.RevokeReason, tt.wantReason)
+			}
+		})
+	}
+}
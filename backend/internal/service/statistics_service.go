@@ -1,18 +1,27 @@
 package service
 
 import (
+	"sort"
 	"time"
 
 	"huaan-medical/internal/model"
+	"huaan-medical/internal/repository"
 	"huaan-medical/pkg/database"
+	"huaan-medical/pkg/errorcode"
 )
 
 // StatisticsService 统计服务
-type StatisticsService struct{}
+type StatisticsService struct {
+	deptRepo   *repository.DepartmentRepository
+	reviewRepo *repository.DoctorReviewRepository
+}
 
 // NewStatisticsService 创建统计服务实例
 func NewStatisticsService() *StatisticsService {
-	return &StatisticsService{}
+	return &StatisticsService{
+		deptRepo:   repository.NewDepartmentRepository(),
+		reviewRepo: repository.NewDoctorReviewRepository(),
+	}
 }
 
 // DashboardData 仪表盘数据
@@ -36,6 +45,7 @@ type TrendData struct {
 
 // DepartmentStat 科室统计
 type DepartmentStat struct {
+	DepartmentID   int64  `json:"department_id"`   // 科室ID
 	DepartmentName string `json:"department_name"` // 科室名称
 	DoctorCount    int64  `json:"doctor_count"`    // 医生数
 	AppointmentCount int64  `json:"appointment_count"` // 预约数
@@ -66,7 +76,8 @@ type DoctorStat struct {
 	DepartmentName string `json:"department_name"`
 	AppointmentCount int64  `json:"appointment_count"`
 	CompletedCount int64  `json:"completed_count"`
-	Rating float64 `json:"rating"` // 评分（预留）
+	Rating float64 `json:"rating"` // 平均评分（未屏蔽评价）
+	ReviewCount int64 `json:"review_count"` // 评价数
 }
 
 // DepartmentRanking 科室排行
@@ -83,7 +94,8 @@ type TimeSlotStat struct {
 }
 
 // GetDashboard 获取仪表盘数据
-func (s *StatisticsService) GetDashboard() (*DashboardData, error) {
+// rollup 为 true 时科室统计汇总到一级科室（含全部下级科室）
+func (s *StatisticsService) GetDashboard(rollup bool) (*DashboardData, error) {
 	db := database.GetDB()
 	today := time.Now().Format("2006-01-02")
 
@@ -150,9 +162,9 @@ func (s *StatisticsService) GetDashboard() (*DashboardData, error) {
 	var deptStats []DeptStat
 	db.Model(&model.Department{}).
 		Select("departments.id as department_id, departments.name as department_name, "+
-			"COUNT(DISTINCT doctors.id) as doctor_count, "+
+			"COUNT(DISTINCT doctor_departments.doctor_id) as doctor_count, "+
 			"COUNT(appointments.id) as appointment_count").
-		Joins("LEFT JOIN doctors ON doctors.department_id = departments.id").
+		Joins("LEFT JOIN doctor_departments ON doctor_departments.department_id = departments.id").
 		Joins("LEFT JOIN appointments ON appointments.department_id = departments.id").
 		Group("departments.id").
 		Find(&deptStats)
@@ -160,17 +172,27 @@ func (s *StatisticsService) GetDashboard() (*DashboardData, error) {
 	data.DepartmentStats = make([]DepartmentStat, len(deptStats))
 	for i, stat := range deptStats {
 		data.DepartmentStats[i] = DepartmentStat{
+			DepartmentID:     stat.DepartmentID,
 			DepartmentName:   stat.DepartmentName,
 			DoctorCount:      stat.DoctorCount,
 			AppointmentCount: stat.AppointmentCount,
 		}
 	}
 
+	if rollup {
+		stats, err := s.rollupDepartmentStats(data.DepartmentStats)
+		if err != nil {
+			return nil, err
+		}
+		data.DepartmentStats = stats
+	}
+
 	return &data, nil
 }
 
 // GetStatistics 获取统计数据
-func (s *StatisticsService) GetStatistics(startDate, endDate string) (*StatisticsData, error) {
+// rollup 为 true 时科室排行汇总到一级科室（含全部下级科室）
+func (s *StatisticsService) GetStatistics(startDate, endDate string, rollup bool) (*StatisticsData, error) {
 	db := database.GetDB()
 
 	var data StatisticsData
@@ -203,7 +225,7 @@ func (s *StatisticsService) GetStatistics(startDate, endDate string) (*Statistic
 			"COUNT(appointments.id) as appointment_count, "+
 			"SUM(CASE WHEN appointments.status = ? THEN 1 ELSE 0 END) as completed_count", model.AppointmentStatusCompleted).
 		Joins("JOIN doctors ON doctors.id = appointments.doctor_id").
-		Joins("JOIN departments ON departments.id = appointments.department_id")
+		Joins("JOIN departments ON departments.id = doctors.department_id")
 
 	if startDate != "" && endDate != "" {
 		docQuery = docQuery.Where("DATE(appointments.appointment_date) BETWEEN ? AND ?", startDate, endDate)
@@ -214,6 +236,15 @@ func (s *StatisticsService) GetStatistics(startDate, endDate string) (*Statistic
 		Limit(10).
 		Find(&docStats)
 
+	docIDs := make([]int64, len(docStats))
+	for i, stat := range docStats {
+		docIDs[i] = stat.DoctorID
+	}
+	summaries, err := s.reviewRepo.SummaryByDoctors(docIDs)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
 	data.DoctorStats = make([]DoctorStat, len(docStats))
 	for i, stat := range docStats {
 		data.DoctorStats[i] = DoctorStat{
@@ -222,6 +253,8 @@ func (s *StatisticsService) GetStatistics(startDate, endDate string) (*Statistic
 			DepartmentName:   stat.DepartmentName,
 			AppointmentCount: stat.AppointmentCount,
 			CompletedCount:   stat.CompletedCount,
+			Rating:           summaries[stat.DoctorID].Rating,
+			ReviewCount:      summaries[stat.DoctorID].ReviewCount,
 		}
 	}
 
@@ -241,10 +274,11 @@ func (s *StatisticsService) GetStatistics(startDate, endDate string) (*Statistic
 		deptQuery = deptQuery.Where("DATE(appointments.appointment_date) BETWEEN ? AND ?", startDate, endDate)
 	}
 
-	deptQuery.Group("departments.id").
-		Order("appointment_count DESC").
-		Limit(10).
-		Find(&deptRanks)
+	deptQuery = deptQuery.Group("departments.id").Order("appointment_count DESC")
+	if !rollup {
+		deptQuery = deptQuery.Limit(10)
+	}
+	deptQuery.Find(&deptRanks)
 
 	data.DepartmentRanking = make([]DepartmentRanking, len(deptRanks))
 	for i, rank := range deptRanks {
@@ -255,6 +289,14 @@ func (s *StatisticsService) GetStatistics(startDate, endDate string) (*Statistic
 		}
 	}
 
+	if rollup {
+		ranking, err := s.rollupDepartmentRanking(data.DepartmentRanking)
+		if err != nil {
+			return nil, err
+		}
+		data.DepartmentRanking = ranking
+	}
+
 	// 时段分布
 	type TimeSlot struct {
 		Period string
@@ -280,3 +322,78 @@ func (s *StatisticsService) GetStatistics(startDate, endDate string) (*Statistic
 
 	return &data, nil
 }
+
+// rollupDepartmentStats 将科室统计汇总到一级科室，按一级科室的排序返回
+func (s *StatisticsService) rollupDepartmentStats(stats []DepartmentStat) ([]DepartmentStat, error) {
+	departments, err := s.deptRepo.ListAllIncludeDisabled()
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	rootIDs := departmentRootIDs(departments)
+
+	totals := make(map[int64]*DepartmentStat)
+	for _, stat := range stats {
+		rootID, ok := rootIDs[stat.DepartmentID]
+		if !ok {
+			continue
+		}
+		total, ok := totals[rootID]
+		if !ok {
+			total = &DepartmentStat{DepartmentID: rootID}
+			totals[rootID] = total
+		}
+		total.DoctorCount += stat.DoctorCount
+		total.AppointmentCount += stat.AppointmentCount
+	}
+
+	result := make([]DepartmentStat, 0, len(totals))
+	for _, dept := range departments {
+		if total, ok := totals[dept.ID]; ok {
+			total.DepartmentName = dept.Name
+			result = append(result, *total)
+		}
+	}
+	return result, nil
+}
+
+// rollupDepartmentRanking 将科室排行汇总到一级科室后重新排序，取前10名
+func (s *StatisticsService) rollupDepartmentRanking(ranking []DepartmentRanking) ([]DepartmentRanking, error) {
+	departments, err := s.deptRepo.ListAllIncludeDisabled()
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	rootIDs := departmentRootIDs(departments)
+
+	names := make(map[int64]string, len(departments))
+	for _, dept := range departments {
+		names[dept.ID] = dept.Name
+	}
+
+	totals := make(map[int64]int64)
+	for _, rank := range ranking {
+		rootID, ok := rootIDs[rank.DepartmentID]
+		if !ok {
+			rootID = rank.DepartmentID
+		}
+		totals[rootID] += rank.AppointmentCount
+	}
+
+	result := make([]DepartmentRanking, 0, len(totals))
+	for id, count := range totals {
+		result = append(result, DepartmentRanking{
+			DepartmentID:     id,
+			DepartmentName:   names[id],
+			AppointmentCount: count,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].AppointmentCount != result[j].AppointmentCount {
+			return result[i].AppointmentCount > result[j].AppointmentCount
+		}
+		return result[i].DepartmentID < result[j].DepartmentID
+	})
+	if len(result) > 10 {
+		result = result[:10]
+	}
+	return result, nil
+}
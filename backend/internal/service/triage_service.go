@@ -0,0 +1,541 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/internal/repository"
+	"huaan-medical/pkg/errorcode"
+	"huaan-medical/pkg/logger"
+)
+
+// TriageService 智能导诊服务
+// 根据管理员维护的症状关键词规则，结合患者年龄/性别为症状描述推荐就诊科室
+type TriageService struct {
+	repo         *repository.TriageRepository
+	deptRepo     *repository.DepartmentRepository
+	scheduleRepo *repository.ScheduleRepository
+	patientRepo  *repository.PatientRepository
+}
+
+// NewTriageService 创建智能导诊服务实例
+func NewTriageService() *TriageService {
+	return &TriageService{
+		repo:         repository.NewTriageRepository(),
+		deptRepo:     repository.NewDepartmentRepository(),
+		scheduleRepo: repository.NewScheduleRepository(),
+		patientRepo:  repository.NewPatientRepository(),
+	}
+}
+
+const (
+	// defaultTriageLimit 默认返回推荐科室数量
+	defaultTriageLimit = 3
+	// triageNoticeMatched 推荐结果提示语
+	triageNoticeMatched = "推荐结果仅供参考，如症状严重或突然加重请立即前往急诊就医"
+	// triageNoticeUnmatched 未匹配到科室时的提示语
+	triageNoticeUnmatched = "暂未能根据症状推荐科室，建议咨询医院导诊台或选择全科医学科就诊"
+)
+
+// triageNegations 否定词（如 "不发烧"、"无明显腹痛"），紧邻关键词之前出现时该处不视为命中
+var triageNegations = []string{"无明显", "没有", "否认", "无", "没", "不", "未"}
+
+// TriageRequest 导诊推荐请求
+type TriageRequest struct {
+	Symptom string `json:"symptom" binding:"required,max=512"`
+	Age     *int   `json:"age" binding:"omitempty,min=0,max=150"`
+	Gender  int    `json:"gender" binding:"omitempty,oneof=0 1 2"`
+	Limit   int    `json:"limit" binding:"omitempty,min=1,max=10"`
+}
+
+// TriageCheckRequest 预约科室匹配检查请求（schedule_id 与 department_id 二选一）
+type TriageCheckRequest struct {
+	PatientID    int64  `json:"patient_id" binding:"required,min=1"`
+	ScheduleID   int64  `json:"schedule_id" binding:"omitempty,min=1"`
+	DepartmentID int64  `json:"department_id" binding:"omitempty,min=1"`
+	Symptom      string `json:"symptom" binding:"required,max=512"`
+}
+
+// CreateTriageRuleRequest 创建导诊规则请求
+type CreateTriageRuleRequest struct {
+	Keywords     []string `json:"keywords" binding:"required,min=1,max=20,dive,required,max=32"`
+	DepartmentID int64    `json:"department_id" binding:"required,min=1"`
+	Weight       int      `json:"weight" binding:"required,min=1,max=100"`
+	MinAge       int      `json:"min_age" binding:"min=0,max=150"`
+	MaxAge       int      `json:"max_age" binding:"min=0,max=150"`
+	Gender       int      `json:"gender" binding:"oneof=0 1 2"`
+	Explanation  string   `json:"explanation" binding:"max=256"`
+	Status       *int     `json:"status"`
+}
+
+// UpdateTriageRuleRequest 更新导诊规则请求
+type UpdateTriageRuleRequest struct {
+	Keywords     []string `json:"keywords" binding:"required,min=1,max=20,dive,required,max=32"`
+	DepartmentID int64    `json:"department_id" binding:"required,min=1"`
+	Weight       int      `json:"weight" binding:"required,min=1,max=100"`
+	MinAge       int      `json:"min_age" binding:"min=0,max=150"`
+	MaxAge       int      `json:"max_age" binding:"min=0,max=150"`
+	Gender       int      `json:"gender" binding:"oneof=0 1 2"`
+	Explanation  string   `json:"explanation" binding:"max=256"`
+	Status       *int     `json:"status"`
+}
+
+// ListTriageRuleRequest 导诊规则列表请求
+type ListTriageRuleRequest struct {
+	Page         int    `form:"page" binding:"required,min=1"`
+	PageSize     int    `form:"page_size" binding:"required,min=1,max=100"`
+	Keyword      string `form:"keyword"`
+	DepartmentID *int64 `form:"department_id"`
+	Status       *int   `form:"status"`
+}
+
+// triageScore 科室得分累计
+type triageScore struct {
+	dept     *model.Department
+	score    int
+	keywords []string
+	reasons  []string
+}
+
+// Recommend 根据症状推荐就诊科室
+func (s *TriageService) Recommend(req *TriageRequest) (*model.TriageResultVO, error) {
+	symptom := strings.TrimSpace(req.Symptom)
+	if symptom == "" {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "请填写症状描述")
+	}
+
+	recommendations, err := s.recommend(symptom, req.Age, req.Gender)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultTriageLimit
+	}
+	if len(recommendations) > limit {
+		recommendations = recommendations[:limit]
+	}
+
+	result := &model.TriageResultVO{
+		Symptom:         symptom,
+		Age:             req.Age,
+		Gender:          req.Gender,
+		Recommendations: recommendations,
+		Notice:          triageNoticeMatched,
+	}
+	if len(recommendations) == 0 {
+		result.Notice = triageNoticeUnmatched
+	}
+	return result, nil
+}
+
+// Check 预约前检查所选科室与症状是否匹配（用户接口）
+func (s *TriageService) Check(userID int64, req *TriageCheckRequest) (*model.TriageCheckVO, error) {
+	patient, err := s.patientRepo.GetByUserAndID(userID, req.PatientID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrPatientNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	departmentID := req.DepartmentID
+	if req.ScheduleID > 0 {
+		schedule, err := s.scheduleRepo.GetByID(req.ScheduleID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errorcode.New(errorcode.ErrScheduleNotFound)
+			}
+			return nil, errorcode.New(errorcode.ErrDatabase)
+		}
+		departmentID = schedule.SessionDepartmentID()
+	}
+	if departmentID == 0 {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "请选择排班或科室")
+	}
+
+	return s.checkDepartment(departmentID, strings.TrimSpace(req.Symptom), patient.KnownAge(), patient.Gender)
+}
+
+// BookingWarning 预约时症状与科室不匹配的提示语（无需提示或检查失败时返回空字符串，不影响预约）
+func (s *TriageService) BookingWarning(patient *model.Patient, departmentID int64, symptom string) string {
+	symptom = strings.TrimSpace(symptom)
+	if patient == nil || departmentID == 0 || symptom == "" {
+		return ""
+	}
+
+	check, err := s.checkDepartment(departmentID, symptom, patient.KnownAge(), patient.Gender)
+	if err != nil {
+		logger.Warn("预约科室导诊检查失败", zap.Error(err), zap.Int64("department_id", departmentID))
+		return ""
+	}
+	if !check.Unlikely {
+		return ""
+	}
+	return check.Message
+}
+
+// ListRules 分页查询导诊规则
+func (s *TriageService) ListRules(req *ListTriageRuleRequest) ([]model.TriageRuleVO, int64, error) {
+	rules, total, err := s.repo.List(req.Page, req.PageSize, strings.TrimSpace(req.Keyword), req.DepartmentID, req.Status)
+	if err != nil {
+		return nil, 0, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	list := make([]model.TriageRuleVO, len(rules))
+	for i := range rules {
+		list[i] = *rules[i].ToVO()
+	}
+	return list, total, nil
+}
+
+// GetRule 获取导诊规则详情
+func (s *TriageService) GetRule(id int64) (*model.TriageRuleVO, error) {
+	rule, err := s.getRule(id)
+	if err != nil {
+		return nil, err
+	}
+	return rule.ToVO(), nil
+}
+
+// CreateRule 创建导诊规则
+func (s *TriageService) CreateRule(req *CreateTriageRuleRequest) (*model.TriageRuleVO, error) {
+	keywords, err := s.validateRule(req.Keywords, req.DepartmentID, req.MinAge, req.MaxAge)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := &model.TriageRule{
+		Keywords:     strings.Join(keywords, ","),
+		DepartmentID: req.DepartmentID,
+		Weight:       req.Weight,
+		MinAge:       req.MinAge,
+		MaxAge:       req.MaxAge,
+		Gender:       req.Gender,
+		Explanation:  strings.TrimSpace(req.Explanation),
+		Status:       model.StatusEnabled,
+	}
+	if req.Status != nil {
+		rule.Status = *req.Status
+	}
+	if err := s.repo.Create(rule); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	return s.GetRule(rule.ID)
+}
+
+// UpdateRule 更新导诊规则
+func (s *TriageService) UpdateRule(id int64, req *UpdateTriageRuleRequest) (*model.TriageRuleVO, error) {
+	rule, err := s.getRule(id)
+	if err != nil {
+		return nil, err
+	}
+
+	keywords, err := s.validateRule(req.Keywords, req.DepartmentID, req.MinAge, req.MaxAge)
+	if err != nil {
+		return nil, err
+	}
+
+	rule.Keywords = strings.Join(keywords, ",")
+	rule.DepartmentID = req.DepartmentID
+	rule.Weight = req.Weight
+	rule.MinAge = req.MinAge
+	rule.MaxAge = req.MaxAge
+	rule.Gender = req.Gender
+	rule.Explanation = strings.TrimSpace(req.Explanation)
+	if req.Status != nil {
+		rule.Status = *req.Status
+	}
+	if err := s.repo.Update(rule); err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	return s.GetRule(id)
+}
+
+// DeleteRule 删除导诊规则
+func (s *TriageService) DeleteRule(id int64) error {
+	if _, err := s.getRule(id); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	return nil
+}
+
+// getRule 查询导诊规则
+func (s *TriageService) getRule(id int64) (*model.TriageRule, error) {
+	rule, err := s.repo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrTriageRuleNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return rule, nil
+}
+
+// validateRule 校验规则参数，返回去重后的关键词
+func (s *TriageService) validateRule(keywords []string, departmentID int64, minAge, maxAge int) ([]string, error) {
+	if maxAge > 0 && minAge > maxAge {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "最小年龄不能大于最大年龄")
+	}
+
+	list := normalizeTriageKeywords(keywords)
+	if len(list) == 0 {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "请填写症状关键词")
+	}
+
+	if _, err := s.deptRepo.GetByID(departmentID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrDepartmentNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return list, nil
+}
+
+// recommend 按启用规则计算各科室得分，返回按得分降序排列的全部推荐科室
+func (s *TriageService) recommend(symptom string, age *int, gender int) ([]model.TriageRecommendationVO, error) {
+	rules, err := s.repo.ListEnabled()
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	eligibility, err := newDepartmentEligibility(s.deptRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	text := normalizeTriageText(symptom)
+	scores := make(map[int64]*triageScore)
+	total := 0
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Department == nil || rule.Department.Status != model.StatusEnabled {
+			continue
+		}
+		if !rule.MatchProfile(age, gender) || !eligibility.eligible(rule.DepartmentID, age, gender) {
+			continue
+		}
+		matched := matchTriageKeywords(text, rule.KeywordList())
+		if len(matched) == 0 {
+			continue
+		}
+
+		item, ok := scores[rule.DepartmentID]
+		if !ok {
+			item = &triageScore{dept: rule.Department}
+			scores[rule.DepartmentID] = item
+		}
+		item.score += rule.Weight
+		total += rule.Weight
+		for _, keyword := range matched {
+			if !containsTriageWord(item.keywords, keyword) {
+				item.keywords = append(item.keywords, keyword)
+			}
+		}
+		item.reasons = append(item.reasons, triageReason(rule, matched))
+	}
+
+	list := make([]*triageScore, 0, len(scores))
+	for _, item := range scores {
+		list = append(list, item)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].score != list[j].score {
+			return list[i].score > list[j].score
+		}
+		if list[i].dept.SortOrder != list[j].dept.SortOrder {
+			return list[i].dept.SortOrder < list[j].dept.SortOrder
+		}
+		return list[i].dept.ID < list[j].dept.ID
+	})
+
+	recommendations := make([]model.TriageRecommendationVO, len(list))
+	for i, item := range list {
+		recommendations[i] = model.TriageRecommendationVO{
+			DepartmentID:    item.dept.ID,
+			DepartmentName:  item.dept.Name,
+			ParentID:        item.dept.ParentID,
+			Score:           item.score,
+			Confidence:      int(math.Round(float64(item.score) * 100 / float64(total))),
+			MatchedKeywords: item.keywords,
+			Reasons:         item.reasons,
+		}
+	}
+	return recommendations, nil
+}
+
+// checkDepartment 检查科室与症状是否匹配
+// 就诊人不符合科室接诊限制时直接提示；所选科室与任一推荐科室相同或存在上下级关系即视为匹配；
+// 症状未命中任何规则时不提示
+func (s *TriageService) checkDepartment(departmentID int64, symptom string, age *int, gender int) (*model.TriageCheckVO, error) {
+	dept, err := s.deptRepo.GetByID(departmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrDepartmentNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	result := &model.TriageCheckVO{
+		DepartmentID:    dept.ID,
+		DepartmentName:  dept.Name,
+		Recommendations: []model.TriageRecommendationVO{},
+	}
+
+	// 不符合科室接诊限制（年龄/性别）时直接提示
+	eligibility, err := newDepartmentEligibility(s.deptRepo)
+	if err != nil {
+		return nil, err
+	}
+	if reason := eligibility.reason(dept.ID, age, gender); reason != "" {
+		result.Unlikely = true
+		result.Message = reason
+		return result, nil
+	}
+	if symptom == "" {
+		return result, nil
+	}
+
+	recommendations, err := s.recommend(symptom, age, gender)
+	if err != nil {
+		return nil, err
+	}
+	if len(recommendations) == 0 {
+		return result, nil
+	}
+
+	parents := make(map[int64]int64, len(eligibility.departments))
+	for id, d := range eligibility.departments {
+		parents[id] = d.ParentID
+	}
+	for _, item := range recommendations {
+		if isRelatedDepartment(parents, departmentID, item.DepartmentID) {
+			return result, nil
+		}
+	}
+
+	if len(recommendations) > defaultTriageLimit {
+		recommendations = recommendations[:defaultTriageLimit]
+	}
+	names := make([]string, len(recommendations))
+	for i, item := range recommendations {
+		names[i] = item.DepartmentName
+	}
+	result.Unlikely = true
+	result.Recommendations = recommendations
+	result.Message = fmt.Sprintf("根据您描述的症状，建议优先考虑%s，当前所选科室为%s，请确认是否继续预约",
+		strings.Join(names, "、"), dept.Name)
+	return result, nil
+}
+
+// isRelatedDepartment 判断两个科室是否相同或存在上下级关系
+func isRelatedDepartment(parents map[int64]int64, a, b int64) bool {
+	return a == b || isAncestorDepartment(parents, a, b) || isAncestorDepartment(parents, b, a)
+}
+
+// isAncestorDepartment 判断 ancestor 是否为 id 的上级科室
+func isAncestorDepartment(parents map[int64]int64, ancestor, id int64) bool {
+	// 限制层级深度，防止脏数据形成循环
+	for depth := 0; depth < 16; depth++ {
+		parentID, ok := parents[id]
+		if !ok || parentID == 0 {
+			return false
+		}
+		if parentID == ancestor {
+			return true
+		}
+		id = parentID
+	}
+	return false
+}
+
+// triageReason 推荐理由
+func triageReason(rule *model.TriageRule, matched []string) string {
+	reason := rule.Explanation
+	if reason == "" {
+		reason = fmt.Sprintf("症状「%s」建议就诊%s", strings.Join(matched, "、"), rule.Department.Name)
+	}
+	if constraint := rule.ConstraintText(); constraint != "不限" {
+		reason += "（适用于" + constraint + "）"
+	}
+	return reason
+}
+
+// matchTriageKeywords 返回症状描述中命中（且未被否定）的关键词
+func matchTriageKeywords(text string, keywords []string) []string {
+	var matched []string
+	for _, keyword := range keywords {
+		normalized := normalizeTriageText(keyword)
+		if normalized != "" && containsAffirmed(text, normalized) {
+			matched = append(matched, keyword)
+		}
+	}
+	return matched
+}
+
+// containsAffirmed 判断文本中是否存在未被否定词修饰的关键词
+func containsAffirmed(text, keyword string) bool {
+	offset := 0
+	for {
+		idx := strings.Index(text[offset:], keyword)
+		if idx < 0 {
+			return false
+		}
+		pos := offset + idx
+		if !hasNegationBefore(text[:pos]) {
+			return true
+		}
+		offset = pos + len(keyword)
+	}
+}
+
+// hasNegationBefore 判断关键词前是否紧邻否定词
+func hasNegationBefore(prefix string) bool {
+	for _, negation := range triageNegations {
+		if strings.HasSuffix(prefix, negation) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeTriageText 症状文本归一化（转小写、去除空白）
+func normalizeTriageText(text string) string {
+	return strings.Join(strings.Fields(strings.ToLower(text)), "")
+}
+
+// normalizeTriageKeywords 关键词去空、去重（保持原有顺序）
+func normalizeTriageKeywords(keywords []string) []string {
+	list := make([]string, 0, len(keywords))
+	for _, keyword := range keywords {
+		keyword = strings.TrimSpace(strings.ReplaceAll(keyword, ",", ""))
+		if keyword == "" || containsTriageWord(list, keyword) {
+			continue
+		}
+		list = append(list, keyword)
+	}
+	return list
+}
+
+// containsTriageWord 判断列表中是否已包含该词
+func containsTriageWord(list []string, word string) bool {
+	for _, item := range list {
+		if item == word {
+			return true
+		}
+	}
+	return false
+}
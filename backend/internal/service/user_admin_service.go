@@ -0,0 +1,238 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"huaan-medical/internal/model"
+	"huaan-medical/internal/repository"
+	"huaan-medical/pkg/errorcode"
+)
+
+const (
+	// userDetailLoginLogLimit 用户详情展示的最近登录记录数
+	userDetailLoginLogLimit = 20
+	// userDetailActionLimit 用户详情展示的最近管理操作数
+	userDetailActionLimit = 20
+)
+
+// UserAdminService 用户账号管理服务（管理后台）
+type UserAdminService struct {
+	userRepo        *repository.UserRepository
+	patientRepo     *repository.PatientRepository
+	appointmentRepo *repository.AppointmentRepository
+	logRepo         *repository.LogRepository
+	sessionRepo     *repository.SessionRepository
+}
+
+// NewUserAdminService 创建用户账号管理服务实例
+func NewUserAdminService() *UserAdminService {
+	return &UserAdminService{
+		userRepo:        repository.NewUserRepository(),
+		patientRepo:     repository.NewPatientRepository(),
+		appointmentRepo: repository.NewAppointmentRepository(),
+		logRepo:         repository.NewLogRepository(),
+		sessionRepo:     repository.NewSessionRepository(),
+	}
+}
+
+// ListAdminUsersRequest 用户账号列表查询请求
+type ListAdminUsersRequest struct {
+	Page     int    `form:"page" binding:"required,min=1"`
+	PageSize int    `form:"page_size" binding:"required,min=1,max=100"`
+	Keyword  string `form:"keyword"`                              // 手机号、用户名或昵称
+	Status   *int   `form:"status" binding:"omitempty,oneof=0 1"` // 账号状态
+	Blocked  bool   `form:"blocked"`                              // 仅显示爽约封禁中的账号
+}
+
+// UserAdminActionRequest 启用/停用/解封用户账号请求
+type UserAdminActionRequest struct {
+	Reason           string `json:"reason" binding:"required,max=256"`
+	ResetMissedCount bool   `json:"reset_missed_count"` // 解封时同时清零爽约次数
+}
+
+// UserAppointmentStats 用户预约统计
+type UserAppointmentStats struct {
+	Total     int64 `json:"total"`
+	Pending   int64 `json:"pending"`
+	Completed int64 `json:"completed"`
+	Cancelled int64 `json:"cancelled"`
+	Missed    int64 `json:"missed"`
+}
+
+// UserAdminDetail 用户账号详情（管理后台）
+type UserAdminDetail struct {
+	User             *model.UserAdminVO         `json:"user"`
+	Patients         []model.PatientVO          `json:"patients"`
+	AppointmentStats *UserAppointmentStats      `json:"appointment_stats"`
+	ActiveSessions   int                        `json:"active_sessions"` // 当前在线设备数
+	LoginLogs        []*model.LoginLogVO        `json:"login_logs"`      // 最近登录记录
+	Actions          []*model.UserAdminActionVO `json:"actions"`         // 最近管理操作
+}
+
+// List 分页查询用户账号
+func (s *UserAdminService) List(req *ListAdminUsersRequest) ([]*model.UserAdminVO, int64, error) {
+	users, total, err := s.userRepo.ListAdmin(req.Page, req.PageSize, req.Keyword, req.Status, req.Blocked, time.Now())
+	if err != nil {
+		return nil, 0, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	list := make([]*model.UserAdminVO, 0, len(users))
+	for i := range users {
+		list = append(list, users[i].ToAdminVO())
+	}
+	return list, total, nil
+}
+
+// GetDetail 查询用户账号详情：账号信息、就诊人、预约统计、登录记录及管理操作记录
+func (s *UserAdminService) GetDetail(id int64) (*UserAdminDetail, error) {
+	user, err := s.getUser(id)
+	if err != nil {
+		return nil, err
+	}
+
+	patients, err := s.patientRepo.ListByUser(id)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	counts, err := s.appointmentRepo.CountByUserGroupStatus(id)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	sessions, err := s.sessionRepo.ListActiveByUser(id, user.TokensRevokedAt, time.Now())
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	logs, err := s.logRepo.ListRecentLoginLogs(model.UserTypeUser, id, userDetailLoginLogLimit)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	actions, err := s.userRepo.ListAdminActions(id, userDetailActionLimit)
+	if err != nil {
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+
+	detail := &UserAdminDetail{
+		User:     user.ToAdminVO(),
+		Patients: make([]model.PatientVO, 0, len(patients)),
+		AppointmentStats: &UserAppointmentStats{
+			Pending:   counts[model.AppointmentStatusPending],
+			Completed: counts[model.AppointmentStatusCompleted],
+			Cancelled: counts[model.AppointmentStatusCancelled],
+			Missed:    counts[model.AppointmentStatusMissed],
+		},
+		ActiveSessions: len(sessions),
+		LoginLogs:      make([]*model.LoginLogVO, 0, len(logs)),
+		Actions:        make([]*model.UserAdminActionVO, 0, len(actions)),
+	}
+	for i := range patients {
+		detail.Patients = append(detail.Patients, *patients[i].ToVO())
+	}
+	for _, count := range counts {
+		detail.AppointmentStats.Total += count
+	}
+	for i := range logs {
+		detail.LoginLogs = append(detail.LoginLogs, logs[i].ToVO())
+	}
+	for i := range actions {
+		detail.Actions = append(detail.Actions, actions[i].ToVO())
+	}
+	return detail, nil
+}
+
+// Enable 启用用户账号
+func (s *UserAdminService) Enable(adminID int64, adminName string, id int64, req *UserAdminActionRequest) (*model.UserAdminVO, error) {
+	user, err := s.getUser(id)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status == model.StatusEnabled {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "账号已是启用状态")
+	}
+
+	updates := map[string]interface{}{"status": model.StatusEnabled}
+	if err := s.applyAction(adminID, adminName, user, model.UserAdminActionEnable, req.Reason, updates); err != nil {
+		return nil, err
+	}
+	return s.reload(id)
+}
+
+// Disable 停用用户账号，已登录的设备立即下线
+func (s *UserAdminService) Disable(adminID int64, adminName string, id int64, req *UserAdminActionRequest) (*model.UserAdminVO, error) {
+	user, err := s.getUser(id)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status == model.StatusDisabled {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "账号已是停用状态")
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":            model.StatusDisabled,
+		"tokens_revoked_at": now,
+	}
+	if err := s.applyAction(adminID, adminName, user, model.UserAdminActionDisable, req.Reason, updates); err != nil {
+		return nil, err
+	}
+	markUserTokensRevoked(id, now)
+	return s.reload(id)
+}
+
+// Unblock 解除爽约封禁，可选同时清零爽约次数
+func (s *UserAdminService) Unblock(adminID int64, adminName string, id int64, req *UserAdminActionRequest) (*model.UserAdminVO, error) {
+	user, err := s.getUser(id)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsBlocked() && !(req.ResetMissedCount && user.MissedCount > 0) {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "账号未被封禁")
+	}
+
+	updates := map[string]interface{}{"blocked_until": nil}
+	if req.ResetMissedCount {
+		updates["missed_count"] = 0
+	}
+	if err := s.applyAction(adminID, adminName, user, model.UserAdminActionUnblock, req.Reason, updates); err != nil {
+		return nil, err
+	}
+	return s.reload(id)
+}
+
+// applyAction 更新账号并记录操作原因
+func (s *UserAdminService) applyAction(adminID int64, adminName string, user *model.User, action, reason string, updates map[string]interface{}) error {
+	record := &model.UserAdminAction{
+		UserID:    user.ID,
+		AdminID:   adminID,
+		AdminName: adminName,
+		Action:    action,
+		Reason:    reason,
+	}
+	if err := s.userRepo.UpdateWithAdminAction(user.ID, updates, record); err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	return nil
+}
+
+// getUser 查询用户账号
+func (s *UserAdminService) getUser(id int64) (*model.User, error) {
+	user, err := s.userRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errorcode.New(errorcode.ErrUserNotFound)
+		}
+		return nil, errorcode.New(errorcode.ErrDatabase)
+	}
+	return user, nil
+}
+
+// reload 重新查询并返回账号信息
+func (s *UserAdminService) reload(id int64) (*model.UserAdminVO, error) {
+	user, err := s.getUser(id)
+	if err != nil {
+		return nil, err
+	}
+	return user.ToAdminVO(), nil
+}
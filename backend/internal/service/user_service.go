@@ -1,7 +1,10 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -10,6 +13,7 @@ import (
 	"huaan-medical/pkg/config"
 	"huaan-medical/pkg/errorcode"
 	"huaan-medical/pkg/jwt"
+	"huaan-medical/pkg/redis"
 	"huaan-medical/pkg/sms"
 	"huaan-medical/pkg/utils"
 	"huaan-medical/pkg/wechat"
@@ -17,13 +21,17 @@ import (
 
 // UserService 用户服务
 type UserService struct {
-	userRepo *repository.UserRepository
+	userRepo       *repository.UserRepository
+	logRepo        *repository.LogRepository
+	sessionService *SessionService
 }
 
 // NewUserService 创建用户服务实例
 func NewUserService() *UserService {
 	return &UserService{
-		userRepo: repository.NewUserRepository(),
+		userRepo:       repository.NewUserRepository(),
+		logRepo:        repository.NewLogRepository(),
+		sessionService: NewSessionService(),
 	}
 }
 
@@ -45,12 +53,12 @@ type WeChatLoginResponse struct {
 type UpdateUserInfoRequest struct {
 	Nickname string `json:"nickname" binding:"max=64"`
 	Avatar   string `json:"avatar" binding:"max=512"`
-	Phone    string `json:"phone" binding:"omitempty,len=11"`
+	Phone    string `json:"phone" binding:"omitempty,len=11"` // 仅允许与当前手机号一致，更换请使用绑定手机号接口
 	Gender   int    `json:"gender" binding:"oneof=0 1 2"`
 }
 
 // WeChatLogin 微信登录
-func (s *UserService) WeChatLogin(req *WeChatLoginRequest, clientIP string) (*WeChatLoginResponse, error) {
+func (s *UserService) WeChatLogin(req *WeChatLoginRequest, clientIP, userAgent string) (*WeChatLoginResponse, error) {
 	// 获取配置
 	cfg, err := config.Load("config.yaml")
 	if err != nil || cfg.WeChat.AppID == "" || cfg.WeChat.AppSecret == "" {
@@ -102,10 +110,10 @@ func (s *UserService) WeChatLogin(req *WeChatLoginRequest, clientIP string) (*We
 		)
 	}
 
-	// 3. 生成JWT Token
-	tokenPair, err := jwt.GenerateTokenPair(user.ID, user.OpenID)
+	// 3. 创建登录会话并生成JWT Token
+	tokenPair, err := s.sessionService.Create(user, model.LoginTypeWeChat, clientIP, userAgent)
 	if err != nil {
-		return nil, errorcode.New(errorcode.ErrInternalServer)
+		return nil, err
 	}
 
 	// 4. 更新登录信息（记录IP地址和登录时间）
@@ -123,6 +131,25 @@ func (s *UserService) WeChatLogin(req *WeChatLoginRequest, clientIP string) (*We
 	}, nil
 }
 
+// RefreshToken 刷新Token（轮换刷新Token，重复使用将吊销整个会话）
+func (s *UserService) RefreshToken(refreshToken, clientIP string) (*jwt.TokenPair, error) {
+	return s.sessionService.Refresh(refreshToken, clientIP)
+}
+
+// markUserTokensRevoked 吊销用户全部登录会话，并在Redis中标记用户Token吊销时间，保留至最长的刷新Token有效期
+func markUserTokensRevoked(userID int64, now time.Time) {
+	revokeUserSessions(userID, now)
+	if !redis.IsEnabled() {
+		return
+	}
+
+	ttl := 7 * 24 * time.Hour
+	if cfg := config.Get(); cfg != nil && cfg.JWT.RefreshTokenExpire > 0 {
+		ttl = cfg.JWT.RefreshTokenExpire
+	}
+	_ = redis.Set(context.Background(), fmt.Sprintf(redis.KeyUserTokenRevoked, userID), now.Unix(), ttl)
+}
+
 // GetUserInfo 获取用户信息
 func (s *UserService) GetUserInfo(userID int64) (*model.UserVO, error) {
 	user, err := s.userRepo.GetByID(userID)
@@ -157,17 +184,9 @@ func (s *UserService) UpdateUserInfo(userID int64, req *UpdateUserInfoRequest) (
 		return nil, errorcode.New(errorcode.ErrAccountDisabled)
 	}
 
-	// 如果要绑定手机号，需要验证格式
-	if req.Phone != "" {
-		if !utils.ValidatePhone(req.Phone) {
-			return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "手机号格式错误")
-		}
-
-		// 检查手机号是否已被其他用户绑定
-		existingUser, err := s.userRepo.GetByPhone(req.Phone)
-		if err == nil && existingUser.ID != userID {
-			return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "该手机号已被其他用户绑定")
-		}
+	// 手机号是登录方式，需通过短信验证绑定，不允许直接修改
+	if req.Phone != "" && req.Phone != user.Phone {
+		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "请通过绑定手机号功能验证后修改手机号")
 	}
 
 	// 更新用户信息
@@ -177,9 +196,6 @@ func (s *UserService) UpdateUserInfo(userID int64, req *UpdateUserInfoRequest) (
 	if req.Avatar != "" {
 		user.Avatar = req.Avatar
 	}
-	if req.Phone != "" {
-		user.Phone = req.Phone
-	}
 	user.Gender = req.Gender
 
 	if err := s.userRepo.Update(user); err != nil {
@@ -198,7 +214,7 @@ func (s *UserService) UpdateUserInfo(userID int64, req *UpdateUserInfoRequest) (
 // RegisterRequest 注册请求
 type RegisterRequest struct {
 	Username        string `json:"username" binding:"required,min=4,max=20"`
-	Password        string `json:"password" binding:"required,min=6,max=20"`
+	Password        string `json:"password" binding:"required,min=8,max=20"`
 	ConfirmPassword string `json:"confirm_password" binding:"required,eqfield=Password"`
 	Nickname        string `json:"nickname" binding:"max=64"`
 }
@@ -225,7 +241,7 @@ type UserLoginResponse struct {
 }
 
 // Register 用户名注册
-func (s *UserService) Register(req *RegisterRequest, clientIP string) (*UserLoginResponse, error) {
+func (s *UserService) Register(req *RegisterRequest, clientIP, userAgent string) (*UserLoginResponse, error) {
 	// 1. 验证用户名格式（字母数字下划线）
 	if !utils.ValidateUsername(req.Username) {
 		return nil, errorcode.New(errorcode.ErrUsernameInvalid)
@@ -240,7 +256,10 @@ func (s *UserService) Register(req *RegisterRequest, clientIP string) (*UserLogi
 		return nil, errorcode.New(errorcode.ErrUsernameExists)
 	}
 
-	// 3. 加密密码
+	// 3. 校验密码强度并加密
+	if !utils.ValidatePasswordStrength(req.Password) {
+		return nil, errorcode.New(errorcode.ErrPasswordWeak)
+	}
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
 		return nil, errorcode.New(errorcode.ErrInternalServer)
@@ -262,10 +281,10 @@ func (s *UserService) Register(req *RegisterRequest, clientIP string) (*UserLogi
 		return nil, errorcode.New(errorcode.ErrDatabase)
 	}
 
-	// 5. 生成Token
-	tokenPair, err := jwt.GenerateTokenPair(user.ID, "")
+	// 5. 创建登录会话并生成Token
+	tokenPair, err := s.sessionService.Create(user, model.LoginTypePassword, clientIP, userAgent)
 	if err != nil {
-		return nil, errorcode.New(errorcode.ErrInternalServer)
+		return nil, err
 	}
 
 	// 6. 更新登录信息
@@ -284,7 +303,7 @@ func (s *UserService) Register(req *RegisterRequest, clientIP string) (*UserLogi
 }
 
 // PasswordLogin 密码登录
-func (s *UserService) PasswordLogin(req *PasswordLoginRequest, clientIP string) (*UserLoginResponse, error) {
+func (s *UserService) PasswordLogin(req *PasswordLoginRequest, clientIP, userAgent string) (*UserLoginResponse, error) {
 	// 1. 根据用户名查询用户
 	user, err := s.userRepo.GetByUsername(req.Username)
 	if err != nil {
@@ -296,6 +315,7 @@ func (s *UserService) PasswordLogin(req *PasswordLoginRequest, clientIP string)
 
 	// 2. 验证密码
 	if !utils.CheckPassword(req.Password, user.Password) {
+		s.writeLoginLog(user, clientIP, userAgent, model.LoginStatusFailed, "密码错误")
 		return nil, errorcode.New(errorcode.ErrPasswordWrong)
 	}
 
@@ -312,10 +332,10 @@ func (s *UserService) PasswordLogin(req *PasswordLoginRequest, clientIP string)
 		)
 	}
 
-	// 5. 生成Token
-	tokenPair, err := jwt.GenerateTokenPair(user.ID, "")
+	// 5. 创建登录会话并生成Token
+	tokenPair, err := s.sessionService.Create(user, model.LoginTypePassword, clientIP, userAgent)
 	if err != nil {
-		return nil, errorcode.New(errorcode.ErrInternalServer)
+		return nil, err
 	}
 
 	// 6. 更新登录信息
@@ -334,7 +354,7 @@ func (s *UserService) PasswordLogin(req *PasswordLoginRequest, clientIP string)
 }
 
 // PhoneLogin 手机号验证码登录
-func (s *UserService) PhoneLogin(req *PhoneLoginRequest, clientIP string) (*UserLoginResponse, error) {
+func (s *UserService) PhoneLogin(req *PhoneLoginRequest, clientIP, userAgent string) (*UserLoginResponse, error) {
 	// 1. 验证手机号格式
 	if !utils.ValidatePhone(req.Phone) {
 		return nil, errorcode.NewWithMessage(errorcode.ErrInvalidParams, "手机号格式错误")
@@ -381,10 +401,10 @@ func (s *UserService) PhoneLogin(req *PhoneLoginRequest, clientIP string) (*User
 		)
 	}
 
-	// 6. 生成Token
-	tokenPair, err := jwt.GenerateTokenPair(user.ID, "")
+	// 6. 创建登录会话并生成Token
+	tokenPair, err := s.sessionService.Create(user, model.LoginTypePhone, clientIP, userAgent)
 	if err != nil {
-		return nil, errorcode.New(errorcode.ErrInternalServer)
+		return nil, err
 	}
 
 	// 7. 更新登录信息
@@ -401,3 +421,121 @@ func (s *UserService) PhoneLogin(req *PhoneLoginRequest, clientIP string) (*User
 		IsNew:        isNew,
 	}, nil
 }
+
+// ChangeUserPasswordRequest 修改密码请求
+type ChangeUserPasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8,max=20"`
+}
+
+// ResetUserPasswordRequest 短信验证码重置密码请求
+type ResetUserPasswordRequest struct {
+	Phone       string `json:"phone" binding:"required,len=11"`
+	Code        string `json:"code" binding:"required,len=6"`
+	NewPassword string `json:"new_password" binding:"required,min=8,max=20"`
+}
+
+// ChangePassword 修改密码（校验原密码），成功后吊销所有已签发的Token，需重新登录
+func (s *UserService) ChangePassword(userID int64, req *ChangeUserPasswordRequest, clientIP, userAgent string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errorcode.New(errorcode.ErrUserNotFound)
+		}
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if user.Status == model.StatusDisabled {
+		return errorcode.New(errorcode.ErrAccountDisabled)
+	}
+	if !user.HasPasswordLogin() {
+		return errorcode.New(errorcode.ErrPasswordNotSet)
+	}
+
+	if !utils.CheckPassword(req.OldPassword, user.Password) {
+		s.writeLoginLog(user, clientIP, userAgent, model.LoginStatusFailed, "修改密码失败：原密码错误")
+		return errorcode.New(errorcode.ErrPasswordWrong)
+	}
+	if req.NewPassword == req.OldPassword {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "新密码不能与原密码相同")
+	}
+
+	if err := s.updatePassword(user, req.NewPassword); err != nil {
+		return err
+	}
+	s.writeLoginLog(user, clientIP, userAgent, model.LoginStatusSuccess, "修改密码")
+	return nil
+}
+
+// ResetPassword 通过绑定手机号的短信验证码重置密码，成功后吊销所有已签发的Token
+func (s *UserService) ResetPassword(req *ResetUserPasswordRequest, clientIP, userAgent string) error {
+	if !utils.ValidatePhone(req.Phone) {
+		return errorcode.NewWithMessage(errorcode.ErrInvalidParams, "手机号格式错误")
+	}
+
+	if err := sms.GetService().VerifyCode(req.Phone, req.Code); err != nil {
+		return errorcode.NewWithMessage(errorcode.ErrSMSCodeInvalid, err.Error())
+	}
+
+	user, err := s.userRepo.GetByPhone(req.Phone)
+	if err != nil {
+		// 与验证码错误返回相同结果，避免据此探测手机号是否已注册
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errorcode.New(errorcode.ErrSMSCodeInvalid)
+		}
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	if user.Status == model.StatusDisabled {
+		return errorcode.New(errorcode.ErrAccountDisabled)
+	}
+	// 未设置用户名的账号只能使用验证码登录，应通过设置用户名密码功能开通密码登录
+	if user.Username == "" {
+		return errorcode.NewWithMessage(errorcode.ErrPasswordNotSet, "该账号未设置用户名，请登录后设置用户名和密码")
+	}
+
+	if err := s.updatePassword(user, req.NewPassword); err != nil {
+		return err
+	}
+	s.writeLoginLog(user, clientIP, userAgent, model.LoginStatusSuccess, "短信验证码重置密码")
+	return nil
+}
+
+// updatePassword 校验强度后写入新密码，并吊销该用户此前签发的所有Token
+func (s *UserService) updatePassword(user *model.User, password string) error {
+	if !utils.ValidatePasswordStrength(password) {
+		return errorcode.New(errorcode.ErrPasswordWeak)
+	}
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return errorcode.New(errorcode.ErrInternalServer)
+	}
+
+	now := time.Now()
+	if err := s.userRepo.UpdatePassword(user.ID, hashedPassword, now); err != nil {
+		return errorcode.New(errorcode.ErrDatabase)
+	}
+	markUserTokensRevoked(user.ID, now)
+	return nil
+}
+
+// writeLoginLog 记录用户密码登录失败、改密等账号安全相关的登录日志
+func (s *UserService) writeLoginLog(user *model.User, ip, userAgent string, status int, msg string) {
+	if s.logRepo == nil {
+		return
+	}
+
+	device := userAgent
+	if len(device) > 256 {
+		device = device[:256]
+	}
+
+	_ = s.logRepo.CreateLoginLog(&model.LoginLog{
+		UserType:  model.UserTypeUser,
+		UserID:    user.ID,
+		Username:  user.Username,
+		LoginType: model.LoginTypePassword,
+		IP:        ip,
+		Device:    device,
+		Status:    status,
+		Message:   msg,
+	})
+}
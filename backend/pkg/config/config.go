@@ -15,6 +15,7 @@ type Config struct {
 	JWT       JWTConfig       `mapstructure:"jwt"`
 	WeChat    WeChatConfig    `mapstructure:"wechat"`
 	SMS       SMSConfig       `mapstructure:"sms"`
+	Identity  IdentityConfig  `mapstructure:"identity"`
 	Log       LogConfig       `mapstructure:"log"`
 	Business  BusinessConfig  `mapstructure:"business"`
 	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
@@ -93,6 +94,12 @@ type SMSConfig struct {
 	Provider string `mapstructure:"provider"` // console | disabled | (预留第三方服务商)
 }
 
+// IdentityConfig 实名核验配置
+type IdentityConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Provider string `mapstructure:"provider"` // mock | disabled | (预留第三方服务商)
+}
+
 // LogConfig 日志配置
 type LogConfig struct {
 	Level      string `mapstructure:"level"`
@@ -108,6 +115,10 @@ type BusinessConfig struct {
 	Appointment AppointmentConfig `mapstructure:"appointment"`
 	Schedule    ScheduleConfig    `mapstructure:"schedule"`
 	Checkin     CheckinConfig     `mapstructure:"checkin"`
+	Review      ReviewConfig      `mapstructure:"review"`
+	Doctor      DoctorConfig      `mapstructure:"doctor"`
+	Patient     PatientConfig     `mapstructure:"patient"`
+	Account     AccountConfig     `mapstructure:"account"`
 }
 
 // AppointmentConfig 预约规则配置
@@ -128,6 +139,8 @@ type ScheduleConfig struct {
 	SlotDuration   int    `mapstructure:"slot_duration"`
 	MorningSlots   int    `mapstructure:"morning_slots"`
 	AfternoonSlots int    `mapstructure:"afternoon_slots"`
+
+	QuotaRolloverMinutes int `mapstructure:"quota_rollover_minutes"` // 就诊开始前N分钟将未用完的现场/VIP预留号源转入公共池
 }
 
 // CheckinConfig 签到规则配置
@@ -136,6 +149,30 @@ type CheckinConfig struct {
 	LateMinutes  int `mapstructure:"late_minutes"`
 }
 
+// ReviewConfig 就诊评价规则配置
+type ReviewConfig struct {
+	WindowDays int `mapstructure:"window_days"` // 就诊完成后N天内可评价
+}
+
+// DoctorConfig 医生资料规则配置
+type DoctorConfig struct {
+	ExpiryAlertDays int `mapstructure:"expiry_alert_days"` // 执业证书/资格证书到期前N天提醒
+}
+
+// PatientConfig 就诊人规则配置
+type PatientConfig struct {
+	InvitationExpireHours int `mapstructure:"invitation_expire_hours"` // 共享邀请有效期（小时）
+	MaxMembers            int `mapstructure:"max_members"`             // 每个就诊人最多共享成员数（不含所有者）
+	ConsentValidDays      int `mapstructure:"consent_valid_days"`      // 成年家人病历查看授权有效期（天）
+}
+
+// AccountConfig 账号个人信息规则配置
+type AccountConfig struct {
+	DeletionCoolingDays int    `mapstructure:"deletion_cooling_days"` // 注销冷静期（天），期间可撤回
+	ExportExpireHours   int    `mapstructure:"export_expire_hours"`   // 个人数据导出文件下载有效期（小时）
+	ExportDir           string `mapstructure:"export_dir"`            // 导出文件存放目录（不对外提供静态访问）
+}
+
 // RateLimitConfig 限流配置
 type RateLimitConfig struct {
 	Enabled           bool `mapstructure:"enabled"`
@@ -201,6 +238,10 @@ func setDefaults() {
 	viper.SetDefault("sms.enabled", false)
 	viper.SetDefault("sms.provider", "disabled")
 
+	// 实名核验默认配置（默认关闭）
+	viper.SetDefault("identity.enabled", false)
+	viper.SetDefault("identity.provider", "disabled")
+
 	// 日志默认配置
 	viper.SetDefault("log.level", "info")
 	viper.SetDefault("log.filename", "logs/app.log")
@@ -227,6 +268,17 @@ func setDefaults() {
 	viper.SetDefault("business.checkin.early_minutes", 30)
 	viper.SetDefault("business.checkin.late_minutes", 15)
 
+	viper.SetDefault("business.review.window_days", 30)
+
+	viper.SetDefault("business.doctor.expiry_alert_days", 30)
+
+	viper.SetDefault("business.patient.invitation_expire_hours", 72)
+	viper.SetDefault("business.patient.max_members", 5)
+
+	viper.SetDefault("business.account.deletion_cooling_days", 15)
+	viper.SetDefault("business.account.export_expire_hours", 72)
+	viper.SetDefault("business.account.export_dir", "exports")
+
 	// 限流默认配置
 	viper.SetDefault("rate_limit.enabled", true)
 	viper.SetDefault("rate_limit.requests_per_second", 100)
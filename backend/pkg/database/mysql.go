@@ -64,6 +64,11 @@ func GetDB() *gorm.DB {
 	return db
 }
 
+// SetDB 替换数据库实例（仅供测试注入不连接真实数据库的实例）
+func SetDB(instance *gorm.DB) {
+	db = instance
+}
+
 // Close 关闭数据库连接
 func Close() error {
 	if db != nil {
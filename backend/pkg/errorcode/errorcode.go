@@ -38,6 +38,7 @@ const (
 	ErrForbidden         = 403001 // 无权限访问
 	ErrPermissionDenied  = 403002 // 权限不足
 	ErrResourceForbidden = 403003 // 资源禁止访问
+	ErrAgreementConsentRequired = 403004 // 需同意最新版协议
 
 	// 资源不存在 404xxx
 	ErrNotFound           = 404001 // 资源不存在
@@ -49,6 +50,20 @@ const (
 	ErrAppointmentNotFound = 404007 // 预约不存在
 	ErrRecordNotFound     = 404008 // 就诊记录不存在
 	ErrAdminNotFound      = 404009 // 管理员不存在
+	ErrDoctorAccountNotFound = 404010 // 医生账号不存在
+	ErrDoctorLeaveNotFound   = 404011 // 停诊申请不存在
+	ErrReviewNotFound        = 404012 // 评价不存在
+	ErrTriageRuleNotFound    = 404013 // 导诊规则不存在
+	ErrCampusNotFound        = 404014 // 院区不存在
+	ErrRoomNotFound          = 404015 // 诊室不存在
+	ErrSpecialtyTagNotFound  = 404016 // 擅长领域标签不存在
+	ErrPatientInvitationNotFound = 404017 // 就诊人共享邀请不存在
+	ErrPatientDuplicateNotFound  = 404018 // 疑似重复记录不存在
+	ErrPatientMergeNotFound      = 404019 // 就诊人合并记录不存在
+	ErrDataExportNotFound        = 404020 // 数据导出记录不存在
+	ErrAccountDeletionNotFound   = 404021 // 注销申请不存在
+	ErrSessionNotFound           = 404022 // 登录设备不存在
+	ErrAgreementNotFound         = 404023 // 协议不存在
 
 	// 业务错误 - 用户相关 410xxx
 	ErrPhoneExists        = 410001 // 手机号已存在
@@ -61,6 +76,24 @@ const (
 	ErrSMSCodeInvalid          = 410008 // 验证码错误
 	ErrSMSCodeExpired          = 410009 // 验证码已过期
 	ErrSMSCodeSendTooFrequent  = 410010 // 验证码发送频繁
+	ErrIDDocumentInvalid       = 410011 // 证件号码无效
+	ErrIdentityMismatch        = 410012 // 实名核验不一致
+	ErrPatientInvitationInvalid = 410013 // 共享邀请无效或已失效
+	ErrPatientMemberExists      = 410014 // 已是就诊人共享成员
+	ErrDataExportInProgress     = 410015 // 已有进行中的数据导出
+	ErrDataExportUnavailable    = 410016 // 导出文件未生成或已过期
+	ErrAccountDeletionPending   = 410017 // 账号已在注销冷静期
+	ErrAccountHasPendingAppt    = 410018 // 存在待就诊预约，无法注销
+	ErrLoginAlreadyBound        = 410019 // 登录方式已绑定
+	ErrLastLoginMethod          = 410020 // 不能解绑唯一的登录方式
+	ErrAccountMergeInvalid      = 410021 // 账号合并凭证无效或已过期
+	ErrPasswordWeak             = 410022 // 密码强度不足
+	ErrPasswordNotSet           = 410023 // 账号未设置密码
+	ErrPatientSelfExists        = 410024 // 已添加本人就诊人
+	ErrPatientLocked            = 410025 // 已成年子女资料只读
+	ErrRecordConsentRequired    = 410026 // 查看成年家人病历需本人授权
+	ErrPatientTransferInvalid   = 410027 // 就诊人无法转交
+	ErrPatientSelfUnverified    = 410028 // 本人就诊人未通过实名核验
 
 	// 业务错误 - 预约相关 420xxx
 	ErrScheduleUnavailable     = 420001 // 该时段不可预约
@@ -85,12 +118,37 @@ const (
 	ErrScheduleHasAppt      = 430002 // 排班下有预约，无法删除
 	ErrInvalidSchedulePeriod = 430003 // 无效的排班时段
 	ErrScheduleDatePassed   = 430004 // 排班日期已过
+	ErrScheduleNotReleased  = 430005 // 排班尚未放号
+	ErrScheduleSwapNotFound = 430006 // 换班申请不存在
+	ErrScheduleSwapStatus   = 430007 // 换班申请状态不允许该操作
 
 	// 业务错误 - 科室/医生相关 440xxx
 	ErrDepartmentHasDoctor = 440001 // 科室下有医生，无法删除
 	ErrDepartmentDisabled  = 440002 // 科室已停用
 	ErrDoctorDisabled      = 440003 // 医生已停诊
 	ErrDoctorHasSchedule   = 440004 // 医生有排班，无法删除
+	ErrDepartmentHasChild  = 440005 // 科室下有子科室，无法删除
+	ErrDepartmentParent    = 440006 // 上级科室无效（不存在或形成循环）
+	ErrDoctorAccountExists = 440007 // 医生已开通账号
+	ErrDoctorLeaveStatus   = 440008 // 停诊申请状态不允许该操作
+	ErrDoctorLeaveConflict = 440009 // 停诊时间与已有申请重叠
+	ErrDepartmentIneligible = 440010 // 就诊人不符合科室接诊限制
+	ErrCampusInUse          = 440011 // 院区下有科室或诊室，无法删除
+	ErrCampusDisabled       = 440012 // 院区已停用
+	ErrRoomCampusMismatch   = 440013 // 诊室与科室不属于同一院区
+
+	// 业务错误 - 就诊记录相关 450xxx
+	ErrRecordSigned            = 450001 // 病历已签署，不可直接修改
+	ErrRecordNotSigned         = 450002 // 病历未签署，无需补充更正
+	ErrRecordAppointmentStatus = 450003 // 预约状态不允许书写病历
+	ErrRecordNoChange          = 450004 // 补充更正没有变更内容
+
+	// 业务错误 - 就诊评价相关 460xxx
+	ErrReviewExists     = 460001 // 该预约已评价
+	ErrReviewNotAllowed = 460002 // 预约未完成，不能评价
+	ErrReviewExpired    = 460003 // 已超过评价期限
+	ErrReviewTagInvalid = 460004 // 评价标签无效
+	ErrReviewHidden     = 460005 // 评价已屏蔽
 
 	// 服务端错误 500xxx
 	ErrInternalServer = 500001 // 服务器内部错误
@@ -129,6 +187,7 @@ var codeMessages = map[int]string{
 	ErrForbidden:         "无权访问",
 	ErrPermissionDenied:  "权限不足",
 	ErrResourceForbidden: "资源禁止访问",
+	ErrAgreementConsentRequired: "协议已更新，请阅读并同意最新版本后继续使用",
 
 	// 资源不存在
 	ErrNotFound:           "资源不存在",
@@ -140,6 +199,20 @@ var codeMessages = map[int]string{
 	ErrAppointmentNotFound: "预约不存在",
 	ErrRecordNotFound:     "就诊记录不存在",
 	ErrAdminNotFound:      "管理员不存在",
+	ErrDoctorAccountNotFound: "医生账号不存在",
+	ErrDoctorLeaveNotFound:   "停诊申请不存在",
+	ErrReviewNotFound:        "评价不存在",
+	ErrTriageRuleNotFound:    "导诊规则不存在",
+	ErrCampusNotFound:        "院区不存在",
+	ErrRoomNotFound:          "诊室不存在",
+	ErrSpecialtyTagNotFound:  "擅长领域标签不存在",
+	ErrPatientInvitationNotFound: "共享邀请不存在",
+	ErrPatientDuplicateNotFound:  "疑似重复记录不存在",
+	ErrPatientMergeNotFound:      "合并记录不存在",
+	ErrDataExportNotFound:        "数据导出记录不存在",
+	ErrAccountDeletionNotFound:   "没有进行中的注销申请",
+	ErrSessionNotFound:           "登录设备不存在",
+	ErrAgreementNotFound:         "协议不存在",
 
 	// 用户相关
 	ErrPhoneExists:        "手机号已被使用",
@@ -152,6 +225,24 @@ var codeMessages = map[int]string{
 	ErrSMSCodeInvalid:          "验证码错误",
 	ErrSMSCodeExpired:          "验证码已过期，请重新获取",
 	ErrSMSCodeSendTooFrequent:  "验证码发送过于频繁，请稍后再试",
+	ErrIDDocumentInvalid:       "证件号码无效",
+	ErrIdentityMismatch:        "姓名与证件号码不一致，请核对后重试",
+	ErrPatientInvitationInvalid: "邀请码无效或已失效",
+	ErrPatientMemberExists:      "您已是该就诊人的共享成员",
+	ErrDataExportInProgress:     "已有正在生成的数据导出，请稍后再试",
+	ErrDataExportUnavailable:    "导出文件尚未生成或已过期",
+	ErrAccountDeletionPending:   "账号已申请注销，正在冷静期中",
+	ErrAccountHasPendingAppt:    "存在待就诊的预约，请就诊或取消后再申请注销",
+	ErrLoginAlreadyBound:        "该登录方式已绑定",
+	ErrLastLoginMethod:          "账号至少需要保留一种登录方式",
+	ErrAccountMergeInvalid:      "合并凭证无效或已过期，请重新验证",
+	ErrPasswordWeak:             "密码需为8-20位，且同时包含字母和数字",
+	ErrPasswordNotSet:           "账号未设置密码登录",
+	ErrPatientSelfExists:        "每个账号只能添加一位本人就诊人",
+	ErrPatientLocked:            "该就诊人已成年，资料仅可查看，如需修改请转交本人管理",
+	ErrRecordConsentRequired:    "该就诊人已成年，查看病历需经本人授权",
+	ErrPatientTransferInvalid:   "该就诊人暂不能转交",
+	ErrPatientSelfUnverified:    "本人就诊人需通过实名核验（姓名、证件号码与手机号一致）",
 
 	// 预约相关
 	ErrScheduleUnavailable:     "该时段暂不可预约",
@@ -176,12 +267,37 @@ var codeMessages = map[int]string{
 	ErrScheduleHasAppt:      "该排班下有预约记录，无法删除",
 	ErrInvalidSchedulePeriod: "无效的排班时段",
 	ErrScheduleDatePassed:   "排班日期已过",
+	ErrScheduleNotReleased:  "该排班尚未放号",
+	ErrScheduleSwapNotFound: "换班申请不存在",
+	ErrScheduleSwapStatus:   "当前换班申请状态不允许该操作",
 
 	// 科室/医生相关
 	ErrDepartmentHasDoctor: "该科室下有医生，请先处理医生信息",
 	ErrDepartmentDisabled:  "该科室已停用",
 	ErrDoctorDisabled:      "该医生已停诊",
 	ErrDoctorHasSchedule:   "该医生有排班记录，无法删除",
+	ErrDepartmentHasChild:  "该科室下有子科室，请先移动或删除子科室",
+	ErrDepartmentParent:    "上级科室无效，不能选择自身或下级科室",
+	ErrDoctorAccountExists: "该医生已开通账号",
+	ErrDoctorLeaveStatus:   "当前停诊申请状态不允许该操作",
+	ErrDoctorLeaveConflict: "该时间段已有停诊申请",
+	ErrDepartmentIneligible: "就诊人不符合该科室的接诊条件",
+	ErrCampusInUse:          "该院区下有科室或诊室，请先迁移或删除",
+	ErrCampusDisabled:       "该院区已停用",
+	ErrRoomCampusMismatch:   "诊室与出诊科室不属于同一院区",
+
+	// 就诊记录相关
+	ErrRecordSigned:            "病历已签署，如需修改请提交补充更正",
+	ErrRecordNotSigned:         "病历尚未签署，请直接编辑",
+	ErrRecordAppointmentStatus: "患者未签到，暂不能书写病历",
+	ErrRecordNoChange:          "补充更正内容与原病历一致",
+
+	// 就诊评价相关
+	ErrReviewExists:     "该预约已评价，不能重复评价",
+	ErrReviewNotAllowed: "就诊完成后才能评价",
+	ErrReviewExpired:    "已超过评价期限",
+	ErrReviewTagInvalid: "评价标签无效",
+	ErrReviewHidden:     "该评价已被屏蔽",
 
 	// 服务端错误
 	ErrInternalServer: "服务器开小差了，请稍后再试",
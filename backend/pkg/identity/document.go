@@ -0,0 +1,93 @@
+package identity
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// 证件类型
+const (
+	DocTypeIDCard        = "id_card"         // 居民身份证
+	DocTypePassport      = "passport"        // 护照
+	DocTypeHKMacauPermit = "hk_macau_permit" // 港澳居民来往内地通行证
+)
+
+// 性别（与 model.GenderMale / model.GenderFemale 取值一致）
+const (
+	GenderMale   = 1
+	GenderFemale = 2
+)
+
+var (
+	passportPattern      = regexp.MustCompile(`^[A-Z0-9]{5,17}$`)
+	hkMacauPermitPattern = regexp.MustCompile(`^[HM]\d{8}(\d{2})?$`)
+)
+
+// DocTypeName 证件类型名称
+func DocTypeName(docType string) string {
+	switch docType {
+	case DocTypeIDCard, "":
+		return "居民身份证"
+	case DocTypePassport:
+		return "护照"
+	case DocTypeHKMacauPermit:
+		return "港澳居民来往内地通行证"
+	default:
+		return "其他证件"
+	}
+}
+
+// IsSupportedDocType 是否为支持的证件类型
+func IsSupportedDocType(docType string) bool {
+	switch docType {
+	case DocTypeIDCard, DocTypePassport, DocTypeHKMacauPermit:
+		return true
+	}
+	return false
+}
+
+// NormalizeNumber 规范化证件号码（去除空白、转大写）
+func NormalizeNumber(number string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(number), ""))
+}
+
+// ValidateDocument 校验证件号码，返回规范化后的号码
+// 居民身份证按 GB 11643 校验地区码、出生日期及校验码；其他证件仅校验格式
+func ValidateDocument(docType, number string) (string, error) {
+	number = NormalizeNumber(number)
+	switch docType {
+	case DocTypeIDCard, "":
+		info, err := ParseIDCard(number)
+		if err != nil {
+			return "", err
+		}
+		return info.Number, nil
+	case DocTypePassport:
+		if !passportPattern.MatchString(number) {
+			return "", errors.New("护照号码格式错误")
+		}
+		return number, nil
+	case DocTypeHKMacauPermit:
+		if !hkMacauPermitPattern.MatchString(number) {
+			return "", errors.New("港澳居民来往内地通行证号码格式错误")
+		}
+		return number, nil
+	default:
+		return "", fmt.Errorf("不支持的证件类型: %s", docType)
+	}
+}
+
+// AgeAt 计算在指定日期的周岁年龄
+func AgeAt(birth, t time.Time) int {
+	age := t.Year() - birth.Year()
+	if t.Month() < birth.Month() || (t.Month() == birth.Month() && t.Day() < birth.Day()) {
+		age--
+	}
+	if age < 0 {
+		return 0
+	}
+	return age
+}
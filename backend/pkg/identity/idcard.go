@@ -0,0 +1,107 @@
+package identity
+
+import (
+	"errors"
+	"time"
+)
+
+// IDCardInfo 居民身份证号码解析结果
+type IDCardInfo struct {
+	Number     string    // 规范化后的号码（校验码 X 大写）
+	RegionCode string    // 6位行政区划代码
+	Province   string    // 省级行政区名称
+	BirthDate  time.Time // 出生日期
+	Gender     int       // 性别 1男 2女
+}
+
+// BirthDateString 出生日期（YYYY-MM-DD）
+func (i *IDCardInfo) BirthDateString() string {
+	return i.BirthDate.Format("2006-01-02")
+}
+
+// Age 当前周岁年龄
+func (i *IDCardInfo) Age() int {
+	return AgeAt(i.BirthDate, time.Now())
+}
+
+// GB 11643-1999 校验码加权因子及校验码对照表
+var (
+	checksumWeights = [17]int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	checksumCodes   = [11]byte{'1', '0', 'X', '9', '8', '7', '6', '5', '4', '3', '2'}
+)
+
+// provinceCodes 省级行政区划代码（GB/T 2260），81/82/83 为港澳台居民居住证
+var provinceCodes = map[string]string{
+	"11": "北京市", "12": "天津市", "13": "河北省", "14": "山西省", "15": "内蒙古自治区",
+	"21": "辽宁省", "22": "吉林省", "23": "黑龙江省",
+	"31": "上海市", "32": "江苏省", "33": "浙江省", "34": "安徽省", "35": "福建省", "36": "江西省", "37": "山东省",
+	"41": "河南省", "42": "湖北省", "43": "湖南省", "44": "广东省", "45": "广西壮族自治区", "46": "海南省",
+	"50": "重庆市", "51": "四川省", "52": "贵州省", "53": "云南省", "54": "西藏自治区",
+	"61": "陕西省", "62": "甘肃省", "63": "青海省", "64": "宁夏回族自治区", "65": "新疆维吾尔自治区",
+	"71": "台湾省", "81": "香港特别行政区", "82": "澳门特别行政区", "83": "台湾地区",
+}
+
+// minBirthDate 最早出生日期
+var minBirthDate = time.Date(1900, 1, 1, 0, 0, 0, 0, time.Local)
+
+// ParseIDCard 按 GB 11643 校验并解析18位居民身份证号码
+// 校验内容：格式、省级地区码、出生日期（真实日期且不晚于今天）、校验码
+func ParseIDCard(number string) (*IDCardInfo, error) {
+	number = NormalizeNumber(number)
+	if len(number) != 18 {
+		return nil, errors.New("身份证号码应为18位")
+	}
+	for i := 0; i < 17; i++ {
+		if number[i] < '0' || number[i] > '9' {
+			return nil, errors.New("身份证号码格式错误")
+		}
+	}
+	if last := number[17]; (last < '0' || last > '9') && last != 'X' {
+		return nil, errors.New("身份证号码格式错误")
+	}
+
+	province, ok := provinceCodes[number[:2]]
+	if !ok || number[2:6] == "0000" {
+		return nil, errors.New("身份证号码地区码无效")
+	}
+
+	birth, err := time.ParseInLocation("20060102", number[6:14], time.Local)
+	if err != nil || birth.Format("20060102") != number[6:14] {
+		return nil, errors.New("身份证号码出生日期无效")
+	}
+	if birth.Before(minBirthDate) || birth.After(time.Now()) {
+		return nil, errors.New("身份证号码出生日期无效")
+	}
+
+	if checksum(number) != number[17] {
+		return nil, errors.New("身份证号码校验码错误")
+	}
+
+	gender := GenderFemale
+	if int(number[16]-'0')%2 == 1 {
+		gender = GenderMale
+	}
+
+	return &IDCardInfo{
+		Number:     number,
+		RegionCode: number[:6],
+		Province:   province,
+		BirthDate:  birth,
+		Gender:     gender,
+	}, nil
+}
+
+// ValidIDCard 身份证号码是否有效
+func ValidIDCard(number string) bool {
+	_, err := ParseIDCard(number)
+	return err == nil
+}
+
+// checksum 计算前17位本体码对应的校验码
+func checksum(number string) byte {
+	sum := 0
+	for i := 0; i < 17; i++ {
+		sum += int(number[i]-'0') * checksumWeights[i]
+	}
+	return checksumCodes[sum%11]
+}
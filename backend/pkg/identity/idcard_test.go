@@ -0,0 +1,91 @@
+package identity
+
+import (
+	"testing"
+	"time"
+)
+
+// withChecksum 为前17位本体码补上正确的校验码
+func withChecksum(body string) string {
+	return body + string(checksum(body+"0"))
+}
+
+func TestParseIDCard(t *testing.T) {
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("20060102")
+
+	tests := []struct {
+		name       string
+		number     string
+		wantErr    string
+		wantBirth  string
+		wantGender int
+	}{
+		{"GB 11643 示例号码", "11010519491231002X", "", "1949-12-31", GenderFemale},
+		{"校验码小写x", "11010519491231002x", "", "1949-12-31", GenderFemale},
+		{"含空格", "110105 19491231 002X", "", "1949-12-31", GenderFemale},
+		{"男性", withChecksum("44030420000229001"), "", "2000-02-29", GenderMale},
+		{"校验码错误", "110105194912310021", "身份证号码校验码错误", "", 0},
+		{"长度不足", "11010519491231002", "身份证号码应为18位", "", 0},
+		{"本体码含字母", "1101051949123100AX", "身份证号码格式错误", "", 0},
+		{"校验位非法字符", "11010519491231002Y", "身份证号码格式错误", "", 0},
+		{"省级地区码无效", withChecksum("99010519491231002"), "身份证号码地区码无效", "", 0},
+		{"地区码后四位为0", withChecksum("11000019491231002"), "身份证号码地区码无效", "", 0},
+		{"非闰年2月29日", withChecksum("11010520010229002"), "身份证号码出生日期无效", "", 0},
+		{"月份无效", withChecksum("11010519491301002"), "身份证号码出生日期无效", "", 0},
+		{"早于1900年", withChecksum("11010518991231002"), "身份证号码出生日期无效", "", 0},
+		{"晚于今天", withChecksum("110105" + tomorrow + "002"), "身份证号码出生日期无效", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := ParseIDCard(tt.number)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ParseIDCard(%q) error = %v, want %q", tt.number, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseIDCard(%q) unexpected error: %v", tt.number, err)
+			}
+			if got := info.BirthDateString(); got != tt.wantBirth {
+				t.Errorf("BirthDate = %s, want %s", got, tt.wantBirth)
+			}
+			if info.Gender != tt.wantGender {
+				t.Errorf("Gender = %d, want %d", info.Gender, tt.wantGender)
+			}
+			if info.Number != NormalizeNumber(tt.number) {
+				t.Errorf("Number = %s, want %s", info.Number, NormalizeNumber(tt.number))
+			}
+		})
+	}
+}
+
+func TestAgeAt(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		name  string
+		birth time.Time
+		at    time.Time
+		want  int
+	}{
+		{"生日当天满岁", date(2008, 6, 15), date(2026, 6, 15), 18},
+		{"生日前一天", date(2008, 6, 15), date(2026, 6, 14), 17},
+		{"生日所在月之前", date(2008, 6, 15), date(2026, 5, 20), 17},
+		{"闰日出生平年2月28日未满岁", date(2008, 2, 29), date(2026, 2, 28), 17},
+		{"闰日出生平年3月1日满岁", date(2008, 2, 29), date(2026, 3, 1), 18},
+		{"出生当天", date(2026, 1, 1), date(2026, 1, 1), 0},
+		{"出生日期晚于指定日期", date(2026, 1, 2), date(2026, 1, 1), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AgeAt(tt.birth, tt.at); got != tt.want {
+				t.Errorf("AgeAt(%s, %s) = %d, want %d", tt.birth.Format("2006-01-02"), tt.at.Format("2006-01-02"), got, tt.want)
+			}
+		})
+	}
+}
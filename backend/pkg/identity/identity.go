@@ -0,0 +1,45 @@
+package identity
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"huaan-medical/pkg/config"
+)
+
+// verifyTimeout 单次实名核验超时时间
+const verifyTimeout = 5 * time.Second
+
+// GetProvider 根据配置获取实名核验服务商
+func GetProvider() Provider {
+	cfg := config.Get()
+	if cfg == nil || !cfg.Identity.Enabled {
+		return &DisabledProvider{}
+	}
+	switch cfg.Identity.Provider {
+	case "mock":
+		// 避免误把 mock provider 用在生产
+		if gin.Mode() == gin.ReleaseMode {
+			return &DisabledProvider{}
+		}
+		return &MockProvider{}
+	default:
+		return &DisabledProvider{}
+	}
+}
+
+// Enabled 是否已配置可用的实名核验服务商
+func Enabled() bool {
+	_, disabled := GetProvider().(*DisabledProvider)
+	return !disabled
+}
+
+// Verify 调用已配置的服务商核验姓名与证件号码是否一致
+// 未启用实名核验时返回 ErrProviderDisabled
+func Verify(req *VerifyRequest) (*VerifyResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), verifyTimeout)
+	defer cancel()
+	return GetProvider().Verify(ctx, req)
+}
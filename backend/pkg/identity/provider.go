@@ -0,0 +1,30 @@
+package identity
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrProviderDisabled 实名核验服务未配置/未启用
+var ErrProviderDisabled = errors.New("实名核验服务未配置")
+
+// VerifyRequest 实名核验请求
+type VerifyRequest struct {
+	Name    string
+	DocType string
+	Number  string
+	Phone   string // 可选：填写时同时核验该手机号登记在此证件名下（运营商三要素）
+}
+
+// VerifyResult 实名核验结果
+type VerifyResult struct {
+	Matched bool   // 姓名与证件号码（及手机号）是否一致
+	Reason  string // 不一致原因或服务商返回说明
+	TraceID string // 服务商流水号（用于对账/排查）
+}
+
+// Provider 实名核验服务商抽象
+// 说明：仅负责调用第三方核验姓名与证件号码（及手机号）是否一致；证件格式校验由 ValidateDocument 负责。
+type Provider interface {
+	Verify(ctx context.Context, req *VerifyRequest) (*VerifyResult, error)
+}
@@ -0,0 +1,10 @@
+package identity
+
+import "context"
+
+// DisabledProvider 实名核验未配置/未启用时的默认实现
+type DisabledProvider struct{}
+
+func (p *DisabledProvider) Verify(_ context.Context, _ *VerifyRequest) (*VerifyResult, error) {
+	return nil, ErrProviderDisabled
+}
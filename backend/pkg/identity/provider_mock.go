@@ -0,0 +1,42 @@
+package identity
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"huaan-medical/pkg/logger"
+)
+
+// mockPhonePattern 模拟核验时视为有效的手机号
+var mockPhonePattern = regexp.MustCompile(`^1[3-9]\d{9}$`)
+
+// MockProvider 开发/联调用：本地模拟实名核验，不调用第三方服务
+// 规则：证件号码格式有效即视为一致；姓名包含「不一致」或手机号格式无效时返回不一致，便于联调失败分支
+type MockProvider struct{}
+
+func (p *MockProvider) Verify(_ context.Context, req *VerifyRequest) (*VerifyResult, error) {
+	traceID := fmt.Sprintf("mock-%d", time.Now().UnixNano())
+	result := &VerifyResult{Matched: true, Reason: "本地模拟核验通过", TraceID: traceID}
+
+	if _, err := ValidateDocument(req.DocType, req.Number); err != nil {
+		result.Matched = false
+		result.Reason = err.Error()
+	} else if strings.Contains(req.Name, "不一致") {
+		result.Matched = false
+		result.Reason = "姓名与证件号码不一致"
+	} else if req.Phone != "" && !mockPhonePattern.MatchString(req.Phone) {
+		result.Matched = false
+		result.Reason = "手机号未登记在该证件名下"
+	}
+
+	logger.Warn("实名核验（mock provider）",
+		zap.String("doc_type", req.DocType),
+		zap.Bool("matched", result.Matched),
+		zap.String("trace_id", traceID))
+	return result, nil
+}
@@ -1,6 +1,8 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"time"
 
@@ -18,9 +20,11 @@ const (
 )
 
 // Claims 自定义JWT声明
+// SessionID 为服务端登录会话ID，Access/Refresh Token 共用；Refresh Token 的 ID(jti) 用于轮换与重放检测
 type Claims struct {
 	UserID    int64     `json:"user_id"`
 	OpenID    string    `json:"open_id,omitempty"`
+	SessionID string    `json:"sid,omitempty"`
 	TokenType TokenType `json:"token_type"`
 	jwt.RegisteredClaims
 }
@@ -33,11 +37,25 @@ type AdminClaims struct {
 	jwt.RegisteredClaims
 }
 
+// DoctorClaims 医生JWT声明（Subject/Audience 固定为 doctor，与用户、管理员Token互不通用）
+type DoctorClaims struct {
+	AccountID int64  `json:"account_id"`
+	DoctorID  int64  `json:"doctor_id"`
+	Username  string `json:"username"`
+	jwt.RegisteredClaims
+}
+
+// doctorAudience 医生Token受众
+const doctorAudience = "doctor"
+
 // TokenPair Token对
 type TokenPair struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
 	ExpiresIn    int64  `json:"expires_in"` // 过期时间（秒）
+
+	RefreshID        string    `json:"-"` // Refresh Token 唯一标识(jti)
+	RefreshExpiresAt time.Time `json:"-"` // Refresh Token 过期时间
 }
 
 var jwtConfig *config.JWTConfig
@@ -47,14 +65,19 @@ func Init(cfg *config.JWTConfig) {
 	jwtConfig = cfg
 }
 
-// GenerateTokenPair 生成Token对
-func GenerateTokenPair(userID int64, openID string) (*TokenPair, error) {
+// GenerateTokenPair 为登录会话生成Token对
+func GenerateTokenPair(userID int64, openID, sessionID string) (*TokenPair, error) {
 	now := time.Now()
+	refreshID, err := newTokenID()
+	if err != nil {
+		return nil, err
+	}
 
 	// 生成Access Token
 	accessClaims := Claims{
 		UserID:    userID,
 		OpenID:    openID,
+		SessionID: sessionID,
 		TokenType: AccessToken,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(now.Add(jwtConfig.AccessTokenExpire)),
@@ -70,12 +93,15 @@ func GenerateTokenPair(userID int64, openID string) (*TokenPair, error) {
 	}
 
 	// 生成Refresh Token
+	refreshExpiresAt := now.Add(jwtConfig.RefreshTokenExpire)
 	refreshClaims := Claims{
 		UserID:    userID,
 		OpenID:    openID,
+		SessionID: sessionID,
 		TokenType: RefreshToken,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(now.Add(jwtConfig.RefreshTokenExpire)),
+			ID:        refreshID,
+			ExpiresAt: jwt.NewNumericDate(refreshExpiresAt),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "huaan-medical",
@@ -88,9 +114,11 @@ func GenerateTokenPair(userID int64, openID string) (*TokenPair, error) {
 	}
 
 	return &TokenPair{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresIn:    int64(jwtConfig.AccessTokenExpire.Seconds()),
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		ExpiresIn:        int64(jwtConfig.AccessTokenExpire.Seconds()),
+		RefreshID:        refreshID,
+		RefreshExpiresAt: refreshExpiresAt,
 	}, nil
 }
 
@@ -114,6 +142,27 @@ func GenerateAdminToken(adminID int64, username, role string) (string, error) {
 	return generateToken(claims)
 }
 
+// GenerateDoctorToken 生成医生Token
+func GenerateDoctorToken(accountID, doctorID int64, username string) (string, error) {
+	now := time.Now()
+
+	claims := DoctorClaims{
+		AccountID: accountID,
+		DoctorID:  doctorID,
+		Username:  username,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(jwtConfig.AccessTokenExpire)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    "huaan-medical",
+			Subject:   doctorAudience,
+			Audience:  jwt.ClaimStrings{doctorAudience},
+		},
+	}
+
+	return generateToken(claims)
+}
+
 // ParseToken 解析用户Token
 func ParseToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
@@ -133,11 +182,11 @@ func ParseToken(tokenString string) (*Claims, error) {
 	return nil, errors.New("token解析失败")
 }
 
-// ParseAdminToken 解析管理员Token
+// ParseAdminToken 解析管理员Token（校验主题，拒绝用户/医生Token）
 func ParseAdminToken(tokenString string) (*AdminClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &AdminClaims{}, func(token *jwt.Token) (interface{}, error) {
 		return []byte(jwtConfig.Secret), nil
-	})
+	}, jwt.WithSubject("admin"))
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
 			return nil, errors.New("token已过期")
@@ -152,18 +201,23 @@ func ParseAdminToken(tokenString string) (*AdminClaims, error) {
 	return nil, errors.New("token解析失败")
 }
 
-// RefreshAccessToken 刷新Access Token
-func RefreshAccessToken(refreshTokenString string) (*TokenPair, error) {
-	claims, err := ParseToken(refreshTokenString)
+// ParseDoctorToken 解析医生Token（校验受众与主题，拒绝用户/管理员Token）
+func ParseDoctorToken(tokenString string) (*DoctorClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &DoctorClaims{}, func(token *jwt.Token) (interface{}, error) {
+		return []byte(jwtConfig.Secret), nil
+	}, jwt.WithAudience(doctorAudience), jwt.WithSubject(doctorAudience))
 	if err != nil {
-		return nil, err
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, errors.New("token已过期")
+		}
+		return nil, errors.New("token无效")
 	}
 
-	if claims.TokenType != RefreshToken {
-		return nil, errors.New("无效的refresh token")
+	if claims, ok := token.Claims.(*DoctorClaims); ok && token.Valid && claims.DoctorID > 0 {
+		return claims, nil
 	}
 
-	return GenerateTokenPair(claims.UserID, claims.OpenID)
+	return nil, errors.New("token解析失败")
 }
 
 // generateToken 生成Token
@@ -171,3 +225,12 @@ func generateToken(claims jwt.Claims) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(jwtConfig.Secret))
 }
+
+// newTokenID 生成随机Token标识
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
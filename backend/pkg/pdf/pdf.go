@@ -0,0 +1,198 @@
+// Package pdf 提供最小化的 PDF 生成能力（A4 纯文本、自动换行分页），
+// 中文使用 Adobe 预置的 STSong-Light 字体（不嵌入字体文件，由阅读器提供），
+// 用于个人数据导出等简单文档场景，避免引入完整的 PDF 依赖
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+)
+
+// A4 版面（单位：point）
+const (
+	pageWidth    = 595.0
+	pageHeight   = 842.0
+	marginX      = 50.0
+	marginTop    = 60.0
+	marginBottom = 60.0
+	lineSpacing  = 1.5
+)
+
+// 字号
+const (
+	TitleSize   = 18.0
+	HeadingSize = 14.0
+	TextSize    = 10.5
+)
+
+// line 已排版的一行文本
+type line struct {
+	text string
+	size float64
+	x, y float64
+}
+
+// Document PDF 文档
+type Document struct {
+	pages [][]line
+	y     float64
+}
+
+// New 创建空白文档
+func New() *Document {
+	d := &Document{}
+	d.newPage()
+	return d
+}
+
+// Title 添加文档标题
+func (d *Document) Title(text string) {
+	d.write(text, TitleSize, 0)
+	d.Space(TextSize)
+}
+
+// Heading 添加小节标题
+func (d *Document) Heading(text string) {
+	d.Space(TextSize / 2)
+	d.write(text, HeadingSize, 0)
+}
+
+// Text 添加正文，超出版心宽度时自动换行
+func (d *Document) Text(text string) {
+	d.write(text, TextSize, 0)
+}
+
+// Field 添加“名称：值”形式的正文，值换行时与首行值对齐
+func (d *Document) Field(label, value string) {
+	prefix := label + "："
+	indent := textWidth(prefix, TextSize)
+	if indent > (pageWidth-2*marginX)/2 {
+		d.Text(prefix + value)
+		return
+	}
+	d.writeLine(prefix, TextSize, 0, false)
+	d.write(value, TextSize, indent)
+}
+
+// Space 添加空白
+func (d *Document) Space(height float64) {
+	d.y -= height
+}
+
+// Bytes 输出 PDF 文件内容
+func (d *Document) Bytes() []byte {
+	var buf bytes.Buffer
+	var offsets []int
+
+	obj := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	// 1 目录 2 页面树 3-5 字体；之后每页依次为页面对象、内容流
+	pageCount := len(d.pages)
+	kids := new(bytes.Buffer)
+	for i := 0; i < pageCount; i++ {
+		fmt.Fprintf(kids, "%d 0 R ", 6+i*2)
+	}
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", bytes.TrimSpace(kids.Bytes()), pageCount))
+	obj("<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light /Encoding /UniGB-UCS2-H /DescendantFonts [4 0 R] >>")
+	obj("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light " +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 2 >> " +
+		"/FontDescriptor 5 0 R /DW 1000 /W [1 95 500 814 939 500] >>")
+	obj("<< /Type /FontDescriptor /FontName /STSong-Light /Flags 6 /FontBBox [-25 -254 1000 880] " +
+		"/ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>")
+
+	for i, lines := range d.pages {
+		var content bytes.Buffer
+		for _, l := range lines {
+			fmt.Fprintf(&content, "BT /F1 %.1f Tf %.2f %.2f Td <%s> Tj ET\n", l.size, l.x, l.y, encodeUCS2(l.text))
+		}
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] "+
+			"/Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", pageWidth, pageHeight, 7+i*2))
+		obj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.Bytes()
+}
+
+// write 按版心宽度折行写入文本
+func (d *Document) write(text string, size, indent float64) {
+	maxWidth := pageWidth - 2*marginX - indent
+	if text == "" {
+		d.writeLine("", size, indent, true)
+		return
+	}
+
+	for text != "" {
+		width, cut := 0.0, 0
+		for i, r := range text {
+			w := runeWidth(r) * size
+			if width+w > maxWidth && cut > 0 {
+				break
+			}
+			width += w
+			cut = i + utf8.RuneLen(r)
+		}
+		d.writeLine(text[:cut], size, indent, true)
+		text = text[cut:]
+	}
+}
+
+// writeLine 写入一行，newline 为 false 时下一次写入仍在同一行
+func (d *Document) writeLine(text string, size, indent float64, newline bool) {
+	if d.y-size < marginBottom {
+		d.newPage()
+	}
+	page := len(d.pages) - 1
+	d.pages[page] = append(d.pages[page], line{text: text, size: size, x: marginX + indent, y: d.y - size})
+	if newline {
+		d.y -= size * lineSpacing
+	}
+}
+
+// newPage 开始新的一页
+func (d *Document) newPage() {
+	d.pages = append(d.pages, nil)
+	d.y = pageHeight - marginTop
+}
+
+// runeWidth 字符宽度（em），ASCII 为半角，其余按全角计算
+func runeWidth(r rune) float64 {
+	if r < 0x80 {
+		return 0.5
+	}
+	return 1
+}
+
+// textWidth 文本宽度（point）
+func textWidth(text string, size float64) float64 {
+	width := 0.0
+	for _, r := range text {
+		width += runeWidth(r) * size
+	}
+	return width
+}
+
+// encodeUCS2 将文本编码为 UCS-2 大端十六进制串，基本平面以外及控制字符替换为问号
+func encodeUCS2(text string) string {
+	var buf bytes.Buffer
+	for _, r := range text {
+		if r > 0xFFFF || r < 0x20 {
+			r = '?'
+		}
+		fmt.Fprintf(&buf, "%04X", r)
+	}
+	return buf.String()
+}
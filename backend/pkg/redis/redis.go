@@ -86,8 +86,9 @@ func Close() error {
 // Key 常量定义
 const (
 	// Token相关
-	KeyTokenBlacklist = "token:blacklist:%s" // Token黑名单
-	KeyRefreshToken   = "token:refresh:%d"   // 刷新Token
+	KeyTokenBlacklist   = "token:blacklist:%s"    // Token黑名单
+	KeyRefreshToken     = "token:refresh:%s"      // 登录会话当前刷新Token标识（会话ID）
+	KeyUserTokenRevoked = "token:revoked:user:%d" // 用户Token整体吊销时间（Unix秒）
 
 	// 用户相关
 	KeyUserInfo = "user:info:%d" // 用户信息缓存
@@ -97,6 +98,13 @@ const (
 	KeyScheduleLock   = "schedule:lock:%d:%s" // 排班锁定
 	KeyDailyApptCount = "appt:daily:%d:%s"    // 每日预约计数
 
+	// 排班相关
+	KeyDoctorSchedule = "schedule:doctor:%d:%s:%s" // 医生排班缓存（医生ID:开始日期:结束日期）
+
+	// 协议相关
+	KeyAgreementCurrent  = "agreement:current"     // 必须同意的协议现行版本（JSON）
+	KeyAgreementAccepted = "agreement:accepted:%d" // 用户已同意的现行协议版本签名
+
 	// 验证码相关
 	KeySmsCode = "sms:code:%s" // 短信验证码
 
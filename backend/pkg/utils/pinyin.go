@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/mozillazg/go-pinyin"
+)
+
+// pinyinMaxVariants 多音字组合的最大数量（避免长文本组合爆炸）
+const pinyinMaxVariants = 8
+
+// ToPinyin 将文本转换为全拼及首字母（小写、无声调、无分隔）
+// 多音字会生成多种读音组合（最多 pinyinMaxVariants 种），如 "曾" 同时生成 zeng/ceng；
+// 非汉字的字母、数字原样保留（转小写），其它字符忽略
+func ToPinyin(text string) (full []string, initials []string) {
+	args := pinyin.NewArgs()
+	args.Heteronym = true
+
+	fullVariants := []string{""}
+	initialVariants := []string{""}
+	for _, r := range text {
+		var readings []string
+		switch {
+		case unicode.Is(unicode.Han, r):
+			list := pinyin.SinglePinyin(r, args)
+			if len(list) == 0 {
+				continue
+			}
+			readings = list
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			readings = []string{strings.ToLower(string(r))}
+		default:
+			continue
+		}
+
+		fullVariants = expandPinyinVariants(fullVariants, readings, false)
+		initialVariants = expandPinyinVariants(initialVariants, readings, true)
+	}
+
+	return dedupeStrings(fullVariants), dedupeStrings(initialVariants)
+}
+
+// expandPinyinVariants 将当前组合与单字读音做笛卡尔积（超过上限时只保留第一种读音）
+func expandPinyinVariants(current, readings []string, initialOnly bool) []string {
+	if len(current)*len(readings) > pinyinMaxVariants {
+		readings = readings[:1]
+	}
+	next := make([]string, 0, len(current)*len(readings))
+	for _, prefix := range current {
+		for _, reading := range readings {
+			if initialOnly {
+				reading = reading[:1]
+			}
+			next = append(next, prefix+reading)
+		}
+	}
+	return next
+}
+
+// dedupeStrings 去重并去除空字符串（保持原有顺序）
+func dedupeStrings(list []string) []string {
+	seen := make(map[string]bool, len(list))
+	result := make([]string, 0, len(list))
+	for _, s := range list {
+		if s == "" || seen[s] {
+			continue
+		}
+		seen[s] = true
+		result = append(result, s)
+	}
+	return result
+}
+
+// IsPinyinQuery 判断搜索词是否为拼音/首字母（仅由字母组成）
+func IsPinyinQuery(query string) bool {
+	if query == "" {
+		return false
+	}
+	for _, r := range query {
+		if r > unicode.MaxASCII || !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -4,15 +4,15 @@ import (
 	"crypto/md5"
 	"crypto/rand"
 	"encoding/hex"
-	"fmt"
 	"regexp"
-	"strconv"
 	"strings"
 	"time"
 	"unicode"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
+
+	"huaan-medical/pkg/identity"
 )
 
 // GenerateUUID 生成UUID
@@ -92,12 +92,29 @@ func ValidateUsername(username string) bool {
 	return matched
 }
 
-// ValidateIDCard 验证身份证号格式（简单校验）
+// ValidatePasswordStrength 验证密码强度
+// 规则：8-20字符，不含空白字符，必须同时包含字母和数字
+func ValidatePasswordStrength(password string) bool {
+	if len(password) < 8 || len(password) > 20 {
+		return false
+	}
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+			hasLetter = true
+		case r >= '0' && r <= '9':
+			hasDigit = true
+		case r <= ' ' || r > '~':
+			return false
+		}
+	}
+	return hasLetter && hasDigit
+}
+
+// ValidateIDCard 验证18位居民身份证号（GB 11643：地区码、出生日期、校验码）
 func ValidateIDCard(idCard string) bool {
-	// 18位身份证号
-	pattern := `^\d{17}[\dXx]$`
-	matched, _ := regexp.MatchString(pattern, idCard)
-	return matched
+	return identity.ValidIDCard(idCard)
 }
 
 // MaskPhone 手机号脱敏（显示前3后4）
@@ -178,44 +195,31 @@ func GetMonthEnd() time.Time {
 	return time.Date(nextMonth.Year(), nextMonth.Month(), 1, 0, 0, 0, 0, time.Local).Add(-time.Nanosecond)
 }
 
-// CalculateAge 根据身份证号计算年龄
+// CalculateAge 根据身份证号计算当前周岁年龄（号码无效时返回0）
 func CalculateAge(idCard string) int {
-	if len(idCard) != 18 {
+	info, err := identity.ParseIDCard(idCard)
+	if err != nil {
 		return 0
 	}
-	birthYear, _ := strconv.Atoi(idCard[6:10])
-	birthMonth, _ := strconv.Atoi(idCard[10:12])
-	birthDay, _ := strconv.Atoi(idCard[12:14])
-
-	now := time.Now()
-	age := now.Year() - birthYear
-
-	// 如果还没过生日，年龄减1
-	if int(now.Month()) < birthMonth || (int(now.Month()) == birthMonth && now.Day() < birthDay) {
-		age--
-	}
-
-	return age
+	return info.Age()
 }
 
-// GetGenderFromIDCard 从身份证号获取性别
+// GetGenderFromIDCard 从身份证号获取性别 1男 2女（号码无效时返回0）
 func GetGenderFromIDCard(idCard string) int {
-	if len(idCard) != 18 {
+	info, err := identity.ParseIDCard(idCard)
+	if err != nil {
 		return 0
 	}
-	genderCode, _ := strconv.Atoi(string(idCard[16]))
-	if genderCode%2 == 0 {
-		return 2 // 女
-	}
-	return 1 // 男
+	return info.Gender
 }
 
-// GetBirthDateFromIDCard 从身份证号获取出生日期
+// GetBirthDateFromIDCard 从身份证号获取出生日期（YYYY-MM-DD，号码无效时返回空）
 func GetBirthDateFromIDCard(idCard string) string {
-	if len(idCard) != 18 {
+	info, err := identity.ParseIDCard(idCard)
+	if err != nil {
 		return ""
 	}
-	return fmt.Sprintf("%s-%s-%s", idCard[6:10], idCard[10:12], idCard[12:14])
+	return info.BirthDateString()
 }
 
 // ContainsChinese 检查字符串是否包含中文
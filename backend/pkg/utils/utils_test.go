@@ -0,0 +1,34 @@
+package utils
+
+import "testing"
+
+func TestValidatePasswordStrength(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		want     bool
+	}{
+		{"字母加数字", "abc12345", true},
+		{"大小写字母加数字", "Abcdef12", true},
+		{"含符号", "abc_123!", true},
+		{"最长20位", "abcdefghij1234567890", true},
+		{"不足8位", "abc1234", false},
+		{"超过20位", "abcdefghij12345678901", false},
+		{"纯字母", "abcdefgh", false},
+		{"纯数字", "12345678", false},
+		{"纯符号", "!@#$%^&*", false},
+		{"含空格", "abc 12345", false},
+		{"含制表符", "abc\t12345", false},
+		{"含中文", "密码abc12345", false},
+		{"全角数字不算数字", "abcdefg１", false},
+		{"空字符串", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidatePasswordStrength(tt.password); got != tt.want {
+				t.Errorf("ValidatePasswordStrength(%q) = %v, want %v", tt.password, got, tt.want)
+			}
+		})
+	}
+}
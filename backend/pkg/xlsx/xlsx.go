@@ -0,0 +1,281 @@
+// Package xlsx 提供最小化的 XLSX 读写能力（仅首个工作表、纯文本单元格），
+// 用于排班导入等简单表格场景，避免引入完整的 Excel 依赖
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ErrNoSheet 文件中没有工作表
+var ErrNoSheet = errors.New("xlsx: 文件中没有工作表")
+
+// sharedStrings 共享字符串表
+type sharedStrings struct {
+	Items []struct {
+		Text string `xml:"t"`
+		Runs []struct {
+			Text string `xml:"t"`
+		} `xml:"r"`
+	} `xml:"si"`
+}
+
+// worksheet 工作表
+type worksheet struct {
+	Rows []struct {
+		Index int `xml:"r,attr"`
+		Cells []struct {
+			Ref       string `xml:"r,attr"`
+			Type      string `xml:"t,attr"`
+			Value     string `xml:"v"`
+			InlineStr struct {
+				Text string `xml:"t"`
+			} `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// workbook 工作簿（用于定位首个工作表）
+type workbook struct {
+	Sheets []struct {
+		RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// relationships 关系表
+type relationships struct {
+	Items []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// ReadRows 读取首个工作表的所有行，单元格统一按文本返回
+// 空行会保留为空切片，以便调用方按 Excel 行号报告错误
+func ReadRows(r io.ReaderAt, size int64) ([][]string, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("xlsx: 无法解析文件: %w", err)
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	// 共享字符串
+	var shared []string
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		var sst sharedStrings
+		if err := decodeXML(f, &sst); err != nil {
+			return nil, err
+		}
+		shared = make([]string, len(sst.Items))
+		for i, item := range sst.Items {
+			if len(item.Runs) == 0 {
+				shared[i] = item.Text
+				continue
+			}
+			var sb strings.Builder
+			for _, run := range item.Runs {
+				sb.WriteString(run.Text)
+			}
+			shared[i] = sb.String()
+		}
+	}
+
+	sheetFile, err := firstSheet(files)
+	if err != nil {
+		return nil, err
+	}
+
+	var ws worksheet
+	if err := decodeXML(sheetFile, &ws); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	for _, row := range ws.Rows {
+		// 补齐缺失的空行（XLSX 不保存空行）
+		if row.Index > 0 {
+			for len(rows) < row.Index-1 {
+				rows = append(rows, nil)
+			}
+		}
+
+		var cells []string
+		for i, cell := range row.Cells {
+			col := i
+			if cell.Ref != "" {
+				if c, ok := columnIndex(cell.Ref); ok {
+					col = c
+				}
+			}
+			for len(cells) < col {
+				cells = append(cells, "")
+			}
+
+			value := cell.Value
+			switch cell.Type {
+			case "s":
+				idx, err := strconv.Atoi(cell.Value)
+				if err == nil && idx >= 0 && idx < len(shared) {
+					value = shared[idx]
+				}
+			case "inlineStr":
+				value = cell.InlineStr.Text
+			}
+			cells = append(cells, strings.TrimSpace(value))
+		}
+		rows = append(rows, cells)
+	}
+
+	return rows, nil
+}
+
+// WriteRows 将文本行写入只有一个工作表的 XLSX 文件
+func WriteRows(w io.Writer, sheetName string, rows [][]string) error {
+	zw := zip.NewWriter(w)
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`</Types>`},
+		{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="` + escape(sheetName) + `" sheetId="1" r:id="rId1"/></sheets>` +
+			`</workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`</Relationships>`},
+		{"xl/worksheets/sheet1.xml", buildSheet(rows)},
+	}
+
+	for _, part := range parts {
+		fw, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, part.content); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// buildSheet 生成工作表XML（使用内联字符串）
+func buildSheet(rows [][]string) string {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`)
+	sb.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for r, row := range rows {
+		sb.WriteString(`<row r="` + strconv.Itoa(r+1) + `">`)
+		for c, value := range row {
+			sb.WriteString(`<c r="` + columnName(c) + strconv.Itoa(r+1) + `" t="inlineStr"><is><t>`)
+			sb.WriteString(escape(value))
+			sb.WriteString(`</t></is></c>`)
+		}
+		sb.WriteString(`</row>`)
+	}
+	sb.WriteString(`</sheetData></worksheet>`)
+	return sb.String()
+}
+
+// firstSheet 定位首个工作表文件
+func firstSheet(files map[string]*zip.File) (*zip.File, error) {
+	wbFile, ok := files["xl/workbook.xml"]
+	relFile, relOK := files["xl/_rels/workbook.xml.rels"]
+	if ok && relOK {
+		var wb workbook
+		var rels relationships
+		if err := decodeXML(wbFile, &wb); err == nil && len(wb.Sheets) > 0 {
+			if err := decodeXML(relFile, &rels); err == nil {
+				for _, rel := range rels.Items {
+					if rel.ID != wb.Sheets[0].RID {
+						continue
+					}
+					target := strings.TrimPrefix(rel.Target, "/")
+					if !strings.HasPrefix(target, "xl/") {
+						target = path.Join("xl", target)
+					}
+					if f, ok := files[target]; ok {
+						return f, nil
+					}
+				}
+			}
+		}
+	}
+
+	// 回退：按约定路径查找
+	if f, ok := files["xl/worksheets/sheet1.xml"]; ok {
+		return f, nil
+	}
+	return nil, ErrNoSheet
+}
+
+// decodeXML 解析压缩包内的XML文件
+func decodeXML(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("xlsx: 解析 %s 失败: %w", f.Name, err)
+	}
+	return nil
+}
+
+// columnIndex 将单元格引用（如 "C5"）转换为从0开始的列序号
+func columnIndex(ref string) (int, bool) {
+	col := 0
+	n := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		col = col*26 + int(ch-'A'+1)
+		n++
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return col - 1, true
+}
+
+// columnName 将从0开始的列序号转换为列名（如 0 -> "A"）
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// escape 转义XML文本
+func escape(s string) string {
+	var sb strings.Builder
+	_ = xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
@@ -0,0 +1,17 @@
+import { http } from '../utils/request'
+
+export function getCurrentAgreement(type) {
+  return http.get(`/agreements/${type}`)
+}
+
+export function getPendingAgreements() {
+  return http.get('/user/agreements/pending')
+}
+
+export function consentAgreement(data) {
+  return http.post('/user/agreements/consents', data)
+}
+
+export function getMyAgreementConsents() {
+  return http.get('/user/agreements/consents')
+}
@@ -20,6 +20,10 @@ export function refreshToken({ refresh_token }) {
   return http.post('/auth/refresh', { refresh_token })
 }
 
+export function logoutSession() {
+  return http.post('/user/logout')
+}
+
 export function getUserInfo() {
   return http.get('/user/info')
 }
@@ -2,13 +2,12 @@ import { defineStore } from 'pinia'
 import { getUserInfo } from '../api/auth'
 import {
   bootstrapAuth,
-  clearTokens,
   getAccessToken,
   getUser,
   isLoggedIn,
   setTokens as setTokensStorage,
   setUser as setUserStorage,
-  toLoginPage,
+  signOut,
 } from '../utils/auth'
 
 export const useUserStore = defineStore('user', {
@@ -57,10 +56,9 @@ export const useUserStore = defineStore('user', {
       this.setUser(user)
       return user
     },
-    logout() {
-      clearTokens()
+    async logout() {
       this.setUser(null)
-      toLoginPage()
+      await signOut()
     },
   },
 })
@@ -1,6 +1,6 @@
 import { STORAGE_KEYS } from './config'
 import { getStorage, removeStorage, setStorage } from './storage'
-import { refreshToken as refreshTokenApi } from '../api/auth'
+import { logoutSession, refreshToken as refreshTokenApi } from '../api/auth'
 
 let refreshPromise = null
 
@@ -75,8 +75,13 @@ export async function ensureValidAccessToken() {
     if (!exp || exp - now > 60) return token
   }
 
+  return refreshAccessToken()
+}
+
+// refresh_token 一次有效（服务端轮换），并发请求必须共用同一次刷新，否则会被判定为重复使用而使登录失效
+export function refreshAccessToken() {
   const rt = getRefreshToken()
-  if (!rt) return ''
+  if (!rt) return Promise.resolve('')
 
   if (!refreshPromise) {
     refreshPromise = (async () => {
@@ -106,6 +111,18 @@ export async function logout() {
   toLoginPage()
 }
 
+// 主动退出：先通知服务端吊销当前会话，再清理本地登录态
+export async function signOut() {
+  if (getAccessToken()) {
+    try {
+      await logoutSession()
+    } catch (e) {
+      // 服务端会话随 Token 过期失效，忽略
+    }
+  }
+  await logout()
+}
+
 export async function bootstrapAuth() {
   await ensureValidAccessToken()
 }
@@ -30,6 +30,9 @@ export const STORAGE_KEYS = {
 
 export const SUCCESS_CODE = 200000
 
+// 协议已更新需重新同意
+export const AGREEMENT_CONSENT_REQUIRED_CODE = 403004
+
 // 订阅消息模板ID（需要你在微信公众平台配置）
 export const WECHAT_SUBSCRIBE_TEMPLATE_IDS = {
   appointmentReminder: getRuntimeSubscribeTemplateIds()?.appointmentReminder || '',
@@ -1,6 +1,5 @@
-import { API_BASE_URL, SUCCESS_CODE } from './config'
-import { ensureValidAccessToken, getRefreshToken, logout, setTokens } from './auth'
-import { refreshToken as refreshTokenApi } from '../api/auth'
+import { API_BASE_URL, SUCCESS_CODE, AGREEMENT_CONSENT_REQUIRED_CODE } from './config'
+import { ensureValidAccessToken, logout, refreshAccessToken } from './auth'
 
 function buildUrl(path) {
   if (!path) return API_BASE_URL
@@ -23,12 +22,26 @@ function requestRaw({ method, url, data, header }) {
   })
 }
 
-async function tryRefreshToken() {
-  const rt = getRefreshToken()
-  if (!rt) return ''
-  const data = await refreshTokenApi({ refresh_token: rt })
-  setTokens({ accessToken: data?.access_token, refreshToken: data?.refresh_token })
-  return data?.access_token || ''
+// 协议发布新版本后，打开协议更新页展示待同意协议的全文，由用户逐一阅读并同意
+// 协议更新页通过 AGREEMENT_RESULT_EVENT 事件返回用户是否已全部同意
+const AGREEMENT_PAGE = '/pages/legal/agreement-update'
+const AGREEMENT_RESULT_EVENT = 'agreement-consent-result'
+let agreementPrompting = null
+function promptAgreementConsent() {
+  if (agreementPrompting) return agreementPrompting
+  agreementPrompting = new Promise((resolve) => {
+    uni.$once(AGREEMENT_RESULT_EVENT, (ok) => resolve(!!ok))
+    uni.navigateTo({
+      url: AGREEMENT_PAGE,
+      fail: () => {
+        uni.$off(AGREEMENT_RESULT_EVENT)
+        resolve(false)
+      },
+    })
+  }).finally(() => {
+    agreementPrompting = null
+  })
+  return agreementPrompting
 }
 
 export async function request({ method = 'GET', path, data, params, headers } = {}) {
@@ -44,27 +57,34 @@ export async function request({ method = 'GET', path, data, params, headers } =
   const payload = params ? { ...(data || {}), ...(params || {}) } : data
 
   try {
+    let activeHeader = header
     let res = await requestRaw({ method, url, data: payload, header })
     const httpStatus = res.statusCode
     const body = res.data || {}
 
     if (httpStatus === 401) {
       // 尝试刷新一次并重试
-      await tryRefreshToken()
-      const newToken = await ensureValidAccessToken()
+      const newToken = await refreshAccessToken()
       if (!newToken) {
         await logout()
         throw new Error('未授权')
       }
-      const retryHeader = { ...header, Authorization: `Bearer ${newToken}` }
-      res = await requestRaw({ method, url, data: payload, header: retryHeader })
+      activeHeader = { ...header, Authorization: `Bearer ${newToken}` }
+      res = await requestRaw({ method, url, data: payload, header: activeHeader })
       if (res.statusCode === 401) {
         await logout()
         throw new Error('未授权')
       }
     }
 
-    const finalBody = res.data || {}
+    let finalBody = res.data || {}
+    if (finalBody?.code === AGREEMENT_CONSENT_REQUIRED_CODE) {
+      // 同意最新协议后重试原请求
+      if (await promptAgreementConsent()) {
+        res = await requestRaw({ method, url, data: payload, header: activeHeader })
+        finalBody = res.data || {}
+      }
+    }
     if (typeof finalBody === 'object' && finalBody && 'code' in finalBody) {
       if (finalBody.code === SUCCESS_CODE) return finalBody.data
       if (String(finalBody.code).startsWith('401')) {